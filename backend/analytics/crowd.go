@@ -0,0 +1,76 @@
+package analytics
+
+import (
+	"sort"
+	"time"
+
+	"liftoff/backend/models"
+)
+
+// crowdPriorWeight is how many ratings' worth of the gym-wide average each slot
+// starts with, so a single quiet visit doesn't outrank a consistently quiet hour
+const crowdPriorWeight = 2.0
+
+// BestTimes groups crowd ratings by weekday and hour in loc and returns the slots
+// from quietest to busiest. Slots are ranked by their average rating shrunk
+// toward the gym-wide average, so slots with more samples are trusted more.
+func BestTimes(ratings []models.CrowdRating, loc *time.Location) []models.CrowdSlot {
+	if len(ratings) == 0 {
+		return []models.CrowdSlot{}
+	}
+	type key struct {
+		day  time.Weekday
+		hour int
+	}
+	type bucket struct{ sum, n float64 }
+	slots := map[key]*bucket{}
+	var total float64
+	for _, r := range ratings {
+		t := r.StartedAt.In(loc)
+		k := key{t.Weekday(), t.Hour()}
+		b := slots[k]
+		if b == nil {
+			b = &bucket{}
+			slots[k] = b
+		}
+		b.sum += float64(r.Crowd)
+		b.n++
+		total += float64(r.Crowd)
+	}
+	mean := total / float64(len(ratings))
+
+	keys := make([]key, 0, len(slots))
+	for k := range slots {
+		keys = append(keys, k)
+	}
+	score := func(k key) float64 {
+		b := slots[k]
+		return (b.sum + crowdPriorWeight*mean) / (b.n + crowdPriorWeight)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if sa, sb := score(a), score(b); sa != sb {
+			return sa < sb
+		}
+		if slots[a].n != slots[b].n {
+			return slots[a].n > slots[b].n
+		}
+		if a.day != b.day {
+			return a.day < b.day
+		}
+		return a.hour < b.hour
+	})
+
+	result := make([]models.CrowdSlot, 0, len(keys))
+	for _, k := range keys {
+		b := slots[k]
+		result = append(result, models.CrowdSlot{
+			Weekday:  k.day.String(),
+			Hour:     k.hour,
+			AvgCrowd: round2(b.sum / b.n),
+			Samples:  int(b.n),
+			Score:    round2(score(k)),
+		})
+	}
+	return result
+}
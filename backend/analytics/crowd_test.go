@@ -0,0 +1,48 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"liftoff/backend/models"
+)
+
+func TestBestTimes(t *testing.T) {
+	monday := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	rate := func(day, hour, crowd int) models.CrowdRating {
+		return models.CrowdRating{StartedAt: monday.AddDate(0, 0, day).Add(time.Duration(hour) * time.Hour), Crowd: crowd}
+	}
+	ratings := []models.CrowdRating{
+		rate(0, 6, 1), // a single quiet visit
+		rate(1, 18, 1), rate(8, 18, 1), rate(15, 18, 1), rate(22, 18, 2),
+		rate(2, 18, 5), rate(9, 18, 5),
+	}
+
+	slots := BestTimes(ratings, time.UTC)
+	if len(slots) != 3 {
+		t.Fatalf("got %d slots", len(slots))
+	}
+	// The consistently quiet Tuesday evening beats one quiet Monday morning
+	if s := slots[0]; s.Weekday != "Tuesday" || s.Hour != 18 || s.Samples != 4 || s.AvgCrowd != 1.25 || s.Score != 1.6 {
+		t.Errorf("best = %+v", s)
+	}
+	if s := slots[1]; s.Weekday != "Monday" || s.Hour != 6 || s.AvgCrowd != 1 {
+		t.Errorf("second = %+v", s)
+	}
+	if s := slots[2]; s.Weekday != "Wednesday" || s.AvgCrowd != 5 {
+		t.Errorf("busiest = %+v", s)
+	}
+
+	// Hours follow the requested zone
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("tzdata unavailable")
+	}
+	if s := BestTimes(ratings, ny)[0]; s.Weekday != "Tuesday" || s.Hour != 13 {
+		t.Errorf("best in New York = %+v", s)
+	}
+
+	if got := BestTimes(nil, time.UTC); got == nil || len(got) != 0 {
+		t.Errorf("no ratings = %v", got)
+	}
+}
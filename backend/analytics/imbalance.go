@@ -0,0 +1,114 @@
+package analytics
+
+import (
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"liftoff/backend/calc"
+	"liftoff/backend/models"
+)
+
+// DefaultImbalanceThreshold is the left/right gap, in percent, above which an exercise is flagged
+const DefaultImbalanceThreshold = 10.0
+
+// ImbalanceThresholdFromEnv reads IMBALANCE_THRESHOLD_PCT, falling back to DefaultImbalanceThreshold
+func ImbalanceThresholdFromEnv() float64 {
+	if v, err := strconv.ParseFloat(os.Getenv("IMBALANCE_THRESHOLD_PCT"), 64); err == nil && v > 0 {
+		return v
+	}
+	return DefaultImbalanceThreshold
+}
+
+// sideStrength scores one side of a set as kind: estimated 1RM (0 when it cannot
+// be estimated), or reps for bodyweight work
+func sideStrength(side models.SideResult, kind string) float64 {
+	if kind == models.RecordReps {
+		return float64(side.Reps)
+	}
+	e1rm, _ := calc.OneRepMax(side.Weight, side.Reps, calc.FormulaEpley)
+	return e1rm
+}
+
+// ComputeImbalance groups unilateral sets by exercise and 7-day window ending at
+// now, compares each side's best set per window, and flags exercises whose most
+// recent window differs by more than threshold percent. A window with weighted
+// sets compares estimated 1RMs and ignores its bodyweight sets, so the two are
+// never mixed. Exercises are sorted by that latest imbalance, largest first.
+func ComputeImbalance(entries []models.SideSetEntry, now time.Time, threshold float64) []models.ExerciseImbalance {
+	type bucket struct {
+		kind        string
+		left, right float64
+	}
+	byExercise := map[string]map[int]*bucket{}
+	for _, e := range entries {
+		age := now.Sub(e.LoggedAt)
+		if age < 0 {
+			continue
+		}
+		weeks := byExercise[e.ExerciseName]
+		if weeks == nil {
+			weeks = map[int]*bucket{}
+			byExercise[e.ExerciseName] = weeks
+		}
+		kind := models.RecordReps
+		if e.Sides.Left.Weight > 0 || e.Sides.Right.Weight > 0 {
+			kind = models.RecordE1RM
+		}
+		i := int(age / Week)
+		b := weeks[i]
+		if b == nil || (b.kind == models.RecordReps && kind == models.RecordE1RM) {
+			b = &bucket{kind: kind}
+			weeks[i] = b
+		} else if b.kind != kind {
+			continue
+		}
+		if v := sideStrength(e.Sides.Left, kind); v > b.left {
+			b.left = v
+		}
+		if v := sideStrength(e.Sides.Right, kind); v > b.right {
+			b.right = v
+		}
+	}
+
+	result := []models.ExerciseImbalance{}
+	for name, weeks := range byExercise {
+		indexes := make([]int, 0, len(weeks))
+		for i := range weeks {
+			indexes = append(indexes, i)
+		}
+		// Oldest window first
+		sort.Sort(sort.Reverse(sort.IntSlice(indexes)))
+
+		ex := models.ExerciseImbalance{ExerciseName: name}
+		for _, i := range indexes {
+			b := weeks[i]
+			w := models.SideImbalance{
+				WeekStart: now.Add(-time.Duration(i+1) * Week),
+				Kind:      b.kind,
+				Left:      round2(b.left),
+				Right:     round2(b.right),
+			}
+			if stronger := max(b.left, b.right); stronger > 0 && b.left != b.right {
+				w.ImbalancePct = round2((stronger - min(b.left, b.right)) / stronger * 100)
+				w.Weaker = "left"
+				if b.right < b.left {
+					w.Weaker = "right"
+				}
+			}
+			ex.Weeks = append(ex.Weeks, w)
+		}
+		ex.Latest = ex.Weeks[len(ex.Weeks)-1]
+		ex.Flagged = ex.Latest.ImbalancePct > threshold
+		result = append(result, ex)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Latest.ImbalancePct != result[j].Latest.ImbalancePct {
+			return result[i].Latest.ImbalancePct > result[j].Latest.ImbalancePct
+		}
+		return result[i].ExerciseName < result[j].ExerciseName
+	})
+	return result
+}
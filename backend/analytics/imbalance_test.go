@@ -0,0 +1,58 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"liftoff/backend/models"
+)
+
+func TestComputeImbalance(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	set := func(name string, daysAgo int, left, right models.SideResult) models.SideSetEntry {
+		return models.SideSetEntry{
+			ExerciseName: name,
+			LoggedAt:     now.Add(-time.Duration(daysAgo) * day),
+			Sides:        models.SetSides{Left: left, Right: right},
+		}
+	}
+
+	entries := []models.SideSetEntry{
+		set("Dumbbell Rows", 10, models.SideResult{Weight: 40, Reps: 1}, models.SideResult{Weight: 40, Reps: 1}),
+		set("Dumbbell Rows", 2, models.SideResult{Weight: 40, Reps: 1}, models.SideResult{Weight: 30, Reps: 1}),
+		set("Dumbbell Rows", 1, models.SideResult{Weight: 20, Reps: 1}, models.SideResult{Weight: 34, Reps: 1}), // weaker set ignored
+		set("Single-leg Glute Bridge", 3, models.SideResult{Reps: 19}, models.SideResult{Reps: 20}),
+		// A weighted set takes over the window, and the bodyweight sets in it are not compared
+		set("Single-leg RDL", 3, models.SideResult{Reps: 15}, models.SideResult{Reps: 15}),
+		set("Single-leg RDL", 2, models.SideResult{Weight: 20, Reps: 1}, models.SideResult{Weight: 19, Reps: 1}),
+		set("Single-leg RDL", 1, models.SideResult{Reps: 20}, models.SideResult{Reps: 10}),
+	}
+	result := ComputeImbalance(entries, now, 10)
+	if len(result) != 3 {
+		t.Fatalf("got %d exercises", len(result))
+	}
+
+	rows := result[0]
+	if rows.ExerciseName != "Dumbbell Rows" || len(rows.Weeks) != 2 {
+		t.Fatalf("first = %+v", rows)
+	}
+	if rows.Weeks[0].ImbalancePct != 0 || rows.Weeks[0].Weaker != "" {
+		t.Errorf("even week = %+v", rows.Weeks[0])
+	}
+	if rows.Latest.Kind != models.RecordE1RM || rows.Latest.Left != 40 || rows.Latest.Right != 34 || rows.Latest.Weaker != "right" ||
+		rows.Latest.ImbalancePct != 15 || !rows.Flagged {
+		t.Errorf("latest = %+v flagged=%v", rows.Latest, rows.Flagged)
+	}
+
+	// Bodyweight sets compare reps
+	bridge := result[1]
+	if bridge.Latest.Kind != models.RecordReps || bridge.Latest.Weaker != "left" || bridge.Latest.ImbalancePct != 5 || bridge.Flagged {
+		t.Errorf("bridge = %+v flagged=%v", bridge.Latest, bridge.Flagged)
+	}
+
+	rdl := result[2]
+	if rdl.Latest.Kind != models.RecordE1RM || rdl.Latest.Left != 20 || rdl.Latest.Right != 19 || rdl.Latest.ImbalancePct != 5 {
+		t.Errorf("rdl = %+v", rdl.Latest)
+	}
+}
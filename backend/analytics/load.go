@@ -0,0 +1,92 @@
+package analytics
+
+import (
+	"math"
+	"os"
+	"strconv"
+	"time"
+
+	"liftoff/backend/models"
+)
+
+/**
+ * Analytics Package
+ *
+ * Derived training metrics computed from completed sessions. Training load
+ * uses the acute:chronic workload ratio (ACWR): the load of the last 7 days
+ * divided by the weekly average of the last 28. Ratios well above 1 mean
+ * load is climbing faster than the body has adapted to, a common precursor
+ * of overuse injuries.
+ */
+
+// Window lengths for the acute:chronic ratio
+const (
+	Week         = 7 * 24 * time.Hour
+	ChronicWeeks = 4
+)
+
+// DefaultRampThreshold is the ratio above which a load ramp alert is raised
+const DefaultRampThreshold = 1.5
+
+// RampThresholdFromEnv reads LOAD_RAMP_THRESHOLD, falling back to DefaultRampThreshold
+func RampThresholdFromEnv() float64 {
+	if v, err := strconv.ParseFloat(os.Getenv("LOAD_RAMP_THRESHOLD"), 64); err == nil && v > 1 {
+		return v
+	}
+	return DefaultRampThreshold
+}
+
+// ComputeLoad buckets sessions into the ChronicWeeks 7-day windows ending at now
+// and derives the acute:chronic ratio for metric (models.LoadMetricTonnage or
+// models.LoadMetricDuration). The ratio is only reported once there is load
+// before the acute week, so a first week of training never counts as a ramp.
+func ComputeLoad(sessions []models.SessionLoad, now time.Time, metric string, threshold float64) *models.TrainingLoad {
+	load := &models.TrainingLoad{
+		Metric:    metric,
+		Weeks:     make([]models.WeeklyLoad, ChronicWeeks),
+		Threshold: threshold,
+	}
+	for i := range load.Weeks {
+		load.Weeks[i].WeekStart = now.Add(-time.Duration(i+1) * Week)
+	}
+
+	for _, s := range sessions {
+		age := now.Sub(s.EndedAt)
+		if age < 0 || age >= ChronicWeeks*Week {
+			continue
+		}
+		w := &load.Weeks[int(age/Week)]
+		w.Sessions++
+		w.Sets += s.Sets
+		w.Tonnage += s.Tonnage
+		w.Minutes += s.Minutes
+	}
+
+	var total, earlier float64
+	for i, w := range load.Weeks {
+		v := weekValue(w, metric)
+		total += v
+		if i > 0 {
+			earlier += v
+		}
+	}
+	load.Acute = round2(weekValue(load.Weeks[0], metric))
+	load.Chronic = round2(total / ChronicWeeks)
+	if earlier > 0 && load.Chronic > 0 {
+		ratio := round2(load.Acute / load.Chronic)
+		load.Ratio = &ratio
+		load.RampAlert = ratio > threshold
+	}
+	return load
+}
+
+func weekValue(w models.WeeklyLoad, metric string) float64 {
+	if metric == models.LoadMetricDuration {
+		return w.Minutes
+	}
+	return w.Tonnage
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
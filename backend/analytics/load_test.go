@@ -0,0 +1,45 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"liftoff/backend/models"
+)
+
+func TestComputeLoad(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	session := func(daysAgo int, tonnage, minutes float64) models.SessionLoad {
+		return models.SessionLoad{EndedAt: now.Add(-time.Duration(daysAgo) * day), Tonnage: tonnage, Sets: 10, Minutes: minutes}
+	}
+
+	// Three steady weeks of 1000 kg, then 3000 kg this week
+	sessions := []models.SessionLoad{
+		session(1, 1500, 60), session(3, 1500, 60),
+		session(9, 1000, 60), session(16, 1000, 60), session(23, 1000, 60),
+		session(40, 9999, 60), // outside the chronic window
+	}
+	load := ComputeLoad(sessions, now, models.LoadMetricTonnage, 1.5)
+	if load.Acute != 3000 || load.Chronic != 1500 {
+		t.Fatalf("acute/chronic = %v/%v, want 3000/1500", load.Acute, load.Chronic)
+	}
+	if load.Ratio == nil || *load.Ratio != 2 || !load.RampAlert {
+		t.Errorf("ratio = %v, alert = %v", load.Ratio, load.RampAlert)
+	}
+	if load.Weeks[0].Sessions != 2 || load.Weeks[1].Sessions != 1 {
+		t.Errorf("weeks = %+v", load.Weeks)
+	}
+
+	// By duration the ramp is milder: 120 / (300/4)
+	load = ComputeLoad(sessions, now, models.LoadMetricDuration, 1.5)
+	if load.Ratio == nil || *load.Ratio != 1.6 {
+		t.Errorf("duration ratio = %v, want 1.6", load.Ratio)
+	}
+
+	// A first week of training has nothing to compare against
+	load = ComputeLoad(sessions[:2], now, models.LoadMetricTonnage, 1.5)
+	if load.Ratio != nil || load.RampAlert {
+		t.Errorf("first week: ratio = %v, alert = %v", load.Ratio, load.RampAlert)
+	}
+}
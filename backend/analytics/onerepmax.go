@@ -0,0 +1,55 @@
+package analytics
+
+import (
+	"errors"
+	"math"
+
+	"liftoff/backend/calc"
+	"liftoff/backend/models"
+)
+
+// OneRepMaxHistory estimates a one-rep max from each set with formula and keeps
+// the best per session, in the order sessions first appear in sets. Sets the
+// formula cannot estimate (no weight, or over 30 reps) are skipped; ties go to
+// the heavier set.
+func OneRepMaxHistory(sets []models.LoggedSet, formula string) ([]models.OneRepMaxPoint, error) {
+	points := []models.OneRepMaxPoint{}
+	index := map[string]int{}
+	for _, set := range sets {
+		e1rm, err := calc.OneRepMax(set.Weight, set.Reps, formula)
+		if errors.Is(err, calc.ErrUnknownFormula) {
+			return nil, err
+		}
+		if err != nil {
+			continue
+		}
+		point := models.OneRepMaxPoint{
+			SessionID: set.SessionID,
+			Date:      set.StartedAt,
+			Weight:    set.Weight,
+			Reps:      set.Reps,
+			E1RM:      math.Round(e1rm*100) / 100,
+		}
+		i, seen := index[set.SessionID]
+		if !seen {
+			index[set.SessionID] = len(points)
+			points = append(points, point)
+			continue
+		}
+		if best := points[i]; point.E1RM > best.E1RM || (point.E1RM == best.E1RM && point.Weight > best.Weight) {
+			points[i] = point
+		}
+	}
+	return points, nil
+}
+
+// BestOneRepMax returns the highest point of a history, or nil if it is empty
+func BestOneRepMax(points []models.OneRepMaxPoint) *models.OneRepMaxPoint {
+	var best *models.OneRepMaxPoint
+	for i := range points {
+		if best == nil || points[i].E1RM > best.E1RM {
+			best = &points[i]
+		}
+	}
+	return best
+}
@@ -0,0 +1,41 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"liftoff/backend/calc"
+	"liftoff/backend/models"
+)
+
+func TestOneRepMaxHistory(t *testing.T) {
+	start := time.Date(2024, 3, 1, 18, 0, 0, 0, time.UTC)
+	sets := []models.LoggedSet{
+		{SessionID: "a", StartedAt: start, Weight: 100, Reps: 5},
+		{SessionID: "a", StartedAt: start, Weight: 110, Reps: 3}, // 121 beats 116.67
+		{SessionID: "a", StartedAt: start, Weight: 0, Reps: 12},  // bodyweight, skipped
+		{SessionID: "b", StartedAt: start.Add(72 * time.Hour), Weight: 115, Reps: 1},
+	}
+
+	history, err := OneRepMaxHistory(sets, calc.FormulaEpley)
+	if err != nil || len(history) != 2 {
+		t.Fatalf("history = %+v, %v", history, err)
+	}
+	if history[0].SessionID != "a" || history[0].Weight != 110 || history[0].E1RM != 121 || history[1].E1RM != 115 {
+		t.Errorf("history = %+v", history)
+	}
+	if best := BestOneRepMax(history); best == nil || best.SessionID != "a" {
+		t.Errorf("best = %+v", best)
+	}
+
+	brzycki, err := OneRepMaxHistory(sets[:1], calc.FormulaBrzycki)
+	if err != nil || brzycki[0].E1RM != 112.5 {
+		t.Errorf("brzycki = %+v, %v", brzycki, err)
+	}
+	if _, err := OneRepMaxHistory(sets, "magic"); err == nil {
+		t.Error("unknown formula accepted")
+	}
+	if empty, _ := OneRepMaxHistory(nil, ""); len(empty) != 0 || BestOneRepMax(empty) != nil {
+		t.Errorf("no sets = %+v", empty)
+	}
+}
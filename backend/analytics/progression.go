@@ -0,0 +1,59 @@
+package analytics
+
+import (
+	"math"
+
+	"liftoff/backend/models"
+)
+
+// EvaluateProgression applies a progression rule to an exercise given the sets
+// logged for it in an ended session. ok is false when the rule does not apply:
+// timed and cardio exercises, and sessions with no working sets logged, which
+// leave the exercise and the rule's count of misses as they were.
+//
+// The session succeeds when every working set is completed at or above the
+// target reps, the top of the exercise's rep range when it has one. A deload
+// takes about the rule's percentage off, in whole increments and at least one,
+// so weights stay on the grid the increment builds.
+func EvaluateProgression(rule models.ProgressionRule, e models.Exercise, sets []*models.ExerciseSet) (result models.ProgressionResult, ok bool) {
+	if e.IsTimed() || e.IsCardio() {
+		return result, false
+	}
+	target := e.Reps
+	if e.RepsMax > 0 {
+		target = e.RepsMax
+	}
+	working, hit := 0, 0
+	for _, set := range sets {
+		if set.IsWarmup() {
+			continue
+		}
+		working++
+		if set.Completed && set.Reps >= target {
+			hit++
+		}
+	}
+	if working == 0 {
+		return result, false
+	}
+
+	result = models.ProgressionResult{
+		ExerciseID:     e.ID,
+		ExerciseName:   e.Name,
+		PreviousWeight: e.Weight,
+		Weight:         e.Weight,
+	}
+	switch {
+	case hit == working:
+		result.Outcome = models.ProgressionIncreased
+		result.Weight = round2(e.Weight + rule.WeightIncrement)
+	case rule.Failures+1 >= rule.FailureLimit:
+		result.Outcome = models.ProgressionDeloaded
+		steps := math.Max(1, math.Round(e.Weight*rule.DeloadPercent/100/rule.WeightIncrement))
+		result.Weight = math.Max(0, round2(e.Weight-steps*rule.WeightIncrement))
+	default:
+		result.Outcome = models.ProgressionMissed
+		result.Failures = rule.Failures + 1
+	}
+	return result, true
+}
@@ -0,0 +1,66 @@
+package analytics
+
+import (
+	"testing"
+
+	"liftoff/backend/models"
+)
+
+func TestEvaluateProgression(t *testing.T) {
+	rule := models.ProgressionRule{WeightIncrement: 5, FailureLimit: 2, DeloadPercent: 10}
+	squat := models.Exercise{ID: "squat", Name: "Squat", Sets: 3, Reps: 5, Weight: 185}
+	sets := func(reps ...int) []*models.ExerciseSet {
+		logged := []*models.ExerciseSet{{Reps: 8, Weight: 95, SetType: models.SetTypeWarmup}}
+		for _, r := range reps {
+			logged = append(logged, &models.ExerciseSet{Reps: r, Weight: 185, Completed: true})
+		}
+		return logged
+	}
+
+	got, ok := EvaluateProgression(rule, squat, sets(5, 5, 6))
+	if !ok || got.Outcome != models.ProgressionIncreased || got.PreviousWeight != 185 || got.Weight != 190 || got.Failures != 0 {
+		t.Errorf("all sets hit = %+v, %v", got, ok)
+	}
+	got, _ = EvaluateProgression(rule, squat, sets(5, 5, 4))
+	if got.Outcome != models.ProgressionMissed || got.Weight != 185 || got.Failures != 1 {
+		t.Errorf("first miss = %+v", got)
+	}
+	unfinished := sets(5, 5, 5)
+	unfinished[3].Completed = false
+	if got, _ := EvaluateProgression(rule, squat, unfinished); got.Outcome != models.ProgressionMissed {
+		t.Errorf("unfinished set = %+v", got)
+	}
+
+	// The second miss in a row deloads about 10% in whole increments: 18.5 rounds to 20
+	rule.Failures = 1
+	got, _ = EvaluateProgression(rule, squat, sets(5, 3, 3))
+	if got.Outcome != models.ProgressionDeloaded || got.Weight != 165 || got.Failures != 0 {
+		t.Errorf("deload = %+v", got)
+	}
+	// At least one increment comes off, and a kilogram increment keeps kilogram weights round
+	if got, _ := EvaluateProgression(rule, models.Exercise{Name: "Curl", Sets: 3, Reps: 10, Weight: 20}, sets(8)); got.Weight != 15 {
+		t.Errorf("light deload = %+v", got)
+	}
+	kg := models.ProgressionRule{WeightIncrement: 5.51, FailureLimit: 1, DeloadPercent: 10}
+	if got, _ := EvaluateProgression(kg, models.Exercise{Name: "Bench", Sets: 3, Reps: 5, Weight: 220.46}, sets(4)); got.Weight != 198.42 {
+		t.Errorf("metric deload = %+v", got)
+	}
+
+	// Rep ranges progress from the top of the range
+	row := models.Exercise{Name: "Row", Sets: 2, Reps: 8, RepsMin: 8, RepsMax: 12, Weight: 100}
+	if got, _ := EvaluateProgression(models.ProgressionRule{WeightIncrement: 5, FailureLimit: 3}, row, sets(12, 10)); got.Outcome != models.ProgressionMissed {
+		t.Errorf("bottom of range = %+v", got)
+	}
+
+	for name, e := range map[string]models.Exercise{
+		"timed":  {Name: "Plank", Sets: 3, Mode: models.ExerciseModeDuration, DurationSeconds: 60},
+		"cardio": {Name: "Run", Sets: 1, Mode: models.ExerciseModeCardio, DistanceMeters: 5000},
+	} {
+		if _, ok := EvaluateProgression(rule, e, sets(1)); ok {
+			t.Errorf("%s: rule applied", name)
+		}
+	}
+	if _, ok := EvaluateProgression(rule, squat, sets()); ok {
+		t.Error("warm-ups only: rule applied")
+	}
+}
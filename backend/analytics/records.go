@@ -0,0 +1,82 @@
+package analytics
+
+import (
+	"strings"
+
+	"liftoff/backend/calc"
+	"liftoff/backend/models"
+)
+
+// SessionRecords finds the sets of a session that beat the user's previous bests,
+// keyed by lowercased exercise name as returned by SessionRepository.GetExerciseBests.
+// Only the session's top set of each kind is flagged, and exercises with no earlier
+// history are skipped so first sessions aren't all records.
+func SessionRecords(session *models.WorkoutSession, prior map[string]models.ExerciseBest) []models.SetRecord {
+	records := []models.SetRecord{}
+	for _, se := range session.Exercises {
+		if se.Exercise == nil {
+			continue
+		}
+		prev, ok := prior[strings.ToLower(se.Exercise.Name)]
+		if !ok {
+			continue
+		}
+
+		type top struct {
+			setID string
+			value float64
+		}
+		tops := map[string]*top{}
+		consider := func(kind, setID string, value float64) {
+			if t := tops[kind]; t == nil || value > t.value {
+				tops[kind] = &top{setID, value}
+			}
+		}
+		for _, set := range se.Sets {
+			if !set.Completed || set.IsWarmup() {
+				continue
+			}
+			if se.Exercise.IsTimed() || se.Exercise.IsCardio() {
+				if set.DurationSeconds != nil {
+					consider(models.RecordDuration, set.ID, float64(*set.DurationSeconds))
+				}
+				if set.DistanceMeters != nil {
+					consider(models.RecordDistance, set.ID, float64(*set.DistanceMeters))
+				}
+				continue
+			}
+			if set.Weight <= 0 {
+				consider(models.RecordReps, set.ID, float64(set.Reps))
+				continue
+			}
+			consider(models.RecordWeight, set.ID, set.Weight)
+			if e1rm, err := calc.OneRepMax(set.Weight, set.Reps, calc.FormulaEpley); err == nil {
+				consider(models.RecordE1RM, set.ID, e1rm)
+			}
+		}
+
+		for _, kind := range []string{models.RecordWeight, models.RecordE1RM, models.RecordReps, models.RecordDuration, models.RecordDistance} {
+			t := tops[kind]
+			if t == nil {
+				continue
+			}
+			var previous float64
+			switch kind {
+			case models.RecordWeight:
+				previous = prev.Weight
+			case models.RecordE1RM:
+				previous = prev.E1RM
+			case models.RecordReps:
+				previous = float64(prev.Reps)
+			case models.RecordDuration:
+				previous = float64(prev.DurationSeconds)
+			case models.RecordDistance:
+				previous = float64(prev.DistanceMeters)
+			}
+			if t.value > previous {
+				records = append(records, models.SetRecord{SetID: t.setID, Kind: kind, Value: round2(t.value), Previous: round2(previous)})
+			}
+		}
+	}
+	return records
+}
@@ -0,0 +1,57 @@
+package analytics
+
+import (
+	"testing"
+
+	"liftoff/backend/models"
+)
+
+func TestSessionRecords(t *testing.T) {
+	hold := func(s int) *int { return &s }
+	session := &models.WorkoutSession{Exercises: []*models.SessionExercise{
+		{Exercise: &models.Exercise{Name: "Bench Press"}, Sets: []*models.ExerciseSet{
+			{ID: "b1", Weight: 140, Reps: 5, Completed: true},
+			{ID: "b2", Weight: 145, Reps: 1, Completed: true},
+			{ID: "b3", Weight: 150, Reps: 1}, // not completed
+		}},
+		{Exercise: &models.Exercise{Name: "Plank", Mode: models.ExerciseModeDuration}, Sets: []*models.ExerciseSet{
+			{ID: "p1", DurationSeconds: hold(50), Completed: true},
+			{ID: "p2", DurationSeconds: hold(60), Completed: true},
+		}},
+		{Exercise: &models.Exercise{Name: "Pull-ups"}, Sets: []*models.ExerciseSet{
+			{ID: "u1", Reps: 8, Completed: true},
+		}},
+		{Exercise: &models.Exercise{Name: "Running", Mode: models.ExerciseModeCardio}, Sets: []*models.ExerciseSet{
+			{ID: "r1", DurationSeconds: hold(1500), DistanceMeters: hold(5200), Completed: true},
+		}},
+		{Exercise: &models.Exercise{Name: "Curls"}, Sets: []*models.ExerciseSet{ // no history
+			{ID: "c1", Weight: 30, Reps: 10, Completed: true},
+		}},
+	}}
+	prior := map[string]models.ExerciseBest{
+		"bench press": {Weight: 140, E1RM: 160, Reps: 8},
+		"plank":       {DurationSeconds: 55},
+		"pull-ups":    {Reps: 10},
+		"running":     {DurationSeconds: 1800, DistanceMeters: 5000},
+	}
+
+	got := map[string]models.SetRecord{}
+	for _, r := range SessionRecords(session, prior) {
+		got[r.Kind+":"+r.SetID] = r
+	}
+	if len(got) != 4 {
+		t.Fatalf("records = %+v", got)
+	}
+	if r, ok := got["weight:b2"]; !ok || r.Value != 145 || r.Previous != 140 {
+		t.Errorf("weight record = %+v", r)
+	}
+	if r, ok := got["e1rm:b1"]; !ok || r.Value != 163.33 {
+		t.Errorf("e1rm record = %+v", r)
+	}
+	if r, ok := got["duration:p2"]; !ok || r.Previous != 55 {
+		t.Errorf("duration record = %+v", r)
+	}
+	if r, ok := got["distance:r1"]; !ok || r.Value != 5200 || r.Previous != 5000 {
+		t.Errorf("distance record = %+v", r)
+	}
+}
@@ -0,0 +1,70 @@
+package analytics
+
+import (
+	"math"
+
+	"liftoff/backend/calc"
+	"liftoff/backend/models"
+)
+
+// RepsInReserve is how many reps short of failure scaled template weights aim for
+const RepsInReserve = 2
+
+// levelWeightShare is the share of a library exercise's default weight a user
+// with no history of that exercise starts at, by training level
+var levelWeightShare = map[string]float64{
+	"beginner":     0.5,
+	"intermediate": 0.75,
+	"advanced":     1,
+}
+
+// ScaleExercise fits a template exercise to the user before it is copied into
+// their workout. best is the user's logged best for the exercise (nil when they
+// have none), library its match in the exercise library (nil when unknown), and
+// level their training level as in models.TrainingProfile.
+//
+// Logged history wins: weighted exercises start at the heaviest weight that
+// leaves RepsInReserve reps at the template's rep target given the best
+// estimated 1RM, and bodyweight and timed exercises at 80% of the best reps or
+// hold; cardio targets are kept as they are. Without history, weights start at
+// a level-based share of the library's default. Beginners also do one set
+// fewer (never below two) and advanced lifters one more (never above five).
+func ScaleExercise(e models.Exercise, best *models.ExerciseBest, library *models.ExerciseTemplate, level string) models.Exercise {
+	switch level {
+	case "beginner":
+		if e.Sets > 2 {
+			e.Sets--
+		}
+	case "advanced":
+		if e.Sets > 1 && e.Sets < 5 {
+			e.Sets++
+		}
+	}
+
+	if e.IsTimed() {
+		if best != nil && best.DurationSeconds > 0 {
+			// Holds are rounded to 5 seconds, and never below 10
+			e.DurationSeconds = int(math.Max(10, math.Round(float64(best.DurationSeconds)*0.8/5)*5))
+		}
+		return e
+	}
+	if e.IsCardio() {
+		return e
+	}
+
+	switch {
+	case best != nil && best.E1RM > 0 && e.Reps > 0:
+		if w, err := calc.WeightForReps(best.E1RM, e.Reps, RepsInReserve, calc.DefaultIncrement); err == nil && w > 0 {
+			e.Weight = w
+		}
+	case best != nil && best.Weight <= 0 && best.Reps > 0 && e.Reps > 1:
+		e.Reps = int(math.Max(1, math.Round(float64(best.Reps)*0.8)))
+	case e.Weight == 0 && library != nil && library.DefaultWeight > 0:
+		share, ok := levelWeightShare[level]
+		if !ok {
+			share = levelWeightShare["beginner"]
+		}
+		e.Weight = math.Floor(library.DefaultWeight*share/calc.DefaultIncrement) * calc.DefaultIncrement
+	}
+	return e
+}
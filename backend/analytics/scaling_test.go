@@ -0,0 +1,42 @@
+package analytics
+
+import (
+	"testing"
+
+	"liftoff/backend/models"
+)
+
+func TestScaleExercise(t *testing.T) {
+	bench := models.Exercise{Name: "Bench Press", Sets: 4, Reps: 8}
+	library := &models.ExerciseTemplate{Name: "Barbell Bench Press", DefaultWeight: 135}
+
+	// 8 reps with 2 in reserve from a 140 e1RM: 140 / (1 + 10/30) = 105
+	got := ScaleExercise(bench, &models.ExerciseBest{Weight: 120, E1RM: 140, Reps: 5}, library, "intermediate")
+	if got.Weight != 105 || got.Sets != 4 || got.Reps != 8 {
+		t.Errorf("with history = %+v", got)
+	}
+
+	// No history: a share of the library default by level, with sets adjusted
+	if got := ScaleExercise(bench, nil, library, "beginner"); got.Weight != 67.5 || got.Sets != 3 {
+		t.Errorf("beginner = %+v", got)
+	}
+	if got := ScaleExercise(bench, nil, library, "advanced"); got.Weight != 135 || got.Sets != 5 {
+		t.Errorf("advanced = %+v", got)
+	}
+	if got := ScaleExercise(bench, nil, nil, "intermediate"); got.Weight != 0 {
+		t.Errorf("unknown exercise = %+v", got)
+	}
+
+	pushups := models.Exercise{Name: "Push-ups", Sets: 3, Reps: 10}
+	if got := ScaleExercise(pushups, &models.ExerciseBest{Reps: 25}, nil, "intermediate"); got.Reps != 20 || got.Weight != 0 {
+		t.Errorf("bodyweight = %+v", got)
+	}
+
+	plank := models.Exercise{Name: "Plank", Sets: 3, Mode: models.ExerciseModeDuration, DurationSeconds: 45}
+	if got := ScaleExercise(plank, &models.ExerciseBest{DurationSeconds: 92}, nil, "intermediate"); got.DurationSeconds != 75 {
+		t.Errorf("timed = %+v", got)
+	}
+	if got := ScaleExercise(plank, nil, nil, "intermediate"); got.DurationSeconds != 45 {
+		t.Errorf("timed without history = %+v", got)
+	}
+}
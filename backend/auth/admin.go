@@ -1,27 +1,105 @@
 package auth
 
 import (
+	"context"
+	"log"
 	"net/http"
 	"os"
+	"slices"
 	"strings"
+	"sync"
 
 	"github.com/gin-gonic/gin"
 )
 
-// AdminMiddleware requires AuthMiddleware and checks if user is admin.
-// Admin emails are from ADMIN_EMAILS env (comma-separated) or default admin@liftoff.local
-func AdminMiddleware() gin.HandlerFunc {
+// User roles, stored in users.role. Admins pass every role check.
+const (
+	RoleUser  = "user"
+	RoleCoach = "coach"
+	RoleAdmin = "admin"
+)
+
+// UserRoleKey holds the effective role once RequireRole has resolved it
+const UserRoleKey = "user_role"
+
+// ValidRole reports whether role is one of the known roles
+func ValidRole(role string) bool {
+	return role == RoleUser || role == RoleCoach || role == RoleAdmin
+}
+
+// RoleResolver looks up the role stored for a user
+type RoleResolver interface {
+	UserRole(ctx context.Context, userID string) (string, error)
+}
+
+var (
+	roleMu       sync.RWMutex
+	roleResolver RoleResolver
+)
+
+// SetRoleResolver installs the lookup RequireRole uses (nil leaves only ADMIN_EMAILS)
+func SetRoleResolver(resolver RoleResolver) {
+	roleMu.Lock()
+	defer roleMu.Unlock()
+	roleResolver = resolver
+}
+
+func getRoleResolver() RoleResolver {
+	roleMu.RLock()
+	defer roleMu.RUnlock()
+	return roleResolver
+}
+
+// EffectiveRole combines a stored role with the ADMIN_EMAILS bootstrap list
+func EffectiveRole(role, email string) string {
+	if IsAdminEmail(email) {
+		return RoleAdmin
+	}
+	if role == "" {
+		return RoleUser
+	}
+	return role
+}
+
+// RequireRole requires AuthMiddleware and one of the given roles. Admins always pass.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	required := strings.ToUpper(roles[0][:1]) + roles[0][1:]
 	return func(c *gin.Context) {
+		stored := ""
+		if resolver := getRoleResolver(); resolver != nil {
+			var err error
+			if stored, err = resolver.UserRole(c.Request.Context(), GetUserID(c)); err != nil {
+				log.Printf("Role lookup failed: %v", err)
+				c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "Unable to verify role"})
+				return
+			}
+		}
 		email, _ := c.Get(UserEmailKey)
-		emailStr, ok := email.(string)
-		if !ok || !IsAdminEmail(emailStr) {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+		emailStr, _ := email.(string)
+		role := EffectiveRole(stored, emailStr)
+		if role != RoleAdmin && !slices.Contains(roles, role) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": required + " access required"})
 			return
 		}
+		c.Set(UserRoleKey, role)
 		c.Next()
 	}
 }
 
+// AdminMiddleware requires AuthMiddleware and the admin role, granted in the
+// database or through ADMIN_EMAILS (comma-separated, default admin@liftoff.local)
+func AdminMiddleware() gin.HandlerFunc {
+	requireAdmin := RequireRole(RoleAdmin)
+	return func(c *gin.Context) {
+		// Admin actions are never taken, or audited, under another account's name
+		if GetImpersonatorID(c) != "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Admin access is not available while impersonating"})
+			return
+		}
+		requireAdmin(c)
+	}
+}
+
 // IsAdminEmail returns true if the email is an allowed admin email
 func IsAdminEmail(email string) bool {
 	allowlist := os.Getenv("ADMIN_EMAILS")
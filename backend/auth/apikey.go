@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyPrefix starts every personal API key so leaked keys are easy to recognise
+const APIKeyPrefix = "lft_"
+
+// APIKeyIDKey holds the ID of the API key used to authenticate, when one was
+const APIKeyIDKey = "api_key_id"
+
+// ErrInvalidAPIKey is returned by resolvers for unknown or revoked keys
+var ErrInvalidAPIKey = errors.New("invalid api key")
+
+// APIKeyOwner is the user an API key authenticates as
+type APIKeyOwner struct {
+	KeyID  string
+	UserID string
+	Email  string
+}
+
+// APIKeyResolver looks up the owner of an API key by its hash (see HashToken)
+type APIKeyResolver interface {
+	ResolveAPIKey(ctx context.Context, keyHash string) (*APIKeyOwner, error)
+}
+
+var (
+	apiKeyMu       sync.RWMutex
+	apiKeyResolver APIKeyResolver
+)
+
+// SetAPIKeyResolver enables "Authorization: ApiKey <key>" in AuthMiddleware (nil disables it)
+func SetAPIKeyResolver(resolver APIKeyResolver) {
+	apiKeyMu.Lock()
+	defer apiKeyMu.Unlock()
+	apiKeyResolver = resolver
+}
+
+func getAPIKeyResolver() APIKeyResolver {
+	apiKeyMu.RLock()
+	defer apiKeyMu.RUnlock()
+	return apiKeyResolver
+}
+
+// GetAPIKeyID returns the API key used for this request, or "" for token auth (call after AuthMiddleware)
+func GetAPIKeyID(c *gin.Context) string {
+	id, _ := c.Get(APIKeyIDKey)
+	if s, ok := id.(string); ok {
+		return s
+	}
+	return ""
+}
@@ -0,0 +1,226 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	ProviderApple = "apple"
+
+	appleIssuer = "https://appleid.apple.com"
+)
+
+var ErrInvalidAppleToken = errors.New("invalid Apple identity token")
+
+// AppleProvider holds Sign in with Apple configuration
+type AppleProvider struct {
+	TeamID      string
+	ClientIDs   []string // first entry is the Services ID used for code exchange; others (e.g. iOS bundle IDs) are accepted audiences
+	KeyID       string
+	PrivateKey  *ecdsa.PrivateKey
+	RedirectURL string
+	TokenURL    string
+	KeysURL     string
+	HTTPClient  *http.Client
+}
+
+// GetAppleProvider loads Apple config from environment.
+// Requires APPLE_TEAM_ID, APPLE_CLIENT_ID, APPLE_KEY_ID and APPLE_PRIVATE_KEY (PEM) or APPLE_PRIVATE_KEY_FILE.
+func GetAppleProvider() (*AppleProvider, error) {
+	teamID := os.Getenv("APPLE_TEAM_ID")
+	keyID := os.Getenv("APPLE_KEY_ID")
+	var clientIDs []string
+	for _, id := range strings.Split(os.Getenv("APPLE_CLIENT_ID"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			clientIDs = append(clientIDs, id)
+		}
+	}
+
+	keyPEM := strings.ReplaceAll(os.Getenv("APPLE_PRIVATE_KEY"), `\n`, "\n")
+	if keyPEM == "" {
+		if path := os.Getenv("APPLE_PRIVATE_KEY_FILE"); path != "" {
+			b, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read Apple private key: %w", err)
+			}
+			keyPEM = string(b)
+		}
+	}
+
+	if teamID == "" || keyID == "" || len(clientIDs) == 0 || keyPEM == "" {
+		return nil, ErrOAuthNotConfigured
+	}
+
+	key, err := ParseApplePrivateKey([]byte(keyPEM))
+	if err != nil {
+		return nil, err
+	}
+
+	redirectURL := os.Getenv("APPLE_REDIRECT_URL")
+	if redirectURL == "" {
+		redirectURL = "http://localhost:5173/oauth/apple"
+	}
+
+	return &AppleProvider{
+		TeamID:      teamID,
+		ClientIDs:   clientIDs,
+		KeyID:       keyID,
+		PrivateKey:  key,
+		RedirectURL: redirectURL,
+		TokenURL:    appleIssuer + "/auth/token",
+		KeysURL:     appleIssuer + "/auth/keys",
+	}, nil
+}
+
+// ParseApplePrivateKey parses the PKCS#8 .p8 key downloaded from the Apple developer portal
+func ParseApplePrivateKey(pemBytes []byte) (*ecdsa.PrivateKey, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, errors.New("invalid Apple private key PEM")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Apple private key: %w", err)
+	}
+	key, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("Apple private key must be an EC key")
+	}
+	return key, nil
+}
+
+// ClientSecret generates the short-lived ES256 JWT Apple expects as client_secret
+func (p *AppleProvider) ClientSecret(now time.Time) (string, error) {
+	claims := jwt.RegisteredClaims{
+		Issuer:    p.TeamID,
+		Subject:   p.ClientIDs[0],
+		Audience:  jwt.ClaimStrings{appleIssuer},
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(5 * time.Minute)),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["kid"] = p.KeyID
+	return token.SignedString(p.PrivateKey)
+}
+
+// Exchange trades an authorization code for tokens; the response carries the id_token
+func (p *AppleProvider) Exchange(ctx context.Context, code string) (*OAuthToken, error) {
+	secret, err := p.ClientSecret(time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign Apple client secret: %w", err)
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.RedirectURL)
+	form.Set("client_id", p.ClientIDs[0])
+	form.Set("client_secret", secret)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOAuthExchange, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: status %d", ErrOAuthExchange, resp.StatusCode)
+	}
+
+	var token OAuthToken
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOAuthExchange, err)
+	}
+	if token.IDToken == "" {
+		return nil, fmt.Errorf("%w: missing id_token", ErrOAuthExchange)
+	}
+	return &token, nil
+}
+
+// appleIDClaims are the identity token claims we rely on.
+// Apple sends email_verified as either a boolean or the string "true".
+type appleIDClaims struct {
+	Email         string          `json:"email"`
+	EmailVerified json.RawMessage `json:"email_verified"`
+	Nonce         string          `json:"nonce"`
+	jwt.RegisteredClaims
+}
+
+// VerifyIdentityToken validates an Apple id_token against Apple's JWKS.
+// If nonce is non-empty the token's nonce must equal its SHA-256 hex digest (Apple JS hashes it).
+func (p *AppleProvider) VerifyIdentityToken(ctx context.Context, idToken, nonce string) (*OAuthUserInfo, error) {
+	keys := SharedJWKSCache(p.KeysURL, p.HTTPClient)
+
+	var claims appleIDClaims
+	_, err := jwt.ParseWithClaims(idToken, &claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return keys.Key(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256"}),
+		jwt.WithIssuer(appleIssuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAppleToken, err)
+	}
+
+	if !p.acceptsAudience(claims.Audience) {
+		return nil, fmt.Errorf("%w: unexpected audience", ErrInvalidAppleToken)
+	}
+	if nonce != "" {
+		sum := sha256.Sum256([]byte(nonce))
+		if claims.Nonce != hex.EncodeToString(sum[:]) && claims.Nonce != nonce {
+			return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidAppleToken)
+		}
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: missing subject", ErrInvalidAppleToken)
+	}
+
+	verified := strings.Trim(string(claims.EmailVerified), `"`) == "true"
+	return &OAuthUserInfo{
+		Subject:       claims.Subject,
+		Email:         NormalizeEmail(claims.Email),
+		EmailVerified: verified,
+	}, nil
+}
+
+func (p *AppleProvider) acceptsAudience(aud jwt.ClaimStrings) bool {
+	for _, a := range aud {
+		for _, id := range p.ClientIDs {
+			if a == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (p *AppleProvider) client() *http.Client {
+	if p.HTTPClient != nil {
+		return p.HTTPClient
+	}
+	return &http.Client{Timeout: 10 * time.Second}
+}
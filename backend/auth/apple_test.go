@@ -0,0 +1,134 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func newTestAppleProvider(t *testing.T) *AppleProvider {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &AppleProvider{
+		TeamID:     "TEAM123",
+		ClientIDs:  []string{"com.liftoff.web", "com.liftoff.ios"},
+		KeyID:      "KEY123",
+		PrivateKey: key,
+	}
+}
+
+func TestParseApplePrivateKey(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+
+	parsed, err := ParseApplePrivateKey(pemBytes)
+	if err != nil {
+		t.Fatalf("ParseApplePrivateKey() error = %v", err)
+	}
+	if !parsed.Equal(key) {
+		t.Error("parsed key does not match")
+	}
+
+	if _, err := ParseApplePrivateKey([]byte("not a pem")); err == nil {
+		t.Error("expected error for invalid PEM")
+	}
+}
+
+func TestAppleProvider_ClientSecret(t *testing.T) {
+	p := newTestAppleProvider(t)
+
+	secret, err := p.ClientSecret(time.Now())
+	if err != nil {
+		t.Fatalf("ClientSecret() error = %v", err)
+	}
+
+	var claims jwt.RegisteredClaims
+	token, err := jwt.ParseWithClaims(secret, &claims, func(t *jwt.Token) (interface{}, error) {
+		return &p.PrivateKey.PublicKey, nil
+	}, jwt.WithValidMethods([]string{"ES256"}))
+	if err != nil {
+		t.Fatalf("client secret does not verify: %v", err)
+	}
+	if token.Header["kid"] != "KEY123" {
+		t.Errorf("kid = %v", token.Header["kid"])
+	}
+	if claims.Issuer != "TEAM123" || claims.Subject != "com.liftoff.web" {
+		t.Errorf("iss/sub = %q/%q", claims.Issuer, claims.Subject)
+	}
+}
+
+func TestAppleProvider_VerifyIdentityToken(t *testing.T) {
+	signingKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []JWK{{
+				Kty: "RSA",
+				Kid: "apple-kid",
+				Alg: "RS256",
+				N:   base64.RawURLEncoding.EncodeToString(signingKey.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(signingKey.E)).Bytes()),
+			}},
+		})
+	}))
+	defer srv.Close()
+
+	p := newTestAppleProvider(t)
+	p.KeysURL = srv.URL
+
+	sign := func(aud string, verified interface{}) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+			"iss":            appleIssuer,
+			"aud":            aud,
+			"sub":            "001234.abcd",
+			"email":          "User@PrivateRelay.AppleID.com",
+			"email_verified": verified,
+			"exp":            time.Now().Add(time.Hour).Unix(),
+		})
+		token.Header["kid"] = "apple-kid"
+		s, err := token.SignedString(signingKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+
+	info, err := p.VerifyIdentityToken(context.Background(), sign("com.liftoff.ios", "true"), "")
+	if err != nil {
+		t.Fatalf("VerifyIdentityToken() error = %v", err)
+	}
+	if info.Subject != "001234.abcd" || info.Email != "user@privaterelay.appleid.com" || !info.EmailVerified {
+		t.Errorf("info = %+v", info)
+	}
+
+	info, err = p.VerifyIdentityToken(context.Background(), sign("com.liftoff.web", true), "")
+	if err != nil || !info.EmailVerified {
+		t.Errorf("boolean email_verified: info = %+v, err = %v", info, err)
+	}
+
+	if _, err := p.VerifyIdentityToken(context.Background(), sign("com.other.app", "true"), ""); err == nil {
+		t.Error("expected error for foreign audience")
+	}
+	if _, err := p.VerifyIdentityToken(context.Background(), sign("com.liftoff.web", "true"), "nonce-1"); err == nil {
+		t.Error("expected error for nonce mismatch")
+	}
+}
@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrPasswordBreached is returned for passwords found in known data breaches
+var ErrPasswordBreached = errors.New("password has appeared in a data breach; choose a different one")
+
+// pwnedRangeURL is the Have I Been Pwned k-anonymity range API
+const pwnedRangeURL = "https://api.pwnedpasswords.com/range/"
+
+// DefaultBreachCheckTimeout bounds each lookup so an unreachable API cannot stall sign-up
+const DefaultBreachCheckTimeout = 2 * time.Second
+
+// BreachChecker screens passwords against the Have I Been Pwned Pwned Passwords
+// range API. Only the first five hex characters of the password's SHA-1 hash are
+// sent; the matching suffixes are compared locally.
+type BreachChecker struct {
+	url    string
+	client *http.Client
+}
+
+// NewBreachChecker creates a checker whose lookups give up after timeout
+func NewBreachChecker(timeout time.Duration) *BreachChecker {
+	return &BreachChecker{url: pwnedRangeURL, client: &http.Client{Timeout: timeout}}
+}
+
+// BreachCheckerFromEnv returns a checker when PASSWORD_BREACH_CHECK is true, otherwise nil.
+// PASSWORD_BREACH_TIMEOUT bounds each lookup (default 2s).
+func BreachCheckerFromEnv() *BreachChecker {
+	raw := os.Getenv("PASSWORD_BREACH_CHECK")
+	if raw == "" {
+		return nil
+	}
+	enabled, err := strconv.ParseBool(raw)
+	if err != nil {
+		log.Printf("Invalid PASSWORD_BREACH_CHECK=%q, ignoring", raw)
+		return nil
+	}
+	if !enabled {
+		return nil
+	}
+	return NewBreachChecker(durationFromEnv("PASSWORD_BREACH_TIMEOUT", DefaultBreachCheckTimeout))
+}
+
+// Count returns how many times the password appears in known breaches
+func (b *BreachChecker) Count(ctx context.Context, password string) (int, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.url+prefix, nil)
+	if err != nil {
+		return 0, err
+	}
+	// Padding hides the real number of matches from anyone watching response sizes
+	req.Header.Set("Add-Padding", "true")
+	req.Header.Set("User-Agent", "liftoff-backend")
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("breach lookup returned %d", resp.StatusCode)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		candidate, count, ok := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !ok || !strings.EqualFold(candidate, suffix) {
+			continue
+		}
+		// Padding entries have a count of 0
+		return strconv.Atoi(count)
+	}
+	return 0, scanner.Err()
+}
+
+var (
+	breachMu      sync.RWMutex
+	breachChecker *BreachChecker
+)
+
+// SetBreachChecker installs the checker consulted by ValidatePasswordStrong (nil disables screening)
+func SetBreachChecker(checker *BreachChecker) {
+	breachMu.Lock()
+	defer breachMu.Unlock()
+	breachChecker = checker
+}
+
+func getBreachChecker() *BreachChecker {
+	breachMu.RLock()
+	defer breachMu.RUnlock()
+	return breachChecker
+}
+
+// ValidatePasswordStrong applies ValidatePassword and, when a BreachChecker is installed,
+// rejects passwords found in known breaches. It fails open: if the lookup cannot be
+// completed the password is accepted and the failure logged.
+func ValidatePasswordStrong(ctx context.Context, password string) error {
+	if err := ValidatePassword(password); err != nil {
+		return err
+	}
+	checker := getBreachChecker()
+	if checker == nil {
+		return nil
+	}
+	count, err := checker.Count(ctx, password)
+	if err != nil {
+		log.Printf("Password breach check unavailable, skipping: %v", err)
+		return nil
+	}
+	if count > 0 {
+		return ErrPasswordBreached
+	}
+	return nil
+}
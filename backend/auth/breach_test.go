@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestValidatePasswordStrong(t *testing.T) {
+	const breached = "Password1!"
+	sum := sha1.Sum([]byte(breached))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	var gotPrefix, gotPadding string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPrefix = strings.TrimPrefix(r.URL.Path, "/range/")
+		gotPadding = r.Header.Get("Add-Padding")
+		if gotPrefix != hash[:5] {
+			fmt.Fprint(w, "0000000000000000000000000000000000A:0\r\n")
+			return
+		}
+		fmt.Fprintf(w, "0018A45C4D1DEF81644B54AB7F969B88D65:3\r\n%s:52579\r\n00D4F6E8FA6EECAD2A3AA415EEC418D38EC:0\r\n", hash[5:])
+	}))
+	defer srv.Close()
+
+	checker := NewBreachChecker(DefaultBreachCheckTimeout)
+	checker.url = srv.URL + "/range/"
+	SetBreachChecker(checker)
+	defer SetBreachChecker(nil)
+
+	if err := ValidatePasswordStrong(context.Background(), breached); !errors.Is(err, ErrPasswordBreached) {
+		t.Errorf("breached password: err = %v", err)
+	}
+	if gotPrefix != hash[:5] || gotPadding != "true" {
+		t.Errorf("request prefix = %q, padding = %q", gotPrefix, gotPadding)
+	}
+	if err := ValidatePasswordStrong(context.Background(), "Unbreached9!x"); err != nil {
+		t.Errorf("clean password: err = %v", err)
+	}
+	// Basic rules still apply before any lookup
+	if err := ValidatePasswordStrong(context.Background(), "short"); !errors.Is(err, ErrPasswordTooShort) {
+		t.Errorf("short password: err = %v", err)
+	}
+
+	// Fails open when the API is unreachable or erroring
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	})
+	if err := ValidatePasswordStrong(context.Background(), breached); err != nil {
+		t.Errorf("API error: err = %v", err)
+	}
+	srv.Close()
+	if err := ValidatePasswordStrong(context.Background(), breached); err != nil {
+		t.Errorf("API down: err = %v", err)
+	}
+
+	SetBreachChecker(nil)
+	if err := ValidatePasswordStrong(context.Background(), breached); err != nil {
+		t.Errorf("screening disabled: err = %v", err)
+	}
+}
+
+func TestBreachCheckerFromEnv(t *testing.T) {
+	for raw, want := range map[string]bool{"": false, "false": false, "nope": false, "true": true, "1": true} {
+		t.Setenv("PASSWORD_BREACH_CHECK", raw)
+		if got := BreachCheckerFromEnv() != nil; got != want {
+			t.Errorf("PASSWORD_BREACH_CHECK=%q: enabled = %v", raw, got)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package captcha
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	var gotSecret, gotIP string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSecret, gotIP = r.PostFormValue("secret"), r.PostFormValue("remoteip")
+		if r.PostFormValue("response") == "good" {
+			fmt.Fprint(w, `{"success":true}`)
+			return
+		}
+		fmt.Fprint(w, `{"success":false,"error-codes":["invalid-input-response"]}`)
+	}))
+	defer srv.Close()
+
+	v, err := NewVerifier(ProviderTurnstile, "s3cret", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.url = srv.URL
+	ctx := context.Background()
+
+	if err := v.Verify(ctx, "good", "203.0.113.7"); err != nil {
+		t.Errorf("good token: err = %v", err)
+	}
+	if gotSecret != "s3cret" || gotIP != "203.0.113.7" {
+		t.Errorf("secret = %q, remoteip = %q", gotSecret, gotIP)
+	}
+	if err := v.Verify(ctx, "bad", ""); !errors.Is(err, ErrFailed) {
+		t.Errorf("bad token: err = %v", err)
+	}
+	if err := v.Verify(ctx, "", ""); !errors.Is(err, ErrMissing) {
+		t.Errorf("missing token: err = %v", err)
+	}
+
+	// Provider errors are not the client's fault
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	})
+	if err := v.Verify(ctx, "good", ""); err == nil || errors.Is(err, ErrFailed) {
+		t.Errorf("provider down: err = %v", err)
+	}
+
+	// Nothing is enforced until a verifier is installed
+	if err := Verify(ctx, "", ""); err != nil {
+		t.Errorf("disabled: err = %v", err)
+	}
+	SetVerifier(v)
+	defer SetVerifier(nil)
+	if err := Verify(ctx, "", ""); !errors.Is(err, ErrMissing) {
+		t.Errorf("enabled: err = %v", err)
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("CAPTCHA_SECRET", "s3cret")
+	for provider, want := range map[string]bool{"": false, "hcaptcha": true, "Turnstile": true, "recaptcha": false} {
+		t.Setenv("CAPTCHA_PROVIDER", provider)
+		if got := FromEnv() != nil; got != want {
+			t.Errorf("CAPTCHA_PROVIDER=%q: enabled = %v, want %v", provider, got, want)
+		}
+	}
+	t.Setenv("CAPTCHA_PROVIDER", "hcaptcha")
+	t.Setenv("CAPTCHA_SECRET", "")
+	if FromEnv() != nil {
+		t.Error("enabled without a secret")
+	}
+}
@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Minimal CBOR (RFC 8949) decoder covering the subset used by WebAuthn
+// attestation objects and COSE keys: integers, byte/text strings, arrays,
+// maps and the simple values false/true/null. Indefinite lengths, tags and
+// floats are rejected.
+
+var errCBORTruncated = errors.New("cbor: unexpected end of data")
+
+// cborDecode decodes one item from data and returns it with the number of bytes consumed.
+// Integers decode as int64, byte strings as []byte, maps as map[interface{}]interface{}.
+func cborDecode(data []byte) (interface{}, int, error) {
+	return cborDecodeDepth(data, 0)
+}
+
+func cborDecodeDepth(data []byte, depth int) (interface{}, int, error) {
+	if depth > 16 {
+		return nil, 0, errors.New("cbor: nesting too deep")
+	}
+	if len(data) == 0 {
+		return nil, 0, errCBORTruncated
+	}
+	major := data[0] >> 5
+	info := data[0] & 0x1f
+
+	arg, n, err := cborArgument(data, info)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	switch major {
+	case 0:
+		if arg > 1<<63-1 {
+			return nil, 0, errors.New("cbor: integer overflow")
+		}
+		return int64(arg), n, nil
+	case 1:
+		if arg > 1<<63-1 {
+			return nil, 0, errors.New("cbor: integer overflow")
+		}
+		return -1 - int64(arg), n, nil
+	case 2, 3:
+		if uint64(len(data)-n) < arg {
+			return nil, 0, errCBORTruncated
+		}
+		end := n + int(arg)
+		if major == 2 {
+			return append([]byte(nil), data[n:end]...), end, nil
+		}
+		return string(data[n:end]), end, nil
+	case 4:
+		if arg > uint64(len(data)) {
+			return nil, 0, errCBORTruncated
+		}
+		items := make([]interface{}, 0, arg)
+		for i := uint64(0); i < arg; i++ {
+			item, used, err := cborDecodeDepth(data[n:], depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			items = append(items, item)
+			n += used
+		}
+		return items, n, nil
+	case 5:
+		if arg > uint64(len(data)) {
+			return nil, 0, errCBORTruncated
+		}
+		m := make(map[interface{}]interface{}, arg)
+		for i := uint64(0); i < arg; i++ {
+			key, used, err := cborDecodeDepth(data[n:], depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			n += used
+			switch key.(type) {
+			case int64, string:
+			default:
+				return nil, 0, fmt.Errorf("cbor: unsupported map key %T", key)
+			}
+			val, used, err := cborDecodeDepth(data[n:], depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			n += used
+			m[key] = val
+		}
+		return m, n, nil
+	case 7:
+		switch info {
+		case 20:
+			return false, 1, nil
+		case 21:
+			return true, 1, nil
+		case 22:
+			return nil, 1, nil
+		}
+	}
+	return nil, 0, fmt.Errorf("cbor: unsupported item (major %d, info %d)", major, info)
+}
+
+// cborArgument reads the length/value argument that follows an initial byte
+func cborArgument(data []byte, info byte) (uint64, int, error) {
+	switch {
+	case info < 24:
+		return uint64(info), 1, nil
+	case info == 24:
+		if len(data) < 2 {
+			return 0, 0, errCBORTruncated
+		}
+		return uint64(data[1]), 2, nil
+	case info == 25:
+		if len(data) < 3 {
+			return 0, 0, errCBORTruncated
+		}
+		return uint64(binary.BigEndian.Uint16(data[1:3])), 3, nil
+	case info == 26:
+		if len(data) < 5 {
+			return 0, 0, errCBORTruncated
+		}
+		return uint64(binary.BigEndian.Uint32(data[1:5])), 5, nil
+	case info == 27:
+		if len(data) < 9 {
+			return 0, 0, errCBORTruncated
+		}
+		return binary.BigEndian.Uint64(data[1:9]), 9, nil
+	}
+	return 0, 0, fmt.Errorf("cbor: unsupported additional info %d", info)
+}
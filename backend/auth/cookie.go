@@ -0,0 +1,188 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Auth transports selected by AUTH_TRANSPORT
+const (
+	TransportBearer = "bearer" // token returned in the response body and sent as "Authorization: Bearer"
+	TransportCookie = "cookie" // token only in an HttpOnly cookie, out of reach of page scripts
+	TransportBoth   = "both"   // cookie set and token returned; either is accepted (for migrating clients)
+)
+
+const (
+	DefaultSessionCookie = "liftoff_session"
+	DefaultCSRFCookie    = "liftoff_csrf"
+	// CSRFHeader must echo the CSRF cookie on unsafe requests authenticated by cookie
+	CSRFHeader = "X-CSRF-Token"
+	// cookieAuthKey marks requests authenticated by the session cookie
+	cookieAuthKey = "cookie_auth"
+)
+
+// CookieConfig controls cookie-based sessions
+type CookieConfig struct {
+	Transport string
+	Name      string // session cookie; the CSRF cookie is named by CSRFName
+	CSRFName  string
+	Domain    string
+	Path      string
+	Secure    bool
+	SameSite  http.SameSite
+}
+
+// GetCookieConfig loads AUTH_TRANSPORT and the AUTH_COOKIE_* settings from environment
+func GetCookieConfig() CookieConfig {
+	cfg := CookieConfig{
+		Transport: TransportBearer,
+		Name:      DefaultSessionCookie,
+		CSRFName:  DefaultCSRFCookie,
+		Domain:    os.Getenv("AUTH_COOKIE_DOMAIN"),
+		Path:      "/",
+		Secure:    true,
+		SameSite:  http.SameSiteLaxMode,
+	}
+	switch t := strings.ToLower(os.Getenv("AUTH_TRANSPORT")); t {
+	case "", TransportBearer:
+	case TransportCookie, TransportBoth:
+		cfg.Transport = t
+	default:
+		log.Printf("Invalid AUTH_TRANSPORT=%q, using %s", t, TransportBearer)
+	}
+	if name := os.Getenv("AUTH_COOKIE_NAME"); name != "" {
+		cfg.Name = name
+		cfg.CSRFName = name + "_csrf"
+	}
+	if raw := os.Getenv("AUTH_COOKIE_SECURE"); raw != "" {
+		if secure, err := strconv.ParseBool(raw); err == nil {
+			cfg.Secure = secure
+		}
+	}
+	switch strings.ToLower(os.Getenv("AUTH_COOKIE_SAMESITE")) {
+	case "strict":
+		cfg.SameSite = http.SameSiteStrictMode
+	case "none":
+		// Browsers reject SameSite=None cookies that are not Secure
+		cfg.SameSite = http.SameSiteNoneMode
+		cfg.Secure = true
+	}
+	return cfg
+}
+
+// UsesCookie reports whether sign-in sets the session cookie and AuthMiddleware reads it
+func (cfg CookieConfig) UsesCookie() bool {
+	return cfg.Transport == TransportCookie || cfg.Transport == TransportBoth
+}
+
+// ReturnsToken reports whether sign-in responses include the token itself
+func (cfg CookieConfig) ReturnsToken() bool {
+	return cfg.Transport != TransportCookie
+}
+
+// SetSessionCookie stores a newly issued token in the session cookie, with its
+// CSRF token beside it, and returns the CSRF token. It does nothing and returns ""
+// unless the transport uses cookies.
+func SetSessionCookie(c *gin.Context, token string, expiresAt time.Time) string {
+	cfg := GetCookieConfig()
+	if !cfg.UsesCookie() {
+		return ""
+	}
+	csrf := csrfTokenFor(token)
+	maxAge := int(time.Until(expiresAt).Seconds())
+	setCookie(c, cfg, cfg.Name, token, maxAge, true)
+	// Readable by the frontend, which echoes it in the X-CSRF-Token header
+	setCookie(c, cfg, cfg.CSRFName, csrf, maxAge, false)
+	return csrf
+}
+
+// ClearSessionCookie removes the session and CSRF cookies on sign-out
+func ClearSessionCookie(c *gin.Context) {
+	cfg := GetCookieConfig()
+	if !cfg.UsesCookie() {
+		return
+	}
+	setCookie(c, cfg, cfg.Name, "", -1, true)
+	setCookie(c, cfg, cfg.CSRFName, "", -1, false)
+}
+
+func setCookie(c *gin.Context, cfg CookieConfig, name, value string, maxAge int, httpOnly bool) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     cfg.Path,
+		Domain:   cfg.Domain,
+		MaxAge:   maxAge,
+		Secure:   cfg.Secure,
+		HttpOnly: httpOnly,
+		SameSite: cfg.SameSite,
+	})
+}
+
+// GetCSRFToken returns the CSRF token of a request authenticated by the session cookie,
+// so a client that lost it (e.g. after a reload) can fetch it again from /auth/me
+func GetCSRFToken(c *gin.Context) string {
+	if !c.GetBool(cookieAuthKey) {
+		return ""
+	}
+	csrf, _ := c.Cookie(GetCookieConfig().CSRFName)
+	return csrf
+}
+
+// sessionCookieToken returns the token from the session cookie, or "" when cookies
+// are not in use or the request has none
+func sessionCookieToken(c *gin.Context, cfg CookieConfig) string {
+	if !cfg.UsesCookie() {
+		return ""
+	}
+	token, err := c.Cookie(cfg.Name)
+	if err != nil {
+		return ""
+	}
+	return token
+}
+
+// csrfTokenFor derives the CSRF token of a session token with an HMAC keyed by the JWT
+// secret. Binding it to the session stops a sibling subdomain that can plant cookies
+// from pairing its own CSRF cookie and header (a signed double-submit cookie).
+func csrfTokenFor(token string) string {
+	mac := hmac.New(sha256.New, GetTokenConfig().Secret)
+	mac.Write([]byte("csrf:" + token))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// checkCSRF requires unsafe requests authenticated by cookie to echo the CSRF cookie
+// in the X-CSRF-Token header, which other sites cannot read or set, and the token
+// to be the one derived from the session token
+func checkCSRF(c *gin.Context, cfg CookieConfig, token string) bool {
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	expected, err := c.Cookie(cfg.CSRFName)
+	got := c.GetHeader(CSRFHeader)
+	return err == nil && expected != "" && subtle.ConstantTimeCompare([]byte(expected), []byte(got)) == 1 &&
+		hmac.Equal([]byte(got), []byte(csrfTokenFor(token)))
+}
+
+// RequestToken returns the JWT a request carries, from the Bearer header or the session
+// cookie, without validating it. For callers that run before AuthMiddleware.
+func RequestToken(c *gin.Context) string {
+	if header := c.GetHeader("Authorization"); header != "" {
+		if strings.HasPrefix(header, "Bearer ") {
+			return strings.TrimPrefix(header, "Bearer ")
+		}
+		return ""
+	}
+	return sessionCookieToken(c, GetCookieConfig())
+}
@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// cookieRouter issues a session cookie on /login and serves GET and POST /test behind AuthMiddleware
+func cookieRouter(t *testing.T, transport string) *gin.Engine {
+	t.Setenv("JWT_SECRET", "test-secret")
+	t.Setenv("AUTH_TRANSPORT", transport)
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/login", func(c *gin.Context) {
+		token, expiresAt, err := GenerateToken("user-123", "test@example.com", false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.JSON(http.StatusOK, gin.H{"csrfToken": SetSessionCookie(c, token, expiresAt)})
+	})
+	ok := func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"user_id": GetUserID(c), "csrf": GetCSRFToken(c)})
+	}
+	r.GET("/test", AuthMiddleware(), ok)
+	r.POST("/test", AuthMiddleware(), ok)
+	return r
+}
+
+func sessionCookies(t *testing.T, r *gin.Engine) []*http.Cookie {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/login", nil))
+	return w.Result().Cookies()
+}
+
+func TestSessionCookie_Attributes(t *testing.T) {
+	r := cookieRouter(t, TransportCookie)
+	cookies := sessionCookies(t, r)
+	if len(cookies) != 2 {
+		t.Fatalf("got %d cookies, want session and CSRF", len(cookies))
+	}
+	for _, c := range cookies {
+		switch c.Name {
+		case DefaultSessionCookie:
+			if !c.HttpOnly || !c.Secure || c.SameSite != http.SameSiteLaxMode {
+				t.Errorf("session cookie %+v should be HttpOnly, Secure and SameSite=Lax", c)
+			}
+		case DefaultCSRFCookie:
+			if c.HttpOnly {
+				t.Error("CSRF cookie must be readable by the frontend")
+			}
+		default:
+			t.Errorf("unexpected cookie %q", c.Name)
+		}
+		if c.MaxAge <= 0 {
+			t.Errorf("cookie %q has MaxAge %d", c.Name, c.MaxAge)
+		}
+	}
+}
+
+func TestAuthMiddleware_Cookie(t *testing.T) {
+	r := cookieRouter(t, TransportCookie)
+	cookies := sessionCookies(t, r)
+	var csrf string
+	for _, c := range cookies {
+		if c.Name == DefaultCSRFCookie {
+			csrf = c.Value
+		}
+	}
+
+	send := func(method, header string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/test", nil)
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		if header != "" {
+			req.Header.Set(CSRFHeader, header)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := send("GET", ""); w.Code != http.StatusOK || !contains(w.Body.String(), csrf) {
+		t.Errorf("GET with cookie: got %d %s, want 200 with the CSRF token", w.Code, w.Body.String())
+	}
+	if w := send("POST", ""); w.Code != http.StatusForbidden {
+		t.Errorf("POST without CSRF header: got %d, want 403", w.Code)
+	}
+	if w := send("POST", "wrong"); w.Code != http.StatusForbidden {
+		t.Errorf("POST with wrong CSRF header: got %d, want 403", w.Code)
+	}
+	if w := send("POST", csrf); w.Code != http.StatusOK {
+		t.Errorf("POST with CSRF header: got %d, want 200", w.Code)
+	}
+
+	// A planted CSRF cookie with a matching header is rejected: the token is bound to the session
+	for _, c := range cookies {
+		if c.Name == DefaultCSRFCookie {
+			c.Value = "planted"
+		}
+	}
+	if w := send("POST", "planted"); w.Code != http.StatusForbidden {
+		t.Errorf("POST with planted CSRF cookie: got %d, want 403", w.Code)
+	}
+}
+
+func TestAuthMiddleware_BearerIgnoresCookie(t *testing.T) {
+	r := cookieRouter(t, TransportCookie)
+	cookies := sessionCookies(t, r)
+
+	t.Setenv("AUTH_TRANSPORT", TransportBearer)
+	req := httptest.NewRequest("GET", "/test", nil)
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("bearer transport with only a cookie: got %d, want 401", w.Code)
+	}
+}
+
+func TestGetCookieConfig(t *testing.T) {
+	t.Setenv("AUTH_TRANSPORT", "both")
+	t.Setenv("AUTH_COOKIE_NAME", "sid")
+	t.Setenv("AUTH_COOKIE_SECURE", "false")
+	t.Setenv("AUTH_COOKIE_SAMESITE", "none")
+	cfg := GetCookieConfig()
+	if !cfg.UsesCookie() || !cfg.ReturnsToken() {
+		t.Errorf("both transport: UsesCookie=%v ReturnsToken=%v", cfg.UsesCookie(), cfg.ReturnsToken())
+	}
+	if cfg.Name != "sid" || cfg.CSRFName != "sid_csrf" {
+		t.Errorf("got cookie names %q/%q", cfg.Name, cfg.CSRFName)
+	}
+	if !cfg.Secure || cfg.SameSite != http.SameSiteNoneMode {
+		t.Error("SameSite=None must force Secure")
+	}
+
+	t.Setenv("AUTH_TRANSPORT", "bogus")
+	if cfg := GetCookieConfig(); cfg.UsesCookie() || !cfg.ReturnsToken() {
+		t.Error("invalid AUTH_TRANSPORT should fall back to bearer")
+	}
+}
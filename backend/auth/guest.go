@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GuestKey is set when the request was made with a guest token
+const GuestKey = "guest"
+
+// DeviceIDHeader carries the device ID a guest token was issued for
+const DeviceIDHeader = "X-Device-ID"
+
+// DefaultGuestTTL is how long a guest token lasts and how long an idle guest account
+// is kept; GUEST_TTL overrides it
+const DefaultGuestTTL = 30 * 24 * time.Hour
+
+// GuestTTL returns how long guest tokens and idle guest accounts last
+func GuestTTL() time.Duration {
+	return durationFromEnv("GUEST_TTL", DefaultGuestTTL)
+}
+
+// GenerateGuestToken issues a token for a provisional user that is only accepted
+// alongside the device ID whose hash is deviceHash
+func GenerateGuestToken(userID, email, deviceHash string) (string, time.Time, error) {
+	expiry := time.Now().Add(GuestTTL())
+	claims := Claims{
+		UserID:           userID,
+		Email:            email,
+		Device:           deviceHash,
+		RegisteredClaims: GetTokenConfig().registeredClaims(expiry),
+	}
+
+	keys, err := GetKeySet()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	tokenString, err := keys.Sign(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return tokenString, expiry, nil
+}
+
+// IsGuest reports whether the request was made with a guest token (call after AuthMiddleware)
+func IsGuest(c *gin.Context) bool {
+	return c.GetBool(GuestKey)
+}
+
+// RegisteredMiddleware rejects guest tokens on account-level routes, such as
+// API keys and email changes, that need a registered account
+func RegisteredMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if IsGuest(c) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Register to use this feature"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,164 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Password hashing algorithms
+const (
+	HashBcrypt   = "bcrypt"
+	HashArgon2id = "argon2id"
+)
+
+// Default argon2id parameters, per the OWASP password storage cheat sheet
+const (
+	DefaultArgon2Time    = 2
+	DefaultArgon2Memory  = 19 * 1024 // KiB
+	DefaultArgon2Threads = 1
+
+	argon2SaltLen = 16
+	argon2KeyLen  = 32
+)
+
+var errInvalidArgon2Hash = errors.New("invalid argon2id hash")
+
+// HashConfig selects the algorithm and work factors for new password hashes.
+// Existing hashes of either algorithm are still accepted.
+type HashConfig struct {
+	Algorithm     string // HashBcrypt or HashArgon2id
+	BcryptCost    int
+	Argon2Time    uint32
+	Argon2Memory  uint32 // KiB
+	Argon2Threads uint8
+}
+
+// GetHashConfig loads password hashing settings from environment: PASSWORD_HASH
+// (bcrypt or argon2id, default bcrypt), BCRYPT_COST, and ARGON2_TIME,
+// ARGON2_MEMORY_KIB and ARGON2_THREADS
+func GetHashConfig() HashConfig {
+	cfg := HashConfig{
+		Algorithm:     HashBcrypt,
+		BcryptCost:    intFromEnv("BCRYPT_COST", bcrypt.DefaultCost),
+		Argon2Time:    DefaultArgon2Time,
+		Argon2Memory:  DefaultArgon2Memory,
+		Argon2Threads: DefaultArgon2Threads,
+	}
+	switch raw := strings.ToLower(strings.TrimSpace(os.Getenv("PASSWORD_HASH"))); raw {
+	case "", HashBcrypt:
+	case HashArgon2id:
+		cfg.Algorithm = HashArgon2id
+	default:
+		log.Printf("Invalid PASSWORD_HASH=%q, using %s", raw, HashBcrypt)
+	}
+	if cfg.BcryptCost < bcrypt.MinCost || cfg.BcryptCost > bcrypt.MaxCost {
+		log.Printf("Invalid BCRYPT_COST=%d, using %d", cfg.BcryptCost, bcrypt.DefaultCost)
+		cfg.BcryptCost = bcrypt.DefaultCost
+	}
+	if t := intFromEnv("ARGON2_TIME", DefaultArgon2Time); t >= 1 {
+		cfg.Argon2Time = uint32(t)
+	}
+	if p := intFromEnv("ARGON2_THREADS", DefaultArgon2Threads); p >= 1 && p <= 255 {
+		cfg.Argon2Threads = uint8(p)
+	}
+	// argon2 needs at least 8 KiB per thread
+	if m := intFromEnv("ARGON2_MEMORY_KIB", DefaultArgon2Memory); m >= 8*int(cfg.Argon2Threads) && m <= 4*1024*1024 {
+		cfg.Argon2Memory = uint32(m)
+	}
+	return cfg
+}
+
+// HashPassword hashes a password with the configured algorithm
+func HashPassword(password string) (string, error) {
+	return GetHashConfig().Hash(password)
+}
+
+// Hash hashes a password with this configuration. argon2id hashes use the PHC
+// string format: $argon2id$v=19$m=<KiB>,t=<time>,p=<threads>$<salt>$<key>.
+func (cfg HashConfig) Hash(password string) (string, error) {
+	if cfg.Algorithm != HashArgon2id {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), cfg.BcryptCost)
+		if err != nil {
+			return "", err
+		}
+		return string(hash), nil
+	}
+
+	salt := make([]byte, argon2SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, cfg.Argon2Time, cfg.Argon2Memory, cfg.Argon2Threads, argon2KeyLen)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version,
+		cfg.Argon2Memory, cfg.Argon2Time, cfg.Argon2Threads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// CheckPassword verifies a password against a bcrypt or argon2id hash
+func CheckPassword(password, hash string) bool {
+	if !strings.HasPrefix(hash, "$argon2id$") {
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+	}
+	params, salt, key, err := parseArgon2Hash(hash)
+	if err != nil {
+		return false
+	}
+	got := argon2.IDKey([]byte(password), salt, params.Argon2Time, params.Argon2Memory, params.Argon2Threads, uint32(len(key)))
+	return subtle.ConstantTimeCompare(got, key) == 1
+}
+
+// NeedsRehash reports whether a stored hash uses another algorithm or weaker
+// parameters than this configuration, so it should be replaced at next sign-in
+func (cfg HashConfig) NeedsRehash(hash string) bool {
+	if hash == "" {
+		return false
+	}
+	if strings.HasPrefix(hash, "$argon2id$") {
+		if cfg.Algorithm != HashArgon2id {
+			return true
+		}
+		params, _, _, err := parseArgon2Hash(hash)
+		return err != nil || params.Argon2Time < cfg.Argon2Time ||
+			params.Argon2Memory < cfg.Argon2Memory || params.Argon2Threads < cfg.Argon2Threads
+	}
+	if cfg.Algorithm != HashBcrypt {
+		return true
+	}
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost < cfg.BcryptCost
+}
+
+func parseArgon2Hash(hash string) (HashConfig, []byte, []byte, error) {
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return HashConfig{}, nil, nil, errInvalidArgon2Hash
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return HashConfig{}, nil, nil, errInvalidArgon2Hash
+	}
+	params := HashConfig{Algorithm: HashArgon2id}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Argon2Memory, &params.Argon2Time, &params.Argon2Threads); err != nil ||
+		params.Argon2Time < 1 || params.Argon2Threads < 1 {
+		return HashConfig{}, nil, nil, errInvalidArgon2Hash
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return HashConfig{}, nil, nil, errInvalidArgon2Hash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return HashConfig{}, nil, nil, errInvalidArgon2Hash
+	}
+	return params, salt, key, nil
+}
@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHashConfig(t *testing.T) {
+	bcryptCfg := HashConfig{Algorithm: HashBcrypt, BcryptCost: 4}
+	argonCfg := HashConfig{Algorithm: HashArgon2id, Argon2Time: 1, Argon2Memory: 1024, Argon2Threads: 1}
+
+	for _, cfg := range []HashConfig{bcryptCfg, argonCfg} {
+		hash, err := cfg.Hash("Password1!")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !CheckPassword("Password1!", hash) || CheckPassword("Password2!", hash) {
+			t.Errorf("%s: CheckPassword mismatch for %q", cfg.Algorithm, hash)
+		}
+		if cfg.NeedsRehash(hash) {
+			t.Errorf("%s: fresh hash needs rehash", cfg.Algorithm)
+		}
+	}
+
+	argonHash, _ := argonCfg.Hash("Password1!")
+	if !strings.HasPrefix(argonHash, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Errorf("argon2id hash = %q", argonHash)
+	}
+	bcryptHash, _ := bcryptCfg.Hash("Password1!")
+
+	stronger := argonCfg
+	stronger.Argon2Memory = 2048
+	if !stronger.NeedsRehash(argonHash) || !argonCfg.NeedsRehash(bcryptHash) || !bcryptCfg.NeedsRehash(argonHash) {
+		t.Error("weaker or other-algorithm hash not flagged for rehash")
+	}
+	if !(HashConfig{Algorithm: HashBcrypt, BcryptCost: 5}).NeedsRehash(bcryptHash) {
+		t.Error("lower bcrypt cost not flagged for rehash")
+	}
+	if (HashConfig{Algorithm: HashBcrypt, BcryptCost: 4}).NeedsRehash(strings.Replace(bcryptHash, "$04$", "$05$", 1)) {
+		t.Error("higher bcrypt cost flagged for rehash")
+	}
+
+	for _, bad := range []string{"", "$argon2id$v=19$m=1024,t=1,p=0$c2FsdA$a2V5", "$argon2id$v=19$garbage"} {
+		if CheckPassword("Password1!", bad) {
+			t.Errorf("CheckPassword accepted %q", bad)
+		}
+	}
+}
+
+func TestGetHashConfig(t *testing.T) {
+	t.Setenv("PASSWORD_HASH", "Argon2id")
+	t.Setenv("BCRYPT_COST", "99")
+	t.Setenv("ARGON2_THREADS", "4")
+	cfg := GetHashConfig()
+	if cfg.Algorithm != HashArgon2id || cfg.BcryptCost != 10 || cfg.Argon2Threads != 4 || cfg.Argon2Memory != DefaultArgon2Memory {
+		t.Errorf("config = %+v", cfg)
+	}
+}
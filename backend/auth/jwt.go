@@ -4,9 +4,11 @@ import (
 	"errors"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 var (
@@ -14,21 +16,33 @@ var (
 )
 
 const (
-	DefaultTokenExpiryMinutes    = 15
+	DefaultTokenExpiryMinutes   = 15
 	DefaultRememberMeExpiryDays = 30
 )
 
 type Claims struct {
 	UserID string `json:"user_id"`
 	Email  string `json:"email"`
+	// ImpersonatedBy is the admin a support token was issued to (see GenerateImpersonationToken)
+	ImpersonatedBy string `json:"impersonated_by,omitempty"`
+	// Device is the hashed device ID a guest token is bound to (see GenerateGuestToken)
+	Device string `json:"device,omitempty"`
+	// Scope limits what the token may do, e.g. ScopeRead (see GenerateScopedToken)
+	Scope string `json:"scope,omitempty"`
+	// ReauthFor marks a step-up token and names the session (jti) it elevates (see GenerateReauthToken)
+	ReauthFor string `json:"reauth_for,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// TokenConfig holds JWT configuration
+// TokenConfig holds JWT configuration. Signing keys beyond the HS256 secret are in KeySet.
+// Issuer and Audience, when set, are written to every token and required on validation,
+// so environments sharing a signing key still reject each other's tokens.
 type TokenConfig struct {
-	Secret             []byte
-	ExpiryMinutes      int
+	Secret               []byte
+	ExpiryMinutes        int
 	RememberMeExpiryDays int
+	Issuer               string
+	Audience             string
 }
 
 // GetTokenConfig loads JWT config from environment
@@ -52,9 +66,25 @@ func GetTokenConfig() TokenConfig {
 		Secret:               []byte(secret),
 		ExpiryMinutes:        expiryMinutes,
 		RememberMeExpiryDays: rememberMeDays,
+		Issuer:               strings.TrimSpace(os.Getenv("JWT_ISSUER")),
+		Audience:             strings.TrimSpace(os.Getenv("JWT_AUDIENCE")),
 	}
 }
 
+// registeredClaims returns the standard claims for a new token expiring at expiry
+func (c TokenConfig) registeredClaims(expiry time.Time) jwt.RegisteredClaims {
+	claims := jwt.RegisteredClaims{
+		ID:        uuid.NewString(),
+		Issuer:    c.Issuer,
+		ExpiresAt: jwt.NewNumericDate(expiry),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+	}
+	if c.Audience != "" {
+		claims.Audience = jwt.ClaimStrings{c.Audience}
+	}
+	return claims
+}
+
 // GenerateToken creates a JWT for the user
 func GenerateToken(userID, email string, rememberMe bool) (string, time.Time, error) {
 	config := GetTokenConfig()
@@ -67,16 +97,16 @@ func GenerateToken(userID, email string, rememberMe bool) (string, time.Time, er
 	}
 
 	claims := Claims{
-		UserID: userID,
-		Email:  email,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expiry),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-		},
+		UserID:           userID,
+		Email:            email,
+		RegisteredClaims: config.registeredClaims(expiry),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(config.Secret)
+	keys, err := GetKeySet()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	tokenString, err := keys.Sign(claims)
 	if err != nil {
 		return "", time.Time{}, err
 	}
@@ -86,11 +116,20 @@ func GenerateToken(userID, email string, rememberMe bool) (string, time.Time, er
 
 // ValidateToken parses and validates a JWT, returning the claims
 func ValidateToken(tokenString string) (*Claims, error) {
-	config := GetTokenConfig()
+	keys, err := GetKeySet()
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
 
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return config.Secret, nil
-	})
+	opts := []jwt.ParserOption{jwt.WithValidMethods([]string{"HS256", "RS256", "EdDSA"})}
+	config := GetTokenConfig()
+	if config.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(config.Issuer))
+	}
+	if config.Audience != "" {
+		opts = append(opts, jwt.WithAudience(config.Audience))
+	}
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, keys.keyFunc, opts...)
 
 	if err != nil {
 		return nil, ErrInvalidToken
@@ -67,3 +67,41 @@ func TestGenerateToken_RememberMe(t *testing.T) {
 		t.Errorf("RememberMe token should have longer expiry: short=%v, long=%v", diffShort, diffLong)
 	}
 }
+
+func TestValidateToken_IssuerAudience(t *testing.T) {
+	t.Setenv("JWT_SECRET", "shared-secret")
+	t.Setenv("JWT_ISSUER", "https://staging.liftoff.example")
+	t.Setenv("JWT_AUDIENCE", "liftoff-staging")
+
+	staging, _, err := GenerateToken("u1", "e@e.com", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := ValidateToken(staging)
+	if err != nil {
+		t.Fatalf("same environment: %v", err)
+	}
+	if claims.Issuer != "https://staging.liftoff.example" || len(claims.Audience) != 1 || claims.Audience[0] != "liftoff-staging" {
+		t.Errorf("claims = %+v", claims.RegisteredClaims)
+	}
+
+	// Production shares the key but not the issuer or audience
+	t.Setenv("JWT_ISSUER", "https://liftoff.example")
+	if _, err := ValidateToken(staging); err != ErrInvalidToken {
+		t.Errorf("other issuer: err = %v", err)
+	}
+	t.Setenv("JWT_ISSUER", "https://staging.liftoff.example")
+	t.Setenv("JWT_AUDIENCE", "liftoff-production")
+	if _, err := ValidateToken(staging); err != ErrInvalidToken {
+		t.Errorf("other audience: err = %v", err)
+	}
+
+	// Tokens issued before the claims were configured are rejected once they are
+	t.Setenv("JWT_ISSUER", "")
+	t.Setenv("JWT_AUDIENCE", "")
+	legacy, _, _ := GenerateToken("u1", "e@e.com", false)
+	t.Setenv("JWT_ISSUER", "https://staging.liftoff.example")
+	if _, err := ValidateToken(legacy); err != ErrInvalidToken {
+		t.Errorf("token without iss: err = %v", err)
+	}
+}
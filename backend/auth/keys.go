@@ -0,0 +1,222 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrUnsupportedKey is returned for PEM keys that are neither RSA nor Ed25519
+var ErrUnsupportedKey = errors.New("unsupported JWT key type (want RSA or Ed25519)")
+
+// verifyKey is a key tokens may be validated against
+type verifyKey struct {
+	method jwt.SigningMethod
+	key    interface{}
+}
+
+// KeySet holds the key new tokens are signed with and every key tokens are accepted from.
+//
+// By default tokens are HS256-signed with JWT_SECRET. Setting JWT_SIGNING_KEY (PEM) or
+// JWT_SIGNING_KEY_FILE switches to RS256 or EdDSA depending on the key type. Every token
+// carries a kid header; to rotate, move the old key to JWT_PREVIOUS_KEY_FILES (comma-
+// separated PEM paths, optionally "kid=path") or JWT_PREVIOUS_SECRETS and tokens it
+// signed stay valid until they expire.
+type KeySet struct {
+	SigningKID string
+	signMethod jwt.SigningMethod
+	signKey    interface{}
+	verify     map[string]verifyKey
+}
+
+var (
+	keySetMu    sync.Mutex
+	keySetEnv   string
+	keySetCache *KeySet
+)
+
+var keyEnvVars = []string{
+	"JWT_SECRET", "JWT_SIGNING_KEY", "JWT_SIGNING_KEY_FILE", "JWT_KEY_ID",
+	"JWT_PREVIOUS_KEY_FILES", "JWT_PREVIOUS_SECRETS",
+}
+
+// GetKeySet loads the signing keys from environment, reusing the parsed keys until the
+// environment changes
+func GetKeySet() (*KeySet, error) {
+	var env strings.Builder
+	for _, name := range keyEnvVars {
+		env.WriteString(os.Getenv(name))
+		env.WriteByte(0)
+	}
+
+	keySetMu.Lock()
+	defer keySetMu.Unlock()
+	if keySetCache != nil && keySetEnv == env.String() {
+		return keySetCache, nil
+	}
+	ks, err := loadKeySet()
+	if err != nil {
+		return nil, err
+	}
+	keySetCache, keySetEnv = ks, env.String()
+	return ks, nil
+}
+
+func loadKeySet() (*KeySet, error) {
+	ks := &KeySet{verify: map[string]verifyKey{}}
+
+	keyPEM := strings.ReplaceAll(os.Getenv("JWT_SIGNING_KEY"), `\n`, "\n")
+	if keyPEM == "" {
+		if path := os.Getenv("JWT_SIGNING_KEY_FILE"); path != "" {
+			b, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read JWT signing key: %w", err)
+			}
+			keyPEM = string(b)
+		}
+	}
+
+	if keyPEM != "" {
+		method, priv, pub, err := parsePEMKey([]byte(keyPEM))
+		if err != nil {
+			return nil, err
+		}
+		if priv == nil {
+			return nil, errors.New("JWT signing key must be a private key")
+		}
+		kid, err := publicKeyID(pub)
+		if err != nil {
+			return nil, err
+		}
+		ks.SigningKID, ks.signMethod, ks.signKey = kid, method, priv
+		ks.verify[kid] = verifyKey{method: method, key: pub}
+	} else {
+		secret := GetTokenConfig().Secret
+		kid := secretKeyID(secret)
+		ks.SigningKID, ks.signMethod, ks.signKey = kid, jwt.SigningMethodHS256, secret
+		ks.verify[kid] = verifyKey{method: jwt.SigningMethodHS256, key: secret}
+	}
+
+	if kid := os.Getenv("JWT_KEY_ID"); kid != "" {
+		ks.verify[kid] = ks.verify[ks.SigningKID]
+		delete(ks.verify, ks.SigningKID)
+		ks.SigningKID = kid
+	}
+
+	for _, entry := range splitList(os.Getenv("JWT_PREVIOUS_KEY_FILES")) {
+		kid, path := "", entry
+		if i := strings.Index(entry, "="); i > 0 {
+			kid, path = entry[:i], entry[i+1:]
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read previous JWT key: %w", err)
+		}
+		method, _, pub, err := parsePEMKey(b)
+		if err != nil {
+			return nil, fmt.Errorf("previous JWT key %s: %w", path, err)
+		}
+		if kid == "" {
+			if kid, err = publicKeyID(pub); err != nil {
+				return nil, err
+			}
+		}
+		if _, exists := ks.verify[kid]; !exists {
+			ks.verify[kid] = verifyKey{method: method, key: pub}
+		}
+	}
+	for _, secret := range splitList(os.Getenv("JWT_PREVIOUS_SECRETS")) {
+		kid := secretKeyID([]byte(secret))
+		if _, exists := ks.verify[kid]; !exists {
+			ks.verify[kid] = verifyKey{method: jwt.SigningMethodHS256, key: []byte(secret)}
+		}
+	}
+	return ks, nil
+}
+
+// Sign signs claims with the current key, setting the kid header
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.signMethod, claims)
+	token.Header["kid"] = ks.SigningKID
+	return token.SignedString(ks.signKey)
+}
+
+// keyFunc picks the verification key by kid. Tokens issued before kids were added
+// are only accepted from HS256 keys; the parser tries each of them in turn.
+func (ks *KeySet) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		if token.Method != jwt.SigningMethodHS256 {
+			return nil, ErrInvalidToken
+		}
+		var keys jwt.VerificationKeySet
+		for _, k := range ks.verify {
+			if k.method == jwt.SigningMethodHS256 {
+				keys.Keys = append(keys.Keys, k.key)
+			}
+		}
+		return keys, nil
+	}
+	k, ok := ks.verify[kid]
+	if !ok || k.method.Alg() != token.Method.Alg() {
+		return nil, ErrInvalidToken
+	}
+	return k.key, nil
+}
+
+// parsePEMKey reads an RSA or Ed25519 key, private or public. priv is nil for public keys.
+func parsePEMKey(pemBytes []byte) (jwt.SigningMethod, crypto.PrivateKey, crypto.PublicKey, error) {
+	if key, err := jwt.ParseRSAPrivateKeyFromPEM(pemBytes); err == nil {
+		return jwt.SigningMethodRS256, key, &key.PublicKey, nil
+	}
+	if key, err := jwt.ParseEdPrivateKeyFromPEM(pemBytes); err == nil {
+		return jwt.SigningMethodEdDSA, key, key.(ed25519.PrivateKey).Public(), nil
+	}
+	if key, err := jwt.ParseRSAPublicKeyFromPEM(pemBytes); err == nil {
+		return jwt.SigningMethodRS256, nil, key, nil
+	}
+	if key, err := jwt.ParseEdPublicKeyFromPEM(pemBytes); err == nil {
+		return jwt.SigningMethodEdDSA, nil, key, nil
+	}
+	return nil, nil, nil, ErrUnsupportedKey
+}
+
+// publicKeyID derives a stable kid from the public key so rotated keys need no manual IDs
+func publicKeyID(pub crypto.PublicKey) (string, error) {
+	switch pub.(type) {
+	case *rsa.PublicKey, ed25519.PublicKey:
+	default:
+		return "", ErrUnsupportedKey
+	}
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode JWT public key: %w", err)
+	}
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+func secretKeyID(secret []byte) string {
+	sum := sha256.Sum256(append([]byte("hs256:"), secret...))
+	return "hs-" + hex.EncodeToString(sum[:8])
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func writeKeyPEM(t *testing.T, key interface{}) string {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "key.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestKeySet_AsymmetricRotation(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	rsaPath, edPath := writeKeyPEM(t, rsaKey), writeKeyPEM(t, edKey)
+
+	t.Setenv("JWT_SIGNING_KEY_FILE", rsaPath)
+	oldToken, _, err := GenerateToken("u1", "a@b.com", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, _, _ := jwt.NewParser().ParseUnverified(oldToken, &Claims{})
+	if parsed.Method.Alg() != "RS256" || parsed.Header["kid"] == "" {
+		t.Fatalf("header = %v", parsed.Header)
+	}
+
+	// Rotate to Ed25519, keeping the RSA key for validation only
+	t.Setenv("JWT_SIGNING_KEY_FILE", edPath)
+	t.Setenv("JWT_PREVIOUS_KEY_FILES", rsaPath)
+	newToken, _, err := GenerateToken("u1", "a@b.com", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed, _, _ = jwt.NewParser().ParseUnverified(newToken, &Claims{}); parsed.Method.Alg() != "EdDSA" {
+		t.Errorf("new token alg = %s", parsed.Method.Alg())
+	}
+	for name, token := range map[string]string{"old": oldToken, "new": newToken} {
+		if _, err := ValidateToken(token); err != nil {
+			t.Errorf("%s token rejected: %v", name, err)
+		}
+	}
+
+	// Retiring the RSA key invalidates its tokens
+	t.Setenv("JWT_PREVIOUS_KEY_FILES", "")
+	if _, err := ValidateToken(oldToken); err != ErrInvalidToken {
+		t.Errorf("retired key: err = %v", err)
+	}
+}
+
+func TestKeySet_SecretRotation(t *testing.T) {
+	t.Setenv("JWT_SECRET", "old-secret")
+	withKID, _, _ := GenerateToken("u1", "a@b.com", false)
+
+	// Tokens from before kids were added carry no kid header
+	legacy, err := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
+		UserID:           "u1",
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+	}).SignedString([]byte("old-secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("JWT_SECRET", "new-secret")
+	t.Setenv("JWT_PREVIOUS_SECRETS", "old-secret")
+	for name, token := range map[string]string{"kid": withKID, "legacy": legacy} {
+		if _, err := ValidateToken(token); err != nil {
+			t.Errorf("%s token rejected after rotation: %v", name, err)
+		}
+	}
+
+	t.Setenv("JWT_PREVIOUS_SECRETS", "")
+	for name, token := range map[string]string{"kid": withKID, "legacy": legacy} {
+		if _, err := ValidateToken(token); err != ErrInvalidToken {
+			t.Errorf("%s token accepted after secret retired", name)
+		}
+	}
+}
+
+func TestKeySet_InvalidKey(t *testing.T) {
+	t.Setenv("JWT_SIGNING_KEY", "not a pem key")
+	if _, err := GetKeySet(); err == nil {
+		t.Error("expected error for invalid signing key")
+	}
+}
@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	DefaultMaxAccountFailures = 5
+	DefaultMaxIPFailures      = 20
+	DefaultBaseLockout        = time.Minute
+	DefaultMaxLockout         = time.Hour
+	DefaultFailureWindow      = time.Hour
+)
+
+// LockoutConfig controls login throttling. Once an account or IP reaches its failure
+// threshold it is locked for BaseLockout, doubling with every further failure up to MaxLockout.
+// Failures are forgotten after FailureWindow without another failure.
+type LockoutConfig struct {
+	MaxAccountFailures int // 0 disables per-account lockout
+	MaxIPFailures      int // 0 disables per-IP throttling
+	BaseLockout        time.Duration
+	MaxLockout         time.Duration
+	FailureWindow      time.Duration
+}
+
+// GetLockoutConfig loads login throttling settings from environment
+func GetLockoutConfig() LockoutConfig {
+	return LockoutConfig{
+		MaxAccountFailures: intFromEnv("LOGIN_MAX_FAILURES", DefaultMaxAccountFailures),
+		MaxIPFailures:      intFromEnv("LOGIN_IP_MAX_FAILURES", DefaultMaxIPFailures),
+		BaseLockout:        durationFromEnv("LOGIN_LOCKOUT_BASE", DefaultBaseLockout),
+		MaxLockout:         durationFromEnv("LOGIN_LOCKOUT_MAX", DefaultMaxLockout),
+		FailureWindow:      durationFromEnv("LOGIN_FAILURE_WINDOW", DefaultFailureWindow),
+	}
+}
+
+// LockoutDuration returns how long to lock after the given number of consecutive failures
+func (cfg LockoutConfig) LockoutDuration(failures, threshold int) time.Duration {
+	if threshold <= 0 || failures < threshold {
+		return 0
+	}
+	d := cfg.BaseLockout
+	for i := threshold; i < failures && d < cfg.MaxLockout; i++ {
+		d *= 2
+	}
+	if d > cfg.MaxLockout {
+		d = cfg.MaxLockout
+	}
+	return d
+}
+
+const (
+	DefaultResetEmailCooldown   = time.Minute
+	DefaultMaxResetIPRequests   = 10
+	DefaultResetIPRequestWindow = time.Hour
+)
+
+// ResetThrottleConfig limits forgot-password requests. Each address gets at most one
+// reset email per EmailCooldown, and each IP at most MaxIPRequests per IPWindow.
+type ResetThrottleConfig struct {
+	EmailCooldown time.Duration
+	MaxIPRequests int // 0 disables per-IP throttling
+	IPWindow      time.Duration
+}
+
+// GetResetThrottleConfig loads forgot-password throttling settings from environment
+func GetResetThrottleConfig() ResetThrottleConfig {
+	return ResetThrottleConfig{
+		EmailCooldown: durationFromEnv("PASSWORD_RESET_COOLDOWN", DefaultResetEmailCooldown),
+		MaxIPRequests: intFromEnv("PASSWORD_RESET_IP_MAX", DefaultMaxResetIPRequests),
+		IPWindow:      durationFromEnv("PASSWORD_RESET_IP_WINDOW", DefaultResetIPRequestWindow),
+	}
+}
+
+func intFromEnv(key string, def int) int {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		log.Printf("Invalid %s=%q, using %d", key, raw, def)
+		return def
+	}
+	return n
+}
+
+func durationFromEnv(key string, def time.Duration) time.Duration {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		log.Printf("Invalid %s=%q, using %v", key, raw, def)
+		return def
+	}
+	return d
+}
@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLockoutDuration(t *testing.T) {
+	cfg := LockoutConfig{BaseLockout: time.Minute, MaxLockout: 10 * time.Minute}
+
+	cases := []struct {
+		failures, threshold int
+		want                time.Duration
+	}{
+		{4, 5, 0},
+		{5, 5, time.Minute},
+		{6, 5, 2 * time.Minute},
+		{8, 5, 8 * time.Minute},
+		{9, 5, 10 * time.Minute},
+		{50, 5, 10 * time.Minute},
+		{100, 0, 0},
+	}
+	for _, tc := range cases {
+		if got := cfg.LockoutDuration(tc.failures, tc.threshold); got != tc.want {
+			t.Errorf("LockoutDuration(%d, %d) = %v, want %v", tc.failures, tc.threshold, got, tc.want)
+		}
+	}
+}
+
+func TestGetLockoutConfig(t *testing.T) {
+	os.Setenv("LOGIN_MAX_FAILURES", "3")
+	os.Setenv("LOGIN_IP_MAX_FAILURES", "0")
+	os.Setenv("LOGIN_LOCKOUT_BASE", "30s")
+	os.Setenv("LOGIN_LOCKOUT_MAX", "bogus")
+	defer func() {
+		for _, k := range []string{"LOGIN_MAX_FAILURES", "LOGIN_IP_MAX_FAILURES", "LOGIN_LOCKOUT_BASE", "LOGIN_LOCKOUT_MAX"} {
+			os.Unsetenv(k)
+		}
+	}()
+
+	cfg := GetLockoutConfig()
+	if cfg.MaxAccountFailures != 3 || cfg.MaxIPFailures != 0 {
+		t.Errorf("thresholds = %d/%d, want 3/0", cfg.MaxAccountFailures, cfg.MaxIPFailures)
+	}
+	if cfg.BaseLockout != 30*time.Second || cfg.MaxLockout != DefaultMaxLockout {
+		t.Errorf("lockouts = %v/%v", cfg.BaseLockout, cfg.MaxLockout)
+	}
+	if cfg.FailureWindow != DefaultFailureWindow {
+		t.Errorf("window = %v", cfg.FailureWindow)
+	}
+}
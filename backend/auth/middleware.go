@@ -1,6 +1,9 @@
 package auth
 
 import (
+	"context"
+	"errors"
+	"log"
 	"net/http"
 	"strings"
 
@@ -9,34 +12,172 @@ import (
 
 const UserIDKey = "user_id"
 const UserEmailKey = "user_email"
+const ClaimsKey = "token_claims"
 
-// AuthMiddleware validates JWT and sets user context
+// AuthMiddleware validates JWT and sets user context. The token comes from the
+// Authorization header or, when AUTH_TRANSPORT enables cookies and there is no
+// header, from the session cookie.
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
+			cfg := GetCookieConfig()
+			token := sessionCookieToken(c, cfg)
+			if token == "" {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
+				return
+			}
+			if !checkCSRF(c, cfg, token) {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Missing or invalid CSRF token"})
+				return
+			}
+			c.Set(cookieAuthKey, true)
+			authenticateToken(c, token)
 			return
 		}
 
-		// Support "Bearer <token>" format
+		// Support "Bearer <token>" format, and "ApiKey <key>" when a resolver is installed
 		parts := strings.SplitN(authHeader, " ", 2)
+		if len(parts) == 2 && strings.ToLower(parts[0]) == "apikey" {
+			if resolver := getAPIKeyResolver(); resolver != nil {
+				authenticateAPIKey(c, resolver, parts[1])
+				return
+			}
+		}
 		if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization format"})
 			return
 		}
 
-		tokenString := parts[1]
-		claims, err := ValidateToken(tokenString)
-		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+		authenticateToken(c, parts[1])
+	}
+}
+
+// OptionalAuthMiddleware authenticates requests that carry credentials exactly like
+// AuthMiddleware, rejecting bad ones, and lets requests without any through anonymously
+func OptionalAuthMiddleware() gin.HandlerFunc {
+	required := AuthMiddleware()
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" && sessionCookieToken(c, GetCookieConfig()) == "" {
+			c.Next()
+			return
+		}
+		required(c)
+	}
+}
+
+var (
+	// ErrTokenRevoked is returned for tokens revoked by logout, logout-all or an admin
+	ErrTokenRevoked = errors.New("token has been revoked")
+	// ErrRestrictedToken is returned by VerifyAccountToken for scoped, guest and impersonation tokens
+	ErrRestrictedToken = errors.New("token is restricted")
+)
+
+// verifyToken validates a sign-in token and checks that it has not been revoked
+// and its account is not suspended. Step-up tokens are not sign-in tokens.
+func verifyToken(ctx context.Context, tokenString string) (*Claims, error) {
+	claims, err := ValidateToken(tokenString)
+	if err != nil || claims.ReauthFor != "" {
+		return nil, ErrInvalidToken
+	}
+	revoked, err := IsRevoked(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, ErrTokenRevoked
+	}
+	return claims, nil
+}
+
+// VerifyAccountToken checks a token as AuthMiddleware does and also refuses
+// tokens that do not carry the account's full rights: scoped tokens, guest
+// tokens bound to a device and impersonation tokens, with ErrRestrictedToken.
+// Use it where a token in a request body proves ownership of an account.
+func VerifyAccountToken(ctx context.Context, tokenString string) (*Claims, error) {
+	claims, err := verifyToken(ctx, tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Scope != "" || claims.Device != "" || claims.ImpersonatedBy != "" {
+		return nil, ErrRestrictedToken
+	}
+	return claims, nil
+}
+
+// authenticateToken validates a JWT, sets the user context and continues the chain
+func authenticateToken(c *gin.Context, tokenString string) {
+	claims, err := verifyToken(c.Request.Context(), tokenString)
+	switch {
+	case errors.Is(err, ErrInvalidToken):
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+		return
+	case errors.Is(err, ErrAccountSuspended):
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "This account has been suspended"})
+		return
+	case errors.Is(err, ErrTokenRevoked):
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token has been revoked"})
+		return
+	case err != nil:
+		log.Printf("Token revocation check failed: %v", err)
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "Unable to verify token"})
+		return
+	}
+
+	// Tokens without a jti predate session tracking and cannot be listed or revoked individually
+	if tracker := getSessionTracker(); tracker != nil && claims.ID != "" {
+		if err := tracker.TrackSession(c.Request.Context(), claims, c.Request.UserAgent(), c.ClientIP()); err != nil {
+			log.Printf("Session tracking failed: %v", err)
+		}
+	}
+
+	// Guest tokens only work from the device they were issued to
+	if claims.Device != "" {
+		if HashToken(c.GetHeader(DeviceIDHeader)) != claims.Device {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Guest token is not valid on this device"})
 			return
 		}
+		c.Set(GuestKey, true)
+	}
 
-		c.Set(UserIDKey, claims.UserID)
-		c.Set(UserEmailKey, claims.Email)
-		c.Next()
+	// Every request made while impersonating is logged with the admin behind it
+	if claims.ImpersonatedBy != "" {
+		log.Printf("Impersonation: admin %s as user %s: %s %s", claims.ImpersonatedBy, claims.UserID, c.Request.Method, c.Request.URL.Path)
+		c.Set(ImpersonatorIDKey, claims.ImpersonatedBy)
+		c.Header("X-Impersonated-By", claims.ImpersonatedBy)
 	}
+
+	c.Set(UserIDKey, claims.UserID)
+	c.Set(UserEmailKey, claims.Email)
+	c.Set(ClaimsKey, claims)
+	c.Next()
+}
+
+// authenticateAPIKey resolves a personal API key to its owner and continues the chain
+func authenticateAPIKey(c *gin.Context, resolver APIKeyResolver, key string) {
+	if !strings.HasPrefix(key, APIKeyPrefix) {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
+		return
+	}
+	owner, err := resolver.ResolveAPIKey(c.Request.Context(), HashToken(key))
+	if errors.Is(err, ErrInvalidAPIKey) {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
+		return
+	}
+	if errors.Is(err, ErrAccountSuspended) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "This account has been suspended"})
+		return
+	}
+	if err != nil {
+		log.Printf("API key lookup failed: %v", err)
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "Unable to verify API key"})
+		return
+	}
+
+	c.Set(UserIDKey, owner.UserID)
+	c.Set(UserEmailKey, owner.Email)
+	c.Set(APIKeyIDKey, owner.KeyID)
+	c.Next()
 }
 
 // GetUserID extracts user ID from gin context (call after AuthMiddleware)
@@ -47,3 +188,12 @@ func GetUserID(c *gin.Context) string {
 	}
 	return ""
 }
+
+// GetClaims returns the validated token claims (call after AuthMiddleware)
+func GetClaims(c *gin.Context) *Claims {
+	claims, _ := c.Get(ClaimsKey)
+	if cl, ok := claims.(*Claims); ok {
+		return cl
+	}
+	return nil
+}
@@ -1,10 +1,12 @@
 package auth
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -96,6 +98,40 @@ func TestAuthMiddleware_ValidToken(t *testing.T) {
 	}
 }
 
+type fakeRevocationChecker struct {
+	revokedJTI string
+}
+
+func (f fakeRevocationChecker) IsTokenRevoked(_ context.Context, jti, _ string, _ time.Time) (bool, error) {
+	return jti == f.revokedJTI, nil
+}
+
+func TestAuthMiddleware_RevokedToken(t *testing.T) {
+	os.Setenv("JWT_SECRET", "test-secret")
+	defer os.Unsetenv("JWT_SECRET")
+
+	revoked, _, _ := GenerateToken("user-123", "test@example.com", false)
+	active, _, _ := GenerateToken("user-123", "test@example.com", false)
+	claims, err := ValidateToken(revoked)
+	if err != nil || claims.ID == "" {
+		t.Fatalf("expected token with jti, got %+v (%v)", claims, err)
+	}
+
+	SetRevocationChecker(fakeRevocationChecker{revokedJTI: claims.ID})
+	defer SetRevocationChecker(nil)
+
+	r := setupMiddlewareRouter(AuthMiddleware())
+	for token, want := range map[string]int{revoked: http.StatusUnauthorized, active: http.StatusOK} {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != want {
+			t.Errorf("got %d, want %d", w.Code, want)
+		}
+	}
+}
+
 // --- AdminMiddleware ---
 
 // adminRouter sets user_email in context (simulating AuthMiddleware) then runs AdminMiddleware
@@ -212,3 +248,132 @@ func contains(s, sub string) bool {
 			return false
 		}())
 }
+
+type fakeAPIKeyResolver struct {
+	keyHash string
+}
+
+func (f fakeAPIKeyResolver) ResolveAPIKey(_ context.Context, keyHash string) (*APIKeyOwner, error) {
+	if keyHash != f.keyHash {
+		return nil, ErrInvalidAPIKey
+	}
+	return &APIKeyOwner{KeyID: "key-1", UserID: "user-123", Email: "test@example.com"}, nil
+}
+
+func TestAuthMiddleware_APIKey(t *testing.T) {
+	key := APIKeyPrefix + "secret"
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/test", AuthMiddleware(), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"user_id": GetUserID(c), "key_id": GetAPIKeyID(c)})
+	})
+	request := func(header string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set("Authorization", header)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// Without a resolver the scheme is not recognised
+	if w := request("ApiKey " + key); w.Code != http.StatusUnauthorized {
+		t.Errorf("no resolver: got %d, want 401", w.Code)
+	}
+
+	SetAPIKeyResolver(fakeAPIKeyResolver{keyHash: HashToken(key)})
+	defer SetAPIKeyResolver(nil)
+
+	w := request("ApiKey " + key)
+	if w.Code != http.StatusOK || !contains(w.Body.String(), "user-123") || !contains(w.Body.String(), "key-1") {
+		t.Errorf("valid key: got %d %s", w.Code, w.Body.String())
+	}
+	for _, h := range []string{"ApiKey " + APIKeyPrefix + "other", "ApiKey secret"} {
+		if w := request(h); w.Code != http.StatusUnauthorized {
+			t.Errorf("%q: got %d, want 401", h, w.Code)
+		}
+	}
+}
+
+// --- RequireRole ---
+
+type fakeRoleResolver map[string]string
+
+func (f fakeRoleResolver) UserRole(_ context.Context, userID string) (string, error) {
+	if userID == "broken" {
+		return "", context.DeadlineExceeded
+	}
+	return f[userID], nil
+}
+
+func TestRequireRole(t *testing.T) {
+	SetRoleResolver(fakeRoleResolver{"coach-1": RoleCoach, "admin-1": RoleAdmin, "user-1": RoleUser})
+	defer SetRoleResolver(nil)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/coach", func(c *gin.Context) {
+		c.Set(UserIDKey, c.GetHeader("X-User"))
+		c.Set(UserEmailKey, c.GetHeader("X-Email"))
+	}, RequireRole(RoleCoach), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"role": c.GetString(UserRoleKey)})
+	})
+
+	cases := []struct {
+		user, email string
+		want        int
+	}{
+		{"coach-1", "coach@example.com", http.StatusOK},
+		{"admin-1", "someone@example.com", http.StatusOK},
+		{"user-1", "user@example.com", http.StatusForbidden},
+		{"user-1", "admin@liftoff.local", http.StatusOK}, // ADMIN_EMAILS bootstrap
+		{"broken", "user@example.com", http.StatusServiceUnavailable},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest("GET", "/coach", nil)
+		req.Header.Set("X-User", tc.user)
+		req.Header.Set("X-Email", tc.email)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tc.want {
+			t.Errorf("%s/%s: got %d, want %d (%s)", tc.user, tc.email, w.Code, tc.want, w.Body.String())
+		}
+	}
+}
+
+func TestOptionalAuthMiddleware(t *testing.T) {
+	os.Setenv("JWT_SECRET", "test-secret")
+	defer os.Unsetenv("JWT_SECRET")
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/test", OptionalAuthMiddleware(), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"user_id": GetUserID(c)})
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/test", nil))
+	if w.Code != http.StatusOK || !contains(w.Body.String(), `"user_id":""`) {
+		t.Errorf("anonymous: got %d %s", w.Code, w.Body.String())
+	}
+
+	token, _, err := GenerateToken("user-123", "test@example.com", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || !contains(w.Body.String(), "user-123") {
+		t.Errorf("signed in: got %d %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("Authorization", "Bearer this.is.invalid")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("bad token: got %d, want 401", w.Code)
+	}
+}
@@ -0,0 +1,158 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	ErrOAuthNotConfigured = errors.New("oauth provider is not configured")
+	ErrOAuthExchange      = errors.New("failed to exchange authorization code")
+)
+
+const ProviderGoogle = "google"
+
+// OAuthProvider describes an OAuth2 authorization-code provider
+type OAuthProvider struct {
+	Name         string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	AuthURL      string
+	TokenURL     string
+	UserInfoURL  string
+	Scopes       []string
+	HTTPClient   *http.Client
+}
+
+// OAuthToken is the subset of the token endpoint response we use
+type OAuthToken struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// OAuthUserInfo is the identity returned by a provider's userinfo endpoint
+type OAuthUserInfo struct {
+	Subject       string `json:"sub"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+}
+
+// GetGoogleOAuthProvider loads Google OAuth config from environment.
+// Returns ErrOAuthNotConfigured unless GOOGLE_CLIENT_ID and GOOGLE_CLIENT_SECRET are set.
+func GetGoogleOAuthProvider() (*OAuthProvider, error) {
+	clientID := os.Getenv("GOOGLE_CLIENT_ID")
+	clientSecret := os.Getenv("GOOGLE_CLIENT_SECRET")
+	if clientID == "" || clientSecret == "" {
+		return nil, ErrOAuthNotConfigured
+	}
+
+	redirectURL := os.Getenv("GOOGLE_REDIRECT_URL")
+	if redirectURL == "" {
+		redirectURL = "http://localhost:8080/api/auth/google/callback"
+	}
+
+	return &OAuthProvider{
+		Name:         ProviderGoogle,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  redirectURL,
+		AuthURL:      "https://accounts.google.com/o/oauth2/v2/auth",
+		TokenURL:     "https://oauth2.googleapis.com/token",
+		UserInfoURL:  "https://openidconnect.googleapis.com/v1/userinfo",
+		Scopes:       []string{"openid", "email", "profile"},
+	}, nil
+}
+
+// AuthCodeURL returns the provider consent URL for the given state
+func (p *OAuthProvider) AuthCodeURL(state string) string {
+	params := url.Values{}
+	params.Set("client_id", p.ClientID)
+	params.Set("redirect_uri", p.RedirectURL)
+	params.Set("response_type", "code")
+	params.Set("scope", strings.Join(p.Scopes, " "))
+	params.Set("state", state)
+	return p.AuthURL + "?" + params.Encode()
+}
+
+// Exchange trades an authorization code for tokens
+func (p *OAuthProvider) Exchange(ctx context.Context, code string) (*OAuthToken, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.RedirectURL)
+	form.Set("client_id", p.ClientID)
+	form.Set("client_secret", p.ClientSecret)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOAuthExchange, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: status %d", ErrOAuthExchange, resp.StatusCode)
+	}
+
+	var token OAuthToken
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOAuthExchange, err)
+	}
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("%w: missing access token", ErrOAuthExchange)
+	}
+	return &token, nil
+}
+
+// UserInfo fetches the authenticated identity using an access token
+func (p *OAuthProvider) UserInfo(ctx context.Context, accessToken string) (*OAuthUserInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.UserInfoURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user info: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch user info: status %d", resp.StatusCode)
+	}
+
+	var info OAuthUserInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, fmt.Errorf("failed to decode user info: %w", err)
+	}
+	if info.Subject == "" {
+		return nil, errors.New("user info missing subject")
+	}
+	info.Email = NormalizeEmail(info.Email)
+	return &info, nil
+}
+
+func (p *OAuthProvider) client() *http.Client {
+	if p.HTTPClient != nil {
+		return p.HTTPClient
+	}
+	return &http.Client{Timeout: 10 * time.Second}
+}
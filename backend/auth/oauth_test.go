@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+)
+
+func TestGetGoogleOAuthProvider_NotConfigured(t *testing.T) {
+	os.Unsetenv("GOOGLE_CLIENT_ID")
+	os.Unsetenv("GOOGLE_CLIENT_SECRET")
+
+	_, err := GetGoogleOAuthProvider()
+	if err != ErrOAuthNotConfigured {
+		t.Errorf("GetGoogleOAuthProvider() error = %v, want ErrOAuthNotConfigured", err)
+	}
+}
+
+func TestOAuthProvider_AuthCodeURL(t *testing.T) {
+	os.Setenv("GOOGLE_CLIENT_ID", "client-id")
+	os.Setenv("GOOGLE_CLIENT_SECRET", "client-secret")
+	defer os.Unsetenv("GOOGLE_CLIENT_ID")
+	defer os.Unsetenv("GOOGLE_CLIENT_SECRET")
+
+	p, err := GetGoogleOAuthProvider()
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(p.AuthCodeURL("state-123"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	if q.Get("client_id") != "client-id" {
+		t.Errorf("client_id = %q", q.Get("client_id"))
+	}
+	if q.Get("state") != "state-123" {
+		t.Errorf("state = %q", q.Get("state"))
+	}
+	if q.Get("response_type") != "code" {
+		t.Errorf("response_type = %q", q.Get("response_type"))
+	}
+}
+
+func TestOAuthProvider_ExchangeAndUserInfo(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			r.ParseForm()
+			if r.Form.Get("code") != "good-code" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"access_token":"at-1","token_type":"Bearer"}`))
+		case "/userinfo":
+			if r.Header.Get("Authorization") != "Bearer at-1" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"sub":"g-42","email":"Lifter@Example.com","email_verified":true}`))
+		}
+	}))
+	defer srv.Close()
+
+	p := &OAuthProvider{Name: ProviderGoogle, TokenURL: srv.URL + "/token", UserInfoURL: srv.URL + "/userinfo"}
+
+	if _, err := p.Exchange(context.Background(), "bad-code"); err == nil {
+		t.Error("Exchange() with bad code should fail")
+	}
+
+	token, err := p.Exchange(context.Background(), "good-code")
+	if err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+	info, err := p.UserInfo(context.Background(), token.AccessToken)
+	if err != nil {
+		t.Fatalf("UserInfo() error = %v", err)
+	}
+	if info.Subject != "g-42" || info.Email != "lifter@example.com" || !info.EmailVerified {
+		t.Errorf("UserInfo() = %+v", info)
+	}
+}
@@ -0,0 +1,320 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ProviderOIDC links identities from the deployment's OpenID Connect provider.
+// A deployment has at most one, so the issuer is not part of the link.
+const ProviderOIDC = "oidc"
+
+var (
+	ErrInvalidIDToken       = errors.New("invalid OIDC identity token")
+	ErrOIDCDiscovery        = errors.New("failed to discover OIDC provider")
+	ErrOIDCDomainNotAllowed = errors.New("this email domain may not sign in with single sign-on")
+)
+
+// OIDCProvider is a generic OpenID Connect provider, e.g. a gym's own identity
+// provider. Endpoints come from the issuer's discovery document.
+type OIDCProvider struct {
+	OAuthProvider
+	Issuer         string
+	DisplayName    string // shown on the sign-in button
+	JWKSURL        string
+	EmailClaim     string   // claim holding the email; some providers use "upn" or "preferred_username"
+	TrustEmail     bool     // treat emails as verified when the provider does not send email_verified
+	AllowedDomains []string // if set, only emails in these domains may sign in
+	BasicAuth      bool     // send the client secret with HTTP Basic auth rather than in the form
+}
+
+// OIDCDiscovery is the subset of /.well-known/openid-configuration we use
+type OIDCDiscovery struct {
+	Issuer                   string   `json:"issuer"`
+	AuthorizationEndpoint    string   `json:"authorization_endpoint"`
+	TokenEndpoint            string   `json:"token_endpoint"`
+	UserInfoEndpoint         string   `json:"userinfo_endpoint"`
+	JWKSURI                  string   `json:"jwks_uri"`
+	TokenEndpointAuthMethods []string `json:"token_endpoint_auth_methods_supported"`
+}
+
+// discoveryTTL is how long a discovery document is trusted before it is refetched
+const discoveryTTL = time.Hour
+
+type cachedDiscovery struct {
+	doc       *OIDCDiscovery
+	fetchedAt time.Time
+}
+
+var (
+	discoveryMu    sync.Mutex
+	discoveryCache = map[string]cachedDiscovery{}
+)
+
+// GetOIDCProvider loads OIDC config from environment and discovers the issuer's endpoints.
+// Returns ErrOAuthNotConfigured unless OIDC_ISSUER, OIDC_CLIENT_ID and OIDC_CLIENT_SECRET are set.
+func GetOIDCProvider(ctx context.Context) (*OIDCProvider, error) {
+	issuer := strings.TrimSuffix(os.Getenv("OIDC_ISSUER"), "/")
+	clientID := os.Getenv("OIDC_CLIENT_ID")
+	clientSecret := os.Getenv("OIDC_CLIENT_SECRET")
+	if issuer == "" || clientID == "" || clientSecret == "" {
+		return nil, ErrOAuthNotConfigured
+	}
+
+	p := &OIDCProvider{
+		OAuthProvider: OAuthProvider{
+			Name:         ProviderOIDC,
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			RedirectURL:  os.Getenv("OIDC_REDIRECT_URL"),
+			Scopes:       strings.Fields(os.Getenv("OIDC_SCOPES")),
+		},
+		Issuer:      issuer,
+		DisplayName: os.Getenv("OIDC_NAME"),
+		EmailClaim:  os.Getenv("OIDC_EMAIL_CLAIM"),
+	}
+	if p.RedirectURL == "" {
+		p.RedirectURL = "http://localhost:8080/api/auth/oidc/callback"
+	}
+	if len(p.Scopes) == 0 {
+		p.Scopes = []string{"openid", "email", "profile"}
+	}
+	if p.DisplayName == "" {
+		p.DisplayName = "Single sign-on"
+	}
+	if p.EmailClaim == "" {
+		p.EmailClaim = "email"
+	}
+	p.TrustEmail, _ = strconv.ParseBool(os.Getenv("OIDC_TRUST_EMAIL"))
+	for _, d := range strings.Split(os.Getenv("OIDC_ALLOWED_DOMAINS"), ",") {
+		if d = strings.ToLower(strings.TrimSpace(d)); d != "" {
+			p.AllowedDomains = append(p.AllowedDomains, strings.TrimPrefix(d, "@"))
+		}
+	}
+
+	if err := p.Discover(ctx); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Discover fills in the provider's endpoints from the issuer's discovery document,
+// which is cached per issuer
+func (p *OIDCProvider) Discover(ctx context.Context) error {
+	discoveryMu.Lock()
+	cached, ok := discoveryCache[p.Issuer]
+	discoveryMu.Unlock()
+
+	doc := cached.doc
+	if !ok || time.Since(cached.fetchedAt) >= discoveryTTL {
+		var err error
+		doc, err = p.fetchDiscovery(ctx)
+		if err != nil {
+			return err
+		}
+		discoveryMu.Lock()
+		discoveryCache[p.Issuer] = cachedDiscovery{doc: doc, fetchedAt: time.Now()}
+		discoveryMu.Unlock()
+	}
+
+	p.AuthURL = doc.AuthorizationEndpoint
+	p.TokenURL = doc.TokenEndpoint
+	p.UserInfoURL = doc.UserInfoEndpoint
+	p.JWKSURL = doc.JWKSURI
+	// client_secret_basic is the default when the provider does not say
+	p.BasicAuth = len(doc.TokenEndpointAuthMethods) == 0
+	for _, m := range doc.TokenEndpointAuthMethods {
+		if m == "client_secret_basic" {
+			p.BasicAuth = true
+		}
+	}
+	return nil
+}
+
+func (p *OIDCProvider) fetchDiscovery(ctx context.Context) (*OIDCDiscovery, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.Issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.client().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCDiscovery, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: status %d", ErrOIDCDiscovery, resp.StatusCode)
+	}
+
+	var doc OIDCDiscovery
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCDiscovery, err)
+	}
+	// The document must describe the issuer it was fetched from, or tokens it vouches for could be forged
+	if strings.TrimSuffix(doc.Issuer, "/") != p.Issuer {
+		return nil, fmt.Errorf("%w: issuer mismatch (%q)", ErrOIDCDiscovery, doc.Issuer)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return nil, fmt.Errorf("%w: missing endpoints", ErrOIDCDiscovery)
+	}
+	return &doc, nil
+}
+
+// AuthCodeURL returns the provider consent URL for the given state and nonce
+func (p *OIDCProvider) AuthCodeURL(state, nonce string) string {
+	u := p.OAuthProvider.AuthCodeURL(state)
+	return u + "&nonce=" + url.QueryEscape(nonce)
+}
+
+// Exchange trades an authorization code for tokens; the response must carry an id_token
+func (p *OIDCProvider) Exchange(ctx context.Context, code string) (*OAuthToken, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.RedirectURL)
+	if !p.BasicAuth {
+		form.Set("client_id", p.ClientID)
+		form.Set("client_secret", p.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.BasicAuth {
+		req.SetBasicAuth(url.QueryEscape(p.ClientID), url.QueryEscape(p.ClientSecret))
+	}
+
+	resp, err := p.client().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOAuthExchange, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: status %d", ErrOAuthExchange, resp.StatusCode)
+	}
+
+	var token OAuthToken
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOAuthExchange, err)
+	}
+	if token.IDToken == "" {
+		return nil, fmt.Errorf("%w: missing id_token", ErrOAuthExchange)
+	}
+	return &token, nil
+}
+
+// Identity verifies the token response's id_token against the provider's keys and
+// maps its claims to an identity. When the id_token has no email it is read from
+// the userinfo endpoint instead.
+func (p *OIDCProvider) Identity(ctx context.Context, token *OAuthToken, nonce string) (*OAuthUserInfo, error) {
+	keys := SharedJWKSCache(p.JWKSURL, p.HTTPClient)
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(token.IDToken, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return keys.Key(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "ES256", "ES384"}),
+		jwt.WithIssuer(p.Issuer),
+		jwt.WithAudience(p.ClientID),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+	if got, _ := claims["nonce"].(string); got != nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	}
+
+	info := p.mapClaims(claims)
+	if info.Subject == "" {
+		return nil, fmt.Errorf("%w: missing subject", ErrInvalidIDToken)
+	}
+	if info.Email == "" && p.UserInfoURL != "" && token.AccessToken != "" {
+		extra, err := p.userInfoClaims(ctx, token.AccessToken)
+		if err != nil {
+			return nil, err
+		}
+		// The userinfo response is only trusted for the subject the id_token names
+		if sub, _ := extra["sub"].(string); sub == info.Subject {
+			info = p.mapClaims(extra)
+		}
+	}
+
+	if !p.AllowsEmail(info.Email) {
+		return nil, ErrOIDCDomainNotAllowed
+	}
+	return info, nil
+}
+
+// mapClaims reads the subject, the configured email claim and email_verified,
+// which some providers send as the string "true"
+func (p *OIDCProvider) mapClaims(claims map[string]interface{}) *OAuthUserInfo {
+	sub, _ := claims["sub"].(string)
+	email, _ := claims[p.EmailClaim].(string)
+	verified := p.TrustEmail
+	switch v := claims["email_verified"].(type) {
+	case bool:
+		verified = verified || v
+	case string:
+		verified = verified || v == "true"
+	}
+	return &OAuthUserInfo{Subject: sub, Email: NormalizeEmail(email), EmailVerified: verified}
+}
+
+func (p *OIDCProvider) userInfoClaims(ctx context.Context, accessToken string) (map[string]interface{}, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.UserInfoURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user info: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch user info: status %d", resp.StatusCode)
+	}
+
+	claims := map[string]interface{}{}
+	if err := json.NewDecoder(resp.Body).Decode(&claims); err != nil {
+		return nil, fmt.Errorf("failed to decode user info: %w", err)
+	}
+	return claims, nil
+}
+
+// AllowsEmail reports whether email may sign in under OIDC_ALLOWED_DOMAINS
+func (p *OIDCProvider) AllowsEmail(email string) bool {
+	if len(p.AllowedDomains) == 0 {
+		return true
+	}
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domain := email[at+1:]
+	for _, d := range p.AllowedDomains {
+		if domain == d {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,186 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// fakeIdP serves discovery, JWKS, token and userinfo endpoints. The token endpoint
+// returns whatever id_token the test put in idToken.
+type fakeIdP struct {
+	*httptest.Server
+	key      *rsa.PrivateKey
+	idToken  string
+	userinfo map[string]interface{}
+}
+
+func newFakeIdP(t *testing.T) *fakeIdP {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp := &fakeIdP{key: key}
+	idp.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			json.NewEncoder(w).Encode(OIDCDiscovery{
+				Issuer:                idp.URL,
+				AuthorizationEndpoint: idp.URL + "/authorize",
+				TokenEndpoint:         idp.URL + "/token",
+				UserInfoEndpoint:      idp.URL + "/userinfo",
+				JWKSURI:               idp.URL + "/jwks",
+			})
+		case "/jwks":
+			json.NewEncoder(w).Encode(map[string]interface{}{"keys": []JWK{{
+				Kty: "RSA",
+				Kid: "idp-kid",
+				N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}}})
+		case "/token":
+			id, secret, ok := r.BasicAuth()
+			if !ok || id != "gym-app" || secret != "s3cret" || r.FormValue("code") != "good-code" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]string{"access_token": "at-1", "id_token": idp.idToken})
+		case "/userinfo":
+			json.NewEncoder(w).Encode(idp.userinfo)
+		}
+	}))
+	t.Cleanup(idp.Close)
+	return idp
+}
+
+func (idp *fakeIdP) sign(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+	base := jwt.MapClaims{"iss": idp.URL, "aud": "gym-app", "sub": "member-7", "nonce": "n-1", "exp": time.Now().Add(time.Hour).Unix()}
+	for k, v := range claims {
+		base[k] = v
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, base)
+	token.Header["kid"] = "idp-kid"
+	s, err := token.SignedString(idp.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func oidcEnv(t *testing.T, issuer string) {
+	t.Setenv("OIDC_ISSUER", issuer)
+	t.Setenv("OIDC_CLIENT_ID", "gym-app")
+	t.Setenv("OIDC_CLIENT_SECRET", "s3cret")
+}
+
+func TestGetOIDCProvider_NotConfigured(t *testing.T) {
+	t.Setenv("OIDC_ISSUER", "")
+	if _, err := GetOIDCProvider(context.Background()); err != ErrOAuthNotConfigured {
+		t.Errorf("GetOIDCProvider() error = %v, want ErrOAuthNotConfigured", err)
+	}
+}
+
+func TestGetOIDCProvider_Discovery(t *testing.T) {
+	idp := newFakeIdP(t)
+	oidcEnv(t, idp.URL+"/")
+
+	p, err := GetOIDCProvider(context.Background())
+	if err != nil {
+		t.Fatalf("GetOIDCProvider() error = %v", err)
+	}
+	if p.TokenURL != idp.URL+"/token" || p.JWKSURL != idp.URL+"/jwks" || !p.BasicAuth {
+		t.Errorf("provider = %+v", p)
+	}
+	u, err := url.Parse(p.AuthCodeURL("state-1", "n-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q := u.Query(); q.Get("nonce") != "n-1" || q.Get("state") != "state-1" || q.Get("scope") != "openid email profile" {
+		t.Errorf("auth URL query = %v", q)
+	}
+
+	// A document naming another issuer is refused
+	other := newFakeIdP(t)
+	p = &OIDCProvider{Issuer: other.URL + "/tenant"}
+	other.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(OIDCDiscovery{Issuer: "https://evil.example", AuthorizationEndpoint: "a", TokenEndpoint: "t", JWKSURI: "j"})
+	})
+	if err := p.Discover(context.Background()); !errors.Is(err, ErrOIDCDiscovery) {
+		t.Errorf("Discover() with foreign issuer: err = %v", err)
+	}
+}
+
+func TestOIDCProvider_Identity(t *testing.T) {
+	idp := newFakeIdP(t)
+	oidcEnv(t, idp.URL)
+	t.Setenv("OIDC_ALLOWED_DOMAINS", "gym.example")
+	p, err := GetOIDCProvider(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idp.idToken = idp.sign(t, jwt.MapClaims{"email": "Member@Gym.example", "email_verified": "true"})
+	if _, err := p.Exchange(context.Background(), "bad-code"); err == nil {
+		t.Error("Exchange() with bad code should fail")
+	}
+	token, err := p.Exchange(context.Background(), "good-code")
+	if err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+	info, err := p.Identity(context.Background(), token, "n-1")
+	if err != nil {
+		t.Fatalf("Identity() error = %v", err)
+	}
+	if info.Subject != "member-7" || info.Email != "member@gym.example" || !info.EmailVerified {
+		t.Errorf("info = %+v", info)
+	}
+
+	if _, err := p.Identity(context.Background(), token, "other-nonce"); !errors.Is(err, ErrInvalidIDToken) {
+		t.Errorf("nonce mismatch: err = %v", err)
+	}
+	foreign := &OAuthToken{IDToken: idp.sign(t, jwt.MapClaims{"aud": "other-app", "email": "member@gym.example"})}
+	if _, err := p.Identity(context.Background(), foreign, "n-1"); !errors.Is(err, ErrInvalidIDToken) {
+		t.Errorf("foreign audience: err = %v", err)
+	}
+	outsider := &OAuthToken{IDToken: idp.sign(t, jwt.MapClaims{"email": "someone@elsewhere.example", "email_verified": true})}
+	if _, err := p.Identity(context.Background(), outsider, "n-1"); !errors.Is(err, ErrOIDCDomainNotAllowed) {
+		t.Errorf("disallowed domain: err = %v", err)
+	}
+
+	// Without an email in the id_token it comes from userinfo, but only for the same subject
+	idp.userinfo = map[string]interface{}{"sub": "member-7", "email": "member@gym.example", "email_verified": true}
+	bare := &OAuthToken{AccessToken: "at-1", IDToken: idp.sign(t, nil)}
+	if info, err := p.Identity(context.Background(), bare, "n-1"); err != nil || info.Email != "member@gym.example" {
+		t.Errorf("userinfo fallback: info = %+v, err = %v", info, err)
+	}
+	idp.userinfo["sub"] = "someone-else"
+	p.AllowedDomains = nil
+	if info, err := p.Identity(context.Background(), bare, "n-1"); err != nil || info.Email != "" {
+		t.Errorf("userinfo for another subject must be ignored: info = %+v, err = %v", info, err)
+	}
+}
+
+func TestOIDCProvider_EmailClaimAndTrust(t *testing.T) {
+	p := &OIDCProvider{EmailClaim: "upn"}
+	info := p.mapClaims(map[string]interface{}{"sub": "s", "upn": "Coach@Gym.example"})
+	if info.Email != "coach@gym.example" || info.EmailVerified {
+		t.Errorf("info = %+v", info)
+	}
+	p.TrustEmail = true
+	if info := p.mapClaims(map[string]interface{}{"sub": "s", "upn": "coach@gym.example"}); !info.EmailVerified {
+		t.Error("OIDC_TRUST_EMAIL should mark emails verified")
+	}
+}
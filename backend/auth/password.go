@@ -6,8 +6,6 @@ import (
 	"errors"
 	"regexp"
 	"strings"
-
-	"golang.org/x/crypto/bcrypt"
 )
 
 // HashToken creates a deterministic hash of a token for secure storage
@@ -50,21 +48,6 @@ func ValidatePassword(password string) error {
 	return nil
 }
 
-// HashPassword hashes a password using bcrypt
-func HashPassword(password string) (string, error) {
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
-		return "", err
-	}
-	return string(hash), nil
-}
-
-// CheckPassword verifies a password against a hash
-func CheckPassword(password, hash string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-	return err == nil
-}
-
 // NormalizeEmail converts email to lowercase for case-insensitive comparison
 func NormalizeEmail(email string) string {
 	return strings.ToLower(strings.TrimSpace(email))
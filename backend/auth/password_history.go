@@ -0,0 +1,25 @@
+package auth
+
+import "errors"
+
+// ErrPasswordReused is returned when a new password matches one of the user's recent passwords
+var ErrPasswordReused = errors.New("password was used recently; choose a different one")
+
+// DefaultPasswordHistory is how many recent passwords, the current one included, cannot be reused
+const DefaultPasswordHistory = 5
+
+// PasswordHistoryDepth reads PASSWORD_HISTORY: how many recent passwords, including
+// the current one, a new password may not match. 0 allows reuse.
+func PasswordHistoryDepth() int {
+	return intFromEnv("PASSWORD_HISTORY", DefaultPasswordHistory)
+}
+
+// PasswordReused reports whether password matches any of the password hashes
+func PasswordReused(password string, hashes []string) bool {
+	for _, hash := range hashes {
+		if hash != "" && CheckPassword(password, hash) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReauthHeader carries the step-up token from POST /api/auth/reauth
+const ReauthHeader = "X-Reauth-Token"
+
+// DefaultReauthTTL is how long a step-up token lasts; REAUTH_TTL overrides it
+const DefaultReauthTTL = 5 * time.Minute
+
+// ReauthTTL returns how long step-up tokens last
+func ReauthTTL() time.Duration {
+	return durationFromEnv("REAUTH_TTL", DefaultReauthTTL)
+}
+
+// GenerateReauthToken issues a short-lived step-up token proving the user just
+// re-entered their credentials. It is only valid next to the session token whose
+// jti is sessionID, and is never accepted as a session token itself.
+func GenerateReauthToken(userID, sessionID string) (string, time.Time, error) {
+	expiry := time.Now().Add(ReauthTTL())
+	claims := Claims{
+		UserID:           userID,
+		ReauthFor:        sessionID,
+		RegisteredClaims: GetTokenConfig().registeredClaims(expiry),
+	}
+
+	keys, err := GetKeySet()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	tokenString, err := keys.Sign(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return tokenString, expiry, nil
+}
+
+// IsReauthenticated reports whether the request carries a valid step-up token for
+// its session token (call after AuthMiddleware)
+func IsReauthenticated(c *gin.Context) bool {
+	session := GetClaims(c)
+	if session == nil || session.ID == "" {
+		return false
+	}
+	raw := c.GetHeader(ReauthHeader)
+	if raw == "" {
+		return false
+	}
+	claims, err := ValidateToken(raw)
+	return err == nil && claims.ReauthFor == session.ID && claims.UserID == session.UserID
+}
+
+// RequireReauth guards sensitive routes, such as account deletion, with "sudo mode":
+// the request must carry a step-up token from POST /api/auth/reauth in ReauthHeader.
+// Use after AuthMiddleware.
+func RequireReauth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !IsReauthenticated(c) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error":           "Confirm your password to continue",
+				"reauth_required": true,
+			})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrAccountSuspended is returned by checkers and resolvers for users an admin has suspended
+var ErrAccountSuspended = errors.New("account suspended")
+
+// RevocationChecker reports whether an otherwise valid token has been revoked,
+// either individually (by jti) or by a user-wide "logout everywhere" cutoff.
+// It returns ErrAccountSuspended for tokens of suspended users.
+type RevocationChecker interface {
+	IsTokenRevoked(ctx context.Context, jti, userID string, issuedAt time.Time) (bool, error)
+}
+
+var (
+	revocationMu      sync.RWMutex
+	revocationChecker RevocationChecker
+)
+
+// SetRevocationChecker installs the checker consulted by AuthMiddleware (nil disables checks)
+func SetRevocationChecker(checker RevocationChecker) {
+	revocationMu.Lock()
+	defer revocationMu.Unlock()
+	revocationChecker = checker
+}
+
+func getRevocationChecker() RevocationChecker {
+	revocationMu.RLock()
+	defer revocationMu.RUnlock()
+	return revocationChecker
+}
+
+// IsRevoked consults the installed RevocationChecker for claims; with none installed nothing is revoked
+func IsRevoked(ctx context.Context, claims *Claims) (bool, error) {
+	checker := getRevocationChecker()
+	if checker == nil {
+		return false, nil
+	}
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+	return checker.IsTokenRevoked(ctx, claims.ID, claims.UserID, issuedAt)
+}
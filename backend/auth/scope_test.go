@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClaimsHasScope(t *testing.T) {
+	tests := []struct {
+		scope, want string
+		ok          bool
+	}{
+		{"", ScopeWrite, true},
+		{ScopeRead, ScopeRead, true},
+		{ScopeRead, ScopeWrite, false},
+		{ScopeWrite, ScopeRead, true},
+		{"read write", ScopeWrite, true},
+	}
+	for _, tt := range tests {
+		c := &Claims{Scope: tt.scope}
+		if got := c.HasScope(tt.want); got != tt.ok {
+			t.Errorf("Claims{Scope: %q}.HasScope(%q) = %v, want %v", tt.scope, tt.want, got, tt.ok)
+		}
+	}
+}
+
+func TestScopedTokenRoundTrip(t *testing.T) {
+	token, _, err := GenerateScopedToken("user-1", "a@example.com", ScopeRead, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := ValidateToken(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Scope != ScopeRead || claims.HasScope(ScopeWrite) {
+		t.Errorf("scope = %q", claims.Scope)
+	}
+}
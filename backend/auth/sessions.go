@@ -0,0 +1,29 @@
+package auth
+
+import (
+	"context"
+	"sync"
+)
+
+// SessionTracker records the device, IP and last-seen time of each token as it is used
+type SessionTracker interface {
+	TrackSession(ctx context.Context, claims *Claims, device, ip string) error
+}
+
+var (
+	sessionTrackerMu sync.RWMutex
+	sessionTracker   SessionTracker
+)
+
+// SetSessionTracker installs the tracker AuthMiddleware reports token use to (nil disables tracking)
+func SetSessionTracker(tracker SessionTracker) {
+	sessionTrackerMu.Lock()
+	defer sessionTrackerMu.Unlock()
+	sessionTracker = tracker
+}
+
+func getSessionTracker() SessionTracker {
+	sessionTrackerMu.RLock()
+	defer sessionTrackerMu.RUnlock()
+	return sessionTracker
+}
@@ -0,0 +1,299 @@
+package auth
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+)
+
+// COSE algorithm identifiers supported for passkeys
+const (
+	COSEAlgES256 = -7
+	COSEAlgEdDSA = -8
+	COSEAlgRS256 = -257
+)
+
+// Authenticator data flags
+const (
+	authDataUserPresent  = 0x01
+	authDataAttestedCred = 0x40
+)
+
+var ErrWebAuthnVerification = errors.New("webauthn verification failed")
+
+// WebAuthnConfig identifies this relying party to authenticators
+type WebAuthnConfig struct {
+	RPID    string
+	RPName  string
+	Origins []string
+}
+
+// GetWebAuthnConfig loads relying party settings from environment.
+// WEBAUTHN_RP_ID defaults to localhost and WEBAUTHN_ORIGINS (comma-separated) to FRONTEND_URL.
+func GetWebAuthnConfig() *WebAuthnConfig {
+	rpID := os.Getenv("WEBAUTHN_RP_ID")
+	if rpID == "" {
+		rpID = "localhost"
+	}
+	rpName := os.Getenv("WEBAUTHN_RP_NAME")
+	if rpName == "" {
+		rpName = "Liftoff"
+	}
+	origins := os.Getenv("WEBAUTHN_ORIGINS")
+	if origins == "" {
+		origins = os.Getenv("FRONTEND_URL")
+	}
+	if origins == "" {
+		origins = "http://localhost:5173"
+	}
+
+	var list []string
+	for _, o := range strings.Split(origins, ",") {
+		if o = strings.TrimRight(strings.TrimSpace(o), "/"); o != "" {
+			list = append(list, o)
+		}
+	}
+	return &WebAuthnConfig{RPID: rpID, RPName: rpName, Origins: list}
+}
+
+// NewWebAuthnChallenge returns a random base64url challenge
+func NewWebAuthnChallenge() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// DecodeBase64URL accepts base64url with or without padding
+func DecodeBase64URL(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+// RegisteredCredential is the result of a verified registration ceremony
+type RegisteredCredential struct {
+	ID        []byte
+	PublicKey []byte // COSE_Key as sent by the authenticator
+	Algorithm int64
+	SignCount uint32
+}
+
+// VerifyRegistration checks a navigator.credentials.create() response.
+// Attestation statements are not verified (we request attestation "none");
+// the credential is trusted on first use like a password.
+func (cfg *WebAuthnConfig) VerifyRegistration(clientDataJSON, attestationObject []byte, challenge string) (*RegisteredCredential, error) {
+	if err := cfg.verifyClientData(clientDataJSON, "webauthn.create", challenge); err != nil {
+		return nil, err
+	}
+
+	decoded, _, err := cborDecode(attestationObject)
+	if err != nil {
+		return nil, fmt.Errorf("%w: attestation object: %v", ErrWebAuthnVerification, err)
+	}
+	att, ok := decoded.(map[interface{}]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: attestation object is not a map", ErrWebAuthnVerification)
+	}
+	authData, ok := att["authData"].([]byte)
+	if !ok {
+		return nil, fmt.Errorf("%w: missing authData", ErrWebAuthnVerification)
+	}
+
+	parsed, err := cfg.parseAuthData(authData)
+	if err != nil {
+		return nil, err
+	}
+	if parsed.flags&authDataAttestedCred == 0 || len(parsed.credentialID) == 0 {
+		return nil, fmt.Errorf("%w: no attested credential", ErrWebAuthnVerification)
+	}
+
+	alg, err := coseAlgorithm(parsed.publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RegisteredCredential{
+		ID:        parsed.credentialID,
+		PublicKey: parsed.publicKey,
+		Algorithm: alg,
+		SignCount: parsed.signCount,
+	}, nil
+}
+
+// VerifyAssertion checks a navigator.credentials.get() response against a stored credential
+// and returns the authenticator's new signature counter
+func (cfg *WebAuthnConfig) VerifyAssertion(clientDataJSON, authenticatorData, signature, publicKey []byte, challenge string, storedSignCount uint32) (uint32, error) {
+	if err := cfg.verifyClientData(clientDataJSON, "webauthn.get", challenge); err != nil {
+		return 0, err
+	}
+
+	parsed, err := cfg.parseAuthData(authenticatorData)
+	if err != nil {
+		return 0, err
+	}
+
+	// A counter that fails to advance suggests a cloned authenticator.
+	// Authenticators that do not implement counters always report zero.
+	if (parsed.signCount != 0 || storedSignCount != 0) && parsed.signCount <= storedSignCount {
+		return 0, fmt.Errorf("%w: signature counter did not increase", ErrWebAuthnVerification)
+	}
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := append(append([]byte(nil), authenticatorData...), clientDataHash[:]...)
+	if err := verifyCOSESignature(publicKey, signed, signature); err != nil {
+		return 0, err
+	}
+	return parsed.signCount, nil
+}
+
+type collectedClientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+func (cfg *WebAuthnConfig) verifyClientData(raw []byte, ceremony, challenge string) error {
+	var cd collectedClientData
+	if err := json.Unmarshal(raw, &cd); err != nil {
+		return fmt.Errorf("%w: client data: %v", ErrWebAuthnVerification, err)
+	}
+	if cd.Type != ceremony {
+		return fmt.Errorf("%w: unexpected ceremony type %q", ErrWebAuthnVerification, cd.Type)
+	}
+	if strings.TrimRight(cd.Challenge, "=") != challenge {
+		return fmt.Errorf("%w: challenge mismatch", ErrWebAuthnVerification)
+	}
+	for _, o := range cfg.Origins {
+		if cd.Origin == o {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: origin %q not allowed", ErrWebAuthnVerification, cd.Origin)
+}
+
+type authenticatorData struct {
+	flags        byte
+	signCount    uint32
+	credentialID []byte
+	publicKey    []byte
+}
+
+func (cfg *WebAuthnConfig) parseAuthData(data []byte) (*authenticatorData, error) {
+	if len(data) < 37 {
+		return nil, fmt.Errorf("%w: authenticator data too short", ErrWebAuthnVerification)
+	}
+	rpIDHash := sha256.Sum256([]byte(cfg.RPID))
+	if !bytes.Equal(data[:32], rpIDHash[:]) {
+		return nil, fmt.Errorf("%w: relying party ID mismatch", ErrWebAuthnVerification)
+	}
+
+	out := &authenticatorData{
+		flags:     data[32],
+		signCount: binary.BigEndian.Uint32(data[33:37]),
+	}
+	if out.flags&authDataUserPresent == 0 {
+		return nil, fmt.Errorf("%w: user not present", ErrWebAuthnVerification)
+	}
+
+	if out.flags&authDataAttestedCred != 0 {
+		rest := data[37:]
+		if len(rest) < 18 {
+			return nil, fmt.Errorf("%w: attested credential data too short", ErrWebAuthnVerification)
+		}
+		idLen := int(binary.BigEndian.Uint16(rest[16:18]))
+		rest = rest[18:]
+		if len(rest) < idLen {
+			return nil, fmt.Errorf("%w: credential ID truncated", ErrWebAuthnVerification)
+		}
+		out.credentialID = append([]byte(nil), rest[:idLen]...)
+		rest = rest[idLen:]
+
+		_, used, err := cborDecode(rest)
+		if err != nil {
+			return nil, fmt.Errorf("%w: credential public key: %v", ErrWebAuthnVerification, err)
+		}
+		out.publicKey = append([]byte(nil), rest[:used]...)
+	}
+	return out, nil
+}
+
+// coseAlgorithm returns the alg of a COSE key after checking it can be used
+func coseAlgorithm(coseKey []byte) (int64, error) {
+	_, alg, err := parseCOSEKey(coseKey)
+	return alg, err
+}
+
+func parseCOSEKey(coseKey []byte) (crypto.PublicKey, int64, error) {
+	decoded, _, err := cborDecode(coseKey)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: public key: %v", ErrWebAuthnVerification, err)
+	}
+	m, ok := decoded.(map[interface{}]interface{})
+	if !ok {
+		return nil, 0, fmt.Errorf("%w: public key is not a map", ErrWebAuthnVerification)
+	}
+	alg, _ := m[int64(3)].(int64)
+
+	switch alg {
+	case COSEAlgES256:
+		x, _ := m[int64(-2)].([]byte)
+		y, _ := m[int64(-3)].([]byte)
+		if crv, _ := m[int64(-1)].(int64); crv != 1 || len(x) != 32 || len(y) != 32 {
+			return nil, 0, fmt.Errorf("%w: invalid P-256 key", ErrWebAuthnVerification)
+		}
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !key.Curve.IsOnCurve(key.X, key.Y) {
+			return nil, 0, fmt.Errorf("%w: point not on curve", ErrWebAuthnVerification)
+		}
+		return key, alg, nil
+	case COSEAlgRS256:
+		n, _ := m[int64(-1)].([]byte)
+		e, _ := m[int64(-2)].([]byte)
+		if len(n) < 256 || len(e) == 0 || len(e) > 4 {
+			return nil, 0, fmt.Errorf("%w: invalid RSA key", ErrWebAuthnVerification)
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, alg, nil
+	case COSEAlgEdDSA:
+		x, _ := m[int64(-2)].([]byte)
+		if crv, _ := m[int64(-1)].(int64); crv != 6 || len(x) != ed25519.PublicKeySize {
+			return nil, 0, fmt.Errorf("%w: invalid Ed25519 key", ErrWebAuthnVerification)
+		}
+		return ed25519.PublicKey(x), alg, nil
+	}
+	return nil, 0, fmt.Errorf("%w: unsupported algorithm %d", ErrWebAuthnVerification, alg)
+}
+
+func verifyCOSESignature(coseKey, signed, signature []byte) error {
+	key, _, err := parseCOSEKey(coseKey)
+	if err != nil {
+		return err
+	}
+	digest := sha256.Sum256(signed)
+
+	var ok bool
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		ok = ecdsa.VerifyASN1(k, digest[:], signature)
+	case *rsa.PublicKey:
+		ok = rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], signature) == nil
+	case ed25519.PublicKey:
+		ok = ed25519.Verify(k, signed, signature)
+	}
+	if !ok {
+		return fmt.Errorf("%w: invalid signature", ErrWebAuthnVerification)
+	}
+	return nil
+}
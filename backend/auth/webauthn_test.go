@@ -0,0 +1,161 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// cborHead encodes a CBOR initial byte plus argument
+func cborHead(major byte, n int) []byte {
+	switch {
+	case n < 24:
+		return []byte{major<<5 | byte(n)}
+	case n < 256:
+		return []byte{major<<5 | 24, byte(n)}
+	default:
+		return []byte{major<<5 | 25, byte(n >> 8), byte(n)}
+	}
+}
+
+func cborInt(v int) []byte {
+	if v < 0 {
+		return cborHead(1, -1-v)
+	}
+	return cborHead(0, v)
+}
+
+func cborBytes(b []byte) []byte { return append(cborHead(2, len(b)), b...) }
+func cborText(s string) []byte  { return append(cborHead(3, len(s)), s...) }
+
+func testCOSEKey(pub *ecdsa.PublicKey) []byte {
+	x := make([]byte, 32)
+	y := make([]byte, 32)
+	pub.X.FillBytes(x)
+	pub.Y.FillBytes(y)
+
+	out := cborHead(5, 5)
+	out = append(out, cborInt(1)...)
+	out = append(out, cborInt(2)...) // kty: EC2
+	out = append(out, cborInt(3)...)
+	out = append(out, cborInt(COSEAlgES256)...)
+	out = append(out, cborInt(-1)...)
+	out = append(out, cborInt(1)...) // crv: P-256
+	out = append(out, cborInt(-2)...)
+	out = append(out, cborBytes(x)...)
+	out = append(out, cborInt(-3)...)
+	out = append(out, cborBytes(y)...)
+	return out
+}
+
+func testAuthData(rpID string, flags byte, signCount uint32, credID, coseKey []byte) []byte {
+	hash := sha256.Sum256([]byte(rpID))
+	out := append([]byte(nil), hash[:]...)
+	out = append(out, flags)
+	out = binary.BigEndian.AppendUint32(out, signCount)
+	if credID != nil {
+		out = append(out, make([]byte, 16)...) // AAGUID
+		out = binary.BigEndian.AppendUint16(out, uint16(len(credID)))
+		out = append(out, credID...)
+		out = append(out, coseKey...)
+	}
+	return out
+}
+
+func testClientData(typ, challenge, origin string) []byte {
+	b, _ := json.Marshal(map[string]string{"type": typ, "challenge": challenge, "origin": origin})
+	return b
+}
+
+func TestCBORDecode(t *testing.T) {
+	data := append(cborHead(5, 2), cborText("fmt")...)
+	data = append(data, cborText("none")...)
+	data = append(data, cborInt(-7)...)
+	data = append(data, cborBytes([]byte{1, 2, 3})...)
+
+	v, n, err := cborDecode(data)
+	if err != nil {
+		t.Fatalf("cborDecode() error = %v", err)
+	}
+	if n != len(data) {
+		t.Errorf("consumed %d bytes, want %d", n, len(data))
+	}
+	m := v.(map[interface{}]interface{})
+	if m["fmt"] != "none" {
+		t.Errorf("fmt = %v", m["fmt"])
+	}
+	if b, _ := m[int64(-7)].([]byte); len(b) != 3 {
+		t.Errorf("bytes = %v", m[int64(-7)])
+	}
+
+	if _, _, err := cborDecode(data[:len(data)-1]); err == nil {
+		t.Error("expected error for truncated input")
+	}
+}
+
+func TestWebAuthn_RegistrationAndAssertion(t *testing.T) {
+	cfg := &WebAuthnConfig{RPID: "liftoff.test", Origins: []string{"https://liftoff.test"}}
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	credID := []byte("credential-1")
+
+	// Registration
+	challenge, _ := NewWebAuthnChallenge()
+	authData := testAuthData(cfg.RPID, authDataUserPresent|authDataAttestedCred, 0, credID, testCOSEKey(&key.PublicKey))
+	attestation := append(cborHead(5, 3), cborText("fmt")...)
+	attestation = append(attestation, cborText("none")...)
+	attestation = append(attestation, cborText("attStmt")...)
+	attestation = append(attestation, cborHead(5, 0)...)
+	attestation = append(attestation, cborText("authData")...)
+	attestation = append(attestation, cborBytes(authData)...)
+
+	reg, err := cfg.VerifyRegistration(testClientData("webauthn.create", challenge, "https://liftoff.test"), attestation, challenge)
+	if err != nil {
+		t.Fatalf("VerifyRegistration() error = %v", err)
+	}
+	if string(reg.ID) != string(credID) || reg.Algorithm != COSEAlgES256 {
+		t.Errorf("registered = %+v", reg)
+	}
+
+	if _, err := cfg.VerifyRegistration(testClientData("webauthn.create", challenge, "https://evil.test"), attestation, challenge); !errors.Is(err, ErrWebAuthnVerification) {
+		t.Errorf("foreign origin: err = %v", err)
+	}
+
+	// Assertion
+	sign := func(challenge string, count uint32) ([]byte, []byte, []byte) {
+		clientData := testClientData("webauthn.get", challenge, "https://liftoff.test")
+		ad := testAuthData(cfg.RPID, authDataUserPresent, count, nil, nil)
+		hash := sha256.Sum256(clientData)
+		digest := sha256.Sum256(append(append([]byte(nil), ad...), hash[:]...))
+		sig, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return clientData, ad, sig
+	}
+
+	challenge, _ = NewWebAuthnChallenge()
+	clientData, ad, sig := sign(challenge, 5)
+	count, err := cfg.VerifyAssertion(clientData, ad, sig, reg.PublicKey, challenge, 0)
+	if err != nil {
+		t.Fatalf("VerifyAssertion() error = %v", err)
+	}
+	if count != 5 {
+		t.Errorf("sign count = %d, want 5", count)
+	}
+
+	if _, err := cfg.VerifyAssertion(clientData, ad, sig, reg.PublicKey, "other-challenge", 0); err == nil {
+		t.Error("expected challenge mismatch")
+	}
+	if _, err := cfg.VerifyAssertion(clientData, ad, sig, reg.PublicKey, challenge, 5); err == nil {
+		t.Error("expected error for non-increasing counter")
+	}
+	sig[len(sig)-1] ^= 0xff
+	if _, err := cfg.VerifyAssertion(clientData, ad, sig, reg.PublicKey, challenge, 0); err == nil {
+		t.Error("expected error for tampered signature")
+	}
+}
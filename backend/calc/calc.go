@@ -0,0 +1,151 @@
+package calc
+
+import (
+	"errors"
+	"math"
+	"strings"
+)
+
+/**
+ * Calc Package
+ *
+ * Strength training math shared by the API and server-side analytics:
+ * estimated one-rep max, percentage tables and warmup ramps. Exposed
+ * directly under /api/calc so clients never re-implement the formulas.
+ */
+
+// Supported one-rep max formulas
+const (
+	FormulaEpley   = "epley"
+	FormulaBrzycki = "brzycki"
+)
+
+// Formulas lists every supported formula, default first
+var Formulas = []string{FormulaEpley, FormulaBrzycki}
+
+// DefaultIncrement is the smallest plate jump weights are rounded to
+const DefaultIncrement = 2.5
+
+var (
+	ErrInvalidWeight  = errors.New("weight must be greater than 0")
+	ErrInvalidReps    = errors.New("reps must be between 1 and 30")
+	ErrUnknownFormula = errors.New("unknown formula")
+)
+
+// OneRepMax estimates a one-rep max from a set of reps at weight.
+// An empty formula uses Epley.
+func OneRepMax(weight float64, reps int, formula string) (float64, error) {
+	if weight <= 0 {
+		return 0, ErrInvalidWeight
+	}
+	if reps < 1 || reps > 30 {
+		return 0, ErrInvalidReps
+	}
+	if reps == 1 {
+		return weight, nil
+	}
+	switch strings.ToLower(formula) {
+	case "", FormulaEpley:
+		return weight * (1 + float64(reps)/30), nil
+	case FormulaBrzycki:
+		return weight * 36 / (37 - float64(reps)), nil
+	}
+	return 0, ErrUnknownFormula
+}
+
+// RepsAtPercent estimates how many reps can be done at a percentage of 1RM (inverse Epley)
+func RepsAtPercent(percent float64) int {
+	if percent >= 100 {
+		return 1
+	}
+	reps := int(math.Floor(30 * (100/percent - 1)))
+	if reps < 1 {
+		reps = 1
+	}
+	return reps
+}
+
+// WeightForReps estimates the heaviest weight for reps with reserve reps left in
+// the tank (inverse Epley), rounded down to increment so it never overshoots max
+func WeightForReps(max float64, reps, reserve int, increment float64) (float64, error) {
+	if max <= 0 {
+		return 0, ErrInvalidWeight
+	}
+	if reps < 1 || reps+reserve > 30 {
+		return 0, ErrInvalidReps
+	}
+	weight := max
+	if total := reps + reserve; total > 1 {
+		weight = max / (1 + float64(total)/30)
+	}
+	if increment > 0 {
+		weight = math.Floor(weight/increment) * increment
+	}
+	return weight, nil
+}
+
+// RoundTo rounds weight to the nearest multiple of increment
+func RoundTo(weight, increment float64) float64 {
+	if increment <= 0 {
+		return weight
+	}
+	return math.Round(weight/increment) * increment
+}
+
+// PercentRow is one line of a percentage table
+type PercentRow struct {
+	Percent int     `json:"percent"`
+	Weight  float64 `json:"weight"`
+	Reps    int     `json:"reps"`
+}
+
+// PercentTable lists 50-100% of max in 5% steps, rounded to increment
+func PercentTable(max, increment float64) ([]PercentRow, error) {
+	if max <= 0 {
+		return nil, ErrInvalidWeight
+	}
+	rows := make([]PercentRow, 0, 11)
+	for p := 100; p >= 50; p -= 5 {
+		rows = append(rows, PercentRow{
+			Percent: p,
+			Weight:  RoundTo(max*float64(p)/100, increment),
+			Reps:    RepsAtPercent(float64(p)),
+		})
+	}
+	return rows, nil
+}
+
+// WarmupSet is one ramp-up set before the working weight
+type WarmupSet struct {
+	Weight float64 `json:"weight"`
+	Reps   int     `json:"reps"`
+}
+
+// warmupRamp is the percentage of working weight and reps for each warmup step
+var warmupRamp = []struct {
+	percent float64
+	reps    int
+}{{40, 5}, {60, 3}, {80, 2}}
+
+// Warmups ramps from the empty bar to the working weight.
+// Steps that round to the bar or repeat the previous weight are skipped.
+func Warmups(working, bar, increment float64) ([]WarmupSet, error) {
+	if working <= 0 {
+		return nil, ErrInvalidWeight
+	}
+	sets := []WarmupSet{}
+	last := 0.0
+	if bar > 0 && bar < working {
+		sets = append(sets, WarmupSet{Weight: bar, Reps: 10})
+		last = bar
+	}
+	for _, step := range warmupRamp {
+		w := RoundTo(working*step.percent/100, increment)
+		if w <= last || w >= working {
+			continue
+		}
+		sets = append(sets, WarmupSet{Weight: w, Reps: step.reps})
+		last = w
+	}
+	return sets, nil
+}
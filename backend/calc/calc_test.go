@@ -0,0 +1,113 @@
+package calc
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestOneRepMax(t *testing.T) {
+	cases := []struct {
+		weight  float64
+		reps    int
+		formula string
+		want    float64
+	}{
+		{100, 1, "", 100},
+		{100, 5, "", 116.667},
+		{100, 5, FormulaEpley, 116.667},
+		{100, 5, FormulaBrzycki, 112.5},
+		{140, 10, "Brzycki", 186.667},
+	}
+	for _, tc := range cases {
+		got, err := OneRepMax(tc.weight, tc.reps, tc.formula)
+		if err != nil {
+			t.Fatalf("OneRepMax(%v, %d, %q) error = %v", tc.weight, tc.reps, tc.formula, err)
+		}
+		if math.Abs(got-tc.want) > 0.001 {
+			t.Errorf("OneRepMax(%v, %d, %q) = %v, want %v", tc.weight, tc.reps, tc.formula, got, tc.want)
+		}
+	}
+
+	if _, err := OneRepMax(0, 5, ""); !errors.Is(err, ErrInvalidWeight) {
+		t.Errorf("zero weight: err = %v", err)
+	}
+	if _, err := OneRepMax(100, 0, ""); !errors.Is(err, ErrInvalidReps) {
+		t.Errorf("zero reps: err = %v", err)
+	}
+	if _, err := OneRepMax(100, 5, "magic"); !errors.Is(err, ErrUnknownFormula) {
+		t.Errorf("unknown formula: err = %v", err)
+	}
+}
+
+func TestPercentTable(t *testing.T) {
+	rows, err := PercentTable(140, 2.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 11 {
+		t.Fatalf("got %d rows, want 11", len(rows))
+	}
+	if rows[0].Percent != 100 || rows[0].Weight != 140 || rows[0].Reps != 1 {
+		t.Errorf("100%% row = %+v", rows[0])
+	}
+	// 85% of 140 = 119 -> 120 on 2.5 plates; Epley gives 5 reps
+	if r := rows[3]; r.Percent != 85 || r.Weight != 120 || r.Reps != 5 {
+		t.Errorf("85%% row = %+v", r)
+	}
+}
+
+func TestWeightForReps(t *testing.T) {
+	cases := []struct {
+		max         float64
+		reps, spare int
+		increment   float64
+		want        float64
+	}{
+		{100, 1, 0, 2.5, 100},
+		{116.667, 5, 0, 0, 100},
+		// 8 reps with 2 in reserve: 140 / (1 + 10/30) = 105
+		{140, 8, 2, 2.5, 105},
+		// 100 / (1 + 12/30) = 71.4 -> 70, never rounded up
+		{100, 10, 2, 2.5, 70},
+	}
+	for _, tc := range cases {
+		got, err := WeightForReps(tc.max, tc.reps, tc.spare, tc.increment)
+		if err != nil {
+			t.Fatalf("WeightForReps(%v, %d, %d) error = %v", tc.max, tc.reps, tc.spare, err)
+		}
+		if math.Abs(got-tc.want) > 0.001 {
+			t.Errorf("WeightForReps(%v, %d, %d) = %v, want %v", tc.max, tc.reps, tc.spare, got, tc.want)
+		}
+	}
+	if _, err := WeightForReps(0, 5, 0, 2.5); !errors.Is(err, ErrInvalidWeight) {
+		t.Errorf("zero max: err = %v", err)
+	}
+	if _, err := WeightForReps(100, 30, 2, 2.5); !errors.Is(err, ErrInvalidReps) {
+		t.Errorf("too many reps: err = %v", err)
+	}
+}
+
+func TestWarmups(t *testing.T) {
+	sets, err := Warmups(140, 20, 2.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []WarmupSet{{20, 10}, {55, 5}, {85, 3}, {112.5, 2}}
+	if len(sets) != len(want) {
+		t.Fatalf("sets = %+v, want %+v", sets, want)
+	}
+	for i := range want {
+		if sets[i] != want[i] {
+			t.Errorf("set %d = %+v, want %+v", i, sets[i], want[i])
+		}
+	}
+
+	// Light working weights skip steps that would not be above the bar
+	sets, _ = Warmups(30, 20, 2.5)
+	for _, s := range sets {
+		if s.Weight >= 30 {
+			t.Errorf("warmup %+v is not below working weight", s)
+		}
+	}
+}
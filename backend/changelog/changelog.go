@@ -0,0 +1,170 @@
+package changelog
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+/**
+ * Changelog Package
+ *
+ * Machine-readable release notes. CHANGELOG.md is embedded at build time
+ * and parsed into releases, newest first; the newest release is the
+ * server version. Clients fetch /api/changelog with the last version they
+ * saw to show a "what's new" dialog for everything since.
+ */
+
+//go:embed CHANGELOG.md
+var notes []byte
+
+// Change types, from the Keep a Changelog section headings
+const (
+	TypeAdded      = "added"
+	TypeChanged    = "changed"
+	TypeDeprecated = "deprecated"
+	TypeRemoved    = "removed"
+	TypeFixed      = "fixed"
+	TypeSecurity   = "security"
+)
+
+var ErrInvalidVersion = errors.New("version must look like 1.2.0")
+
+// Change is one bullet of a release
+type Change struct {
+	Type    string `json:"type"`
+	Summary string `json:"summary"`
+}
+
+// Release is one version's notes
+type Release struct {
+	Version string   `json:"version"`
+	Date    string   `json:"date,omitempty"`
+	Changes []Change `json:"changes"`
+}
+
+var (
+	releaseHeading = regexp.MustCompile(`^## \[?(\d+(?:\.\d+){0,2})\]?(?:\s+-\s+(\d{4}-\d{2}-\d{2}))?\s*$`)
+	changeTypes    = map[string]string{
+		"added": TypeAdded, "changed": TypeChanged, "deprecated": TypeDeprecated,
+		"removed": TypeRemoved, "fixed": TypeFixed, "security": TypeSecurity,
+	}
+)
+
+// releases is parsed once; a malformed CHANGELOG.md fails at startup like a bad template
+var releases = mustParse(notes)
+
+func mustParse(md []byte) []Release {
+	parsed, err := Parse(md)
+	if err != nil {
+		panic(fmt.Sprintf("changelog: %v", err))
+	}
+	return parsed
+}
+
+// Parse reads Keep a Changelog style Markdown. Text outside release sections is ignored;
+// releases must be listed newest first.
+func Parse(md []byte) ([]Release, error) {
+	var out []Release
+	changeType := ""
+	scanner := bufio.NewScanner(bytes.NewReader(md))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(text, "## "):
+			m := releaseHeading.FindStringSubmatch(text)
+			if m == nil {
+				return nil, fmt.Errorf("line %d: release heading must look like ## [1.2.0] - 2006-01-02", line)
+			}
+			if n := len(out); n > 0 && Compare(m[1], out[n-1].Version) >= 0 {
+				return nil, fmt.Errorf("line %d: %s is not older than %s", line, m[1], out[n-1].Version)
+			}
+			out = append(out, Release{Version: m[1], Date: m[2], Changes: []Change{}})
+			changeType = ""
+		case len(out) == 0:
+			continue
+		case strings.HasPrefix(text, "### "):
+			t, ok := changeTypes[strings.ToLower(strings.TrimSpace(text[4:]))]
+			if !ok {
+				return nil, fmt.Errorf("line %d: unknown change type %q", line, text[4:])
+			}
+			changeType = t
+		case strings.HasPrefix(text, "- ") || strings.HasPrefix(text, "* "):
+			if changeType == "" {
+				return nil, fmt.Errorf("line %d: change listed before a ### heading", line)
+			}
+			r := &out[len(out)-1]
+			r.Changes = append(r.Changes, Change{Type: changeType, Summary: strings.TrimSpace(text[2:])})
+		case text != "":
+			// A wrapped bullet continues the previous change
+			if r := &out[len(out)-1]; len(r.Changes) > 0 {
+				last := &r.Changes[len(r.Changes)-1]
+				last.Summary += " " + text
+			}
+		}
+	}
+	return out, scanner.Err()
+}
+
+// Version is the server version: the newest release in the changelog
+func Version() string {
+	if len(releases) == 0 {
+		return "0.0.0"
+	}
+	return releases[0].Version
+}
+
+// Since returns the releases newer than version, newest first. An empty version returns all.
+func Since(version string) ([]Release, error) {
+	if version == "" {
+		return releases, nil
+	}
+	if _, ok := parseVersion(version); !ok {
+		return nil, ErrInvalidVersion
+	}
+	out := []Release{}
+	for _, r := range releases {
+		if Compare(r.Version, version) <= 0 {
+			break
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+// Compare orders two dotted versions numerically, returning -1, 0 or 1.
+// Missing components count as 0, so 1.2 equals 1.2.0.
+func Compare(a, b string) int {
+	va, _ := parseVersion(a)
+	vb, _ := parseVersion(b)
+	for i := range va {
+		if va[i] != vb[i] {
+			if va[i] < vb[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func parseVersion(v string) ([3]int, bool) {
+	var out [3]int
+	parts := strings.Split(strings.TrimPrefix(v, "v"), ".")
+	if len(parts) > 3 {
+		return out, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return out, false
+		}
+		out[i] = n
+	}
+	return out, true
+}
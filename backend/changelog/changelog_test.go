@@ -0,0 +1,109 @@
+package changelog
+
+import (
+	"errors"
+	"testing"
+)
+
+const sample = `# Changelog
+
+Intro text is ignored.
+
+## [2.0.0] - 2026-03-01
+
+### Removed
+- The old endpoint.
+
+### Added
+- A feature whose description
+  wraps onto a second line.
+
+## 1.10.0
+
+### Fixed
+* A bug.
+
+## [1.9.2] - 2025-12-24
+`
+
+func TestParse(t *testing.T) {
+	got, err := Parse([]byte(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("got %d releases, want 3", len(got))
+	}
+	first := got[0]
+	if first.Version != "2.0.0" || first.Date != "2026-03-01" || len(first.Changes) != 2 {
+		t.Fatalf("first release = %+v", first)
+	}
+	if first.Changes[0] != (Change{Type: TypeRemoved, Summary: "The old endpoint."}) {
+		t.Errorf("change 0 = %+v", first.Changes[0])
+	}
+	if want := "A feature whose description wraps onto a second line."; first.Changes[1].Summary != want || first.Changes[1].Type != TypeAdded {
+		t.Errorf("change 1 = %+v", first.Changes[1])
+	}
+	if got[1].Version != "1.10.0" || got[1].Date != "" || got[1].Changes[0].Type != TypeFixed {
+		t.Errorf("second release = %+v", got[1])
+	}
+	if len(got[2].Changes) != 0 {
+		t.Errorf("empty release has changes %+v", got[2].Changes)
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	for name, md := range map[string]string{
+		"bad heading":  "## Next release\n",
+		"out of order": "## [1.0.0]\n## [1.1.0]\n",
+		"unknown type": "## [1.0.0]\n### Improved\n- x\n",
+		"no type":      "## [1.0.0]\n- x\n",
+	} {
+		if _, err := Parse([]byte(md)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestEmbeddedChangelog(t *testing.T) {
+	if len(releases) == 0 || Version() != releases[0].Version {
+		t.Fatalf("version %q, releases %d", Version(), len(releases))
+	}
+	for _, r := range releases {
+		if r.Date == "" || len(r.Changes) == 0 {
+			t.Errorf("release %s has no date or changes", r.Version)
+		}
+	}
+}
+
+func TestSince(t *testing.T) {
+	all, err := Since("")
+	if err != nil || len(all) != len(releases) {
+		t.Fatalf("Since(\"\") = %d releases, %v", len(all), err)
+	}
+	newer, err := Since(releases[1].Version)
+	if err != nil || len(newer) != 1 || newer[0].Version != Version() {
+		t.Errorf("Since(previous) = %+v, %v", newer, err)
+	}
+	if none, err := Since("v" + Version()); err != nil || len(none) != 0 {
+		t.Errorf("Since(current) = %+v, %v", none, err)
+	}
+	if _, err := Since("latest"); !errors.Is(err, ErrInvalidVersion) {
+		t.Errorf("Since(latest) error = %v", err)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	for _, tt := range []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.0", "1.2", 0},
+		{"1.10.0", "1.9.9", 1},
+		{"1.0.0", "2", -1},
+	} {
+		if got := Compare(tt.a, tt.b); got != tt.want {
+			t.Errorf("Compare(%s, %s) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
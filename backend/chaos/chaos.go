@@ -0,0 +1,146 @@
+package chaos
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+/**
+ * Chaos Package
+ *
+ * Development-only fault injection for resilience testing. The middleware
+ * delays, fails or drops a configurable fraction of requests on selected
+ * routes so client retry and offline-sync logic can be exercised against
+ * realistic failures. It is configured from CHAOS_* environment variables
+ * (see ConfigFromEnv) and is never installed in release mode.
+ */
+
+// Config controls which requests are disrupted and how
+type Config struct {
+	// Routes limits injection to matching requests. Each entry is a path prefix,
+	// optionally preceded by a method ("POST /api/sessions"). Empty matches everything.
+	Routes []string
+	// MaxLatency delays each matching request by between half and all of this duration
+	MaxLatency time.Duration
+	// ErrorRate is the fraction (0-1) of matching requests answered with a 500
+	ErrorRate float64
+	// DropRate is the fraction (0-1) of matching requests whose connection is closed without a response
+	DropRate float64
+}
+
+// Enabled reports whether the config injects any faults
+func (cfg Config) Enabled() bool {
+	return cfg.MaxLatency > 0 || cfg.ErrorRate > 0 || cfg.DropRate > 0
+}
+
+func (cfg Config) String() string {
+	routes := "all routes"
+	if len(cfg.Routes) > 0 {
+		routes = strings.Join(cfg.Routes, ", ")
+	}
+	return fmt.Sprintf("latency<=%v errors=%.0f%% drops=%.0f%% on %s", cfg.MaxLatency, cfg.ErrorRate*100, cfg.DropRate*100, routes)
+}
+
+// ConfigFromEnv reads CHAOS_LATENCY (Go duration), CHAOS_ERROR_RATE and CHAOS_DROP_RATE (0-1),
+// and CHAOS_ROUTES (comma-separated, e.g. "POST /api/sessions,/api/workouts")
+func ConfigFromEnv() Config {
+	var cfg Config
+	if raw := os.Getenv("CHAOS_LATENCY"); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			log.Printf("Invalid CHAOS_LATENCY=%q, ignoring", raw)
+		}
+		cfg.MaxLatency = d
+	}
+	cfg.ErrorRate = rateFromEnv("CHAOS_ERROR_RATE")
+	cfg.DropRate = rateFromEnv("CHAOS_DROP_RATE")
+	for _, r := range strings.Split(os.Getenv("CHAOS_ROUTES"), ",") {
+		if r = strings.TrimSpace(r); r != "" {
+			cfg.Routes = append(cfg.Routes, r)
+		}
+	}
+	return cfg
+}
+
+func rateFromEnv(key string) float64 {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return 0
+	}
+	rate, err := strconv.ParseFloat(raw, 64)
+	if err != nil || rate < 0 || rate > 1 {
+		log.Printf("Invalid %s=%q (want 0-1), ignoring", key, raw)
+		return 0
+	}
+	return rate
+}
+
+// Middleware injects the configured faults into matching requests
+func Middleware(cfg Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !cfg.matches(c.Request) {
+			c.Next()
+			return
+		}
+
+		if cfg.MaxLatency > 0 {
+			// Cut short by the request deadline, like a real slow network would be
+			select {
+			case <-time.After(cfg.MaxLatency/2 + time.Duration(rand.Int63n(int64(cfg.MaxLatency/2)+1))):
+			case <-c.Request.Context().Done():
+			}
+		}
+
+		if cfg.DropRate > 0 && rand.Float64() < cfg.DropRate {
+			drop(c)
+			return
+		}
+		if cfg.ErrorRate > 0 && rand.Float64() < cfg.ErrorRate {
+			c.Header("X-Chaos-Injected", "error")
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Simulated failure (chaos)"})
+			return
+		}
+		c.Next()
+	}
+}
+
+func (cfg Config) matches(req *http.Request) bool {
+	if len(cfg.Routes) == 0 {
+		return true
+	}
+	for _, route := range cfg.Routes {
+		path := route
+		if method, rest, ok := strings.Cut(route, " "); ok {
+			if !strings.EqualFold(method, req.Method) {
+				continue
+			}
+			path = strings.TrimSpace(rest)
+		}
+		path = strings.TrimRight(path, "/")
+		if req.URL.Path == path || strings.HasPrefix(req.URL.Path, path+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// drop closes the client connection without writing a response,
+// falling back to a 503 when the connection cannot be hijacked (e.g. HTTP/2)
+func drop(c *gin.Context) {
+	c.Abort()
+	conn, _, err := c.Writer.Hijack()
+	if err != nil {
+		c.Header("X-Chaos-Injected", "drop")
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Simulated dropped connection (chaos)"})
+		return
+	}
+	conn.Close()
+}
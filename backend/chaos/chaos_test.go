@@ -0,0 +1,93 @@
+package chaos
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func setupRouter(cfg Config) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Middleware(cfg))
+	ok := func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"ok": true}) }
+	r.GET("/api/workouts", ok)
+	r.GET("/api/sessions", ok)
+	r.POST("/api/sessions", ok)
+	return r
+}
+
+func TestMiddleware_ErrorOnSelectedRoutes(t *testing.T) {
+	r := setupRouter(Config{Routes: []string{"POST /api/sessions"}, ErrorRate: 1})
+
+	cases := []struct {
+		method, path string
+		want         int
+	}{
+		{"POST", "/api/sessions", http.StatusInternalServerError},
+		{"GET", "/api/sessions", http.StatusOK},
+		{"GET", "/api/workouts", http.StatusOK},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, nil))
+		if w.Code != tc.want {
+			t.Errorf("%s %s: got %d, want %d", tc.method, tc.path, w.Code, tc.want)
+		}
+	}
+}
+
+func TestMiddleware_Latency(t *testing.T) {
+	r := setupRouter(Config{MaxLatency: 40 * time.Millisecond})
+
+	start := time.Now()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/workouts", nil))
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("request took %v, want at least 20ms", elapsed)
+	}
+	if w.Code != http.StatusOK {
+		t.Errorf("got %d, want 200", w.Code)
+	}
+}
+
+func TestMiddleware_DropConnection(t *testing.T) {
+	srv := httptest.NewServer(setupRouter(Config{DropRate: 1}))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/api/workouts")
+	if err == nil {
+		resp.Body.Close()
+		t.Fatalf("expected connection error, got status %d", resp.StatusCode)
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	os.Setenv("CHAOS_LATENCY", "250ms")
+	os.Setenv("CHAOS_ERROR_RATE", "0.1")
+	os.Setenv("CHAOS_DROP_RATE", "2")
+	os.Setenv("CHAOS_ROUTES", "POST /api/sessions, /api/workouts")
+	defer func() {
+		for _, k := range []string{"CHAOS_LATENCY", "CHAOS_ERROR_RATE", "CHAOS_DROP_RATE", "CHAOS_ROUTES"} {
+			os.Unsetenv(k)
+		}
+	}()
+
+	cfg := ConfigFromEnv()
+	if cfg.MaxLatency != 250*time.Millisecond || cfg.ErrorRate != 0.1 {
+		t.Errorf("cfg = %+v", cfg)
+	}
+	if cfg.DropRate != 0 {
+		t.Errorf("out-of-range drop rate should be ignored, got %v", cfg.DropRate)
+	}
+	if len(cfg.Routes) != 2 || cfg.Routes[1] != "/api/workouts" {
+		t.Errorf("routes = %q", cfg.Routes)
+	}
+	if !cfg.Enabled() || (Config{}).Enabled() {
+		t.Error("Enabled() mismatch")
+	}
+}
@@ -0,0 +1,105 @@
+// Command liftoffctl runs maintenance tasks against the Liftoff database.
+//
+// It connects the same way as the server (DATABASE_URL, falling back to
+// ./liftoff.db) and reads the same .env file.
+//
+//	liftoffctl anonymize -yes [-password staging-pass]
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/database"
+	"liftoff/backend/models"
+	"liftoff/backend/repository"
+)
+
+const usage = `Usage: liftoffctl <command> [flags]
+
+Commands:
+  anonymize   scramble personal data in place, for staging copies of production
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	var err error
+	switch os.Args[1] {
+	case "anonymize":
+		err = anonymize(os.Args[2:])
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// anonymize rewrites the connected database so it can be shared with staging:
+// emails, passwords, notes and session metadata are replaced and credentials dropped
+func anonymize(args []string) error {
+	fs := flag.NewFlagSet("anonymize", flag.ExitOnError)
+	password := fs.String("password", "", "set every account's password to this (default: password sign-in disabled)")
+	yes := fs.Bool("yes", false, "confirm the database should be rewritten in place")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), "Usage: liftoffctl anonymize -yes [-password staging-pass]\n\n"+
+			"Rewrites the database in place. Only run it against a copy of production.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if !*yes {
+		fs.Usage()
+		return fmt.Errorf("refusing to anonymize without -yes")
+	}
+
+	passwordHash := ""
+	if *password != "" {
+		if err := auth.ValidatePassword(*password); err != nil {
+			return err
+		}
+		hash, err := auth.HashPassword(*password)
+		if err != nil {
+			return fmt.Errorf("failed to hash password: %w", err)
+		}
+		passwordHash = hash
+	}
+
+	// A fresh key per run: scrambled values cannot be matched across copies
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	db, err := database.NewDatabase()
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+	backend := "PostgreSQL"
+	if db.IsSQLite() {
+		backend = "SQLite (./liftoff.db)"
+	}
+	log.Printf("Anonymizing %s database", backend)
+
+	adminRepo := repository.NewAdminRepository(db.GetPool(), db.GetSQLite(), db.IsSQLite())
+	report, err := adminRepo.Anonymize(context.Background(), models.NewScrambler(key), passwordHash)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
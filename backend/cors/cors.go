@@ -0,0 +1,197 @@
+package cors
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+/**
+ * CORS Package
+ *
+ * Cross-origin access control for the browser frontend. Only origins on the
+ * allowlist get CORS headers, so credentialed requests (cookies) can be
+ * enabled without opening the API to every site. Preflight responses carry
+ * Access-Control-Max-Age so browsers cache them instead of sending an
+ * OPTIONS request before every call.
+ */
+
+// Config is the CORS policy applied by Middleware
+type Config struct {
+	// AllowedOrigins are exact origins ("https://app.example.com"), subdomain
+	// wildcards ("https://*.example.com") or "*" for any origin
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+// Defaults used when the corresponding CORS_* variable is unset
+var (
+	DefaultMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+	DefaultHeaders = []string{"Accept", "Content-Type", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "X-Device-ID", "Authorization"}
+	DefaultExposed = []string{"X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset", "Retry-After", "Deprecation", "Sunset", "Link", "X-Request-ID", "X-Weight-Unit"}
+)
+
+// DefaultMaxAge is how long browsers may cache a preflight response
+const DefaultMaxAge = 10 * time.Minute
+
+// ConfigFromEnv reads CORS_ALLOWED_ORIGINS (default: FRONTEND_URL), CORS_ALLOWED_METHODS,
+// CORS_ALLOWED_HEADERS and CORS_EXPOSED_HEADERS (comma-separated), CORS_ALLOW_CREDENTIALS
+// (default true) and CORS_MAX_AGE (Go duration)
+func ConfigFromEnv() Config {
+	cfg := Config{
+		AllowedOrigins:   listFromEnv("CORS_ALLOWED_ORIGINS", nil),
+		AllowedMethods:   listFromEnv("CORS_ALLOWED_METHODS", DefaultMethods),
+		AllowedHeaders:   listFromEnv("CORS_ALLOWED_HEADERS", DefaultHeaders),
+		ExposedHeaders:   listFromEnv("CORS_EXPOSED_HEADERS", DefaultExposed),
+		AllowCredentials: true,
+		MaxAge:           DefaultMaxAge,
+	}
+	if len(cfg.AllowedOrigins) == 0 {
+		origin := os.Getenv("FRONTEND_URL")
+		if origin == "" {
+			origin = "http://localhost:5173"
+		}
+		cfg.AllowedOrigins = []string{strings.TrimRight(origin, "/")}
+	}
+	if raw := os.Getenv("CORS_ALLOW_CREDENTIALS"); raw != "" {
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			log.Printf("Invalid CORS_ALLOW_CREDENTIALS=%q, ignoring", raw)
+		} else {
+			cfg.AllowCredentials = b
+		}
+	}
+	if raw := os.Getenv("CORS_MAX_AGE"); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil || d < 0 {
+			log.Printf("Invalid CORS_MAX_AGE=%q, ignoring", raw)
+		} else {
+			cfg.MaxAge = d
+		}
+	}
+	if cfg.AllowCredentials && cfg.allowsAny() {
+		// Browsers reject credentials with a wildcard origin; never reflect arbitrary origins instead
+		log.Println("CORS_ALLOWED_ORIGINS=* disables CORS_ALLOW_CREDENTIALS")
+		cfg.AllowCredentials = false
+	}
+	return cfg
+}
+
+// Allows reports whether origin is on the allowlist
+func (cfg Config) Allows(origin string) bool {
+	for _, allowed := range cfg.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+		// "https://*.example.com" matches any subdomain but not example.com itself
+		if i := strings.Index(allowed, "://*."); i >= 0 {
+			scheme, suffix := allowed[:i+3], allowed[i+4:]
+			if len(origin) > len(scheme)+len(suffix) &&
+				strings.HasPrefix(strings.ToLower(origin), strings.ToLower(scheme)) &&
+				strings.HasSuffix(strings.ToLower(origin), strings.ToLower(suffix)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (cfg Config) allowsAny() bool {
+	for _, o := range cfg.AllowedOrigins {
+		if o == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func (cfg Config) allowsMethod(method string) bool {
+	for _, m := range cfg.AllowedMethods {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// Middleware applies cfg to every request. Preflights from disallowed origins or for
+// disallowed methods get a 403; other requests pass through without CORS headers,
+// leaving the browser to block the response.
+func Middleware(cfg Config) gin.HandlerFunc {
+	methods := strings.Join(cfg.AllowedMethods, ", ")
+	headers := strings.Join(cfg.AllowedHeaders, ", ")
+	exposed := strings.Join(cfg.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(cfg.MaxAge.Seconds()))
+	anyOrigin := cfg.allowsAny() && !cfg.AllowCredentials
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+		h := c.Writer.Header()
+		h.Add("Vary", "Origin")
+
+		if origin == "" {
+			c.Next()
+			return
+		}
+		if !cfg.Allows(origin) {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if anyOrigin {
+			h.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			h.Set("Access-Control-Allow-Origin", origin)
+		}
+		if cfg.AllowCredentials {
+			h.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !preflight {
+			if exposed != "" {
+				h.Set("Access-Control-Expose-Headers", exposed)
+			}
+			c.Next()
+			return
+		}
+
+		if !cfg.allowsMethod(c.GetHeader("Access-Control-Request-Method")) {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+		h.Add("Vary", "Access-Control-Request-Method")
+		h.Add("Vary", "Access-Control-Request-Headers")
+		h.Set("Access-Control-Allow-Methods", methods)
+		h.Set("Access-Control-Allow-Headers", headers)
+		h.Set("Access-Control-Max-Age", maxAge)
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
+
+func listFromEnv(key string, def []string) []string {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def
+	}
+	var out []string
+	for _, part := range strings.Split(raw, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
@@ -0,0 +1,107 @@
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func setupRouter(cfg Config) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Middleware(cfg))
+	r.GET("/api/workouts", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"ok": true}) })
+	return r
+}
+
+func request(r *gin.Engine, method, origin, requestMethod string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/api/workouts", nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	if requestMethod != "" {
+		req.Header.Set("Access-Control-Request-Method", requestMethod)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestMiddleware_Allowlist(t *testing.T) {
+	cfg := Config{
+		AllowedOrigins:   []string{"https://app.liftoff.test", "https://*.preview.liftoff.test"},
+		AllowedMethods:   DefaultMethods,
+		AllowedHeaders:   DefaultHeaders,
+		ExposedHeaders:   []string{"X-RateLimit-Remaining"},
+		AllowCredentials: true,
+		MaxAge:           5 * time.Minute,
+	}
+	r := setupRouter(cfg)
+
+	w := request(r, "GET", "https://app.liftoff.test", "")
+	if w.Code != http.StatusOK || w.Header().Get("Access-Control-Allow-Origin") != "https://app.liftoff.test" {
+		t.Errorf("allowed origin: %d %v", w.Code, w.Header())
+	}
+	if w.Header().Get("Access-Control-Allow-Credentials") != "true" || w.Header().Get("Access-Control-Expose-Headers") != "X-RateLimit-Remaining" {
+		t.Errorf("missing credential/expose headers: %v", w.Header())
+	}
+
+	w = request(r, "GET", "https://pr-42.preview.liftoff.test", "")
+	if w.Header().Get("Access-Control-Allow-Origin") != "https://pr-42.preview.liftoff.test" {
+		t.Errorf("wildcard subdomain not allowed: %v", w.Header())
+	}
+
+	for _, origin := range []string{"https://evil.test", "https://preview.liftoff.test", "http://app.liftoff.test"} {
+		w = request(r, "GET", origin, "")
+		if w.Header().Get("Access-Control-Allow-Origin") != "" {
+			t.Errorf("%s should not get CORS headers", origin)
+		}
+	}
+}
+
+func TestMiddleware_Preflight(t *testing.T) {
+	r := setupRouter(Config{
+		AllowedOrigins: []string{"https://app.liftoff.test"},
+		AllowedMethods: []string{"GET", "POST"},
+		AllowedHeaders: []string{"Authorization"},
+		MaxAge:         5 * time.Minute,
+	})
+
+	w := request(r, "OPTIONS", "https://app.liftoff.test", "POST")
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("preflight: got %d, want 204", w.Code)
+	}
+	if w.Header().Get("Access-Control-Max-Age") != "300" || w.Header().Get("Access-Control-Allow-Headers") != "Authorization" {
+		t.Errorf("preflight headers: %v", w.Header())
+	}
+
+	if w := request(r, "OPTIONS", "https://app.liftoff.test", "DELETE"); w.Code != http.StatusForbidden {
+		t.Errorf("disallowed method: got %d, want 403", w.Code)
+	}
+	if w := request(r, "OPTIONS", "https://evil.test", "GET"); w.Code != http.StatusForbidden {
+		t.Errorf("disallowed origin: got %d, want 403", w.Code)
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("FRONTEND_URL", "https://app.liftoff.test/")
+	cfg := ConfigFromEnv()
+	if len(cfg.AllowedOrigins) != 1 || cfg.AllowedOrigins[0] != "https://app.liftoff.test" || !cfg.AllowCredentials {
+		t.Errorf("default config = %+v", cfg)
+	}
+
+	// A wildcard origin can never be combined with credentials
+	t.Setenv("CORS_ALLOWED_ORIGINS", "*")
+	t.Setenv("CORS_MAX_AGE", "1h")
+	cfg = ConfigFromEnv()
+	if cfg.AllowCredentials || cfg.MaxAge != time.Hour {
+		t.Errorf("wildcard config = %+v", cfg)
+	}
+	w := request(setupRouter(cfg), "GET", "https://anything.test", "")
+	if w.Header().Get("Access-Control-Allow-Origin") != "*" {
+		t.Errorf("wildcard: %v", w.Header())
+	}
+}
@@ -5,8 +5,10 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"time"
 
 	"liftoff/backend/auth"
+	"liftoff/backend/models"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -28,7 +30,7 @@ func MigrateSQLite(db *sql.DB) error {
 		if err := ensureAdminUserSQLite(db); err != nil {
 			return err
 		}
-		return ensureRoutinesTablesSQLite(db)
+		return ensureTablesSQLite(db)
 	}
 
 	log.Println("Running migration: add user_id to workouts, sessions, dino_game_scores")
@@ -55,7 +57,69 @@ func MigrateSQLite(db *sql.DB) error {
 	}
 
 	log.Println("Migration completed: existing data assigned to admin@liftoff.local (password: Admin123!)")
-	return ensureRoutinesTablesSQLite(db)
+	return ensureTablesSQLite(db)
+}
+
+// ensureTablesSQLite runs every idempotent schema step added after the initial schema
+func ensureTablesSQLite(db *sql.DB) error {
+	for _, ensure := range []func(*sql.DB) error{
+		ensureRoutinesTablesSQLite,
+		ensureOAuthTablesSQLite,
+		ensureRecommendationTablesSQLite,
+		ensureSessionMetadataSQLite,
+		ensureInjuryTablesSQLite,
+		ensureDeprecationTablesSQLite,
+		ensureWebAuthnTablesSQLite,
+		ensureTokenRevocationTablesSQLite,
+		ensureTokenPurposeSQLite,
+		ensureLoginAttemptTablesSQLite,
+		ensureWorkoutTargetDurationSQLite,
+		ensureAPIKeyTablesSQLite,
+		ensureAlertTablesSQLite,
+		ensureTimedExercisesSQLite,
+		ensureSetSegmentsSQLite,
+		ensureEmailChangeTablesSQLite,
+		ensureUnilateralSQLite,
+		ensureUserSessionTablesSQLite,
+		ensureUserRolesSQLite,
+		ensureRetentionTablesSQLite,
+		ensurePartnerSessionsSQLite,
+		ensurePasswordHistorySQLite,
+		ensureResetTokenUsageSQLite,
+		ensureExerciseTaxonomySQLite,
+		ensureAuditLogSQLite,
+		ensureGuestAccountsSQLite,
+		ensureLoginEventsSQLite,
+		ensureUserStatusSQLite,
+		ensureLegalHoldSQLite,
+		ensureWorkoutTypeSQLite,
+		ensureExercisePositionSQLite,
+		ensureWorkoutArchiveSQLite,
+		ensureWorkoutTagsSQLite,
+		ensureWorkoutNotesSQLite,
+		ensureExerciseTemplatesSQLite,
+		ensureWorkoutTemplatesSQLite,
+		ensureCustomWorkoutTemplatesSQLite,
+		ensureWorkoutTemplateSharingSQLite,
+		ensureExerciseGroupsSQLite,
+		ensureExerciseRestSQLite,
+		ensureIntensitySQLite,
+		ensureRepRangesSQLite,
+		ensureWarmupSetsSQLite,
+		ensureSetTypesSQLite,
+		ensureExerciseMetadataSQLite,
+		ensureExerciseMediaSQLite,
+		ensureUserPreferencesSQLite,
+		ensureCardioSQLite,
+		ensureScheduledWorkoutsSQLite,
+		ensureProgramsSQLite,
+		ensureProgressionRulesSQLite,
+	} {
+		if err := ensure(db); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // ensureRoutinesTablesSQLite creates routines and routine_workouts tables if they don't exist
@@ -122,7 +186,7 @@ func MigratePostgres(pool *pgxpool.Pool) error {
 		if err := ensureAdminUserPostgres(ctx, pool); err != nil {
 			return err
 		}
-		return ensureRoutinesTablesPostgres(ctx, pool)
+		return ensureTablesPostgres(ctx, pool)
 	}
 
 	log.Println("Running migration: add user_id to workouts, sessions, dino_game_scores")
@@ -168,7 +232,71 @@ func MigratePostgres(pool *pgxpool.Pool) error {
 	}
 
 	log.Println("Migration completed: existing data assigned to admin@liftoff.local (password: Admin123!)")
-	return ensureRoutinesTablesPostgres(ctx, pool)
+	return ensureTablesPostgres(ctx, pool)
+}
+
+// ensureTablesPostgres runs every idempotent schema step added after the initial schema
+func ensureTablesPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	for _, ensure := range []func(context.Context, *pgxpool.Pool) error{
+		ensureRoutinesTablesPostgres,
+		ensureOAuthTablesPostgres,
+		ensureRecommendationTablesPostgres,
+		ensureSessionMetadataPostgres,
+		ensureInjuryTablesPostgres,
+		ensureDeprecationTablesPostgres,
+		ensureWebAuthnTablesPostgres,
+		ensureTokenRevocationTablesPostgres,
+		ensureTokenPurposePostgres,
+		ensureLoginAttemptTablesPostgres,
+		ensureWorkoutTargetDurationPostgres,
+		ensureAPIKeyTablesPostgres,
+		ensureAlertTablesPostgres,
+		ensureTimedExercisesPostgres,
+		ensureSetSegmentsPostgres,
+		ensureEmailChangeTablesPostgres,
+		ensureUnilateralPostgres,
+		ensureUserSessionTablesPostgres,
+		ensureUserRolesPostgres,
+		ensureRetentionTablesPostgres,
+		ensurePartnerSessionsPostgres,
+		ensurePasswordHistoryPostgres,
+		ensureResetTokenUsagePostgres,
+		ensureExerciseTaxonomyPostgres,
+		ensureAuditLogPostgres,
+		ensureGuestAccountsPostgres,
+		ensureLoginEventsPostgres,
+		ensureUserStatusPostgres,
+		ensureLegalHoldPostgres,
+		ensureWorkoutTypePostgres,
+		ensureExercisePositionPostgres,
+		ensureWorkoutArchivePostgres,
+		ensureWorkoutTagsPostgres,
+		ensureWorkoutNotesPostgres,
+		ensureExerciseTemplatesPostgres,
+		ensureWorkoutTemplatesPostgres,
+		ensureCustomWorkoutTemplatesPostgres,
+		ensureWorkoutTemplateSharingPostgres,
+		ensureExerciseGroupsPostgres,
+		ensureExerciseRestPostgres,
+		ensureIntensityPostgres,
+		ensureRepRangesPostgres,
+		ensureWarmupSetsPostgres,
+		ensureSetTypesPostgres,
+		ensureExerciseMetadataPostgres,
+		ensureExerciseMediaPostgres,
+		ensureUserPreferencesPostgres,
+		ensureCardioPostgres,
+		ensureScheduledWorkoutsPostgres,
+		ensureProgramsPostgres,
+		ensureProgressionRulesPostgres,
+		// Last, as the views read columns added above
+		ensureAnalyticsViewsPostgres,
+	} {
+		if err := ensure(ctx, pool); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // ensureRoutinesTablesPostgres creates routines and routine_workouts tables if they don't exist
@@ -216,3 +344,1809 @@ func ensureAdminUserPostgres(ctx context.Context, pool *pgxpool.Pool) error {
 		adminUserID, adminEmail, hash)
 	return err
 }
+
+// ensureOAuthTablesSQLite creates the oauth_identities table used for social sign-in
+func ensureOAuthTablesSQLite(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS oauth_identities (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		provider TEXT NOT NULL,
+		subject TEXT NOT NULL,
+		email TEXT NOT NULL DEFAULT '',
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (provider, subject)
+	)`)
+	if err != nil {
+		return fmt.Errorf("create oauth_identities: %w", err)
+	}
+	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_oauth_identities_user_id ON oauth_identities(user_id)`)
+	return err
+}
+
+// ensureOAuthTablesPostgres creates the oauth_identities table used for social sign-in
+func ensureOAuthTablesPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `CREATE TABLE IF NOT EXISTS oauth_identities (
+		id VARCHAR(36) PRIMARY KEY,
+		user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		provider VARCHAR(50) NOT NULL,
+		subject VARCHAR(255) NOT NULL,
+		email VARCHAR(255) NOT NULL DEFAULT '',
+		created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+		UNIQUE (provider, subject)
+	)`)
+	if err != nil {
+		return fmt.Errorf("create oauth_identities: %w", err)
+	}
+	_, err = pool.Exec(ctx, `CREATE INDEX IF NOT EXISTS idx_oauth_identities_user_id ON oauth_identities(user_id)`)
+	return err
+}
+
+// ensureRecommendationTablesSQLite creates the precomputed template_recommendations table
+func ensureRecommendationTablesSQLite(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS template_recommendations (
+		user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		template_id TEXT NOT NULL,
+		kind TEXT NOT NULL,
+		name TEXT NOT NULL,
+		score REAL NOT NULL,
+		reasons TEXT NOT NULL DEFAULT '[]',
+		computed_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (user_id, kind, template_id)
+	)`)
+	if err != nil {
+		return fmt.Errorf("create template_recommendations: %w", err)
+	}
+	return nil
+}
+
+// ensureRecommendationTablesPostgres creates the precomputed template_recommendations table
+func ensureRecommendationTablesPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `CREATE TABLE IF NOT EXISTS template_recommendations (
+		user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		template_id VARCHAR(100) NOT NULL,
+		kind VARCHAR(50) NOT NULL,
+		name VARCHAR(255) NOT NULL,
+		score DOUBLE PRECISION NOT NULL,
+		reasons TEXT NOT NULL DEFAULT '[]',
+		computed_at TIMESTAMP NOT NULL DEFAULT NOW(),
+		PRIMARY KEY (user_id, kind, template_id)
+	)`)
+	if err != nil {
+		return fmt.Errorf("create template_recommendations: %w", err)
+	}
+	return nil
+}
+
+// addColumnSQLite adds a column unless it already exists (SQLite lacks ADD COLUMN IF NOT EXISTS)
+func addColumnSQLite(db *sql.DB, table, column, definition string) error {
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`, table, column).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("inspect %s: %w", table, err)
+	}
+	if count > 0 {
+		return nil
+	}
+	if _, err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)); err != nil {
+		return fmt.Errorf("add %s.%s: %w", table, column, err)
+	}
+	return nil
+}
+
+// ensureSessionMetadataSQLite adds the JSON metadata column to workout_sessions
+func ensureSessionMetadataSQLite(db *sql.DB) error {
+	return addColumnSQLite(db, "workout_sessions", "metadata", "TEXT")
+}
+
+// ensureSessionMetadataPostgres adds the JSON metadata column to workout_sessions
+func ensureSessionMetadataPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `ALTER TABLE workout_sessions ADD COLUMN IF NOT EXISTS metadata TEXT`)
+	if err != nil {
+		return fmt.Errorf("add workout_sessions.metadata: %w", err)
+	}
+	return nil
+}
+
+// ensureInjuryTablesSQLite creates the injuries table used for contraindication warnings
+func ensureInjuryTablesSQLite(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS injuries (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		name TEXT NOT NULL,
+		restrictions TEXT NOT NULL DEFAULT '[]',
+		notes TEXT NOT NULL DEFAULT '',
+		active BOOLEAN NOT NULL DEFAULT 1,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		resolved_at DATETIME
+	)`)
+	if err != nil {
+		return fmt.Errorf("create injuries: %w", err)
+	}
+	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_injuries_user_id ON injuries(user_id)`)
+	return err
+}
+
+// ensureInjuryTablesPostgres creates the injuries table used for contraindication warnings
+func ensureInjuryTablesPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `CREATE TABLE IF NOT EXISTS injuries (
+		id VARCHAR(36) PRIMARY KEY,
+		user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		name VARCHAR(100) NOT NULL,
+		restrictions TEXT NOT NULL DEFAULT '[]',
+		notes TEXT NOT NULL DEFAULT '',
+		active BOOLEAN NOT NULL DEFAULT true,
+		created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+		resolved_at TIMESTAMP
+	)`)
+	if err != nil {
+		return fmt.Errorf("create injuries: %w", err)
+	}
+	_, err = pool.Exec(ctx, `CREATE INDEX IF NOT EXISTS idx_injuries_user_id ON injuries(user_id)`)
+	return err
+}
+
+// ensureDeprecationTablesSQLite creates the deprecated_endpoint_usage table
+func ensureDeprecationTablesSQLite(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS deprecated_endpoint_usage (
+		method TEXT NOT NULL,
+		path TEXT NOT NULL,
+		user_id TEXT NOT NULL,
+		token_id TEXT NOT NULL,
+		user_agent TEXT NOT NULL DEFAULT '',
+		call_count INTEGER NOT NULL DEFAULT 0,
+		first_seen DATETIME NOT NULL,
+		last_seen DATETIME NOT NULL,
+		PRIMARY KEY (method, path, user_id, token_id)
+	)`)
+	if err != nil {
+		return fmt.Errorf("create deprecated_endpoint_usage: %w", err)
+	}
+	return nil
+}
+
+// ensureDeprecationTablesPostgres creates the deprecated_endpoint_usage table
+func ensureDeprecationTablesPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `CREATE TABLE IF NOT EXISTS deprecated_endpoint_usage (
+		method VARCHAR(10) NOT NULL,
+		path VARCHAR(255) NOT NULL,
+		user_id VARCHAR(36) NOT NULL,
+		token_id VARCHAR(64) NOT NULL,
+		user_agent TEXT NOT NULL DEFAULT '',
+		call_count BIGINT NOT NULL DEFAULT 0,
+		first_seen TIMESTAMP NOT NULL,
+		last_seen TIMESTAMP NOT NULL,
+		PRIMARY KEY (method, path, user_id, token_id)
+	)`)
+	if err != nil {
+		return fmt.Errorf("create deprecated_endpoint_usage: %w", err)
+	}
+	return nil
+}
+
+// ensureWebAuthnTablesSQLite creates passkey credential and challenge tables
+func ensureWebAuthnTablesSQLite(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS webauthn_credentials (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		name TEXT NOT NULL,
+		public_key BLOB NOT NULL,
+		algorithm INTEGER NOT NULL,
+		sign_count INTEGER NOT NULL DEFAULT 0,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		last_used_at DATETIME
+	)`)
+	if err != nil {
+		return fmt.Errorf("create webauthn_credentials: %w", err)
+	}
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_webauthn_credentials_user_id ON webauthn_credentials(user_id)`); err != nil {
+		return err
+	}
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS webauthn_challenges (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL DEFAULT '',
+		kind TEXT NOT NULL,
+		challenge TEXT NOT NULL,
+		expires_at DATETIME NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("create webauthn_challenges: %w", err)
+	}
+	return nil
+}
+
+// ensureWebAuthnTablesPostgres creates passkey credential and challenge tables
+func ensureWebAuthnTablesPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `CREATE TABLE IF NOT EXISTS webauthn_credentials (
+		id VARCHAR(512) PRIMARY KEY,
+		user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		name VARCHAR(100) NOT NULL,
+		public_key BYTEA NOT NULL,
+		algorithm INTEGER NOT NULL,
+		sign_count BIGINT NOT NULL DEFAULT 0,
+		created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+		last_used_at TIMESTAMP
+	)`)
+	if err != nil {
+		return fmt.Errorf("create webauthn_credentials: %w", err)
+	}
+	if _, err := pool.Exec(ctx, `CREATE INDEX IF NOT EXISTS idx_webauthn_credentials_user_id ON webauthn_credentials(user_id)`); err != nil {
+		return err
+	}
+	_, err = pool.Exec(ctx, `CREATE TABLE IF NOT EXISTS webauthn_challenges (
+		id VARCHAR(36) PRIMARY KEY,
+		user_id VARCHAR(36) NOT NULL DEFAULT '',
+		kind VARCHAR(20) NOT NULL,
+		challenge VARCHAR(100) NOT NULL,
+		expires_at TIMESTAMP NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("create webauthn_challenges: %w", err)
+	}
+	return nil
+}
+
+// ensureTokenRevocationTablesSQLite creates the revoked token denylist and per-user cutoffs
+func ensureTokenRevocationTablesSQLite(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS revoked_tokens (
+		jti TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		expires_at INTEGER NOT NULL,
+		revoked_at INTEGER NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("create revoked_tokens: %w", err)
+	}
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_revoked_tokens_expires_at ON revoked_tokens(expires_at)`); err != nil {
+		return err
+	}
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS user_token_cutoffs (
+		user_id TEXT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+		revoked_before INTEGER NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("create user_token_cutoffs: %w", err)
+	}
+	return nil
+}
+
+// ensureTokenRevocationTablesPostgres creates the revoked token denylist and per-user cutoffs
+func ensureTokenRevocationTablesPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `CREATE TABLE IF NOT EXISTS revoked_tokens (
+		jti VARCHAR(36) PRIMARY KEY,
+		user_id VARCHAR(36) NOT NULL,
+		expires_at BIGINT NOT NULL,
+		revoked_at BIGINT NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("create revoked_tokens: %w", err)
+	}
+	if _, err := pool.Exec(ctx, `CREATE INDEX IF NOT EXISTS idx_revoked_tokens_expires_at ON revoked_tokens(expires_at)`); err != nil {
+		return err
+	}
+	_, err = pool.Exec(ctx, `CREATE TABLE IF NOT EXISTS user_token_cutoffs (
+		user_id VARCHAR(36) PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+		revoked_before BIGINT NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("create user_token_cutoffs: %w", err)
+	}
+	return nil
+}
+
+// ensureTokenPurposeSQLite lets password_reset_tokens hold other one-time tokens (magic links)
+func ensureTokenPurposeSQLite(db *sql.DB) error {
+	return addColumnSQLite(db, "password_reset_tokens", "purpose", "TEXT NOT NULL DEFAULT 'password_reset'")
+}
+
+// ensureTokenPurposePostgres lets password_reset_tokens hold other one-time tokens (magic links)
+func ensureTokenPurposePostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `ALTER TABLE password_reset_tokens ADD COLUMN IF NOT EXISTS purpose VARCHAR(20) NOT NULL DEFAULT 'password_reset'`)
+	if err != nil {
+		return fmt.Errorf("add password_reset_tokens.purpose: %w", err)
+	}
+	return nil
+}
+
+// ensureLoginAttemptTablesSQLite creates the login_attempts table used for lockouts
+func ensureLoginAttemptTablesSQLite(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS login_attempts (
+		attempt_key TEXT PRIMARY KEY,
+		failures INTEGER NOT NULL DEFAULT 0,
+		last_failure INTEGER NOT NULL,
+		locked_until INTEGER NOT NULL DEFAULT 0
+	)`)
+	if err != nil {
+		return fmt.Errorf("create login_attempts: %w", err)
+	}
+	return nil
+}
+
+// ensureLoginAttemptTablesPostgres creates the login_attempts table used for lockouts
+func ensureLoginAttemptTablesPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `CREATE TABLE IF NOT EXISTS login_attempts (
+		attempt_key VARCHAR(320) PRIMARY KEY,
+		failures INTEGER NOT NULL DEFAULT 0,
+		last_failure BIGINT NOT NULL,
+		locked_until BIGINT NOT NULL DEFAULT 0
+	)`)
+	if err != nil {
+		return fmt.Errorf("create login_attempts: %w", err)
+	}
+	return nil
+}
+
+// ensureWorkoutTargetDurationSQLite adds the optional per-workout session duration goal
+func ensureWorkoutTargetDurationSQLite(db *sql.DB) error {
+	return addColumnSQLite(db, "workouts", "target_duration_minutes", "INTEGER")
+}
+
+// ensureWorkoutTargetDurationPostgres adds the optional per-workout session duration goal
+func ensureWorkoutTargetDurationPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `ALTER TABLE workouts ADD COLUMN IF NOT EXISTS target_duration_minutes INTEGER`)
+	if err != nil {
+		return fmt.Errorf("add workouts.target_duration_minutes: %w", err)
+	}
+	return nil
+}
+
+// ensureAPIKeyTablesSQLite creates the api_keys table for personal API keys
+func ensureAPIKeyTablesSQLite(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS api_keys (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		name TEXT NOT NULL,
+		prefix TEXT NOT NULL,
+		key_hash TEXT NOT NULL UNIQUE,
+		created_at INTEGER NOT NULL,
+		last_used_at INTEGER
+	)`)
+	if err != nil {
+		return fmt.Errorf("create api_keys: %w", err)
+	}
+	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON api_keys(user_id)`)
+	return err
+}
+
+// ensureAPIKeyTablesPostgres creates the api_keys table for personal API keys
+func ensureAPIKeyTablesPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `CREATE TABLE IF NOT EXISTS api_keys (
+		id VARCHAR(36) PRIMARY KEY,
+		user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		name VARCHAR(100) NOT NULL,
+		prefix VARCHAR(16) NOT NULL,
+		key_hash VARCHAR(64) NOT NULL UNIQUE,
+		created_at BIGINT NOT NULL,
+		last_used_at BIGINT
+	)`)
+	if err != nil {
+		return fmt.Errorf("create api_keys: %w", err)
+	}
+	_, err = pool.Exec(ctx, `CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON api_keys(user_id)`)
+	return err
+}
+
+// ensureAlertTablesSQLite creates the user_alerts table for in-app alerts
+func ensureAlertTablesSQLite(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS user_alerts (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		kind TEXT NOT NULL,
+		message TEXT NOT NULL,
+		created_at INTEGER NOT NULL,
+		dismissed_at INTEGER
+	)`)
+	if err != nil {
+		return fmt.Errorf("create user_alerts: %w", err)
+	}
+	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_user_alerts_user_id ON user_alerts(user_id, created_at)`)
+	return err
+}
+
+// ensureAlertTablesPostgres creates the user_alerts table for in-app alerts
+func ensureAlertTablesPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `CREATE TABLE IF NOT EXISTS user_alerts (
+		id VARCHAR(36) PRIMARY KEY,
+		user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		kind VARCHAR(50) NOT NULL,
+		message TEXT NOT NULL,
+		created_at BIGINT NOT NULL,
+		dismissed_at BIGINT
+	)`)
+	if err != nil {
+		return fmt.Errorf("create user_alerts: %w", err)
+	}
+	_, err = pool.Exec(ctx, `CREATE INDEX IF NOT EXISTS idx_user_alerts_user_id ON user_alerts(user_id, created_at)`)
+	return err
+}
+
+// ensureTimedExercisesSQLite adds duration mode to exercises and held time to logged sets
+func ensureTimedExercisesSQLite(db *sql.DB) error {
+	if err := addColumnSQLite(db, "exercises", "mode", "TEXT NOT NULL DEFAULT 'reps'"); err != nil {
+		return err
+	}
+	if err := addColumnSQLite(db, "exercises", "duration_seconds", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	return addColumnSQLite(db, "exercise_sets", "duration_seconds", "INTEGER")
+}
+
+// ensureTimedExercisesPostgres adds duration mode to exercises and held time to logged sets
+func ensureTimedExercisesPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	for _, stmt := range []string{
+		`ALTER TABLE exercises ADD COLUMN IF NOT EXISTS mode VARCHAR(20) NOT NULL DEFAULT 'reps'`,
+		`ALTER TABLE exercises ADD COLUMN IF NOT EXISTS duration_seconds INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE exercise_sets ADD COLUMN IF NOT EXISTS duration_seconds INTEGER`,
+	} {
+		if _, err := pool.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("add timed exercise columns: %w", err)
+		}
+	}
+	return nil
+}
+
+// ensureSetSegmentsSQLite adds drop/rest-pause segments to exercise_sets. segment_volume
+// caches their weight x reps so volume queries need not parse the JSON.
+func ensureSetSegmentsSQLite(db *sql.DB) error {
+	for _, col := range [][2]string{
+		{"technique", "TEXT NOT NULL DEFAULT ''"},
+		{"segments", "TEXT"},
+		{"segment_volume", "REAL NOT NULL DEFAULT 0"},
+	} {
+		if err := addColumnSQLite(db, "exercise_sets", col[0], col[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureSetSegmentsPostgres adds drop/rest-pause segments to exercise_sets
+func ensureSetSegmentsPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	for _, stmt := range []string{
+		`ALTER TABLE exercise_sets ADD COLUMN IF NOT EXISTS technique VARCHAR(20) NOT NULL DEFAULT ''`,
+		`ALTER TABLE exercise_sets ADD COLUMN IF NOT EXISTS segments TEXT`,
+		`ALTER TABLE exercise_sets ADD COLUMN IF NOT EXISTS segment_volume DECIMAL(12,2) NOT NULL DEFAULT 0`,
+	} {
+		if _, err := pool.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("add exercise set segment columns: %w", err)
+		}
+	}
+	return nil
+}
+
+// ensureEmailChangeTablesSQLite creates pending_email_changes for confirmed email changes
+func ensureEmailChangeTablesSQLite(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS pending_email_changes (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		new_email TEXT NOT NULL,
+		token_hash TEXT NOT NULL UNIQUE,
+		expires_at INTEGER NOT NULL,
+		created_at INTEGER NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("create pending_email_changes: %w", err)
+	}
+	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_pending_email_changes_user_id ON pending_email_changes(user_id)`)
+	return err
+}
+
+// ensureEmailChangeTablesPostgres creates pending_email_changes for confirmed email changes
+func ensureEmailChangeTablesPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `CREATE TABLE IF NOT EXISTS pending_email_changes (
+		id VARCHAR(36) PRIMARY KEY,
+		user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		new_email VARCHAR(255) NOT NULL,
+		token_hash VARCHAR(64) NOT NULL UNIQUE,
+		expires_at BIGINT NOT NULL,
+		created_at BIGINT NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("create pending_email_changes: %w", err)
+	}
+	_, err = pool.Exec(ctx, `CREATE INDEX IF NOT EXISTS idx_pending_email_changes_user_id ON pending_email_changes(user_id)`)
+	return err
+}
+
+// ensureUnilateralSQLite adds unilateral exercises and per-side results to exercise_sets.
+// side_volume caches the right side's weight x reps, like segment_volume.
+func ensureUnilateralSQLite(db *sql.DB) error {
+	if err := addColumnSQLite(db, "exercises", "unilateral", "BOOLEAN NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if err := addColumnSQLite(db, "exercise_sets", "sides", "TEXT"); err != nil {
+		return err
+	}
+	return addColumnSQLite(db, "exercise_sets", "side_volume", "REAL NOT NULL DEFAULT 0")
+}
+
+// ensureUnilateralPostgres adds unilateral exercises and per-side results to exercise_sets
+func ensureUnilateralPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	for _, stmt := range []string{
+		`ALTER TABLE exercises ADD COLUMN IF NOT EXISTS unilateral BOOLEAN NOT NULL DEFAULT FALSE`,
+		`ALTER TABLE exercise_sets ADD COLUMN IF NOT EXISTS sides TEXT`,
+		`ALTER TABLE exercise_sets ADD COLUMN IF NOT EXISTS side_volume DECIMAL(12,2) NOT NULL DEFAULT 0`,
+	} {
+		if _, err := pool.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("add unilateral columns: %w", err)
+		}
+	}
+	return nil
+}
+
+// ensureUserSessionTablesSQLite creates user_sessions for signed-in device management
+func ensureUserSessionTablesSQLite(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS user_sessions (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		device TEXT NOT NULL DEFAULT '',
+		ip TEXT NOT NULL DEFAULT '',
+		issued_at INTEGER NOT NULL,
+		expires_at INTEGER NOT NULL,
+		created_at INTEGER NOT NULL,
+		last_seen_at INTEGER NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("create user_sessions: %w", err)
+	}
+	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_user_sessions_user_id ON user_sessions(user_id)`)
+	return err
+}
+
+// ensureUserSessionTablesPostgres creates user_sessions for signed-in device management
+func ensureUserSessionTablesPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `CREATE TABLE IF NOT EXISTS user_sessions (
+		id VARCHAR(36) PRIMARY KEY,
+		user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		device VARCHAR(255) NOT NULL DEFAULT '',
+		ip VARCHAR(45) NOT NULL DEFAULT '',
+		issued_at BIGINT NOT NULL,
+		expires_at BIGINT NOT NULL,
+		created_at BIGINT NOT NULL,
+		last_seen_at BIGINT NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("create user_sessions: %w", err)
+	}
+	_, err = pool.Exec(ctx, `CREATE INDEX IF NOT EXISTS idx_user_sessions_user_id ON user_sessions(user_id)`)
+	return err
+}
+
+// ensureUserRolesSQLite adds the role column that RequireRole checks
+func ensureUserRolesSQLite(db *sql.DB) error {
+	return addColumnSQLite(db, "users", "role", "TEXT NOT NULL DEFAULT 'user'")
+}
+
+// ensureUserRolesPostgres adds the role column that RequireRole checks
+func ensureUserRolesPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	if _, err := pool.Exec(ctx, `ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'user'`); err != nil {
+		return fmt.Errorf("add users.role: %w", err)
+	}
+	return nil
+}
+
+// ensureRetentionTablesSQLite adds activity tracking and the inactivity retention tables
+func ensureRetentionTablesSQLite(db *sql.DB) error {
+	if err := addColumnSQLite(db, "users", "last_active_at", "INTEGER"); err != nil {
+		return err
+	}
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS retention_warnings (
+		user_id TEXT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+		warned_at INTEGER NOT NULL,
+		delete_after INTEGER NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("create retention_warnings: %w", err)
+	}
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS retention_deletions (
+		user_id TEXT PRIMARY KEY,
+		last_active_at INTEGER NOT NULL,
+		deleted_at INTEGER NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("create retention_deletions: %w", err)
+	}
+	return nil
+}
+
+// ensureRetentionTablesPostgres adds activity tracking and the inactivity retention tables
+func ensureRetentionTablesPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	if _, err := pool.Exec(ctx, `ALTER TABLE users ADD COLUMN IF NOT EXISTS last_active_at BIGINT`); err != nil {
+		return fmt.Errorf("add users.last_active_at: %w", err)
+	}
+	_, err := pool.Exec(ctx, `CREATE TABLE IF NOT EXISTS retention_warnings (
+		user_id VARCHAR(36) PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+		warned_at BIGINT NOT NULL,
+		delete_after BIGINT NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("create retention_warnings: %w", err)
+	}
+	_, err = pool.Exec(ctx, `CREATE TABLE IF NOT EXISTS retention_deletions (
+		user_id VARCHAR(36) PRIMARY KEY,
+		last_active_at BIGINT NOT NULL,
+		deleted_at BIGINT NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("create retention_deletions: %w", err)
+	}
+	return nil
+}
+
+// ensurePartnerSessionsSQLite creates the table linking training partners' sessions
+func ensurePartnerSessionsSQLite(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS partner_sessions (
+		id TEXT PRIMARY KEY,
+		host_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		host_session_id TEXT NOT NULL UNIQUE,
+		partner_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		partner_session_id TEXT,
+		status TEXT NOT NULL DEFAULT 'invited',
+		created_at INTEGER NOT NULL,
+		joined_at INTEGER
+	)`)
+	if err != nil {
+		return fmt.Errorf("create partner_sessions: %w", err)
+	}
+	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_partner_sessions_partner_id ON partner_sessions(partner_id)`)
+	return err
+}
+
+// ensurePartnerSessionsPostgres creates the table linking training partners' sessions
+func ensurePartnerSessionsPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `CREATE TABLE IF NOT EXISTS partner_sessions (
+		id VARCHAR(36) PRIMARY KEY,
+		host_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		host_session_id VARCHAR(36) NOT NULL UNIQUE,
+		partner_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		partner_session_id VARCHAR(36),
+		status VARCHAR(16) NOT NULL DEFAULT 'invited',
+		created_at BIGINT NOT NULL,
+		joined_at BIGINT
+	)`)
+	if err != nil {
+		return fmt.Errorf("create partner_sessions: %w", err)
+	}
+	_, err = pool.Exec(ctx, `CREATE INDEX IF NOT EXISTS idx_partner_sessions_partner_id ON partner_sessions(partner_id)`)
+	return err
+}
+
+// ensurePasswordHistorySQLite creates the table of previous password hashes
+func ensurePasswordHistorySQLite(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS password_history (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		password_hash TEXT NOT NULL,
+		created_at INTEGER NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("create password_history: %w", err)
+	}
+	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_password_history_user_id ON password_history(user_id, created_at)`)
+	return err
+}
+
+// ensurePasswordHistoryPostgres creates the table of previous password hashes
+func ensurePasswordHistoryPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `CREATE TABLE IF NOT EXISTS password_history (
+		id VARCHAR(36) PRIMARY KEY,
+		user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		password_hash TEXT NOT NULL,
+		created_at BIGINT NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("create password_history: %w", err)
+	}
+	_, err = pool.Exec(ctx, `CREATE INDEX IF NOT EXISTS idx_password_history_user_id ON password_history(user_id, created_at)`)
+	return err
+}
+
+// ensureResetTokenUsageSQLite records when a password reset token was redeemed
+func ensureResetTokenUsageSQLite(db *sql.DB) error {
+	return addColumnSQLite(db, "password_reset_tokens", "used_at", "DATETIME")
+}
+
+// ensureResetTokenUsagePostgres records when a password reset token was redeemed
+func ensureResetTokenUsagePostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `ALTER TABLE password_reset_tokens ADD COLUMN IF NOT EXISTS used_at TIMESTAMP`)
+	if err != nil {
+		return fmt.Errorf("add password_reset_tokens.used_at: %w", err)
+	}
+	return nil
+}
+
+// ensureExerciseTaxonomySQLite creates the admin-managed exercise categories and
+// seeds them on first run
+func ensureExerciseTaxonomySQLite(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS exercise_categories (
+		id TEXT PRIMARY KEY,
+		parent_id TEXT REFERENCES exercise_categories(id) ON DELETE RESTRICT,
+		name TEXT NOT NULL UNIQUE,
+		position INTEGER NOT NULL DEFAULT 0,
+		created_at INTEGER NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("create exercise_categories: %w", err)
+	}
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS exercise_library (
+		name TEXT PRIMARY KEY,
+		category_id TEXT NOT NULL REFERENCES exercise_categories(id) ON DELETE RESTRICT
+	)`)
+	if err != nil {
+		return fmt.Errorf("create exercise_library: %w", err)
+	}
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM exercise_categories`).Scan(&count); err != nil || count > 0 {
+		return err
+	}
+	now := time.Now().Unix()
+	for _, c := range models.DefaultExerciseCategories {
+		_, err := db.Exec(`INSERT INTO exercise_categories (id, parent_id, name, position, created_at) VALUES (?, NULLIF(?, ''), ?, ?, ?)`,
+			c.ID, c.ParentID, c.Name, c.Position, now)
+		if err != nil {
+			return fmt.Errorf("seed exercise_categories: %w", err)
+		}
+	}
+	for name, categoryID := range models.DefaultExerciseLibraryCategories {
+		if _, err := db.Exec(`INSERT OR IGNORE INTO exercise_library (name, category_id) VALUES (?, ?)`, name, categoryID); err != nil {
+			return fmt.Errorf("seed exercise_library: %w", err)
+		}
+	}
+	return nil
+}
+
+// ensureExerciseTaxonomyPostgres creates the admin-managed exercise categories and
+// seeds them on first run
+func ensureExerciseTaxonomyPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `CREATE TABLE IF NOT EXISTS exercise_categories (
+		id VARCHAR(36) PRIMARY KEY,
+		parent_id VARCHAR(36) REFERENCES exercise_categories(id) ON DELETE RESTRICT,
+		name VARCHAR(100) NOT NULL UNIQUE,
+		position INTEGER NOT NULL DEFAULT 0,
+		created_at BIGINT NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("create exercise_categories: %w", err)
+	}
+	_, err = pool.Exec(ctx, `CREATE TABLE IF NOT EXISTS exercise_library (
+		name VARCHAR(100) PRIMARY KEY,
+		category_id VARCHAR(36) NOT NULL REFERENCES exercise_categories(id) ON DELETE RESTRICT
+	)`)
+	if err != nil {
+		return fmt.Errorf("create exercise_library: %w", err)
+	}
+	var count int
+	if err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM exercise_categories`).Scan(&count); err != nil || count > 0 {
+		return err
+	}
+	now := time.Now().Unix()
+	for _, c := range models.DefaultExerciseCategories {
+		_, err := pool.Exec(ctx, `INSERT INTO exercise_categories (id, parent_id, name, position, created_at) VALUES ($1, NULLIF($2, ''), $3, $4, $5)`,
+			c.ID, c.ParentID, c.Name, c.Position, now)
+		if err != nil {
+			return fmt.Errorf("seed exercise_categories: %w", err)
+		}
+	}
+	for name, categoryID := range models.DefaultExerciseLibraryCategories {
+		_, err := pool.Exec(ctx, `INSERT INTO exercise_library (name, category_id) VALUES ($1, $2) ON CONFLICT (name) DO NOTHING`, name, categoryID)
+		if err != nil {
+			return fmt.Errorf("seed exercise_library: %w", err)
+		}
+	}
+	return nil
+}
+
+// ensureAuditLogSQLite creates the audit_log table recording changes users and
+// admins make to account history
+func ensureAuditLogSQLite(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS audit_log (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		actor_id TEXT NOT NULL,
+		action TEXT NOT NULL,
+		detail TEXT NOT NULL DEFAULT '{}',
+		created_at INTEGER NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("create audit_log: %w", err)
+	}
+	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_audit_log_user_id ON audit_log(user_id, created_at)`)
+	return err
+}
+
+// ensureAuditLogPostgres creates the audit_log table recording changes users and
+// admins make to account history
+func ensureAuditLogPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `CREATE TABLE IF NOT EXISTS audit_log (
+		id VARCHAR(36) PRIMARY KEY,
+		user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		actor_id VARCHAR(36) NOT NULL,
+		action VARCHAR(50) NOT NULL,
+		detail TEXT NOT NULL DEFAULT '{}',
+		created_at BIGINT NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("create audit_log: %w", err)
+	}
+	_, err = pool.Exec(ctx, `CREATE INDEX IF NOT EXISTS idx_audit_log_user_id ON audit_log(user_id, created_at)`)
+	return err
+}
+
+// ensureGuestAccountsSQLite creates the guest_accounts table marking provisional
+// users created for a device before registration
+func ensureGuestAccountsSQLite(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS guest_accounts (
+		user_id TEXT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+		device_hash TEXT NOT NULL UNIQUE,
+		created_at INTEGER NOT NULL,
+		last_seen_at INTEGER NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("create guest_accounts: %w", err)
+	}
+	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_guest_accounts_last_seen_at ON guest_accounts(last_seen_at)`)
+	return err
+}
+
+// ensureGuestAccountsPostgres creates the guest_accounts table marking provisional
+// users created for a device before registration
+func ensureGuestAccountsPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `CREATE TABLE IF NOT EXISTS guest_accounts (
+		user_id VARCHAR(36) PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+		device_hash VARCHAR(64) NOT NULL UNIQUE,
+		created_at BIGINT NOT NULL,
+		last_seen_at BIGINT NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("create guest_accounts: %w", err)
+	}
+	_, err = pool.Exec(ctx, `CREATE INDEX IF NOT EXISTS idx_guest_accounts_last_seen_at ON guest_accounts(last_seen_at)`)
+	return err
+}
+
+// ensureLoginEventsSQLite creates the login_events table recording successful
+// password sign-ins, used to spot sign-ins from new devices
+func ensureLoginEventsSQLite(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS login_events (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		ip TEXT NOT NULL,
+		user_agent TEXT NOT NULL,
+		created_at INTEGER NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("create login_events: %w", err)
+	}
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_login_events_user_device ON login_events(user_id, ip, user_agent)`); err != nil {
+		return err
+	}
+	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_login_events_created_at ON login_events(created_at)`)
+	return err
+}
+
+// ensureLoginEventsPostgres creates the login_events table recording successful
+// password sign-ins, used to spot sign-ins from new devices
+func ensureLoginEventsPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `CREATE TABLE IF NOT EXISTS login_events (
+		id VARCHAR(36) PRIMARY KEY,
+		user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		ip VARCHAR(45) NOT NULL,
+		user_agent VARCHAR(255) NOT NULL,
+		created_at BIGINT NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("create login_events: %w", err)
+	}
+	if _, err := pool.Exec(ctx, `CREATE INDEX IF NOT EXISTS idx_login_events_user_device ON login_events(user_id, ip, user_agent)`); err != nil {
+		return err
+	}
+	_, err = pool.Exec(ctx, `CREATE INDEX IF NOT EXISTS idx_login_events_created_at ON login_events(created_at)`)
+	return err
+}
+
+// ensureUserStatusSQLite adds the status column used to suspend accounts
+func ensureUserStatusSQLite(db *sql.DB) error {
+	return addColumnSQLite(db, "users", "status", "TEXT NOT NULL DEFAULT 'active'")
+}
+
+// ensureUserStatusPostgres adds the status column used to suspend accounts
+func ensureUserStatusPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	if _, err := pool.Exec(ctx, `ALTER TABLE users ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'active'`); err != nil {
+		return fmt.Errorf("add users.status: %w", err)
+	}
+	return nil
+}
+
+// ensureLegalHoldSQLite adds the legal hold flag and pending hard-delete confirmations
+func ensureLegalHoldSQLite(db *sql.DB) error {
+	if err := addColumnSQLite(db, "users", "legal_hold", "BOOLEAN NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS hard_delete_confirmations (
+		token_hash TEXT PRIMARY KEY,
+		admin_id TEXT NOT NULL,
+		user_id TEXT NOT NULL,
+		kind TEXT NOT NULL,
+		record_id TEXT NOT NULL,
+		reason TEXT NOT NULL,
+		expires_at INTEGER NOT NULL,
+		created_at INTEGER NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("create hard_delete_confirmations: %w", err)
+	}
+	return nil
+}
+
+// ensureLegalHoldPostgres adds the legal hold flag and pending hard-delete confirmations
+func ensureLegalHoldPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	if _, err := pool.Exec(ctx, `ALTER TABLE users ADD COLUMN IF NOT EXISTS legal_hold BOOLEAN NOT NULL DEFAULT FALSE`); err != nil {
+		return fmt.Errorf("add users.legal_hold: %w", err)
+	}
+	_, err := pool.Exec(ctx, `CREATE TABLE IF NOT EXISTS hard_delete_confirmations (
+		token_hash VARCHAR(64) PRIMARY KEY,
+		admin_id VARCHAR(36) NOT NULL,
+		user_id VARCHAR(36) NOT NULL,
+		kind VARCHAR(20) NOT NULL,
+		record_id VARCHAR(36) NOT NULL,
+		reason TEXT NOT NULL,
+		expires_at BIGINT NOT NULL,
+		created_at BIGINT NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("create hard_delete_confirmations: %w", err)
+	}
+	return nil
+}
+
+// ensureAnalyticsViewsPostgres creates the analytics materialized views and their
+// refresh log. SQLite has no materialized views and keeps querying the tables.
+func ensureAnalyticsViewsPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	for _, stmt := range []string{
+		`CREATE MATERIALIZED VIEW IF NOT EXISTS daily_exercise_volume AS
+		SELECT
+			ws.user_id,
+			e.name AS exercise_name,
+			DATE(es.created_at) AS day,
+			MAX(es.weight) AS max_weight,
+			SUM(es.weight * es.reps + es.segment_volume + es.side_volume) AS total_volume,
+			MAX(e.mode) AS mode,
+			COALESCE(SUM(es.duration_seconds), 0) AS total_duration,
+			COALESCE(MAX(es.duration_seconds), 0) AS max_duration,
+			COALESCE(SUM(es.distance_meters), 0) AS total_distance,
+			COALESCE(MAX(es.distance_meters), 0) AS max_distance
+		FROM exercise_sets es
+		JOIN session_exercises se ON es.session_exercise_id = se.id
+		JOIN workout_sessions ws ON se.session_id = ws.id
+		JOIN exercises e ON se.exercise_id = e.id
+		WHERE es.completed = true AND es.set_type <> 'warmup'
+		GROUP BY ws.user_id, e.name, DATE(es.created_at)`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_daily_exercise_volume_key ON daily_exercise_volume(user_id, exercise_name, day)`,
+		`CREATE MATERIALIZED VIEW IF NOT EXISTS weekly_training_summary AS
+		SELECT user_id, week_start, COUNT(*) AS sessions, SUM(sets) AS sets, SUM(tonnage) AS tonnage, SUM(minutes) AS minutes
+		FROM (
+			SELECT
+				ws.user_id,
+				DATE_TRUNC('week', ws.ended_at)::date AS week_start,
+				COUNT(es.id) AS sets,
+				COALESCE(SUM(es.weight * es.reps + es.segment_volume + es.side_volume), 0)::float8 AS tonnage,
+				EXTRACT(EPOCH FROM ws.ended_at - ws.started_at)::float8 / 60 AS minutes
+			FROM workout_sessions ws
+			LEFT JOIN session_exercises se ON se.session_id = ws.id
+			LEFT JOIN exercise_sets es ON es.session_exercise_id = se.id AND es.completed = true AND es.set_type <> 'warmup'
+			WHERE ws.ended_at IS NOT NULL
+			GROUP BY ws.id
+		) s
+		GROUP BY user_id, week_start`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_weekly_training_summary_key ON weekly_training_summary(user_id, week_start)`,
+		`CREATE TABLE IF NOT EXISTS analytics_view_refreshes (
+			view_name VARCHAR(64) PRIMARY KEY,
+			refreshed_at TIMESTAMP NOT NULL
+		)`,
+	} {
+		if _, err := pool.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("create analytics views: %w", err)
+		}
+	}
+	return nil
+}
+
+// ensureWorkoutTypeSQLite adds the workout category
+func ensureWorkoutTypeSQLite(db *sql.DB) error {
+	return addColumnSQLite(db, "workouts", "type", "TEXT NOT NULL DEFAULT ''")
+}
+
+// ensureWorkoutTypePostgres adds the workout category
+func ensureWorkoutTypePostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `ALTER TABLE workouts ADD COLUMN IF NOT EXISTS type VARCHAR(20) NOT NULL DEFAULT ''`)
+	if err != nil {
+		return fmt.Errorf("add workouts.type: %w", err)
+	}
+	return nil
+}
+
+// backfillExercisePosition numbers exercises that predate positions in the order
+// they were created. New exercises always start at 1, so this only touches old rows.
+const backfillExercisePosition = `UPDATE exercises SET position = ranked.rn
+	FROM (SELECT id, ROW_NUMBER() OVER (PARTITION BY workout_id ORDER BY created_at, id) AS rn FROM exercises WHERE position = 0) ranked
+	WHERE exercises.id = ranked.id`
+
+// ensureExercisePositionSQLite adds the explicit exercise order and backfills it
+func ensureExercisePositionSQLite(db *sql.DB) error {
+	if err := addColumnSQLite(db, "exercises", "position", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if _, err := db.Exec(backfillExercisePosition); err != nil {
+		return fmt.Errorf("backfill exercises.position: %w", err)
+	}
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_exercises_workout_position ON exercises(workout_id, position)`); err != nil {
+		return fmt.Errorf("create exercises position index: %w", err)
+	}
+	return nil
+}
+
+// ensureExercisePositionPostgres adds the explicit exercise order and backfills it
+func ensureExercisePositionPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	for _, stmt := range []string{
+		`ALTER TABLE exercises ADD COLUMN IF NOT EXISTS position INTEGER NOT NULL DEFAULT 0`,
+		backfillExercisePosition,
+		`CREATE INDEX IF NOT EXISTS idx_exercises_workout_position ON exercises(workout_id, position)`,
+	} {
+		if _, err := pool.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("add exercises.position: %w", err)
+		}
+	}
+	return nil
+}
+
+// ensureWorkoutArchiveSQLite adds the archive timestamp for hidden workouts
+func ensureWorkoutArchiveSQLite(db *sql.DB) error {
+	return addColumnSQLite(db, "workouts", "archived_at", "DATETIME")
+}
+
+// ensureWorkoutArchivePostgres adds the archive timestamp for hidden workouts
+func ensureWorkoutArchivePostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `ALTER TABLE workouts ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP`)
+	if err != nil {
+		return fmt.Errorf("add workouts.archived_at: %w", err)
+	}
+	return nil
+}
+
+// ensureWorkoutTagsSQLite creates the workout_tags join table
+func ensureWorkoutTagsSQLite(db *sql.DB) error {
+	for _, stmt := range []string{
+		`CREATE TABLE IF NOT EXISTS workout_tags (
+			workout_id TEXT NOT NULL REFERENCES workouts(id) ON DELETE CASCADE,
+			tag TEXT NOT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (workout_id, tag)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_workout_tags_tag ON workout_tags(tag)`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			return fmt.Errorf("create workout_tags: %w", err)
+		}
+	}
+	return nil
+}
+
+// ensureWorkoutTagsPostgres creates the workout_tags join table
+func ensureWorkoutTagsPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	for _, stmt := range []string{
+		`CREATE TABLE IF NOT EXISTS workout_tags (
+			workout_id VARCHAR(36) NOT NULL REFERENCES workouts(id) ON DELETE CASCADE,
+			tag VARCHAR(30) NOT NULL,
+			created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+			PRIMARY KEY (workout_id, tag)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_workout_tags_tag ON workout_tags(tag)`,
+	} {
+		if _, err := pool.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("create workout_tags: %w", err)
+		}
+	}
+	return nil
+}
+
+// ensureWorkoutNotesSQLite adds freeform notes to workouts
+func ensureWorkoutNotesSQLite(db *sql.DB) error {
+	return addColumnSQLite(db, "workouts", "notes", "TEXT NOT NULL DEFAULT ''")
+}
+
+// ensureWorkoutNotesPostgres adds freeform notes to workouts
+func ensureWorkoutNotesPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `ALTER TABLE workouts ADD COLUMN IF NOT EXISTS notes TEXT NOT NULL DEFAULT ''`)
+	if err != nil {
+		return fmt.Errorf("add workouts.notes: %w", err)
+	}
+	return nil
+}
+
+// ensureExerciseTemplatesSQLite creates the table of user-defined exercise templates
+func ensureExerciseTemplatesSQLite(db *sql.DB) error {
+	for _, stmt := range []string{
+		`CREATE TABLE IF NOT EXISTS exercise_templates (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			name TEXT NOT NULL,
+			default_sets INTEGER NOT NULL DEFAULT 3,
+			default_reps INTEGER NOT NULL DEFAULT 0,
+			default_weight REAL NOT NULL DEFAULT 0,
+			mode TEXT NOT NULL DEFAULT '',
+			default_duration_seconds INTEGER NOT NULL DEFAULT 0,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_exercise_templates_user_id ON exercise_templates(user_id)`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			return fmt.Errorf("create exercise_templates: %w", err)
+		}
+	}
+	return nil
+}
+
+// ensureExerciseTemplatesPostgres creates the table of user-defined exercise templates
+func ensureExerciseTemplatesPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	for _, stmt := range []string{
+		`CREATE TABLE IF NOT EXISTS exercise_templates (
+			id VARCHAR(36) PRIMARY KEY,
+			user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			name VARCHAR(255) NOT NULL,
+			default_sets INTEGER NOT NULL DEFAULT 3,
+			default_reps INTEGER NOT NULL DEFAULT 0,
+			default_weight DECIMAL(8,2) NOT NULL DEFAULT 0,
+			mode VARCHAR(20) NOT NULL DEFAULT '',
+			default_duration_seconds INTEGER NOT NULL DEFAULT 0,
+			created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+			updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_exercise_templates_user_id ON exercise_templates(user_id)`,
+	} {
+		if _, err := pool.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("create exercise_templates: %w", err)
+		}
+	}
+	return nil
+}
+
+// ensureWorkoutTemplatesSQLite creates the workout template catalog and seeds it
+// from models.DefaultWorkoutTemplates on first run
+func ensureWorkoutTemplatesSQLite(db *sql.DB) error {
+	for _, stmt := range []string{
+		`CREATE TABLE IF NOT EXISTS workout_templates (
+			id TEXT PRIMARY KEY,
+			name TEXT NOT NULL,
+			type TEXT NOT NULL DEFAULT '',
+			description TEXT NOT NULL DEFAULT '',
+			difficulty TEXT NOT NULL DEFAULT '',
+			duration_minutes INTEGER NOT NULL DEFAULT 0,
+			position INTEGER NOT NULL DEFAULT 0,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS workout_template_exercises (
+			template_id TEXT NOT NULL REFERENCES workout_templates(id) ON DELETE CASCADE,
+			position INTEGER NOT NULL,
+			name TEXT NOT NULL,
+			sets INTEGER NOT NULL,
+			reps INTEGER NOT NULL DEFAULT 0,
+			weight REAL NOT NULL DEFAULT 0,
+			mode TEXT NOT NULL DEFAULT '',
+			duration_seconds INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY (template_id, position)
+		)`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			return fmt.Errorf("create workout_templates: %w", err)
+		}
+	}
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM workout_templates`).Scan(&count); err != nil || count > 0 {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	now := time.Now()
+	for i, t := range models.DefaultWorkoutTemplates {
+		_, err := tx.Exec(`INSERT INTO workout_templates (id, name, type, description, difficulty, duration_minutes, position, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			t.ID, t.Name, t.Type, t.Description, t.Difficulty, t.Duration, i+1, now)
+		if err != nil {
+			return fmt.Errorf("seed workout_templates: %w", err)
+		}
+		for j, e := range t.Exercises {
+			_, err := tx.Exec(`INSERT INTO workout_template_exercises (template_id, position, name, sets, reps, weight, mode, duration_seconds)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+				t.ID, j+1, e.Name, e.Sets, e.Reps, e.Weight, e.Mode, e.DurationSeconds)
+			if err != nil {
+				return fmt.Errorf("seed workout_template_exercises: %w", err)
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// ensureWorkoutTemplatesPostgres creates the workout template catalog and seeds it
+// from models.DefaultWorkoutTemplates on first run
+func ensureWorkoutTemplatesPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	for _, stmt := range []string{
+		`CREATE TABLE IF NOT EXISTS workout_templates (
+			id VARCHAR(100) PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			type VARCHAR(20) NOT NULL DEFAULT '',
+			description TEXT NOT NULL DEFAULT '',
+			difficulty VARCHAR(20) NOT NULL DEFAULT '',
+			duration_minutes INTEGER NOT NULL DEFAULT 0,
+			position INTEGER NOT NULL DEFAULT 0,
+			created_at TIMESTAMP NOT NULL DEFAULT NOW()
+		)`,
+		`CREATE TABLE IF NOT EXISTS workout_template_exercises (
+			template_id VARCHAR(100) NOT NULL REFERENCES workout_templates(id) ON DELETE CASCADE,
+			position INTEGER NOT NULL,
+			name VARCHAR(255) NOT NULL,
+			sets INTEGER NOT NULL,
+			reps INTEGER NOT NULL DEFAULT 0,
+			weight DECIMAL(8,2) NOT NULL DEFAULT 0,
+			mode VARCHAR(20) NOT NULL DEFAULT '',
+			duration_seconds INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY (template_id, position)
+		)`,
+	} {
+		if _, err := pool.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("create workout_templates: %w", err)
+		}
+	}
+	var count int
+	if err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM workout_templates`).Scan(&count); err != nil || count > 0 {
+		return err
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	now := time.Now()
+	for i, t := range models.DefaultWorkoutTemplates {
+		_, err := tx.Exec(ctx, `INSERT INTO workout_templates (id, name, type, description, difficulty, duration_minutes, position, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8) ON CONFLICT (id) DO NOTHING`,
+			t.ID, t.Name, t.Type, t.Description, t.Difficulty, t.Duration, i+1, now)
+		if err != nil {
+			return fmt.Errorf("seed workout_templates: %w", err)
+		}
+		for j, e := range t.Exercises {
+			_, err := tx.Exec(ctx, `INSERT INTO workout_template_exercises (template_id, position, name, sets, reps, weight, mode, duration_seconds)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8) ON CONFLICT DO NOTHING`,
+				t.ID, j+1, e.Name, e.Sets, e.Reps, e.Weight, e.Mode, e.DurationSeconds)
+			if err != nil {
+				return fmt.Errorf("seed workout_template_exercises: %w", err)
+			}
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// ensureCustomWorkoutTemplatesSQLite gives workout templates an optional owner
+func ensureCustomWorkoutTemplatesSQLite(db *sql.DB) error {
+	if err := addColumnSQLite(db, "workout_templates", "user_id", "TEXT REFERENCES users(id) ON DELETE CASCADE"); err != nil {
+		return err
+	}
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_workout_templates_user_id ON workout_templates(user_id)`); err != nil {
+		return fmt.Errorf("index workout_templates.user_id: %w", err)
+	}
+	return nil
+}
+
+// ensureCustomWorkoutTemplatesPostgres gives workout templates an optional owner
+func ensureCustomWorkoutTemplatesPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	for _, stmt := range []string{
+		`ALTER TABLE workout_templates ADD COLUMN IF NOT EXISTS user_id VARCHAR(36) REFERENCES users(id) ON DELETE CASCADE`,
+		`CREATE INDEX IF NOT EXISTS idx_workout_templates_user_id ON workout_templates(user_id)`,
+	} {
+		if _, err := pool.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("add workout_templates.user_id: %w", err)
+		}
+	}
+	return nil
+}
+
+// ensureWorkoutTemplateSharingSQLite adds the hashed share link token to workout templates
+func ensureWorkoutTemplateSharingSQLite(db *sql.DB) error {
+	if err := addColumnSQLite(db, "workout_templates", "share_token_hash", "TEXT"); err != nil {
+		return err
+	}
+	if _, err := db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_workout_templates_share_token_hash ON workout_templates(share_token_hash)`); err != nil {
+		return fmt.Errorf("index workout_templates.share_token_hash: %w", err)
+	}
+	return nil
+}
+
+// ensureWorkoutTemplateSharingPostgres adds the hashed share link token to workout templates
+func ensureWorkoutTemplateSharingPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	for _, stmt := range []string{
+		`ALTER TABLE workout_templates ADD COLUMN IF NOT EXISTS share_token_hash VARCHAR(64)`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_workout_templates_share_token_hash ON workout_templates(share_token_hash)`,
+	} {
+		if _, err := pool.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("add workout_templates.share_token_hash: %w", err)
+		}
+	}
+	return nil
+}
+
+// ensureExerciseGroupsSQLite adds superset/circuit grouping to exercises
+func ensureExerciseGroupsSQLite(db *sql.DB) error {
+	if err := addColumnSQLite(db, "exercises", "group_id", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	return addColumnSQLite(db, "exercises", "group_type", "TEXT NOT NULL DEFAULT ''")
+}
+
+// ensureExerciseGroupsPostgres adds superset/circuit grouping to exercises
+func ensureExerciseGroupsPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	for _, stmt := range []string{
+		`ALTER TABLE exercises ADD COLUMN IF NOT EXISTS group_id VARCHAR(36) NOT NULL DEFAULT ''`,
+		`ALTER TABLE exercises ADD COLUMN IF NOT EXISTS group_type VARCHAR(20) NOT NULL DEFAULT ''`,
+	} {
+		if _, err := pool.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("add exercises grouping: %w", err)
+		}
+	}
+	return nil
+}
+
+// ensureExerciseRestSQLite adds planned rest times to exercises and templates
+func ensureExerciseRestSQLite(db *sql.DB) error {
+	if err := addColumnSQLite(db, "exercises", "rest_seconds", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if err := addColumnSQLite(db, "exercise_templates", "default_rest_seconds", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	return addColumnSQLite(db, "workout_template_exercises", "rest_seconds", "INTEGER NOT NULL DEFAULT 0")
+}
+
+// ensureExerciseRestPostgres adds planned rest times to exercises and templates
+func ensureExerciseRestPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	for _, stmt := range []string{
+		`ALTER TABLE exercises ADD COLUMN IF NOT EXISTS rest_seconds INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE exercise_templates ADD COLUMN IF NOT EXISTS default_rest_seconds INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE workout_template_exercises ADD COLUMN IF NOT EXISTS rest_seconds INTEGER NOT NULL DEFAULT 0`,
+	} {
+		if _, err := pool.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("add rest_seconds: %w", err)
+		}
+	}
+	return nil
+}
+
+// ensureIntensitySQLite adds target RPE and tempo to exercises and actual RPE to sets
+func ensureIntensitySQLite(db *sql.DB) error {
+	if err := addColumnSQLite(db, "exercises", "target_rpe", "REAL"); err != nil {
+		return err
+	}
+	if err := addColumnSQLite(db, "exercises", "tempo", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	return addColumnSQLite(db, "exercise_sets", "actual_rpe", "REAL")
+}
+
+// ensureIntensityPostgres adds target RPE and tempo to exercises and actual RPE to sets
+func ensureIntensityPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	for _, stmt := range []string{
+		`ALTER TABLE exercises ADD COLUMN IF NOT EXISTS target_rpe DECIMAL(3,1)`,
+		`ALTER TABLE exercises ADD COLUMN IF NOT EXISTS tempo VARCHAR(10) NOT NULL DEFAULT ''`,
+		`ALTER TABLE exercise_sets ADD COLUMN IF NOT EXISTS actual_rpe DECIMAL(3,1)`,
+	} {
+		if _, err := pool.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("add rpe and tempo: %w", err)
+		}
+	}
+	return nil
+}
+
+// ensureRepRangesSQLite adds rep ranges to exercises and templates
+func ensureRepRangesSQLite(db *sql.DB) error {
+	for _, col := range []struct{ table, column string }{
+		{"exercises", "reps_min"},
+		{"exercises", "reps_max"},
+		{"exercise_templates", "default_reps_min"},
+		{"exercise_templates", "default_reps_max"},
+		{"workout_template_exercises", "reps_min"},
+		{"workout_template_exercises", "reps_max"},
+	} {
+		if err := addColumnSQLite(db, col.table, col.column, "INTEGER NOT NULL DEFAULT 0"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureRepRangesPostgres adds rep ranges to exercises and templates
+func ensureRepRangesPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	for _, stmt := range []string{
+		`ALTER TABLE exercises ADD COLUMN IF NOT EXISTS reps_min INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE exercises ADD COLUMN IF NOT EXISTS reps_max INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE exercise_templates ADD COLUMN IF NOT EXISTS default_reps_min INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE exercise_templates ADD COLUMN IF NOT EXISTS default_reps_max INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE workout_template_exercises ADD COLUMN IF NOT EXISTS reps_min INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE workout_template_exercises ADD COLUMN IF NOT EXISTS reps_max INTEGER NOT NULL DEFAULT 0`,
+	} {
+		if _, err := pool.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("add rep ranges: %w", err)
+		}
+	}
+	return nil
+}
+
+// ensureWarmupSetsSQLite marks generated warm-up sets
+func ensureWarmupSetsSQLite(db *sql.DB) error {
+	return addColumnSQLite(db, "exercise_sets", "warmup", "BOOLEAN NOT NULL DEFAULT 0")
+}
+
+// ensureWarmupSetsPostgres marks generated warm-up sets
+func ensureWarmupSetsPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	if _, err := pool.Exec(ctx, `ALTER TABLE exercise_sets ADD COLUMN IF NOT EXISTS warmup BOOLEAN NOT NULL DEFAULT false`); err != nil {
+		return fmt.Errorf("add exercise_sets.warmup: %w", err)
+	}
+	return nil
+}
+
+// ensureSetTypesSQLite adds the set type, carrying over generated warm-ups and
+// drop sets logged before it existed
+func ensureSetTypesSQLite(db *sql.DB) error {
+	if err := addColumnSQLite(db, "exercise_sets", "set_type", "TEXT NOT NULL DEFAULT 'normal'"); err != nil {
+		return err
+	}
+	for _, stmt := range []string{
+		`UPDATE exercise_sets SET set_type = 'warmup', warmup = 0 WHERE warmup = 1`,
+		`UPDATE exercise_sets SET set_type = 'drop' WHERE technique = 'drop_set' AND set_type = 'normal'`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			return fmt.Errorf("backfill exercise_sets.set_type: %w", err)
+		}
+	}
+	return nil
+}
+
+// ensureSetTypesPostgres adds the set type, carrying over generated warm-ups and
+// drop sets logged before it existed. Analytics views created before warm-ups
+// were left out of volume are dropped, for ensureAnalyticsViewsPostgres to
+// recreate.
+func ensureSetTypesPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	for _, stmt := range []string{
+		`ALTER TABLE exercise_sets ADD COLUMN IF NOT EXISTS set_type VARCHAR(20) NOT NULL DEFAULT 'normal'`,
+		`UPDATE exercise_sets SET set_type = 'warmup', warmup = false WHERE warmup`,
+		`UPDATE exercise_sets SET set_type = 'drop' WHERE technique = 'drop_set' AND set_type = 'normal'`,
+	} {
+		if _, err := pool.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("add exercise_sets.set_type: %w", err)
+		}
+	}
+	var stale int
+	err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM pg_matviews
+		WHERE matviewname IN ('daily_exercise_volume', 'weekly_training_summary') AND definition NOT LIKE '%set_type%'`).Scan(&stale)
+	if err != nil {
+		return fmt.Errorf("inspect analytics views: %w", err)
+	}
+	if stale > 0 {
+		if _, err := pool.Exec(ctx, `DROP MATERIALIZED VIEW IF EXISTS daily_exercise_volume, weekly_training_summary`); err != nil {
+			return fmt.Errorf("drop analytics views: %w", err)
+		}
+	}
+	return nil
+}
+
+// ensureExerciseMetadataSQLite adds muscles, equipment and instructions to exercise templates
+func ensureExerciseMetadataSQLite(db *sql.DB) error {
+	for _, column := range []string{"primary_muscles", "secondary_muscles", "equipment", "instructions"} {
+		if err := addColumnSQLite(db, "exercise_templates", column, "TEXT"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureExerciseMetadataPostgres adds muscles, equipment and instructions to exercise templates
+func ensureExerciseMetadataPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	for _, column := range []string{"primary_muscles", "secondary_muscles", "equipment", "instructions"} {
+		if _, err := pool.Exec(ctx, `ALTER TABLE exercise_templates ADD COLUMN IF NOT EXISTS `+column+` TEXT`); err != nil {
+			return fmt.Errorf("add exercise_templates.%s: %w", column, err)
+		}
+	}
+	return nil
+}
+
+// ensureExerciseMediaSQLite adds demonstration video and image links to exercises and templates
+func ensureExerciseMediaSQLite(db *sql.DB) error {
+	for _, table := range []string{"exercises", "exercise_templates"} {
+		for _, column := range []string{"video_url", "image_url"} {
+			if err := addColumnSQLite(db, table, column, "TEXT NOT NULL DEFAULT ''"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ensureExerciseMediaPostgres adds demonstration video and image links to exercises and templates
+func ensureExerciseMediaPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	for _, table := range []string{"exercises", "exercise_templates"} {
+		for _, column := range []string{"video_url", "image_url"} {
+			if _, err := pool.Exec(ctx, `ALTER TABLE `+table+` ADD COLUMN IF NOT EXISTS `+column+` TEXT NOT NULL DEFAULT ''`); err != nil {
+				return fmt.Errorf("add %s.%s: %w", table, column, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ensureUserPreferencesSQLite creates the user_preferences table (display units)
+func ensureUserPreferencesSQLite(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS user_preferences (
+		user_id TEXT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+		units TEXT NOT NULL DEFAULT 'imperial',
+		updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	if err != nil {
+		return fmt.Errorf("create user_preferences: %w", err)
+	}
+	return nil
+}
+
+// ensureUserPreferencesPostgres creates the user_preferences table (display units)
+func ensureUserPreferencesPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `CREATE TABLE IF NOT EXISTS user_preferences (
+		user_id VARCHAR(36) PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+		units VARCHAR(10) NOT NULL DEFAULT 'imperial',
+		updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+	)`)
+	if err != nil {
+		return fmt.Errorf("create user_preferences: %w", err)
+	}
+	return nil
+}
+
+// migrateEnduranceRun turns the built-in endurance run's 1 x 1 rep placeholders into
+// cardio targets. Templates seeded since then already have them.
+var migrateEnduranceRun = []string{
+	`UPDATE workout_template_exercises SET mode = 'cardio', reps = 0, duration_seconds = 1800
+		WHERE template_id = 'endurance-run' AND name = 'Running' AND mode = ''`,
+	`UPDATE workout_template_exercises SET mode = 'cardio', reps = 0, duration_seconds = 900
+		WHERE template_id = 'endurance-run' AND name = 'Walking' AND mode = ''`,
+}
+
+// ensureCardioSQLite adds target and logged distances for cardio exercises
+func ensureCardioSQLite(db *sql.DB) error {
+	for _, c := range []struct{ table, column, def string }{
+		{"exercises", "distance_meters", "INTEGER NOT NULL DEFAULT 0"},
+		{"exercise_sets", "distance_meters", "INTEGER"},
+		{"exercise_templates", "default_distance_meters", "INTEGER NOT NULL DEFAULT 0"},
+		{"workout_template_exercises", "distance_meters", "INTEGER NOT NULL DEFAULT 0"},
+	} {
+		if err := addColumnSQLite(db, c.table, c.column, c.def); err != nil {
+			return err
+		}
+	}
+	for _, stmt := range migrateEnduranceRun {
+		if _, err := db.Exec(stmt); err != nil {
+			return fmt.Errorf("migrate endurance run template: %w", err)
+		}
+	}
+	return nil
+}
+
+// ensureCardioPostgres adds target and logged distances for cardio exercises, and
+// drops a daily_exercise_volume view from before distances so it is recreated
+func ensureCardioPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	for _, stmt := range append([]string{
+		`ALTER TABLE exercises ADD COLUMN IF NOT EXISTS distance_meters INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE exercise_sets ADD COLUMN IF NOT EXISTS distance_meters INTEGER`,
+		`ALTER TABLE exercise_templates ADD COLUMN IF NOT EXISTS default_distance_meters INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE workout_template_exercises ADD COLUMN IF NOT EXISTS distance_meters INTEGER NOT NULL DEFAULT 0`,
+	}, migrateEnduranceRun...) {
+		if _, err := pool.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("add cardio columns: %w", err)
+		}
+	}
+	var stale int
+	err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM pg_matviews
+		WHERE matviewname = 'daily_exercise_volume' AND definition NOT LIKE '%distance_meters%'`).Scan(&stale)
+	if err != nil {
+		return fmt.Errorf("inspect analytics views: %w", err)
+	}
+	if stale > 0 {
+		if _, err := pool.Exec(ctx, `DROP MATERIALIZED VIEW IF EXISTS daily_exercise_volume`); err != nil {
+			return fmt.Errorf("drop analytics views: %w", err)
+		}
+	}
+	return nil
+}
+
+// ensureScheduledWorkoutsSQLite creates the training calendar
+func ensureScheduledWorkoutsSQLite(db *sql.DB) error {
+	for _, stmt := range []string{
+		`CREATE TABLE IF NOT EXISTS scheduled_workouts (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			workout_id TEXT NOT NULL REFERENCES workouts(id) ON DELETE CASCADE,
+			date TEXT NOT NULL,
+			time TEXT,
+			status TEXT NOT NULL DEFAULT 'planned',
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_scheduled_workouts_user_date ON scheduled_workouts(user_id, date)`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			return fmt.Errorf("create scheduled_workouts: %w", err)
+		}
+	}
+	return nil
+}
+
+// ensureScheduledWorkoutsPostgres creates the training calendar
+func ensureScheduledWorkoutsPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	for _, stmt := range []string{
+		`CREATE TABLE IF NOT EXISTS scheduled_workouts (
+			id VARCHAR(36) PRIMARY KEY,
+			user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			workout_id VARCHAR(36) NOT NULL REFERENCES workouts(id) ON DELETE CASCADE,
+			date VARCHAR(10) NOT NULL,
+			time VARCHAR(5),
+			status VARCHAR(20) NOT NULL DEFAULT 'planned',
+			created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+			updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_scheduled_workouts_user_date ON scheduled_workouts(user_id, date)`,
+	} {
+		if _, err := pool.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("create scheduled_workouts: %w", err)
+		}
+	}
+	return nil
+}
+
+// ensureProgramsSQLite creates multi-week training programs and enrollments
+func ensureProgramsSQLite(db *sql.DB) error {
+	for _, stmt := range []string{
+		`CREATE TABLE IF NOT EXISTS programs (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			name TEXT NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_programs_user ON programs(user_id)`,
+		`CREATE TABLE IF NOT EXISTS program_workouts (
+			program_id TEXT NOT NULL REFERENCES programs(id) ON DELETE CASCADE,
+			day INTEGER NOT NULL,
+			workout_id TEXT NOT NULL REFERENCES workouts(id) ON DELETE CASCADE,
+			PRIMARY KEY (program_id, day)
+		)`,
+		`CREATE TABLE IF NOT EXISTS program_weeks (
+			program_id TEXT NOT NULL REFERENCES programs(id) ON DELETE CASCADE,
+			week INTEGER NOT NULL,
+			weight_percent REAL NOT NULL DEFAULT 100,
+			sets_delta INTEGER NOT NULL DEFAULT 0,
+			reps_delta INTEGER NOT NULL DEFAULT 0,
+			notes TEXT NOT NULL DEFAULT '',
+			PRIMARY KEY (program_id, week)
+		)`,
+		`CREATE TABLE IF NOT EXISTS program_enrollments (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			program_id TEXT NOT NULL REFERENCES programs(id) ON DELETE CASCADE,
+			week INTEGER NOT NULL DEFAULT 1,
+			days_done INTEGER NOT NULL DEFAULT 0,
+			status TEXT NOT NULL DEFAULT 'active',
+			started_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			completed_at DATETIME,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_program_enrollments_user ON program_enrollments(user_id, status)`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			return fmt.Errorf("create programs: %w", err)
+		}
+	}
+	return nil
+}
+
+// ensureProgramsPostgres creates multi-week training programs and enrollments
+func ensureProgramsPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	for _, stmt := range []string{
+		`CREATE TABLE IF NOT EXISTS programs (
+			id VARCHAR(36) PRIMARY KEY,
+			user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			name VARCHAR(255) NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+			updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_programs_user ON programs(user_id)`,
+		`CREATE TABLE IF NOT EXISTS program_workouts (
+			program_id VARCHAR(36) NOT NULL REFERENCES programs(id) ON DELETE CASCADE,
+			day INTEGER NOT NULL,
+			workout_id VARCHAR(36) NOT NULL REFERENCES workouts(id) ON DELETE CASCADE,
+			PRIMARY KEY (program_id, day)
+		)`,
+		`CREATE TABLE IF NOT EXISTS program_weeks (
+			program_id VARCHAR(36) NOT NULL REFERENCES programs(id) ON DELETE CASCADE,
+			week INTEGER NOT NULL,
+			weight_percent DOUBLE PRECISION NOT NULL DEFAULT 100,
+			sets_delta INTEGER NOT NULL DEFAULT 0,
+			reps_delta INTEGER NOT NULL DEFAULT 0,
+			notes TEXT NOT NULL DEFAULT '',
+			PRIMARY KEY (program_id, week)
+		)`,
+		`CREATE TABLE IF NOT EXISTS program_enrollments (
+			id VARCHAR(36) PRIMARY KEY,
+			user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			program_id VARCHAR(36) NOT NULL REFERENCES programs(id) ON DELETE CASCADE,
+			week INTEGER NOT NULL DEFAULT 1,
+			days_done INTEGER NOT NULL DEFAULT 0,
+			status VARCHAR(20) NOT NULL DEFAULT 'active',
+			started_at TIMESTAMP NOT NULL DEFAULT NOW(),
+			completed_at TIMESTAMP,
+			updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_program_enrollments_user ON program_enrollments(user_id, status)`,
+	} {
+		if _, err := pool.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("create programs: %w", err)
+		}
+	}
+	return nil
+}
+
+// ensureProgressionRulesSQLite creates per-exercise progression rules
+func ensureProgressionRulesSQLite(db *sql.DB) error {
+	for _, stmt := range []string{
+		`CREATE TABLE IF NOT EXISTS progression_rules (
+			exercise_id TEXT PRIMARY KEY REFERENCES exercises(id) ON DELETE CASCADE,
+			user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			weight_increment REAL NOT NULL DEFAULT 5,
+			failure_limit INTEGER NOT NULL DEFAULT 2,
+			deload_percent REAL NOT NULL DEFAULT 10,
+			failures INTEGER NOT NULL DEFAULT 0,
+			last_session_id TEXT,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_progression_rules_user ON progression_rules(user_id)`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			return fmt.Errorf("create progression_rules: %w", err)
+		}
+	}
+	return nil
+}
+
+// ensureProgressionRulesPostgres creates per-exercise progression rules
+func ensureProgressionRulesPostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	for _, stmt := range []string{
+		`CREATE TABLE IF NOT EXISTS progression_rules (
+			exercise_id VARCHAR(36) PRIMARY KEY REFERENCES exercises(id) ON DELETE CASCADE,
+			user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			weight_increment DOUBLE PRECISION NOT NULL DEFAULT 5,
+			failure_limit INTEGER NOT NULL DEFAULT 2,
+			deload_percent DOUBLE PRECISION NOT NULL DEFAULT 10,
+			failures INTEGER NOT NULL DEFAULT 0,
+			last_session_id VARCHAR(36),
+			created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+			updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_progression_rules_user ON progression_rules(user_id)`,
+	} {
+		if _, err := pool.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("create progression_rules: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/models"
+)
+
+// Demo account seeded by NewMockDatabase
+const (
+	DemoUserID       = "00000000-0000-0000-0000-000000000002"
+	DemoUserEmail    = "demo@liftoff.local"
+	DemoUserPassword = "Demo123!"
+)
+
+type mockExercise struct {
+	name   string
+	sets   int
+	reps   int
+	weight float64
+	// seconds marks a duration-mode exercise held for that long per set
+	seconds int
+}
+
+type mockWorkout struct {
+	name      string
+	exercises []mockExercise
+}
+
+// mockWorkouts is the demo user's Push/Pull/Legs split
+var mockWorkouts = []mockWorkout{
+	{"Push Day", []mockExercise{
+		{"Barbell Bench Press", 4, 8, 135, 0},
+		{"Overhead Press", 3, 8, 75, 0},
+		{"Lateral Raises", 3, 15, 15, 0},
+		{"Tricep Pushdowns", 3, 12, 40, 0},
+	}},
+	{"Pull Day", []mockExercise{
+		{"Deadlifts", 3, 5, 225, 0},
+		{"Pull-ups", 4, 8, 0, 0},
+		{"Barbell Rows", 4, 10, 95, 0},
+		{"Bicep Curls", 3, 12, 25, 0},
+	}},
+	{"Leg Day", []mockExercise{
+		{"Barbell Squats", 4, 8, 185, 0},
+		{"Leg Press", 3, 10, 270, 0},
+		{"Lunges", 3, 12, 0, 0},
+		{"Plank", 3, 0, 0, 45},
+	}},
+}
+
+// mockSessionCount is the number of completed sessions seeded (four weeks, three a week)
+const mockSessionCount = 12
+
+/**
+ * NewMockDatabase creates an in-memory SQLite database for --mock mode
+ *
+ * Runs the normal SQLite schema and migrations, then seeds a demo account
+ * with workouts, a routine and a month of completed sessions. IDs and
+ * contents are fixed so every run looks the same; session dates are
+ * relative to today so recent-history views have data. Nothing is persisted.
+ *
+ * Returns:
+ * - *Database: Database instance with SQLite connection
+ * - error: Schema or seed error
+ */
+func NewMockDatabase() (*Database, error) {
+	db, err := sql.Open("sqlite3", "file:liftoff-mock?mode=memory&cache=shared")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open in-memory database: %w", err)
+	}
+	// The shared in-memory database is dropped when its last connection closes
+	db.SetMaxIdleConns(4)
+	db.SetConnMaxLifetime(0)
+
+	if err := createSQLiteTables(db); err != nil {
+		return nil, fmt.Errorf("failed to create SQLite tables: %w", err)
+	}
+	if err := MigrateSQLite(db); err != nil {
+		return nil, fmt.Errorf("failed to run migrations: %w", err)
+	}
+	if err := seedMockData(db); err != nil {
+		return nil, fmt.Errorf("failed to seed demo data: %w", err)
+	}
+
+	log.Printf("Database connected successfully (in-memory mock, sign in as %s / %s)", DemoUserEmail, DemoUserPassword)
+
+	return &Database{sqlite: db, useSQLite: true}, nil
+}
+
+// mockID returns a stable UUID-shaped ID so seeded rows keep the same IDs across runs
+func mockID(kind, n int) string {
+	return fmt.Sprintf("00000000-0000-4000-8%03d-%012d", kind, n)
+}
+
+// ID namespaces for mockID
+const (
+	mockKindWorkout = iota + 1
+	mockKindExercise
+	mockKindSession
+	mockKindSessionExercise
+	mockKindSet
+	mockKindRoutine
+)
+
+func seedMockData(db *sql.DB) error {
+	hash, err := auth.HashPassword(DemoUserPassword)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	created := today.AddDate(0, 0, -mockSessionCount*7/3-1)
+
+	if _, err := tx.Exec(`INSERT INTO users (id, email, password_hash, created_at) VALUES (?, ?, ?, ?)`,
+		DemoUserID, DemoUserEmail, hash, created); err != nil {
+		return fmt.Errorf("insert demo user: %w", err)
+	}
+
+	exerciseIDs := make([][]string, len(mockWorkouts))
+	for w, workout := range mockWorkouts {
+		workoutID := mockID(mockKindWorkout, w+1)
+		if _, err := tx.Exec(`INSERT INTO workouts (id, user_id, name, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`,
+			workoutID, DemoUserID, workout.name, created, created); err != nil {
+			return fmt.Errorf("insert workout: %w", err)
+		}
+		for e, ex := range workout.exercises {
+			id := mockID(mockKindExercise, (w+1)*100+e+1)
+			exerciseIDs[w] = append(exerciseIDs[w], id)
+			mode := models.ExerciseModeReps
+			if ex.seconds > 0 {
+				mode = models.ExerciseModeDuration
+			}
+			if _, err := tx.Exec(`INSERT INTO exercises (id, name, sets, reps, weight, mode, duration_seconds, workout_id, position, created_at, updated_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				id, ex.name, ex.sets, ex.reps, ex.weight, mode, ex.seconds, workoutID, e+1, created, created); err != nil {
+				return fmt.Errorf("insert exercise: %w", err)
+			}
+		}
+	}
+
+	routineID := mockID(mockKindRoutine, 1)
+	if _, err := tx.Exec(`INSERT INTO routines (id, user_id, name, description, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		routineID, DemoUserID, "Push Pull Legs", "Three-day split, repeat weekly", created, created); err != nil {
+		return fmt.Errorf("insert routine: %w", err)
+	}
+	for w := range mockWorkouts {
+		if _, err := tx.Exec(`INSERT INTO routine_workouts (id, routine_id, workout_id, slot_order, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?)`,
+			mockID(mockKindRoutine, 100+w+1), routineID, mockID(mockKindWorkout, w+1), w+1, created, created); err != nil {
+			return fmt.Errorf("insert routine workout: %w", err)
+		}
+	}
+
+	// Sessions rotate through the split every other day, adding 5 lb per cycle on loaded lifts
+	setN := 0
+	for s := 0; s < mockSessionCount; s++ {
+		w := s % len(mockWorkouts)
+		cycle := float64(s / len(mockWorkouts))
+		startedAt := today.AddDate(0, 0, -(mockSessionCount-s)*2).Add(18 * time.Hour)
+		endedAt := startedAt.Add(time.Duration(50+s%3*5) * time.Minute)
+		metadata := models.SessionMetadata{Gym: "Downtown Gym", Crowd: 1 + (s*3)%5}
+		if s%4 == 0 {
+			metadata.Partners = []string{"Sam"}
+		}
+
+		sessionID := mockID(mockKindSession, s+1)
+		if _, err := tx.Exec(`INSERT INTO workout_sessions (id, user_id, workout_id, started_at, ended_at, is_active, created_at, updated_at, metadata)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			sessionID, DemoUserID, mockID(mockKindWorkout, w+1), startedAt, endedAt, false, startedAt, endedAt, metadata); err != nil {
+			return fmt.Errorf("insert session: %w", err)
+		}
+
+		for e, ex := range mockWorkouts[w].exercises {
+			sessionExerciseID := mockID(mockKindSessionExercise, (s+1)*100+e+1)
+			if _, err := tx.Exec(`INSERT INTO session_exercises (id, session_id, exercise_id, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`,
+				sessionExerciseID, sessionID, exerciseIDs[w][e], startedAt, startedAt); err != nil {
+				return fmt.Errorf("insert session exercise: %w", err)
+			}
+			weight := ex.weight
+			if weight > 0 {
+				weight += 5 * cycle
+			}
+			var held *int
+			if ex.seconds > 0 {
+				held = &ex.seconds
+			}
+			for i := 0; i < ex.sets; i++ {
+				setN++
+				loggedAt := startedAt.Add(time.Duration(e*10+i*2) * time.Minute)
+				if _, err := tx.Exec(`INSERT INTO exercise_sets (id, session_exercise_id, reps, weight, duration_seconds, completed, notes, created_at, updated_at)
+					VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+					mockID(mockKindSet, setN), sessionExerciseID, ex.reps, weight, held, true, "", loggedAt, loggedAt); err != nil {
+					return fmt.Errorf("insert set: %w", err)
+				}
+			}
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,115 @@
+package deadline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"liftoff/backend/requestid"
+
+	"github.com/gin-gonic/gin"
+)
+
+/**
+ * Deadline Package
+ *
+ * Bounds how long a request may run. The middleware gives each request's
+ * context a deadline; repositories pass that context to every query, so a
+ * slow database call is cancelled instead of holding the handler. A
+ * request that runs out of time is answered 504 Gateway Timeout with its
+ * request ID in place of whatever error the handler produced.
+ */
+
+// DefaultTimeout applies when REQUEST_TIMEOUT is unset
+const DefaultTimeout = 30 * time.Second
+
+// FromEnv reads REQUEST_TIMEOUT (a duration; 0 disables deadlines)
+func FromEnv() time.Duration {
+	raw := os.Getenv("REQUEST_TIMEOUT")
+	if raw == "" {
+		return DefaultTimeout
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d < 0 {
+		log.Printf("Invalid REQUEST_TIMEOUT=%q, using %v", raw, DefaultTimeout)
+		return DefaultTimeout
+	}
+	return d
+}
+
+// Middleware applies timeout to each request's context, except for paths under
+// the exempt prefixes (long downloads). A timeout of 0 installs nothing.
+func Middleware(timeout time.Duration, exempt ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+		for _, prefix := range exempt {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				c.Next()
+				return
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		w := &timeoutWriter{ResponseWriter: c.Writer, ctx: ctx, requestID: requestid.Get(c)}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+
+		if !w.Written() && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			w.WriteHeader(http.StatusGatewayTimeout)
+			w.Write(nil)
+		}
+		if w.timedOut {
+			log.Printf("%s %s exceeded the %v request deadline (request %s)", c.Request.Method, c.Request.URL.Path, timeout, w.requestID)
+		}
+	}
+}
+
+// timeoutWriter replaces server errors written after the deadline with a 504,
+// since they are almost always the cancelled query surfacing as a 500
+type timeoutWriter struct {
+	gin.ResponseWriter
+	ctx       context.Context
+	requestID string
+	timedOut  bool
+	replaced  bool
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	if code >= http.StatusInternalServerError && errors.Is(w.ctx.Err(), context.DeadlineExceeded) {
+		w.timedOut = true
+		code = http.StatusGatewayTimeout
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *timeoutWriter) Write(b []byte) (int, error) {
+	if !w.timedOut {
+		return w.ResponseWriter.Write(b)
+	}
+	// The handler's body is dropped in favour of the timeout error
+	if !w.replaced {
+		w.replaced = true
+		body, _ := json.Marshal(gin.H{"error": "Request timed out", "requestId": w.requestID})
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Header().Del("Content-Length")
+		if _, err := w.ResponseWriter.Write(body); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
@@ -0,0 +1,70 @@
+package deadline
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"liftoff/backend/requestid"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(requestid.Middleware(), Middleware(20*time.Millisecond, "/export"))
+	// slow stands in for a handler whose query is cancelled by the deadline
+	slow := func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch workouts"})
+		case <-time.After(time.Second):
+			c.JSON(http.StatusOK, gin.H{"ok": true})
+		}
+	}
+	r.GET("/slow", slow)
+	r.GET("/export", slow)
+	r.GET("/silent", func(c *gin.Context) { <-c.Request.Context().Done() })
+	r.GET("/late-client-error", func(c *gin.Context) {
+		<-c.Request.Context().Done()
+		c.JSON(http.StatusNotFound, gin.H{"error": "Workout not found"})
+	})
+	r.GET("/fast", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"ok": true}) })
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set(requestid.Header, "trace-1234")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, path := range []string{"/slow", "/silent"} {
+		w := get(path)
+		var body struct{ Error, RequestID string }
+		json.Unmarshal(w.Body.Bytes(), &body)
+		if w.Code != http.StatusGatewayTimeout || body.Error != "Request timed out" || body.RequestID != "trace-1234" {
+			t.Errorf("%s: got %d %s", path, w.Code, w.Body.String())
+		}
+	}
+	if w := get("/late-client-error"); w.Code != http.StatusNotFound {
+		t.Errorf("client errors pass through: got %d", w.Code)
+	}
+	if w := get("/fast"); w.Code != http.StatusOK || w.Header().Get(requestid.Header) != "trace-1234" {
+		t.Errorf("fast: got %d %v", w.Code, w.Header())
+	}
+	if w := get("/export"); w.Code != http.StatusOK {
+		t.Errorf("exempt path: got %d", w.Code)
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	for raw, want := range map[string]time.Duration{"": DefaultTimeout, "5s": 5 * time.Second, "0": 0, "soon": DefaultTimeout} {
+		t.Setenv("REQUEST_TIMEOUT", raw)
+		if got := FromEnv(); got != want {
+			t.Errorf("REQUEST_TIMEOUT=%q: got %v, want %v", raw, got, want)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package deprecation
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+/**
+ * Deprecation Package
+ *
+ * Soft-launch framework for breaking API changes. Routes are wrapped with
+ * Registry.Deprecate, which advertises the change through standard headers
+ * (Deprecation, Sunset and Link) and counts calls per user and token. Counts
+ * are buffered in memory and written to the Store by Flush, which runs as a
+ * background job, so admins can see who still depends on a route before it
+ * is removed.
+ */
+
+// Store persists aggregated usage of deprecated routes
+type Store interface {
+	RecordDeprecatedUsage(ctx context.Context, usage []*models.DeprecatedUsage) error
+}
+
+type usageKey struct {
+	method, path, userID, tokenID string
+}
+
+// Registry tracks deprecated routes and buffers their usage
+type Registry struct {
+	store Store
+
+	mu      sync.Mutex
+	notices []models.DeprecationNotice
+	pending map[usageKey]*models.DeprecatedUsage
+}
+
+// NewRegistry creates a registry that flushes usage to store
+func NewRegistry(store Store) *Registry {
+	return &Registry{store: store, pending: map[usageKey]*models.DeprecatedUsage{}}
+}
+
+// Deprecate registers a notice and returns middleware for the route it describes.
+// Place it after AuthMiddleware so calls can be attributed to a user.
+func (r *Registry) Deprecate(notice models.DeprecationNotice) gin.HandlerFunc {
+	r.mu.Lock()
+	r.notices = append(r.notices, notice)
+	r.mu.Unlock()
+
+	return func(c *gin.Context) {
+		SetHeaders(c.Writer.Header(), notice)
+		r.record(c, notice)
+		c.Next()
+	}
+}
+
+// SetHeaders writes the RFC 9745 Deprecation, RFC 8594 Sunset and Link headers
+func SetHeaders(h http.Header, notice models.DeprecationNotice) {
+	if notice.Since.IsZero() {
+		h.Set("Deprecation", "true")
+	} else {
+		h.Set("Deprecation", "@"+strconv.FormatInt(notice.Since.Unix(), 10))
+	}
+	if !notice.Sunset.IsZero() {
+		h.Set("Sunset", notice.Sunset.UTC().Format(http.TimeFormat))
+	}
+	if notice.Link != "" {
+		h.Add("Link", "<"+notice.Link+`>; rel="deprecation"`)
+	}
+	if notice.Replacement != "" {
+		h.Add("Link", "<"+notice.Replacement+`>; rel="successor-version"`)
+	}
+}
+
+func (r *Registry) record(c *gin.Context, notice models.DeprecationNotice) {
+	credential := c.GetHeader("Authorization")
+	if credential == "" {
+		credential = auth.RequestToken(c) // session cookie
+	}
+	key := usageKey{
+		method:  notice.Method,
+		path:    notice.Path,
+		userID:  auth.GetUserID(c),
+		tokenID: tokenID(credential),
+	}
+	now := time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.pending[key]
+	if !ok {
+		u = &models.DeprecatedUsage{
+			Method:    key.method,
+			Path:      key.path,
+			UserID:    key.userID,
+			TokenID:   key.tokenID,
+			FirstSeen: now,
+		}
+		r.pending[key] = u
+	}
+	u.Count++
+	u.LastSeen = now
+	u.UserAgent = c.Request.UserAgent()
+}
+
+// Notices returns the registered deprecations ordered by sunset date
+func (r *Registry) Notices() []models.DeprecationNotice {
+	r.mu.Lock()
+	notices := append([]models.DeprecationNotice(nil), r.notices...)
+	r.mu.Unlock()
+	sort.Slice(notices, func(i, j int) bool { return notices[i].Sunset.Before(notices[j].Sunset) })
+	return notices
+}
+
+// Flush writes buffered usage to the store. Usage is re-queued if the write fails.
+func (r *Registry) Flush(ctx context.Context) error {
+	r.mu.Lock()
+	if len(r.pending) == 0 {
+		r.mu.Unlock()
+		return nil
+	}
+	batch := make([]*models.DeprecatedUsage, 0, len(r.pending))
+	for _, u := range r.pending {
+		batch = append(batch, u)
+	}
+	r.pending = map[usageKey]*models.DeprecatedUsage{}
+	r.mu.Unlock()
+
+	if err := r.store.RecordDeprecatedUsage(ctx, batch); err != nil {
+		r.requeue(batch)
+		return err
+	}
+	return nil
+}
+
+func (r *Registry) requeue(batch []*models.DeprecatedUsage) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range batch {
+		key := usageKey{u.Method, u.Path, u.UserID, u.TokenID}
+		if cur, ok := r.pending[key]; ok {
+			cur.Count += u.Count
+			cur.FirstSeen = u.FirstSeen
+			continue
+		}
+		r.pending[key] = u
+	}
+}
+
+// tokenID identifies the bearer token without storing it (first 12 hex chars of its SHA-256)
+func tokenID(header string) string {
+	token := strings.TrimSpace(strings.TrimPrefix(header, "Bearer "))
+	if token == "" {
+		return "anonymous"
+	}
+	return auth.HashToken(token)[:12]
+}
@@ -0,0 +1,107 @@
+package deprecation
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"liftoff/backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type memoryStore struct {
+	usage []*models.DeprecatedUsage
+	err   error
+}
+
+func (s *memoryStore) RecordDeprecatedUsage(ctx context.Context, usage []*models.DeprecatedUsage) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.usage = append(s.usage, usage...)
+	return nil
+}
+
+func newTestRouter(reg *Registry, notice models.DeprecationNotice) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/old", reg.Deprecate(notice), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return r
+}
+
+func TestDeprecate_SetsHeaders(t *testing.T) {
+	notice := models.DeprecationNotice{
+		Method:      http.MethodGet,
+		Path:        "/old",
+		Since:       time.Unix(1700000000, 0),
+		Sunset:      time.Date(2027, 1, 31, 0, 0, 0, 0, time.UTC),
+		Link:        "https://example.com/changelog",
+		Replacement: "/new",
+	}
+	r := newTestRouter(NewRegistry(&memoryStore{}), notice)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/old", nil))
+
+	if got := w.Header().Get("Deprecation"); got != "@1700000000" {
+		t.Errorf("Deprecation = %q", got)
+	}
+	if got := w.Header().Get("Sunset"); got != "Sun, 31 Jan 2027 00:00:00 GMT" {
+		t.Errorf("Sunset = %q", got)
+	}
+	if links := w.Header().Values("Link"); len(links) != 2 {
+		t.Errorf("Link = %v, want deprecation and successor links", links)
+	}
+}
+
+func TestRegistry_FlushAggregatesByToken(t *testing.T) {
+	store := &memoryStore{}
+	reg := NewRegistry(store)
+	r := newTestRouter(reg, models.DeprecationNotice{Method: http.MethodGet, Path: "/old"})
+
+	for _, token := range []string{"token-a", "token-a", "token-b"} {
+		req := httptest.NewRequest(http.MethodGet, "/old", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	if err := reg.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.usage) != 2 {
+		t.Fatalf("got %d usage rows, want 2", len(store.usage))
+	}
+	var total int64
+	for _, u := range store.usage {
+		total += u.Count
+	}
+	if total != 3 {
+		t.Errorf("total count = %d, want 3", total)
+	}
+}
+
+func TestRegistry_FlushRequeuesOnError(t *testing.T) {
+	store := &memoryStore{err: errors.New("db down")}
+	reg := NewRegistry(store)
+	r := newTestRouter(reg, models.DeprecationNotice{Method: http.MethodGet, Path: "/old"})
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/old", nil))
+
+	if err := reg.Flush(context.Background()); err == nil {
+		t.Fatal("expected flush error")
+	}
+
+	store.err = nil
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/old", nil))
+	if err := reg.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.usage) != 1 || store.usage[0].Count != 2 {
+		t.Errorf("usage = %+v, want one row with count 2", store.usage)
+	}
+}
@@ -0,0 +1,175 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/**
+ * Email Package
+ *
+ * Transactional email (password resets, address verification, welcome and
+ * sign-in links). Messages are rendered from the HTML and plain-text
+ * templates in templates/ and handed to a Sender: SMTP, SendGrid or Amazon
+ * SES, chosen with EMAIL_PROVIDER and wrapped to retry transient failures.
+ * Without a provider a LogSender writes messages to the log so links can be
+ * followed in development.
+ */
+
+// Message is a rendered email with HTML and plain-text bodies
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Sender delivers messages
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// LogSender logs messages instead of sending them
+type LogSender struct{}
+
+// Send implements Sender
+func (LogSender) Send(ctx context.Context, msg Message) error {
+	log.Printf("Email to %s (dev mode): %s\n%s", msg.To, msg.Subject, msg.Text)
+	return nil
+}
+
+// defaultFromAddress is used when neither EMAIL_FROM nor SMTP_FROM is set
+const defaultFromAddress = "Liftoff <no-reply@liftoff.local>"
+
+// fromAddressFromEnv reads EMAIL_FROM, falling back to SMTP_FROM
+func fromAddressFromEnv() string {
+	for _, key := range []string{"EMAIL_FROM", "SMTP_FROM"} {
+		if v := os.Getenv(key); v != "" {
+			return v
+		}
+	}
+	return defaultFromAddress
+}
+
+// SenderFromEnv picks the provider named by EMAIL_PROVIDER (smtp, sendgrid, ses or log).
+// Without it, SMTP is used when SMTP_HOST is set and messages are logged otherwise.
+// Real providers retry transient failures (EMAIL_MAX_ATTEMPTS, default 3).
+func SenderFromEnv() (Sender, error) {
+	provider := strings.ToLower(os.Getenv("EMAIL_PROVIDER"))
+	if provider == "" && os.Getenv("SMTP_HOST") != "" {
+		provider = "smtp"
+	}
+
+	var sender Sender
+	switch provider {
+	case "", "log":
+		return LogSender{}, nil
+	case "smtp":
+		cfg := SMTPConfigFromEnv()
+		if cfg.Host == "" {
+			return nil, errors.New("EMAIL_PROVIDER=smtp requires SMTP_HOST")
+		}
+		sender = NewSMTPSender(cfg)
+	case "sendgrid":
+		key := os.Getenv("SENDGRID_API_KEY")
+		if key == "" {
+			return nil, errors.New("EMAIL_PROVIDER=sendgrid requires SENDGRID_API_KEY")
+		}
+		sender = NewSendGridSender(key, fromAddressFromEnv())
+	case "ses":
+		cfg := SESConfigFromEnv()
+		if cfg.Region == "" || cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+			return nil, errors.New("EMAIL_PROVIDER=ses requires AWS_REGION, AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+		}
+		sender = NewSESSender(cfg)
+	default:
+		return nil, fmt.Errorf("unknown EMAIL_PROVIDER %q", provider)
+	}
+
+	attempts, err := strconv.Atoi(os.Getenv("EMAIL_MAX_ATTEMPTS"))
+	if err != nil || attempts < 1 {
+		attempts = defaultMaxAttempts
+	}
+	return NewRetrySender(provider, sender, attempts), nil
+}
+
+// ErrInvalidMessage is returned for messages no provider could deliver, so they are not retried
+var ErrInvalidMessage = errors.New("invalid message")
+
+// addresses validates the message's recipient and subject and parses both addresses
+func (m Message) addresses(from string) (to, sender *mail.Address, err error) {
+	if to, err = mail.ParseAddress(m.To); err != nil {
+		return nil, nil, fmt.Errorf("%w: recipient %q: %v", ErrInvalidMessage, m.To, err)
+	}
+	if sender, err = mail.ParseAddress(from); err != nil {
+		return nil, nil, fmt.Errorf("%w: sender %q: %v", ErrInvalidMessage, from, err)
+	}
+	if strings.ContainsAny(m.Subject, "\r\n") {
+		return nil, nil, fmt.Errorf("%w: subject must be a single line", ErrInvalidMessage)
+	}
+	return to, sender, nil
+}
+
+// build encodes the message as a multipart/alternative MIME document
+func (m Message) build(from string, now time.Time) ([]byte, error) {
+	to, sender, err := m.addresses(from)
+	if err != nil {
+		return nil, err
+	}
+
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+	for _, alt := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", m.Text},
+		{"text/html; charset=utf-8", m.HTML},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {alt.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(alt.content)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	domain := sender.Address[strings.LastIndex(sender.Address, "@")+1:]
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", sender.String())
+	fmt.Fprintf(&msg, "To: %s\r\n", to.String())
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "Message-ID: <%s@%s>\r\n", hex.EncodeToString(id), domain)
+	fmt.Fprintf(&msg, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", parts.Boundary())
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
@@ -0,0 +1,167 @@
+package email
+
+import (
+	"bufio"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTemplates(t *testing.T) {
+	msg, err := PasswordReset("jo@example.com", "https://app.example/reset-password?token=a&b=<c>", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.To != "jo@example.com" || msg.Subject == "" {
+		t.Errorf("message = %+v", msg)
+	}
+	if !strings.Contains(msg.Text, "token=a&b=<c>") || !strings.Contains(msg.Text, "expires in 1 hour") {
+		t.Errorf("text = %q", msg.Text)
+	}
+	// The HTML body escapes the link
+	if !strings.Contains(msg.HTML, "token=a&amp;b=%3cc%3e") || strings.Contains(msg.HTML, "<c>") {
+		t.Errorf("html = %q", msg.HTML)
+	}
+
+	for _, render := range []func() (Message, error){
+		func() (Message, error) { return VerifyEmail("jo@example.com", "https://x/confirm", 15*time.Minute) },
+		func() (Message, error) { return Welcome("jo@example.com", "https://x") },
+		func() (Message, error) { return MagicLink("jo@example.com", "https://x/magic", 2*time.Hour) },
+		func() (Message, error) {
+			return NewLogin("jo@example.com", "Firefox", "203.0.113.9", time.Now(), "https://x/forgot-password")
+		},
+		func() (Message, error) {
+			return InactivityWarning("jo@example.com", "https://x/login", time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC))
+		},
+	} {
+		msg, err := render()
+		if err != nil || !strings.Contains(msg.Text, "https://x") || !strings.Contains(msg.HTML, "https://x") {
+			t.Errorf("render %q: %v", msg.Subject, err)
+		}
+	}
+	if msg, _ := InactivityWarning("jo@example.com", "https://x", time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC)); !strings.Contains(msg.Text, "March 4, 2025") {
+		t.Errorf("inactivity text = %q", msg.Text)
+	}
+	if msg, _ := NewLogin("jo@example.com", "<script>", "203.0.113.9", time.Date(2025, 3, 4, 9, 30, 0, 0, time.UTC), "https://x"); msg.Subject != "New login to your Liftoff account" ||
+		!strings.Contains(msg.Text, "March 4, 2025 at 09:30 UTC") || !strings.Contains(msg.Text, "203.0.113.9") || strings.Contains(msg.HTML, "<script>") {
+		t.Errorf("new login = %q / %q", msg.Subject, msg.Text)
+	}
+}
+
+func TestMessageBuild(t *testing.T) {
+	msg := Message{To: "jo@example.com", Subject: "Héllo", Text: "plain body", HTML: "<p>html body</p>"}
+	raw, err := msg.build("Liftoff <no-reply@liftoff.local>", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subject, _ := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject")); subject != "Héllo" {
+		t.Errorf("subject = %q", subject)
+	}
+	_, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := multipart.NewReader(parsed.Body, params["boundary"])
+	var bodies []string
+	for {
+		part, err := parts.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(part)
+		bodies = append(bodies, part.Header.Get("Content-Type")+": "+string(b))
+	}
+	if len(bodies) != 2 || bodies[0] != "text/plain; charset=utf-8: plain body" || bodies[1] != "text/html; charset=utf-8: <p>html body</p>" {
+		t.Errorf("parts = %q", bodies)
+	}
+
+	if _, err := (Message{To: "jo@example.com\r\nBcc: x@evil.example", Subject: "x"}).build("a@b.c", time.Now()); err == nil {
+		t.Error("header injection in recipient accepted")
+	}
+}
+
+func TestSMTPSender(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	// A minimal SMTP server that accepts one message
+	received := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(s string) { io.WriteString(conn, s+"\r\n") }
+		reply("220 test ESMTP")
+		var envelope []string
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"):
+				reply("250-test")
+				reply("250 8BITMIME")
+			case strings.HasPrefix(cmd, "MAIL"), strings.HasPrefix(cmd, "RCPT"):
+				envelope = append(envelope, strings.TrimSpace(line))
+				reply("250 OK")
+			case cmd == "DATA":
+				reply("354 go ahead")
+				var data strings.Builder
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil || l == ".\r\n" {
+						break
+					}
+					data.WriteString(l)
+				}
+				received <- strings.Join(envelope, "\n") + "\n" + data.String()
+				reply("250 queued")
+			case cmd == "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("502 unsupported")
+			}
+		}
+	}()
+
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+	sender := NewSMTPSender(SMTPConfig{Host: "127.0.0.1", Port: port, From: "Liftoff <no-reply@liftoff.local>"})
+	msg, err := Welcome("jo@example.com", "https://app.example")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sender.Send(t.Context(), msg); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case got := <-received:
+		for _, want := range []string{"MAIL FROM:<no-reply@liftoff.local>", "RCPT TO:<jo@example.com>", "Subject: Welcome to Liftoff", "multipart/alternative"} {
+			if !strings.Contains(got, want) {
+				t.Errorf("missing %q in:\n%s", want, got)
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message received")
+	}
+}
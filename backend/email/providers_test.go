@@ -0,0 +1,146 @@
+package email
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSendGridSender(t *testing.T) {
+	var got sendGridRequest
+	var authHeader string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeader = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	s := NewSendGridSender("sg-key", "Liftoff <no-reply@liftoff.test>")
+	s.url = srv.URL
+	err := s.Send(context.Background(), Message{To: "jo@example.com", Subject: "Hi", Text: "text", HTML: "<p>html</p>"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authHeader != "Bearer sg-key" {
+		t.Errorf("Authorization = %q", authHeader)
+	}
+	if got.From.Email != "no-reply@liftoff.test" || got.From.Name != "Liftoff" || got.Subject != "Hi" {
+		t.Errorf("request = %+v", got)
+	}
+	if len(got.Personalizations) != 1 || got.Personalizations[0].To[0].Email != "jo@example.com" {
+		t.Errorf("personalizations = %+v", got.Personalizations)
+	}
+	if len(got.Content) != 2 || got.Content[0].Type != "text/plain" || got.Content[1].Value != "<p>html</p>" {
+		t.Errorf("content = %+v", got.Content)
+	}
+
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad request", http.StatusBadRequest)
+	})
+	err = s.Send(context.Background(), Message{To: "jo@example.com", Subject: "Hi", Text: "text"})
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusBadRequest || retryable(err) {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func TestSESSender(t *testing.T) {
+	var got sesRequest
+	var r0 *http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r0 = r
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"MessageId":"abc"}`))
+	}))
+	defer srv.Close()
+
+	s := NewSESSender(SESConfig{
+		Region: "eu-west-1", AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret",
+		SessionToken: "session", From: "no-reply@liftoff.test", Endpoint: srv.URL,
+	})
+	s.now = func() time.Time { return time.Date(2025, 3, 4, 5, 6, 7, 0, time.UTC) }
+	if err := s.Send(context.Background(), Message{To: "jo@example.com", Subject: "Hi", Text: "text", HTML: "<p>html</p>"}); err != nil {
+		t.Fatal(err)
+	}
+	if r0.URL.Path != "/v2/email/outbound-emails" {
+		t.Errorf("path = %q", r0.URL.Path)
+	}
+	authz := r0.Header.Get("Authorization")
+	if !strings.HasPrefix(authz, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20250304/eu-west-1/ses/aws4_request, ") ||
+		!strings.Contains(authz, "SignedHeaders=content-type;host;x-amz-date;x-amz-security-token,") {
+		t.Errorf("Authorization = %q", authz)
+	}
+	if r0.Header.Get("X-Amz-Date") != "20250304T050607Z" || r0.Header.Get("X-Amz-Security-Token") != "session" {
+		t.Errorf("headers = %v", r0.Header)
+	}
+	if got.FromEmailAddress != "<no-reply@liftoff.test>" || got.Destination.ToAddresses[0] != "<jo@example.com>" ||
+		got.Content.Simple.Subject.Data != "Hi" || got.Content.Simple.Body.HTML.Data != "<p>html</p>" {
+		t.Errorf("request = %+v", got)
+	}
+}
+
+// TestSignV4 checks the signer against the get-vanilla case from the AWS SigV4 test suite
+func TestSignV4(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	signV4(req, nil, "service", "us-east-1", "AKIDEXAMPLE", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+		"SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization = %q, want %q", got, want)
+	}
+}
+
+type stubSender struct {
+	errs  []error
+	calls int
+}
+
+func (s *stubSender) Send(ctx context.Context, msg Message) error {
+	s.calls++
+	if s.calls <= len(s.errs) {
+		return s.errs[s.calls-1]
+	}
+	return nil
+}
+
+func TestRetrySender(t *testing.T) {
+	unavailable := &HTTPError{Provider: "test", StatusCode: http.StatusServiceUnavailable}
+	cases := []struct {
+		name      string
+		errs      []error
+		wantCalls int
+		wantErr   bool
+	}{
+		{"succeeds after transient failures", []error{unavailable, unavailable}, 3, false},
+		{"gives up after max attempts", []error{unavailable, unavailable, unavailable, unavailable}, 3, true},
+		{"does not retry rejections", []error{&HTTPError{Provider: "test", StatusCode: http.StatusBadRequest}}, 1, true},
+		{"does not retry invalid messages", []error{ErrInvalidMessage}, 1, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			stub := &stubSender{errs: tc.errs}
+			s := NewRetrySender("test", stub, 3)
+			var waits []time.Duration
+			s.sleep = func(ctx context.Context, d time.Duration) error {
+				waits = append(waits, d)
+				return nil
+			}
+			err := s.Send(context.Background(), Message{To: "jo@example.com"})
+			if (err != nil) != tc.wantErr || stub.calls != tc.wantCalls {
+				t.Errorf("err = %v, calls = %d", err, stub.calls)
+			}
+			for _, d := range waits {
+				if d <= 0 || d > retryMaxDelay {
+					t.Errorf("wait = %v", d)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"net/textproto"
+	"time"
+)
+
+const (
+	defaultMaxAttempts = 3
+	retryBaseDelay     = 500 * time.Millisecond
+	retryMaxDelay      = 10 * time.Second
+)
+
+// HTTPError is a rejected request to an HTTP email API
+type HTTPError struct {
+	Provider   string
+	StatusCode int
+	Body       string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("%s returned %d: %s", e.Provider, e.StatusCode, e.Body)
+}
+
+// retryable reports whether a failed send may succeed if tried again: throttling,
+// server errors, SMTP 4xx replies and network failures are; invalid messages,
+// other rejections and cancelled contexts are not
+func retryable(err error) bool {
+	if errors.Is(err, ErrInvalidMessage) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == 429 || httpErr.StatusCode >= 500
+	}
+	var smtpErr *textproto.Error
+	if errors.As(err, &smtpErr) {
+		return smtpErr.Code >= 400 && smtpErr.Code < 500
+	}
+	return true
+}
+
+// RetrySender retries transient failures of another sender with exponential backoff
+// and jitter, and logs every failed delivery
+type RetrySender struct {
+	name     string
+	next     Sender
+	attempts int
+	sleep    func(ctx context.Context, d time.Duration) error
+}
+
+// NewRetrySender wraps next, trying each message up to attempts times
+func NewRetrySender(name string, next Sender, attempts int) *RetrySender {
+	return &RetrySender{name: name, next: next, attempts: attempts, sleep: sleepContext}
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// Send implements Sender
+func (s *RetrySender) Send(ctx context.Context, msg Message) error {
+	delay := retryBaseDelay
+	for attempt := 1; ; attempt++ {
+		err := s.next.Send(ctx, msg)
+		if err == nil {
+			return nil
+		}
+		if attempt >= s.attempts || !retryable(err) {
+			log.Printf("Email delivery via %s to %s failed after %d attempt(s): %v", s.name, msg.To, attempt, err)
+			return err
+		}
+		// Jitter keeps retries of many messages from arriving in lockstep
+		wait := delay/2 + rand.N(delay/2+1)
+		log.Printf("Email delivery via %s to %s failed (attempt %d/%d), retrying in %v: %v", s.name, msg.To, attempt, s.attempts, wait, err)
+		if err := s.sleep(ctx, wait); err != nil {
+			return err
+		}
+		delay = min(delay*2, retryMaxDelay)
+	}
+}
@@ -0,0 +1,88 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+)
+
+// sendGridURL is the SendGrid v3 mail send endpoint
+const sendGridURL = "https://api.sendgrid.com/v3/mail/send"
+
+// SendGridSender delivers mail through the SendGrid v3 API
+type SendGridSender struct {
+	apiKey string
+	from   string
+	url    string
+	client *http.Client
+}
+
+// NewSendGridSender creates a SendGrid sender
+func NewSendGridSender(apiKey, from string) *SendGridSender {
+	return &SendGridSender{apiKey: apiKey, from: from, url: sendGridURL, client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+type sendGridPersonalization struct {
+	To []sendGridAddress `json:"to"`
+}
+
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sendGridRequest struct {
+	Personalizations []sendGridPersonalization `json:"personalizations"`
+	From             sendGridAddress           `json:"from"`
+	Subject          string                    `json:"subject"`
+	Content          []sendGridContent         `json:"content"`
+}
+
+// Send implements Sender
+func (s *SendGridSender) Send(ctx context.Context, msg Message) error {
+	to, from, err := msg.addresses(s.from)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(sendGridRequest{
+		Personalizations: []sendGridPersonalization{{To: []sendGridAddress{{Email: to.Address, Name: to.Name}}}},
+		From:             sendGridAddress{Email: from.Address, Name: from.Name},
+		Subject:          msg.Subject,
+		// SendGrid requires text/plain before text/html
+		Content: []sendGridContent{{Type: "text/plain", Value: msg.Text}, {Type: "text/html", Value: msg.HTML}},
+	})
+	if err != nil {
+		return err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+s.apiKey)
+	httpReq.Header.Set("Content-Type", "application/json")
+	return doEmailRequest(s.client, "sendgrid", httpReq)
+}
+
+// doEmailRequest sends an API request and turns non-2xx responses into *HTTPError
+func doEmailRequest(client *http.Client, provider string, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return &HTTPError{Provider: provider, StatusCode: resp.StatusCode, Body: string(bytes.TrimSpace(detail))}
+}
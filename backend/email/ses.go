@@ -0,0 +1,165 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SESConfig holds Amazon SES v2 settings
+type SESConfig struct {
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string // for temporary credentials
+	From            string
+	Endpoint        string // default https://email.<region>.amazonaws.com
+}
+
+// SESConfigFromEnv reads AWS_REGION (or AWS_DEFAULT_REGION), AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN and SES_ENDPOINT
+func SESConfigFromEnv() SESConfig {
+	cfg := SESConfig{
+		Region:          os.Getenv("AWS_REGION"),
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		From:            fromAddressFromEnv(),
+		Endpoint:        os.Getenv("SES_ENDPOINT"),
+	}
+	if cfg.Region == "" {
+		cfg.Region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	return cfg
+}
+
+// SESSender delivers mail through the Amazon SES v2 SendEmail API
+type SESSender struct {
+	cfg    SESConfig
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewSESSender creates an SES sender
+func NewSESSender(cfg SESConfig) *SESSender {
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://email." + cfg.Region + ".amazonaws.com"
+	}
+	cfg.Endpoint = strings.TrimRight(cfg.Endpoint, "/")
+	return &SESSender{cfg: cfg, client: &http.Client{Timeout: 30 * time.Second}, now: time.Now}
+}
+
+type sesContent struct {
+	Data    string `json:"Data"`
+	Charset string `json:"Charset"`
+}
+
+type sesRequest struct {
+	FromEmailAddress string `json:"FromEmailAddress"`
+	Destination      struct {
+		ToAddresses []string `json:"ToAddresses"`
+	} `json:"Destination"`
+	Content struct {
+		Simple struct {
+			Subject sesContent `json:"Subject"`
+			Body    struct {
+				Text sesContent `json:"Text"`
+				HTML sesContent `json:"Html"`
+			} `json:"Body"`
+		} `json:"Simple"`
+	} `json:"Content"`
+}
+
+// Send implements Sender
+func (s *SESSender) Send(ctx context.Context, msg Message) error {
+	to, from, err := msg.addresses(s.cfg.From)
+	if err != nil {
+		return err
+	}
+	var req sesRequest
+	req.FromEmailAddress = from.String()
+	req.Destination.ToAddresses = []string{to.String()}
+	req.Content.Simple.Subject = sesContent{Data: msg.Subject, Charset: "UTF-8"}
+	req.Content.Simple.Body.Text = sesContent{Data: msg.Text, Charset: "UTF-8"}
+	req.Content.Simple.Body.HTML = sesContent{Data: msg.HTML, Charset: "UTF-8"}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.Endpoint+"/v2/email/outbound-emails", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if s.cfg.SessionToken != "" {
+		httpReq.Header.Set("X-Amz-Security-Token", s.cfg.SessionToken)
+	}
+	signV4(httpReq, body, "ses", s.cfg.Region, s.cfg.AccessKeyID, s.cfg.SecretAccessKey, s.now())
+	return doEmailRequest(s.client, "ses", httpReq)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// signV4 adds an AWS Signature Version 4 Authorization header to req, signing
+// the host, the X-Amz-Date it sets and any Content-Type and X-Amz-* headers.
+// The request path must already be URI-encoded and the query string empty.
+func signV4(req *http.Request, body []byte, service, region, accessKeyID, secretKey string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "content-type" || strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method, path, req.URL.RawQuery, canonicalHeaders.String(), signedHeaders, sha256Hex(body),
+	}, "\n")
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+accessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
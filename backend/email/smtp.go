@@ -0,0 +1,114 @@
+package email
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"time"
+)
+
+// defaultSMTPTimeout bounds a delivery when the context has no deadline
+const defaultSMTPTimeout = 30 * time.Second
+
+// SMTPConfig holds SMTP server settings
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string // empty disables AUTH
+	Password string
+	From     string
+}
+
+// SMTPConfigFromEnv reads SMTP_HOST, SMTP_PORT (default 587), SMTP_USERNAME
+// and SMTP_PASSWORD, with the sender from fromAddressFromEnv
+func SMTPConfigFromEnv() SMTPConfig {
+	cfg := SMTPConfig{
+		Host:     os.Getenv("SMTP_HOST"),
+		Port:     os.Getenv("SMTP_PORT"),
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     fromAddressFromEnv(),
+	}
+	if cfg.Port == "" {
+		cfg.Port = "587"
+	}
+	return cfg
+}
+
+// SMTPSender delivers mail through an SMTP server. Port 465 uses implicit TLS;
+// other ports upgrade with STARTTLS when the server offers it.
+type SMTPSender struct {
+	cfg SMTPConfig
+}
+
+// NewSMTPSender creates an SMTP sender
+func NewSMTPSender(cfg SMTPConfig) *SMTPSender {
+	return &SMTPSender{cfg: cfg}
+}
+
+// Send implements Sender
+func (s *SMTPSender) Send(ctx context.Context, msg Message) error {
+	to, from, err := msg.addresses(s.cfg.From)
+	if err != nil {
+		return err
+	}
+	data, err := msg.build(s.cfg.From, time.Now())
+	if err != nil {
+		return err
+	}
+
+	addr := net.JoinHostPort(s.cfg.Host, s.cfg.Port)
+	tlsConfig := &tls.Config{ServerName: s.cfg.Host}
+	var conn net.Conn
+	if s.cfg.Port == "465" {
+		conn, err = (&tls.Dialer{Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("connect to %s: %w", addr, err)
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(defaultSMTPTimeout)
+	}
+	conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("smtp handshake: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok && s.cfg.Port != "465" {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("smtp starttls: %w", err)
+		}
+	}
+	if s.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)); err != nil {
+			return fmt.Errorf("smtp auth: %w", err)
+		}
+	}
+	if err := client.Mail(from.Address); err != nil {
+		return fmt.Errorf("smtp mail from: %w", err)
+	}
+	if err := client.Rcpt(to.Address); err != nil {
+		return fmt.Errorf("smtp rcpt to: %w", err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp data: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("smtp data: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("smtp data: %w", err)
+	}
+	return client.Quit()
+}
@@ -0,0 +1,104 @@
+package email
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+	"time"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+var (
+	htmlTemplates = htmltemplate.Must(htmltemplate.ParseFS(templateFS, "templates/*.html"))
+	textTemplates = texttemplate.Must(texttemplate.ParseFS(templateFS, "templates/*.txt"))
+)
+
+// templateData is what every template renders from
+type templateData struct {
+	Subject     string
+	Email       string
+	Link        string
+	ExpiresIn   string
+	DeleteAfter string
+	When        string
+	Device      string
+	IP          string
+}
+
+func render(name string, data templateData) (Message, error) {
+	var html, text bytes.Buffer
+	if err := htmlTemplates.ExecuteTemplate(&html, name+".html", data); err != nil {
+		return Message{}, fmt.Errorf("render %s.html: %w", name, err)
+	}
+	if err := textTemplates.ExecuteTemplate(&text, name+".txt", data); err != nil {
+		return Message{}, fmt.Errorf("render %s.txt: %w", name, err)
+	}
+	return Message{To: data.Email, Subject: data.Subject, Text: text.String(), HTML: html.String()}, nil
+}
+
+// formatTTL describes a link lifetime such as "1 hour" or "15 minutes"
+func formatTTL(d time.Duration) string {
+	unit, n := "minute", int(d.Round(time.Minute)/time.Minute)
+	if d >= time.Hour && d%time.Hour == 0 {
+		unit, n = "hour", int(d/time.Hour)
+	}
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+// PasswordReset renders the password reset email
+func PasswordReset(to, link string, ttl time.Duration) (Message, error) {
+	return render("password_reset", templateData{
+		Subject: "Reset your Liftoff password", Email: to, Link: link, ExpiresIn: formatTTL(ttl),
+	})
+}
+
+// VerifyEmail renders the confirmation sent to a new email address
+func VerifyEmail(to, link string, ttl time.Duration) (Message, error) {
+	return render("verify_email", templateData{
+		Subject: "Confirm your new Liftoff email address", Email: to, Link: link, ExpiresIn: formatTTL(ttl),
+	})
+}
+
+// Welcome renders the email sent after registration
+func Welcome(to, appURL string) (Message, error) {
+	return render("welcome", templateData{Subject: "Welcome to Liftoff", Email: to, Link: appURL})
+}
+
+// MagicLink renders a one-time sign-in link
+func MagicLink(to, link string, ttl time.Duration) (Message, error) {
+	return render("magic_link", templateData{
+		Subject: "Your Liftoff sign-in link", Email: to, Link: link, ExpiresIn: formatTTL(ttl),
+	})
+}
+
+// InactivityWarning renders the notice sent before an inactive account is deleted
+func InactivityWarning(to, signInURL string, deleteAfter time.Time) (Message, error) {
+	return render("inactivity_warning", templateData{
+		Subject:     "Your Liftoff account will be deleted",
+		Email:       to,
+		Link:        signInURL,
+		DeleteAfter: deleteAfter.UTC().Format("January 2, 2006"),
+	})
+}
+
+// NewLogin renders the notice sent when an account is signed in to from a new device
+func NewLogin(to, device, ip string, at time.Time, resetURL string) (Message, error) {
+	if device == "" {
+		device = "Unknown device"
+	}
+	return render("new_login", templateData{
+		Subject: "New login to your Liftoff account",
+		Email:   to,
+		Link:    resetURL,
+		When:    at.UTC().Format("January 2, 2006 at 15:04 UTC"),
+		Device:  device,
+		IP:      ip,
+	})
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeleteAccount permanently erases the authenticated user's account and data.
+// The route requires a step-up token from POST /api/auth/reauth.
+func (h *AuthHandler) DeleteAccount(c *gin.Context) {
+	if auth.GetAPIKeyID(c) != "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "API keys cannot delete the account"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	user, err := h.userRepo.GetByID(ctx, auth.GetUserID(c))
+	if err != nil || user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	err = h.userRepo.DeleteAccount(ctx, user.ID)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+	if errors.Is(err, repository.ErrLegalHold) {
+		c.JSON(http.StatusConflict, gin.H{"error": "This account cannot be deleted right now; contact support"})
+		return
+	}
+	if err != nil {
+		log.Printf("Error deleting account %s: %v", user.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete account"})
+		return
+	}
+
+	log.Printf("Account %s deleted at the user's request", user.ID)
+	c.JSON(http.StatusOK, gin.H{"message": "Account and all associated data deleted"})
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/database"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestDeleteAccount(t *testing.T) {
+	// The mock database has the full schema and a demo user with a month of history
+	db, err := database.NewMockDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	sqlite := db.GetSQLite()
+	if _, err := sqlite.Exec(`INSERT INTO api_keys (id, user_id, name, prefix, key_hash, created_at) VALUES ('k1', ?, 'k', 'lft_', 'h', 0)`, database.DemoUserID); err != nil {
+		t.Fatal(err)
+	}
+
+	userRepo := repository.NewUserRepository(nil, sqlite, true)
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := NewAuthHandler(userRepo)
+	r.POST("/reauth", auth.AuthMiddleware(), h.Reauth)
+	r.DELETE("/account", auth.AuthMiddleware(), auth.RequireReauth(), h.DeleteAccount)
+	token, _, err := auth.GenerateToken(database.DemoUserID, database.DemoUserEmail, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reauth := func(password string) *httptest.ResponseRecorder {
+		raw, _ := json.Marshal(map[string]string{"password": password})
+		req := httptest.NewRequest(http.MethodPost, "/reauth", bytes.NewReader(raw))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	del := func(reauthToken string) int {
+		req := httptest.NewRequest(http.MethodDelete, "/account", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set(auth.ReauthHeader, reauthToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if w := reauth("wrong"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("wrong password: got %d", w.Code)
+	}
+	if code := del(""); code != http.StatusUnauthorized {
+		t.Fatalf("delete without reauth: got %d", code)
+	}
+	w := reauth(database.DemoUserPassword)
+	var body struct {
+		ReauthToken string `json:"reauthToken"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("reauth: got %d %s", w.Code, w.Body.String())
+	}
+	if code := del(token); code != http.StatusUnauthorized {
+		t.Fatalf("session token as reauth token: got %d", code)
+	}
+	req := httptest.NewRequest(http.MethodDelete, "/account", nil)
+	req.Header.Set("Authorization", "Bearer "+body.ReauthToken)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("reauth token as session token: got %d", w.Code)
+	}
+	if code := del(body.ReauthToken); code != http.StatusOK {
+		t.Fatalf("delete: got %d", code)
+	}
+
+	for _, q := range []string{
+		`SELECT COUNT(*) FROM users WHERE id = '` + database.DemoUserID + `'`,
+		`SELECT COUNT(*) FROM workouts WHERE user_id = '` + database.DemoUserID + `'`,
+		`SELECT COUNT(*) FROM workout_sessions WHERE user_id = '` + database.DemoUserID + `'`,
+		`SELECT COUNT(*) FROM routines WHERE user_id = '` + database.DemoUserID + `'`,
+		`SELECT COUNT(*) FROM api_keys WHERE user_id = '` + database.DemoUserID + `'`,
+		`SELECT COUNT(*) FROM exercises`,
+		`SELECT COUNT(*) FROM session_exercises`,
+		`SELECT COUNT(*) FROM exercise_sets`,
+	} {
+		var n int
+		if err := sqlite.QueryRow(q).Scan(&n); err != nil || n != 0 {
+			t.Errorf("%s = %d, %v", q, n, err)
+		}
+	}
+
+	// Outstanding tokens for the deleted user are rejected
+	revoked, err := repository.NewTokenRevocationRepository(nil, sqlite, true).IsTokenRevoked(t.Context(), "", database.DemoUserID, time.Time{})
+	if err != nil || !revoked {
+		t.Errorf("token of deleted user revoked = %v, %v", revoked, err)
+	}
+}
@@ -1,8 +1,11 @@
 package handlers
 
 import (
+	"log"
 	"net/http"
+	"time"
 
+	"liftoff/backend/auth"
 	"liftoff/backend/models"
 	"liftoff/backend/repository"
 
@@ -42,3 +45,47 @@ func (h *AdminHandler) GetStats(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, stats)
 }
+
+// ImpersonationResponse is a sign-in response for the target user, naming the admin behind it
+type ImpersonationResponse struct {
+	AuthResponse
+	ImpersonatedBy string `json:"impersonatedBy"`
+}
+
+// Impersonate issues a short-lived token signed in as another user, for support (admin only).
+// The token carries the admin in its impersonated_by claim and every request made with it is logged.
+// Other admins cannot be impersonated.
+func (h *AdminHandler) Impersonate(c *gin.Context) {
+	adminID := auth.GetUserID(c)
+	if auth.GetImpersonatorID(c) != "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Cannot impersonate while impersonating"})
+		return
+	}
+	target, err := h.userRepo.GetByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		log.Printf("Error getting user to impersonate: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to impersonate user"})
+		return
+	}
+	if target == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if target.ID == adminID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot impersonate yourself"})
+		return
+	}
+	if auth.EffectiveRole(target.Role, target.Email) == auth.RoleAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admins cannot be impersonated"})
+		return
+	}
+
+	token, expiresAt, err := auth.GenerateImpersonationToken(target.ID, target.Email, adminID)
+	if err != nil {
+		log.Printf("Error generating impersonation token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to impersonate user"})
+		return
+	}
+	log.Printf("Impersonation: admin %s started impersonating user %s until %s", adminID, target.ID, expiresAt.Format(time.RFC3339))
+	c.JSON(http.StatusOK, ImpersonationResponse{AuthResponse: newAuthResponse(target, token, expiresAt), ImpersonatedBy: adminID})
+}
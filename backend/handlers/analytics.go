@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"liftoff/backend/analytics"
+	"liftoff/backend/auth"
+	"liftoff/backend/calc"
+	"liftoff/backend/models"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AnalyticsHandler serves derived training metrics and the alerts raised from them
+type AnalyticsHandler struct {
+	sessionRepo *repository.SessionRepository
+	alertRepo   *repository.AlertRepository
+}
+
+// NewAnalyticsHandler creates a new analytics handler
+func NewAnalyticsHandler(sessionRepo *repository.SessionRepository, alertRepo *repository.AlertRepository) *AnalyticsHandler {
+	return &AnalyticsHandler{sessionRepo: sessionRepo, alertRepo: alertRepo}
+}
+
+// GetLoad returns weekly training load and the acute:chronic ratio (?metric=tonnage|duration)
+func (h *AnalyticsHandler) GetLoad(c *gin.Context) {
+	metric := c.DefaultQuery("metric", models.LoadMetricTonnage)
+	if metric != models.LoadMetricTonnage && metric != models.LoadMetricDuration {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "metric must be tonnage or duration"})
+		return
+	}
+	load, err := h.computeLoad(c.Request.Context(), auth.GetUserID(c), metric)
+	if err != nil {
+		log.Printf("Error computing training load: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute training load"})
+		return
+	}
+	c.JSON(http.StatusOK, load)
+}
+
+// CheckLoadRamp raises a load_ramp alert when the user's tonnage ratio exceeds the
+// threshold, at most once a week. Called after a session ends.
+func (h *AnalyticsHandler) CheckLoadRamp(ctx context.Context, userID string) error {
+	load, err := h.computeLoad(ctx, userID, models.LoadMetricTonnage)
+	if err != nil || !load.RampAlert {
+		return err
+	}
+	recent, err := h.alertRepo.HasAlertSince(ctx, userID, models.AlertKindLoadRamp, time.Now().Add(-analytics.Week))
+	if err != nil || recent {
+		return err
+	}
+	msg := fmt.Sprintf("Your training load this week is %.2fx your 4-week average. Consider a lighter session to reduce injury risk.", *load.Ratio)
+	_, err = h.alertRepo.CreateAlert(ctx, userID, models.AlertKindLoadRamp, msg)
+	return err
+}
+
+func (h *AnalyticsHandler) computeLoad(ctx context.Context, userID, metric string) (*models.TrainingLoad, error) {
+	now := time.Now()
+	sessions, err := h.sessionRepo.GetSessionLoads(ctx, userID, now.Add(-analytics.ChronicWeeks*analytics.Week))
+	if err != nil {
+		return nil, err
+	}
+	return analytics.ComputeLoad(sessions, now, metric, analytics.RampThresholdFromEnv()), nil
+}
+
+// GetImbalance compares left and right sides of unilateral exercises week by week (?weeks=12, up to 52)
+func (h *AnalyticsHandler) GetImbalance(c *gin.Context) {
+	weeks, err := strconv.Atoi(c.DefaultQuery("weeks", "12"))
+	if err != nil || weeks < 1 || weeks > 52 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "weeks must be between 1 and 52"})
+		return
+	}
+	now := time.Now()
+	entries, err := h.sessionRepo.GetSideSets(c.Request.Context(), auth.GetUserID(c), now.Add(-time.Duration(weeks)*analytics.Week))
+	if err != nil {
+		log.Printf("Error computing imbalance: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute imbalance"})
+		return
+	}
+	threshold := analytics.ImbalanceThresholdFromEnv()
+	c.JSON(http.StatusOK, gin.H{
+		"threshold_pct": threshold,
+		"exercises":     analytics.ComputeImbalance(entries, now, threshold),
+	})
+}
+
+// GetWeekly totals sessions, sets, tonnage and minutes per calendar week, oldest
+// first, for the current week and the ?weeks=12 (up to 52) before it. Weeks
+// without finished sessions are left out.
+func (h *AnalyticsHandler) GetWeekly(c *gin.Context) {
+	weeks, err := strconv.Atoi(c.DefaultQuery("weeks", "12"))
+	if err != nil || weeks < 1 || weeks > 52 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "weeks must be between 1 and 52"})
+		return
+	}
+	summaries, err := h.sessionRepo.GetWeeklySummaries(c.Request.Context(), auth.GetUserID(c), time.Now().Add(-time.Duration(weeks)*analytics.Week))
+	if err != nil {
+		log.Printf("Error fetching weekly summaries: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch weekly summaries"})
+		return
+	}
+	if summaries == nil {
+		summaries = []models.WeeklyLoad{}
+	}
+	c.JSON(http.StatusOK, gin.H{"weeks": summaries})
+}
+
+// GetGymBestTimes suggests the user's least crowded weekday/hour windows at a gym,
+// from the crowd ratings journaled on their sessions. The :id is the gym name as
+// recorded in session metadata. Hours are in ?tz= (IANA name, default UTC); ?limit=3.
+func (h *AnalyticsHandler) GetGymBestTimes(c *gin.Context) {
+	gym := strings.TrimSpace(c.Param("id"))
+	loc, err := time.LoadLocation(c.DefaultQuery("tz", "UTC"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "tz must be an IANA time zone such as Europe/London"})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "3"))
+	if err != nil || limit < 1 || limit > 24*7 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 168"})
+		return
+	}
+	ratings, err := h.sessionRepo.GetCrowdRatings(c.Request.Context(), auth.GetUserID(c), gym)
+	if err != nil {
+		log.Printf("Error computing gym best times: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute best times"})
+		return
+	}
+	slots := analytics.BestTimes(ratings, loc)
+	c.JSON(http.StatusOK, gin.H{
+		"gym":        gym,
+		"timezone":   loc.String(),
+		"ratings":    len(ratings),
+		"best_times": slots[:min(limit, len(slots))],
+	})
+}
+
+// GetAlerts lists the user's undismissed alerts (?all=true includes dismissed ones)
+func (h *AnalyticsHandler) GetAlerts(c *gin.Context) {
+	alerts, err := h.alertRepo.ListAlerts(c.Request.Context(), auth.GetUserID(c), c.Query("all") == "true")
+	if err != nil {
+		log.Printf("Error listing alerts: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch alerts"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"alerts": alerts})
+}
+
+// DismissAlert hides an alert
+func (h *AnalyticsHandler) DismissAlert(c *gin.Context) {
+	err := h.alertRepo.DismissAlert(c.Request.Context(), auth.GetUserID(c), c.Param("id"))
+	if errors.Is(err, repository.ErrAlertNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Alert not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("Error dismissing alert: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to dismiss alert"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Alert dismissed"})
+}
+
+// GetOneRepMax charts an exercise's estimated one-rep max: the best per session,
+// oldest first, and the best overall as current (?formula=epley|brzycki). The
+// :id is the exercise name, matched case-insensitively across all workouts.
+func (h *AnalyticsHandler) GetOneRepMax(c *gin.Context) {
+	name := strings.TrimSpace(c.Param("id"))
+	formula := strings.ToLower(c.DefaultQuery("formula", calc.FormulaEpley))
+	if !slices.Contains(calc.Formulas, formula) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "formula must be epley or brzycki"})
+		return
+	}
+	sets, err := h.sessionRepo.GetExerciseSetHistory(c.Request.Context(), auth.GetUserID(c), name)
+	if err != nil {
+		log.Printf("Error fetching exercise set history: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute one-rep max"})
+		return
+	}
+	history, err := analytics.OneRepMaxHistory(sets, formula)
+	if err != nil {
+		log.Printf("Error estimating one-rep max: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute one-rep max"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"exercise": name,
+		"formula":  formula,
+		"current":  analytics.BestOneRepMax(history),
+		"history":  history,
+	})
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/database"
+	"liftoff/backend/models"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGetWeekly(t *testing.T) {
+	db, err := database.NewMockDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	sqlite := db.GetSQLite()
+	h := NewAnalyticsHandler(repository.NewSessionRepository(nil, sqlite, true), repository.NewAlertRepository(nil, sqlite, true))
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/weekly", auth.AuthMiddleware(), h.GetWeekly)
+	token, _, err := auth.GenerateToken(database.DemoUserID, database.DemoUserEmail, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	get := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/weekly"+query, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := get("?weeks=53"); w.Code != http.StatusBadRequest {
+		t.Errorf("weeks=53: %d", w.Code)
+	}
+	w := get("?weeks=8")
+	if w.Code != http.StatusOK {
+		t.Fatalf("weekly: %d %s", w.Code, w.Body.String())
+	}
+	var body struct {
+		Weeks []models.WeeklyLoad `json:"weeks"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	sessions := 0
+	for i, week := range body.Weeks {
+		if week.WeekStart.Weekday() != time.Monday {
+			t.Errorf("week %d starts on %s", i, week.WeekStart.Weekday())
+		}
+		if i > 0 && !week.WeekStart.After(body.Weeks[i-1].WeekStart) {
+			t.Errorf("weeks out of order: %v", body.Weeks)
+		}
+		if week.Sets == 0 || week.Tonnage <= 0 || week.Minutes <= 0 {
+			t.Errorf("week %d = %+v", i, week)
+		}
+		sessions += week.Sessions
+	}
+	if sessions != 12 {
+		t.Errorf("got %d sessions, want the 12 seeded", sessions)
+	}
+}
+
+func TestGetOneRepMax(t *testing.T) {
+	db, err := database.NewMockDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	sqlite := db.GetSQLite()
+	h := NewAnalyticsHandler(repository.NewSessionRepository(nil, sqlite, true), repository.NewAlertRepository(nil, sqlite, true))
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/exercises/:id/one-rep-max", func(c *gin.Context) { c.Set(auth.UserIDKey, database.DemoUserID) }, h.GetOneRepMax)
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	if w := get("/exercises/Barbell%20Squats/one-rep-max?formula=magic"); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown formula: %d", w.Code)
+	}
+	w := get("/exercises/barbell%20squats/one-rep-max?formula=brzycki")
+	var body struct {
+		Formula string
+		Current *models.OneRepMaxPoint
+		History []models.OneRepMaxPoint
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); w.Code != http.StatusOK || err != nil {
+		t.Fatalf("one-rep max: %d %s", w.Code, w.Body)
+	}
+	if body.Formula != "brzycki" || len(body.History) == 0 || body.Current == nil {
+		t.Fatalf("body = %+v", body)
+	}
+	for i, point := range body.History {
+		if point.E1RM < point.Weight || point.E1RM > body.Current.E1RM {
+			t.Errorf("point %d = %+v, current %+v", i, point, body.Current)
+		}
+		if i > 0 && point.Date.Before(body.History[i-1].Date) {
+			t.Errorf("history out of order at %d", i)
+		}
+	}
+
+	w = get("/exercises/Unknown/one-rep-max")
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Current != nil || len(body.History) != 0 {
+		t.Errorf("no sets: %s", w.Body)
+	}
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxAPIKeysPerUser caps how many personal API keys one account may hold
+const maxAPIKeysPerUser = 20
+
+// APIKeyHandler manages personal API keys
+type APIKeyHandler struct {
+	apiKeyRepo *repository.APIKeyRepository
+}
+
+// NewAPIKeyHandler creates a new API key handler
+func NewAPIKeyHandler(apiKeyRepo *repository.APIKeyRepository) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyRepo: apiKeyRepo}
+}
+
+// CreateAPIKeyRequest names a new key
+type CreateAPIKeyRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// Create issues a new key. The plaintext key is only ever returned here.
+func (h *APIKeyHandler) Create(c *gin.Context) {
+	if !requireTokenAuth(c) {
+		return
+	}
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Name is required"})
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Name must be 1-100 characters"})
+		return
+	}
+
+	userID := auth.GetUserID(c)
+	count, err := h.apiKeyRepo.CountAPIKeys(c.Request.Context(), userID)
+	if err != nil {
+		log.Printf("Error counting api keys: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API key"})
+		return
+	}
+	if count >= maxAPIKeysPerUser {
+		c.JSON(http.StatusConflict, gin.H{"error": "API key limit reached, revoke an unused key first"})
+		return
+	}
+
+	secret, err := repository.GenerateSecureToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API key"})
+		return
+	}
+	plainKey := auth.APIKeyPrefix + secret
+	key, err := h.apiKeyRepo.CreateAPIKey(c.Request.Context(), userID, name, plainKey[:len(auth.APIKeyPrefix)+8], auth.HashToken(plainKey))
+	if err != nil {
+		log.Printf("Error creating api key: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API key"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"api_key": key, "key": plainKey})
+}
+
+// List returns the user's keys without their secrets
+func (h *APIKeyHandler) List(c *gin.Context) {
+	keys, err := h.apiKeyRepo.ListAPIKeys(c.Request.Context(), auth.GetUserID(c))
+	if err != nil {
+		log.Printf("Error listing api keys: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list API keys"})
+		return
+	}
+	c.JSON(http.StatusOK, keys)
+}
+
+// Revoke deletes a key so it can no longer authenticate
+func (h *APIKeyHandler) Revoke(c *gin.Context) {
+	if !requireTokenAuth(c) {
+		return
+	}
+	err := h.apiKeyRepo.DeleteAPIKey(c.Request.Context(), auth.GetUserID(c), c.Param("id"))
+	if errors.Is(err, repository.ErrAPIKeyNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("Error revoking api key: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke API key"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "API key revoked"})
+}
+
+// requireTokenAuth rejects requests authenticated with an API key, so a leaked
+// key cannot be used to mint further keys, and impersonation tokens, so support
+// access cannot outlive the token
+func requireTokenAuth(c *gin.Context) bool {
+	if auth.GetAPIKeyID(c) != "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "API keys cannot manage API keys"})
+		return false
+	}
+	if auth.GetImpersonatorID(c) != "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Impersonation tokens cannot manage API keys"})
+		return false
+	}
+	return true
+}
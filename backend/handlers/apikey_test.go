@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAPIKey_Lifecycle(t *testing.T) {
+	db := newTestDB(t)
+	if _, err := db.Exec(`CREATE TABLE api_keys (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		name TEXT NOT NULL,
+		prefix TEXT NOT NULL,
+		key_hash TEXT NOT NULL UNIQUE,
+		created_at INTEGER NOT NULL,
+		last_used_at INTEGER
+	)`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO users (id, email, password_hash) VALUES ('u1', 'keys@test.com', 'x')`); err != nil {
+		t.Fatal(err)
+	}
+
+	apiKeyRepo := repository.NewAPIKeyRepository(nil, db, true)
+	auth.SetAPIKeyResolver(apiKeyRepo)
+	defer auth.SetAPIKeyResolver(nil)
+
+	gin.SetMode(gin.TestMode)
+	handler := NewAPIKeyHandler(apiKeyRepo)
+	r := gin.New()
+	// Management routes trust a fixed user, as AuthMiddleware would set for a bearer token
+	asUser := func(c *gin.Context) { c.Set(auth.UserIDKey, "u1") }
+	r.POST("/api-keys", asUser, handler.Create)
+	r.DELETE("/api-keys/:id", asUser, handler.Revoke)
+	r.GET("/whoami", auth.AuthMiddleware(), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"user_id": auth.GetUserID(c)})
+	})
+	r.POST("/via-key/api-keys", auth.AuthMiddleware(), handler.Create)
+
+	do := func(method, path, authHeader string, body interface{}) *httptest.ResponseRecorder {
+		raw, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, bytes.NewReader(raw))
+		req.Header.Set("Content-Type", "application/json")
+		if authHeader != "" {
+			req.Header.Set("Authorization", authHeader)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(http.MethodPost, "/api-keys", "", map[string]string{"name": "script"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: got %d %s", w.Code, w.Body.String())
+	}
+	var created struct {
+		Key    string `json:"key"`
+		APIKey struct {
+			ID     string `json:"id"`
+			Prefix string `json:"prefix"`
+		} `json:"api_key"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatal(err)
+	}
+	if created.APIKey.Prefix == "" || created.Key[:len(created.APIKey.Prefix)] != created.APIKey.Prefix {
+		t.Errorf("prefix %q does not start key", created.APIKey.Prefix)
+	}
+
+	if w := do(http.MethodGet, "/whoami", "ApiKey "+created.Key, nil); w.Code != http.StatusOK {
+		t.Errorf("whoami with key: got %d", w.Code)
+	}
+	if w := do(http.MethodPost, "/via-key/api-keys", "ApiKey "+created.Key, map[string]string{"name": "x"}); w.Code != http.StatusForbidden {
+		t.Errorf("create via api key: got %d, want 403", w.Code)
+	}
+
+	if w := do(http.MethodDelete, "/api-keys/"+created.APIKey.ID, "", nil); w.Code != http.StatusOK {
+		t.Fatalf("revoke: got %d", w.Code)
+	}
+	if w := do(http.MethodGet, "/whoami", "ApiKey "+created.Key, nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("revoked key: got %d, want 401", w.Code)
+	}
+	if w := do(http.MethodDelete, "/api-keys/"+created.APIKey.ID, "", nil); w.Code != http.StatusNotFound {
+		t.Errorf("second revoke: got %d, want 404", w.Code)
+	}
+}
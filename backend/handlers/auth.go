@@ -1,13 +1,18 @@
 package handlers
 
 import (
+	"errors"
 	"log"
+	"math"
 	"net/http"
-	"os"
 	"regexp"
+	"strconv"
 	"time"
 
 	"liftoff/backend/auth"
+	"liftoff/backend/auth/captcha"
+	"liftoff/backend/email"
+	"liftoff/backend/models"
 	"liftoff/backend/repository"
 
 	"github.com/gin-gonic/gin"
@@ -15,14 +20,29 @@ import (
 
 var emailRegex = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
 
+// passwordResetTTL bounds how long a password reset link stays valid
+const passwordResetTTL = time.Hour
+
 // AuthHandler handles authentication HTTP requests
 type AuthHandler struct {
-	userRepo *repository.UserRepository
+	userRepo         *repository.UserRepository
+	mailer           email.Sender
+	registrationOpen func() bool
 }
 
-// NewAuthHandler creates a new auth handler
+// NewAuthHandler creates a new auth handler. Emails are logged until SetEmailSender is called.
 func NewAuthHandler(userRepo *repository.UserRepository) *AuthHandler {
-	return &AuthHandler{userRepo: userRepo}
+	return &AuthHandler{userRepo: userRepo, mailer: email.LogSender{}, registrationOpen: func() bool { return true }}
+}
+
+// SetRegistrationCheck sets what decides whether provider sign-ins may create new accounts
+func (h *AuthHandler) SetRegistrationCheck(open func() bool) {
+	h.registrationOpen = open
+}
+
+// SetEmailSender sets how reset, sign-in and welcome emails are delivered
+func (h *AuthHandler) SetEmailSender(sender email.Sender) {
+	h.mailer = sender
 }
 
 // LoginRequest is the request body for login
@@ -34,21 +54,52 @@ type LoginRequest struct {
 
 // RegisterRequest is the request body for registration
 type RegisterRequest struct {
-	Email    string `json:"email" binding:"required"`
-	Password string `json:"password" binding:"required"`
+	Email        string `json:"email" binding:"required"`
+	Password     string `json:"password" binding:"required"`
+	CaptchaToken string `json:"captchaToken"` // required when CAPTCHA_PROVIDER is set
 }
 
 // AuthResponse is the response for auth endpoints
 type AuthResponse struct {
-	Token     string `json:"token"`
+	Token     string `json:"token,omitempty"`     // omitted when AUTH_TRANSPORT=cookie
+	CSRFToken string `json:"csrfToken,omitempty"` // echo in X-CSRF-Token when signed in by cookie
 	ExpiresAt string `json:"expiresAt"`
 	User      struct {
 		ID      string `json:"id"`
 		Email   string `json:"email"`
+		Role    string `json:"role"`
 		IsAdmin bool   `json:"isAdmin"`
 	} `json:"user"`
 }
 
+// newAuthResponse builds the token response shared by every sign-in method
+func newAuthResponse(user *models.User, token string, expiresAt time.Time) AuthResponse {
+	resp := AuthResponse{
+		Token:     token,
+		ExpiresAt: expiresAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	resp.User.ID = user.ID
+	resp.User.Email = user.Email
+	resp.User.Role = auth.EffectiveRole(user.Role, user.Email)
+	resp.User.IsAdmin = resp.User.Role == auth.RoleAdmin
+	return resp
+}
+
+// respondAuth sends a sign-in response. When AUTH_TRANSPORT uses cookies the token is
+// also stored in the session cookie, and in cookie-only mode left out of the body.
+func respondAuth(c *gin.Context, status int, user *models.User, token string, expiresAt time.Time) {
+	if user.Suspended() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This account has been suspended"})
+		return
+	}
+	resp := newAuthResponse(user, token, expiresAt)
+	resp.CSRFToken = auth.SetSessionCookie(c, token, expiresAt)
+	if !auth.GetCookieConfig().ReturnsToken() {
+		resp.Token = ""
+	}
+	c.JSON(status, resp)
+}
+
 // Login handles user login
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req LoginRequest
@@ -63,36 +114,133 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userRepo.GetByEmail(c.Request.Context(), email)
+	ctx := c.Request.Context()
+	lockout := auth.GetLockoutConfig()
+	accountKey := repository.LoginAccountKey(email)
+	ipKey := repository.LoginIPKey(c.ClientIP())
+
+	if until := h.loginLockedUntil(c, ipKey); !until.IsZero() {
+		setRetryAfter(c, until)
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many failed login attempts, try again later"})
+		return
+	}
+	if until := h.loginLockedUntil(c, accountKey); !until.IsZero() {
+		setRetryAfter(c, until)
+		c.JSON(http.StatusLocked, gin.H{"error": "Account temporarily locked after too many failed login attempts"})
+		return
+	}
+
+	user, err := h.userRepo.GetByEmail(ctx, email)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Login failed"})
 		return
 	}
 
 	if user == nil || !auth.CheckPassword(req.Password, user.PasswordHash) {
+		// Unknown emails are tracked too so lockouts do not reveal which accounts exist
+		h.recordLoginFailure(c, lockout, accountKey, lockout.MaxAccountFailures)
+		h.recordLoginFailure(c, lockout, ipKey, lockout.MaxIPFailures)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
 		return
 	}
 
+	if err := h.userRepo.ClearLoginFailures(ctx, accountKey); err != nil {
+		log.Printf("Error clearing login failures: %v", err)
+	}
+	if user.Suspended() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This account has been suspended"})
+		return
+	}
+	h.rehashPassword(c, user, req.Password)
+
+	// A sign-in from an unfamiliar IP and browser pair gets a heads-up email
+	newDevice, err := h.userRepo.RecordLogin(ctx, user.ID, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		log.Printf("Error recording login: %v", err)
+	} else if newDevice {
+		h.sendNewLogin(user.Email, c.Request.UserAgent(), c.ClientIP(), time.Now())
+	}
+
 	tokenString, expiresAt, err := auth.GenerateToken(user.ID, user.Email, req.RememberMe)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
 	}
 
-	c.JSON(http.StatusOK, AuthResponse{
-		Token:     tokenString,
-		ExpiresAt: expiresAt.Format("2006-01-02T15:04:05Z07:00"),
-		User: struct {
-			ID      string `json:"id"`
-			Email   string `json:"email"`
-			IsAdmin bool   `json:"isAdmin"`
-		}{
-			ID:      user.ID,
-			Email:   user.Email,
-			IsAdmin: auth.IsAdminEmail(user.Email),
-		},
-	})
+	respondAuth(c, http.StatusOK, user, tokenString, expiresAt)
+}
+
+// rehashPassword upgrades the stored hash after a successful password check when it
+// uses another algorithm or weaker parameters than configured. Failures are only logged.
+func (h *AuthHandler) rehashPassword(c *gin.Context, user *models.User, password string) {
+	cfg := auth.GetHashConfig()
+	if !cfg.NeedsRehash(user.PasswordHash) {
+		return
+	}
+	hash, err := cfg.Hash(password)
+	if err != nil {
+		log.Printf("Error rehashing password: %v", err)
+		return
+	}
+	if err := h.userRepo.RehashPassword(c.Request.Context(), user.ID, user.PasswordHash, hash); err != nil {
+		log.Printf("Error rehashing password: %v", err)
+		return
+	}
+	user.PasswordHash = hash
+}
+
+// loginLockedUntil returns when a login lock expires, or the zero time if unlocked.
+// Lookup errors are logged and treated as unlocked so a tracking outage cannot block every login.
+func (h *AuthHandler) loginLockedUntil(c *gin.Context, key string) time.Time {
+	until, err := h.userRepo.GetLoginLockout(c.Request.Context(), key)
+	if err != nil {
+		log.Printf("Error checking login lockout: %v", err)
+		return time.Time{}
+	}
+	return until
+}
+
+// recordLoginFailure counts a failure and locks key with exponential backoff once it reaches threshold
+func (h *AuthHandler) recordLoginFailure(c *gin.Context, cfg auth.LockoutConfig, key string, threshold int) {
+	if threshold <= 0 {
+		return
+	}
+	failures, err := h.userRepo.RecordLoginFailure(c.Request.Context(), key, cfg.FailureWindow)
+	if err != nil {
+		log.Printf("Error recording login failure: %v", err)
+		return
+	}
+	if d := cfg.LockoutDuration(failures, threshold); d > 0 {
+		log.Printf("Locking login for %s for %v after %d failures", key, d, failures)
+		if err := h.userRepo.LockLogin(c.Request.Context(), key, time.Now().Add(d)); err != nil {
+			log.Printf("Error locking login: %v", err)
+		}
+	}
+}
+
+// setRetryAfter tells the client how many seconds to wait before retrying
+func setRetryAfter(c *gin.Context, until time.Time) {
+	secs := int(math.Ceil(time.Until(until).Seconds()))
+	if secs < 1 {
+		secs = 1
+	}
+	c.Header("Retry-After", strconv.Itoa(secs))
+}
+
+// verifyCaptcha checks the request's captcha token when enforcement is on, writing the
+// error response and returning false when it does not pass
+func verifyCaptcha(c *gin.Context, token string) bool {
+	err := captcha.Verify(c.Request.Context(), token, c.ClientIP())
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, captcha.ErrMissing), errors.Is(err, captcha.ErrFailed):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		log.Printf("Captcha verification error: %v", err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Captcha verification is unavailable, try again shortly"})
+	}
+	return false
 }
 
 // Register handles user registration
@@ -109,7 +257,11 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	if err := auth.ValidatePassword(req.Password); err != nil {
+	if !verifyCaptcha(c, req.CaptchaToken) {
+		return
+	}
+
+	if err := auth.ValidatePasswordStrong(c.Request.Context(), req.Password); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -140,6 +292,8 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
+	h.sendWelcome(user.Email)
+
 	// Generate short-lived token for new registration (no remember me on signup)
 	tokenString, expiresAt, err := auth.GenerateToken(user.ID, user.Email, false)
 	if err != nil {
@@ -147,24 +301,13 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, AuthResponse{
-		Token:     tokenString,
-		ExpiresAt: expiresAt.Format("2006-01-02T15:04:05Z07:00"),
-		User: struct {
-			ID      string `json:"id"`
-			Email   string `json:"email"`
-			IsAdmin bool   `json:"isAdmin"`
-		}{
-			ID:      user.ID,
-			Email:   user.Email,
-			IsAdmin: auth.IsAdminEmail(user.Email),
-		},
-	})
+	respondAuth(c, http.StatusCreated, user, tokenString, expiresAt)
 }
 
 // ForgotPasswordRequest is the request body for forgot password
 type ForgotPasswordRequest struct {
-	Email string `json:"email" binding:"required"`
+	Email        string `json:"email" binding:"required"`
+	CaptchaToken string `json:"captchaToken"` // required when CAPTCHA_PROVIDER is set
 }
 
 // ResetPasswordRequest is the request body for reset password
@@ -187,6 +330,23 @@ func (h *AuthHandler) ForgotPassword(c *gin.Context) {
 		return
 	}
 
+	// Checked before throttling so bots cannot use up a real user's reset allowance
+	if !verifyCaptcha(c, req.CaptchaToken) {
+		return
+	}
+
+	// Throttled whether or not the account exists, so a 429 reveals nothing
+	accountKey := repository.ResetAccountKey(email)
+	ipKey := repository.ResetIPKey(c.ClientIP())
+	for _, key := range []string{ipKey, accountKey} {
+		if until := h.loginLockedUntil(c, key); !until.IsZero() {
+			setRetryAfter(c, until)
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many reset requests, try again later"})
+			return
+		}
+	}
+	h.recordResetRequest(c, auth.GetResetThrottleConfig(), accountKey, ipKey)
+
 	user, err := h.userRepo.GetByEmail(c.Request.Context(), email)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "If an account exists, a reset link has been sent"})
@@ -205,30 +365,45 @@ func (h *AuthHandler) ForgotPassword(c *gin.Context) {
 	}
 
 	tokenHash := auth.HashToken(plainToken)
-	expiresAt := time.Now().Add(1 * time.Hour)
+	expiresAt := time.Now().Add(passwordResetTTL)
 	err = h.userRepo.CreatePasswordResetToken(c.Request.Context(), user.ID, tokenHash, expiresAt)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create reset token"})
 		return
 	}
 
-	frontendURL := os.Getenv("FRONTEND_URL")
-	if frontendURL == "" {
-		frontendURL = "http://localhost:5173"
-	}
-	resetLink := frontendURL + "/reset-password?token=" + plainToken
-
-	// In production, send email. For dev, log the link.
-	if os.Getenv("SMTP_HOST") != "" {
-		// TODO: Integrate with email service (SMTP, SendGrid, etc.)
-		log.Printf("Password reset for %s: %s", email, resetLink)
-	} else {
-		log.Printf("Password reset link for %s (dev mode): %s", email, resetLink)
-	}
+	resetLink := frontendURL() + "/reset-password?token=" + plainToken
+	h.sendPasswordReset(user.Email, resetLink)
 
 	c.JSON(http.StatusOK, gin.H{"message": "If an account exists, a reset link has been sent"})
 }
 
+// recordResetRequest starts the cooldown for the address and counts the request
+// against the IP, locking the IP for the rest of its window once it hits the limit.
+// Errors are logged only, like login tracking.
+func (h *AuthHandler) recordResetRequest(c *gin.Context, cfg auth.ResetThrottleConfig, accountKey, ipKey string) {
+	ctx := c.Request.Context()
+	if _, err := h.userRepo.RecordLoginFailure(ctx, accountKey, cfg.EmailCooldown); err != nil {
+		log.Printf("Error recording reset request: %v", err)
+	} else if err := h.userRepo.LockLogin(ctx, accountKey, time.Now().Add(cfg.EmailCooldown)); err != nil {
+		log.Printf("Error starting reset cooldown: %v", err)
+	}
+	if cfg.MaxIPRequests <= 0 {
+		return
+	}
+	requests, err := h.userRepo.RecordLoginFailure(ctx, ipKey, cfg.IPWindow)
+	if err != nil {
+		log.Printf("Error recording reset request: %v", err)
+		return
+	}
+	if requests >= cfg.MaxIPRequests {
+		log.Printf("Throttling password resets for %s after %d requests", ipKey, requests)
+		if err := h.userRepo.LockLogin(ctx, ipKey, time.Now().Add(cfg.IPWindow)); err != nil {
+			log.Printf("Error throttling password resets: %v", err)
+		}
+	}
+}
+
 // ResetPassword completes password reset with token
 func (h *AuthHandler) ResetPassword(c *gin.Context) {
 	var req ResetPasswordRequest
@@ -237,7 +412,7 @@ func (h *AuthHandler) ResetPassword(c *gin.Context) {
 		return
 	}
 
-	if err := auth.ValidatePassword(req.NewPassword); err != nil {
+	if err := auth.ValidatePasswordStrong(c.Request.Context(), req.NewPassword); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -249,18 +424,40 @@ func (h *AuthHandler) ResetPassword(c *gin.Context) {
 		return
 	}
 
+	// The new password may not repeat the current one or other recent ones
+	recent, err := h.userRepo.RecentPasswordHashes(c.Request.Context(), userID, auth.PasswordHistoryDepth())
+	if err != nil {
+		log.Printf("Error reading password history: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+	if auth.PasswordReused(req.NewPassword, recent) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": auth.ErrPasswordReused.Error()})
+		return
+	}
+
 	passwordHash, err := auth.HashPassword(req.NewPassword)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
 		return
 	}
 
-	if err := h.userRepo.UpdatePassword(c.Request.Context(), userID, passwordHash); err != nil {
+	// Claim the token before changing anything so it cannot be redeemed twice
+	used, err := h.userRepo.UsePasswordResetToken(c.Request.Context(), tokenHash)
+	if err != nil {
+		log.Printf("Error using reset token: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
 		return
 	}
+	if !used {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset token"})
+		return
+	}
 
-	_ = h.userRepo.DeletePasswordResetToken(c.Request.Context(), tokenHash)
+	if err := h.userRepo.UpdatePassword(c.Request.Context(), userID, passwordHash); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Password has been reset successfully"})
 }
@@ -279,11 +476,22 @@ func (h *AuthHandler) Me(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	role := auth.EffectiveRole(user.Role, user.Email)
+	resp := gin.H{
 		"user": gin.H{
 			"id":      user.ID,
 			"email":   user.Email,
-			"isAdmin": auth.IsAdminEmail(user.Email),
+			"role":    role,
+			"isAdmin": role == auth.RoleAdmin,
 		},
-	})
+	}
+	// Lets the client show that support is signed in as this user
+	if admin := auth.GetImpersonatorID(c); admin != "" {
+		resp["impersonatedBy"] = admin
+	}
+	// Lets a cookie-authenticated client recover its CSRF token after a reload
+	if csrf := auth.GetCSRFToken(c); csrf != "" {
+		resp["csrfToken"] = csrf
+	}
+	c.JSON(http.StatusOK, resp)
 }
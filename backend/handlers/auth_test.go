@@ -2,12 +2,21 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"liftoff/backend/auth"
+	"liftoff/backend/auth/captcha"
+	"liftoff/backend/database"
+	"liftoff/backend/email"
+	"liftoff/backend/ipallow"
 	"liftoff/backend/repository"
 
 	"github.com/gin-gonic/gin"
@@ -148,3 +157,534 @@ func TestHashToken(t *testing.T) {
 		t.Errorf("SHA256 hash should be 64 hex chars, got %d", len(hash1))
 	}
 }
+
+func TestMagicLogin_SingleUse(t *testing.T) {
+	db := newTestDB(t)
+	if _, err := db.Exec(`CREATE TABLE password_reset_tokens (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		purpose TEXT NOT NULL DEFAULT 'password_reset',
+		token_hash TEXT NOT NULL,
+		expires_at DATETIME NOT NULL,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		used_at DATETIME
+	)`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO users (id, email, password_hash) VALUES ('u1', 'magic@test.com', 'x')`); err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	userRepo := repository.NewUserRepository(nil, db, true)
+	handler := NewAuthHandler(userRepo)
+	r := gin.New()
+	r.POST("/magic-login", handler.MagicLogin)
+
+	ctx := context.Background()
+	expires := time.Now().Add(time.Hour)
+	_ = userRepo.CreateOneTimeToken(ctx, "u1", repository.TokenPurposeMagicLink, auth.HashToken("magic"), expires)
+	_ = userRepo.CreatePasswordResetToken(ctx, "u1", auth.HashToken("reset"), expires)
+	_ = userRepo.CreateOneTimeToken(ctx, "u1", repository.TokenPurposeMagicLink, auth.HashToken("stale"), time.Now().Add(-time.Minute))
+
+	tests := []struct {
+		name       string
+		token      string
+		wantStatus int
+	}{
+		{"valid link", "magic", http.StatusOK},
+		{"reused link", "magic", http.StatusUnauthorized},
+		{"reset token", "reset", http.StatusUnauthorized},
+		{"expired link", "stale", http.StatusUnauthorized},
+		{"unknown", "nope", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		body, _ := json.Marshal(map[string]string{"token": tt.token})
+		req := httptest.NewRequest(http.MethodPost, "/magic-login", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: got status %d, want %d", tt.name, w.Code, tt.wantStatus)
+		}
+	}
+}
+
+func TestLogin_Lockout(t *testing.T) {
+	os.Setenv("LOGIN_MAX_FAILURES", "3")
+	os.Setenv("LOGIN_IP_MAX_FAILURES", "5")
+	defer os.Unsetenv("LOGIN_MAX_FAILURES")
+	defer os.Unsetenv("LOGIN_IP_MAX_FAILURES")
+
+	db := newTestDB(t)
+	if _, err := db.Exec(`CREATE TABLE login_attempts (
+		attempt_key TEXT PRIMARY KEY,
+		failures INTEGER NOT NULL DEFAULT 0,
+		last_failure INTEGER NOT NULL,
+		locked_until INTEGER NOT NULL DEFAULT 0
+	)`); err != nil {
+		t.Fatal(err)
+	}
+	hash, _ := auth.HashPassword("Passw0rd!")
+	if _, err := db.Exec(`INSERT INTO users (id, email, password_hash) VALUES ('u1', 'lock@test.com', ?)`, hash); err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/login", NewAuthHandler(repository.NewUserRepository(nil, db, true)).Login)
+	login := func(email, password string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]string{"email": email, "password": password})
+		req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	for i := 0; i < 3; i++ {
+		if w := login("lock@test.com", "wrong"); w.Code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d: got %d, want 401", i+1, w.Code)
+		}
+	}
+	w := login("lock@test.com", "Passw0rd!")
+	if w.Code != http.StatusLocked {
+		t.Fatalf("locked account: got %d, want 423", w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("expected Retry-After header")
+	}
+
+	// Two more failures on another account push the IP over its threshold
+	login("other@test.com", "wrong")
+	login("other@test.com", "wrong")
+	if w := login("someone@test.com", "wrong"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("throttled IP: got %d, want 429", w.Code)
+	}
+}
+
+func TestLogin_LockoutIgnoresForwardedFor(t *testing.T) {
+	os.Setenv("LOGIN_IP_MAX_FAILURES", "3")
+	defer os.Unsetenv("LOGIN_IP_MAX_FAILURES")
+	os.Unsetenv("TRUSTED_PROXIES")
+
+	db := newTestDB(t)
+	if _, err := db.Exec(`CREATE TABLE login_attempts (
+		attempt_key TEXT PRIMARY KEY,
+		failures INTEGER NOT NULL DEFAULT 0,
+		last_failure INTEGER NOT NULL,
+		locked_until INTEGER NOT NULL DEFAULT 0
+	)`); err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	if err := ipallow.TrustProxies(r); err != nil {
+		t.Fatal(err)
+	}
+	r.POST("/login", NewAuthHandler(repository.NewUserRepository(nil, db, true)).Login)
+
+	// A new forged address on every attempt still counts against the one peer
+	for i := 0; i < 4; i++ {
+		body, _ := json.Marshal(map[string]string{"email": fmt.Sprintf("user%d@test.com", i), "password": "wrong"})
+		req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Forwarded-For", fmt.Sprintf("198.51.100.%d", i+1))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		want := http.StatusUnauthorized
+		if i == 3 {
+			want = http.StatusTooManyRequests
+		}
+		if w.Code != want {
+			t.Errorf("attempt %d: got %d, want %d", i+1, w.Code, want)
+		}
+	}
+}
+
+// captureSender records sent emails for assertions
+type captureSender chan email.Message
+
+func (s captureSender) Send(ctx context.Context, msg email.Message) error {
+	s <- msg
+	return nil
+}
+
+func TestForgotPassword_SendsEmail(t *testing.T) {
+	db, err := database.NewMockDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	gin.SetMode(gin.TestMode)
+	handler := NewAuthHandler(repository.NewUserRepository(nil, db.GetSQLite(), true))
+	sent := make(captureSender, 1)
+	handler.SetEmailSender(sent)
+	r := gin.New()
+	r.POST("/forgot", handler.ForgotPassword)
+
+	for _, addr := range []string{"nobody@example.com", database.DemoUserEmail} {
+		body, _ := json.Marshal(map[string]string{"email": addr})
+		req := httptest.NewRequest(http.MethodPost, "/forgot", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: got status %d", addr, w.Code)
+		}
+	}
+
+	select {
+	case msg := <-sent:
+		if msg.To != database.DemoUserEmail || !strings.Contains(msg.Text, "/reset-password?token=") || !strings.Contains(msg.HTML, "/reset-password?token=") {
+			t.Errorf("reset email = %+v", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no reset email sent")
+	}
+	select {
+	case msg := <-sent:
+		t.Errorf("unexpected email to %s", msg.To)
+	default:
+	}
+}
+
+func TestLogin_NewDeviceEmail(t *testing.T) {
+	db, err := database.NewMockDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	gin.SetMode(gin.TestMode)
+	handler := NewAuthHandler(repository.NewUserRepository(nil, db.GetSQLite(), true))
+	sent := make(captureSender, 2)
+	handler.SetEmailSender(sent)
+	r := gin.New()
+	r.POST("/login", handler.Login)
+	login := func(userAgent string) {
+		body, _ := json.Marshal(map[string]string{"email": database.DemoUserEmail, "password": database.DemoUserPassword})
+		req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", userAgent)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("login: got %d %s", w.Code, w.Body)
+		}
+	}
+
+	// The first recorded sign-in and repeats from the same device send nothing
+	login("Laptop Browser")
+	login("Laptop Browser")
+	login("Phone Browser")
+
+	select {
+	case msg := <-sent:
+		if msg.To != database.DemoUserEmail || msg.Subject != "New login to your Liftoff account" || !strings.Contains(msg.Text, "Phone Browser") {
+			t.Errorf("new login email = %+v", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no new login email sent")
+	}
+	select {
+	case msg := <-sent:
+		t.Errorf("unexpected email %q", msg.Subject)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestLogin_RehashesPassword(t *testing.T) {
+	t.Setenv("PASSWORD_HASH", auth.HashArgon2id)
+	t.Setenv("ARGON2_MEMORY_KIB", "1024")
+	db, err := database.NewMockDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	gin.SetMode(gin.TestMode)
+	userRepo := repository.NewUserRepository(nil, db.GetSQLite(), true)
+	r := gin.New()
+	r.POST("/login", NewAuthHandler(userRepo).Login)
+	login := func() {
+		body, _ := json.Marshal(map[string]string{"email": database.DemoUserEmail, "password": database.DemoUserPassword})
+		req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("login: got %d %s", w.Code, w.Body)
+		}
+	}
+
+	// The demo user was seeded before the switch, so its hash is upgraded on sign-in
+	hash, err := auth.HashConfig{Algorithm: auth.HashBcrypt, BcryptCost: 4}.Hash(database.DemoUserPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.GetSQLite().Exec(`UPDATE users SET password_hash = ? WHERE id = ?`, hash, database.DemoUserID); err != nil {
+		t.Fatal(err)
+	}
+	storedHash := func() string {
+		var h string
+		if err := db.GetSQLite().QueryRow(`SELECT password_hash FROM users WHERE id = ?`, database.DemoUserID).Scan(&h); err != nil {
+			t.Fatal(err)
+		}
+		return h
+	}
+	login()
+	upgraded := storedHash()
+	if !strings.HasPrefix(upgraded, "$argon2id$v=19$m=1024,") {
+		t.Fatalf("hash after login = %q", upgraded)
+	}
+	recent, err := userRepo.RecentPasswordHashes(t.Context(), database.DemoUserID, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recent) != 1 {
+		t.Errorf("rehash recorded password history: %d hashes", len(recent))
+	}
+
+	// The upgraded hash still signs in and is left alone
+	login()
+	if storedHash() != upgraded {
+		t.Error("hash changed on second login")
+	}
+}
+
+func TestResetPassword_RejectsReuse(t *testing.T) {
+	t.Setenv("PASSWORD_HISTORY", "2")
+	db, err := database.NewMockDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	sqlite := db.GetSQLite()
+	userRepo := repository.NewUserRepository(nil, sqlite, true)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/reset", NewAuthHandler(userRepo).ResetPassword)
+	reset := func(password string) int {
+		token, err := repository.GenerateSecureToken()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := userRepo.CreatePasswordResetToken(t.Context(), database.DemoUserID, auth.HashToken(token), time.Now().Add(time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+		body, _ := json.Marshal(map[string]string{"token": token, "newPassword": password})
+		req := httptest.NewRequest(http.MethodPost, "/reset", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		// Keep history entries from sharing a timestamp
+		if _, err := sqlite.Exec(`UPDATE password_history SET created_at = created_at - 10`); err != nil {
+			t.Fatal(err)
+		}
+		return w.Code
+	}
+
+	steps := []struct {
+		password string
+		want     int
+	}{
+		{database.DemoUserPassword, http.StatusBadRequest}, // the current password
+		{"Second2!pass", http.StatusOK},
+		{database.DemoUserPassword, http.StatusBadRequest}, // the previous one
+		{"Third3!pass", http.StatusOK},
+		{database.DemoUserPassword, http.StatusOK}, // now three passwords back
+	}
+	for i, step := range steps {
+		if got := reset(step.password); got != step.want {
+			t.Fatalf("step %d (%s): got %d, want %d", i, step.password, got, step.want)
+		}
+	}
+
+	t.Setenv("PASSWORD_HISTORY", "0")
+	if got := reset(database.DemoUserPassword); got != http.StatusOK {
+		t.Errorf("history disabled: got %d", got)
+	}
+}
+
+func TestForgotPassword_Throttled(t *testing.T) {
+	t.Setenv("PASSWORD_RESET_IP_MAX", "3")
+	db, err := database.NewMockDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	gin.SetMode(gin.TestMode)
+	handler := NewAuthHandler(repository.NewUserRepository(nil, db.GetSQLite(), true))
+	sent := make(captureSender, 10)
+	handler.SetEmailSender(sent)
+	r := gin.New()
+	r.POST("/forgot", handler.ForgotPassword)
+	forgot := func(addr string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]string{"email": addr})
+		req := httptest.NewRequest(http.MethodPost, "/forgot", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	steps := []struct {
+		email string
+		want  int
+	}{
+		{database.DemoUserEmail, http.StatusOK},
+		{database.DemoUserEmail, http.StatusTooManyRequests}, // address cooldown
+		{"nobody@example.com", http.StatusOK},
+		{"nobody@example.com", http.StatusTooManyRequests}, // same for unknown addresses
+		{"other@example.com", http.StatusOK},               // third request from this IP
+		{"fourth@example.com", http.StatusTooManyRequests}, // IP limit
+	}
+	for i, step := range steps {
+		w := forgot(step.email)
+		if w.Code != step.want {
+			t.Fatalf("step %d (%s): got %d, want %d", i, step.email, w.Code, step.want)
+		}
+		if w.Code == http.StatusTooManyRequests && w.Header().Get("Retry-After") == "" {
+			t.Errorf("step %d: missing Retry-After", i)
+		}
+	}
+}
+
+func TestResetPassword_SingleUseToken(t *testing.T) {
+	db, err := database.NewMockDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	userRepo := repository.NewUserRepository(nil, db.GetSQLite(), true)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/reset", NewAuthHandler(userRepo).ResetPassword)
+	reset := func(token, password string) int {
+		body, _ := json.Marshal(map[string]string{"token": token, "newPassword": password})
+		req := httptest.NewRequest(http.MethodPost, "/reset", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	expires := time.Now().Add(time.Hour)
+	if err := userRepo.CreatePasswordResetToken(t.Context(), database.DemoUserID, auth.HashToken("first"), expires); err != nil {
+		t.Fatal(err)
+	}
+	if err := userRepo.CreatePasswordResetToken(t.Context(), database.DemoUserID, auth.HashToken("second"), expires); err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []struct {
+		name, token, password string
+		want                  int
+	}{
+		{"superseded token", "first", "Second2!pass", http.StatusBadRequest},
+		{"latest token", "second", "Second2!pass", http.StatusOK},
+		{"redeemed again", "second", "Third3!pass", http.StatusBadRequest},
+	}
+	for _, step := range steps {
+		if got := reset(step.token, step.password); got != step.want {
+			t.Errorf("%s: got %d, want %d", step.name, got, step.want)
+		}
+	}
+}
+
+func TestCaptchaRequired(t *testing.T) {
+	t.Setenv("PASSWORD_RESET_IP_MAX", "1")
+	db, err := database.NewMockDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	gin.SetMode(gin.TestMode)
+	handler := NewAuthHandler(repository.NewUserRepository(nil, db.GetSQLite(), true))
+	handler.SetEmailSender(make(captureSender, 10))
+	r := gin.New()
+	r.POST("/register", handler.Register)
+	r.POST("/forgot", handler.ForgotPassword)
+	post := func(path string, body map[string]string) *httptest.ResponseRecorder {
+		b, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(b))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	verifier, err := captcha.NewVerifier(captcha.ProviderHCaptcha, "secret", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	captcha.SetVerifier(verifier)
+	defer captcha.SetVerifier(nil)
+	if w := post("/register", map[string]string{"email": "new@example.com", "password": "Str0ng!Passw0rd"}); w.Code != http.StatusBadRequest {
+		t.Errorf("register without token: got %d", w.Code)
+	}
+	if w := post("/forgot", map[string]string{"email": database.DemoUserEmail}); w.Code != http.StatusBadRequest {
+		t.Errorf("forgot-password without token: got %d", w.Code)
+	}
+
+	// Rejected requests do not count towards the reset throttle, and nothing is enforced once disabled
+	captcha.SetVerifier(nil)
+	if w := post("/forgot", map[string]string{"email": database.DemoUserEmail}); w.Code != http.StatusOK {
+		t.Errorf("forgot-password with captcha off: got %d", w.Code)
+	}
+	if w := post("/register", map[string]string{"email": "new@example.com", "password": "Str0ng!Passw0rd"}); w.Code != http.StatusCreated {
+		t.Errorf("register with captcha off: got %d, body %s", w.Code, w.Body.String())
+	}
+}
+
+func TestLogin_CookieTransport(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	t.Setenv("AUTH_TRANSPORT", auth.TransportCookie)
+	db, err := database.NewMockDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	gin.SetMode(gin.TestMode)
+	handler := NewAuthHandler(repository.NewUserRepository(nil, db.GetSQLite(), true))
+	r := gin.New()
+	r.POST("/login", handler.Login)
+	r.GET("/me", auth.AuthMiddleware(), handler.Me)
+
+	body, _ := json.Marshal(map[string]string{"email": database.DemoUserEmail, "password": database.DemoUserPassword})
+	req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("login: got %d, body %s", w.Code, w.Body.String())
+	}
+	var resp AuthResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Token != "" {
+		t.Error("cookie transport must not return the token in the body")
+	}
+	if resp.CSRFToken == "" {
+		t.Error("expected a CSRF token in the login response")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/me", nil)
+	for _, c := range w.Result().Cookies() {
+		req.AddCookie(c)
+	}
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), resp.CSRFToken) {
+		t.Errorf("me with session cookie: got %d, body %s", w.Code, w.Body.String())
+	}
+}
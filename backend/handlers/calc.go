@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"liftoff/backend/calc"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CalcHandler exposes the strength calculators in the calc package
+type CalcHandler struct{}
+
+// NewCalcHandler creates a new calculator handler
+func NewCalcHandler() *CalcHandler {
+	return &CalcHandler{}
+}
+
+// OneRepMax estimates a one-rep max: ?weight=100&reps=5[&formula=epley|brzycki]
+func (h *CalcHandler) OneRepMax(c *gin.Context) {
+	weight, ok := floatQuery(c, "weight", 0)
+	if !ok {
+		return
+	}
+	reps, err := strconv.Atoi(c.Query("reps"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": calc.ErrInvalidReps.Error()})
+		return
+	}
+	formula := c.DefaultQuery("formula", calc.FormulaEpley)
+
+	e1rm, err := calc.OneRepMax(weight, reps, formula)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	estimates := gin.H{}
+	for _, f := range calc.Formulas {
+		v, _ := calc.OneRepMax(weight, reps, f)
+		estimates[f] = round2(v)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"weight":    weight,
+		"reps":      reps,
+		"formula":   formula,
+		"e1rm":      round2(e1rm),
+		"estimates": estimates,
+	})
+}
+
+// PercentTable lists working weights from 50-100% of a max: ?max=140[&increment=2.5]
+func (h *CalcHandler) PercentTable(c *gin.Context) {
+	max, ok := floatQuery(c, "max", 0)
+	if !ok {
+		return
+	}
+	increment, ok := floatQuery(c, "increment", calc.DefaultIncrement)
+	if !ok {
+		return
+	}
+	rows, err := calc.PercentTable(max, increment)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"max": max, "increment": increment, "rows": rows})
+}
+
+// Warmups builds a warmup ramp to a working weight: ?weight=140[&bar=20&increment=2.5]
+func (h *CalcHandler) Warmups(c *gin.Context) {
+	weight, ok := floatQuery(c, "weight", 0)
+	if !ok {
+		return
+	}
+	bar, ok := floatQuery(c, "bar", 20)
+	if !ok {
+		return
+	}
+	increment, ok := floatQuery(c, "increment", calc.DefaultIncrement)
+	if !ok {
+		return
+	}
+	sets, err := calc.Warmups(weight, bar, increment)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"weight": weight, "bar": bar, "sets": sets})
+}
+
+// floatQuery parses a non-negative float query parameter, writing a 400 on failure
+func floatQuery(c *gin.Context, key string, def float64) (float64, bool) {
+	raw := c.Query(key)
+	if raw == "" {
+		return def, true
+	}
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil || v < 0 || math.IsInf(v, 0) || math.IsNaN(v) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": key + " must be a non-negative number"})
+		return 0, false
+	}
+	return v, true
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"net/http"
+
+	"liftoff/backend/changelog"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ChangelogHandler serves the embedded release notes
+type ChangelogHandler struct{}
+
+// NewChangelogHandler creates a new changelog handler
+func NewChangelogHandler() *ChangelogHandler {
+	return &ChangelogHandler{}
+}
+
+// GetChangelog lists releases newest first, with the server's version. With
+// ?since=1.2.0 only later releases are returned, for "what's new" dialogs.
+func (h *ChangelogHandler) GetChangelog(c *gin.Context) {
+	releases, err := changelog.Since(c.Query("since"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"version": changelog.Version(), "releases": releases})
+}
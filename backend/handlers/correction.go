@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/models"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CorrectionHandler lets users fix history logged at the wrong time, and lists the
+// audit entries such fixes leave behind
+type CorrectionHandler struct {
+	sessionRepo        *repository.SessionRepository
+	auditRepo          *repository.AuditRepository
+	recommendationRepo *repository.RecommendationRepository
+	analytics          *AnalyticsHandler
+}
+
+// NewCorrectionHandler creates a new correction handler
+func NewCorrectionHandler(sessionRepo *repository.SessionRepository, auditRepo *repository.AuditRepository, recommendationRepo *repository.RecommendationRepository, analytics *AnalyticsHandler) *CorrectionHandler {
+	return &CorrectionHandler{sessionRepo: sessionRepo, auditRepo: auditRepo, recommendationRepo: recommendationRepo, analytics: analytics}
+}
+
+// RetimeSessions shifts the user's completed sessions by an hour offset or moves them
+// to another calendar date, then recomputes the data derived from their dates
+func (h *CorrectionHandler) RetimeSessions(c *gin.Context) {
+	var req models.SessionTimeCorrection
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	shift, err := req.Validate()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID := auth.GetUserID(c)
+	actorID := auth.GetImpersonatorID(c)
+	if actorID == "" {
+		actorID = userID
+	}
+	sessions, entry, err := h.sessionRepo.RetimeSessions(ctx, userID, actorID, req, shift)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNoSessionsMatched):
+			c.JSON(http.StatusNotFound, gin.H{"error": "No completed sessions match"})
+		case errors.Is(err, repository.ErrTooManySessions):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			log.Printf("Error retiming sessions: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to correct sessions"})
+		}
+		return
+	}
+
+	if _, err := h.recommendationRepo.RefreshUser(ctx, userID); err != nil {
+		log.Printf("Error refreshing recommendations after retime: %v", err)
+	}
+	if err := h.analytics.CheckLoadRamp(ctx, userID); err != nil {
+		log.Printf("Error checking training load after retime: %v", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sessions": sessions, "audit": entry})
+}
+
+// GetAuditLog lists changes made to the user's history, newest first (?limit=50).
+// A full page sets X-Next-Cursor to the last entry's ID; pass it as ?before= for the next page.
+func (h *CorrectionHandler) GetAuditLog(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 || limit > 500 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 500"})
+		return
+	}
+	entries, err := h.auditRepo.ListForUser(c.Request.Context(), auth.GetUserID(c), c.Query("before"), limit)
+	if err != nil {
+		log.Printf("Error listing audit log: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list audit log"})
+		return
+	}
+	if len(entries) == limit {
+		c.Header("X-Next-Cursor", entries[len(entries)-1].ID)
+	}
+	c.JSON(http.StatusOK, entries)
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/database"
+	"liftoff/backend/models"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRetimeSessions(t *testing.T) {
+	db, err := database.NewMockDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	sqlite := db.GetSQLite()
+
+	var sessionID, setID string
+	var startedAt, setCreatedAt time.Time
+	err = sqlite.QueryRow(`SELECT ws.id, ws.started_at, es.id, es.created_at FROM workout_sessions ws
+		JOIN session_exercises se ON se.session_id = ws.id JOIN exercise_sets es ON es.session_exercise_id = se.id
+		WHERE ws.user_id = ? AND ws.is_active = 0 AND ws.ended_at IS NOT NULL LIMIT 1`, database.DemoUserID).
+		Scan(&sessionID, &startedAt, &setID, &setCreatedAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sessionRepo := repository.NewSessionRepository(nil, sqlite, true)
+	workoutRepo := repository.NewWorkoutRepository(nil, sqlite, true)
+	taxonomyRepo := repository.NewTaxonomyRepository(nil, sqlite, true)
+	h := NewCorrectionHandler(sessionRepo, repository.NewAuditRepository(nil, sqlite, true),
+		repository.NewRecommendationRepository(nil, sqlite, true, workoutRepo, taxonomyRepo),
+		NewAnalyticsHandler(sessionRepo, repository.NewAlertRepository(nil, sqlite, true)))
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	setUser := func(c *gin.Context) { c.Set(auth.UserIDKey, database.DemoUserID) }
+	r.POST("/sessions/retime", setUser, h.RetimeSessions)
+	r.GET("/audit", setUser, h.GetAuditLog)
+	retime := func(body interface{}) *httptest.ResponseRecorder {
+		raw, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPost, "/sessions/retime", bytes.NewReader(raw))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := retime(map[string]interface{}{"session_ids": []string{sessionID}}); w.Code != http.StatusBadRequest {
+		t.Errorf("no change: got %d", w.Code)
+	}
+	if w := retime(map[string]interface{}{"session_ids": []string{"missing"}, "offset_hours": 3}); w.Code != http.StatusNotFound {
+		t.Errorf("unknown session: got %d", w.Code)
+	}
+
+	w := retime(map[string]interface{}{"session_ids": []string{sessionID}, "offset_hours": -5})
+	if w.Code != http.StatusOK {
+		t.Fatalf("retime: got %d %s", w.Code, w.Body)
+	}
+	var resp struct {
+		Sessions []models.RetimedSession `json:"sessions"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Sessions) != 1 || !resp.Sessions[0].NewStartedAt.Equal(startedAt.Add(-5*time.Hour)) {
+		t.Errorf("sessions = %+v", resp.Sessions)
+	}
+
+	var newStarted, newSetCreated time.Time
+	if err := sqlite.QueryRow(`SELECT started_at FROM workout_sessions WHERE id = ?`, sessionID).Scan(&newStarted); err != nil {
+		t.Fatal(err)
+	}
+	if err := sqlite.QueryRow(`SELECT created_at FROM exercise_sets WHERE id = ?`, setID).Scan(&newSetCreated); err != nil {
+		t.Fatal(err)
+	}
+	if !newStarted.Equal(startedAt.Add(-5*time.Hour)) || !newSetCreated.Equal(setCreatedAt.Add(-5*time.Hour)) {
+		t.Errorf("stored times = %v, %v", newStarted, newSetCreated)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/audit", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	var entries []models.AuditEntry
+	if err := json.Unmarshal(w.Body.Bytes(), &entries); err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Action != models.AuditActionSessionsRetimed || entries[0].ActorID != database.DemoUserID {
+		t.Errorf("audit = %+v", entries)
+	}
+
+	// Pages follow creation time, including entries with random IDs from before IDs were time-ordered
+	legacy := []string{"ffffffff-0000-4000-8000-000000000000", "00000000-0000-4000-8000-000000000000"}
+	for i, id := range legacy {
+		_, err := sqlite.Exec(`INSERT INTO audit_log (id, user_id, actor_id, action, detail, created_at) VALUES (?, ?, ?, ?, '{}', ?)`,
+			id, database.DemoUserID, database.DemoUserID, models.AuditActionSessionsRetimed, time.Now().Add(-time.Duration(i+1)*time.Hour).Unix())
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	page := func(query string) ([]models.AuditEntry, string) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/audit?"+query, nil))
+		var entries []models.AuditEntry
+		if err := json.Unmarshal(w.Body.Bytes(), &entries); err != nil {
+			t.Fatal(err)
+		}
+		return entries, w.Header().Get("X-Next-Cursor")
+	}
+	first, cursor := page("limit=2")
+	if len(first) != 2 || first[1].ID != legacy[0] || cursor != legacy[0] {
+		t.Fatalf("first page = %+v, cursor %q", first, cursor)
+	}
+	second, cursor := page("limit=2&before=" + cursor)
+	if len(second) != 1 || second[0].ID != legacy[1] || cursor != "" {
+		t.Errorf("second page = %+v, cursor %q", second, cursor)
+	}
+}
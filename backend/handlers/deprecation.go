@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"liftoff/backend/deprecation"
+	"liftoff/backend/models"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeprecationHandler serves the admin report of deprecated route usage
+type DeprecationHandler struct {
+	registry        *deprecation.Registry
+	deprecationRepo *repository.DeprecationRepository
+}
+
+// NewDeprecationHandler creates a new deprecation handler
+func NewDeprecationHandler(registry *deprecation.Registry, deprecationRepo *repository.DeprecationRepository) *DeprecationHandler {
+	return &DeprecationHandler{registry: registry, deprecationRepo: deprecationRepo}
+}
+
+// GetReport lists every deprecated route with the users and tokens still calling it (admin only)
+func (h *DeprecationHandler) GetReport(c *gin.Context) {
+	if err := h.registry.Flush(c.Request.Context()); err != nil {
+		log.Printf("Error flushing deprecated usage: %v", err)
+	}
+
+	usage, err := h.deprecationRepo.GetDeprecatedUsage(c.Request.Context())
+	if err != nil {
+		log.Printf("Error fetching deprecated usage: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get deprecation report"})
+		return
+	}
+
+	byRoute := map[string][]*models.DeprecatedUsage{}
+	for _, u := range usage {
+		key := u.Method + " " + u.Path
+		byRoute[key] = append(byRoute[key], u)
+	}
+
+	report := []models.DeprecationReport{}
+	for _, n := range h.registry.Notices() {
+		entry := models.DeprecationReport{DeprecationNotice: n, Usage: byRoute[n.Method+" "+n.Path]}
+		if entry.Usage == nil {
+			entry.Usage = []*models.DeprecatedUsage{}
+		}
+		report = append(report, entry)
+	}
+	c.JSON(http.StatusOK, gin.H{"deprecations": report})
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"liftoff/backend/email"
+)
+
+// emailSendTimeout bounds a single delivery attempt
+const emailSendTimeout = 30 * time.Second
+
+// sendEmail renders a message and delivers it in the background, so a slow mail
+// server neither delays the response nor reveals through timing that an account exists
+func sendEmail(sender email.Sender, kind string, render func() (email.Message, error)) {
+	msg, err := render()
+	if err != nil {
+		log.Printf("Error rendering %s email: %v", kind, err)
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), emailSendTimeout)
+		defer cancel()
+		if err := sender.Send(ctx, msg); err != nil {
+			log.Printf("Error sending %s email to %s: %v", kind, msg.To, err)
+		}
+	}()
+}
+
+func (h *AuthHandler) sendPasswordReset(to, link string) {
+	sendEmail(h.mailer, "password reset", func() (email.Message, error) {
+		return email.PasswordReset(to, link, passwordResetTTL)
+	})
+}
+
+func (h *AuthHandler) sendMagicLink(to, link string) {
+	sendEmail(h.mailer, "sign-in link", func() (email.Message, error) {
+		return email.MagicLink(to, link, magicLinkTTL)
+	})
+}
+
+func (h *AuthHandler) sendNewLogin(to, device, ip string, at time.Time) {
+	sendEmail(h.mailer, "new login", func() (email.Message, error) {
+		return email.NewLogin(to, device, ip, at, frontendURL()+"/forgot-password")
+	})
+}
+
+func (h *AuthHandler) sendWelcome(to string) {
+	sendEmail(h.mailer, "welcome", func() (email.Message, error) {
+		return email.Welcome(to, frontendURL())
+	})
+}
+
+func (h *EmailChangeHandler) sendVerification(to, link string) {
+	sendEmail(h.mailer, "email verification", func() (email.Message, error) {
+		return email.VerifyEmail(to, link, emailChangeTTL)
+	})
+}
+
+func (h *GuestHandler) sendWelcome(to string) {
+	sendEmail(h.mailer, "welcome", func() (email.Message, error) {
+		return email.Welcome(to, frontendURL())
+	})
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/email"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// emailChangeTTL bounds how long a confirmation link for a new email stays valid
+const emailChangeTTL = time.Hour
+
+// EmailChangeHandler changes a user's email once the new address confirms it.
+// users.email is only touched on confirmation, so a hijacked session cannot
+// move an account to an address the attacker controls without the password.
+type EmailChangeHandler struct {
+	userRepo       *repository.UserRepository
+	revocationRepo *repository.TokenRevocationRepository
+	mailer         email.Sender
+}
+
+// NewEmailChangeHandler creates a new email change handler. Emails are logged until SetEmailSender is called.
+func NewEmailChangeHandler(userRepo *repository.UserRepository, revocationRepo *repository.TokenRevocationRepository) *EmailChangeHandler {
+	return &EmailChangeHandler{userRepo: userRepo, revocationRepo: revocationRepo, mailer: email.LogSender{}}
+}
+
+// SetEmailSender sets how confirmation emails are delivered
+func (h *EmailChangeHandler) SetEmailSender(sender email.Sender) {
+	h.mailer = sender
+}
+
+// ChangeEmailRequest is the request body for starting an email change
+type ChangeEmailRequest struct {
+	NewEmail string `json:"newEmail" binding:"required"`
+}
+
+// ConfirmEmailChangeRequest is the request body for confirming an email change
+type ConfirmEmailChangeRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// RequestChange sends a confirmation link to the new address. The route requires a
+// step-up token from POST /api/auth/reauth.
+func (h *EmailChangeHandler) RequestChange(c *gin.Context) {
+	if auth.GetAPIKeyID(c) != "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "API keys cannot change the account email"})
+		return
+	}
+	var req ChangeEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "New email is required"})
+		return
+	}
+
+	newEmail := auth.NormalizeEmail(req.NewEmail)
+	if !emailRegex.MatchString(newEmail) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid email format"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	user, err := h.userRepo.GetByID(ctx, auth.GetUserID(c))
+	if err != nil || user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+	if newEmail == auth.NormalizeEmail(user.Email) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "New email is the same as the current one"})
+		return
+	}
+
+	existing, err := h.userRepo.GetByEmail(ctx, newEmail)
+	if err != nil {
+		log.Printf("Error checking email for change: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start email change"})
+		return
+	}
+	if existing != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "An account with this email already exists"})
+		return
+	}
+
+	plainToken, err := repository.GenerateSecureToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start email change"})
+		return
+	}
+	err = h.userRepo.CreatePendingEmailChange(ctx, user.ID, newEmail, auth.HashToken(plainToken), time.Now().Add(emailChangeTTL))
+	if err != nil {
+		log.Printf("Error creating pending email change: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start email change"})
+		return
+	}
+
+	confirmLink := frontendURL() + "/confirm-email?token=" + plainToken
+	h.sendVerification(newEmail, confirmLink)
+
+	c.JSON(http.StatusOK, gin.H{"message": "A confirmation link has been sent to the new address"})
+}
+
+// ConfirmChange applies a pending email change and signs the user out everywhere,
+// since existing tokens carry the old address
+func (h *EmailChangeHandler) ConfirmChange(c *gin.Context) {
+	var req ConfirmEmailChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Token is required"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	user, oldEmail, err := h.userRepo.ConfirmEmailChange(ctx, auth.HashToken(req.Token))
+	if errors.Is(err, repository.ErrEmailTaken) {
+		c.JSON(http.StatusConflict, gin.H{"error": "An account with this email already exists"})
+		return
+	}
+	if err != nil {
+		log.Printf("Error confirming email change: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change email"})
+		return
+	}
+	if user == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired confirmation link"})
+		return
+	}
+
+	if err := h.revocationRepo.RevokeAllForUser(ctx, user.ID, time.Now()); err != nil {
+		log.Printf("Error revoking tokens after email change for user %s: %v", user.ID, err)
+	}
+	// Tell the old address so an unexpected change can be reported
+	log.Printf("Email for account %s changed from %s to %s", user.ID, oldEmail, user.Email)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email updated. Please sign in again.", "email": user.Email})
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/email"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestEmailChange_RequiresConfirmation(t *testing.T) {
+	db := newTestDB(t)
+	for _, q := range []string{
+		`CREATE TABLE pending_email_changes (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			new_email TEXT NOT NULL,
+			token_hash TEXT NOT NULL UNIQUE,
+			expires_at INTEGER NOT NULL,
+			created_at INTEGER NOT NULL
+		)`,
+		`CREATE TABLE user_token_cutoffs (user_id TEXT PRIMARY KEY, revoked_before INTEGER NOT NULL)`,
+	} {
+		if _, err := db.Exec(q); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hash, _ := auth.HashPassword("Passw0rd!")
+	if _, err := db.Exec(`INSERT INTO users (id, email, password_hash) VALUES ('u1', 'old@test.com', ?), ('u2', 'taken@test.com', 'x')`, hash); err != nil {
+		t.Fatal(err)
+	}
+
+	userRepo := repository.NewUserRepository(nil, db, true)
+	handler := NewEmailChangeHandler(userRepo, repository.NewTokenRevocationRepository(nil, db, true))
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/change-email", func(c *gin.Context) { c.Set(auth.UserIDKey, "u1") }, handler.RequestChange)
+	r.POST("/confirm-email-change", handler.ConfirmChange)
+
+	sent := make(captureSender, 1)
+	handler.SetEmailSender(sent)
+
+	post := func(path string, body interface{}) *httptest.ResponseRecorder {
+		raw, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(raw))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := post("/change-email", map[string]string{"newEmail": "taken@test.com"}); w.Code != http.StatusConflict {
+		t.Errorf("taken email: got %d", w.Code)
+	}
+	if w := post("/change-email", map[string]string{"newEmail": "New@Test.com"}); w.Code != http.StatusOK {
+		t.Fatalf("request change: got %d %s", w.Code, w.Body.String())
+	}
+
+	// Nothing changes until the new address confirms
+	if user, _ := userRepo.GetByID(t.Context(), "u1"); user.Email != "old@test.com" {
+		t.Fatalf("email changed before confirmation: %s", user.Email)
+	}
+
+	var msg email.Message
+	select {
+	case msg = <-sent:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no confirmation email sent")
+	}
+	m := regexp.MustCompile(`confirm-email\?token=([0-9a-f]+)`).FindStringSubmatch(msg.Text)
+	if msg.To != "new@test.com" || m == nil {
+		t.Fatalf("no confirmation link sent to the new address: %+v", msg)
+	}
+	if w := post("/confirm-email-change", map[string]string{"token": "bogus"}); w.Code != http.StatusBadRequest {
+		t.Errorf("bogus token: got %d", w.Code)
+	}
+	if w := post("/confirm-email-change", map[string]string{"token": m[1]}); w.Code != http.StatusOK {
+		t.Fatalf("confirm: got %d %s", w.Code, w.Body.String())
+	}
+	if user, _ := userRepo.GetByID(t.Context(), "u1"); user.Email != "new@test.com" {
+		t.Errorf("email = %s, want new@test.com", user.Email)
+	}
+	var cutoffs int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM user_token_cutoffs WHERE user_id = 'u1'`).Scan(&cutoffs); err != nil || cutoffs != 1 {
+		t.Errorf("tokens not revoked: %d, %v", cutoffs, err)
+	}
+
+	// Links are single-use
+	if w := post("/confirm-email-change", map[string]string{"token": m[1]}); w.Code != http.StatusBadRequest {
+		t.Errorf("reused token: got %d", w.Code)
+	}
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/models"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExportHandler serves personal data exports
+type ExportHandler struct {
+	userRepo    *repository.UserRepository
+	workoutRepo *repository.WorkoutRepository
+	sessionRepo *repository.SessionRepository
+}
+
+// NewExportHandler creates a new export handler
+func NewExportHandler(userRepo *repository.UserRepository, workoutRepo *repository.WorkoutRepository, sessionRepo *repository.SessionRepository) *ExportHandler {
+	return &ExportHandler{userRepo: userRepo, workoutRepo: workoutRepo, sessionRepo: sessionRepo}
+}
+
+// Export streams a ZIP archive of everything stored about the user, one JSON file
+// per kind of record. Sessions, sets and scores are written row by row as they are
+// read, so large histories are never held in memory.
+func (h *ExportHandler) Export(c *gin.Context) {
+	if auth.GetAPIKeyID(c) != "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "API keys cannot export account data"})
+		return
+	}
+	ctx := c.Request.Context()
+	userID := auth.GetUserID(c)
+	user, err := h.userRepo.GetByID(ctx, userID)
+	if err != nil || user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+	// Workouts are bounded by what the user builds by hand, so they are gathered
+	// before the response starts and can still fail with a proper status
+	workouts, err := h.workoutRepo.GetWorkouts(ctx, userID, repository.WorkoutFilter{IncludeArchived: true})
+	if err == nil {
+		for i, w := range workouts {
+			if workouts[i], err = h.workoutRepo.GetWorkout(ctx, userID, w.ID); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		log.Printf("Error exporting workouts for %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export data"})
+		return
+	}
+
+	now := time.Now().UTC()
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="liftoff-export-%s.zip"`, now.Format("20060102")))
+	c.Status(http.StatusOK)
+
+	if err := writeExport(ctx, c.Writer, h, user, workouts, now); err != nil {
+		// Headers are already sent; the truncated archive fails to open on the client
+		log.Printf("Error streaming export for %s: %v", userID, err)
+	}
+}
+
+func writeExport(ctx context.Context, w io.Writer, h *ExportHandler, user *models.User, workouts []*models.Workout, now time.Time) error {
+	zw := zip.NewWriter(w)
+	file := func(name string) (io.Writer, error) {
+		return zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: now})
+	}
+
+	f, err := file("profile.json")
+	if err != nil {
+		return err
+	}
+	if err := writeIndented(f, gin.H{"user": user, "exported_at": now}); err != nil {
+		return err
+	}
+	if f, err = file("workouts.json"); err != nil {
+		return err
+	}
+	if err := writeIndented(f, workouts); err != nil {
+		return err
+	}
+
+	if f, err = file("sessions.json"); err != nil {
+		return err
+	}
+	err = streamJSONArray(f, func(emit func(interface{}) error) error {
+		return h.sessionRepo.EachSession(ctx, user.ID, func(s *models.WorkoutSession) error { return emit(s) })
+	})
+	if err != nil {
+		return err
+	}
+
+	if f, err = file("sets.json"); err != nil {
+		return err
+	}
+	err = streamJSONArray(f, func(emit func(interface{}) error) error {
+		return h.sessionRepo.EachSet(ctx, user.ID, func(s *models.ExportSet) error { return emit(s) })
+	})
+	if err != nil {
+		return err
+	}
+
+	if f, err = file("dino_game_scores.json"); err != nil {
+		return err
+	}
+	err = streamJSONArray(f, func(emit func(interface{}) error) error {
+		return h.workoutRepo.EachDinoGameScore(ctx, user.ID, func(s *models.DinoGameScore) error { return emit(s) })
+	})
+	if err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func writeIndented(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// streamJSONArray writes the values passed to emit as a JSON array, one element per line
+func streamJSONArray(w io.Writer, each func(emit func(interface{}) error) error) error {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	sep := "\n"
+	err := each(func(v interface{}) error {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, sep); err != nil {
+			return err
+		}
+		sep = ",\n"
+		_, err = w.Write(b)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n]\n")
+	return err
+}
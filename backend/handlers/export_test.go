@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/database"
+	"liftoff/backend/models"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestExport(t *testing.T) {
+	db, err := database.NewMockDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	sqlite := db.GetSQLite()
+	workoutRepo := repository.NewWorkoutRepository(nil, sqlite, true)
+	if _, err := workoutRepo.CreateDinoGameScore(t.Context(), database.DemoUserID, 42); err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewExportHandler(repository.NewUserRepository(nil, sqlite, true), workoutRepo, repository.NewSessionRepository(nil, sqlite, true))
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/export", func(c *gin.Context) { c.Set(auth.UserIDKey, database.DemoUserID) }, h.Export)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/export", nil))
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/zip" {
+		t.Fatalf("got %d %s: %s", w.Code, w.Header().Get("Content-Type"), w.Body.String())
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string][]byte{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name], _ = io.ReadAll(rc)
+		rc.Close()
+	}
+
+	var profile struct {
+		User models.User `json:"user"`
+	}
+	if err := json.Unmarshal(files["profile.json"], &profile); err != nil || profile.User.Email != "demo@liftoff.local" {
+		t.Errorf("profile = %s, %v", files["profile.json"], err)
+	}
+	var workouts []models.Workout
+	if err := json.Unmarshal(files["workouts.json"], &workouts); err != nil || len(workouts) != 3 || len(workouts[0].Exercises) == 0 {
+		t.Errorf("workouts = %d, %v", len(workouts), err)
+	}
+	var sessions []models.WorkoutSession
+	if err := json.Unmarshal(files["sessions.json"], &sessions); err != nil || len(sessions) == 0 {
+		t.Fatalf("sessions = %d, %v", len(sessions), err)
+	}
+	var sets []models.ExportSet
+	if err := json.Unmarshal(files["sets.json"], &sets); err != nil || len(sets) == 0 || sets[0].ExerciseName == "" || sets[0].SessionID != sessions[0].ID {
+		t.Errorf("sets = %d, %v", len(sets), err)
+	}
+	var scores []models.DinoGameScore
+	if err := json.Unmarshal(files["dino_game_scores.json"], &scores); err != nil || len(scores) != 1 || scores[0].Score != 42 {
+		t.Errorf("scores = %s, %v", files["dino_game_scores.json"], err)
+	}
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/email"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// minDeviceIDLength keeps guessable device IDs from opening someone else's guest data
+const minDeviceIDLength = 16
+
+// GuestHandler lets people log workouts before registering, under a provisional
+// account bound to their device, and claim that data when they sign up
+type GuestHandler struct {
+	userRepo       *repository.UserRepository
+	revocationRepo *repository.TokenRevocationRepository
+	mailer         email.Sender
+}
+
+// NewGuestHandler creates a new guest handler. Emails are logged until SetEmailSender is called.
+func NewGuestHandler(userRepo *repository.UserRepository, revocationRepo *repository.TokenRevocationRepository) *GuestHandler {
+	return &GuestHandler{userRepo: userRepo, revocationRepo: revocationRepo, mailer: email.LogSender{}}
+}
+
+// SetEmailSender sets how the welcome email sent on claim is delivered
+func (h *GuestHandler) SetEmailSender(sender email.Sender) {
+	h.mailer = sender
+}
+
+// StartGuestRequest names the device to sign in as a guest from
+type StartGuestRequest struct {
+	DeviceID     string `json:"deviceId" binding:"required"`
+	CaptchaToken string `json:"captchaToken"` // required when CAPTCHA_PROVIDER is set
+}
+
+// Start issues a guest token for the device, creating its provisional account on
+// first use. The token is only accepted with the same device ID in X-Device-ID.
+func (h *GuestHandler) Start(c *gin.Context) {
+	var req StartGuestRequest
+	if err := c.ShouldBindJSON(&req); err != nil || len(req.DeviceID) < minDeviceIDLength || len(req.DeviceID) > 200 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "deviceId must be a random string of 16 to 200 characters"})
+		return
+	}
+	if !verifyCaptcha(c, req.CaptchaToken) {
+		return
+	}
+
+	deviceHash := auth.HashToken(req.DeviceID)
+	user, err := h.userRepo.GetOrCreateGuest(c.Request.Context(), deviceHash)
+	if err != nil {
+		log.Printf("Error starting guest session: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start guest session"})
+		return
+	}
+	tokenString, expiresAt, err := auth.GenerateGuestToken(user.ID, user.Email, deviceHash)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+	respondAuth(c, http.StatusOK, user, tokenString, expiresAt)
+}
+
+// Claim registers an account for a signed-in guest and moves everything they logged
+// into it. The guest account and its token stop working.
+func (h *GuestHandler) Claim(c *gin.Context) {
+	if !auth.IsGuest(c) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only guest sessions can be claimed"})
+		return
+	}
+	var req RegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Email and password are required"})
+		return
+	}
+	email := auth.NormalizeEmail(req.Email)
+	if !emailRegex.MatchString(email) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid email format"})
+		return
+	}
+	if !verifyCaptcha(c, req.CaptchaToken) {
+		return
+	}
+	ctx := c.Request.Context()
+	if err := auth.ValidatePasswordStrong(ctx, req.Password); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	existing, err := h.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		log.Printf("Claim GetByEmail error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Registration failed"})
+		return
+	}
+	if existing != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "An account with this email already exists"})
+		return
+	}
+	passwordHash, err := auth.HashPassword(req.Password)
+	if err != nil {
+		log.Printf("Claim HashPassword error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Registration failed"})
+		return
+	}
+
+	guestID := auth.GetUserID(c)
+	user, result, err := h.userRepo.ClaimGuest(ctx, guestID, email, passwordHash)
+	if errors.Is(err, repository.ErrNotGuest) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only guest sessions can be claimed"})
+		return
+	}
+	if err != nil {
+		log.Printf("Error claiming guest account %s: %v", guestID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Registration failed"})
+		return
+	}
+
+	// The guest user is gone, but its signed token would still verify until it expires
+	if claims := auth.GetClaims(c); claims != nil && claims.ID != "" && claims.ExpiresAt != nil {
+		if err := h.revocationRepo.RevokeToken(ctx, claims.ID, guestID, claims.ExpiresAt.Time); err != nil {
+			log.Printf("Error revoking guest token: %v", err)
+		}
+	}
+	log.Printf("Guest account %s claimed as %s (moved %v)", guestID, user.ID, result.Moved)
+	h.sendWelcome(user.Email)
+
+	tokenString, expiresAt, err := auth.GenerateToken(user.ID, user.Email, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Registration succeeded but failed to generate token"})
+		return
+	}
+	respondAuth(c, http.StatusCreated, user, tokenString, expiresAt)
+}
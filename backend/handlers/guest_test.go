@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/database"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGuestClaim(t *testing.T) {
+	db, err := database.NewMockDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	sqlite := db.GetSQLite()
+	userRepo := repository.NewUserRepository(nil, sqlite, true)
+	h := NewGuestHandler(userRepo, repository.NewTokenRevocationRepository(nil, sqlite, true))
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/guest", h.Start)
+	r.POST("/guest/claim", auth.AuthMiddleware(), h.Claim)
+	r.POST("/api-keys", auth.AuthMiddleware(), auth.RegisteredMiddleware(), func(c *gin.Context) { c.Status(http.StatusCreated) })
+	post := func(path, token, device string, body interface{}) *httptest.ResponseRecorder {
+		raw, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(raw))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		if device != "" {
+			req.Header.Set(auth.DeviceIDHeader, device)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	start := func(device string) AuthResponse {
+		w := post("/guest", "", "", map[string]string{"deviceId": device})
+		if w.Code != http.StatusOK {
+			t.Fatalf("start guest: got %d %s", w.Code, w.Body)
+		}
+		var resp AuthResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	if w := post("/guest", "", "", map[string]string{"deviceId": "short"}); w.Code != http.StatusBadRequest {
+		t.Errorf("short device ID: got %d", w.Code)
+	}
+	const device = "0f8e7d6c-5b4a-3928-1706-f5e4d3c2b1a0"
+	guest := start(device)
+	if again := start(device); again.User.ID != guest.User.ID {
+		t.Errorf("same device got a new guest: %s, %s", again.User.ID, guest.User.ID)
+	}
+	if _, err := sqlite.Exec(`INSERT INTO workouts (id, name, user_id) VALUES ('w-guest', 'Trial Workout', ?)`, guest.User.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if w := post("/api-keys", guest.Token, device, nil); w.Code != http.StatusForbidden {
+		t.Errorf("guest creating API key: got %d", w.Code)
+	}
+	claim := map[string]string{"email": "traveller@example.com", "password": "Tr1al-And-Err0r!"}
+	if w := post("/guest/claim", guest.Token, "another-device-0000000", claim); w.Code != http.StatusUnauthorized {
+		t.Errorf("claim from another device: got %d", w.Code)
+	}
+	claim["email"] = database.DemoUserEmail
+	if w := post("/guest/claim", guest.Token, device, claim); w.Code != http.StatusConflict {
+		t.Errorf("claim with a taken email: got %d", w.Code)
+	}
+	claim["email"] = "traveller@example.com"
+	w := post("/guest/claim", guest.Token, device, claim)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("claim: got %d %s", w.Code, w.Body)
+	}
+	var claimed AuthResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &claimed); err != nil {
+		t.Fatal(err)
+	}
+
+	var owner string
+	if err := sqlite.QueryRow(`SELECT user_id FROM workouts WHERE id = 'w-guest'`).Scan(&owner); err != nil || owner != claimed.User.ID {
+		t.Errorf("workout owner = %q, %v; want %s", owner, err, claimed.User.ID)
+	}
+	var guests int
+	if err := sqlite.QueryRow(`SELECT COUNT(*) FROM users WHERE id = ?`, guest.User.ID).Scan(&guests); err != nil || guests != 0 {
+		t.Errorf("guest user still exists: %d, %v", guests, err)
+	}
+	if w := post("/guest/claim", claimed.Token, "", claim); w.Code != http.StatusBadRequest {
+		t.Errorf("claiming a registered account: got %d", w.Code)
+	}
+}
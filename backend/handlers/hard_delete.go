@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/models"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// hardDeleteConfirmTTL bounds how long a hard-delete confirmation token stays valid
+const hardDeleteConfirmTTL = 10 * time.Minute
+
+// HardDeleteHandler lets admins permanently delete individual records, such as
+// reported content, and place accounts under legal hold to protect them
+type HardDeleteHandler struct {
+	userRepo *repository.UserRepository
+}
+
+// NewHardDeleteHandler creates a new hard delete handler
+func NewHardDeleteHandler(userRepo *repository.UserRepository) *HardDeleteHandler {
+	return &HardDeleteHandler{userRepo: userRepo}
+}
+
+// LegalHoldRequest records why a hold was placed or released
+type LegalHoldRequest struct {
+	Reason string `json:"reason"`
+}
+
+// PlaceLegalHold stops an account from being deleted, in whole or record by record (admin only)
+func (h *HardDeleteHandler) PlaceLegalHold(c *gin.Context) {
+	h.setLegalHold(c, true)
+}
+
+// ReleaseLegalHold lifts a legal hold (admin only)
+func (h *HardDeleteHandler) ReleaseLegalHold(c *gin.Context) {
+	h.setLegalHold(c, false)
+}
+
+func (h *HardDeleteHandler) setLegalHold(c *gin.Context, hold bool) {
+	var req LegalHoldRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+	}
+	if len(req.Reason) > 500 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reason must be at most 500 characters"})
+		return
+	}
+
+	userID := c.Param("id")
+	adminID := auth.GetUserID(c)
+	entry, err := h.userRepo.SetLegalHold(c.Request.Context(), userID, adminID, hold, req.Reason)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("Error setting legal hold for %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update legal hold"})
+		return
+	}
+	log.Printf("Admin %s set legal hold of user %s to %t", adminID, userID, hold)
+	c.JSON(http.StatusOK, gin.H{"id": userID, "legal_hold": hold, "audit": entry})
+}
+
+// RequestHardDeleteRequest explains why a record is being deleted
+type RequestHardDeleteRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// RequestHardDelete checks a record can be deleted and returns a short-lived token
+// to confirm it with (admin only). Nothing is deleted yet.
+func (h *HardDeleteHandler) RequestHardDelete(c *gin.Context) {
+	var req RequestHardDeleteRequest
+	if err := c.ShouldBindJSON(&req); err != nil || len(req.Reason) > 500 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A reason of at most 500 characters is required"})
+		return
+	}
+	userID, kind, recordID, ok := hardDeleteTarget(c)
+	if !ok {
+		return
+	}
+
+	token, err := repository.GenerateSecureToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to request deletion"})
+		return
+	}
+	expiresAt := time.Now().Add(hardDeleteConfirmTTL)
+	err = h.userRepo.RequestHardDelete(c.Request.Context(), auth.GetUserID(c), userID, kind, recordID, req.Reason, auth.HashToken(token), expiresAt)
+	if !hardDeleteError(c, err, "Failed to request deletion") {
+		return
+	}
+	c.JSON(http.StatusOK, models.HardDeleteConfirmation{
+		Token:     token,
+		UserID:    userID,
+		Kind:      kind,
+		RecordID:  recordID,
+		ExpiresAt: expiresAt.UTC(),
+	})
+}
+
+// ConfirmHardDeleteRequest carries the token from RequestHardDelete
+type ConfirmHardDeleteRequest struct {
+	ConfirmToken string `json:"confirm_token" binding:"required"`
+}
+
+// HardDelete permanently deletes a record once confirmed with a token from
+// RequestHardDelete, and records it in the user's audit log (admin only)
+func (h *HardDeleteHandler) HardDelete(c *gin.Context) {
+	var req ConfirmHardDeleteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "confirm_token is required"})
+		return
+	}
+	userID, kind, recordID, ok := hardDeleteTarget(c)
+	if !ok {
+		return
+	}
+
+	adminID := auth.GetUserID(c)
+	entry, err := h.userRepo.HardDelete(c.Request.Context(), adminID, userID, kind, recordID, auth.HashToken(req.ConfirmToken))
+	if !hardDeleteError(c, err, "Failed to delete record") {
+		return
+	}
+	log.Printf("Admin %s hard-deleted %s %s of user %s", adminID, kind, recordID, userID)
+	c.JSON(http.StatusOK, gin.H{"message": "Record permanently deleted", "audit": entry})
+}
+
+func hardDeleteTarget(c *gin.Context) (string, string, string, bool) {
+	kind := c.Param("kind")
+	if !models.ValidHardDeleteKind(kind) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "kind must be workout or session"})
+		return "", "", "", false
+	}
+	return c.Param("id"), kind, c.Param("recordId"), true
+}
+
+// hardDeleteError writes the response for a failed request or deletion and reports
+// whether err was nil
+func hardDeleteError(c *gin.Context, err error, message string) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, repository.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	case errors.Is(err, repository.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Record not found"})
+	case errors.Is(err, repository.ErrLegalHold):
+		c.JSON(http.StatusConflict, gin.H{"error": "This account is under legal hold"})
+	case errors.Is(err, repository.ErrInvalidConfirmation):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Invalid or expired confirmation token"})
+	default:
+		log.Printf("Hard delete error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/database"
+	"liftoff/backend/models"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestHardDelete(t *testing.T) {
+	db, err := database.NewMockDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	sqlite := db.GetSQLite()
+	ctx := t.Context()
+	userRepo := repository.NewUserRepository(nil, sqlite, true)
+	workoutRepo := repository.NewWorkoutRepository(nil, sqlite, true)
+	sessionRepo := repository.NewSessionRepository(nil, sqlite, true)
+
+	workout, err := workoutRepo.CreateWorkout(ctx, database.DemoUserID, "Reported", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := workoutRepo.CreateExercise(ctx, database.DemoUserID, &models.Exercise{Name: "Squats", Sets: 3, Reps: 5, WorkoutID: workout.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sessionRepo.CreateSessionWithExercises(ctx, database.DemoUserID, workout.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	tokenFor := func(email string) string {
+		u, err := userRepo.CreateUser(ctx, email, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		token, _, err := auth.GenerateToken(u.ID, u.Email, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	admin, otherAdmin := tokenFor("support@liftoff.test"), tokenFor("legal@liftoff.test")
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := NewHardDeleteHandler(userRepo)
+	authed := r.Group("/", auth.AuthMiddleware())
+	authed.PUT("/users/:id/legal-hold", h.PlaceLegalHold)
+	authed.DELETE("/users/:id/legal-hold", h.ReleaseLegalHold)
+	authed.POST("/users/:id/records/:kind/:recordId/hard-delete", h.RequestHardDelete)
+	authed.DELETE("/users/:id/records/:kind/:recordId", h.HardDelete)
+	do := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	record := "/users/" + database.DemoUserID + "/records/workout/" + workout.ID
+	request := func() string {
+		w := do(http.MethodPost, record+"/hard-delete", admin, `{"reason": "reported content"}`)
+		if w.Code != http.StatusOK {
+			t.Fatalf("request: got %d: %s", w.Code, w.Body)
+		}
+		var conf models.HardDeleteConfirmation
+		json.Unmarshal(w.Body.Bytes(), &conf)
+		return `{"confirm_token": "` + conf.Token + `"}`
+	}
+
+	if w := do(http.MethodPost, "/users/"+database.DemoUserID+"/records/routine/x/hard-delete", admin, `{"reason": "x"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown kind: got %d", w.Code)
+	}
+	if w := do(http.MethodPost, "/users/"+database.DemoUserID+"/records/session/missing/hard-delete", admin, `{"reason": "x"}`); w.Code != http.StatusNotFound {
+		t.Errorf("missing record: got %d", w.Code)
+	}
+
+	// Tokens are bound to the admin who requested them and are single-use
+	confirm := request()
+	if w := do(http.MethodDelete, record, otherAdmin, confirm); w.Code != http.StatusForbidden {
+		t.Errorf("other admin's confirmation: got %d: %s", w.Code, w.Body)
+	}
+	if w := do(http.MethodDelete, record, admin, `{"confirm_token": "guess"}`); w.Code != http.StatusForbidden {
+		t.Errorf("wrong token: got %d", w.Code)
+	}
+
+	// A hold placed after the request still blocks the deletion
+	if w := do(http.MethodPut, "/users/"+database.DemoUserID+"/legal-hold", otherAdmin, `{"reason": "litigation"}`); w.Code != http.StatusOK {
+		t.Fatalf("place hold: got %d: %s", w.Code, w.Body)
+	}
+	if w := do(http.MethodDelete, record, admin, confirm); w.Code != http.StatusConflict {
+		t.Errorf("confirm under hold: got %d: %s", w.Code, w.Body)
+	}
+	if w := do(http.MethodPost, record+"/hard-delete", admin, `{"reason": "reported content"}`); w.Code != http.StatusConflict {
+		t.Errorf("request under hold: got %d: %s", w.Code, w.Body)
+	}
+	if err := userRepo.DeleteAccount(ctx, database.DemoUserID); err != repository.ErrLegalHold {
+		t.Errorf("DeleteAccount under hold: err = %v", err)
+	}
+	if w := do(http.MethodDelete, "/users/"+database.DemoUserID+"/legal-hold", otherAdmin, ""); w.Code != http.StatusOK {
+		t.Fatalf("release hold: got %d: %s", w.Code, w.Body)
+	}
+
+	confirm = request()
+	if w := do(http.MethodDelete, record, admin, confirm); w.Code != http.StatusOK {
+		t.Fatalf("confirm: got %d: %s", w.Code, w.Body)
+	}
+	if w := do(http.MethodDelete, record, admin, confirm); w.Code != http.StatusForbidden {
+		t.Errorf("reused token: got %d", w.Code)
+	}
+
+	for _, table := range []string{"workouts WHERE id = ?", "exercises WHERE workout_id = ?", "workout_sessions WHERE workout_id = ?"} {
+		var n int
+		if err := sqlite.QueryRow(`SELECT COUNT(*) FROM `+table, workout.ID).Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		if n != 0 {
+			t.Errorf("%s: %d rows left", table, n)
+		}
+	}
+	entries, err := repository.NewAuditRepository(nil, sqlite, true).ListForUser(ctx, database.DemoUserID, "", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actions := map[string]string{}
+	for _, e := range entries {
+		actions[e.Action] = string(e.Detail)
+	}
+	if len(entries) != 3 || !strings.Contains(actions[models.AuditActionRecordHardDeleted], `"reason":"reported content"`) ||
+		!strings.Contains(actions[models.AuditActionLegalHoldPlaced], "litigation") {
+		t.Errorf("audit = %v", actions)
+	}
+}
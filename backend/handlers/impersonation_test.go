@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/database"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestImpersonate(t *testing.T) {
+	db, err := database.NewMockDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	sqlite := db.GetSQLite()
+	userRepo := repository.NewUserRepository(nil, sqlite, true)
+	admin, err := userRepo.CreateUser(t.Context(), "support@liftoff.test", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	adminToken, _, err := auth.GenerateToken(admin.ID, admin.Email, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ADMIN_EMAILS", "other-admin@liftoff.test")
+	otherAdmin, err := userRepo.CreateUser(t.Context(), "other-admin@liftoff.test", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherAdminToken, _, err := auth.GenerateToken(otherAdmin.ID, otherAdmin.Email, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asOtherAdmin, _, err := auth.GenerateImpersonationToken(otherAdmin.ID, otherAdmin.Email, admin.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(auth.AuthMiddleware())
+	r.POST("/admin/users/:id/impersonate", NewAdminHandler(userRepo, repository.NewAdminRepository(nil, sqlite, true)).Impersonate)
+	r.GET("/me", NewAuthHandler(userRepo).Me)
+	r.GET("/admin/ping", auth.AdminMiddleware(), func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.POST("/api-keys", NewAPIKeyHandler(repository.NewAPIKeyRepository(nil, sqlite, true)).Create)
+	do := func(method, path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(`{"name": "support"}`))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(http.MethodPost, "/admin/users/"+database.DemoUserID+"/impersonate", adminToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("impersonate: got %d: %s", w.Code, w.Body)
+	}
+	var resp ImpersonationResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.User.ID != database.DemoUserID || resp.ImpersonatedBy != admin.ID {
+		t.Fatalf("response = %+v", resp)
+	}
+	claims, err := auth.ValidateToken(resp.Token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.UserID != database.DemoUserID || claims.ImpersonatedBy != admin.ID || claims.ExpiresAt.After(time.Now().Add(auth.DefaultImpersonationTTL)) {
+		t.Errorf("claims = %+v", claims)
+	}
+
+	w = do(http.MethodGet, "/me", resp.Token)
+	var me struct {
+		User struct {
+			ID string `json:"id"`
+		} `json:"user"`
+		ImpersonatedBy string `json:"impersonatedBy"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &me)
+	if w.Code != http.StatusOK || me.User.ID != database.DemoUserID || me.ImpersonatedBy != admin.ID {
+		t.Errorf("me: got %d %s", w.Code, w.Body)
+	}
+	if got := w.Header().Get("X-Impersonated-By"); got != admin.ID {
+		t.Errorf("X-Impersonated-By = %q", got)
+	}
+	if w := do(http.MethodGet, "/me", adminToken); strings.Contains(w.Body.String(), "impersonatedBy") {
+		t.Errorf("regular token reported impersonation: %s", w.Body)
+	}
+
+	steps := []struct {
+		name, method, path, token string
+		want                      int
+	}{
+		{"chained", http.MethodPost, "/admin/users/" + admin.ID + "/impersonate", resp.Token, http.StatusForbidden},
+		{"self", http.MethodPost, "/admin/users/" + admin.ID + "/impersonate", adminToken, http.StatusBadRequest},
+		{"unknown user", http.MethodPost, "/admin/users/nope/impersonate", adminToken, http.StatusNotFound},
+		{"mint api key", http.MethodPost, "/api-keys", resp.Token, http.StatusForbidden},
+		{"admin target", http.MethodPost, "/admin/users/" + otherAdmin.ID + "/impersonate", adminToken, http.StatusForbidden},
+		{"admin routes", http.MethodGet, "/admin/ping", otherAdminToken, http.StatusOK},
+		{"admin routes while impersonating", http.MethodGet, "/admin/ping", asOtherAdmin, http.StatusForbidden},
+	}
+	for _, step := range steps {
+		if w := do(step.method, step.path, step.token); w.Code != step.want {
+			t.Errorf("%s: got %d, want %d: %s", step.name, w.Code, step.want, w.Body)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/models"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// InjuryHandler manages user-logged injuries
+type InjuryHandler struct {
+	injuryRepo *repository.InjuryRepository
+}
+
+// NewInjuryHandler creates a new injury handler
+func NewInjuryHandler(injuryRepo *repository.InjuryRepository) *InjuryHandler {
+	return &InjuryHandler{injuryRepo: injuryRepo}
+}
+
+// CreateInjuryRequest is the request body for logging an injury
+type CreateInjuryRequest struct {
+	Name         string   `json:"name" binding:"required"`
+	Restrictions []string `json:"restrictions" binding:"required,min=1"`
+	Notes        string   `json:"notes"`
+}
+
+// GetInjuries lists the user's injuries (?active=true for active only)
+func (h *InjuryHandler) GetInjuries(c *gin.Context) {
+	injuries, err := h.injuryRepo.GetInjuries(c.Request.Context(), auth.GetUserID(c), c.Query("active") == "true")
+	if err != nil {
+		log.Printf("Error fetching injuries: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch injuries"})
+		return
+	}
+	if injuries == nil {
+		injuries = []*models.Injury{}
+	}
+	c.JSON(http.StatusOK, injuries)
+}
+
+// CreateInjury logs an active injury with the risk flags it restricts
+func (h *InjuryHandler) CreateInjury(c *gin.Context) {
+	var req CreateInjuryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Name and at least one restriction are required"})
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Name must be 1-100 characters"})
+		return
+	}
+	restrictions := make([]string, 0, len(req.Restrictions))
+	for _, r := range req.Restrictions {
+		if !isRiskFlag(r) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown restriction: " + r, "valid": models.RiskFlags})
+			return
+		}
+		restrictions = append(restrictions, r)
+	}
+
+	injury := &models.Injury{
+		UserID:       auth.GetUserID(c),
+		Name:         name,
+		Restrictions: restrictions,
+		Notes:        strings.TrimSpace(req.Notes),
+	}
+	if err := h.injuryRepo.CreateInjury(c.Request.Context(), injury); err != nil {
+		log.Printf("Error creating injury: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log injury"})
+		return
+	}
+	c.JSON(http.StatusCreated, injury)
+}
+
+// ResolveInjury marks an injury as healed
+func (h *InjuryHandler) ResolveInjury(c *gin.Context) {
+	err := h.injuryRepo.ResolveInjury(c.Request.Context(), auth.GetUserID(c), c.Param("id"))
+	if errors.Is(err, repository.ErrInjuryNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Active injury not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("Error resolving injury: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve injury"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Injury resolved"})
+}
+
+// DeleteInjury removes an injury record
+func (h *InjuryHandler) DeleteInjury(c *gin.Context) {
+	err := h.injuryRepo.DeleteInjury(c.Request.Context(), auth.GetUserID(c), c.Param("id"))
+	if errors.Is(err, repository.ErrInjuryNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Injury not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("Error deleting injury: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete injury"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Injury deleted"})
+}
+
+func isRiskFlag(flag string) bool {
+	for _, f := range models.RiskFlags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
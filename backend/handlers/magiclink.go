@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// magicLinkTTL bounds how long an emailed login link stays valid
+const magicLinkTTL = 15 * time.Minute
+
+type MagicLinkRequest struct {
+	Email string `json:"email" binding:"required"`
+}
+
+type MagicLoginRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// RequestMagicLink emails a one-time login link (or logs it in dev)
+func (h *AuthHandler) RequestMagicLink(c *gin.Context) {
+	var req MagicLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Email is required"})
+		return
+	}
+
+	email := auth.NormalizeEmail(req.Email)
+	if !emailRegex.MatchString(email) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid email format"})
+		return
+	}
+
+	const sent = "If an account exists, a sign-in link has been sent"
+	user, err := h.userRepo.GetByEmail(c.Request.Context(), email)
+	if err != nil {
+		log.Printf("Error looking up user for magic link: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": sent})
+		return
+	}
+	// Always return success to prevent email enumeration
+	if user == nil {
+		c.JSON(http.StatusOK, gin.H{"message": sent})
+		return
+	}
+
+	plainToken, err := repository.GenerateSecureToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate sign-in link"})
+		return
+	}
+
+	tokenHash := auth.HashToken(plainToken)
+	err = h.userRepo.CreateOneTimeToken(c.Request.Context(), user.ID, repository.TokenPurposeMagicLink, tokenHash, time.Now().Add(magicLinkTTL))
+	if err != nil {
+		log.Printf("Error creating magic link token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate sign-in link"})
+		return
+	}
+
+	loginLink := frontendURL() + "/magic-login?token=" + plainToken
+	h.sendMagicLink(user.Email, loginLink)
+
+	c.JSON(http.StatusOK, gin.H{"message": sent})
+}
+
+// MagicLogin exchanges a magic-link token for a JWT. Each link works once.
+func (h *AuthHandler) MagicLogin(c *gin.Context) {
+	var req MagicLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Token is required"})
+		return
+	}
+
+	userID, err := h.userRepo.ConsumeOneTimeToken(c.Request.Context(), repository.TokenPurposeMagicLink, auth.HashToken(req.Token))
+	if err != nil {
+		log.Printf("Error consuming magic link token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Login failed"})
+		return
+	}
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired sign-in link"})
+		return
+	}
+
+	user, err := h.userRepo.GetByID(c.Request.Context(), userID)
+	if err != nil || user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	tokenString, expiresAt, err := auth.GenerateToken(user.ID, user.Email, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+	respondAuth(c, http.StatusOK, user, tokenString, expiresAt)
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/maintenance"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MaintenanceHandler lets admins switch maintenance mode and registration at runtime
+type MaintenanceHandler struct {
+	sw *maintenance.Switch
+}
+
+// NewMaintenanceHandler creates a new maintenance handler
+func NewMaintenanceHandler(sw *maintenance.Switch) *MaintenanceHandler {
+	return &MaintenanceHandler{sw: sw}
+}
+
+// MaintenanceRequest sets both switches
+type MaintenanceRequest struct {
+	Maintenance          bool   `json:"maintenance"`
+	Message              string `json:"message"`
+	RegistrationDisabled bool   `json:"registration_disabled"`
+}
+
+// GetStatus tells clients whether the API accepts writes and new accounts (public)
+func (h *MaintenanceHandler) GetStatus(c *gin.Context) {
+	state := h.sw.State()
+	status := gin.H{"maintenance": state.Maintenance, "registration_open": !state.RegistrationDisabled}
+	if state.Maintenance {
+		message := state.Message
+		if message == "" {
+			message = maintenance.DefaultMessage
+		}
+		status["message"] = message
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// Get returns the switches with who last changed them (admin only)
+func (h *MaintenanceHandler) Get(c *gin.Context) {
+	c.JSON(http.StatusOK, h.sw.State())
+}
+
+// Set replaces the switches on this server instance (admin only)
+func (h *MaintenanceHandler) Set(c *gin.Context) {
+	var req MaintenanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(req.Message) > 500 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "message must be at most 500 characters"})
+		return
+	}
+	adminID := auth.GetUserID(c)
+	state := h.sw.Set(maintenance.State{
+		Maintenance:          req.Maintenance,
+		Message:              req.Message,
+		RegistrationDisabled: req.RegistrationDisabled,
+	}, adminID)
+	log.Printf("Maintenance mode %v, registration disabled %v, set by admin %s", state.Maintenance, state.RegistrationDisabled, adminID)
+	c.JSON(http.StatusOK, state)
+}
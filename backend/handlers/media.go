@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"liftoff/backend/media"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MediaHandler takes uploads of exercise demonstration media and serves them
+type MediaHandler struct {
+	store *media.Store
+}
+
+// NewMediaHandler creates a new media handler
+func NewMediaHandler(store *media.Store) *MediaHandler {
+	return &MediaHandler{store: store}
+}
+
+// Upload stores a form video or image sent as the multipart "file" field and
+// returns the URL to set as an exercise's or template's video_url or image_url
+func (h *MediaHandler) Upload(c *gin.Context) {
+	// Leave room for the multipart framing around the file itself
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.store.MaxBytes()+1<<20)
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": media.ErrTooLarge.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		log.Printf("Error opening upload: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store upload"})
+		return
+	}
+	defer file.Close()
+
+	name, kind, err := h.store.Save(file)
+	switch {
+	case errors.Is(err, media.ErrUnsupportedType):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+	case errors.Is(err, media.ErrTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	case err != nil:
+		log.Printf("Error storing upload: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store upload"})
+	default:
+		c.JSON(http.StatusCreated, gin.H{"url": media.URLPrefix + name, "kind": kind})
+	}
+}
+
+// Serve returns an uploaded file. Uploads never change, so they may be cached for good.
+func (h *MediaHandler) Serve(c *gin.Context) {
+	path, ok := h.store.Path(c.Param("name"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Media not found"})
+		return
+	}
+	c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	c.File(path)
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"liftoff/backend/media"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMediaUploadAndServe(t *testing.T) {
+	store, err := media.NewStore(media.Config{Dir: t.TempDir(), MaxBytes: 1 << 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewMediaHandler(store)
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/api/media", h.Upload)
+	r.GET("/api/media/:name", h.Serve)
+
+	upload := func(content []byte) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		part, _ := form.CreateFormFile("file", "squat.mp4")
+		part.Write(content)
+		form.Close()
+		req := httptest.NewRequest("POST", "/api/media", &body)
+		req.Header.Set("Content-Type", form.FormDataContentType())
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// An MP4 file starts with an ftyp box
+	mp4 := append([]byte("\x00\x00\x00\x18ftypmp42\x00\x00\x00\x00mp42isom"), make([]byte, 32)...)
+	w := upload(mp4)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("upload: got %d: %s", w.Code, w.Body)
+	}
+	var got struct {
+		URL  string `json:"url"`
+		Kind string `json:"kind"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &got)
+	if got.Kind != media.KindVideo {
+		t.Errorf("kind = %q, want video", got.Kind)
+	}
+	if _, err := media.NormalizeURL(got.URL, "video_url"); err != nil {
+		t.Errorf("uploaded URL %q cannot be linked: %v", got.URL, err)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", got.URL, nil))
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "video/mp4" || !bytes.Equal(w.Body.Bytes(), mp4) {
+		t.Errorf("serve: got %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+
+	if w := upload([]byte("<!DOCTYPE html><p>hi</p>")); w.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("html upload: got %d, want 415", w.Code)
+	}
+	if w := upload(append(mp4, make([]byte, 2<<10)...)); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("large upload: got %d, want 413", w.Code)
+	}
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/media/missing.mp4", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("missing media: got %d, want 404", w.Code)
+	}
+}
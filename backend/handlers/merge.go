@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/models"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MergeAccountRequest proves ownership of the account to merge into the caller's:
+// either its email and password, or a token signed in to it (for provider-only accounts)
+type MergeAccountRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	Token    string `json:"token"`
+}
+
+// MergeAccount folds another account the caller owns into the signed-in one.
+// The other account is deleted once its data has moved.
+func (h *AuthHandler) MergeAccount(c *gin.Context) {
+	if auth.GetAPIKeyID(c) != "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "API keys cannot merge accounts"})
+		return
+	}
+	var req MergeAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil || (req.Token == "" && (req.Email == "" || req.Password == "")) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Provide the other account's email and password, or a token signed in to it"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	var sourceID string
+	if req.Token != "" {
+		// Only a full sign-in proves ownership: read-only, guest, step-up and
+		// impersonation tokens are refused
+		claims, err := auth.VerifyAccountToken(ctx, req.Token)
+		switch {
+		case errors.Is(err, auth.ErrInvalidToken), errors.Is(err, auth.ErrTokenRevoked),
+			errors.Is(err, auth.ErrRestrictedToken), errors.Is(err, auth.ErrAccountSuspended):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Could not verify the other account"})
+			return
+		case err != nil:
+			log.Printf("Token revocation check failed: %v", err)
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Unable to verify token"})
+			return
+		}
+		sourceID = claims.UserID
+	} else {
+		// Password checks share the login lockout so this cannot be used to guess passwords
+		email := auth.NormalizeEmail(req.Email)
+		lockout := auth.GetLockoutConfig()
+		accountKey := repository.LoginAccountKey(email)
+		if until := h.loginLockedUntil(c, accountKey); !until.IsZero() {
+			setRetryAfter(c, until)
+			c.JSON(http.StatusLocked, gin.H{"error": "Account temporarily locked after too many failed login attempts"})
+			return
+		}
+		user, err := h.userRepo.GetByEmail(ctx, email)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify the other account"})
+			return
+		}
+		if user == nil || !auth.CheckPassword(req.Password, user.PasswordHash) {
+			h.recordLoginFailure(c, lockout, accountKey, lockout.MaxAccountFailures)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Could not verify the other account"})
+			return
+		}
+		if err := h.userRepo.ClearLoginFailures(ctx, accountKey); err != nil {
+			log.Printf("Error clearing login failures: %v", err)
+		}
+		if user.Suspended() {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Could not verify the other account"})
+			return
+		}
+		sourceID = user.ID
+	}
+
+	userID := auth.GetUserID(c)
+	result, ok := mergeAccounts(c, h.userRepo, userID, sourceID)
+	if !ok {
+		return
+	}
+	log.Printf("Account %s merged into %s at the user's request", sourceID, userID)
+	c.JSON(http.StatusOK, gin.H{"merge": result})
+}
+
+// MergeUsersRequest names the account to keep and the one folded into it
+type MergeUsersRequest struct {
+	TargetID string `json:"target_id" binding:"required"`
+	SourceID string `json:"source_id" binding:"required"`
+}
+
+// MergeUsers merges one user into another on their behalf, such as after a support
+// request confirmed both accounts belong to the same person (admin only)
+func (h *AdminHandler) MergeUsers(c *gin.Context) {
+	var req MergeUsersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "target_id and source_id are required"})
+		return
+	}
+	result, ok := mergeAccounts(c, h.userRepo, req.TargetID, req.SourceID)
+	if !ok {
+		return
+	}
+	log.Printf("Account %s merged into %s by admin %s", req.SourceID, req.TargetID, auth.GetUserID(c))
+	c.JSON(http.StatusOK, gin.H{"merge": result})
+}
+
+// ListDuplicates returns groups of accounts that appear to belong to the same person (admin only)
+func (h *AdminHandler) ListDuplicates(c *gin.Context) {
+	ctx := c.Request.Context()
+	users, err := h.userRepo.ListAllUsers(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list users"})
+		return
+	}
+	linked, err := h.userRepo.ListLinkedEmails(ctx)
+	if err != nil {
+		log.Printf("Error listing linked emails: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list users"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"duplicates": models.FindDuplicateAccounts(users, linked)})
+}
+
+// mergeAccounts runs the merge and writes the error response on failure
+func mergeAccounts(c *gin.Context, userRepo *repository.UserRepository, targetID, sourceID string) (*models.AccountMerge, bool) {
+	result, err := userRepo.MergeAccounts(c.Request.Context(), targetID, sourceID)
+	switch {
+	case errors.Is(err, repository.ErrMergeSameAccount):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Both accounts are the same"})
+		return nil, false
+	case errors.Is(err, repository.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return nil, false
+	case errors.Is(err, repository.ErrLegalHold):
+		c.JSON(http.StatusConflict, gin.H{"error": "The other account cannot be merged right now; contact support"})
+		return nil, false
+	case err != nil:
+		log.Printf("Error merging account %s into %s: %v", sourceID, targetID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to merge accounts"})
+		return nil, false
+	}
+	return result, true
+}
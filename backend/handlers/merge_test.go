@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/database"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMergeAccount(t *testing.T) {
+	db, err := database.NewMockDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	sqlite := db.GetSQLite()
+	userRepo := repository.NewUserRepository(nil, sqlite, true)
+
+	// A second, provider-only account with an active session newer than any demo session
+	target, err := userRepo.CreateUser(t.Context(), "demo.google@gmail.com", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sqlite.Exec(`INSERT INTO workouts (id, name, user_id) VALUES ('w-google', 'Google Push', ?)`, target.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sqlite.Exec(`INSERT INTO workout_sessions (id, workout_id, user_id, started_at, is_active) VALUES ('s-google', 'w-google', ?, ?, 1)`,
+		target.ID, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sqlite.Exec(`UPDATE workout_sessions SET is_active = 1, ended_at = NULL WHERE id = (SELECT id FROM workout_sessions WHERE user_id = ? LIMIT 1)`, database.DemoUserID); err != nil {
+		t.Fatal(err)
+	}
+	var demoWorkouts int
+	if err := sqlite.QueryRow(`SELECT COUNT(*) FROM workouts WHERE user_id = ?`, database.DemoUserID).Scan(&demoWorkouts); err != nil || demoWorkouts == 0 {
+		t.Fatalf("demo workouts = %d, %v", demoWorkouts, err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/merge", func(c *gin.Context) { c.Set(auth.UserIDKey, target.ID) }, NewAuthHandler(userRepo).MergeAccount)
+	merge := func(body map[string]string) *httptest.ResponseRecorder {
+		raw, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPost, "/merge", bytes.NewReader(raw))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// Tokens short of a full sign-in do not prove ownership of the other account
+	restricted := map[string]func() (string, time.Time, error){
+		"read-only": func() (string, time.Time, error) {
+			return auth.GenerateScopedToken(database.DemoUserID, database.DemoUserEmail, auth.ScopeRead, time.Hour)
+		},
+		"guest": func() (string, time.Time, error) {
+			return auth.GenerateGuestToken(database.DemoUserID, database.DemoUserEmail, auth.HashToken("device"))
+		},
+		"impersonation": func() (string, time.Time, error) {
+			return auth.GenerateImpersonationToken(database.DemoUserID, database.DemoUserEmail, target.ID)
+		},
+		"step-up": func() (string, time.Time, error) {
+			return auth.GenerateReauthToken(database.DemoUserID, "session")
+		},
+	}
+	for name, generate := range restricted {
+		token, _, err := generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if w := merge(map[string]string{"token": token}); w.Code != http.StatusUnauthorized {
+			t.Errorf("%s token: got %d, want 401", name, w.Code)
+		}
+	}
+	var n int
+	if err := sqlite.QueryRow(`SELECT COUNT(*) FROM users WHERE id = ?`, database.DemoUserID).Scan(&n); err != nil || n != 1 {
+		t.Fatalf("restricted token merged the account: %d, %v", n, err)
+	}
+
+	if w := merge(map[string]string{"email": database.DemoUserEmail, "password": "wrong"}); w.Code != http.StatusUnauthorized {
+		t.Fatalf("wrong password: got %d", w.Code)
+	}
+	if w := merge(map[string]string{"email": "demo.google@gmail.com", "password": "x"}); w.Code != http.StatusUnauthorized {
+		t.Fatalf("passwordless account: got %d", w.Code)
+	}
+	w := merge(map[string]string{"email": database.DemoUserEmail, "password": database.DemoUserPassword})
+	if w.Code != http.StatusOK {
+		t.Fatalf("merge: got %d %s", w.Code, w.Body)
+	}
+	var resp struct {
+		Merge struct {
+			MergedUserID  string           `json:"merged_user_id"`
+			Moved         map[string]int64 `json:"moved"`
+			EndedSessions int64            `json:"ended_sessions"`
+		} `json:"merge"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Merge.MergedUserID != database.DemoUserID || resp.Merge.Moved["workouts"] != int64(demoWorkouts) || resp.Merge.EndedSessions != 1 {
+		t.Errorf("merge = %+v", resp.Merge)
+	}
+
+	if err := sqlite.QueryRow(`SELECT COUNT(*) FROM users WHERE id = ?`, database.DemoUserID).Scan(&n); err != nil || n != 0 {
+		t.Errorf("source user remains: %d, %v", n, err)
+	}
+	if err := sqlite.QueryRow(`SELECT COUNT(*) FROM workouts WHERE user_id = ?`, target.ID).Scan(&n); err != nil || n != demoWorkouts+1 {
+		t.Errorf("target workouts = %d, %v", n, err)
+	}
+	var activeID string
+	if err := sqlite.QueryRow(`SELECT id FROM workout_sessions WHERE user_id = ? AND is_active = 1`, target.ID).Scan(&activeID); err != nil || activeID != "s-google" {
+		t.Errorf("active session = %q, %v", activeID, err)
+	}
+
+	// The provider-only account can now sign in with the merged password
+	user, err := userRepo.GetByEmail(t.Context(), "demo.google@gmail.com")
+	if err != nil || user == nil || !auth.CheckPassword(database.DemoUserPassword, user.PasswordHash) {
+		t.Errorf("merged password not carried over: %v", err)
+	}
+
+	if w := merge(map[string]string{"email": "demo.google@gmail.com", "password": database.DemoUserPassword}); w.Code != http.StatusBadRequest {
+		t.Errorf("self merge: got %d", w.Code)
+	}
+}
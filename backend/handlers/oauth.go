@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/models"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+const oauthStateCookie = "liftoff_oauth_state"
+
+// GoogleLogin redirects the browser to Google's consent screen
+func (h *AuthHandler) GoogleLogin(c *gin.Context) {
+	provider, err := auth.GetGoogleOAuthProvider()
+	if err != nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Google sign-in is not configured"})
+		return
+	}
+
+	state, err := repository.GenerateSecureToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start sign-in"})
+		return
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oauthStateCookie, state, 600, "/api/auth", "", c.Request.TLS != nil, true)
+	c.Redirect(http.StatusFound, provider.AuthCodeURL(state))
+}
+
+// GoogleCallback completes the Google flow, links the identity and issues a JWT
+func (h *AuthHandler) GoogleCallback(c *gin.Context) {
+	provider, err := auth.GetGoogleOAuthProvider()
+	if err != nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Google sign-in is not configured"})
+		return
+	}
+
+	expectedState, err := c.Cookie(oauthStateCookie)
+	if err != nil || expectedState == "" || expectedState != c.Query("state") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sign-in state"})
+		return
+	}
+	c.SetCookie(oauthStateCookie, "", -1, "/api/auth", "", c.Request.TLS != nil, true)
+
+	code := c.Query("code")
+	if code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Authorization code is required"})
+		return
+	}
+
+	token, err := provider.Exchange(c.Request.Context(), code)
+	if err != nil {
+		log.Printf("Google exchange error: %v", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Google sign-in failed"})
+		return
+	}
+
+	info, err := provider.UserInfo(c.Request.Context(), token.AccessToken)
+	if err != nil {
+		log.Printf("Google userinfo error: %v", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Google sign-in failed"})
+		return
+	}
+
+	user, err := h.oauthSignIn(c.Request.Context(), provider.Name, info)
+	if err != nil {
+		log.Printf("Google sign-in error: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	redirectSignedIn(c, user)
+}
+
+// redirectSignedIn issues a JWT and sends the browser back to the frontend's OAuth
+// callback page. The token is handed over in the URL fragment so it never reaches server logs.
+func redirectSignedIn(c *gin.Context, user *models.User) {
+	if user.Suspended() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This account has been suspended"})
+		return
+	}
+	tokenString, expiresAt, err := auth.GenerateToken(user.ID, user.Email, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	fragment := url.Values{}
+	if csrf := auth.SetSessionCookie(c, tokenString, expiresAt); csrf != "" {
+		fragment.Set("csrfToken", csrf)
+	}
+	if auth.GetCookieConfig().ReturnsToken() {
+		fragment.Set("token", tokenString)
+	}
+	fragment.Set("expiresAt", expiresAt.Format("2006-01-02T15:04:05Z07:00"))
+	c.Redirect(http.StatusFound, frontendURL()+"/oauth/callback#"+fragment.Encode())
+}
+
+// AppleSignInRequest is the request body for Sign in with Apple.
+// Either the authorization code (verified server-side via Apple's token endpoint)
+// or the identity token from the native/JS flow must be provided.
+type AppleSignInRequest struct {
+	Code    string `json:"code"`
+	IDToken string `json:"idToken"`
+	Nonce   string `json:"nonce"`
+}
+
+// AppleSignIn verifies an Apple identity, links it to a user and issues a JWT
+func (h *AuthHandler) AppleSignIn(c *gin.Context) {
+	provider, err := auth.GetAppleProvider()
+	if err != nil {
+		if !errors.Is(err, auth.ErrOAuthNotConfigured) {
+			log.Printf("Apple config error: %v", err)
+		}
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Apple sign-in is not configured"})
+		return
+	}
+
+	var req AppleSignInRequest
+	if err := c.ShouldBindJSON(&req); err != nil || (req.Code == "" && req.IDToken == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Authorization code or identity token is required"})
+		return
+	}
+
+	idToken := req.IDToken
+	if req.Code != "" {
+		token, err := provider.Exchange(c.Request.Context(), req.Code)
+		if err != nil {
+			log.Printf("Apple exchange error: %v", err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Apple sign-in failed"})
+			return
+		}
+		idToken = token.IDToken
+	}
+
+	info, err := provider.VerifyIdentityToken(c.Request.Context(), idToken, req.Nonce)
+	if err != nil {
+		log.Printf("Apple token verification error: %v", err)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid Apple identity token"})
+		return
+	}
+
+	user, err := h.oauthSignIn(c.Request.Context(), auth.ProviderApple, info)
+	if err != nil {
+		log.Printf("Apple sign-in error: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokenString, expiresAt, err := auth.GenerateToken(user.ID, user.Email, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	respondAuth(c, http.StatusOK, user, tokenString, expiresAt)
+}
+
+// oauthSignIn resolves a provider identity to a Liftoff user.
+// Existing links win; otherwise a verified email is linked to (or creates) an account.
+func (h *AuthHandler) oauthSignIn(ctx context.Context, provider string, info *auth.OAuthUserInfo) (*models.User, error) {
+	user, err := h.userRepo.GetByOAuthIdentity(ctx, provider, info.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if user != nil {
+		return user, nil
+	}
+
+	if info.Email == "" || !info.EmailVerified || !emailRegex.MatchString(info.Email) {
+		return nil, errors.New("a verified email address is required to sign in")
+	}
+
+	user, err = h.userRepo.GetByEmail(ctx, info.Email)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		if !h.registrationOpen() {
+			return nil, errors.New("new registrations are closed right now")
+		}
+		// Accounts created through a provider have no password until one is reset
+		user, err = h.userRepo.CreateUser(ctx, info.Email, "")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := h.userRepo.LinkOAuthIdentity(ctx, user.ID, provider, info.Subject, info.Email); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// frontendURL returns the configured frontend base URL
+func frontendURL() string {
+	if u := os.Getenv("FRONTEND_URL"); u != "" {
+		return u
+	}
+	return "http://localhost:5173"
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+const oidcNonceCookie = "liftoff_oidc_nonce"
+
+// OIDCConfig tells the frontend whether to offer single sign-on and how to label it
+func (h *AuthHandler) OIDCConfig(c *gin.Context) {
+	provider, err := auth.GetOIDCProvider(c.Request.Context())
+	if err != nil {
+		if !errors.Is(err, auth.ErrOAuthNotConfigured) {
+			log.Printf("OIDC discovery error: %v", err)
+		}
+		c.JSON(http.StatusOK, gin.H{"enabled": false})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"enabled": true, "name": provider.DisplayName})
+}
+
+// OIDCLogin redirects the browser to the deployment's OpenID Connect provider
+func (h *AuthHandler) OIDCLogin(c *gin.Context) {
+	provider, ok := oidcProvider(c)
+	if !ok {
+		return
+	}
+
+	state, err := repository.GenerateSecureToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start sign-in"})
+		return
+	}
+	nonce, err := repository.GenerateSecureToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start sign-in"})
+		return
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oauthStateCookie, state, 600, "/api/auth", "", c.Request.TLS != nil, true)
+	c.SetCookie(oidcNonceCookie, nonce, 600, "/api/auth", "", c.Request.TLS != nil, true)
+	c.Redirect(http.StatusFound, provider.AuthCodeURL(state, nonce))
+}
+
+// OIDCCallback completes the OpenID Connect flow: it verifies the ID token, maps its
+// claims to a user (linking or creating one by verified email) and issues a JWT
+func (h *AuthHandler) OIDCCallback(c *gin.Context) {
+	provider, ok := oidcProvider(c)
+	if !ok {
+		return
+	}
+
+	expectedState, err := c.Cookie(oauthStateCookie)
+	if err != nil || expectedState == "" || expectedState != c.Query("state") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sign-in state"})
+		return
+	}
+	nonce, err := c.Cookie(oidcNonceCookie)
+	if err != nil || nonce == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sign-in state"})
+		return
+	}
+	c.SetCookie(oauthStateCookie, "", -1, "/api/auth", "", c.Request.TLS != nil, true)
+	c.SetCookie(oidcNonceCookie, "", -1, "/api/auth", "", c.Request.TLS != nil, true)
+
+	if e := c.Query("error"); e != "" {
+		log.Printf("OIDC provider returned error: %s (%s)", e, c.Query("error_description"))
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Single sign-on was cancelled or denied"})
+		return
+	}
+	code := c.Query("code")
+	if code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Authorization code is required"})
+		return
+	}
+
+	token, err := provider.Exchange(c.Request.Context(), code)
+	if err != nil {
+		log.Printf("OIDC exchange error: %v", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Single sign-on failed"})
+		return
+	}
+
+	info, err := provider.Identity(c.Request.Context(), token, nonce)
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrOIDCDomainNotAllowed):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, auth.ErrInvalidIDToken):
+			log.Printf("OIDC token verification error: %v", err)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid identity token"})
+		default:
+			log.Printf("OIDC identity error: %v", err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Single sign-on failed"})
+		}
+		return
+	}
+
+	user, err := h.oauthSignIn(c.Request.Context(), provider.Name, info)
+	if err != nil {
+		log.Printf("OIDC sign-in error: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	redirectSignedIn(c, user)
+}
+
+// oidcProvider loads the provider, answering 501 when single sign-on is not
+// configured and 502 when the issuer cannot be discovered
+func oidcProvider(c *gin.Context) (*auth.OIDCProvider, bool) {
+	provider, err := auth.GetOIDCProvider(c.Request.Context())
+	switch {
+	case err == nil:
+		return provider, true
+	case errors.Is(err, auth.ErrOAuthNotConfigured):
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Single sign-on is not configured"})
+	default:
+		log.Printf("OIDC discovery error: %v", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Single sign-on provider is unavailable"})
+	}
+	return nil, false
+}
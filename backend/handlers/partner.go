@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/models"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PartnerHandler runs shared sessions between two training partners. The host
+// invites a partner into their active session; joining copies the host's workout
+// to the partner and starts their own session of it, so each logs their own sets.
+type PartnerHandler struct {
+	partnerRepo *repository.PartnerRepository
+	sessionRepo *repository.SessionRepository
+	workoutRepo *repository.WorkoutRepository
+	userRepo    *repository.UserRepository
+}
+
+// NewPartnerHandler creates a new partner handler
+func NewPartnerHandler(partnerRepo *repository.PartnerRepository, sessionRepo *repository.SessionRepository, workoutRepo *repository.WorkoutRepository, userRepo *repository.UserRepository) *PartnerHandler {
+	return &PartnerHandler{partnerRepo: partnerRepo, sessionRepo: sessionRepo, workoutRepo: workoutRepo, userRepo: userRepo}
+}
+
+// InvitePartnerRequest names the user to train with
+type InvitePartnerRequest struct {
+	Email string `json:"email" binding:"required"`
+}
+
+// Invite invites another user into the caller's active session
+func (h *PartnerHandler) Invite(c *gin.Context) {
+	var req InvitePartnerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Partner email is required"})
+		return
+	}
+	ctx := c.Request.Context()
+	userID := auth.GetUserID(c)
+	partner, err := h.userRepo.GetByEmail(ctx, auth.NormalizeEmail(req.Email))
+	if err != nil {
+		log.Printf("Error looking up partner: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to invite partner"})
+		return
+	}
+	if partner == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if partner.ID == userID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot invite yourself"})
+		return
+	}
+
+	link, err := h.partnerRepo.CreateInvite(ctx, userID, c.Param("id"), partner.ID)
+	switch {
+	case errors.Is(err, repository.ErrSessionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Active session not found"})
+		return
+	case errors.Is(err, repository.ErrPartnerSessionExists):
+		c.JSON(http.StatusConflict, gin.H{"error": "This session already has a partner"})
+		return
+	case err != nil:
+		log.Printf("Error creating partner invite: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to invite partner"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"partner_session": link})
+}
+
+// ListInvites returns partner invites waiting for the caller
+func (h *PartnerHandler) ListInvites(c *gin.Context) {
+	invites, err := h.partnerRepo.ListInvites(c.Request.Context(), auth.GetUserID(c))
+	if err != nil {
+		log.Printf("Error listing partner invites: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list invites"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"invites": invites})
+}
+
+// Accept joins an invite: the host's workout is copied to the caller and a session
+// of it is started, linked to the host's
+func (h *PartnerHandler) Accept(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := auth.GetUserID(c)
+	link, err := h.partnerRepo.Get(ctx, userID, c.Param("id"))
+	if err != nil && !errors.Is(err, repository.ErrPartnerSessionNotFound) {
+		log.Printf("Error getting partner invite: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to join session"})
+		return
+	}
+	if link == nil || link.PartnerID != userID || link.Status != models.PartnerSessionInvited {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invite not found"})
+		return
+	}
+
+	host, err := h.sessionRepo.GetSession(ctx, link.HostSessionID)
+	if err != nil || !host.IsActive {
+		c.JSON(http.StatusConflict, gin.H{"error": "Your partner has already finished this session"})
+		return
+	}
+	active, err := h.sessionRepo.GetActiveSession(ctx, userID)
+	if err != nil {
+		log.Printf("Error getting active session: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to join session"})
+		return
+	}
+	if active != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Finish your current session before joining"})
+		return
+	}
+
+	shared, err := h.workoutRepo.ShareWorkout(ctx, link.HostID, host.WorkoutID)
+	if err != nil {
+		log.Printf("Error reading partner workout: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to join session"})
+		return
+	}
+	workout, _, err := h.workoutRepo.ImportSharedWorkout(ctx, userID, shared)
+	if err != nil {
+		log.Printf("Error copying partner workout: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to join session"})
+		return
+	}
+	session, err := h.sessionRepo.CreateSessionWithExercises(ctx, userID, workout.ID)
+	if err != nil {
+		log.Printf("Error starting partner session: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to join session"})
+		return
+	}
+	if err := h.partnerRepo.Join(ctx, userID, link.ID, session.ID); err != nil {
+		// The invite was withdrawn meanwhile; the session carries on without a partner
+		log.Printf("Error joining partner session %s: %v", link.ID, err)
+		c.JSON(http.StatusConflict, gin.H{"error": "The invite was withdrawn", "session": session})
+		return
+	}
+	session.Partner = &models.SessionPartner{LinkID: link.ID, UserID: link.HostID, Email: link.HostEmail, SessionID: link.HostSessionID}
+	c.JSON(http.StatusCreated, session)
+}
+
+// DeleteInvite withdraws (host) or declines (partner) an invite that has not been joined
+func (h *PartnerHandler) DeleteInvite(c *gin.Context) {
+	err := h.partnerRepo.DeleteInvite(c.Request.Context(), auth.GetUserID(c), c.Param("id"))
+	if errors.Is(err, repository.ErrPartnerSessionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invite not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("Error deleting partner invite: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove invite"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Invite removed"})
+}
+
+// GetPartnerSession returns the partner's side of a partner workout with their sets
+// and completions. Clients poll it during the session to follow the partner live.
+func (h *PartnerHandler) GetPartnerSession(c *gin.Context) {
+	ctx := c.Request.Context()
+	partner, err := h.partnerRepo.GetPartner(ctx, auth.GetUserID(c), c.Param("id"))
+	if err != nil {
+		log.Printf("Error getting session partner: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get partner session"})
+		return
+	}
+	if partner == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not a partner session"})
+		return
+	}
+	session, err := h.sessionRepo.GetSessionWithExercises(ctx, partner.UserID, partner.SessionID)
+	if err != nil {
+		// The partner may have deleted the session or their account
+		c.JSON(http.StatusNotFound, gin.H{"error": "Partner session not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"partner": partner, "session": session})
+}
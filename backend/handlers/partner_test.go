@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/database"
+	"liftoff/backend/models"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestPartnerSession(t *testing.T) {
+	db, err := database.NewMockDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	sqlite := db.GetSQLite()
+	userRepo := repository.NewUserRepository(nil, sqlite, true)
+	sessionRepo := repository.NewSessionRepository(nil, sqlite, true)
+	workoutRepo := repository.NewWorkoutRepository(nil, sqlite, true)
+	partnerRepo := repository.NewPartnerRepository(nil, sqlite, true)
+
+	partnerUser, err := userRepo.CreateUser(t.Context(), "partner@example.com", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var workoutID string
+	if err := sqlite.QueryRow(`SELECT w.id FROM workouts w WHERE w.user_id = ? AND EXISTS (SELECT 1 FROM exercises e WHERE e.workout_id = w.id) LIMIT 1`,
+		database.DemoUserID).Scan(&workoutID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sqlite.Exec(`UPDATE workout_sessions SET is_active = 0 WHERE user_id = ?`, database.DemoUserID); err != nil {
+		t.Fatal(err)
+	}
+	hostSession, err := sessionRepo.CreateSessionWithExercises(t.Context(), database.DemoUserID, workoutID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set(auth.UserIDKey, c.GetHeader("X-Test-User")) })
+	h := NewPartnerHandler(partnerRepo, sessionRepo, workoutRepo, userRepo)
+	r.POST("/sessions/:id/partner", h.Invite)
+	r.GET("/sessions/:id/partner", h.GetPartnerSession)
+	r.GET("/partner-invites", h.ListInvites)
+	r.POST("/partner-invites/:id/accept", h.Accept)
+	r.DELETE("/partner-invites/:id", h.DeleteInvite)
+	do := func(method, path, userID string, body interface{}) *httptest.ResponseRecorder {
+		raw, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, bytes.NewReader(raw))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Test-User", userID)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do(http.MethodPost, "/sessions/"+hostSession.ID+"/partner", partnerUser.ID, gin.H{"email": database.DemoUserEmail}); w.Code != http.StatusNotFound {
+		t.Fatalf("invite into someone else's session: got %d", w.Code)
+	}
+	if w := do(http.MethodPost, "/sessions/"+hostSession.ID+"/partner", database.DemoUserID, gin.H{"email": database.DemoUserEmail}); w.Code != http.StatusBadRequest {
+		t.Fatalf("self invite: got %d", w.Code)
+	}
+	w := do(http.MethodPost, "/sessions/"+hostSession.ID+"/partner", database.DemoUserID, gin.H{"email": "Partner@Example.com"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("invite: got %d %s", w.Code, w.Body)
+	}
+	var invite struct {
+		Link models.PartnerSession `json:"partner_session"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &invite); err != nil {
+		t.Fatal(err)
+	}
+	if w := do(http.MethodPost, "/sessions/"+hostSession.ID+"/partner", database.DemoUserID, gin.H{"email": "partner@example.com"}); w.Code != http.StatusConflict {
+		t.Fatalf("second invite: got %d", w.Code)
+	}
+
+	var invites struct {
+		Invites []models.PartnerSession `json:"invites"`
+	}
+	w = do(http.MethodGet, "/partner-invites", partnerUser.ID, nil)
+	if err := json.Unmarshal(w.Body.Bytes(), &invites); err != nil || len(invites.Invites) != 1 || invites.Invites[0].HostEmail != database.DemoUserEmail {
+		t.Fatalf("invites = %s", w.Body)
+	}
+	if w := do(http.MethodPost, "/partner-invites/"+invite.Link.ID+"/accept", database.DemoUserID, nil); w.Code != http.StatusNotFound {
+		t.Fatalf("host accepting own invite: got %d", w.Code)
+	}
+	if w := do(http.MethodGet, "/sessions/"+hostSession.ID+"/partner", database.DemoUserID, nil); w.Code != http.StatusNotFound {
+		t.Fatalf("partner progress before joining: got %d", w.Code)
+	}
+
+	w = do(http.MethodPost, "/partner-invites/"+invite.Link.ID+"/accept", partnerUser.ID, nil)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("accept: got %d %s", w.Code, w.Body)
+	}
+	var partnerSession models.WorkoutSession
+	if err := json.Unmarshal(w.Body.Bytes(), &partnerSession); err != nil {
+		t.Fatal(err)
+	}
+	if partnerSession.Partner == nil || partnerSession.Partner.SessionID != hostSession.ID || len(partnerSession.Exercises) != len(hostSession.Exercises) {
+		t.Fatalf("partner session = %s", w.Body)
+	}
+
+	// Each side sees the other's completions
+	if err := sessionRepo.CompleteExerciseSet(t.Context(), partnerUser.ID, partnerSession.Exercises[0].ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	var progress struct {
+		Partner models.SessionPartner `json:"partner"`
+		Session models.WorkoutSession `json:"session"`
+	}
+	w = do(http.MethodGet, "/sessions/"+hostSession.ID+"/partner", database.DemoUserID, nil)
+	if err := json.Unmarshal(w.Body.Bytes(), &progress); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("host view: got %d %s", w.Code, w.Body)
+	}
+	if progress.Partner.Email != "partner@example.com" || progress.Session.ID != partnerSession.ID || !progress.Session.Exercises[0].Sets[0].Completed {
+		t.Errorf("host view = %s", w.Body)
+	}
+	w = do(http.MethodGet, "/sessions/"+partnerSession.ID+"/partner", partnerUser.ID, nil)
+	if err := json.Unmarshal(w.Body.Bytes(), &progress); err != nil || progress.Session.ID != hostSession.ID {
+		t.Errorf("partner view: got %d %s", w.Code, w.Body)
+	}
+
+	// Joined links are history, not invites
+	if w := do(http.MethodDelete, "/partner-invites/"+invite.Link.ID, partnerUser.ID, nil); w.Code != http.StatusNotFound {
+		t.Errorf("delete joined link: got %d", w.Code)
+	}
+	partners, err := partnerRepo.PartnersBySession(t.Context(), database.DemoUserID)
+	if err != nil || partners[hostSession.ID] == nil || partners[hostSession.ID].SessionID != partnerSession.ID {
+		t.Errorf("partners = %v, %v", partners, err)
+	}
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/models"
+	"liftoff/backend/repository"
+	"liftoff/backend/units"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PreferencesHandler reads and changes the signed-in user's display preferences
+type PreferencesHandler struct {
+	prefsRepo *repository.PreferencesRepository
+}
+
+// NewPreferencesHandler creates a new preferences handler
+func NewPreferencesHandler(prefsRepo *repository.PreferencesRepository) *PreferencesHandler {
+	return &PreferencesHandler{prefsRepo: prefsRepo}
+}
+
+// UpdatePreferencesRequest is the request body for changing preferences
+type UpdatePreferencesRequest struct {
+	Units string `json:"units" binding:"required"`
+}
+
+// GetPreferences returns the user's preferences
+func (h *PreferencesHandler) GetPreferences(c *gin.Context) {
+	prefs, err := h.prefsRepo.GetPreferences(c.Request.Context(), auth.GetUserID(c))
+	if err != nil {
+		log.Printf("Error fetching preferences: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch preferences"})
+		return
+	}
+	c.JSON(http.StatusOK, prefs)
+}
+
+// UpdatePreferences switches the user between imperial and metric units. Logged
+// weights are not touched: they are stored in pounds and converted per request.
+func (h *PreferencesHandler) UpdatePreferences(c *gin.Context) {
+	var req UpdatePreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "units is required"})
+		return
+	}
+	prefs := &models.UserPreferences{Units: strings.ToLower(strings.TrimSpace(req.Units))}
+	if !units.Valid(prefs.Units) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "units must be imperial or metric"})
+		return
+	}
+	if err := h.prefsRepo.SetPreferences(c.Request.Context(), auth.GetUserID(c), prefs); err != nil {
+		log.Printf("Error saving preferences: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save preferences"})
+		return
+	}
+	c.JSON(http.StatusOK, prefs)
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/database"
+	"liftoff/backend/models"
+	"liftoff/backend/repository"
+	"liftoff/backend/units"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestPreferences(t *testing.T) {
+	db, err := database.NewMockDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	prefsRepo := repository.NewPreferencesRepository(nil, db.GetSQLite(), true)
+	workoutRepo := repository.NewWorkoutRepository(nil, db.GetSQLite(), true)
+	h := NewPreferencesHandler(prefsRepo)
+
+	workout, err := workoutRepo.CreateWorkout(t.Context(), database.DemoUserID, "Squat Day", models.WorkoutTypeStrength, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	squat := &models.Exercise{Name: "Squat", Sets: 3, Reps: 5, Weight: 140, WorkoutID: workout.ID}
+	if err := workoutRepo.CreateExercise(t.Context(), database.DemoUserID, squat); err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set(auth.UserIDKey, database.DemoUserID) }, units.Middleware(prefsRepo.Units))
+	r.GET("/preferences", h.GetPreferences)
+	r.PUT("/preferences", h.UpdatePreferences)
+	r.GET("/exercises/:id/warmup", NewWarmupHandler(workoutRepo).Warmup)
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	var prefs models.UserPreferences
+	w := do(http.MethodGet, "/preferences", "")
+	if err := json.Unmarshal(w.Body.Bytes(), &prefs); err != nil || prefs.Units != units.Imperial || prefs.UpdatedAt != nil {
+		t.Fatalf("default preferences: %d %s", w.Code, w.Body)
+	}
+	if w := do(http.MethodPut, "/preferences", `{"units": "stone"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown units: got %d, want 400", w.Code)
+	}
+	w = do(http.MethodPut, "/preferences", `{"units": "Metric"}`)
+	if err := json.Unmarshal(w.Body.Bytes(), &prefs); w.Code != http.StatusOK || err != nil || prefs.Units != units.Metric || prefs.UpdatedAt == nil {
+		t.Fatalf("switching to metric: %d %s", w.Code, w.Body)
+	}
+
+	// Stored pounds come back as kilograms, and round_to is taken in kilograms
+	w = do(http.MethodGet, "/exercises/"+squat.ID+"/warmup?round_to=2.5", "")
+	var body struct {
+		WorkingWeight float64 `json:"working_weight"`
+		Sets          []models.WarmupSet
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); w.Code != http.StatusOK || err != nil || w.Header().Get(units.Header) != "kg" {
+		t.Fatalf("metric warm-up: %d %s", w.Code, w.Body)
+	}
+	if body.WorkingWeight != 63.5 || len(body.Sets) != 3 || body.Sets[0].Weight != 25 || body.Sets[2].Weight != 50 {
+		t.Errorf("metric warm-up = %+v", body)
+	}
+	stored, err := workoutRepo.GetUserExercise(t.Context(), database.DemoUserID, squat.ID)
+	if err != nil || stored.Weight != 140 {
+		t.Errorf("stored weight changed: %+v, %v", stored, err)
+	}
+}
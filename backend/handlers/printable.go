@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/models"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PrintableHandler lays out workouts as paper log sheets for the gym floor
+type PrintableHandler struct {
+	workoutRepo  *repository.WorkoutRepository
+	taxonomyRepo *repository.TaxonomyRepository
+}
+
+// NewPrintableHandler creates a new printable handler
+func NewPrintableHandler(workoutRepo *repository.WorkoutRepository, taxonomyRepo *repository.TaxonomyRepository) *PrintableHandler {
+	return &PrintableHandler{workoutRepo: workoutRepo, taxonomyRepo: taxonomyRepo}
+}
+
+// Printable returns one of the user's workouts as a log sheet layout with blank
+// set grids for ?sessions= sessions, for the frontend or a PDF renderer to draw
+func (h *PrintableHandler) Printable(c *gin.Context) {
+	sessions := models.DefaultPrintableSessions
+	if raw := c.Query("sessions"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > models.MaxPrintableSessions {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "sessions must be between 1 and " + strconv.Itoa(models.MaxPrintableSessions)})
+			return
+		}
+		sessions = n
+	}
+
+	ctx := c.Request.Context()
+	userID := auth.GetUserID(c)
+	workout, err := h.workoutRepo.GetWorkout(ctx, userID, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Workout not found"})
+		return
+	}
+	tax, err := h.taxonomyRepo.Load(ctx)
+	if err != nil {
+		log.Printf("Error loading taxonomy: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build log sheet"})
+		return
+	}
+	notes, err := h.workoutRepo.LastSessionNotes(ctx, userID, workout.ID)
+	if err != nil {
+		log.Printf("Error loading notes for printable workout: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build log sheet"})
+		return
+	}
+
+	classify := func(name string) (string, string) {
+		library := h.workoutRepo.LibraryExerciseName(name)
+		if library == "" {
+			return "", ""
+		}
+		category, muscleGroup := tax.Classify(library)
+		if category == nil {
+			return "", ""
+		}
+		if muscleGroup == nil {
+			return category.Name, ""
+		}
+		return category.Name, muscleGroup.Name
+	}
+	c.JSON(http.StatusOK, models.NewPrintableWorkout(workout, sessions, classify, notes, time.Now().UTC()))
+}
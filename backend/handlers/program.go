@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/models"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProgramHandler manages multi-week training programs and enrollment in them
+type ProgramHandler struct {
+	programRepo *repository.ProgramRepository
+}
+
+// NewProgramHandler creates a new program handler
+func NewProgramHandler(programRepo *repository.ProgramRepository) *ProgramHandler {
+	return &ProgramHandler{programRepo: programRepo}
+}
+
+// ProgramRequest is the request body for creating or replacing a program:
+// the workouts trained each week, in order, and one progression per week
+type ProgramRequest struct {
+	Name        string                `json:"name" binding:"required"`
+	Description string                `json:"description"`
+	WorkoutIDs  []string              `json:"workout_ids"`
+	Weeks       []*models.ProgramWeek `json:"weeks"`
+}
+
+func (req ProgramRequest) program(userID string) *models.Program {
+	p := &models.Program{UserID: userID, Name: req.Name, Description: req.Description, Weeks: req.Weeks}
+	for _, id := range req.WorkoutIDs {
+		p.Workouts = append(p.Workouts, &models.ProgramWorkout{WorkoutID: id})
+	}
+	return p
+}
+
+// GetPrograms lists the user's programs
+func (h *ProgramHandler) GetPrograms(c *gin.Context) {
+	programs, err := h.programRepo.GetPrograms(c.Request.Context(), auth.GetUserID(c))
+	if err != nil {
+		log.Printf("Error fetching programs: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch programs"})
+		return
+	}
+	c.JSON(http.StatusOK, programs)
+}
+
+// GetProgram returns one program with its workouts and weeks
+func (h *ProgramHandler) GetProgram(c *gin.Context) {
+	program, err := h.programRepo.GetProgram(c.Request.Context(), auth.GetUserID(c), c.Param("id"))
+	if err != nil {
+		programError(c, err, "fetch program")
+		return
+	}
+	c.JSON(http.StatusOK, program)
+}
+
+// CreateProgram saves a new program built from the user's workouts
+func (h *ProgramHandler) CreateProgram(c *gin.Context) {
+	var req ProgramRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Program name is required"})
+		return
+	}
+	p := req.program(auth.GetUserID(c))
+	if err := p.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	program, err := h.programRepo.CreateProgram(c.Request.Context(), p)
+	if err != nil {
+		programError(c, err, "create program")
+		return
+	}
+	c.JSON(http.StatusCreated, program)
+}
+
+// UpdateProgram replaces a program's name, description, workouts and weeks
+func (h *ProgramHandler) UpdateProgram(c *gin.Context) {
+	var req ProgramRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Program name is required"})
+		return
+	}
+	p := req.program(auth.GetUserID(c))
+	p.ID = c.Param("id")
+	if err := p.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	program, err := h.programRepo.UpdateProgram(c.Request.Context(), p)
+	if err != nil {
+		programError(c, err, "update program")
+		return
+	}
+	c.JSON(http.StatusOK, program)
+}
+
+// DeleteProgram deletes a program and any enrollment in it
+func (h *ProgramHandler) DeleteProgram(c *gin.Context) {
+	if err := h.programRepo.DeleteProgram(c.Request.Context(), auth.GetUserID(c), c.Param("id")); err != nil {
+		programError(c, err, "delete program")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Program deleted"})
+}
+
+// Enroll starts the user on week one of a program
+func (h *ProgramHandler) Enroll(c *gin.Context) {
+	enrollment, err := h.programRepo.Enroll(c.Request.Context(), auth.GetUserID(c), c.Param("id"))
+	if err != nil {
+		programError(c, err, "enroll in program")
+		return
+	}
+	c.JSON(http.StatusCreated, enrollment)
+}
+
+// GetEnrollment returns the user's place in the program they are enrolled in
+func (h *ProgramHandler) GetEnrollment(c *gin.Context) {
+	enrollment, err := h.programRepo.GetEnrollment(c.Request.Context(), auth.GetUserID(c))
+	if err != nil {
+		programError(c, err, "fetch program enrollment")
+		return
+	}
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// AdvanceWeek moves the user on to the next week of their program
+func (h *ProgramHandler) AdvanceWeek(c *gin.Context) {
+	enrollment, err := h.programRepo.AdvanceWeek(c.Request.Context(), auth.GetUserID(c))
+	if err != nil {
+		programError(c, err, "advance program week")
+		return
+	}
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// LeaveProgram ends the user's enrollment early
+func (h *ProgramHandler) LeaveProgram(c *gin.Context) {
+	if err := h.programRepo.LeaveProgram(c.Request.Context(), auth.GetUserID(c)); err != nil {
+		programError(c, err, "leave program")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Left program"})
+}
+
+// Today returns the workout the user trains next in their program
+func (h *ProgramHandler) Today(c *gin.Context) {
+	today, err := h.programRepo.GetToday(c.Request.Context(), auth.GetUserID(c))
+	if err != nil {
+		programError(c, err, "fetch today's workout")
+		return
+	}
+	c.JSON(http.StatusOK, today)
+}
+
+// programError maps repository errors to responses; action names the operation for logs
+func programError(c *gin.Context, err error, action string) {
+	switch {
+	case errors.Is(err, repository.ErrProgramNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Program not found"})
+	case errors.Is(err, repository.ErrNotEnrolled):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not enrolled in a program"})
+	case errors.Is(err, repository.ErrWorkoutNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Workout not found"})
+	case errors.Is(err, repository.ErrWeekAdvanced):
+		c.JSON(http.StatusConflict, gin.H{"error": "Program week was already advanced"})
+	default:
+		log.Printf("Error trying to %s: %v", action, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to " + action})
+	}
+}
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/database"
+	"liftoff/backend/models"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestPrograms(t *testing.T) {
+	db, err := database.NewMockDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	workoutRepo := repository.NewWorkoutRepository(nil, db.GetSQLite(), true)
+	programRepo := repository.NewProgramRepository(nil, db.GetSQLite(), true, workoutRepo)
+	sessionRepo := repository.NewSessionRepository(nil, db.GetSQLite(), true)
+	h := NewProgramHandler(programRepo)
+
+	legs, err := workoutRepo.CreateWorkout(t.Context(), database.DemoUserID, "Legs", models.WorkoutTypeStrength, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := workoutRepo.CreateExercise(t.Context(), database.DemoUserID, &models.Exercise{Name: "Squat", Sets: 3, Reps: 5, Weight: 200, WorkoutID: legs.ID}); err != nil {
+		t.Fatal(err)
+	}
+	push, err := workoutRepo.CreateWorkout(t.Context(), database.DemoUserID, "Push", models.WorkoutTypeStrength, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	foreign, err := workoutRepo.CreateWorkout(t.Context(), "someone-else", "Theirs", models.WorkoutTypeStrength, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set(auth.UserIDKey, database.DemoUserID) })
+	r.GET("/programs", h.GetPrograms)
+	r.POST("/programs", h.CreateProgram)
+	r.GET("/programs/today", h.Today)
+	r.GET("/programs/enrollment", h.GetEnrollment)
+	r.POST("/programs/enrollment/advance", h.AdvanceWeek)
+	r.DELETE("/programs/enrollment", h.LeaveProgram)
+	r.GET("/programs/:id", h.GetProgram)
+	r.PUT("/programs/:id", h.UpdateProgram)
+	r.DELETE("/programs/:id", h.DeleteProgram)
+	r.POST("/programs/:id/enroll", h.Enroll)
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	body := `{"name": "Two Week Block", "workout_ids": ["` + legs.ID + `", "` + push.ID + `"],
+		"weeks": [{}, {"weight_percent": 105, "sets_delta": 1, "notes": "Heavier"}]}`
+	var program models.Program
+	w := do(http.MethodPost, "/programs", body)
+	if err := json.Unmarshal(w.Body.Bytes(), &program); w.Code != http.StatusCreated || err != nil {
+		t.Fatalf("create: %d %s", w.Code, w.Body)
+	}
+	if len(program.Workouts) != 2 || program.Workouts[1].WorkoutName != "Push" || len(program.Weeks) != 2 || program.Weeks[0].WeightPercent != 100 {
+		t.Errorf("program = %+v", program)
+	}
+	if w := do(http.MethodPost, "/programs", `{"name": "Theirs", "workout_ids": ["`+foreign.ID+`"], "weeks": [{}]}`); w.Code != http.StatusNotFound {
+		t.Errorf("another user's workout: got %d, want 404", w.Code)
+	}
+	if w := do(http.MethodPost, "/programs", `{"name": "Empty", "weeks": [{}]}`); w.Code != http.StatusBadRequest {
+		t.Errorf("no workouts: got %d, want 400", w.Code)
+	}
+	if w := do(http.MethodGet, "/programs/today", ""); w.Code != http.StatusNotFound {
+		t.Errorf("today before enrolling: got %d, want 404", w.Code)
+	}
+
+	if w := do(http.MethodPost, "/programs/"+program.ID+"/enroll", ""); w.Code != http.StatusCreated {
+		t.Fatalf("enroll: %d %s", w.Code, w.Body)
+	}
+	today := func() models.ProgramToday {
+		t.Helper()
+		var today models.ProgramToday
+		w := do(http.MethodGet, "/programs/today", "")
+		if err := json.Unmarshal(w.Body.Bytes(), &today); w.Code != http.StatusOK || err != nil {
+			t.Fatalf("today: %d %s", w.Code, w.Body)
+		}
+		return today
+	}
+	if got := today(); got.Day != 1 || got.Workout == nil || got.Workout.ID != legs.ID || got.WeeksTotal != 2 || got.Week.Week != 1 {
+		t.Errorf("week 1 day 1 = %+v", got)
+	}
+
+	// Week two's progression reaches both today's workout and the session started from it
+	var enrollment models.ProgramEnrollment
+	w = do(http.MethodPost, "/programs/enrollment/advance", "")
+	if err := json.Unmarshal(w.Body.Bytes(), &enrollment); w.Code != http.StatusOK || err != nil || enrollment.Week != 2 {
+		t.Fatalf("advance: %d %s", w.Code, w.Body)
+	}
+	got := today()
+	if got.Week.Notes != "Heavier" || got.Workout == nil || len(got.Workout.Exercises) != 1 {
+		t.Fatalf("week 2 = %+v", got)
+	}
+	if squat := got.Workout.Exercises[0]; squat.Weight != 210 || squat.Sets != 4 {
+		t.Errorf("week 2 squat = %+v", squat)
+	}
+	session, err := sessionRepo.CreateSessionWithTargets(t.Context(), database.DemoUserID, got.Workout.ID, nil, got.Week.Apply)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sets := session.Exercises[0].Sets; len(sets) != 4 || sets[0].Weight != 210 {
+		t.Errorf("session sets = %+v", sets)
+	}
+	if err := programRepo.CompleteDay(t.Context(), database.DemoUserID, got.Enrollment); err != nil {
+		t.Fatal(err)
+	}
+	// A second start from the same read of today is refused, and an undone day is trained again
+	if err := programRepo.CompleteDay(t.Context(), database.DemoUserID, got.Enrollment); !errors.Is(err, repository.ErrProgramDayTaken) {
+		t.Errorf("second start of day 1: %v", err)
+	}
+	if err := programRepo.UndoDay(t.Context(), database.DemoUserID, got.Enrollment); err != nil {
+		t.Fatal(err)
+	}
+	if got := today(); got.Day != 1 {
+		t.Errorf("after undo = %+v", got)
+	}
+	if err := programRepo.CompleteDay(t.Context(), database.DemoUserID, got.Enrollment); err != nil {
+		t.Fatal(err)
+	}
+	got = today()
+	if got.Day != 2 || got.Workout.ID != push.ID {
+		t.Errorf("after day 1 = %+v", got)
+	}
+	if err := programRepo.CompleteDay(t.Context(), database.DemoUserID, got.Enrollment); err != nil {
+		t.Fatal(err)
+	}
+	if got := today(); !got.WeekComplete || got.Workout != nil {
+		t.Errorf("after day 2 = %+v", got)
+	}
+
+	// Advancing past the last week completes the program
+	w = do(http.MethodPost, "/programs/enrollment/advance", "")
+	if err := json.Unmarshal(w.Body.Bytes(), &enrollment); w.Code != http.StatusOK || err != nil ||
+		enrollment.Status != models.EnrollmentCompleted || enrollment.CompletedAt == nil {
+		t.Fatalf("finish: %d %s", w.Code, w.Body)
+	}
+	if w := do(http.MethodGet, "/programs/enrollment", ""); w.Code != http.StatusNotFound {
+		t.Errorf("enrollment after finishing: got %d, want 404", w.Code)
+	}
+
+	// Re-enrolling starts over; leaving ends it
+	if w := do(http.MethodPost, "/programs/"+program.ID+"/enroll", ""); w.Code != http.StatusCreated {
+		t.Fatalf("re-enroll: %d %s", w.Code, w.Body)
+	}
+	if got := today(); got.Enrollment.Week != 1 || got.Day != 1 {
+		t.Errorf("re-enrolled = %+v", got)
+	}
+	if w := do(http.MethodDelete, "/programs/enrollment", ""); w.Code != http.StatusOK {
+		t.Errorf("leave: %d %s", w.Code, w.Body)
+	}
+	if w := do(http.MethodDelete, "/programs/enrollment", ""); w.Code != http.StatusNotFound {
+		t.Errorf("leave twice: got %d, want 404", w.Code)
+	}
+
+	w = do(http.MethodPut, "/programs/"+program.ID, `{"name": "Push Only", "workout_ids": ["`+push.ID+`"], "weeks": [{"weight_percent": 95}]}`)
+	if err := json.Unmarshal(w.Body.Bytes(), &program); w.Code != http.StatusOK || err != nil {
+		t.Fatalf("update: %d %s", w.Code, w.Body)
+	}
+	if program.Name != "Push Only" || len(program.Workouts) != 1 || len(program.Weeks) != 1 || program.Weeks[0].WeightPercent != 95 {
+		t.Errorf("updated = %+v", program)
+	}
+	if w := do(http.MethodDelete, "/programs/"+program.ID, ""); w.Code != http.StatusOK {
+		t.Fatalf("delete: %d %s", w.Code, w.Body)
+	}
+	if w := do(http.MethodGet, "/programs/"+program.ID, ""); w.Code != http.StatusNotFound {
+		t.Errorf("get after delete: got %d, want 404", w.Code)
+	}
+	if w := do(http.MethodGet, "/programs", ""); w.Body.String() != "[]" {
+		t.Errorf("programs after delete = %s", w.Body)
+	}
+}
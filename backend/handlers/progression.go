@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/models"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProgressionHandler manages the rules that adjust exercise weights as sessions end
+type ProgressionHandler struct {
+	progressionRepo *repository.ProgressionRepository
+}
+
+// NewProgressionHandler creates a new progression handler
+func NewProgressionHandler(progressionRepo *repository.ProgressionRepository) *ProgressionHandler {
+	return &ProgressionHandler{progressionRepo: progressionRepo}
+}
+
+// ProgressionRuleRequest is the request body for setting an exercise's rule;
+// fields left out take their defaults
+type ProgressionRuleRequest struct {
+	WeightIncrement float64 `json:"weight_increment"`
+	FailureLimit    int     `json:"failure_limit"`
+	DeloadPercent   float64 `json:"deload_percent"`
+}
+
+// GetRules lists the user's progression rules
+func (h *ProgressionHandler) GetRules(c *gin.Context) {
+	rules, err := h.progressionRepo.GetRules(c.Request.Context(), auth.GetUserID(c))
+	if err != nil {
+		log.Printf("Error fetching progression rules: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch progression rules"})
+		return
+	}
+	c.JSON(http.StatusOK, rules)
+}
+
+// GetRule returns the rule of one exercise
+func (h *ProgressionHandler) GetRule(c *gin.Context) {
+	rule, err := h.progressionRepo.GetRule(c.Request.Context(), auth.GetUserID(c), c.Param("id"))
+	if err != nil {
+		progressionError(c, err, "fetch")
+		return
+	}
+	c.JSON(http.StatusOK, rule)
+}
+
+// SetRule creates or replaces the rule of one exercise
+func (h *ProgressionHandler) SetRule(c *gin.Context) {
+	var req ProgressionRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	rule := &models.ProgressionRule{
+		ExerciseID:      c.Param("id"),
+		UserID:          auth.GetUserID(c),
+		WeightIncrement: req.WeightIncrement,
+		FailureLimit:    req.FailureLimit,
+		DeloadPercent:   req.DeloadPercent,
+	}
+	if err := rule.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	saved, err := h.progressionRepo.SetRule(c.Request.Context(), rule)
+	if err != nil {
+		progressionError(c, err, "save")
+		return
+	}
+	c.JSON(http.StatusOK, saved)
+}
+
+// DeleteRule removes the rule of one exercise
+func (h *ProgressionHandler) DeleteRule(c *gin.Context) {
+	if err := h.progressionRepo.DeleteRule(c.Request.Context(), auth.GetUserID(c), c.Param("id")); err != nil {
+		progressionError(c, err, "delete")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Progression rule deleted"})
+}
+
+// progressionError maps repository errors to responses; action names the operation for logs
+func progressionError(c *gin.Context, err error, action string) {
+	switch {
+	case errors.Is(err, repository.ErrProgressionRuleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Progression rule not found"})
+	case errors.Is(err, repository.ErrExerciseNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Exercise not found"})
+	case errors.Is(err, repository.ErrProgressionNotApplicable):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		log.Printf("Error trying to %s progression rule: %v", action, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to " + action + " progression rule"})
+	}
+}
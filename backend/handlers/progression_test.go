@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/database"
+	"liftoff/backend/models"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestProgressionRules(t *testing.T) {
+	db, err := database.NewMockDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	workoutRepo := repository.NewWorkoutRepository(nil, db.GetSQLite(), true)
+	sessionRepo := repository.NewSessionRepository(nil, db.GetSQLite(), true)
+	progressionRepo := repository.NewProgressionRepository(nil, db.GetSQLite(), true, workoutRepo, sessionRepo)
+	h := NewProgressionHandler(progressionRepo)
+
+	workout, err := workoutRepo.CreateWorkout(t.Context(), database.DemoUserID, "Squat Day", models.WorkoutTypeStrength, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	squat := &models.Exercise{Name: "Squat", Sets: 2, Reps: 5, Weight: 185, WorkoutID: workout.ID}
+	run := &models.Exercise{Name: "Run", Sets: 1, Mode: models.ExerciseModeCardio, DistanceMeters: 1600, WorkoutID: workout.ID}
+	for _, e := range []*models.Exercise{squat, run} {
+		if err := workoutRepo.CreateExercise(t.Context(), database.DemoUserID, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set(auth.UserIDKey, database.DemoUserID) })
+	r.GET("/progression-rules", h.GetRules)
+	r.GET("/exercises/:id/progression", h.GetRule)
+	r.PUT("/exercises/:id/progression", h.SetRule)
+	r.DELETE("/exercises/:id/progression", h.DeleteRule)
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	var rule models.ProgressionRule
+	w := do(http.MethodPut, "/exercises/"+squat.ID+"/progression", `{"weight_increment": 10}`)
+	if err := json.Unmarshal(w.Body.Bytes(), &rule); w.Code != http.StatusOK || err != nil {
+		t.Fatalf("set rule: %d %s", w.Code, w.Body)
+	}
+	if rule.WeightIncrement != 10 || rule.FailureLimit != models.DefaultFailureLimit || rule.DeloadPercent != models.DefaultDeloadPercent || rule.ExerciseName != "Squat" {
+		t.Errorf("rule = %+v", rule)
+	}
+	for path, want := range map[string]int{
+		"/exercises/" + run.ID + "/progression":   http.StatusBadRequest,
+		"/exercises/missing/progression":          http.StatusNotFound,
+		"/exercises/" + squat.ID + "/progression": http.StatusOK,
+	} {
+		if w := do(http.MethodPut, path, `{}`); w.Code != want {
+			t.Errorf("PUT %s: got %d, want %d", path, w.Code, want)
+		}
+	}
+	if w := do(http.MethodPut, "/exercises/"+squat.ID+"/progression", `{"failure_limit": 20}`); w.Code != http.StatusBadRequest {
+		t.Errorf("failure_limit 20: got %d, want 400", w.Code)
+	}
+
+	// train runs a session of the workout with every squat set at reps, then ends it
+	train := func(reps int) []models.ProgressionResult {
+		t.Helper()
+		session, err := sessionRepo.CreateSessionWithExercises(t.Context(), database.DemoUserID, workout.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, se := range session.Exercises {
+			for _, set := range se.Sets {
+				set.Reps, set.Completed = reps, true
+				if err := sessionRepo.UpdateExerciseSet(t.Context(), database.DemoUserID, set); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+		if _, err := sessionRepo.EndSession(t.Context(), database.DemoUserID, session.ID); err != nil {
+			t.Fatal(err)
+		}
+		results, err := progressionRepo.ApplyProgression(t.Context(), database.DemoUserID, session.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Counting the same session again changes nothing
+		if again, err := progressionRepo.ApplyProgression(t.Context(), database.DemoUserID, session.ID); err != nil || len(again) != 0 {
+			t.Errorf("second evaluation = %+v, %v", again, err)
+		}
+		return results
+	}
+	weight := func() float64 {
+		t.Helper()
+		e, err := workoutRepo.GetUserExercise(t.Context(), database.DemoUserID, squat.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return e.Weight
+	}
+
+	// The rule was reset to defaults by the empty PUT: +5 on success, deload after two misses
+	if got := train(5); len(got) != 1 || got[0].Outcome != models.ProgressionIncreased || weight() != 190 {
+		t.Errorf("success = %+v, weight %v", got, weight())
+	}
+	if got := train(4); len(got) != 1 || got[0].Outcome != models.ProgressionMissed || got[0].Failures != 1 || weight() != 190 {
+		t.Errorf("first miss = %+v, weight %v", got, weight())
+	}
+	if got := train(3); len(got) != 1 || got[0].Outcome != models.ProgressionDeloaded || weight() != 170 {
+		t.Errorf("deload = %+v, weight %v", got, weight())
+	}
+	w = do(http.MethodGet, "/exercises/"+squat.ID+"/progression", "")
+	if err := json.Unmarshal(w.Body.Bytes(), &rule); w.Code != http.StatusOK || err != nil || rule.Failures != 0 {
+		t.Errorf("rule after deload: %d %s", w.Code, w.Body)
+	}
+
+	var rules []models.ProgressionRule
+	if err := json.Unmarshal(do(http.MethodGet, "/progression-rules", "").Body.Bytes(), &rules); err != nil || len(rules) != 1 {
+		t.Errorf("rules = %+v, %v", rules, err)
+	}
+	if w := do(http.MethodDelete, "/exercises/"+squat.ID+"/progression", ""); w.Code != http.StatusOK {
+		t.Fatalf("delete: %d %s", w.Code, w.Body)
+	}
+	if w := do(http.MethodGet, "/exercises/"+squat.ID+"/progression", ""); w.Code != http.StatusNotFound {
+		t.Errorf("get after delete: got %d, want 404", w.Code)
+	}
+	if got := train(5); len(got) != 0 || weight() != 170 {
+		t.Errorf("without a rule = %+v, weight %v", got, weight())
+	}
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReauthRequest carries the current password for step-up authentication
+type ReauthRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// Reauth checks the user's password again and returns a short-lived step-up token
+// for the current session. Routes guarded by auth.RequireReauth, such as account
+// deletion, need it in the X-Reauth-Token header.
+func (h *AuthHandler) Reauth(c *gin.Context) {
+	claims := auth.GetClaims(c)
+	if claims == nil || claims.ID == "" || claims.ImpersonatedBy != "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Sign in again to continue"})
+		return
+	}
+	var req ReauthRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Password is required"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	user, err := h.userRepo.GetByID(ctx, claims.UserID)
+	if err == nil && user != nil {
+		// GetByID omits the password hash
+		user, err = h.userRepo.GetByEmail(ctx, user.Email)
+	}
+	if err != nil || user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	// Failures share the login lockout so a stolen session cannot be used to guess the password
+	lockout := auth.GetLockoutConfig()
+	accountKey := repository.LoginAccountKey(user.Email)
+	if until := h.loginLockedUntil(c, accountKey); !until.IsZero() {
+		setRetryAfter(c, until)
+		c.JSON(http.StatusLocked, gin.H{"error": "Account temporarily locked after too many failed login attempts"})
+		return
+	}
+	if !auth.CheckPassword(req.Password, user.PasswordHash) {
+		h.recordLoginFailure(c, lockout, accountKey, lockout.MaxAccountFailures)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Password is incorrect"})
+		return
+	}
+	if err := h.userRepo.ClearLoginFailures(ctx, accountKey); err != nil {
+		log.Printf("Error clearing login failures: %v", err)
+	}
+
+	token, expiresAt, err := auth.GenerateReauthToken(user.ID, claims.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"reauthToken": token, "expiresAt": expiresAt})
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/models"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RecommendationHandler serves template recommendations
+type RecommendationHandler struct {
+	recommendationRepo *repository.RecommendationRepository
+}
+
+// NewRecommendationHandler creates a new recommendation handler
+func NewRecommendationHandler(recommendationRepo *repository.RecommendationRepository) *RecommendationHandler {
+	return &RecommendationHandler{recommendationRepo: recommendationRepo}
+}
+
+// GetTemplateRecommendations returns the user's ranked template suggestions (?limit=5)
+func (h *RecommendationHandler) GetTemplateRecommendations(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "5"))
+	if err != nil || limit <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+		return
+	}
+
+	recs, err := h.recommendationRepo.GetTemplateRecommendations(c.Request.Context(), auth.GetUserID(c), limit)
+	if err != nil {
+		log.Printf("Error fetching recommendations: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch recommendations"})
+		return
+	}
+	if recs == nil {
+		recs = []*models.TemplateRecommendation{}
+	}
+	c.JSON(http.StatusOK, gin.H{"recommendations": recs})
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"liftoff/backend/email"
+	"liftoff/backend/models"
+	"liftoff/backend/repository"
+	"liftoff/backend/retention"
+
+	"github.com/gin-gonic/gin"
+)
+
+// retentionReportDeletions caps the recent deletions shown to admins
+const retentionReportDeletions = 100
+
+// RetentionHandler reports on the inactivity retention policy
+type RetentionHandler struct {
+	retentionRepo *repository.RetentionRepository
+	policy        retention.Policy
+}
+
+// NewRetentionHandler creates a new retention handler
+func NewRetentionHandler(retentionRepo *repository.RetentionRepository, policy retention.Policy) *RetentionHandler {
+	return &RetentionHandler{retentionRepo: retentionRepo, policy: policy}
+}
+
+// GetReport returns the policy, pending deletion warnings and recent deletions (admin only)
+func (h *RetentionHandler) GetReport(c *gin.Context) {
+	ctx := c.Request.Context()
+	warnings, err := h.retentionRepo.ListRetentionWarnings(ctx)
+	if err != nil {
+		log.Printf("Error listing retention warnings: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get retention report"})
+		return
+	}
+	deletions, err := h.retentionRepo.ListRetentionDeletions(ctx, retentionReportDeletions)
+	if err != nil {
+		log.Printf("Error listing retention deletions: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get retention report"})
+		return
+	}
+	day := 24 * time.Hour
+	c.JSON(http.StatusOK, models.RetentionReport{
+		InactiveDays: int(h.policy.InactiveAfter / day),
+		WarningDays:  int(h.policy.WarnBefore / day),
+		Warnings:     warnings,
+		Deletions:    deletions,
+	})
+}
+
+// InactivityNotifier emails retention warnings through sender. Unlike other emails these
+// are sent synchronously, so the retention job only records warnings that were delivered.
+func InactivityNotifier(sender email.Sender) retention.Notifier {
+	return func(ctx context.Context, to string, deleteAfter time.Time) error {
+		msg, err := email.InactivityWarning(to, frontendURL()+"/login", deleteAfter)
+		if err != nil {
+			return err
+		}
+		return sender.Send(ctx, msg)
+	}
+}
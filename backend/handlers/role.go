@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoleHandler lets admins grant and revoke roles
+type RoleHandler struct {
+	roleRepo *repository.RoleRepository
+}
+
+// NewRoleHandler creates a new role handler
+func NewRoleHandler(roleRepo *repository.RoleRepository) *RoleHandler {
+	return &RoleHandler{roleRepo: roleRepo}
+}
+
+// ListRoles returns every user holding a coach or admin role (admin only).
+// Admins granted only through ADMIN_EMAILS are not listed.
+func (h *RoleHandler) ListRoles(c *gin.Context) {
+	users, err := h.roleRepo.ListElevated(c.Request.Context())
+	if err != nil {
+		log.Printf("Error listing roles: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list roles"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"users": users})
+}
+
+// SetRoleRequest names the role to grant
+type SetRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// GrantRole sets a user's role (admin only)
+func (h *RoleHandler) GrantRole(c *gin.Context) {
+	var req SetRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil || !auth.ValidRole(req.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "role must be user, coach or admin"})
+		return
+	}
+	h.setRole(c, req.Role)
+}
+
+// RevokeRole returns a user to the plain user role (admin only)
+func (h *RoleHandler) RevokeRole(c *gin.Context) {
+	h.setRole(c, auth.RoleUser)
+}
+
+func (h *RoleHandler) setRole(c *gin.Context, role string) {
+	userID := c.Param("id")
+	if userID == auth.GetUserID(c) && role != auth.RoleAdmin {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot remove your own admin role"})
+		return
+	}
+	err := h.roleRepo.SetRole(c.Request.Context(), userID, role)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("Error setting role for %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update role"})
+		return
+	}
+	log.Printf("Admin %s set role of user %s to %s", auth.GetUserID(c), userID, role)
+	c.JSON(http.StatusOK, gin.H{"id": userID, "role": role})
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/models"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ScheduleHandler manages the user's training calendar
+type ScheduleHandler struct {
+	scheduleRepo *repository.ScheduleRepository
+}
+
+// NewScheduleHandler creates a new schedule handler
+func NewScheduleHandler(scheduleRepo *repository.ScheduleRepository) *ScheduleHandler {
+	return &ScheduleHandler{scheduleRepo: scheduleRepo}
+}
+
+// ScheduleWorkoutRequest is the request body for putting a workout on the calendar
+type ScheduleWorkoutRequest struct {
+	WorkoutID string  `json:"workout_id" binding:"required"`
+	Date      string  `json:"date" binding:"required"`
+	Time      *string `json:"time"`
+	Status    string  `json:"status"`
+}
+
+// UpdateScheduledWorkoutRequest changes a calendar entry; fields left out keep
+// their value and an empty time clears it
+type UpdateScheduledWorkoutRequest struct {
+	WorkoutID *string `json:"workout_id"`
+	Date      *string `json:"date"`
+	Time      *string `json:"time"`
+	Status    *string `json:"status"`
+}
+
+// GetSchedule lists the calendar entries between ?from= and ?to=, inclusive
+func (h *ScheduleHandler) GetSchedule(c *gin.Context) {
+	from, to := c.Query("from"), c.Query("to")
+	if err := models.ParseScheduleRange(from, to); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	schedule, err := h.scheduleRepo.GetSchedule(c.Request.Context(), auth.GetUserID(c), from, to)
+	if err != nil {
+		log.Printf("Error fetching schedule: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch schedule"})
+		return
+	}
+	c.JSON(http.StatusOK, schedule)
+}
+
+// GetScheduledWorkout returns one calendar entry
+func (h *ScheduleHandler) GetScheduledWorkout(c *gin.Context) {
+	entry, err := h.scheduleRepo.GetScheduledWorkout(c.Request.Context(), auth.GetUserID(c), c.Param("id"))
+	if err != nil {
+		scheduleError(c, err, "fetch")
+		return
+	}
+	c.JSON(http.StatusOK, entry)
+}
+
+// CreateScheduledWorkout puts one of the user's workouts on a day of the calendar
+func (h *ScheduleHandler) CreateScheduledWorkout(c *gin.Context) {
+	var req ScheduleWorkoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "workout_id and date are required"})
+		return
+	}
+	entry := &models.ScheduledWorkout{
+		UserID:    auth.GetUserID(c),
+		WorkoutID: req.WorkoutID,
+		Date:      req.Date,
+		Time:      req.Time,
+		Status:    req.Status,
+	}
+	if err := entry.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.scheduleRepo.CreateScheduledWorkout(c.Request.Context(), entry); err != nil {
+		scheduleError(c, err, "create")
+		return
+	}
+	c.JSON(http.StatusCreated, entry)
+}
+
+// UpdateScheduledWorkout moves, reassigns or marks a calendar entry
+func (h *ScheduleHandler) UpdateScheduledWorkout(c *gin.Context) {
+	var req UpdateScheduledWorkoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	entry, err := h.scheduleRepo.GetScheduledWorkout(c.Request.Context(), auth.GetUserID(c), c.Param("id"))
+	if err != nil {
+		scheduleError(c, err, "update")
+		return
+	}
+	if req.WorkoutID != nil {
+		entry.WorkoutID = *req.WorkoutID
+	}
+	if req.Date != nil {
+		entry.Date = *req.Date
+	}
+	if req.Time != nil {
+		entry.Time = req.Time
+	}
+	if req.Status != nil {
+		if *req.Status == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "status must be planned, completed or skipped"})
+			return
+		}
+		entry.Status = *req.Status
+	}
+	if err := entry.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.scheduleRepo.UpdateScheduledWorkout(c.Request.Context(), entry); err != nil {
+		scheduleError(c, err, "update")
+		return
+	}
+	c.JSON(http.StatusOK, entry)
+}
+
+// DeleteScheduledWorkout takes an entry off the calendar
+func (h *ScheduleHandler) DeleteScheduledWorkout(c *gin.Context) {
+	if err := h.scheduleRepo.DeleteScheduledWorkout(c.Request.Context(), auth.GetUserID(c), c.Param("id")); err != nil {
+		scheduleError(c, err, "delete")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Scheduled workout deleted"})
+}
+
+// scheduleError maps repository errors to responses; action names the operation for logs
+func scheduleError(c *gin.Context, err error, action string) {
+	switch {
+	case errors.Is(err, repository.ErrScheduledWorkoutNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Scheduled workout not found"})
+	case errors.Is(err, repository.ErrWorkoutNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Workout not found"})
+	default:
+		log.Printf("Error trying to %s scheduled workout: %v", action, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to " + action + " scheduled workout"})
+	}
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/database"
+	"liftoff/backend/models"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSchedule(t *testing.T) {
+	db, err := database.NewMockDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	workoutRepo := repository.NewWorkoutRepository(nil, db.GetSQLite(), true)
+	h := NewScheduleHandler(repository.NewScheduleRepository(nil, db.GetSQLite(), true, workoutRepo))
+
+	legs, err := workoutRepo.CreateWorkout(t.Context(), database.DemoUserID, "Leg Day", models.WorkoutTypeStrength, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	push, err := workoutRepo.CreateWorkout(t.Context(), database.DemoUserID, "Push Day", models.WorkoutTypeStrength, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	foreign, err := workoutRepo.CreateWorkout(t.Context(), "someone-else", "Their Day", models.WorkoutTypeStrength, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set(auth.UserIDKey, database.DemoUserID) })
+	r.GET("/schedule", h.GetSchedule)
+	r.POST("/schedule", h.CreateScheduledWorkout)
+	r.GET("/schedule/:id", h.GetScheduledWorkout)
+	r.PUT("/schedule/:id", h.UpdateScheduledWorkout)
+	r.DELETE("/schedule/:id", h.DeleteScheduledWorkout)
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	schedule := func(body string) models.ScheduledWorkout {
+		t.Helper()
+		var s models.ScheduledWorkout
+		w := do(http.MethodPost, "/schedule", body)
+		if err := json.Unmarshal(w.Body.Bytes(), &s); w.Code != http.StatusCreated || err != nil {
+			t.Fatalf("scheduling %s: %d %s", body, w.Code, w.Body)
+		}
+		return s
+	}
+
+	evening := schedule(`{"workout_id": "` + legs.ID + `", "date": "2026-10-20", "time": "18:30"}`)
+	if evening.Status != models.ScheduleStatusPlanned || evening.WorkoutName != "Leg Day" || evening.Time == nil || *evening.Time != "18:30" {
+		t.Errorf("scheduled = %+v", evening)
+	}
+	anytime := schedule(`{"workout_id": "` + push.ID + `", "date": "2026-10-20", "time": ""}`)
+	schedule(`{"workout_id": "` + push.ID + `", "date": "2026-11-02"}`)
+
+	for _, body := range []string{
+		`{"workout_id": "` + legs.ID + `", "date": "20/10/2026"}`,
+		`{"workout_id": "` + legs.ID + `", "date": "2026-10-20", "time": "6pm"}`,
+		`{"workout_id": "` + legs.ID + `", "date": "2026-10-20", "status": "maybe"}`,
+		`{"date": "2026-10-20"}`,
+	} {
+		if w := do(http.MethodPost, "/schedule", body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: got %d, want 400", body, w.Code)
+		}
+	}
+	if w := do(http.MethodPost, "/schedule", `{"workout_id": "`+foreign.ID+`", "date": "2026-10-20"}`); w.Code != http.StatusNotFound {
+		t.Errorf("another user's workout: got %d, want 404", w.Code)
+	}
+
+	// The range is inclusive, and entries without a time lead their day
+	var month []models.ScheduledWorkout
+	w := do(http.MethodGet, "/schedule?from=2026-10-01&to=2026-10-31", "")
+	if err := json.Unmarshal(w.Body.Bytes(), &month); w.Code != http.StatusOK || err != nil {
+		t.Fatalf("range: %d %s", w.Code, w.Body)
+	}
+	if len(month) != 2 || month[0].ID != anytime.ID || month[0].Time != nil || month[1].ID != evening.ID {
+		t.Errorf("October = %+v", month)
+	}
+	if w := do(http.MethodGet, "/schedule?from=2026-11-02&to=2026-11-02", ""); !strings.Contains(w.Body.String(), "Push Day") {
+		t.Errorf("single day: %s", w.Body)
+	}
+	for _, query := range []string{"", "?from=2026-10-01", "?from=2026-10-31&to=2026-10-01", "?from=2026-01-01&to=2027-06-01"} {
+		if w := do(http.MethodGet, "/schedule"+query, ""); w.Code != http.StatusBadRequest {
+			t.Errorf("%q: got %d, want 400", query, w.Code)
+		}
+	}
+
+	// Updates are partial: moving the day keeps the time
+	var moved models.ScheduledWorkout
+	w = do(http.MethodPut, "/schedule/"+evening.ID, `{"date": "2026-10-21", "status": "completed"}`)
+	if err := json.Unmarshal(w.Body.Bytes(), &moved); w.Code != http.StatusOK || err != nil {
+		t.Fatalf("update: %d %s", w.Code, w.Body)
+	}
+	if moved.Date != "2026-10-21" || moved.Time == nil || *moved.Time != "18:30" || moved.Status != models.ScheduleStatusCompleted || moved.WorkoutName != "Leg Day" {
+		t.Errorf("moved = %+v", moved)
+	}
+	w = do(http.MethodPut, "/schedule/"+evening.ID, `{"workout_id": "`+push.ID+`", "time": ""}`)
+	if err := json.Unmarshal(w.Body.Bytes(), &moved); w.Code != http.StatusOK || err != nil || moved.WorkoutName != "Push Day" || moved.Time != nil {
+		t.Errorf("reassign: %d %s", w.Code, w.Body)
+	}
+	if w := do(http.MethodPut, "/schedule/"+evening.ID, `{"workout_id": "`+foreign.ID+`"}`); w.Code != http.StatusNotFound {
+		t.Errorf("reassign to another user's workout: got %d, want 404", w.Code)
+	}
+	if w := do(http.MethodPut, "/schedule/"+evening.ID, `{"status": ""}`); w.Code != http.StatusBadRequest {
+		t.Errorf("empty status: got %d, want 400", w.Code)
+	}
+
+	if w := do(http.MethodDelete, "/schedule/"+evening.ID, ""); w.Code != http.StatusOK {
+		t.Fatalf("delete: %d %s", w.Code, w.Body)
+	}
+	for _, method := range []string{http.MethodGet, http.MethodDelete} {
+		if w := do(method, "/schedule/"+evening.ID, ""); w.Code != http.StatusNotFound {
+			t.Errorf("%s after delete: got %d, want 404", method, w.Code)
+		}
+	}
+	if _, err := workoutRepo.GetWorkout(t.Context(), database.DemoUserID, legs.ID); err != nil {
+		t.Errorf("unscheduling deleted the workout: %v", err)
+	}
+}
@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"liftoff/backend/analytics"
+	"liftoff/backend/auth"
+	"liftoff/backend/models"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Session log formats
+const (
+	sessionLogMarkdown = "markdown"
+	sessionLogText     = "text"
+)
+
+// SessionLog renders one session as a plain-text training log for pasting into
+// forums or journals (?format=markdown|text, ?tz= IANA zone for times, default UTC)
+func (h *ExportHandler) SessionLog(c *gin.Context) {
+	format := c.DefaultQuery("format", sessionLogMarkdown)
+	if format != sessionLogMarkdown && format != sessionLogText {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be markdown or text"})
+		return
+	}
+	loc, err := time.LoadLocation(c.DefaultQuery("tz", "UTC"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "tz must be an IANA time zone such as Europe/London"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID := auth.GetUserID(c)
+	session, err := h.sessionRepo.GetSessionWithExercises(ctx, userID, c.Param("id"))
+	if errors.Is(err, repository.ErrSessionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+	var prior map[string]models.ExerciseBest
+	if err == nil {
+		prior, err = h.sessionRepo.GetExerciseBests(ctx, userID, session.StartedAt)
+	}
+	if err != nil {
+		log.Printf("Error exporting session log: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export session"})
+		return
+	}
+
+	body := renderSessionLog(session, analytics.SessionRecords(session, prior), format == sessionLogMarkdown, loc)
+	contentType := "text/plain; charset=utf-8"
+	if format == sessionLogMarkdown {
+		contentType = "text/markdown; charset=utf-8"
+	}
+	c.Data(http.StatusOK, contentType, []byte(body))
+}
+
+// renderSessionLog writes a session as Markdown or plain text: a header with
+// date, duration and journal metadata, then each exercise's completed sets with
+// record callouts and notes
+func renderSessionLog(session *models.WorkoutSession, records []models.SetRecord, markdown bool, loc *time.Location) string {
+	var b strings.Builder
+	bySet := map[string][]models.SetRecord{}
+	for _, r := range records {
+		bySet[r.SetID] = append(bySet[r.SetID], r)
+	}
+
+	title := "Workout"
+	if session.Workout != nil && session.Workout.Name != "" {
+		title = session.Workout.Name
+	}
+	when := session.StartedAt.In(loc).Format("Mon 2 Jan 2006, 15:04")
+	if session.EndedAt != nil {
+		minutes := int(session.EndedAt.Sub(session.StartedAt).Minutes())
+		when += fmt.Sprintf("-%s (%d min)", session.EndedAt.In(loc).Format("15:04"), minutes)
+	}
+	if markdown {
+		fmt.Fprintf(&b, "# %s\n\n*%s*\n", title, when)
+	} else {
+		fmt.Fprintf(&b, "%s\n%s\n", title, when)
+	}
+
+	m := session.Metadata
+	var details [][2]string
+	if m.Gym != "" {
+		details = append(details, [2]string{"Gym", m.Gym})
+	}
+	if len(m.Partners) > 0 {
+		details = append(details, [2]string{"Partners", strings.Join(m.Partners, ", ")})
+	}
+	if m.Mood != "" {
+		details = append(details, [2]string{"Mood", m.Mood})
+	}
+	if m.PlaylistURL != "" {
+		details = append(details, [2]string{"Playlist", m.PlaylistURL})
+	}
+	if len(details) > 0 {
+		b.WriteString("\n")
+	}
+	for _, d := range details {
+		if markdown {
+			fmt.Fprintf(&b, "- **%s:** %s\n", d[0], d[1])
+		} else {
+			fmt.Fprintf(&b, "%s: %s\n", d[0], d[1])
+		}
+	}
+
+	var totalVolume float64
+	var totalSets int
+	for _, se := range session.Exercises {
+		name := "Exercise"
+		if se.Exercise != nil {
+			name = se.Exercise.Name
+		}
+		if markdown {
+			fmt.Fprintf(&b, "\n## %s\n\n", name)
+		} else {
+			fmt.Fprintf(&b, "\n%s\n", name)
+		}
+		n := 0
+		for _, set := range se.Sets {
+			if !set.Completed {
+				continue
+			}
+			n++
+			if !set.IsWarmup() {
+				totalSets++
+				totalVolume += set.Volume()
+			}
+			line := formatLoggedSet(se.Exercise, set)
+			if recs := bySet[set.ID]; len(recs) > 0 {
+				described := make([]string, len(recs))
+				for i, r := range recs {
+					described[i] = describeRecord(r)
+				}
+				if markdown {
+					line += " - **PR:** " + strings.Join(described, "; ")
+				} else {
+					line += " [PR: " + strings.Join(described, "; ") + "]"
+				}
+			}
+			if markdown {
+				fmt.Fprintf(&b, "%d. %s\n", n, line)
+			} else {
+				fmt.Fprintf(&b, "  %d. %s\n", n, line)
+			}
+			if set.Notes != nil && strings.TrimSpace(*set.Notes) != "" {
+				note := strings.Join(strings.Fields(*set.Notes), " ")
+				if markdown {
+					fmt.Fprintf(&b, "   > %s\n", note)
+				} else {
+					fmt.Fprintf(&b, "     Note: %s\n", note)
+				}
+			}
+		}
+		if n == 0 {
+			if markdown {
+				b.WriteString("*No completed sets*\n")
+			} else {
+				b.WriteString("  No completed sets\n")
+			}
+		}
+	}
+
+	summary := fmt.Sprintf("%d sets, %s total volume", totalSets, formatNumber(totalVolume))
+	if len(records) > 0 {
+		summary += fmt.Sprintf(", %d PR", len(records))
+		if len(records) > 1 {
+			summary += "s"
+		}
+	}
+	if markdown {
+		fmt.Fprintf(&b, "\n---\n%s\n", summary)
+	} else {
+		fmt.Fprintf(&b, "\n%s\n", summary)
+	}
+	return b.String()
+}
+
+// formatLoggedSet describes one completed set, e.g. "135 x 8", "45 s",
+// "L 40 x 10 / R 40 x 9", "185 x 6 > 155 x 4 (drop set)" or "225 x 5 @ RPE 8.5"
+func formatLoggedSet(exercise *models.Exercise, set *models.ExerciseSet) string {
+	load := func(weight float64, reps int) string {
+		if weight <= 0 {
+			return fmt.Sprintf("%d reps", reps)
+		}
+		return fmt.Sprintf("%s x %d", formatNumber(weight), reps)
+	}
+
+	var s string
+	switch {
+	case exercise != nil && exercise.IsTimed() && set.DurationSeconds != nil:
+		s = fmt.Sprintf("%d s", *set.DurationSeconds)
+		if set.Weight > 0 {
+			s += " @ " + formatNumber(set.Weight)
+		}
+	case exercise != nil && exercise.IsCardio() && (set.DurationSeconds != nil || set.DistanceMeters != nil):
+		var seconds, meters int
+		if set.DurationSeconds != nil {
+			seconds = *set.DurationSeconds
+		}
+		if set.DistanceMeters != nil {
+			meters = *set.DistanceMeters
+		}
+		s = models.FormatCardio(seconds, meters)
+	case !set.Sides.IsZero():
+		s = "L " + load(set.Sides.Left.Weight, set.Sides.Left.Reps) + " / R " + load(set.Sides.Right.Weight, set.Sides.Right.Reps)
+	default:
+		s = load(set.Weight, set.Reps)
+	}
+
+	for _, seg := range set.Segments {
+		s += " > " + load(seg.Weight, seg.Reps)
+	}
+	switch set.Technique {
+	case models.SetTechniqueDropSet:
+		s += " (drop set)"
+	case models.SetTechniqueRestPause:
+		s += " (rest-pause)"
+	}
+	if set.ActualRPE != nil {
+		s += " @ RPE " + formatNumber(*set.ActualRPE)
+	}
+	switch set.SetType {
+	case models.SetTypeWarmup:
+		s += " (warm-up)"
+	case models.SetTypeAMRAP:
+		s += " (AMRAP)"
+	case models.SetTypeFailure:
+		s += " (to failure)"
+	case models.SetTypeDrop:
+		if set.Technique != models.SetTechniqueDropSet {
+			s += " (drop set)"
+		}
+	}
+	return s
+}
+
+func describeRecord(r models.SetRecord) string {
+	switch r.Kind {
+	case models.RecordWeight:
+		return fmt.Sprintf("heaviest weight (previous %s)", formatNumber(r.Previous))
+	case models.RecordE1RM:
+		return fmt.Sprintf("estimated 1RM %s (previous %s)", formatNumber(r.Value), formatNumber(r.Previous))
+	case models.RecordReps:
+		return fmt.Sprintf("most reps (previous %s)", formatNumber(r.Previous))
+	case models.RecordDuration:
+		return fmt.Sprintf("longest hold (previous %s s)", formatNumber(r.Previous))
+	}
+	return r.Kind
+}
+
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"liftoff/backend/models"
+)
+
+func TestRenderSessionLog(t *testing.T) {
+	started := time.Date(2024, 5, 6, 18, 0, 0, 0, time.UTC)
+	ended := started.Add(55 * time.Minute)
+	note := "felt  fast\nnext time 5 more"
+	hold := 45
+	rpe := 8.5
+	session := &models.WorkoutSession{
+		Workout:   &models.Workout{Name: "Push Day"},
+		StartedAt: started,
+		EndedAt:   &ended,
+		Metadata:  models.SessionMetadata{Gym: "Downtown Gym", Partners: []string{"Sam", "Alex"}},
+		Exercises: []*models.SessionExercise{
+			{Exercise: &models.Exercise{Name: "Bench Press"}, Sets: []*models.ExerciseSet{
+				{ID: "b1", Weight: 135, Reps: 8, Completed: true, Notes: &note},
+				{ID: "b2", Weight: 185, Reps: 6, Completed: true, Technique: models.SetTechniqueDropSet,
+					Segments: models.SetSegments{{Weight: 155, Reps: 4}}},
+				{ID: "b3", Weight: 200, Reps: 1},
+				{ID: "b4", Weight: 95, Reps: 10, Completed: true, SetType: models.SetTypeWarmup},
+			}},
+			{Exercise: &models.Exercise{Name: "Plank", Mode: models.ExerciseModeDuration}, Sets: []*models.ExerciseSet{
+				{ID: "p1", DurationSeconds: &hold, Completed: true},
+			}},
+			{Exercise: &models.Exercise{Name: "Single-arm Row", Unilateral: true}, Sets: []*models.ExerciseSet{
+				{ID: "r1", Weight: 40, Reps: 10, Completed: true, ActualRPE: &rpe, Sides: models.SetSides{
+					Left: models.SideResult{Weight: 40, Reps: 10}, Right: models.SideResult{Weight: 40, Reps: 9}}},
+			}},
+		},
+	}
+	records := []models.SetRecord{{SetID: "b2", Kind: models.RecordWeight, Value: 185, Previous: 180}}
+
+	md := renderSessionLog(session, records, true, time.UTC)
+	for _, want := range []string{
+		"# Push Day\n",
+		"*Mon 6 May 2024, 18:00-18:55 (55 min)*",
+		"- **Partners:** Sam, Alex",
+		"## Bench Press\n\n1. 135 x 8\n   > felt fast next time 5 more\n",
+		"2. 185 x 6 > 155 x 4 (drop set) - **PR:** heaviest weight (previous 180)\n",
+		"3. 95 x 10 (warm-up)\n",
+		"1. 45 s\n",
+		"1. L 40 x 10 / R 40 x 9 @ RPE 8.5\n",
+		"4 sets, 3570 total volume, 1 PR\n",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown is missing %q:\n%s", want, md)
+		}
+	}
+	if strings.Contains(md, "200 x 1") {
+		t.Error("incomplete set was rendered")
+	}
+
+	text := renderSessionLog(session, records, false, time.UTC)
+	if strings.Contains(text, "**") || strings.Contains(text, "# ") {
+		t.Errorf("text output has markdown:\n%s", text)
+	}
+	if !strings.Contains(text, "  2. 185 x 6 > 155 x 4 (drop set) [PR: heaviest weight (previous 180)]\n") {
+		t.Errorf("text output:\n%s", text)
+	}
+}
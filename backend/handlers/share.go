@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/models"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ShareHandler shares workouts between users as scannable codes, and saved
+// workout templates as public links
+type ShareHandler struct {
+	workoutRepo *repository.WorkoutRepository
+}
+
+// NewShareHandler creates a new share handler
+func NewShareHandler(workoutRepo *repository.WorkoutRepository) *ShareHandler {
+	return &ShareHandler{workoutRepo: workoutRepo}
+}
+
+// QR returns a compact code for one of the user's workouts, and a frontend link
+// carrying it, for the client to render as a QR code
+func (h *ShareHandler) QR(c *gin.Context) {
+	shared, err := h.workoutRepo.ShareWorkout(c.Request.Context(), auth.GetUserID(c), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Workout not found"})
+		return
+	}
+	code, err := shared.Encode()
+	if err != nil {
+		log.Printf("Error encoding shared workout: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to share workout"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":    code,
+		"url":     frontendURL() + "/import-workout?code=" + url.QueryEscape(code),
+		"workout": shared,
+	})
+}
+
+// ImportWorkoutRequest carries a scanned workout code
+type ImportWorkoutRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// Import creates a copy of a shared workout for the user, mapping its exercises
+// onto the exercise library
+func (h *ShareHandler) Import(c *gin.Context) {
+	var req ImportWorkoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Code is required"})
+		return
+	}
+	shared, err := models.DecodeSharedWorkout(req.Code)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	workout, mappings, err := h.workoutRepo.ImportSharedWorkout(c.Request.Context(), auth.GetUserID(c), shared)
+	if err != nil {
+		log.Printf("Error importing shared workout: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import workout"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"workout": workout, "exercises": mappings})
+}
+
+// ShareTemplate publishes one of the user's saved workout templates and returns its
+// public link. Each call issues a new token, so links shared earlier stop working.
+func (h *ShareHandler) ShareTemplate(c *gin.Context) {
+	token, err := repository.GenerateSecureToken()
+	if err != nil {
+		log.Printf("Error generating share token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to share template"})
+		return
+	}
+	err = h.workoutRepo.ShareWorkoutTemplate(c.Request.Context(), auth.GetUserID(c), c.Param("id"), auth.HashToken(token))
+	if errors.Is(err, repository.ErrWorkoutTemplateNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Template not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("Error sharing template: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to share template"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"token": token,
+		"url":   frontendURL() + "/shared/templates/" + token,
+	})
+}
+
+// UnshareTemplate withdraws the public link of one of the user's templates
+func (h *ShareHandler) UnshareTemplate(c *gin.Context) {
+	err := h.workoutRepo.UnshareWorkoutTemplate(c.Request.Context(), auth.GetUserID(c), c.Param("id"))
+	if errors.Is(err, repository.ErrWorkoutTemplateNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Template not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("Error unsharing template: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unshare template"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Template is no longer shared"})
+}
+
+// SharedTemplate previews a shared template; no sign-in is needed
+func (h *ShareHandler) SharedTemplate(c *gin.Context) {
+	template, ok := h.sharedTemplate(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, template)
+}
+
+// CloneSharedTemplate copies a shared template into the user's own templates
+func (h *ShareHandler) CloneSharedTemplate(c *gin.Context) {
+	template, ok := h.sharedTemplate(c)
+	if !ok {
+		return
+	}
+	clone, err := h.workoutRepo.CloneWorkoutTemplate(c.Request.Context(), auth.GetUserID(c), template)
+	if err != nil {
+		log.Printf("Error cloning shared template: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to copy template"})
+		return
+	}
+	c.JSON(http.StatusCreated, clone)
+}
+
+// sharedTemplate looks up the template behind the :token parameter, responding
+// 404 when the link is unknown or withdrawn
+func (h *ShareHandler) sharedTemplate(c *gin.Context) (*models.WorkoutTemplate, bool) {
+	template, err := h.workoutRepo.GetSharedWorkoutTemplate(c.Request.Context(), auth.HashToken(c.Param("token")))
+	if errors.Is(err, repository.ErrWorkoutTemplateNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Shared template not found"})
+		return nil, false
+	}
+	if err != nil {
+		log.Printf("Error getting shared template: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get shared template"})
+		return nil, false
+	}
+	return template, true
+}
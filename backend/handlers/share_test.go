@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/database"
+	"liftoff/backend/models"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestShareTemplate(t *testing.T) {
+	db, err := database.NewMockDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	workoutRepo := repository.NewWorkoutRepository(nil, db.GetSQLite(), true)
+	h := NewShareHandler(workoutRepo)
+
+	workout, err := workoutRepo.CreateWorkout(t.Context(), database.DemoUserID, "Grip Day", models.WorkoutTypeStrength, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := workoutRepo.CreateExercise(t.Context(), database.DemoUserID, &models.Exercise{Name: "Farmer Carry", Sets: 3, Reps: 1, WorkoutID: workout.ID}); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := workoutRepo.SaveWorkoutAsTemplate(t.Context(), database.DemoUserID, workout.ID, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	asUser := func(id string) gin.HandlerFunc { return func(c *gin.Context) { c.Set(auth.UserIDKey, id) } }
+	r.POST("/owner/workout-templates/:id/share", asUser(database.DemoUserID), h.ShareTemplate)
+	r.DELETE("/owner/workout-templates/:id/share", asUser(database.DemoUserID), h.UnshareTemplate)
+	r.POST("/other/workout-templates/:id/share", asUser("someone-else"), h.ShareTemplate)
+	r.GET("/shared/templates/:token", h.SharedTemplate)
+	r.POST("/shared/templates/:token/clone", asUser("someone-else"), h.CloneSharedTemplate)
+	do := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w
+	}
+
+	for _, path := range []string{"/other/workout-templates/" + saved.ID + "/share", "/owner/workout-templates/push-pull-legs/share"} {
+		if w := do(http.MethodPost, path); w.Code != http.StatusNotFound {
+			t.Errorf("%s: got %d, want 404", path, w.Code)
+		}
+	}
+
+	w := do(http.MethodPost, "/owner/workout-templates/"+saved.ID+"/share")
+	var link struct{ Token, URL string }
+	if err := json.Unmarshal(w.Body.Bytes(), &link); w.Code != http.StatusOK || err != nil || link.Token == "" {
+		t.Fatalf("share: %d %s", w.Code, w.Body)
+	}
+
+	w = do(http.MethodGet, "/shared/templates/"+link.Token)
+	var preview models.WorkoutTemplate
+	if err := json.Unmarshal(w.Body.Bytes(), &preview); w.Code != http.StatusOK || err != nil || preview.Name != "Grip Day" || len(preview.Exercises) != 1 {
+		t.Fatalf("preview: %d %s", w.Code, w.Body)
+	}
+
+	w = do(http.MethodPost, "/shared/templates/"+link.Token+"/clone")
+	var clone models.WorkoutTemplate
+	if err := json.Unmarshal(w.Body.Bytes(), &clone); w.Code != http.StatusCreated || err != nil || clone.ID == saved.ID {
+		t.Fatalf("clone: %d %s", w.Code, w.Body)
+	}
+	if _, err := workoutRepo.GetWorkoutTemplate(t.Context(), "someone-else", clone.ID); err != nil {
+		t.Errorf("clone not in the other user's templates: %v", err)
+	}
+
+	// Sharing again or unsharing breaks the old link
+	do(http.MethodPost, "/owner/workout-templates/"+saved.ID+"/share")
+	if w := do(http.MethodGet, "/shared/templates/"+link.Token); w.Code != http.StatusNotFound {
+		t.Errorf("old link after resharing: got %d, want 404", w.Code)
+	}
+	if w := do(http.MethodDelete, "/owner/workout-templates/"+saved.ID+"/share"); w.Code != http.StatusOK {
+		t.Errorf("unshare: got %d", w.Code)
+	}
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/models"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SuspensionHandler lets admins suspend and reinstate accounts
+type SuspensionHandler struct {
+	userRepo *repository.UserRepository
+}
+
+// NewSuspensionHandler creates a new suspension handler
+func NewSuspensionHandler(userRepo *repository.UserRepository) *SuspensionHandler {
+	return &SuspensionHandler{userRepo: userRepo}
+}
+
+// SuspendRequest optionally records why an account was suspended or reinstated
+type SuspendRequest struct {
+	Reason string `json:"reason"`
+}
+
+// Suspend blocks a user from signing in and invalidates their tokens and API keys (admin only)
+func (h *SuspensionHandler) Suspend(c *gin.Context) {
+	h.setStatus(c, models.UserStatusSuspended)
+}
+
+// Reinstate lets a suspended user sign in again (admin only)
+func (h *SuspensionHandler) Reinstate(c *gin.Context) {
+	h.setStatus(c, models.UserStatusActive)
+}
+
+func (h *SuspensionHandler) setStatus(c *gin.Context, status string) {
+	var req SuspendRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+	}
+	if len(req.Reason) > 500 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reason must be at most 500 characters"})
+		return
+	}
+
+	userID := c.Param("id")
+	adminID := auth.GetUserID(c)
+	if userID == adminID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot suspend your own account"})
+		return
+	}
+	entry, err := h.userRepo.SetStatus(c.Request.Context(), userID, adminID, status, req.Reason)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("Error setting status for %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update account status"})
+		return
+	}
+	log.Printf("Admin %s set status of user %s to %s", adminID, userID, status)
+	c.JSON(http.StatusOK, gin.H{"id": userID, "status": status, "audit": entry})
+}
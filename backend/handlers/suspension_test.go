@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/database"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSuspendAccount(t *testing.T) {
+	db, err := database.NewMockDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	sqlite := db.GetSQLite()
+	userRepo := repository.NewUserRepository(nil, sqlite, true)
+	auth.SetRevocationChecker(repository.NewTokenRevocationRepository(nil, sqlite, true))
+	defer auth.SetRevocationChecker(nil)
+
+	admin, err := userRepo.CreateUser(t.Context(), "support@liftoff.test", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	adminToken, _, err := auth.GenerateToken(admin.ID, admin.Email, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userToken, _, err := auth.GenerateToken(database.DemoUserID, database.DemoUserEmail, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	suspensions := NewSuspensionHandler(userRepo)
+	r.POST("/login", NewAuthHandler(userRepo).Login)
+	authed := r.Group("/", auth.AuthMiddleware())
+	authed.GET("/me", NewAuthHandler(userRepo).Me)
+	authed.PUT("/admin/users/:id/suspend", suspensions.Suspend)
+	authed.DELETE("/admin/users/:id/suspend", suspensions.Reinstate)
+	do := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	login := `{"email": "` + database.DemoUserEmail + `", "password": "` + database.DemoUserPassword + `"}`
+
+	if w := do(http.MethodPut, "/admin/users/"+admin.ID+"/suspend", adminToken, ""); w.Code != http.StatusBadRequest {
+		t.Errorf("self-suspend: got %d: %s", w.Code, w.Body)
+	}
+	if w := do(http.MethodPut, "/admin/users/missing/suspend", adminToken, ""); w.Code != http.StatusNotFound {
+		t.Errorf("unknown user: got %d: %s", w.Code, w.Body)
+	}
+	w := do(http.MethodPut, "/admin/users/"+database.DemoUserID+"/suspend", adminToken, `{"reason": "chargeback"}`)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"status":"suspended"`) {
+		t.Fatalf("suspend: got %d: %s", w.Code, w.Body)
+	}
+
+	if w := do(http.MethodGet, "/me", userToken, ""); w.Code != http.StatusForbidden {
+		t.Errorf("existing token: got %d: %s", w.Code, w.Body)
+	}
+	if w := do(http.MethodPost, "/login", "", login); w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), "suspended") {
+		t.Errorf("login while suspended: got %d: %s", w.Code, w.Body)
+	}
+
+	entries, err := repository.NewAuditRepository(nil, sqlite, true).ListForUser(t.Context(), database.DemoUserID, "", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].ActorID != admin.ID || entries[0].Action != "account.suspended" {
+		t.Errorf("audit = %+v", entries)
+	}
+
+	if w := do(http.MethodDelete, "/admin/users/"+database.DemoUserID+"/suspend", adminToken, ""); w.Code != http.StatusOK {
+		t.Fatalf("reinstate: got %d: %s", w.Code, w.Body)
+	}
+	// Tokens from before the suspension stay invalid; signing in again works
+	if w := do(http.MethodGet, "/me", userToken, ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("old token after reinstating: got %d: %s", w.Code, w.Body)
+	}
+	if w := do(http.MethodPost, "/login", "", login); w.Code != http.StatusOK {
+		t.Errorf("login after reinstating: got %d: %s", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/models"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TaxonomyHandler serves the exercise category taxonomy and lets admins edit it
+type TaxonomyHandler struct {
+	taxonomyRepo *repository.TaxonomyRepository
+	workoutRepo  *repository.WorkoutRepository
+	onChange     func()
+}
+
+// NewTaxonomyHandler creates a new taxonomy handler
+func NewTaxonomyHandler(taxonomyRepo *repository.TaxonomyRepository, workoutRepo *repository.WorkoutRepository) *TaxonomyHandler {
+	return &TaxonomyHandler{taxonomyRepo: taxonomyRepo, workoutRepo: workoutRepo}
+}
+
+// OnChange registers fn to run after every successful edit, e.g. to drop cached responses
+func (h *TaxonomyHandler) OnChange(fn func()) {
+	h.onChange = fn
+}
+
+func (h *TaxonomyHandler) changed() {
+	if h.onChange != nil {
+		h.onChange()
+	}
+}
+
+// GetTaxonomy returns the categories, each with its muscle groups
+func (h *TaxonomyHandler) GetTaxonomy(c *gin.Context) {
+	tax, err := h.taxonomyRepo.Load(c.Request.Context())
+	if err != nil {
+		log.Printf("Error loading taxonomy: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load categories"})
+		return
+	}
+	c.JSON(http.StatusOK, tax)
+}
+
+// CategoryRequest is the request body for creating or updating a category.
+// A parent_id makes it a muscle group within that category.
+type CategoryRequest struct {
+	Name     string `json:"name" binding:"required"`
+	ParentID string `json:"parent_id"`
+	Position int    `json:"position"`
+}
+
+// CreateCategory adds a category or muscle group (admin only)
+func (h *TaxonomyHandler) CreateCategory(c *gin.Context) {
+	category, ok := bindCategory(c)
+	if !ok {
+		return
+	}
+	err := h.taxonomyRepo.CreateCategory(c.Request.Context(), category)
+	if !h.respondCategoryError(c, err, "create") {
+		return
+	}
+	log.Printf("Admin %s created exercise category %s (%q)", auth.GetUserID(c), category.ID, category.Name)
+	h.changed()
+	c.JSON(http.StatusCreated, category)
+}
+
+// UpdateCategory renames, moves or reorders a category (admin only)
+func (h *TaxonomyHandler) UpdateCategory(c *gin.Context) {
+	category, ok := bindCategory(c)
+	if !ok {
+		return
+	}
+	category.ID = c.Param("id")
+	err := h.taxonomyRepo.UpdateCategory(c.Request.Context(), category)
+	if !h.respondCategoryError(c, err, "update") {
+		return
+	}
+	log.Printf("Admin %s updated exercise category %s (%q)", auth.GetUserID(c), category.ID, category.Name)
+	h.changed()
+	c.JSON(http.StatusOK, category)
+}
+
+// DeleteCategory removes an empty category (admin only)
+func (h *TaxonomyHandler) DeleteCategory(c *gin.Context) {
+	id := c.Param("id")
+	err := h.taxonomyRepo.DeleteCategory(c.Request.Context(), id)
+	if !h.respondCategoryError(c, err, "delete") {
+		return
+	}
+	log.Printf("Admin %s deleted exercise category %s", auth.GetUserID(c), id)
+	h.changed()
+	c.JSON(http.StatusOK, gin.H{"message": "Category deleted"})
+}
+
+// SetExerciseCategoryRequest names the category or muscle group to file an exercise under
+type SetExerciseCategoryRequest struct {
+	CategoryID string `json:"category_id" binding:"required"`
+}
+
+// SetExerciseCategory files a library exercise under a category or muscle group (admin only)
+func (h *TaxonomyHandler) SetExerciseCategory(c *gin.Context) {
+	var req SetExerciseCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "category_id is required"})
+		return
+	}
+	name := h.workoutRepo.LibraryExerciseName(c.Param("name"))
+	if name == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Exercise is not in the library"})
+		return
+	}
+	err := h.taxonomyRepo.SetExerciseCategory(c.Request.Context(), name, req.CategoryID)
+	if !h.respondCategoryError(c, err, "update") {
+		return
+	}
+	log.Printf("Admin %s filed %q under exercise category %s", auth.GetUserID(c), name, req.CategoryID)
+	h.changed()
+	c.JSON(http.StatusOK, gin.H{"name": name, "category_id": req.CategoryID})
+}
+
+func bindCategory(c *gin.Context) (*models.ExerciseCategory, bool) {
+	var req CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return nil, false
+	}
+	name := strings.TrimSpace(req.Name)
+	if len(name) > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name must be at most 100 characters"})
+		return nil, false
+	}
+	return &models.ExerciseCategory{Name: name, ParentID: req.ParentID, Position: req.Position}, true
+}
+
+// respondCategoryError writes the response for a failed taxonomy edit and reports
+// whether err was nil
+func (h *TaxonomyHandler) respondCategoryError(c *gin.Context, err error, action string) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, repository.ErrCategoryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+	case errors.Is(err, repository.ErrCategoryNameTaken), errors.Is(err, repository.ErrCategoryInUse):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrCategoryDepth):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		log.Printf("Error trying to %s exercise category: %v", action, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to " + action + " category"})
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"liftoff/backend/database"
+	"liftoff/backend/models"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestTaxonomyAdmin(t *testing.T) {
+	db, err := database.NewMockDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	taxonomyRepo := repository.NewTaxonomyRepository(nil, db.GetSQLite(), true)
+	workoutRepo := repository.NewWorkoutRepository(nil, db.GetSQLite(), true)
+	handler := NewTaxonomyHandler(taxonomyRepo, workoutRepo)
+	changes := 0
+	handler.OnChange(func() { changes++ })
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/exercise-categories", handler.GetTaxonomy)
+	r.POST("/admin/exercise-categories", handler.CreateCategory)
+	r.PUT("/admin/exercise-categories/:id", handler.UpdateCategory)
+	r.DELETE("/admin/exercise-categories/:id", handler.DeleteCategory)
+	r.PUT("/admin/exercise-library/:name/category", handler.SetExerciseCategory)
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	classify := func(name string) (category, group string) {
+		tax, err := taxonomyRepo.Load(t.Context())
+		if err != nil {
+			t.Fatal(err)
+		}
+		templates, _ := workoutRepo.GetExerciseTemplates(t.Context(), "", tax)
+		for _, tmpl := range templates {
+			if tmpl.Name == name {
+				return tmpl.Category, tmpl.MuscleGroup
+			}
+		}
+		return "", ""
+	}
+
+	// Seeded from the built-in categories
+	var tax models.Taxonomy
+	w := do(http.MethodGet, "/exercise-categories", "")
+	if err := json.Unmarshal(w.Body.Bytes(), &tax); err != nil || len(tax.Categories) != len(models.DefaultExerciseCategories)-4 {
+		t.Fatalf("taxonomy: %d %s", w.Code, w.Body)
+	}
+	if category, group := classify("Hammer Curls"); category != "Arms" || group != "Biceps" {
+		t.Errorf("Hammer Curls = %q / %q", category, group)
+	}
+
+	w = do(http.MethodPost, "/admin/exercise-categories", `{"name": "Glutes", "parent_id": "legs", "position": 3}`)
+	var glutes models.ExerciseCategory
+	if err := json.Unmarshal(w.Body.Bytes(), &glutes); w.Code != http.StatusCreated || err != nil || glutes.ID == "" {
+		t.Fatalf("create: %d %s", w.Code, w.Body)
+	}
+	for body, want := range map[string]int{
+		`{"name": "Deeper", "parent_id": "biceps"}`: http.StatusBadRequest, // muscle groups do not nest
+		`{"name": "chest"}`:                         http.StatusConflict,
+		`{"name": "Forearms", "parent_id": "nope"}`: http.StatusNotFound,
+		`{"name": "  "}`:                            http.StatusBadRequest,
+	} {
+		if w := do(http.MethodPost, "/admin/exercise-categories", body); w.Code != want {
+			t.Errorf("create %s: got %d, want %d", body, w.Code, want)
+		}
+	}
+	if w := do(http.MethodPut, "/admin/exercise-categories/arms", `{"name": "Arms", "parent_id": "legs"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("nesting a category with muscle groups: got %d", w.Code)
+	}
+
+	if w := do(http.MethodPut, "/admin/exercise-library/lunges/category", `{"category_id": "`+glutes.ID+`"}`); w.Code != http.StatusOK {
+		t.Fatalf("file exercise: %d %s", w.Code, w.Body)
+	}
+	if category, group := classify("Lunges"); category != "Legs" || group != "Glutes" {
+		t.Errorf("Lunges = %q / %q", category, group)
+	}
+	if w := do(http.MethodPut, "/admin/exercise-library/Zercher%20Squat/category", `{"category_id": "legs"}`); w.Code != http.StatusNotFound {
+		t.Errorf("unknown exercise: got %d", w.Code)
+	}
+
+	// Moving the group to the top level makes it a category for analytics too
+	if w := do(http.MethodPut, "/admin/exercise-categories/"+glutes.ID, `{"name": "Glutes", "position": 8}`); w.Code != http.StatusOK {
+		t.Fatalf("update: %d %s", w.Code, w.Body)
+	}
+	if category, group := classify("Lunges"); category != "Glutes" || group != "" {
+		t.Errorf("Lunges after move = %q / %q", category, group)
+	}
+
+	if w := do(http.MethodDelete, "/admin/exercise-categories/"+glutes.ID, ""); w.Code != http.StatusConflict {
+		t.Errorf("delete category in use: got %d", w.Code)
+	}
+	do(http.MethodPut, "/admin/exercise-library/Lunges/category", `{"category_id": "quadriceps"}`)
+	if w := do(http.MethodDelete, "/admin/exercise-categories/"+glutes.ID, ""); w.Code != http.StatusOK {
+		t.Errorf("delete: got %d %s", w.Code, w.Body)
+	}
+	if w := do(http.MethodDelete, "/admin/exercise-categories/"+glutes.ID, ""); w.Code != http.StatusNotFound {
+		t.Errorf("delete again: got %d", w.Code)
+	}
+	if changes != 5 {
+		t.Errorf("OnChange ran %d times, want 5", changes)
+	}
+}
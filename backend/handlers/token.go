@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TokenHandler handles server-side token revocation and signed-in device management
+type TokenHandler struct {
+	revocationRepo  *repository.TokenRevocationRepository
+	userSessionRepo *repository.UserSessionRepository
+}
+
+// NewTokenHandler creates a new token handler
+func NewTokenHandler(revocationRepo *repository.TokenRevocationRepository, userSessionRepo *repository.UserSessionRepository) *TokenHandler {
+	return &TokenHandler{revocationRepo: revocationRepo, userSessionRepo: userSessionRepo}
+}
+
+// Logout revokes the token used to make this request
+func (h *TokenHandler) Logout(c *gin.Context) {
+	if !h.revokeCurrent(c) {
+		return
+	}
+	auth.ClearSessionCookie(c)
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out"})
+}
+
+// LogoutAll revokes every token issued to the user so far, signing out all devices
+func (h *TokenHandler) LogoutAll(c *gin.Context) {
+	userID := auth.GetUserID(c)
+	if err := h.revocationRepo.RevokeAllForUser(c.Request.Context(), userID, time.Now()); err != nil {
+		log.Printf("Error revoking tokens for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+		return
+	}
+	// Tokens issued within the current second are not covered by the cutoff
+	if claims := auth.GetClaims(c); claims != nil && claims.ID != "" && !h.revokeCurrent(c) {
+		return
+	}
+	auth.ClearSessionCookie(c)
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out of all devices"})
+}
+
+func (h *TokenHandler) revokeCurrent(c *gin.Context) bool {
+	claims := auth.GetClaims(c)
+	if claims == nil || claims.ID == "" {
+		// Tokens issued before jti was added can only be revoked with logout-all
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Token cannot be revoked individually"})
+		return false
+	}
+	expiresAt := time.Now().Add(time.Duration(auth.GetTokenConfig().RememberMeExpiryDays) * 24 * time.Hour)
+	if claims.ExpiresAt != nil {
+		expiresAt = claims.ExpiresAt.Time
+	}
+	if err := h.revocationRepo.RevokeToken(c.Request.Context(), claims.ID, claims.UserID, expiresAt); err != nil {
+		log.Printf("Error revoking token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+		return false
+	}
+	return true
+}
+
+// ListSessions shows the devices signed in to the user's account; the one making
+// the request is marked current
+func (h *TokenHandler) ListSessions(c *gin.Context) {
+	if auth.GetAPIKeyID(c) != "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "API keys cannot manage sessions"})
+		return
+	}
+	sessions, err := h.userSessionRepo.ListSessions(c.Request.Context(), auth.GetUserID(c))
+	if err != nil {
+		log.Printf("Error listing sessions: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions"})
+		return
+	}
+	if claims := auth.GetClaims(c); claims != nil {
+		for _, s := range sessions {
+			s.Current = s.ID == claims.ID
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"sessions": sessions})
+}
+
+// RevokeSession signs one device out by revoking its token
+func (h *TokenHandler) RevokeSession(c *gin.Context) {
+	if auth.GetAPIKeyID(c) != "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "API keys cannot manage sessions"})
+		return
+	}
+	userID := auth.GetUserID(c)
+	session, err := h.userSessionRepo.GetSession(c.Request.Context(), userID, c.Param("id"))
+	if errors.Is(err, repository.ErrUserSessionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+	if err == nil {
+		err = h.revocationRepo.RevokeToken(c.Request.Context(), session.ID, userID, session.ExpiresAt)
+	}
+	if err != nil {
+		log.Printf("Error revoking session: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked"})
+}
+
+// CreateScopedTokenRequest picks the scope and lifetime of a new token
+type CreateScopedTokenRequest struct {
+	Scope         string `json:"scope" binding:"required"`
+	ExpiresInDays int    `json:"expires_in_days"`
+}
+
+// CreateScopedToken mints a token limited to one scope, e.g. a read-only token for a
+// dashboard. It shows up in the session list and is revoked like any other session.
+// Only full sign-in tokens may mint them, so a leaked scoped token cannot widen itself.
+func (h *TokenHandler) CreateScopedToken(c *gin.Context) {
+	claims := auth.GetClaims(c)
+	if claims == nil || claims.Scope != "" || claims.ImpersonatedBy != "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Scoped tokens can only be created from a full sign-in"})
+		return
+	}
+	var req CreateScopedTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil || !auth.ValidScope(req.Scope) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "scope must be read or write"})
+		return
+	}
+	if req.ExpiresInDays == 0 {
+		req.ExpiresInDays = auth.DefaultScopedTokenDays
+	}
+	if req.ExpiresInDays < 1 || req.ExpiresInDays > auth.MaxScopedTokenDays {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expires_in_days must be between 1 and 365"})
+		return
+	}
+
+	token, expiresAt, err := auth.GenerateScopedToken(claims.UserID, claims.Email, req.Scope, time.Duration(req.ExpiresInDays)*24*time.Hour)
+	if err != nil {
+		log.Printf("Error generating scoped token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create token"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"token": token, "scope": req.Scope, "expires_at": expiresAt})
+}
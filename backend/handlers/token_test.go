@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/database"
+	"liftoff/backend/models"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestUserSessions(t *testing.T) {
+	db, err := database.NewMockDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	sqlite := db.GetSQLite()
+	revocationRepo := repository.NewTokenRevocationRepository(nil, sqlite, true)
+	sessionRepo := repository.NewUserSessionRepository(nil, sqlite, true)
+	auth.SetRevocationChecker(revocationRepo)
+	auth.SetSessionTracker(sessionRepo)
+	defer auth.SetRevocationChecker(nil)
+	defer auth.SetSessionTracker(nil)
+
+	h := NewTokenHandler(revocationRepo, sessionRepo)
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/sessions", auth.AuthMiddleware(), h.ListSessions)
+	r.DELETE("/sessions/:id", auth.AuthMiddleware(), h.RevokeSession)
+	do := func(method, path, token, device string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("User-Agent", device)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	list := func(token string) []models.UserSession {
+		w := do(http.MethodGet, "/sessions", token, "Laptop")
+		if w.Code != http.StatusOK {
+			t.Fatalf("list: %d %s", w.Code, w.Body.String())
+		}
+		var body struct {
+			Sessions []models.UserSession `json:"sessions"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		return body.Sessions
+	}
+
+	laptop, _, err := auth.GenerateToken(database.DemoUserID, database.DemoUserEmail, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	phone, _, _ := auth.GenerateToken(database.DemoUserID, database.DemoUserEmail, true)
+	do(http.MethodGet, "/sessions", phone, "Phone")
+
+	sessions := list(laptop)
+	if len(sessions) != 2 {
+		t.Fatalf("got %d sessions", len(sessions))
+	}
+	var phoneID string
+	for _, s := range sessions {
+		switch s.Device {
+		case "Laptop":
+			if !s.Current {
+				t.Error("laptop session should be current")
+			}
+		case "Phone":
+			phoneID = s.ID
+			if s.Current {
+				t.Error("phone session should not be current")
+			}
+		default:
+			t.Errorf("unexpected device %q", s.Device)
+		}
+	}
+
+	if w := do(http.MethodDelete, "/sessions/unknown", laptop, "Laptop"); w.Code != http.StatusNotFound {
+		t.Errorf("revoke unknown: %d", w.Code)
+	}
+	if w := do(http.MethodDelete, "/sessions/"+phoneID, laptop, "Laptop"); w.Code != http.StatusOK {
+		t.Fatalf("revoke: %d %s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodGet, "/sessions", phone, "Phone"); w.Code != http.StatusUnauthorized {
+		t.Errorf("revoked token: %d", w.Code)
+	}
+	if sessions := list(laptop); len(sessions) != 1 || !sessions[0].Current {
+		t.Errorf("after revoke = %+v", sessions)
+	}
+}
+
+func TestScopedTokens(t *testing.T) {
+	db, err := database.NewMockDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	sqlite := db.GetSQLite()
+	h := NewTokenHandler(repository.NewTokenRevocationRepository(nil, sqlite, true), repository.NewUserSessionRepository(nil, sqlite, true))
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/tokens", auth.AuthMiddleware(), h.CreateScopedToken)
+	scoped := r.Group("/workouts", auth.AuthMiddleware(), auth.RequireMethodScope())
+	scoped.GET("", func(c *gin.Context) { c.Status(http.StatusOK) })
+	scoped.POST("", func(c *gin.Context) { c.Status(http.StatusCreated) })
+	do := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	full, _, err := auth.GenerateToken(database.DemoUserID, database.DemoUserEmail, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := do(http.MethodPost, "/tokens", full, `{"scope":"admin"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown scope: %d", w.Code)
+	}
+	if w := do(http.MethodPost, "/tokens", full, `{"scope":"read","expires_in_days":400}`); w.Code != http.StatusBadRequest {
+		t.Errorf("too long: %d", w.Code)
+	}
+	w := do(http.MethodPost, "/tokens", full, `{"scope":"read","expires_in_days":7}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: %d %s", w.Code, w.Body.String())
+	}
+	var created struct {
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatal(err)
+	}
+
+	if w := do(http.MethodGet, "/workouts", created.Token, ""); w.Code != http.StatusOK {
+		t.Errorf("read-only GET: %d", w.Code)
+	}
+	if w := do(http.MethodPost, "/workouts", created.Token, `{}`); w.Code != http.StatusForbidden {
+		t.Errorf("read-only POST: %d", w.Code)
+	}
+	if w := do(http.MethodPost, "/workouts", full, `{}`); w.Code != http.StatusCreated {
+		t.Errorf("full POST: %d", w.Code)
+	}
+	if w := do(http.MethodPost, "/tokens", created.Token, `{"scope":"write"}`); w.Code != http.StatusForbidden {
+		t.Errorf("scoped token minting: %d", w.Code)
+	}
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/models"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WarmupHandler generates warm-up ramps for weighted exercises
+type WarmupHandler struct {
+	workoutRepo *repository.WorkoutRepository
+}
+
+// NewWarmupHandler creates a new warm-up handler
+func NewWarmupHandler(workoutRepo *repository.WorkoutRepository) *WarmupHandler {
+	return &WarmupHandler{workoutRepo: workoutRepo}
+}
+
+// Warmup returns the warm-up sets leading up to one of the user's exercises'
+// working weight. ?scheme=50x5,70x3,85x1 (percent x reps) and ?round_to=5
+// replace the default 40/60/80% ramp rounded to 2.5.
+func (h *WarmupHandler) Warmup(c *gin.Context) {
+	scheme, err := models.ParseWarmupScheme(c.Query("scheme"), c.Query("round_to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	exercise, err := h.workoutRepo.GetUserExercise(c.Request.Context(), auth.GetUserID(c), c.Param("id"))
+	if errors.Is(err, repository.ErrExerciseNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Exercise not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("Error loading exercise for warm-up: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate warm-up"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"exercise_id":    exercise.ID,
+		"working_weight": exercise.Weight,
+		"scheme":         scheme,
+		"sets":           scheme.Ramp(*exercise),
+	})
+}
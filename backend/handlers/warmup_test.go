@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/database"
+	"liftoff/backend/models"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestWarmup(t *testing.T) {
+	db, err := database.NewMockDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	workoutRepo := repository.NewWorkoutRepository(nil, db.GetSQLite(), true)
+	sessionRepo := repository.NewSessionRepository(nil, db.GetSQLite(), true)
+	h := NewWarmupHandler(workoutRepo)
+
+	workout, err := workoutRepo.CreateWorkout(t.Context(), database.DemoUserID, "Squat Day", models.WorkoutTypeStrength, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	squat := &models.Exercise{Name: "Squat", Sets: 3, Reps: 5, Weight: 140, WorkoutID: workout.ID}
+	if err := workoutRepo.CreateExercise(t.Context(), database.DemoUserID, squat); err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	asUser := func(id string) gin.HandlerFunc { return func(c *gin.Context) { c.Set(auth.UserIDKey, id) } }
+	r.GET("/owner/exercises/:id/warmup", asUser(database.DemoUserID), h.Warmup)
+	r.GET("/other/exercises/:id/warmup", asUser("someone-else"), h.Warmup)
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	w := get("/owner/exercises/" + squat.ID + "/warmup?scheme=50x5,75x2&round_to=5")
+	var body struct{ Sets []models.WarmupSet }
+	if err := json.Unmarshal(w.Body.Bytes(), &body); w.Code != http.StatusOK || err != nil {
+		t.Fatalf("got %d: %s", w.Code, w.Body)
+	}
+	if len(body.Sets) != 2 || body.Sets[0].Weight != 70 || body.Sets[1].Weight != 105 || body.Sets[1].Reps != 2 {
+		t.Errorf("sets = %+v", body.Sets)
+	}
+	if w := get("/owner/exercises/" + squat.ID + "/warmup?scheme=80x3,60x5"); w.Code != http.StatusBadRequest {
+		t.Errorf("falling scheme: got %d, want 400", w.Code)
+	}
+	if w := get("/other/exercises/" + squat.ID + "/warmup"); w.Code != http.StatusNotFound {
+		t.Errorf("another user's exercise: got %d, want 404", w.Code)
+	}
+
+	// Starting a session with warm-ups logs them ahead of the working sets
+	scheme := models.DefaultWarmupScheme()
+	session, err := sessionRepo.CreateSessionWithWarmups(t.Context(), database.DemoUserID, workout.ID, &scheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sets := session.Exercises[0].Sets
+	if len(sets) != 6 || !sets[0].IsWarmup() || sets[0].Weight != 55 || sets[2].Weight != 112.5 || sets[3].IsWarmup() || sets[3].Weight != 140 {
+		t.Errorf("session sets = %+v", sets)
+	}
+	if pace := models.NewSessionPace(session, session.StartedAt); pace.RemainingSets != 3 {
+		t.Errorf("remaining sets = %d, want the 3 working sets", pace.RemainingSets)
+	}
+
+	// Completed warm-ups stay out of volume, and editing one without a set_type keeps it a warm-up
+	for _, set := range []*models.ExerciseSet{sets[0], sets[3]} {
+		set.Completed = true
+		set.SetType = ""
+		if err := sessionRepo.UpdateExerciseSet(t.Context(), database.DemoUserID, set); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := sessionRepo.EndSession(t.Context(), database.DemoUserID, session.ID); err != nil {
+		t.Fatal(err)
+	}
+	loads, err := sessionRepo.GetSessionLoads(t.Context(), database.DemoUserID, session.StartedAt.Add(-time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loads) != 1 || loads[0].Sets != 1 || loads[0].Tonnage != 140*5 {
+		t.Errorf("loads = %+v, want only the working set", loads)
+	}
+	sets, err = sessionRepo.GetExerciseSets(t.Context(), sets[0].SessionExerciseID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sets[0].SetType != models.SetTypeWarmup || sets[3].SetType != models.SetTypeNormal {
+		t.Errorf("set types = %q, %q", sets[0].SetType, sets[3].SetType)
+	}
+}
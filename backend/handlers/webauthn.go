@@ -0,0 +1,308 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/models"
+	"liftoff/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// webauthnChallengeTTL bounds how long a ceremony may take
+const webauthnChallengeTTL = 5 * time.Minute
+
+// WebAuthnHandler handles passkey registration and login
+type WebAuthnHandler struct {
+	userRepo     *repository.UserRepository
+	webauthnRepo *repository.WebAuthnRepository
+}
+
+// NewWebAuthnHandler creates a new WebAuthn handler
+func NewWebAuthnHandler(userRepo *repository.UserRepository, webauthnRepo *repository.WebAuthnRepository) *WebAuthnHandler {
+	return &WebAuthnHandler{userRepo: userRepo, webauthnRepo: webauthnRepo}
+}
+
+// WebAuthnCredentialJSON is a PublicKeyCredential serialized with base64url fields (PublicKeyCredential.toJSON())
+type WebAuthnCredentialJSON struct {
+	ID       string `json:"id" binding:"required"`
+	RawID    string `json:"rawId"`
+	Type     string `json:"type"`
+	Response struct {
+		ClientDataJSON    string `json:"clientDataJSON" binding:"required"`
+		AttestationObject string `json:"attestationObject"`
+		AuthenticatorData string `json:"authenticatorData"`
+		Signature         string `json:"signature"`
+		UserHandle        string `json:"userHandle"`
+	} `json:"response"`
+}
+
+// WebAuthnFinishRequest completes a registration or login ceremony
+type WebAuthnFinishRequest struct {
+	ChallengeID string                 `json:"challengeId" binding:"required"`
+	Name        string                 `json:"name"`
+	Credential  WebAuthnCredentialJSON `json:"credential" binding:"required"`
+}
+
+// BeginRegistration returns PublicKeyCredentialCreationOptions for the signed-in user
+func (h *WebAuthnHandler) BeginRegistration(c *gin.Context) {
+	if auth.GetImpersonatorID(c) != "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Impersonation tokens cannot register passkeys"})
+		return
+	}
+	userID := auth.GetUserID(c)
+	cfg := auth.GetWebAuthnConfig()
+
+	user, err := h.userRepo.GetByID(c.Request.Context(), userID)
+	if err != nil || user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	challenge, challengeID, ok := h.newChallenge(c, userID, models.WebAuthnCeremonyRegister)
+	if !ok {
+		return
+	}
+
+	existing, err := h.webauthnRepo.GetCredentialsByUser(c.Request.Context(), userID)
+	if err != nil {
+		log.Printf("Error listing passkeys: %v", err)
+	}
+	exclude := make([]gin.H, 0, len(existing))
+	for _, cred := range existing {
+		exclude = append(exclude, gin.H{"type": "public-key", "id": cred.ID})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"challengeId": challengeID,
+		"publicKey": gin.H{
+			"challenge": challenge,
+			"rp":        gin.H{"id": cfg.RPID, "name": cfg.RPName},
+			"user": gin.H{
+				"id":          base64.RawURLEncoding.EncodeToString([]byte(user.ID)),
+				"name":        user.Email,
+				"displayName": user.Email,
+			},
+			"pubKeyCredParams": []gin.H{
+				{"type": "public-key", "alg": auth.COSEAlgES256},
+				{"type": "public-key", "alg": auth.COSEAlgEdDSA},
+				{"type": "public-key", "alg": auth.COSEAlgRS256},
+			},
+			"timeout":     webauthnChallengeTTL.Milliseconds(),
+			"attestation": "none",
+			"authenticatorSelection": gin.H{
+				"residentKey":      "preferred",
+				"userVerification": "preferred",
+			},
+			"excludeCredentials": exclude,
+		},
+	})
+}
+
+// FinishRegistration verifies the attestation and stores the new passkey
+func (h *WebAuthnHandler) FinishRegistration(c *gin.Context) {
+	userID := auth.GetUserID(c)
+
+	var req WebAuthnFinishRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Credential.Response.AttestationObject == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Challenge ID and credential are required"})
+		return
+	}
+
+	ch, err := h.webauthnRepo.ConsumeChallenge(c.Request.Context(), req.ChallengeID, models.WebAuthnCeremonyRegister)
+	if err != nil || ch.UserID != userID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Registration expired, please try again"})
+		return
+	}
+
+	clientData, err1 := auth.DecodeBase64URL(req.Credential.Response.ClientDataJSON)
+	attestation, err2 := auth.DecodeBase64URL(req.Credential.Response.AttestationObject)
+	if err1 != nil || err2 != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Malformed credential"})
+		return
+	}
+
+	registered, err := auth.GetWebAuthnConfig().VerifyRegistration(clientData, attestation, ch.Challenge)
+	if err != nil {
+		log.Printf("Passkey registration failed: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Passkey could not be verified"})
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = "Passkey"
+	}
+	if len(name) > 100 {
+		name = name[:100]
+	}
+
+	cred := &models.WebAuthnCredential{
+		ID:        base64.RawURLEncoding.EncodeToString(registered.ID),
+		UserID:    userID,
+		Name:      name,
+		PublicKey: registered.PublicKey,
+		Algorithm: registered.Algorithm,
+		SignCount: registered.SignCount,
+	}
+	if err := h.webauthnRepo.AddCredential(c.Request.Context(), cred); err != nil {
+		log.Printf("Error storing passkey: %v", err)
+		c.JSON(http.StatusConflict, gin.H{"error": "Passkey is already registered"})
+		return
+	}
+	c.JSON(http.StatusCreated, cred)
+}
+
+// BeginLogin returns PublicKeyCredentialRequestOptions.
+// With an email the user's passkeys are listed; without one the browser offers discoverable passkeys.
+func (h *WebAuthnHandler) BeginLogin(c *gin.Context) {
+	var req struct {
+		Email string `json:"email"`
+	}
+	_ = c.ShouldBindJSON(&req)
+
+	allow := []gin.H{}
+	if email := auth.NormalizeEmail(req.Email); email != "" {
+		// Unknown emails get an empty list rather than an error to avoid account enumeration
+		if user, err := h.userRepo.GetByEmail(c.Request.Context(), email); err == nil && user != nil {
+			creds, err := h.webauthnRepo.GetCredentialsByUser(c.Request.Context(), user.ID)
+			if err != nil {
+				log.Printf("Error listing passkeys: %v", err)
+			}
+			for _, cred := range creds {
+				allow = append(allow, gin.H{"type": "public-key", "id": cred.ID})
+			}
+		}
+	}
+
+	challenge, challengeID, ok := h.newChallenge(c, "", models.WebAuthnCeremonyLogin)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"challengeId": challengeID,
+		"publicKey": gin.H{
+			"challenge":        challenge,
+			"rpId":             auth.GetWebAuthnConfig().RPID,
+			"timeout":          webauthnChallengeTTL.Milliseconds(),
+			"userVerification": "preferred",
+			"allowCredentials": allow,
+		},
+	})
+}
+
+// FinishLogin verifies the assertion and issues a JWT like the password flow
+func (h *WebAuthnHandler) FinishLogin(c *gin.Context) {
+	var req WebAuthnFinishRequest
+	if err := c.ShouldBindJSON(&req); err != nil ||
+		req.Credential.Response.AuthenticatorData == "" || req.Credential.Response.Signature == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Challenge ID and credential are required"})
+		return
+	}
+
+	ch, err := h.webauthnRepo.ConsumeChallenge(c.Request.Context(), req.ChallengeID, models.WebAuthnCeremonyLogin)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Sign-in expired, please try again"})
+		return
+	}
+
+	cred, err := h.webauthnRepo.GetCredential(c.Request.Context(), strings.TrimRight(req.Credential.ID, "="))
+	if err != nil {
+		log.Printf("Error loading passkey: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Login failed"})
+		return
+	}
+	if cred == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unknown passkey"})
+		return
+	}
+
+	clientData, err1 := auth.DecodeBase64URL(req.Credential.Response.ClientDataJSON)
+	authData, err2 := auth.DecodeBase64URL(req.Credential.Response.AuthenticatorData)
+	signature, err3 := auth.DecodeBase64URL(req.Credential.Response.Signature)
+	if err1 != nil || err2 != nil || err3 != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Malformed credential"})
+		return
+	}
+
+	signCount, err := auth.GetWebAuthnConfig().VerifyAssertion(clientData, authData, signature, cred.PublicKey, ch.Challenge, cred.SignCount)
+	if err != nil {
+		log.Printf("Passkey assertion failed for credential %s: %v", cred.ID, err)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Passkey could not be verified"})
+		return
+	}
+	if req.Credential.Response.UserHandle != "" {
+		handle, err := auth.DecodeBase64URL(req.Credential.Response.UserHandle)
+		if err != nil || string(handle) != cred.UserID {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Passkey could not be verified"})
+			return
+		}
+	}
+	if err := h.webauthnRepo.MarkCredentialUsed(c.Request.Context(), cred.ID, signCount); err != nil {
+		log.Printf("Error updating passkey: %v", err)
+	}
+
+	user, err := h.userRepo.GetByID(c.Request.Context(), cred.UserID)
+	if err != nil || user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	tokenString, expiresAt, err := auth.GenerateToken(user.ID, user.Email, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+	respondAuth(c, http.StatusOK, user, tokenString, expiresAt)
+}
+
+// ListCredentials returns the signed-in user's passkeys
+func (h *WebAuthnHandler) ListCredentials(c *gin.Context) {
+	creds, err := h.webauthnRepo.GetCredentialsByUser(c.Request.Context(), auth.GetUserID(c))
+	if err != nil {
+		log.Printf("Error listing passkeys: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list passkeys"})
+		return
+	}
+	if creds == nil {
+		creds = []*models.WebAuthnCredential{}
+	}
+	c.JSON(http.StatusOK, gin.H{"credentials": creds})
+}
+
+// DeleteCredential removes one of the signed-in user's passkeys
+func (h *WebAuthnHandler) DeleteCredential(c *gin.Context) {
+	err := h.webauthnRepo.DeleteCredential(c.Request.Context(), auth.GetUserID(c), c.Param("id"))
+	if errors.Is(err, repository.ErrCredentialNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Passkey not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("Error deleting passkey: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete passkey"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Passkey deleted"})
+}
+
+func (h *WebAuthnHandler) newChallenge(c *gin.Context, userID, kind string) (string, string, bool) {
+	challenge, err := auth.NewWebAuthnChallenge()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start passkey ceremony"})
+		return "", "", false
+	}
+	id, err := h.webauthnRepo.CreateChallenge(c.Request.Context(), userID, kind, challenge, webauthnChallengeTTL)
+	if err != nil {
+		log.Printf("Error creating passkey challenge: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start passkey ceremony"})
+		return "", "", false
+	}
+	return challenge, id, true
+}
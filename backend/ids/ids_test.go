@@ -0,0 +1,33 @@
+package ids
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestNew(t *testing.T) {
+	before := time.Now().Truncate(time.Millisecond)
+	generated := make([]string, 1000)
+	for i := range generated {
+		generated[i] = New()
+	}
+	after := time.Now()
+
+	if !sort.StringsAreSorted(generated) {
+		t.Error("IDs are not in creation order")
+	}
+	at, ok := Time(generated[0])
+	if !ok || at.Before(before) || at.After(after) {
+		t.Errorf("Time(%s) = %v, %v; want between %v and %v", generated[0], at, ok, before, after)
+	}
+
+	if _, ok := Time(uuid.New().String()); ok {
+		t.Error("Time accepted a UUIDv4")
+	}
+	if _, ok := Time("not-an-id"); ok {
+		t.Error("Time accepted garbage")
+	}
+}
@@ -0,0 +1,120 @@
+package ipallow
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/netip"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+/**
+ * IP Allowlist Package
+ *
+ * Restricts a route group to clients from listed networks, e.g. locking the
+ * admin panel of a hosted deployment to office and VPN ranges. It is applied
+ * in addition to authentication, not instead of it.
+ *
+ * The client address is the TCP peer unless TRUSTED_PROXIES is set, since
+ * X-Forwarded-For from an untrusted peer can name any address.
+ */
+
+// Config lists the networks allowed through Middleware
+type Config struct {
+	Allowed []netip.Prefix
+	// TrustProxy uses gin's ClientIP, which honours X-Forwarded-For from the
+	// engine's trusted proxies, instead of the TCP peer address
+	TrustProxy bool
+}
+
+// Enabled reports whether any networks are listed; an empty allowlist restricts nothing
+func (cfg Config) Enabled() bool {
+	return len(cfg.Allowed) > 0
+}
+
+// Allows reports whether ip is in one of the allowed networks
+func (cfg Config) Allows(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	for _, p := range cfg.Allowed {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// TrustProxies points the engine's ClientIP at the proxies listed in
+// TRUSTED_PROXIES. Unset, no proxy is trusted and ClientIP is the TCP peer:
+// gin otherwise believes X-Forwarded-For from anyone, and per-IP limits keyed
+// on ClientIP could be dodged with a new header on every request.
+func TrustProxies(r *gin.Engine) error {
+	proxies := os.Getenv("TRUSTED_PROXIES")
+	if proxies == "" {
+		return r.SetTrustedProxies(nil)
+	}
+	if err := r.SetTrustedProxies(strings.Split(proxies, ",")); err != nil {
+		return fmt.Errorf("invalid TRUSTED_PROXIES: %w", err)
+	}
+	return nil
+}
+
+// FromEnv reads a comma-separated list of CIDRs or single addresses from the named
+// variable (e.g. ADMIN_ALLOWED_CIDRS). Invalid entries are an error rather than
+// skipped, so a typo cannot silently open or close the group.
+func FromEnv(name string) (Config, error) {
+	cfg := Config{TrustProxy: os.Getenv("TRUSTED_PROXIES") != ""}
+	for _, entry := range strings.Split(os.Getenv(name), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		prefix, err := ParsePrefix(entry)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s entry %q: %w", name, entry, err)
+		}
+		cfg.Allowed = append(cfg.Allowed, prefix)
+	}
+	return cfg, nil
+}
+
+// ParsePrefix parses a CIDR ("10.0.0.0/8") or a single address, which is taken as a /32 or /128
+func ParsePrefix(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		return prefix.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// Middleware answers 403 to clients outside the allowlist. With an empty allowlist
+// it lets every request through.
+func Middleware(cfg Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !cfg.Enabled() {
+			c.Next()
+			return
+		}
+		raw := c.RemoteIP()
+		if cfg.TrustProxy {
+			raw = c.ClientIP()
+		}
+		ip, err := netip.ParseAddr(raw)
+		if err != nil || !cfg.Allows(ip) {
+			log.Printf("Blocked %s %s from %s: not in allowlist", c.Request.Method, c.Request.URL.Path, raw)
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Access from this network is not allowed"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,76 @@
+package ipallow
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("TRUSTED_PROXIES", "")
+	t.Setenv("ADMIN_ALLOWED_CIDRS", " 10.1.2.3/8, 203.0.113.7 ,2001:db8::/32")
+	cfg, err := FromEnv("ADMIN_ALLOWED_CIDRS")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Allowed) != 3 || cfg.Allowed[0].String() != "10.0.0.0/8" || cfg.Allowed[1].String() != "203.0.113.7/32" || cfg.TrustProxy {
+		t.Errorf("cfg = %+v", cfg)
+	}
+
+	t.Setenv("ADMIN_ALLOWED_CIDRS", "10.0.0.0/8,office")
+	if _, err := FromEnv("ADMIN_ALLOWED_CIDRS"); err == nil {
+		t.Error("expected an error for an invalid entry")
+	}
+
+	t.Setenv("ADMIN_ALLOWED_CIDRS", "")
+	if cfg, err := FromEnv("ADMIN_ALLOWED_CIDRS"); err != nil || cfg.Enabled() {
+		t.Errorf("unset allowlist: cfg = %+v, err = %v", cfg, err)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	office, _ := ParsePrefix("198.51.100.0/24")
+	vpn, _ := ParsePrefix("2001:db8::/32")
+	serve := func(cfg Config, remote, forwarded string) int {
+		r := gin.New()
+		r.GET("/admin", Middleware(cfg), func(c *gin.Context) { c.Status(http.StatusOK) })
+		req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+		req.RemoteAddr = remote
+		if forwarded != "" {
+			req.Header.Set("X-Forwarded-For", forwarded)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	cfg := Config{Allowed: []netip.Prefix{office, vpn}}
+	cases := []struct {
+		remote, forwarded string
+		want              int
+	}{
+		{"198.51.100.20:5000", "", http.StatusOK},
+		{"[::ffff:198.51.100.20]:5000", "", http.StatusOK},
+		{"[2001:db8::1]:5000", "", http.StatusOK},
+		{"192.0.2.1:5000", "", http.StatusForbidden},
+		// Without trusted proxies a forged header does not help
+		{"192.0.2.1:5000", "198.51.100.20", http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		if got := serve(cfg, tc.remote, tc.forwarded); got != tc.want {
+			t.Errorf("%s (XFF %q): got %d, want %d", tc.remote, tc.forwarded, got, tc.want)
+		}
+	}
+
+	cfg.TrustProxy = true
+	if got := serve(cfg, "127.0.0.1:5000", "198.51.100.20"); got != http.StatusOK {
+		t.Errorf("forwarded through a trusted proxy: got %d", got)
+	}
+	if got := serve(Config{}, "192.0.2.1:5000", ""); got != http.StatusOK {
+		t.Errorf("empty allowlist: got %d", got)
+	}
+}
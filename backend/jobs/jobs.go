@@ -0,0 +1,108 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+/**
+ * Jobs Package
+ *
+ * Runs background work on a fixed interval inside the API process.
+ * Jobs are registered at startup and stopped when the context passed to
+ * Start is cancelled. Each job runs once immediately and then on every tick;
+ * a job that is still running when its next tick fires is skipped rather
+ * than run concurrently.
+ */
+
+// Func is the unit of work executed by a job
+type Func func(ctx context.Context) error
+
+// Job is a named piece of periodic work
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      Func
+}
+
+// Scheduler runs registered jobs in the background
+type Scheduler struct {
+	mu   sync.Mutex
+	jobs []Job
+	wg   sync.WaitGroup
+}
+
+// NewScheduler creates an empty scheduler
+func NewScheduler() *Scheduler {
+	return &Scheduler{}
+}
+
+// Register adds a job. Jobs with a non-positive interval are ignored.
+func (s *Scheduler) Register(name string, interval time.Duration, run Func) {
+	if interval <= 0 {
+		log.Printf("Job %s disabled (interval %v)", name, interval)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs = append(s.jobs, Job{Name: name, Interval: interval, Run: run})
+}
+
+// Start launches all registered jobs; they stop when ctx is cancelled
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, job)
+	}
+}
+
+// Wait blocks until every job loop has exited
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+// RunNow executes a registered job synchronously, returning false if it is unknown
+func (s *Scheduler) RunNow(ctx context.Context, name string) (bool, error) {
+	s.mu.Lock()
+	var found *Job
+	for i := range s.jobs {
+		if s.jobs[i].Name == name {
+			found = &s.jobs[i]
+			break
+		}
+	}
+	s.mu.Unlock()
+	if found == nil {
+		return false, nil
+	}
+	return true, found.Run(ctx)
+}
+
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	defer s.wg.Done()
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	s.runOnce(ctx, job)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.runOnce(ctx, job)
+		}
+	}
+}
+
+func (s *Scheduler) runOnce(ctx context.Context, job Job) {
+	start := time.Now()
+	if err := job.Run(ctx); err != nil {
+		log.Printf("Job %s failed after %v: %v", job.Name, time.Since(start), err)
+		return
+	}
+	log.Printf("Job %s completed in %v", job.Name, time.Since(start))
+}
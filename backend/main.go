@@ -1,15 +1,41 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"math"
 	"net/http"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"liftoff/backend/auth"
+	"liftoff/backend/auth/captcha"
+	"liftoff/backend/changelog"
+	"liftoff/backend/chaos"
+	"liftoff/backend/cors"
 	"liftoff/backend/database"
+	"liftoff/backend/deadline"
+	"liftoff/backend/deprecation"
+	"liftoff/backend/email"
 	"liftoff/backend/handlers"
+	"liftoff/backend/ipallow"
+	"liftoff/backend/jobs"
+	"liftoff/backend/maintenance"
+	"liftoff/backend/media"
 	"liftoff/backend/models"
+	"liftoff/backend/ratelimit"
 	"liftoff/backend/repository"
+	"liftoff/backend/requestid"
+	"liftoff/backend/respcache"
+	"liftoff/backend/retention"
+	"liftoff/backend/secheaders"
+	"liftoff/backend/units"
 
 	"github.com/gin-gonic/gin"
 )
@@ -24,66 +50,384 @@ import (
 // - Exercise templates for quick workout building
 // - Support for both PostgreSQL and SQLite databases
 
+var (
+	mockMode      = flag.Bool("mock", false, "serve the API from an in-memory database seeded with demo data")
+	mockLatency   = flag.Duration("mock-latency", 0, "with --mock, delay each API response by up to this duration")
+	mockErrorRate = flag.Float64("mock-error-rate", 0, "with --mock, fraction of API requests (0-1) that fail with a 500")
+)
+
 func main() {
+	flag.Parse()
+
+	// Fail fast on unreadable JWT keys rather than on the first login
+	if _, err := auth.GetKeySet(); err != nil {
+		log.Fatal("Failed to load JWT signing keys:", err)
+	}
+
 	// Initialize database connection
-	db, err := database.NewDatabase()
+	var db *database.Database
+	var err error
+	if *mockMode {
+		db, err = database.NewMockDatabase()
+	} else {
+		db, err = database.NewDatabase()
+	}
 	if err != nil {
 		log.Fatal("Failed to connect to database:", err)
 	}
 	defer db.Close()
 
+	// Retry transient PostgreSQL failures and fail fast while it is unreachable (DB_RETRY_*, DB_BREAKER_*)
+	pgPool := repository.NewResilientPool(db.GetPool(), repository.RetryConfigFromEnv())
+	pool := pgPool.AsPool()
+
 	// Initialize repositories for data access
-	workoutRepo := repository.NewWorkoutRepository(db.GetPool(), db.GetSQLite(), db.IsSQLite())
-	routineRepo := repository.NewRoutineRepository(db.GetPool(), db.GetSQLite(), db.IsSQLite(), workoutRepo)
-	sessionRepo := repository.NewSessionRepository(db.GetPool(), db.GetSQLite(), db.IsSQLite())
-	userRepo := repository.NewUserRepository(db.GetPool(), db.GetSQLite(), db.IsSQLite())
-	adminRepo := repository.NewAdminRepository(db.GetPool(), db.GetSQLite(), db.IsSQLite())
+	workoutRepo := repository.NewWorkoutRepository(pool, db.GetSQLite(), db.IsSQLite())
+	routineRepo := repository.NewRoutineRepository(pool, db.GetSQLite(), db.IsSQLite(), workoutRepo)
+	sessionRepo := repository.NewSessionRepository(pool, db.GetSQLite(), db.IsSQLite())
+	userRepo := repository.NewUserRepository(pool, db.GetSQLite(), db.IsSQLite())
+	adminRepo := repository.NewAdminRepository(pool, db.GetSQLite(), db.IsSQLite())
+	taxonomyRepo := repository.NewTaxonomyRepository(pool, db.GetSQLite(), db.IsSQLite())
+	recommendationRepo := repository.NewRecommendationRepository(pool, db.GetSQLite(), db.IsSQLite(), workoutRepo, taxonomyRepo)
+	injuryRepo := repository.NewInjuryRepository(pool, db.GetSQLite(), db.IsSQLite(), workoutRepo)
+	prefsRepo := repository.NewPreferencesRepository(pool, db.GetSQLite(), db.IsSQLite())
+	scheduleRepo := repository.NewScheduleRepository(pool, db.GetSQLite(), db.IsSQLite(), workoutRepo)
+	programRepo := repository.NewProgramRepository(pool, db.GetSQLite(), db.IsSQLite(), workoutRepo)
+	progressionRepo := repository.NewProgressionRepository(pool, db.GetSQLite(), db.IsSQLite(), workoutRepo, sessionRepo)
+	deprecationRepo := repository.NewDeprecationRepository(pool, db.GetSQLite(), db.IsSQLite())
+	webauthnRepo := repository.NewWebAuthnRepository(pool, db.GetSQLite(), db.IsSQLite())
+	revocationRepo := repository.NewTokenRevocationRepository(pool, db.GetSQLite(), db.IsSQLite())
+	apiKeyRepo := repository.NewAPIKeyRepository(pool, db.GetSQLite(), db.IsSQLite())
+	alertRepo := repository.NewAlertRepository(pool, db.GetSQLite(), db.IsSQLite())
+	userSessionRepo := repository.NewUserSessionRepository(pool, db.GetSQLite(), db.IsSQLite())
+	roleRepo := repository.NewRoleRepository(pool, db.GetSQLite(), db.IsSQLite())
+	retentionRepo := repository.NewRetentionRepository(pool, db.GetSQLite(), db.IsSQLite())
+	partnerRepo := repository.NewPartnerRepository(pool, db.GetSQLite(), db.IsSQLite())
+	auditRepo := repository.NewAuditRepository(pool, db.GetSQLite(), db.IsSQLite())
+	// Transactional email through EMAIL_PROVIDER (SMTP, SendGrid or SES), or logged when unset
+	mailer, err := email.SenderFromEnv()
+	if err != nil {
+		log.Fatal("Failed to configure email:", err)
+	}
 	authHandler := handlers.NewAuthHandler(userRepo)
+	authHandler.SetEmailSender(mailer)
+	// Maintenance mode and the registration kill-switch (MAINTENANCE_MODE, REGISTRATION_DISABLED, or admin at runtime).
+	// Sign-in, sign-out and the admin routes still accept writes during maintenance.
+	switches := maintenance.FromEnv("/api/auth/login", "/api/auth/logout", "/api/admin")
+	authHandler.SetRegistrationCheck(switches.RegistrationOpen)
+	maintenanceHandler := handlers.NewMaintenanceHandler(switches)
+	exportHandler := handlers.NewExportHandler(userRepo, workoutRepo, sessionRepo)
+	shareHandler := handlers.NewShareHandler(workoutRepo)
+	printableHandler := handlers.NewPrintableHandler(workoutRepo, taxonomyRepo)
+	warmupHandler := handlers.NewWarmupHandler(workoutRepo)
+	// Uploaded form videos and images, kept under MEDIA_DIR
+	mediaStore, err := media.NewStore(media.ConfigFromEnv())
+	if err != nil {
+		log.Fatal("Failed to configure media storage:", err)
+	}
+	mediaHandler := handlers.NewMediaHandler(mediaStore)
+	partnerHandler := handlers.NewPartnerHandler(partnerRepo, sessionRepo, workoutRepo, userRepo)
+	webauthnHandler := handlers.NewWebAuthnHandler(userRepo, webauthnRepo)
 	adminHandler := handlers.NewAdminHandler(userRepo, adminRepo)
+	roleHandler := handlers.NewRoleHandler(roleRepo)
+	suspensionHandler := handlers.NewSuspensionHandler(userRepo)
+	hardDeleteHandler := handlers.NewHardDeleteHandler(userRepo)
+	retentionPolicy := retention.PolicyFromEnv()
+	retentionHandler := handlers.NewRetentionHandler(retentionRepo, retentionPolicy)
+	recommendationHandler := handlers.NewRecommendationHandler(recommendationRepo)
+	injuryHandler := handlers.NewInjuryHandler(injuryRepo)
+	preferencesHandler := handlers.NewPreferencesHandler(prefsRepo)
+	scheduleHandler := handlers.NewScheduleHandler(scheduleRepo)
+	programHandler := handlers.NewProgramHandler(programRepo)
+	progressionHandler := handlers.NewProgressionHandler(progressionRepo)
+	tokenHandler := handlers.NewTokenHandler(revocationRepo, userSessionRepo)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyRepo)
+	emailChangeHandler := handlers.NewEmailChangeHandler(userRepo, revocationRepo)
+	emailChangeHandler.SetEmailSender(mailer)
+	guestHandler := handlers.NewGuestHandler(userRepo, revocationRepo)
+	guestHandler.SetEmailSender(mailer)
+	analyticsHandler := handlers.NewAnalyticsHandler(sessionRepo, alertRepo)
+	correctionHandler := handlers.NewCorrectionHandler(sessionRepo, auditRepo, recommendationRepo, analyticsHandler)
+	calcHandler := handlers.NewCalcHandler()
+	changelogHandler := handlers.NewChangelogHandler()
+	taxonomyHandler := handlers.NewTaxonomyHandler(taxonomyRepo, workoutRepo)
+
+	// AuthMiddleware rejects tokens revoked via logout / logout-all
+	auth.SetRevocationChecker(revocationRepo)
+	// ...and accepts "Authorization: ApiKey <key>" for personal API keys
+	auth.SetAPIKeyResolver(apiKeyRepo)
+	// ...and records the device and last-seen time of each token for session management
+	auth.SetSessionTracker(userSessionRepo)
+	// ...and RequireRole reads roles from the database, with ADMIN_EMAILS as a bootstrap fallback
+	auth.SetRoleResolver(roleRepo)
+	// New passwords are screened against Have I Been Pwned when PASSWORD_BREACH_CHECK is set
+	auth.SetBreachChecker(auth.BreachCheckerFromEnv())
+	// Sign-up and forgot-password require a captcha token when CAPTCHA_PROVIDER is set
+	captcha.SetVerifier(captcha.FromEnv())
+
+	// Deprecated routes are wrapped with deprecations.Deprecate(models.DeprecationNotice{...})
+	// so clients see Deprecation/Sunset headers and admins can track remaining callers
+	deprecations := deprecation.NewRegistry(deprecationRepo)
+	deprecationHandler := handlers.NewDeprecationHandler(deprecations, deprecationRepo)
+
+	// Token-bucket rate limits per user (or IP when anonymous); auth routes get a tighter bucket
+	authLimiter := ratelimit.LimiterFromEnv("AUTH_RATE_LIMIT", 20, 10)
+	apiLimiter := ratelimit.LimiterFromEnv("RATE_LIMIT", 300, 60)
+
+	// Memoized responses for expensive reads (RESPONSE_CACHE_TTL), dropped on the user's next write
+	respCache := respcache.FromEnv()
+	taxonomyHandler.OnChange(func() {
+		respCache.Invalidate("exercise-templates", "")
+		respCache.Invalidate("exercise-categories", "")
+	})
+
+	// Background jobs (stopped when main returns)
+	jobCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	scheduler := jobs.NewScheduler()
+	scheduler.Register("template-recommendations", durationFromEnv("RECOMMENDATIONS_REFRESH_INTERVAL", 24*time.Hour), recommendationRepo.RefreshAll)
+	scheduler.Register("deprecated-usage-flush", durationFromEnv("DEPRECATION_FLUSH_INTERVAL", time.Minute), deprecations.Flush)
+	scheduler.Register("revoked-token-purge", durationFromEnv("TOKEN_PURGE_INTERVAL", time.Hour), revocationRepo.PurgeExpired)
+	scheduler.Register("rate-limit-sweep", durationFromEnv("RATE_LIMIT_SWEEP_INTERVAL", 10*time.Minute), func(ctx context.Context) error {
+		_ = authLimiter.Sweep(ctx)
+		return apiLimiter.Sweep(ctx)
+	})
+	scheduler.Register("response-cache-sweep", durationFromEnv("RESPONSE_CACHE_SWEEP_INTERVAL", 10*time.Minute), respCache.Sweep)
+	scheduler.Register("login-attempt-purge", durationFromEnv("LOGIN_ATTEMPT_PURGE_INTERVAL", time.Hour), func(ctx context.Context) error {
+		return userRepo.PurgeLoginAttempts(ctx, auth.GetLockoutConfig().FailureWindow)
+	})
+	scheduler.Register("login-event-purge", durationFromEnv("LOGIN_EVENT_PURGE_INTERVAL", 24*time.Hour), func(ctx context.Context) error {
+		return userRepo.PurgeLoginEvents(ctx, durationFromEnv("LOGIN_EVENT_RETENTION", 365*24*time.Hour))
+	})
+	scheduler.Register("one-time-token-purge", durationFromEnv("TOKEN_PURGE_INTERVAL", time.Hour), userRepo.PurgeExpiredOneTimeTokens)
+	scheduler.Register("email-change-purge", durationFromEnv("EMAIL_CHANGE_PURGE_INTERVAL", time.Hour), userRepo.PurgeExpiredEmailChanges)
+	scheduler.Register("guest-account-purge", durationFromEnv("GUEST_PURGE_INTERVAL", 24*time.Hour), func(ctx context.Context) error {
+		return userRepo.PurgeIdleGuests(ctx, auth.GuestTTL())
+	})
+	// Analytics materialized views only exist on Postgres
+	analyticsRefreshInterval := durationFromEnv("ANALYTICS_REFRESH_INTERVAL", 15*time.Minute)
+	if db.IsSQLite() {
+		analyticsRefreshInterval = 0
+	}
+	scheduler.Register("analytics-view-refresh", analyticsRefreshInterval, sessionRepo.RefreshAnalyticsViews)
+	scheduler.Register("user-session-purge", durationFromEnv("USER_SESSION_PURGE_INTERVAL", time.Hour), userSessionRepo.PurgeExpired)
+	// Inactivity retention is off unless RETENTION_INACTIVE_DAYS is set
+	retentionInterval := durationFromEnv("RETENTION_CHECK_INTERVAL", 24*time.Hour)
+	if !retentionPolicy.Enabled() {
+		retentionInterval = 0
+	}
+	retentionEnforcer := retention.NewEnforcer(retentionRepo, userRepo.DeleteAccount, retentionPolicy, handlers.InactivityNotifier(mailer))
+	scheduler.Register("inactivity-retention", retentionInterval, retentionEnforcer.Run)
+	scheduler.Start(jobCtx)
 
 	// Setup Gin router with default middleware (Logger and Recovery)
 	r := gin.Default()
+	// Forwarded client addresses are only believed from TRUSTED_PROXIES, and from no one when unset
+	if err := ipallow.TrustProxies(r); err != nil {
+		log.Fatal("Failed to configure trusted proxies:", err)
+	}
 
-	// Add CORS middleware for frontend integration
-	r.Use(func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
-		c.Header("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+	// Optional network allowlist for the admin panel, checked before authentication
+	adminNetworks, err := ipallow.FromEnv("ADMIN_ALLOWED_CIDRS")
+	if err != nil {
+		log.Fatal("Failed to configure admin allowlist:", err)
+	}
 
-		// Handle preflight requests
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
+	// X-Request-ID on every response, for matching bug reports to logs
+	r.Use(requestid.Middleware())
 
-		c.Next()
-	})
+	// CSP, X-Frame-Options, Referrer-Policy and HSTS over HTTPS (SECURITY_* env)
+	r.Use(secheaders.Middleware(secheaders.ConfigFromEnv()))
+
+	// CORS allowlist for the frontend (CORS_* env, defaults to FRONTEND_URL)
+	r.Use(cors.Middleware(cors.ConfigFromEnv()))
 
 	// API routes group - all endpoints under /api
 	api := r.Group("/api")
+	// Each request's context gets a REQUEST_TIMEOUT deadline; the full data export may run longer
+	api.Use(deadline.Middleware(deadline.FromEnv(), "/api/auth/export"))
+	api.Use(ratelimit.Middleware(
+		ratelimit.Rule{Prefix: "/api/auth", Limiter: authLimiter},
+		ratelimit.Rule{Prefix: "/api", Limiter: apiLimiter},
+	))
+	api.Use(respCache.InvalidateOnWrite())
+	api.Use(failFastWhileDatabaseDown(pgPool, "/api/calc", "/api/changelog"))
+	api.Use(switches.Middleware())
+
+	// Dev-only fault injection (CHAOS_* env, or --mock-latency/--mock-error-rate)
+	chaosConfig := chaos.ConfigFromEnv()
+	if *mockMode {
+		if token, _, err := auth.GenerateToken(database.DemoUserID, database.DemoUserEmail, true); err == nil {
+			log.Printf("Mock mode: demo token for Authorization: Bearer %s", token)
+		}
+		if *mockLatency > 0 {
+			chaosConfig.MaxLatency = *mockLatency
+		}
+		if *mockErrorRate > 0 {
+			chaosConfig.ErrorRate = *mockErrorRate
+		}
+	}
+	if chaosConfig.Enabled() {
+		if gin.Mode() == gin.ReleaseMode && !*mockMode {
+			log.Println("Ignoring CHAOS_* settings in release mode")
+		} else {
+			log.Printf("Chaos injection enabled: %s", chaosConfig)
+			api.Use(chaos.Middleware(chaosConfig))
+		}
+	}
+
+	// Read-only tokens (POST /api/auth/tokens) may call GET routes but never change anything
+	writeScope := auth.RequireScope(auth.ScopeWrite)
 	{
 		// Auth routes (no middleware required for login/register)
 		api.POST("/auth/login", authHandler.Login)
-		api.POST("/auth/register", authHandler.Register)
+		api.POST("/auth/register", switches.RegistrationMiddleware(), authHandler.Register)
+		api.POST("/auth/guest", switches.RegistrationMiddleware(), guestHandler.Start)
+		api.POST("/auth/guest/claim", switches.RegistrationMiddleware(), auth.AuthMiddleware(), writeScope, guestHandler.Claim)
 		api.POST("/auth/forgot-password", authHandler.ForgotPassword)
 		api.POST("/auth/reset-password", authHandler.ResetPassword)
+		api.POST("/auth/magic-link", authHandler.RequestMagicLink)
+		api.POST("/auth/magic-login", authHandler.MagicLogin)
 		api.GET("/auth/me", auth.AuthMiddleware(), authHandler.Me)
+		api.POST("/auth/logout", auth.AuthMiddleware(), tokenHandler.Logout)
+		api.POST("/auth/logout-all", auth.AuthMiddleware(), writeScope, tokenHandler.LogoutAll)
+		api.GET("/auth/sessions", auth.AuthMiddleware(), tokenHandler.ListSessions)
+		api.DELETE("/auth/sessions/:id", auth.AuthMiddleware(), writeScope, tokenHandler.RevokeSession)
+		api.POST("/auth/reauth", auth.AuthMiddleware(), writeScope, authHandler.Reauth)
+		api.POST("/auth/change-email", auth.AuthMiddleware(), writeScope, auth.RegisteredMiddleware(), auth.RequireReauth(), emailChangeHandler.RequestChange)
+		api.POST("/auth/confirm-email-change", emailChangeHandler.ConfirmChange)
+		api.DELETE("/auth/account", auth.AuthMiddleware(), writeScope, auth.RequireReauth(), authHandler.DeleteAccount)
+		api.POST("/auth/account/merge", auth.AuthMiddleware(), writeScope, auth.RegisteredMiddleware(), authHandler.MergeAccount)
+		api.GET("/auth/export", auth.AuthMiddleware(), exportHandler.Export)
+		api.GET("/auth/google/login", authHandler.GoogleLogin)
+		api.GET("/auth/google/callback", authHandler.GoogleCallback)
+		api.POST("/auth/apple", authHandler.AppleSignIn)
+		api.GET("/auth/oidc/config", authHandler.OIDCConfig)
+		api.GET("/auth/oidc/login", authHandler.OIDCLogin)
+		api.GET("/auth/oidc/callback", authHandler.OIDCCallback)
+		api.POST("/auth/webauthn/login/begin", webauthnHandler.BeginLogin)
+		api.POST("/auth/webauthn/login/finish", webauthnHandler.FinishLogin)
+		api.POST("/auth/webauthn/register/begin", auth.AuthMiddleware(), writeScope, auth.RegisteredMiddleware(), webauthnHandler.BeginRegistration)
+		api.POST("/auth/webauthn/register/finish", auth.AuthMiddleware(), writeScope, auth.RegisteredMiddleware(), webauthnHandler.FinishRegistration)
+		api.GET("/auth/webauthn/credentials", auth.AuthMiddleware(), webauthnHandler.ListCredentials)
+		api.DELETE("/auth/webauthn/credentials/:id", auth.AuthMiddleware(), writeScope, webauthnHandler.DeleteCredential)
+		api.GET("/auth/api-keys", auth.AuthMiddleware(), apiKeyHandler.List)
+		api.POST("/auth/api-keys", auth.AuthMiddleware(), writeScope, auth.RegisteredMiddleware(), auth.RequireReauth(), apiKeyHandler.Create)
+		api.DELETE("/auth/api-keys/:id", auth.AuthMiddleware(), writeScope, apiKeyHandler.Revoke)
+		api.POST("/auth/tokens", auth.AuthMiddleware(), auth.RegisteredMiddleware(), tokenHandler.CreateScopedToken)
+
+		// Whether the API is in maintenance and open for registration (public)
+		api.GET("/status", maintenanceHandler.GetStatus)
+
+		// Release notes (public, identical for every caller)
+		api.GET("/changelog", respCache.Public("changelog", time.Hour), changelogHandler.GetChangelog)
+
+		// Strength calculators (stateless, no auth required)
+		api.GET("/calc/e1rm", calcHandler.OneRepMax)
+		api.GET("/calc/percent-table", calcHandler.PercentTable)
+		api.GET("/calc/warmups", calcHandler.Warmups)
 
 		// Admin routes (auth + admin role required)
 		adminAPI := api.Group("/admin")
-		adminAPI.Use(auth.AuthMiddleware(), auth.AdminMiddleware())
+		adminAPI.Use(ipallow.Middleware(adminNetworks), auth.AuthMiddleware(), auth.RequireMethodScope(), auth.AdminMiddleware())
 		{
 			adminAPI.GET("/users", adminHandler.ListUsers)
+			adminAPI.GET("/users/duplicates", adminHandler.ListDuplicates)
+			adminAPI.POST("/users/merge", adminHandler.MergeUsers)
+			adminAPI.POST("/users/:id/impersonate", adminHandler.Impersonate)
 			adminAPI.GET("/stats", adminHandler.GetStats)
+			adminAPI.GET("/maintenance", maintenanceHandler.Get)
+			adminAPI.PUT("/maintenance", maintenanceHandler.Set)
+			adminAPI.GET("/deprecations", deprecationHandler.GetReport)
+			adminAPI.GET("/retention", retentionHandler.GetReport)
+			adminAPI.GET("/roles", roleHandler.ListRoles)
+			adminAPI.PUT("/users/:id/role", roleHandler.GrantRole)
+			adminAPI.DELETE("/users/:id/role", roleHandler.RevokeRole)
+			adminAPI.PUT("/users/:id/suspend", suspensionHandler.Suspend)
+			adminAPI.DELETE("/users/:id/suspend", suspensionHandler.Reinstate)
+			adminAPI.PUT("/users/:id/legal-hold", hardDeleteHandler.PlaceLegalHold)
+			adminAPI.DELETE("/users/:id/legal-hold", hardDeleteHandler.ReleaseLegalHold)
+			adminAPI.POST("/users/:id/records/:kind/:recordId/hard-delete", hardDeleteHandler.RequestHardDelete)
+			adminAPI.DELETE("/users/:id/records/:kind/:recordId", hardDeleteHandler.HardDelete)
+			adminAPI.POST("/exercise-categories", taxonomyHandler.CreateCategory)
+			adminAPI.PUT("/exercise-categories/:id", taxonomyHandler.UpdateCategory)
+			adminAPI.DELETE("/exercise-categories/:id", taxonomyHandler.DeleteCategory)
+			adminAPI.PUT("/exercise-library/:name/category", taxonomyHandler.SetExerciseCategory)
 		}
 	}
+	// Weights are stored in pounds; users who prefer metric send and receive kilograms
+	unitsMiddleware := units.Middleware(prefsRepo.Units)
 	authAPI := api.Group("")
-	authAPI.Use(auth.AuthMiddleware())
+	authAPI.Use(auth.AuthMiddleware(), auth.RequireMethodScope(), unitsMiddleware)
 	{
 		userID := func(c *gin.Context) string { return auth.GetUserID(c) }
+		const workoutTypeError = "type must be strength, cardio, flexibility, hiit, endurance, power or empty"
+		// templateScaler fits built-in template exercises to the user's logged bests and
+		// training level. Lookup errors are logged and the template is copied unscaled.
+		templateScaler := func(c *gin.Context) func(models.Exercise) models.Exercise {
+			profile, err := recommendationRepo.GetTrainingProfile(c.Request.Context(), userID(c))
+			if err != nil {
+				log.Printf("Error loading training profile for template scaling: %v", err)
+				return nil
+			}
+			bests, err := sessionRepo.GetExerciseBests(c.Request.Context(), userID(c), time.Now())
+			if err != nil {
+				log.Printf("Error loading exercise bests for template scaling: %v", err)
+				return nil
+			}
+			return workoutRepo.TemplateScaler(profile.Level, bests)
+		}
 		// Workout management endpoints
+		// Archived workouts are left out unless ?include_archived=true; ?tag=legs and ?type=strength narrow the list.
+		// ?sort=name|created_at and ?order=asc|desc order it. With ?limit= (and ?offset=) the response is a
+		// models.WorkoutPage carrying the total; without it every match is returned as a plain array.
 		authAPI.GET("/workouts", func(c *gin.Context) {
-			workouts, err := workoutRepo.GetWorkouts(c.Request.Context(), userID(c))
+			filter := repository.WorkoutFilter{
+				IncludeArchived: c.Query("include_archived") == "true",
+				Type:            c.Query("type"),
+				Sort:            c.DefaultQuery("sort", repository.WorkoutSortCreatedAt),
+			}
+			if tag := c.Query("tag"); tag != "" {
+				normalized, err := models.NormalizeTag(tag)
+				if err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+					return
+				}
+				filter.Tag = normalized
+			}
+			if !models.ValidWorkoutType(filter.Type) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": workoutTypeError})
+				return
+			}
+			if filter.Sort != repository.WorkoutSortCreatedAt && filter.Sort != repository.WorkoutSortName {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "sort must be name or created_at"})
+				return
+			}
+			switch c.Query("order") {
+			case "":
+			case "asc", "desc":
+				desc := c.Query("order") == "desc"
+				filter.Descending = &desc
+			default:
+				c.JSON(http.StatusBadRequest, gin.H{"error": "order must be asc or desc"})
+				return
+			}
+			_, paged := c.GetQuery("limit")
+			if paged {
+				limit, err := strconv.Atoi(c.Query("limit"))
+				if err != nil || limit <= 0 || limit > 100 {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 100"})
+					return
+				}
+				offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+				if err != nil || offset < 0 {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "offset must not be negative"})
+					return
+				}
+				filter.Limit, filter.Offset = limit, offset
+			}
+
+			workouts, err := workoutRepo.GetWorkouts(c.Request.Context(), userID(c), filter)
 			if err != nil {
 				log.Printf("Error fetching workouts: %v", err)
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch workouts"})
@@ -92,18 +436,38 @@ func main() {
 			if workouts == nil {
 				workouts = []*models.Workout{}
 			}
-			c.JSON(http.StatusOK, workouts)
+			if !paged {
+				c.JSON(http.StatusOK, workouts)
+				return
+			}
+			total, err := workoutRepo.CountWorkouts(c.Request.Context(), userID(c), filter)
+			if err != nil {
+				log.Printf("Error counting workouts: %v", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch workouts"})
+				return
+			}
+			c.JSON(http.StatusOK, models.WorkoutPage{Workouts: workouts, Total: total, Limit: filter.Limit, Offset: filter.Offset})
 		})
 
 		authAPI.POST("/workouts", func(c *gin.Context) {
 			var input struct {
-				Name string `json:"name" binding:"required"`
+				Name  string `json:"name" binding:"required"`
+				Type  string `json:"type"`
+				Notes string `json:"notes"`
 			}
 			if err := c.ShouldBindJSON(&input); err != nil {
 				c.JSON(http.StatusBadRequest, gin.H{"error": "Workout name is required"})
 				return
 			}
-			workout, err := workoutRepo.CreateWorkout(c.Request.Context(), userID(c), input.Name)
+			if !models.ValidWorkoutType(input.Type) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": workoutTypeError})
+				return
+			}
+			if len(input.Notes) > models.MaxWorkoutNotesLen {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("notes must be at most %d characters", models.MaxWorkoutNotesLen)})
+				return
+			}
+			workout, err := workoutRepo.CreateWorkout(c.Request.Context(), userID(c), input.Name, input.Type, input.Notes)
 			if err != nil {
 				log.Printf("Error creating workout: %v", err)
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create workout"})
@@ -112,6 +476,27 @@ func main() {
 			c.JSON(http.StatusCreated, workout)
 		})
 
+		// Case-insensitive name search, ranked; ?exercises=true also matches exercise names
+		authAPI.GET("/workouts/search", func(c *gin.Context) {
+			q := strings.TrimSpace(c.Query("q"))
+			if q == "" || len(q) > 100 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "q must be 1 to 100 characters"})
+				return
+			}
+			limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+			if err != nil || limit <= 0 || limit > 100 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 100"})
+				return
+			}
+			results, err := workoutRepo.SearchWorkouts(c.Request.Context(), userID(c), q, c.Query("exercises") == "true", limit)
+			if err != nil {
+				log.Printf("Error searching workouts: %v", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search workouts"})
+				return
+			}
+			c.JSON(http.StatusOK, results)
+		})
+
 		authAPI.GET("/workouts/:id", func(c *gin.Context) {
 			workout, err := workoutRepo.GetWorkout(c.Request.Context(), userID(c), c.Param("id"))
 			if err != nil {
@@ -121,7 +506,207 @@ func main() {
 			c.JSON(http.StatusOK, workout)
 		})
 
+		authAPI.PUT("/workouts/:id", func(c *gin.Context) {
+			var input struct {
+				Name  *string `json:"name"`
+				Type  *string `json:"type"`
+				Notes *string `json:"notes"`
+			}
+			if err := c.ShouldBindJSON(&input); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if input.Name == nil && input.Type == nil && input.Notes == nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "name, type or notes is required"})
+				return
+			}
+			if input.Notes != nil && len(*input.Notes) > models.MaxWorkoutNotesLen {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("notes must be at most %d characters", models.MaxWorkoutNotesLen)})
+				return
+			}
+			if input.Name != nil {
+				trimmed := strings.TrimSpace(*input.Name)
+				if trimmed == "" || len(trimmed) > 255 {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "name must be 1 to 255 characters"})
+					return
+				}
+				input.Name = &trimmed
+			}
+			if input.Type != nil && !models.ValidWorkoutType(*input.Type) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": workoutTypeError})
+				return
+			}
+			err := workoutRepo.UpdateWorkout(c.Request.Context(), userID(c), c.Param("id"), input.Name, input.Type, input.Notes)
+			if err != nil {
+				if errors.Is(err, repository.ErrWorkoutNotFound) {
+					c.JSON(http.StatusNotFound, gin.H{"error": "Workout not found"})
+					return
+				}
+				log.Printf("Error updating workout: %v", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update workout"})
+				return
+			}
+			workout, err := workoutRepo.GetWorkout(c.Request.Context(), userID(c), c.Param("id"))
+			if err != nil {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Workout not found"})
+				return
+			}
+			c.JSON(http.StatusOK, workout)
+		})
+
+		// Snapshot a workout and its exercises as a template only this user sees
+		authAPI.POST("/workouts/:id/save-as-template", func(c *gin.Context) {
+			var input struct {
+				Name        string `json:"name"`
+				Description string `json:"description"`
+			}
+			if err := c.ShouldBindJSON(&input); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			input.Name = strings.TrimSpace(input.Name)
+			if len(input.Name) > 255 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "name must be at most 255 characters"})
+				return
+			}
+			template, err := workoutRepo.SaveWorkoutAsTemplate(c.Request.Context(), userID(c), c.Param("id"), input.Name, strings.TrimSpace(input.Description))
+			if err != nil {
+				if errors.Is(err, repository.ErrWorkoutNotFound) {
+					c.JSON(http.StatusNotFound, gin.H{"error": "Workout not found"})
+					return
+				}
+				log.Printf("Error saving workout as template: %v", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save template"})
+				return
+			}
+			c.JSON(http.StatusCreated, template)
+		})
+
+		authAPI.GET("/workouts/:id/qr", shareHandler.QR)
+		authAPI.GET("/workouts/:id/printable", printableHandler.Printable)
+		authAPI.POST("/workouts/import", shareHandler.Import)
+		authAPI.POST("/workout-templates/:id/share", shareHandler.ShareTemplate)
+		authAPI.DELETE("/workout-templates/:id/share", shareHandler.UnshareTemplate)
+		api.GET("/shared/templates/:token", shareHandler.SharedTemplate)
+		authAPI.POST("/shared/templates/:token/clone", shareHandler.CloneSharedTemplate)
+
+		authAPI.PUT("/workouts/:id/target-duration", func(c *gin.Context) {
+			var input struct {
+				Minutes *int `json:"minutes"`
+			}
+			if err := c.ShouldBindJSON(&input); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if input.Minutes != nil && (*input.Minutes < 1 || *input.Minutes > 600) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "minutes must be between 1 and 600"})
+				return
+			}
+			err := workoutRepo.SetWorkoutTargetDuration(c.Request.Context(), userID(c), c.Param("id"), input.Minutes)
+			if err != nil {
+				if errors.Is(err, repository.ErrWorkoutNotFound) {
+					c.JSON(http.StatusNotFound, gin.H{"error": "Workout not found"})
+					return
+				}
+				log.Printf("Error setting workout target duration: %v", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update workout"})
+				return
+			}
+			workout, err := workoutRepo.GetWorkout(c.Request.Context(), userID(c), c.Param("id"))
+			if err != nil {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Workout not found"})
+				return
+			}
+			c.JSON(http.StatusOK, workout)
+		})
+
+		// Archiving hides a workout from the list without touching its session history
+		setArchived := func(archived bool) gin.HandlerFunc {
+			return func(c *gin.Context) {
+				err := workoutRepo.SetWorkoutArchived(c.Request.Context(), userID(c), c.Param("id"), archived)
+				if err != nil {
+					if errors.Is(err, repository.ErrWorkoutNotFound) {
+						c.JSON(http.StatusNotFound, gin.H{"error": "Workout not found"})
+						return
+					}
+					log.Printf("Error archiving workout: %v", err)
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update workout"})
+					return
+				}
+				workout, err := workoutRepo.GetWorkout(c.Request.Context(), userID(c), c.Param("id"))
+				if err != nil {
+					c.JSON(http.StatusNotFound, gin.H{"error": "Workout not found"})
+					return
+				}
+				c.JSON(http.StatusOK, workout)
+			}
+		}
+		authAPI.PUT("/workouts/:id/archive", setArchived(true))
+		authAPI.PUT("/workouts/:id/unarchive", setArchived(false))
+
+		// Tags label workouts ("legs", "deload") for filtering with GET /workouts?tag=
+		setTag := func(add bool) gin.HandlerFunc {
+			return func(c *gin.Context) {
+				tag := c.Param("tag")
+				if add {
+					var input struct {
+						Tag string `json:"tag" binding:"required"`
+					}
+					if err := c.ShouldBindJSON(&input); err != nil {
+						c.JSON(http.StatusBadRequest, gin.H{"error": "tag is required"})
+						return
+					}
+					tag = input.Tag
+				}
+				tag, err := models.NormalizeTag(tag)
+				if err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+					return
+				}
+				if add {
+					err = workoutRepo.AddWorkoutTag(c.Request.Context(), userID(c), c.Param("id"), tag)
+				} else {
+					err = workoutRepo.RemoveWorkoutTag(c.Request.Context(), userID(c), c.Param("id"), tag)
+				}
+				switch {
+				case errors.Is(err, repository.ErrWorkoutNotFound):
+					c.JSON(http.StatusNotFound, gin.H{"error": "Workout not found"})
+					return
+				case errors.Is(err, repository.ErrTooManyTags):
+					c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+					return
+				case err != nil:
+					log.Printf("Error updating workout tags: %v", err)
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update workout"})
+					return
+				}
+				workout, err := workoutRepo.GetWorkout(c.Request.Context(), userID(c), c.Param("id"))
+				if err != nil {
+					c.JSON(http.StatusNotFound, gin.H{"error": "Workout not found"})
+					return
+				}
+				c.JSON(http.StatusOK, workout)
+			}
+		}
+		authAPI.POST("/workouts/:id/tags", setTag(true))
+		authAPI.DELETE("/workouts/:id/tags/:tag", setTag(false))
+
+		// Deleting archives the workout so its history survives; ?permanent=true removes it for good
 		authAPI.DELETE("/workouts/:id", func(c *gin.Context) {
+			if c.Query("permanent") != "true" {
+				err := workoutRepo.SetWorkoutArchived(c.Request.Context(), userID(c), c.Param("id"), true)
+				if errors.Is(err, repository.ErrWorkoutNotFound) {
+					c.JSON(http.StatusNotFound, gin.H{"error": "Workout not found"})
+					return
+				}
+				if err != nil {
+					log.Printf("Error archiving workout: %v", err)
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete workout"})
+					return
+				}
+				c.JSON(http.StatusOK, gin.H{"message": "Workout archived"})
+				return
+			}
 			err := workoutRepo.DeleteWorkout(c.Request.Context(), userID(c), c.Param("id"))
 			if err != nil {
 				log.Printf("Error deleting workout: %v", err)
@@ -222,7 +807,7 @@ func main() {
 				Name string `json:"name"`
 			}
 			_ = c.ShouldBindJSON(&input)
-			routine, err := routineRepo.CreateFromTemplate(c.Request.Context(), userID(c), c.Param("templateId"), input.Name)
+			routine, err := routineRepo.CreateFromTemplate(c.Request.Context(), userID(c), c.Param("templateId"), input.Name, templateScaler(c))
 			if err != nil {
 				log.Printf("Error creating from template: %v", err)
 				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -232,8 +817,9 @@ func main() {
 		})
 
 		// Workout template routes
-		api.GET("/workout-templates", func(c *gin.Context) {
-			templates, err := workoutRepo.GetWorkoutTemplates(c.Request.Context())
+		// Signed-in callers also get the templates they saved, so responses are cached per user
+		api.GET("/workout-templates", auth.OptionalAuthMiddleware(), unitsMiddleware, respCache.Private("workout-templates"), func(c *gin.Context) {
+			templates, err := workoutRepo.GetWorkoutTemplates(c.Request.Context(), auth.GetUserID(c))
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 				return
@@ -241,16 +827,193 @@ func main() {
 			c.JSON(http.StatusOK, templates)
 		})
 
-		api.GET("/exercise-templates", func(c *gin.Context) {
-			templates, err := workoutRepo.GetExerciseTemplates(c.Request.Context())
+		// Signed-in callers also get their own templates, so responses are cached per user.
+		// ?muscle= keeps templates working it as a primary or secondary muscle and
+		// ?equipment= those requiring that equipment.
+		api.GET("/exercise-templates", auth.OptionalAuthMiddleware(), unitsMiddleware, respCache.Private("exercise-templates"), func(c *gin.Context) {
+			muscle := strings.ToLower(strings.TrimSpace(c.Query("muscle")))
+			if muscle != "" && !models.ValidMuscle(muscle) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "muscle must be one of " + strings.Join(models.Muscles, ", ")})
+				return
+			}
+			equipment := strings.ToLower(strings.TrimSpace(c.Query("equipment")))
+			if equipment != "" && !models.ValidEquipment(equipment) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "equipment must be one of " + strings.Join(models.Equipment, ", ")})
+				return
+			}
+			tax, err := taxonomyRepo.Load(c.Request.Context())
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 				return
 			}
-			c.JSON(http.StatusOK, templates)
+			templates, err := workoutRepo.GetExerciseTemplates(c.Request.Context(), auth.GetUserID(c), tax)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			matching := []*models.ExerciseTemplate{}
+			for _, t := range templates {
+				if (muscle == "" || t.Works(muscle)) && (equipment == "" || t.Equipment.Contains(equipment)) {
+					matching = append(matching, t)
+				}
+			}
+			c.JSON(http.StatusOK, matching)
+		})
+
+		// User-defined exercise templates, listed with the built-in ones above
+		exerciseTemplateError := func(c *gin.Context, err error, action string) {
+			switch {
+			case errors.Is(err, repository.ErrExerciseTemplateNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": "Exercise template not found"})
+			case errors.Is(err, repository.ErrExerciseTemplateExists):
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			default:
+				log.Printf("Error %s exercise template: %v", action, err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to " + action + " exercise template"})
+			}
+		}
+
+		authAPI.POST("/exercise-templates", func(c *gin.Context) {
+			var template models.ExerciseTemplate
+			if err := c.ShouldBindJSON(&template); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			template = models.ExerciseTemplate{
+				Name:                   template.Name,
+				DefaultSets:            template.DefaultSets,
+				DefaultReps:            template.DefaultReps,
+				DefaultRepsMin:         template.DefaultRepsMin,
+				DefaultRepsMax:         template.DefaultRepsMax,
+				DefaultWeight:          template.DefaultWeight,
+				Mode:                   template.Mode,
+				DefaultDurationSeconds: template.DefaultDurationSeconds,
+				DefaultDistanceMeters:  template.DefaultDistanceMeters,
+				DefaultRestSeconds:     template.DefaultRestSeconds,
+				VideoURL:               template.VideoURL,
+				ImageURL:               template.ImageURL,
+				ExerciseMetadata:       template.ExerciseMetadata,
+			}
+			if err := normalizeMediaURLs(&template.VideoURL, &template.ImageURL); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if err := template.Validate(); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if err := workoutRepo.CreateExerciseTemplate(c.Request.Context(), userID(c), &template); err != nil {
+				exerciseTemplateError(c, err, "create")
+				return
+			}
+			c.JSON(http.StatusCreated, template)
+		})
+
+		authAPI.PUT("/exercise-templates/:id", func(c *gin.Context) {
+			var input struct {
+				Name                   *string   `json:"name"`
+				DefaultSets            *int      `json:"default_sets"`
+				DefaultReps            *int      `json:"default_reps"`
+				DefaultRepsMin         *int      `json:"default_reps_min"`
+				DefaultRepsMax         *int      `json:"default_reps_max"`
+				DefaultWeight          *float64  `json:"default_weight"`
+				Mode                   *string   `json:"mode"`
+				DefaultDurationSeconds *int      `json:"default_duration_seconds"`
+				DefaultDistanceMeters  *int      `json:"default_distance_meters"`
+				DefaultRestSeconds     *int      `json:"default_rest_seconds"`
+				PrimaryMuscles         *[]string `json:"primary_muscles"`
+				SecondaryMuscles       *[]string `json:"secondary_muscles"`
+				Equipment              *[]string `json:"equipment"`
+				Instructions           *string   `json:"instructions"`
+				VideoURL               *string   `json:"video_url"`
+				ImageURL               *string   `json:"image_url"`
+			}
+			if err := c.ShouldBindJSON(&input); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			template, err := workoutRepo.GetExerciseTemplate(c.Request.Context(), userID(c), c.Param("id"))
+			if err != nil {
+				exerciseTemplateError(c, err, "update")
+				return
+			}
+			if input.Name != nil {
+				template.Name = *input.Name
+			}
+			if input.DefaultSets != nil {
+				template.DefaultSets = *input.DefaultSets
+			}
+			if input.DefaultReps != nil {
+				template.DefaultReps = *input.DefaultReps
+			}
+			if input.DefaultRepsMin != nil {
+				template.DefaultRepsMin = *input.DefaultRepsMin
+			}
+			if input.DefaultRepsMax != nil {
+				template.DefaultRepsMax = *input.DefaultRepsMax
+			}
+			if input.DefaultWeight != nil {
+				template.DefaultWeight = *input.DefaultWeight
+			}
+			if input.Mode != nil {
+				template.Mode = *input.Mode
+			}
+			if input.DefaultDurationSeconds != nil {
+				template.DefaultDurationSeconds = *input.DefaultDurationSeconds
+			}
+			if input.DefaultDistanceMeters != nil {
+				template.DefaultDistanceMeters = *input.DefaultDistanceMeters
+			}
+			if input.DefaultRestSeconds != nil {
+				template.DefaultRestSeconds = *input.DefaultRestSeconds
+			}
+			if input.PrimaryMuscles != nil {
+				template.PrimaryMuscles = *input.PrimaryMuscles
+			}
+			if input.SecondaryMuscles != nil {
+				template.SecondaryMuscles = *input.SecondaryMuscles
+			}
+			if input.Equipment != nil {
+				template.Equipment = *input.Equipment
+			}
+			if input.Instructions != nil {
+				template.Instructions = *input.Instructions
+			}
+			if input.VideoURL != nil {
+				template.VideoURL = *input.VideoURL
+			}
+			if input.ImageURL != nil {
+				template.ImageURL = *input.ImageURL
+			}
+			if err := normalizeMediaURLs(&template.VideoURL, &template.ImageURL); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if err := template.Validate(); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if err := workoutRepo.UpdateExerciseTemplate(c.Request.Context(), userID(c), template); err != nil {
+				exerciseTemplateError(c, err, "update")
+				return
+			}
+			c.JSON(http.StatusOK, template)
 		})
 
-		api.GET("/routine-templates", func(c *gin.Context) {
+		authAPI.DELETE("/exercise-templates/:id", func(c *gin.Context) {
+			if err := workoutRepo.DeleteExerciseTemplate(c.Request.Context(), userID(c), c.Param("id")); err != nil {
+				exerciseTemplateError(c, err, "delete")
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"message": "Exercise template deleted"})
+		})
+
+		api.GET("/exercise-categories", respCache.Public("exercise-categories", time.Hour), taxonomyHandler.GetTaxonomy)
+
+		// Uploaded media is public, like the shared templates that link to it
+		api.GET("/media/:name", mediaHandler.Serve)
+
+		api.GET("/routine-templates", respCache.Public("routine-templates", time.Hour), func(c *gin.Context) {
 			templates := routineRepo.GetRoutineTemplates()
 			list := make([]gin.H, len(templates))
 			for i, t := range templates {
@@ -267,7 +1030,11 @@ func main() {
 				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 				return
 			}
-			workout, err := workoutRepo.CreateWorkoutFromTemplate(c.Request.Context(), userID(c), c.Param("id"), req.Name)
+			workout, err := workoutRepo.CreateWorkoutFromTemplate(c.Request.Context(), userID(c), c.Param("id"), req.Name, templateScaler(c))
+			if errors.Is(err, repository.ErrWorkoutTemplateNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 				return
@@ -278,26 +1045,20 @@ func main() {
 		// Exercise routes
 		authAPI.POST("/exercises", func(c *gin.Context) {
 			var input struct {
-				Name      string  `json:"name" binding:"required"`
-				Sets      int     `json:"sets" binding:"required"`
-				Reps      int     `json:"reps" binding:"required"`
-				Weight    float64 `json:"weight"`
-				WorkoutID string  `json:"workout_id" binding:"required"`
+				exerciseInput
+				WorkoutID string `json:"workout_id" binding:"required"`
 			}
 			if err := c.ShouldBindJSON(&input); err != nil {
 				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 				return
 			}
-
-			exercise := &models.Exercise{
-				Name:      input.Name,
-				Sets:      input.Sets,
-				Reps:      input.Reps,
-				Weight:    input.Weight,
-				WorkoutID: input.WorkoutID,
+			exercise, err := input.toExercise(input.WorkoutID)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
 			}
 
-			err := workoutRepo.CreateExercise(c.Request.Context(), userID(c), exercise)
+			err = workoutRepo.CreateExercise(c.Request.Context(), userID(c), exercise)
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 				return
@@ -305,6 +1066,154 @@ func main() {
 			c.JSON(http.StatusCreated, exercise)
 		})
 
+		// Builds a workout in one request: all exercises are created, in order, or none are
+		authAPI.POST("/workouts/:id/exercises/bulk", func(c *gin.Context) {
+			var input struct {
+				Exercises []exerciseInput `json:"exercises" binding:"required,min=1,max=50,dive"`
+			}
+			if err := c.ShouldBindJSON(&input); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "exercises must list 1 to 50 exercises, each with a name and sets"})
+				return
+			}
+			exercises := make([]*models.Exercise, 0, len(input.Exercises))
+			for i, in := range input.Exercises {
+				exercise, err := in.toExercise(c.Param("id"))
+				if err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("exercises[%d]: %v", i, err)})
+					return
+				}
+				exercises = append(exercises, exercise)
+			}
+			err := workoutRepo.CreateExercises(c.Request.Context(), userID(c), c.Param("id"), exercises)
+			if errors.Is(err, repository.ErrWorkoutNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Workout not found"})
+				return
+			}
+			if err != nil {
+				log.Printf("Error creating exercises: %v", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create exercises"})
+				return
+			}
+			c.JSON(http.StatusCreated, exercises)
+		})
+
+		authAPI.GET("/exercises/:id/warmup", warmupHandler.Warmup)
+
+		// Progression rules: weight changes applied when a session with the exercise ends
+		authAPI.GET("/progression-rules", progressionHandler.GetRules)
+		authAPI.GET("/exercises/:id/progression", progressionHandler.GetRule)
+		authAPI.PUT("/exercises/:id/progression", progressionHandler.SetRule)
+		authAPI.DELETE("/exercises/:id/progression", progressionHandler.DeleteRule)
+
+		// Coaches upload form videos and images, then link them from exercises and templates
+		authAPI.POST("/media", auth.RequireRole(auth.RoleCoach), mediaHandler.Upload)
+
+		// Fields left out keep their current value; switching mode needs the matching reps, or
+		// duration_seconds and/or distance_meters
+		authAPI.PUT("/exercises/:id", func(c *gin.Context) {
+			// Another user's exercise is not found, before its edit is even looked at
+			exercise, err := workoutRepo.GetUserExercise(c.Request.Context(), userID(c), c.Param("id"))
+			if errors.Is(err, repository.ErrExerciseNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Exercise not found"})
+				return
+			}
+			if err != nil {
+				log.Printf("Error fetching exercise: %v", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update exercise"})
+				return
+			}
+			var input struct {
+				Name            *string  `json:"name"`
+				Sets            *int     `json:"sets"`
+				Reps            *int     `json:"reps"`
+				RepsMin         *int     `json:"reps_min"`
+				RepsMax         *int     `json:"reps_max"`
+				Weight          *float64 `json:"weight"`
+				Mode            *string  `json:"mode"`
+				DurationSeconds *int     `json:"duration_seconds"`
+				DistanceMeters  *int     `json:"distance_meters"`
+				RestSeconds     *int     `json:"rest_seconds"`
+				TargetRPE       *float64 `json:"target_rpe"`
+				Tempo           *string  `json:"tempo"`
+				VideoURL        *string  `json:"video_url"`
+				ImageURL        *string  `json:"image_url"`
+				Unilateral      *bool    `json:"unilateral"`
+			}
+			if err := c.ShouldBindJSON(&input); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if input.Name != nil {
+				exercise.Name = *input.Name
+			}
+			if input.Sets != nil {
+				exercise.Sets = *input.Sets
+			}
+			if input.Reps != nil {
+				exercise.Reps = *input.Reps
+			}
+			if input.RepsMin != nil {
+				exercise.RepsMin = *input.RepsMin
+			}
+			if input.RepsMax != nil {
+				exercise.RepsMax = *input.RepsMax
+			}
+			// A new range without reps moves reps to its bottom when they fall outside it
+			if input.Reps == nil && exercise.RepsMin > 0 && (exercise.Reps < exercise.RepsMin || exercise.Reps > exercise.RepsMax) {
+				exercise.Reps = 0
+			}
+			if input.Weight != nil {
+				exercise.Weight = *input.Weight
+			}
+			if input.Mode != nil {
+				exercise.Mode = *input.Mode
+			}
+			if input.DurationSeconds != nil {
+				exercise.DurationSeconds = *input.DurationSeconds
+			}
+			if input.DistanceMeters != nil {
+				exercise.DistanceMeters = *input.DistanceMeters
+			}
+			if input.RestSeconds != nil {
+				exercise.RestSeconds = *input.RestSeconds
+			}
+			if input.TargetRPE != nil {
+				exercise.TargetRPE = input.TargetRPE
+			}
+			if input.Tempo != nil {
+				exercise.Tempo = *input.Tempo
+			}
+			if input.VideoURL != nil {
+				exercise.VideoURL = *input.VideoURL
+			}
+			if input.ImageURL != nil {
+				exercise.ImageURL = *input.ImageURL
+			}
+			if input.Unilateral != nil {
+				exercise.Unilateral = *input.Unilateral
+			}
+			if err := normalizeMediaURLs(&exercise.VideoURL, &exercise.ImageURL); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if err := exercise.Validate(); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			err = workoutRepo.UpdateExercise(c.Request.Context(), userID(c), exercise)
+			if err != nil {
+				if errors.Is(err, repository.ErrExerciseNotFound) {
+					c.JSON(http.StatusNotFound, gin.H{"error": "Exercise not found"})
+					return
+				}
+				log.Printf("Error updating exercise: %v", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update exercise"})
+				return
+			}
+			c.JSON(http.StatusOK, exercise)
+		})
+
 		authAPI.DELETE("/exercises/:id", func(c *gin.Context) {
 			err := workoutRepo.DeleteExercise(c.Request.Context(), userID(c), c.Param("id"))
 			if err != nil {
@@ -328,21 +1237,173 @@ func main() {
 			c.JSON(http.StatusOK, exercises)
 		})
 
+		authAPI.PUT("/workouts/:id/exercises/reorder", func(c *gin.Context) {
+			var input struct {
+				ExerciseIDs []string `json:"exercise_ids" binding:"required"`
+			}
+			if err := c.ShouldBindJSON(&input); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "exercise_ids is required"})
+				return
+			}
+			err := workoutRepo.ReorderExercises(c.Request.Context(), userID(c), c.Param("id"), input.ExerciseIDs)
+			if err != nil {
+				switch {
+				case errors.Is(err, repository.ErrWorkoutNotFound):
+					c.JSON(http.StatusNotFound, gin.H{"error": "Workout not found"})
+				case errors.Is(err, repository.ErrInvalidExerciseOrder):
+					c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				default:
+					log.Printf("Error reordering exercises: %v", err)
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reorder exercises"})
+				}
+				return
+			}
+			exercises, err := workoutRepo.GetExercisesByWorkout(c.Request.Context(), c.Param("id"))
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusOK, exercises)
+		})
+
+		// Supersets and circuits: grouped exercises are kept next to each other
+		authAPI.POST("/workouts/:id/exercise-groups", func(c *gin.Context) {
+			var input struct {
+				Type        string   `json:"type" binding:"required"`
+				ExerciseIDs []string `json:"exercise_ids" binding:"required"`
+			}
+			if err := c.ShouldBindJSON(&input); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "type and exercise_ids are required"})
+				return
+			}
+			if !models.ValidExerciseGroupType(input.Type) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "type must be superset or circuit"})
+				return
+			}
+			groupID, err := workoutRepo.GroupExercises(c.Request.Context(), userID(c), c.Param("id"), input.Type, input.ExerciseIDs)
+			if err != nil {
+				switch {
+				case errors.Is(err, repository.ErrWorkoutNotFound):
+					c.JSON(http.StatusNotFound, gin.H{"error": "Workout not found"})
+				case errors.Is(err, repository.ErrInvalidExerciseGroup):
+					c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				default:
+					log.Printf("Error grouping exercises: %v", err)
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to group exercises"})
+				}
+				return
+			}
+			exercises, err := workoutRepo.GetExercisesByWorkout(c.Request.Context(), c.Param("id"))
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusCreated, gin.H{"group_id": groupID, "exercises": exercises})
+		})
+
+		authAPI.DELETE("/workouts/:id/exercise-groups/:groupId", func(c *gin.Context) {
+			err := workoutRepo.UngroupExercises(c.Request.Context(), userID(c), c.Param("id"), c.Param("groupId"))
+			if err != nil {
+				switch {
+				case errors.Is(err, repository.ErrWorkoutNotFound):
+					c.JSON(http.StatusNotFound, gin.H{"error": "Workout not found"})
+				case errors.Is(err, repository.ErrExerciseGroupNotFound):
+					c.JSON(http.StatusNotFound, gin.H{"error": "Exercise group not found"})
+				default:
+					log.Printf("Error ungrouping exercises: %v", err)
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to ungroup exercises"})
+				}
+				return
+			}
+			exercises, err := workoutRepo.GetExercisesByWorkout(c.Request.Context(), c.Param("id"))
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusOK, exercises)
+		})
+
 		// Session routes
 		authAPI.POST("/sessions", func(c *gin.Context) {
 			var input struct {
-				WorkoutID string `json:"workout_id" binding:"required"`
+				WorkoutID string `json:"workout_id"`
+				// Program starts today's workout of the user's training program, with
+				// the week's progression applied, in place of workout_id
+				Program bool `json:"program"`
+				// Warm-up sets are generated ahead of each weighted exercise when set,
+				// with an optional scheme and rounding as for GET /exercises/:id/warmup
+				Warmup        bool    `json:"warmup"`
+				WarmupScheme  string  `json:"warmup_scheme"`
+				WarmupRoundTo float64 `json:"warmup_round_to"`
 			}
 			if err := c.ShouldBindJSON(&input); err != nil {
 				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 				return
 			}
+			var warmup *models.WarmupScheme
+			if input.Warmup {
+				roundTo := ""
+				if input.WarmupRoundTo != 0 {
+					roundTo = strconv.FormatFloat(input.WarmupRoundTo, 'f', -1, 64)
+				}
+				scheme, err := models.ParseWarmupScheme(input.WarmupScheme, roundTo)
+				if err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+					return
+				}
+				warmup = &scheme
+			}
 
-			session, err := sessionRepo.CreateSessionWithExercises(c.Request.Context(), userID(c), input.WorkoutID)
+			var today *models.ProgramToday
+			var adjust func(models.Exercise) models.Exercise
+			if input.Program {
+				var err error
+				today, err = programRepo.GetToday(c.Request.Context(), userID(c))
+				if errors.Is(err, repository.ErrNotEnrolled) {
+					c.JSON(http.StatusNotFound, gin.H{"error": "Not enrolled in a program"})
+					return
+				}
+				if err != nil {
+					log.Printf("Error fetching today's program workout: %v", err)
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch today's workout"})
+					return
+				}
+				if today.WeekComplete {
+					c.JSON(http.StatusConflict, gin.H{"error": "This week's program workouts are done; advance to the next week"})
+					return
+				}
+				input.WorkoutID, adjust = today.Workout.ID, today.Week.Apply
+			} else if input.WorkoutID == "" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "workout_id is required unless program is set"})
+				return
+			}
+
+			// The program day is claimed before the session starts, so concurrent
+			// starts cannot both train it, and handed back if the session fails
+			if today != nil {
+				err := programRepo.CompleteDay(c.Request.Context(), userID(c), today.Enrollment)
+				if errors.Is(err, repository.ErrProgramDayTaken) {
+					c.JSON(http.StatusConflict, gin.H{"error": "Today's program workout was already started"})
+					return
+				}
+				if err != nil {
+					log.Printf("Error recording program workout: %v", err)
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start today's workout"})
+					return
+				}
+			}
+			session, err := sessionRepo.CreateSessionWithTargets(c.Request.Context(), userID(c), input.WorkoutID, warmup, adjust)
 			if err != nil {
+				if today != nil {
+					if err := programRepo.UndoDay(c.Request.Context(), userID(c), today.Enrollment); err != nil {
+						log.Printf("Error undoing program workout: %v", err)
+					}
+				}
 				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 				return
 			}
+			addInjuryWarnings(c, injuryRepo, session)
+			session.Pace = models.NewSessionPace(session, time.Now())
 			c.JSON(http.StatusCreated, session)
 		})
 
@@ -352,6 +1413,9 @@ func main() {
 				c.JSON(http.StatusNotFound, gin.H{"error": "No active session"})
 				return
 			}
+			addInjuryWarnings(c, injuryRepo, session)
+			addPartner(c, partnerRepo, session)
+			session.Pace = models.NewSessionPace(session, time.Now())
 			c.JSON(http.StatusOK, session)
 		})
 
@@ -361,6 +1425,49 @@ func main() {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 				return
 			}
+			if err := analyticsHandler.CheckLoadRamp(c.Request.Context(), userID(c)); err != nil {
+				log.Printf("Error checking training load: %v", err)
+			}
+			progressions, err := progressionRepo.ApplyProgression(c.Request.Context(), userID(c), session.ID)
+			if err != nil {
+				log.Printf("Error applying progression rules: %v", err)
+			}
+			session.Progressions = progressions
+			c.JSON(http.StatusOK, session)
+		})
+
+		authAPI.GET("/sessions/:id/export", exportHandler.SessionLog)
+
+		// Correct sessions logged in the wrong time zone; each correction is audited
+		authAPI.POST("/sessions/retime", correctionHandler.RetimeSessions)
+		authAPI.GET("/audit", correctionHandler.GetAuditLog)
+
+		// Training partner routes
+		authAPI.POST("/sessions/:id/partner", partnerHandler.Invite)
+		authAPI.GET("/sessions/:id/partner", partnerHandler.GetPartnerSession)
+		authAPI.GET("/partner-invites", partnerHandler.ListInvites)
+		authAPI.POST("/partner-invites/:id/accept", partnerHandler.Accept)
+		authAPI.DELETE("/partner-invites/:id", partnerHandler.DeleteInvite)
+
+		authAPI.PUT("/sessions/:id/metadata", func(c *gin.Context) {
+			var input models.SessionMetadata
+			if err := c.ShouldBindJSON(&input); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if err := validateSessionMetadata(&input); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			session, err := sessionRepo.UpdateSessionMetadata(c.Request.Context(), userID(c), c.Param("id"), input)
+			if err != nil {
+				if errors.Is(err, repository.ErrSessionNotFound) {
+					c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+					return
+				}
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
 			c.JSON(http.StatusOK, session)
 		})
 
@@ -384,9 +1491,16 @@ func main() {
 		// Exercise set routes
 		authAPI.POST("/exercise-sets", func(c *gin.Context) {
 			var input struct {
-				SessionExerciseID string  `json:"sessionExerciseId" binding:"required"`
-				Reps              int     `json:"reps"`
-				Weight            float64 `json:"weight"`
+				SessionExerciseID string             `json:"sessionExerciseId" binding:"required"`
+				Reps              int                `json:"reps"`
+				Weight            float64            `json:"weight"`
+				DurationSeconds   *int               `json:"duration_seconds" binding:"omitempty,min=1"`
+				DistanceMeters    *int               `json:"distance_meters" binding:"omitempty,min=1,max=1000000"`
+				Technique         string             `json:"technique"`
+				Segments          models.SetSegments `json:"segments"`
+				Sides             *models.SetSides   `json:"sides"`
+				ActualRPE         *float64           `json:"actual_rpe"`
+				SetType           string             `json:"set_type"`
 			}
 			if err := c.ShouldBindJSON(&input); err != nil {
 				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -397,6 +1511,27 @@ func main() {
 				SessionExerciseID: input.SessionExerciseID,
 				Reps:              input.Reps,
 				Weight:            input.Weight,
+				DurationSeconds:   input.DurationSeconds,
+				DistanceMeters:    input.DistanceMeters,
+				Technique:         input.Technique,
+				Segments:          input.Segments,
+				ActualRPE:         input.ActualRPE,
+				SetType:           input.SetType,
+			}
+			if err := set.ValidateSegments(); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if err := set.ValidateRPE(); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if err := set.ValidateSetType(); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if !applySides(c, set, input.Sides) {
+				return
 			}
 
 			err := sessionRepo.CreateExerciseSet(c.Request.Context(), userID(c), set)
@@ -425,20 +1560,54 @@ func main() {
 
 		authAPI.PUT("/exercise-sets/:id", func(c *gin.Context) {
 			var input struct {
-				Reps   int     `json:"reps" binding:"required,min=1"`
-				Weight float64 `json:"weight" binding:"required,min=0.01"`
-				Notes  *string `json:"notes"`
+				Reps            int                `json:"reps" binding:"min=0"`
+				Weight          float64            `json:"weight" binding:"min=0"`
+				DurationSeconds *int               `json:"duration_seconds" binding:"omitempty,min=1"`
+				DistanceMeters  *int               `json:"distance_meters" binding:"omitempty,min=1,max=1000000"`
+				Technique       string             `json:"technique"`
+				Segments        models.SetSegments `json:"segments"`
+				Sides           *models.SetSides   `json:"sides"`
+				ActualRPE       *float64           `json:"actual_rpe"`
+				SetType         string             `json:"set_type"` // unchanged when empty
+				Notes           *string            `json:"notes"`
 			}
 			if err := c.ShouldBindJSON(&input); err != nil {
 				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 				return
 			}
+			// Timed sets (planks, holds) log seconds held, cardio sets time and/or distance
+			// and unilateral sets each side; everything else needs reps and weight
+			if input.DurationSeconds == nil && input.DistanceMeters == nil && input.Sides == nil && (input.Reps < 1 || input.Weight <= 0) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "reps and weight are required unless duration_seconds or distance_meters is set"})
+				return
+			}
 			set := &models.ExerciseSet{
-				ID:        c.Param("id"),
-				Reps:      input.Reps,
-				Weight:    input.Weight,
-				Notes:     input.Notes,
-				Completed: true,
+				ID:              c.Param("id"),
+				Reps:            input.Reps,
+				Weight:          input.Weight,
+				DurationSeconds: input.DurationSeconds,
+				DistanceMeters:  input.DistanceMeters,
+				Technique:       input.Technique,
+				Segments:        input.Segments,
+				ActualRPE:       input.ActualRPE,
+				SetType:         input.SetType,
+				Notes:           input.Notes,
+				Completed:       true,
+			}
+			if err := set.ValidateSegments(); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if err := set.ValidateRPE(); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if err := set.ValidateSetType(); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if !applySides(c, set, input.Sides) {
+				return
 			}
 			err := sessionRepo.UpdateExerciseSet(c.Request.Context(), userID(c), set)
 			if err != nil {
@@ -450,16 +1619,24 @@ func main() {
 
 		// Workout history routes
 		authAPI.GET("/sessions/completed", func(c *gin.Context) {
-			sessions, err := sessionRepo.GetCompletedSessions(c.Request.Context(), userID(c))
+			sessions, err := sessionRepo.GetCompletedSessions(c.Request.Context(), userID(c), strings.TrimSpace(c.Query("q")))
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 				return
 			}
+			// Partner workouts are marked with who the user trained with
+			partners, err := partnerRepo.PartnersBySession(c.Request.Context(), userID(c))
+			if err != nil {
+				log.Printf("Error listing partner sessions: %v", err)
+			}
+			for _, session := range sessions {
+				session.Partner = partners[session.ID]
+			}
 			c.JSON(http.StatusOK, sessions)
 		})
 
 		// Progress routes
-		authAPI.GET("/progress", func(c *gin.Context) {
+		authAPI.GET("/progress", respCache.Private("progress"), func(c *gin.Context) {
 			progress, err := sessionRepo.GetProgressData(c.Request.Context(), userID(c))
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -468,6 +1645,48 @@ func main() {
 			c.JSON(http.StatusOK, progress)
 		})
 
+		// Analytics and alert routes
+		authAPI.GET("/analytics/load", respCache.Private("analytics-load"), analyticsHandler.GetLoad)
+		authAPI.GET("/analytics/imbalance", respCache.Private("analytics-imbalance"), analyticsHandler.GetImbalance)
+		authAPI.GET("/analytics/weekly", respCache.Private("analytics-weekly"), analyticsHandler.GetWeekly)
+		// :id is the exercise name; gin needs it named like the other /exercises/:id routes
+		authAPI.GET("/exercises/:id/one-rep-max", respCache.Private("one-rep-max"), analyticsHandler.GetOneRepMax)
+		authAPI.GET("/gyms/:id/best-times", analyticsHandler.GetGymBestTimes)
+		authAPI.GET("/alerts", analyticsHandler.GetAlerts)
+		authAPI.PUT("/alerts/:id/dismiss", analyticsHandler.DismissAlert)
+
+		// Recommendation routes
+		authAPI.GET("/recommendations/templates", recommendationHandler.GetTemplateRecommendations)
+
+		// Display preferences (units: imperial or metric)
+		authAPI.GET("/preferences", preferencesHandler.GetPreferences)
+		authAPI.PUT("/preferences", preferencesHandler.UpdatePreferences)
+
+		// Schedule routes (training calendar; dates are YYYY-MM-DD in the user's local time)
+		authAPI.GET("/schedule", scheduleHandler.GetSchedule)
+		authAPI.POST("/schedule", scheduleHandler.CreateScheduledWorkout)
+		authAPI.GET("/schedule/:id", scheduleHandler.GetScheduledWorkout)
+		authAPI.PUT("/schedule/:id", scheduleHandler.UpdateScheduledWorkout)
+		authAPI.DELETE("/schedule/:id", scheduleHandler.DeleteScheduledWorkout)
+
+		// Training program routes; enrollment tracks the user's week in one program
+		authAPI.GET("/programs", programHandler.GetPrograms)
+		authAPI.POST("/programs", programHandler.CreateProgram)
+		authAPI.GET("/programs/today", programHandler.Today)
+		authAPI.GET("/programs/enrollment", programHandler.GetEnrollment)
+		authAPI.POST("/programs/enrollment/advance", programHandler.AdvanceWeek)
+		authAPI.DELETE("/programs/enrollment", programHandler.LeaveProgram)
+		authAPI.GET("/programs/:id", programHandler.GetProgram)
+		authAPI.PUT("/programs/:id", programHandler.UpdateProgram)
+		authAPI.DELETE("/programs/:id", programHandler.DeleteProgram)
+		authAPI.POST("/programs/:id/enroll", programHandler.Enroll)
+
+		// Injury routes
+		authAPI.GET("/injuries", injuryHandler.GetInjuries)
+		authAPI.POST("/injuries", injuryHandler.CreateInjury)
+		authAPI.PUT("/injuries/:id/resolve", injuryHandler.ResolveInjury)
+		authAPI.DELETE("/injuries/:id", injuryHandler.DeleteInjury)
+
 		// Dino game routes
 		authAPI.POST("/dino-game/score", func(c *gin.Context) {
 			var input struct {
@@ -497,8 +1716,22 @@ func main() {
 	}
 
 	// Health check
+	// Health check; 503 while the database is unreachable, so use it as a readiness probe
 	r.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Second)
+		defer cancel()
+		var err error
+		if db.IsSQLite() {
+			err = db.GetSQLite().PingContext(ctx)
+		} else {
+			err = pgPool.Ping(ctx)
+		}
+		if err != nil {
+			log.Printf("Health check: database unavailable: %v", err)
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "degraded", "database": "unavailable", "version": changelog.Version()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "ok", "database": "ok", "version": changelog.Version()})
 	})
 
 	// Get port from environment or use default
@@ -514,3 +1747,190 @@ func main() {
 		log.Fatal("Failed to start server:", err)
 	}
 }
+
+// durationFromEnv parses a Go duration (e.g. "24h") from env, falling back to def.
+// A value of "0" disables whatever the duration controls.
+func durationFromEnv(key string, def time.Duration) time.Duration {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil {
+		log.Printf("Invalid %s=%q, using %v", key, raw, def)
+		return def
+	}
+	return d
+}
+
+// applySides records left/right results on a unilateral set, mirroring the left
+// side into Reps and Weight so side-unaware clients and queries still see the set.
+// It responds 400 and returns false if the sides are invalid.
+func applySides(c *gin.Context, set *models.ExerciseSet, sides *models.SetSides) bool {
+	if sides == nil {
+		return true
+	}
+	if err := sides.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	set.Sides = *sides
+	set.Reps, set.Weight = sides.Left.Reps, sides.Left.Weight
+	return true
+}
+
+// addInjuryWarnings attaches contraindication warnings to a session payload.
+// Failures are logged rather than failing the request.
+func addInjuryWarnings(c *gin.Context, injuryRepo *repository.InjuryRepository, session *models.WorkoutSession) {
+	if session == nil {
+		return
+	}
+	warnings, err := injuryRepo.SessionWarnings(c.Request.Context(), auth.GetUserID(c), session)
+	if err != nil {
+		log.Printf("Error computing injury warnings: %v", err)
+		return
+	}
+	session.Warnings = warnings
+}
+
+// addPartner marks a partner workout with the other participant
+func addPartner(c *gin.Context, partnerRepo *repository.PartnerRepository, session *models.WorkoutSession) {
+	if session == nil {
+		return
+	}
+	partner, err := partnerRepo.GetPartner(c.Request.Context(), auth.GetUserID(c), session.ID)
+	if err != nil {
+		log.Printf("Error getting session partner: %v", err)
+		return
+	}
+	session.Partner = partner
+}
+
+// exerciseInput is the body of POST /api/exercises, less workout_id, and each entry of a bulk create
+type exerciseInput struct {
+	Name            string   `json:"name" binding:"required"`
+	Sets            int      `json:"sets" binding:"required"`
+	Reps            int      `json:"reps"`
+	RepsMin         int      `json:"reps_min"`
+	RepsMax         int      `json:"reps_max"`
+	Weight          float64  `json:"weight"`
+	Mode            string   `json:"mode"`
+	DurationSeconds int      `json:"duration_seconds"`
+	DistanceMeters  int      `json:"distance_meters"`
+	RestSeconds     int      `json:"rest_seconds"`
+	TargetRPE       *float64 `json:"target_rpe"`
+	Tempo           string   `json:"tempo"`
+	VideoURL        string   `json:"video_url"`
+	ImageURL        string   `json:"image_url"`
+	Unilateral      bool     `json:"unilateral"`
+}
+
+// toExercise builds the exercise for a workout, checking its media links and prescription
+func (in exerciseInput) toExercise(workoutID string) (*models.Exercise, error) {
+	exercise := &models.Exercise{
+		Name:            in.Name,
+		Sets:            in.Sets,
+		Reps:            in.Reps,
+		RepsMin:         in.RepsMin,
+		RepsMax:         in.RepsMax,
+		Weight:          in.Weight,
+		Mode:            in.Mode,
+		DurationSeconds: in.DurationSeconds,
+		DistanceMeters:  in.DistanceMeters,
+		RestSeconds:     in.RestSeconds,
+		TargetRPE:       in.TargetRPE,
+		Tempo:           in.Tempo,
+		VideoURL:        in.VideoURL,
+		ImageURL:        in.ImageURL,
+		Unilateral:      in.Unilateral,
+		WorkoutID:       workoutID,
+	}
+	if err := normalizeMediaURLs(&exercise.VideoURL, &exercise.ImageURL); err != nil {
+		return nil, err
+	}
+	if err := exercise.Validate(); err != nil {
+		return nil, err
+	}
+	return exercise, nil
+}
+
+// normalizeMediaURLs checks an exercise's or template's linked video and image
+func normalizeMediaURLs(videoURL, imageURL *string) error {
+	var err error
+	if *videoURL, err = media.NormalizeURL(*videoURL, "video_url"); err != nil {
+		return err
+	}
+	*imageURL, err = media.NormalizeURL(*imageURL, "image_url")
+	return err
+}
+
+// validateSessionMetadata trims metadata fields and enforces size limits
+func validateSessionMetadata(m *models.SessionMetadata) error {
+	const maxField = 200
+	const maxPartners = 10
+	const maxExtra = 20
+
+	m.Gym = strings.TrimSpace(m.Gym)
+	m.Mood = strings.TrimSpace(m.Mood)
+	m.PlaylistURL = strings.TrimSpace(m.PlaylistURL)
+	if len(m.Gym) > maxField || len(m.Mood) > maxField {
+		return fmt.Errorf("gym and mood must be at most %d characters", maxField)
+	}
+	if m.Crowd != 0 && (m.Crowd < models.MinCrowdRating || m.Crowd > models.MaxCrowdRating) {
+		return fmt.Errorf("crowd must be between %d and %d", models.MinCrowdRating, models.MaxCrowdRating)
+	}
+
+	if m.PlaylistURL != "" {
+		u, err := url.Parse(m.PlaylistURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(m.PlaylistURL) > 2048 {
+			return errors.New("playlist_url must be an http(s) URL")
+		}
+	}
+
+	partners := make([]string, 0, len(m.Partners))
+	for _, p := range m.Partners {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if len(p) > maxField {
+			return fmt.Errorf("partner names must be at most %d characters", maxField)
+		}
+		partners = append(partners, p)
+	}
+	if len(partners) > maxPartners {
+		return fmt.Errorf("at most %d partners are allowed", maxPartners)
+	}
+	m.Partners = partners
+
+	if len(m.Extra) > maxExtra {
+		return fmt.Errorf("at most %d extra fields are allowed", maxExtra)
+	}
+	for k, v := range m.Extra {
+		if strings.TrimSpace(k) == "" || len(k) > 50 || len(v) > maxField {
+			return fmt.Errorf("extra keys must be 1-50 characters and values at most %d characters", maxField)
+		}
+	}
+	return nil
+}
+
+// failFastWhileDatabaseDown answers 503 with Retry-After while the database circuit
+// breaker is open, instead of letting requests fail one by one. Paths under the
+// given prefixes do not need the database and are served as usual.
+func failFastWhileDatabaseDown(pool *repository.ResilientPool, exempt ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		wait := pool.OpenFor()
+		if wait <= 0 {
+			c.Next()
+			return
+		}
+		for _, prefix := range exempt {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				c.Next()
+				return
+			}
+		}
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "Service temporarily unavailable, try again shortly"})
+	}
+}
@@ -0,0 +1,143 @@
+package maintenance
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+/**
+ * Maintenance Package
+ *
+ * Runtime switches for operating the service: maintenance mode, which
+ * answers writes with 503 while reads keep working (e.g. during a
+ * migration or restore), and a registration kill-switch that stops new
+ * accounts being created (e.g. during a spam wave). Both start from
+ * MAINTENANCE_MODE / REGISTRATION_DISABLED and can be flipped by admins
+ * at runtime.
+ *
+ * The switches live in process memory, so admin changes apply per server
+ * instance and last until restart; set the env vars for a lasting change.
+ */
+
+// DefaultMessage is shown to clients when no maintenance message is set
+const DefaultMessage = "Liftoff is down for maintenance. Your data is safe; please try again shortly."
+
+// DefaultRetryAfter is the Retry-After sent with maintenance responses
+const DefaultRetryAfter = 5 * time.Minute
+
+// State is the current setting of both switches
+type State struct {
+	Maintenance          bool      `json:"maintenance"`
+	Message              string    `json:"message,omitempty"` // shown to clients during maintenance
+	RegistrationDisabled bool      `json:"registration_disabled"`
+	UpdatedAt            time.Time `json:"updated_at"`
+	UpdatedBy            string    `json:"updated_by,omitempty"` // the admin who last changed it; empty when from env
+}
+
+// Switch holds the state and enforces it through its middleware
+type Switch struct {
+	mu    sync.RWMutex
+	state State
+	// exempt path prefixes still accept writes during maintenance
+	exempt []string
+}
+
+// New creates a switch in the given state. Writes to paths under the exempt
+// prefixes, such as sign-in and the admin routes that turn maintenance off,
+// are still allowed during maintenance.
+func New(state State, exempt ...string) *Switch {
+	if state.UpdatedAt.IsZero() {
+		state.UpdatedAt = time.Now()
+	}
+	return &Switch{state: state, exempt: exempt}
+}
+
+// FromEnv reads MAINTENANCE_MODE, MAINTENANCE_MESSAGE and REGISTRATION_DISABLED
+func FromEnv(exempt ...string) *Switch {
+	return New(State{
+		Maintenance:          boolFromEnv("MAINTENANCE_MODE"),
+		Message:              strings.TrimSpace(os.Getenv("MAINTENANCE_MESSAGE")),
+		RegistrationDisabled: boolFromEnv("REGISTRATION_DISABLED"),
+	}, exempt...)
+}
+
+func boolFromEnv(key string) bool {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return false
+	}
+	on, err := strconv.ParseBool(raw)
+	if err != nil {
+		log.Printf("Invalid %s=%q, using false", key, raw)
+	}
+	return on
+}
+
+// State returns the current state
+func (s *Switch) State() State {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.state
+}
+
+// Set replaces the state, recording who changed it
+func (s *Switch) Set(state State, by string) State {
+	state.UpdatedAt = time.Now()
+	state.UpdatedBy = by
+	s.mu.Lock()
+	s.state = state
+	s.mu.Unlock()
+	return state
+}
+
+// RegistrationOpen reports whether new accounts may be created
+func (s *Switch) RegistrationOpen() bool {
+	return !s.State().RegistrationDisabled
+}
+
+// Middleware answers writes with 503 and the maintenance message while
+// maintenance mode is on. Reads and exempt paths pass through.
+func (s *Switch) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		state := s.State()
+		if !state.Maintenance {
+			c.Next()
+			return
+		}
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+		for _, prefix := range s.exempt {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				c.Next()
+				return
+			}
+		}
+		message := state.Message
+		if message == "" {
+			message = DefaultMessage
+		}
+		c.Header("Retry-After", strconv.Itoa(int(DefaultRetryAfter.Seconds())))
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": message, "maintenance": true})
+	}
+}
+
+// RegistrationMiddleware rejects sign-up routes with 403 while registration is disabled
+func (s *Switch) RegistrationMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !s.RegistrationOpen() {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "New registrations are closed right now", "registration_disabled": true})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,64 @@
+package maintenance
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("MAINTENANCE_MODE", "true")
+	t.Setenv("MAINTENANCE_MESSAGE", " Back at 3pm ")
+	t.Setenv("REGISTRATION_DISABLED", "")
+	state := FromEnv().State()
+	if !state.Maintenance || state.Message != "Back at 3pm" || state.RegistrationDisabled || state.UpdatedBy != "" {
+		t.Errorf("state = %+v", state)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	sw := New(State{}, "/api/admin")
+	r := gin.New()
+	r.Use(sw.Middleware())
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	r.GET("/api/workouts", ok)
+	r.POST("/api/workouts", ok)
+	r.PUT("/api/admin/maintenance", ok)
+	r.POST("/api/auth/register", sw.RegistrationMiddleware(), ok)
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w
+	}
+
+	if w := serve(http.MethodPost, "/api/workouts"); w.Code != http.StatusOK {
+		t.Errorf("write while open: got %d", w.Code)
+	}
+
+	sw.Set(State{Maintenance: true}, "admin-1")
+	w := serve(http.MethodPost, "/api/workouts")
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") == "" || !strings.Contains(w.Body.String(), "maintenance") {
+		t.Errorf("write during maintenance: got %d %s", w.Code, w.Body)
+	}
+	if w := serve(http.MethodGet, "/api/workouts"); w.Code != http.StatusOK {
+		t.Errorf("read during maintenance: got %d", w.Code)
+	}
+	if w := serve(http.MethodPut, "/api/admin/maintenance"); w.Code != http.StatusOK {
+		t.Errorf("exempt write during maintenance: got %d", w.Code)
+	}
+	if state := sw.State(); state.UpdatedBy != "admin-1" || state.UpdatedAt.IsZero() {
+		t.Errorf("state = %+v", state)
+	}
+
+	sw.Set(State{RegistrationDisabled: true}, "admin-1")
+	if w := serve(http.MethodPost, "/api/auth/register"); w.Code != http.StatusForbidden {
+		t.Errorf("register while closed: got %d", w.Code)
+	}
+	if w := serve(http.MethodPost, "/api/workouts"); w.Code != http.StatusOK {
+		t.Errorf("write with registration closed: got %d", w.Code)
+	}
+}
@@ -0,0 +1,173 @@
+package media
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"liftoff/backend/ids"
+)
+
+/**
+ * Media Package
+ *
+ * Stores uploaded exercise demonstration media (form videos and images) on
+ * local disk and serves it back under /api/media/. Only image and video
+ * types recognised by content sniffing are accepted, whatever the client
+ * claims, so an upload can never be served back as HTML or script. Files
+ * are named by a fresh ID and never change, so they can be cached forever.
+ */
+
+// Config says where uploads are kept and how large they may be
+type Config struct {
+	Dir      string
+	MaxBytes int64
+}
+
+// Defaults used when the corresponding MEDIA_* variable is unset
+const (
+	DefaultDir      = "uploads"
+	DefaultMaxBytes = 50 << 20
+)
+
+// URLPrefix is the path uploaded files are served under
+const URLPrefix = "/api/media/"
+
+// Kinds of media
+const (
+	KindImage = "image"
+	KindVideo = "video"
+)
+
+// types maps each accepted sniffed content type to its kind and file extension
+var types = map[string]struct{ kind, ext string }{
+	"image/jpeg": {KindImage, ".jpg"},
+	"image/png":  {KindImage, ".png"},
+	"image/gif":  {KindImage, ".gif"},
+	"image/webp": {KindImage, ".webp"},
+	"video/mp4":  {KindVideo, ".mp4"},
+	"video/webm": {KindVideo, ".webm"},
+}
+
+var (
+	// ErrUnsupportedType is returned for uploads that are not an accepted image or video
+	ErrUnsupportedType = errors.New("only JPEG, PNG, GIF and WebP images and MP4 and WebM videos can be uploaded")
+	// ErrTooLarge is returned for uploads over Config.MaxBytes
+	ErrTooLarge = errors.New("upload is too large")
+)
+
+var namePattern = regexp.MustCompile(`^[0-9a-f-]{36}\.[a-z0-9]{2,4}$`)
+
+// MaxURLLength caps linked media URLs
+const MaxURLLength = 2048
+
+// NormalizeURL trims a linked media URL and checks that it is an http(s) URL
+// or the path of an upload. An empty URL means no media.
+func NormalizeURL(raw, field string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", nil
+	}
+	if len(raw) > MaxURLLength {
+		return "", fmt.Errorf("%s must be at most %d characters", field, MaxURLLength)
+	}
+	if strings.HasPrefix(raw, URLPrefix) && namePattern.MatchString(strings.TrimPrefix(raw, URLPrefix)) {
+		return raw, nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("%s must be an http(s) URL or an uploaded file", field)
+	}
+	return raw, nil
+}
+
+// ConfigFromEnv reads MEDIA_DIR and MEDIA_MAX_BYTES
+func ConfigFromEnv() Config {
+	cfg := Config{Dir: DefaultDir, MaxBytes: DefaultMaxBytes}
+	if dir := os.Getenv("MEDIA_DIR"); dir != "" {
+		cfg.Dir = dir
+	}
+	if raw := os.Getenv("MEDIA_MAX_BYTES"); raw != "" {
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || n <= 0 {
+			log.Printf("Invalid MEDIA_MAX_BYTES=%q, ignoring", raw)
+		} else {
+			cfg.MaxBytes = n
+		}
+	}
+	return cfg
+}
+
+// Store keeps uploaded media in a directory
+type Store struct {
+	cfg Config
+}
+
+// NewStore creates the upload directory if needed
+func NewStore(cfg Config) (*Store, error) {
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create media directory: %w", err)
+	}
+	return &Store{cfg: cfg}, nil
+}
+
+// MaxBytes is the largest upload accepted
+func (s *Store) MaxBytes() int64 {
+	return s.cfg.MaxBytes
+}
+
+// Save stores an upload and returns the name it is served under and its kind
+func (s *Store) Save(r io.Reader) (name, kind string, err error) {
+	br := bufio.NewReaderSize(r, 512)
+	head, err := br.Peek(512)
+	if err != nil && err != io.EOF {
+		return "", "", fmt.Errorf("read upload: %w", err)
+	}
+	t, ok := types[http.DetectContentType(head)]
+	if !ok {
+		return "", "", ErrUnsupportedType
+	}
+
+	tmp, err := os.CreateTemp(s.cfg.Dir, ".upload-*")
+	if err != nil {
+		return "", "", fmt.Errorf("create upload: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	n, err := io.Copy(tmp, io.LimitReader(br, s.cfg.MaxBytes+1))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("write upload: %w", err)
+	}
+	if n > s.cfg.MaxBytes {
+		return "", "", ErrTooLarge
+	}
+
+	name = ids.New() + t.ext
+	if err := os.Rename(tmp.Name(), filepath.Join(s.cfg.Dir, name)); err != nil {
+		return "", "", fmt.Errorf("store upload: %w", err)
+	}
+	return name, t.kind, nil
+}
+
+// Path returns the file behind a served name, or false if there is no such upload
+func (s *Store) Path(name string) (string, bool) {
+	if !namePattern.MatchString(name) {
+		return "", false
+	}
+	path := filepath.Join(s.cfg.Dir, name)
+	if info, err := os.Stat(path); err != nil || !info.Mode().IsRegular() {
+		return "", false
+	}
+	return path, true
+}
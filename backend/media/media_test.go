@@ -0,0 +1,59 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+var png = append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 64)...)
+
+func TestStoreSave(t *testing.T) {
+	store, err := NewStore(Config{Dir: t.TempDir(), MaxBytes: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	name, kind, err := store.Save(bytes.NewReader(png))
+	if err != nil || kind != KindImage || !strings.HasSuffix(name, ".png") {
+		t.Fatalf("Save = %q, %q, %v", name, kind, err)
+	}
+	path, ok := store.Path(name)
+	if !ok {
+		t.Fatal("saved file not found")
+	}
+	if b, _ := os.ReadFile(path); !bytes.Equal(b, png) {
+		t.Error("stored file differs from upload")
+	}
+
+	if _, _, err := store.Save(strings.NewReader("<html><script>alert(1)</script>")); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("html upload: err = %v", err)
+	}
+	if _, _, err := store.Save(bytes.NewReader(append(png, make([]byte, 100)...))); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("large upload: err = %v", err)
+	}
+	for _, name := range []string{"../media.go", "missing.png", "0190a5b2-7c1e-7d44-9a3e-1f2b3c4d5e6f.png"} {
+		if _, ok := store.Path(name); ok {
+			t.Errorf("Path(%q) should not resolve", name)
+		}
+	}
+}
+
+func TestNormalizeURL(t *testing.T) {
+	for raw, want := range map[string]string{
+		"":                               "",
+		" https://example.com/squat.mp4": "https://example.com/squat.mp4",
+		"/api/media/0190a5b2-7c1e-7d44-9a3e-1f2b3c4d5e6f.mp4": "/api/media/0190a5b2-7c1e-7d44-9a3e-1f2b3c4d5e6f.mp4",
+	} {
+		if got, err := NormalizeURL(raw, "video_url"); err != nil || got != want {
+			t.Errorf("NormalizeURL(%q) = %q, %v; want %q", raw, got, err, want)
+		}
+	}
+	for _, raw := range []string{"javascript:alert(1)", "/api/media/../secret", "ftp://example.com/a.mp4", "https://" + strings.Repeat("a", MaxURLLength)} {
+		if _, err := NormalizeURL(raw, "video_url"); err == nil {
+			t.Errorf("NormalizeURL(%q) should fail", raw)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+-- Template recommendations precomputed by the background job
+CREATE TABLE IF NOT EXISTS template_recommendations (
+    user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    template_id VARCHAR(100) NOT NULL,
+    kind VARCHAR(50) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    score DOUBLE PRECISION NOT NULL,
+    reasons TEXT NOT NULL DEFAULT '[]',
+    computed_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, kind, template_id)
+);
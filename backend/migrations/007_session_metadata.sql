@@ -0,0 +1,2 @@
+-- Flexible session context (gym, partners, playlist, mood) stored as JSON
+ALTER TABLE workout_sessions ADD COLUMN IF NOT EXISTS metadata TEXT;
@@ -0,0 +1,12 @@
+-- Per-token call counts for deprecated API routes, used by the admin deprecation report
+CREATE TABLE IF NOT EXISTS deprecated_endpoint_usage (
+    method VARCHAR(10) NOT NULL,
+    path VARCHAR(255) NOT NULL,
+    user_id VARCHAR(36) NOT NULL,
+    token_id VARCHAR(64) NOT NULL,
+    user_agent TEXT NOT NULL DEFAULT '',
+    call_count BIGINT NOT NULL DEFAULT 0,
+    first_seen TIMESTAMP NOT NULL,
+    last_seen TIMESTAMP NOT NULL,
+    PRIMARY KEY (method, path, user_id, token_id)
+);
@@ -0,0 +1,21 @@
+-- Passkey (WebAuthn) credentials and single-use ceremony challenges
+CREATE TABLE IF NOT EXISTS webauthn_credentials (
+    id VARCHAR(512) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    public_key BYTEA NOT NULL,
+    algorithm INTEGER NOT NULL,
+    sign_count BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_webauthn_credentials_user_id ON webauthn_credentials(user_id);
+
+CREATE TABLE IF NOT EXISTS webauthn_challenges (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL DEFAULT '',
+    kind VARCHAR(20) NOT NULL,
+    challenge VARCHAR(100) NOT NULL,
+    expires_at TIMESTAMP NOT NULL
+);
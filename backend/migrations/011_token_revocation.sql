@@ -0,0 +1,15 @@
+-- Server-side token revocation: single-token denylist and per-user "logout everywhere" cutoffs.
+-- Times are unix seconds to match JWT iat/exp.
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    jti VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL,
+    expires_at BIGINT NOT NULL,
+    revoked_at BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_revoked_tokens_expires_at ON revoked_tokens(expires_at);
+
+CREATE TABLE IF NOT EXISTS user_token_cutoffs (
+    user_id VARCHAR(36) PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    revoked_before BIGINT NOT NULL
+);
@@ -0,0 +1,2 @@
+-- password_reset_tokens also stores magic-link login tokens, distinguished by purpose
+ALTER TABLE password_reset_tokens ADD COLUMN IF NOT EXISTS purpose VARCHAR(20) NOT NULL DEFAULT 'password_reset';
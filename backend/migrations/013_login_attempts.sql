@@ -0,0 +1,8 @@
+-- Failed login tracking for account lockout and per-IP throttling.
+-- attempt_key is "account:<email>" or "ip:<address>"; times are unix seconds.
+CREATE TABLE IF NOT EXISTS login_attempts (
+    attempt_key VARCHAR(320) PRIMARY KEY,
+    failures INTEGER NOT NULL DEFAULT 0,
+    last_failure BIGINT NOT NULL,
+    locked_until BIGINT NOT NULL DEFAULT 0
+);
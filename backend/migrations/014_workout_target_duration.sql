@@ -0,0 +1,2 @@
+-- Optional session duration goal per workout, used for live pace tracking
+ALTER TABLE workouts ADD COLUMN IF NOT EXISTS target_duration_minutes INTEGER;
@@ -0,0 +1,11 @@
+-- In-app alerts (e.g. training load ramping too fast). Times are unix seconds.
+CREATE TABLE IF NOT EXISTS user_alerts (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(50) NOT NULL,
+    message TEXT NOT NULL,
+    created_at BIGINT NOT NULL,
+    dismissed_at BIGINT
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_alerts_user_id ON user_alerts(user_id, created_at);
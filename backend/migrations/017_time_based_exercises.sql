@@ -0,0 +1,6 @@
+-- Time-based exercises (planks, holds): target time per set instead of reps
+ALTER TABLE exercises ADD COLUMN IF NOT EXISTS mode VARCHAR(20) NOT NULL DEFAULT 'reps';
+ALTER TABLE exercises ADD COLUMN IF NOT EXISTS duration_seconds INTEGER NOT NULL DEFAULT 0;
+
+-- Seconds actually held for sets of duration-mode exercises
+ALTER TABLE exercise_sets ADD COLUMN IF NOT EXISTS duration_seconds INTEGER;
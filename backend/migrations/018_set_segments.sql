@@ -0,0 +1,6 @@
+-- Drop sets and rest-pause sets: extra weight/rep segments logged within one set
+ALTER TABLE exercise_sets ADD COLUMN IF NOT EXISTS technique VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE exercise_sets ADD COLUMN IF NOT EXISTS segments TEXT;
+
+-- Weight x reps of the segments, kept alongside them for volume queries
+ALTER TABLE exercise_sets ADD COLUMN IF NOT EXISTS segment_volume DECIMAL(12,2) NOT NULL DEFAULT 0;
@@ -0,0 +1,12 @@
+-- Email changes awaiting confirmation from the new address; only the SHA-256 of
+-- each confirmation token is stored. Times are unix seconds.
+CREATE TABLE IF NOT EXISTS pending_email_changes (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    new_email VARCHAR(255) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at BIGINT NOT NULL,
+    created_at BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_pending_email_changes_user_id ON pending_email_changes(user_id);
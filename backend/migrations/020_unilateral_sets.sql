@@ -0,0 +1,6 @@
+-- Single-arm/leg exercises log left and right results in one set entry
+ALTER TABLE exercises ADD COLUMN IF NOT EXISTS unilateral BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE exercise_sets ADD COLUMN IF NOT EXISTS sides TEXT;
+
+-- Weight x reps of the right side (reps/weight mirror the left), for volume queries
+ALTER TABLE exercise_sets ADD COLUMN IF NOT EXISTS side_volume DECIMAL(12,2) NOT NULL DEFAULT 0;
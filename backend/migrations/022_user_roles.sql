@@ -0,0 +1,3 @@
+-- Database-backed roles: user, coach or admin. ADMIN_EMAILS still grants admin
+-- on top of this, so the first admin can sign in before any role is stored.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'user';
@@ -0,0 +1,16 @@
+-- Inactivity data retention (RETENTION_INACTIVE_DAYS). last_active_at moves with
+-- user_sessions.last_seen_at; NULL until the retention job first sees the account.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS last_active_at BIGINT;
+
+CREATE TABLE IF NOT EXISTS retention_warnings (
+    user_id VARCHAR(36) PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    warned_at BIGINT NOT NULL,
+    delete_after BIGINT NOT NULL
+);
+
+-- Deleted accounts keep no email, only when they were last active and removed
+CREATE TABLE IF NOT EXISTS retention_deletions (
+    user_id VARCHAR(36) PRIMARY KEY,
+    last_active_at BIGINT NOT NULL,
+    deleted_at BIGINT NOT NULL
+);
@@ -0,0 +1,15 @@
+-- Training partner shared sessions. The host invites a partner into their active
+-- session; joining gives the partner their own session of the same workout, and
+-- partner_session_id links the two once status is 'joined'. Times are unix seconds.
+CREATE TABLE IF NOT EXISTS partner_sessions (
+    id VARCHAR(36) PRIMARY KEY,
+    host_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    host_session_id VARCHAR(36) NOT NULL UNIQUE,
+    partner_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    partner_session_id VARCHAR(36),
+    status VARCHAR(16) NOT NULL DEFAULT 'invited',
+    created_at BIGINT NOT NULL,
+    joined_at BIGINT
+);
+
+CREATE INDEX IF NOT EXISTS idx_partner_sessions_partner_id ON partner_sessions(partner_id);
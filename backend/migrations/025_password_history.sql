@@ -0,0 +1,10 @@
+-- Previous password hashes, so resets cannot reuse the last PASSWORD_HISTORY passwords.
+-- created_at is unix seconds; at most 24 entries are kept per user.
+CREATE TABLE IF NOT EXISTS password_history (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    password_hash TEXT NOT NULL,
+    created_at BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_history_user_id ON password_history(user_id, created_at);
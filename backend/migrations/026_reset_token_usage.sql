@@ -0,0 +1,5 @@
+-- Redeemed password reset tokens are kept with the time they were used, so a
+-- token cannot be redeemed twice; expired tokens are purged by a background job.
+-- login_attempts also throttles forgot-password requests under "reset:account:<email>"
+-- and "reset:ip:<address>" keys.
+ALTER TABLE password_reset_tokens ADD COLUMN IF NOT EXISTS used_at TIMESTAMP;
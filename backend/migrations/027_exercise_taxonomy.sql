@@ -0,0 +1,16 @@
+-- Admin-managed exercise taxonomy. Top-level rows are categories ("Chest");
+-- rows with a parent are muscle groups within one ("Biceps" under "Arms").
+-- exercise_library files each built-in library exercise under a category or
+-- muscle group. The server seeds both tables on first start. Times are unix seconds.
+CREATE TABLE IF NOT EXISTS exercise_categories (
+    id VARCHAR(36) PRIMARY KEY,
+    parent_id VARCHAR(36) REFERENCES exercise_categories(id) ON DELETE RESTRICT,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at BIGINT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS exercise_library (
+    name VARCHAR(100) PRIMARY KEY,
+    category_id VARCHAR(36) NOT NULL REFERENCES exercise_categories(id) ON DELETE RESTRICT
+);
@@ -0,0 +1,12 @@
+-- Changes made to a user's history, by the user or an admin acting for them
+-- (actor_id). detail is JSON describing the change. Times are unix seconds.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    actor_id VARCHAR(36) NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    detail TEXT NOT NULL DEFAULT '{}',
+    created_at BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_user_id ON audit_log(user_id, created_at);
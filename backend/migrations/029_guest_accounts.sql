@@ -0,0 +1,11 @@
+-- Provisional users created for a device so people can log workouts before
+-- registering. device_hash is the SHA-256 of the client's device ID; the row
+-- is deleted when the guest claims an account. Times are unix seconds.
+CREATE TABLE IF NOT EXISTS guest_accounts (
+    user_id VARCHAR(36) PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    device_hash VARCHAR(64) NOT NULL UNIQUE,
+    created_at BIGINT NOT NULL,
+    last_seen_at BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_guest_accounts_last_seen_at ON guest_accounts(last_seen_at);
@@ -0,0 +1,12 @@
+-- Successful password sign-ins. A sign-in from an IP and user agent pair not
+-- seen before for the user triggers a new-login email. Times are unix seconds.
+CREATE TABLE IF NOT EXISTS login_events (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    ip VARCHAR(45) NOT NULL,
+    user_agent VARCHAR(255) NOT NULL,
+    created_at BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_events_user_device ON login_events(user_id, ip, user_agent);
+CREATE INDEX IF NOT EXISTS idx_login_events_created_at ON login_events(created_at);
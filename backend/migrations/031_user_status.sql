@@ -0,0 +1,3 @@
+-- Account status: 'active' or 'suspended'. Suspended users cannot sign in,
+-- and their existing tokens and API keys are rejected.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'active';
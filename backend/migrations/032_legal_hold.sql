@@ -0,0 +1,14 @@
+-- Legal hold: accounts under hold cannot be deleted, in whole or record by record.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS legal_hold BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Pending admin hard deletes, each confirmed with a single-use token.
+CREATE TABLE IF NOT EXISTS hard_delete_confirmations (
+    token_hash VARCHAR(64) PRIMARY KEY,
+    admin_id VARCHAR(36) NOT NULL,
+    user_id VARCHAR(36) NOT NULL,
+    kind VARCHAR(20) NOT NULL,
+    record_id VARCHAR(36) NOT NULL,
+    reason TEXT NOT NULL,
+    expires_at BIGINT NOT NULL,
+    created_at BIGINT NOT NULL
+);
@@ -0,0 +1,50 @@
+-- Analytics materialized views (Postgres only). Refreshed concurrently by the
+-- analytics-view-refresh job so reads, including on hot standbys, are never blocked.
+
+-- Completed-set totals per user, exercise and day, as served by GET /api/progress.
+CREATE MATERIALIZED VIEW IF NOT EXISTS daily_exercise_volume AS
+SELECT
+    ws.user_id,
+    e.name AS exercise_name,
+    DATE(es.created_at) AS day,
+    MAX(es.weight) AS max_weight,
+    SUM(es.weight * es.reps + es.segment_volume + es.side_volume) AS total_volume,
+    MAX(e.mode) AS mode,
+    COALESCE(SUM(es.duration_seconds), 0) AS total_duration,
+    COALESCE(MAX(es.duration_seconds), 0) AS max_duration
+FROM exercise_sets es
+JOIN session_exercises se ON es.session_exercise_id = se.id
+JOIN workout_sessions ws ON se.session_id = ws.id
+JOIN exercises e ON se.exercise_id = e.id
+WHERE es.completed = true AND es.set_type <> 'warmup'
+GROUP BY ws.user_id, e.name, DATE(es.created_at);
+
+-- REFRESH ... CONCURRENTLY needs a unique index
+CREATE UNIQUE INDEX IF NOT EXISTS idx_daily_exercise_volume_key ON daily_exercise_volume(user_id, exercise_name, day);
+
+-- Finished sessions per user and calendar week (starting Monday).
+CREATE MATERIALIZED VIEW IF NOT EXISTS weekly_training_summary AS
+SELECT user_id, week_start, COUNT(*) AS sessions, SUM(sets) AS sets, SUM(tonnage) AS tonnage, SUM(minutes) AS minutes
+FROM (
+    SELECT
+        ws.user_id,
+        DATE_TRUNC('week', ws.ended_at)::date AS week_start,
+        COUNT(es.id) AS sets,
+        COALESCE(SUM(es.weight * es.reps + es.segment_volume + es.side_volume), 0)::float8 AS tonnage,
+        EXTRACT(EPOCH FROM ws.ended_at - ws.started_at)::float8 / 60 AS minutes
+    FROM workout_sessions ws
+    LEFT JOIN session_exercises se ON se.session_id = ws.id
+    LEFT JOIN exercise_sets es ON es.session_exercise_id = se.id AND es.completed = true AND es.set_type <> 'warmup'
+    WHERE ws.ended_at IS NOT NULL
+    GROUP BY ws.id
+) s
+GROUP BY user_id, week_start;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_weekly_training_summary_key ON weekly_training_summary(user_id, week_start);
+
+-- When each view was last refreshed. Reads use the view for periods that ended
+-- before then and query the tables directly for the rest.
+CREATE TABLE IF NOT EXISTS analytics_view_refreshes (
+    view_name VARCHAR(64) PRIMARY KEY,
+    refreshed_at TIMESTAMP NOT NULL
+);
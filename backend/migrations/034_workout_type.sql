@@ -0,0 +1,2 @@
+-- Workout category (strength, cardio, ...); empty means uncategorized
+ALTER TABLE workouts ADD COLUMN IF NOT EXISTS type VARCHAR(20) NOT NULL DEFAULT '';
@@ -0,0 +1,9 @@
+-- Explicit exercise order within a workout, starting at 1
+ALTER TABLE exercises ADD COLUMN IF NOT EXISTS position INTEGER NOT NULL DEFAULT 0;
+
+-- Existing exercises keep the order they were created in
+UPDATE exercises e SET position = ranked.rn
+FROM (SELECT id, ROW_NUMBER() OVER (PARTITION BY workout_id ORDER BY created_at, id) AS rn FROM exercises WHERE position = 0) ranked
+WHERE e.id = ranked.id;
+
+CREATE INDEX IF NOT EXISTS idx_exercises_workout_position ON exercises(workout_id, position);
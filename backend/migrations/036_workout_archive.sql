@@ -0,0 +1,2 @@
+-- Archived workouts are hidden from the workout list but keep their session history
+ALTER TABLE workouts ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP;
@@ -0,0 +1,9 @@
+-- Free-form labels on workouts (e.g. "legs", "deload"), stored lowercase
+CREATE TABLE IF NOT EXISTS workout_tags (
+    workout_id VARCHAR(36) NOT NULL REFERENCES workouts(id) ON DELETE CASCADE,
+    tag VARCHAR(30) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (workout_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS idx_workout_tags_tag ON workout_tags(tag);
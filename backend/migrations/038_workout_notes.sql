@@ -0,0 +1,2 @@
+-- Freeform plan notes on a workout ("focus on pause reps this block")
+ALTER TABLE workouts ADD COLUMN IF NOT EXISTS notes TEXT NOT NULL DEFAULT '';
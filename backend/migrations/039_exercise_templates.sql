@@ -0,0 +1,15 @@
+-- Exercise templates users add alongside the built-in exercise library
+CREATE TABLE IF NOT EXISTS exercise_templates (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    default_sets INTEGER NOT NULL DEFAULT 3,
+    default_reps INTEGER NOT NULL DEFAULT 0,
+    default_weight DECIMAL(8,2) NOT NULL DEFAULT 0,
+    mode VARCHAR(20) NOT NULL DEFAULT '',
+    default_duration_seconds INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_exercise_templates_user_id ON exercise_templates(user_id);
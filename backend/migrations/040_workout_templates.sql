@@ -0,0 +1,25 @@
+-- Built-in workout templates, read by GET /api/workout-templates and copied by
+-- POST /api/workout-templates/:id/create. The server seeds both tables on first
+-- start; rows added later show up without a release.
+CREATE TABLE IF NOT EXISTS workout_templates (
+    id VARCHAR(100) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    type VARCHAR(20) NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    difficulty VARCHAR(20) NOT NULL DEFAULT '',
+    duration_minutes INTEGER NOT NULL DEFAULT 0,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS workout_template_exercises (
+    template_id VARCHAR(100) NOT NULL REFERENCES workout_templates(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    sets INTEGER NOT NULL,
+    reps INTEGER NOT NULL DEFAULT 0,
+    weight DECIMAL(8,2) NOT NULL DEFAULT 0,
+    mode VARCHAR(20) NOT NULL DEFAULT '',
+    duration_seconds INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (template_id, position)
+);
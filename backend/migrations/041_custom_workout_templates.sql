@@ -0,0 +1,4 @@
+-- Workout templates users save from their own workouts. Built-in templates have no owner.
+ALTER TABLE workout_templates ADD COLUMN IF NOT EXISTS user_id VARCHAR(36) REFERENCES users(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_workout_templates_user_id ON workout_templates(user_id);
@@ -0,0 +1,5 @@
+-- Public share links for user templates. Only the SHA-256 of the token in the
+-- link is stored; sharing again replaces it, breaking the old link.
+ALTER TABLE workout_templates ADD COLUMN IF NOT EXISTS share_token_hash VARCHAR(64);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_workout_templates_share_token_hash ON workout_templates(share_token_hash);
@@ -0,0 +1,4 @@
+-- Supersets and circuits: exercises sharing a group_id are performed back to
+-- back and kept next to each other in the workout. Ungrouped exercises have ''.
+ALTER TABLE exercises ADD COLUMN IF NOT EXISTS group_id VARCHAR(36) NOT NULL DEFAULT '';
+ALTER TABLE exercises ADD COLUMN IF NOT EXISTS group_type VARCHAR(20) NOT NULL DEFAULT '';
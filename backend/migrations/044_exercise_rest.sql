@@ -0,0 +1,5 @@
+-- Planned rest between sets, so clients can run rest timers from the plan.
+-- 0 means no rest time was set.
+ALTER TABLE exercises ADD COLUMN IF NOT EXISTS rest_seconds INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE exercise_templates ADD COLUMN IF NOT EXISTS default_rest_seconds INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE workout_template_exercises ADD COLUMN IF NOT EXISTS rest_seconds INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,5 @@
+-- Intensity-based programming: a target RPE and lifting tempo per exercise,
+-- and the RPE actually reached on each logged set. NULL or '' when not set.
+ALTER TABLE exercises ADD COLUMN IF NOT EXISTS target_rpe DECIMAL(3,1);
+ALTER TABLE exercises ADD COLUMN IF NOT EXISTS tempo VARCHAR(10) NOT NULL DEFAULT '';
+ALTER TABLE exercise_sets ADD COLUMN IF NOT EXISTS actual_rpe DECIMAL(3,1);
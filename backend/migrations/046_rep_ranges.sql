@@ -0,0 +1,7 @@
+-- Rep ranges such as 8-12. 0/0 means a single rep count, given by reps.
+ALTER TABLE exercises ADD COLUMN IF NOT EXISTS reps_min INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE exercises ADD COLUMN IF NOT EXISTS reps_max INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE exercise_templates ADD COLUMN IF NOT EXISTS default_reps_min INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE exercise_templates ADD COLUMN IF NOT EXISTS default_reps_max INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE workout_template_exercises ADD COLUMN IF NOT EXISTS reps_min INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE workout_template_exercises ADD COLUMN IF NOT EXISTS reps_max INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+-- Generated warm-up sets logged ahead of an exercise's working sets
+ALTER TABLE exercise_sets ADD COLUMN IF NOT EXISTS warmup BOOLEAN NOT NULL DEFAULT false;
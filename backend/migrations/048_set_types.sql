@@ -0,0 +1,6 @@
+-- Set types: normal, amrap, drop, failure or warmup. Replaces the warmup flag.
+ALTER TABLE exercise_sets ADD COLUMN IF NOT EXISTS set_type VARCHAR(20) NOT NULL DEFAULT 'normal';
+UPDATE exercise_sets SET set_type = 'warmup', warmup = false WHERE warmup;
+UPDATE exercise_sets SET set_type = 'drop' WHERE technique = 'drop_set' AND set_type = 'normal';
+-- Recreate the analytics views from 033_analytics_views.sql, which now leave warm-ups out
+DROP MATERIALIZED VIEW IF EXISTS daily_exercise_volume, weekly_training_summary;
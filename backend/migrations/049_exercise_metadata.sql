@@ -0,0 +1,5 @@
+-- Exercise template metadata. The muscle and equipment lists are JSON arrays.
+ALTER TABLE exercise_templates ADD COLUMN IF NOT EXISTS primary_muscles TEXT;
+ALTER TABLE exercise_templates ADD COLUMN IF NOT EXISTS secondary_muscles TEXT;
+ALTER TABLE exercise_templates ADD COLUMN IF NOT EXISTS equipment TEXT;
+ALTER TABLE exercise_templates ADD COLUMN IF NOT EXISTS instructions TEXT;
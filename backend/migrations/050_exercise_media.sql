@@ -0,0 +1,5 @@
+-- Demonstration media: linked URLs or uploads served under /api/media/
+ALTER TABLE exercises ADD COLUMN IF NOT EXISTS video_url TEXT NOT NULL DEFAULT '';
+ALTER TABLE exercises ADD COLUMN IF NOT EXISTS image_url TEXT NOT NULL DEFAULT '';
+ALTER TABLE exercise_templates ADD COLUMN IF NOT EXISTS video_url TEXT NOT NULL DEFAULT '';
+ALTER TABLE exercise_templates ADD COLUMN IF NOT EXISTS image_url TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,7 @@
+-- Per-user display preferences. Weights stay stored in pounds; metric users
+-- send and receive kilograms, converted at the API edge.
+CREATE TABLE IF NOT EXISTS user_preferences (
+    user_id VARCHAR(36) PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    units VARCHAR(10) NOT NULL DEFAULT 'imperial',
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,15 @@
+-- Cardio exercises: a target time and/or distance instead of reps, and the
+-- distance actually covered on each logged set. 0 or NULL when not set.
+ALTER TABLE exercises ADD COLUMN IF NOT EXISTS distance_meters INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE exercise_sets ADD COLUMN IF NOT EXISTS distance_meters INTEGER;
+ALTER TABLE exercise_templates ADD COLUMN IF NOT EXISTS default_distance_meters INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE workout_template_exercises ADD COLUMN IF NOT EXISTS distance_meters INTEGER NOT NULL DEFAULT 0;
+
+-- The built-in endurance run logged its cardio as 1 x 1 rep
+UPDATE workout_template_exercises SET mode = 'cardio', reps = 0, duration_seconds = 1800
+    WHERE template_id = 'endurance-run' AND name = 'Running' AND mode = '';
+UPDATE workout_template_exercises SET mode = 'cardio', reps = 0, duration_seconds = 900
+    WHERE template_id = 'endurance-run' AND name = 'Walking' AND mode = '';
+
+-- daily_exercise_volume gains total_distance and max_distance
+DROP MATERIALIZED VIEW IF EXISTS daily_exercise_volume;
@@ -0,0 +1,14 @@
+-- Training calendar: workouts planned for a day, optionally at a time of day.
+-- date (YYYY-MM-DD) and time (HH:MM) are kept as the client's local values.
+CREATE TABLE IF NOT EXISTS scheduled_workouts (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    workout_id VARCHAR(36) NOT NULL REFERENCES workouts(id) ON DELETE CASCADE,
+    date VARCHAR(10) NOT NULL,
+    time VARCHAR(5),
+    status VARCHAR(20) NOT NULL DEFAULT 'planned',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_workouts_user_date ON scheduled_workouts(user_id, date);
@@ -0,0 +1,45 @@
+-- Multi-week training programs: the same workouts every week, with a
+-- per-week progression applied to their weights, sets and reps.
+CREATE TABLE IF NOT EXISTS programs (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_programs_user ON programs(user_id);
+
+CREATE TABLE IF NOT EXISTS program_workouts (
+    program_id VARCHAR(36) NOT NULL REFERENCES programs(id) ON DELETE CASCADE,
+    day INTEGER NOT NULL,
+    workout_id VARCHAR(36) NOT NULL REFERENCES workouts(id) ON DELETE CASCADE,
+    PRIMARY KEY (program_id, day)
+);
+
+CREATE TABLE IF NOT EXISTS program_weeks (
+    program_id VARCHAR(36) NOT NULL REFERENCES programs(id) ON DELETE CASCADE,
+    week INTEGER NOT NULL,
+    weight_percent DOUBLE PRECISION NOT NULL DEFAULT 100,
+    sets_delta INTEGER NOT NULL DEFAULT 0,
+    reps_delta INTEGER NOT NULL DEFAULT 0,
+    notes TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (program_id, week)
+);
+
+-- A user's place in a program; at most one is active at a time.
+-- days_done counts the current week's workouts started from the program.
+CREATE TABLE IF NOT EXISTS program_enrollments (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    program_id VARCHAR(36) NOT NULL REFERENCES programs(id) ON DELETE CASCADE,
+    week INTEGER NOT NULL DEFAULT 1,
+    days_done INTEGER NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    started_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_program_enrollments_user ON program_enrollments(user_id, status);
@@ -0,0 +1,16 @@
+-- Progression rules: per-exercise weight changes applied when a session ends.
+-- failures counts sessions missed in a row; last_session_id keeps a session
+-- from being counted twice.
+CREATE TABLE IF NOT EXISTS progression_rules (
+    exercise_id VARCHAR(36) PRIMARY KEY REFERENCES exercises(id) ON DELETE CASCADE,
+    user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    weight_increment DOUBLE PRECISION NOT NULL DEFAULT 5,
+    failure_limit INTEGER NOT NULL DEFAULT 2,
+    deload_percent DOUBLE PRECISION NOT NULL DEFAULT 10,
+    failures INTEGER NOT NULL DEFAULT 0,
+    last_session_id VARCHAR(36),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_progression_rules_user ON progression_rules(user_id);
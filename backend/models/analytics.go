@@ -0,0 +1,118 @@
+package models
+
+import "time"
+
+// Training load metrics
+const (
+	LoadMetricTonnage  = "tonnage"  // sum of weight x reps over completed sets
+	LoadMetricDuration = "duration" // session minutes
+)
+
+// SessionLoad is the work done in one completed session
+type SessionLoad struct {
+	SessionID string    `json:"session_id"`
+	EndedAt   time.Time `json:"ended_at"`
+	Tonnage   float64   `json:"tonnage"`
+	Sets      int       `json:"sets"`
+	Minutes   float64   `json:"minutes"`
+}
+
+// WeeklyLoad totals the sessions of one 7-day window
+type WeeklyLoad struct {
+	WeekStart time.Time `json:"week_start"`
+	Sessions  int       `json:"sessions"`
+	Sets      int       `json:"sets"`
+	Tonnage   float64   `json:"tonnage"`
+	Minutes   float64   `json:"minutes"`
+}
+
+// TrainingLoad compares the last week's load (acute) with the four-week
+// average (chronic). A ratio above Threshold suggests load is ramping too fast.
+type TrainingLoad struct {
+	Metric    string       `json:"metric"`
+	Weeks     []WeeklyLoad `json:"weeks"` // newest first
+	Acute     float64      `json:"acute"`
+	Chronic   float64      `json:"chronic"`
+	Ratio     *float64     `json:"ratio"` // nil until there is chronic load to compare against
+	Threshold float64      `json:"threshold"`
+	RampAlert bool         `json:"ramp_alert"`
+}
+
+// Alert kinds
+const (
+	AlertKindLoadRamp = "load_ramp"
+)
+
+// Alert is an in-app notice for the user, shown until dismissed
+type Alert struct {
+	ID          string     `json:"id" db:"id"`
+	UserID      string     `json:"-" db:"user_id"`
+	Kind        string     `json:"kind" db:"kind"`
+	Message     string     `json:"message" db:"message"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	DismissedAt *time.Time `json:"dismissed_at" db:"dismissed_at"`
+}
+
+// Crowd ratings journaled in session metadata, from empty to packed
+const (
+	MinCrowdRating = 1
+	MaxCrowdRating = 5
+)
+
+// CrowdRating is how busy a gym was when a session started
+type CrowdRating struct {
+	StartedAt time.Time
+	Crowd     int
+}
+
+// CrowdSlot aggregates the crowd ratings of one weekday and hour
+type CrowdSlot struct {
+	Weekday  string  `json:"weekday"`
+	Hour     int     `json:"hour"`
+	AvgCrowd float64 `json:"avg_crowd"`
+	Samples  int     `json:"samples"`
+	Score    float64 `json:"score"` // average pulled toward the gym mean when samples are few; lower is quieter
+}
+
+// ExerciseBest is the best completed result logged for an exercise
+type ExerciseBest struct {
+	Weight          float64
+	E1RM            float64
+	Reps            int
+	DurationSeconds int
+	DistanceMeters  int
+}
+
+// Personal record kinds
+const (
+	RecordWeight   = "weight"   // heaviest weight lifted
+	RecordE1RM     = "e1rm"     // best estimated one-rep max
+	RecordReps     = "reps"     // most reps, for bodyweight exercises
+	RecordDuration = "duration" // longest hold, for timed exercises
+	RecordDistance = "distance" // furthest distance in one set, for cardio exercises
+)
+
+// SetRecord marks a set that beat the user's previous best for its exercise
+type SetRecord struct {
+	SetID    string  `json:"set_id"`
+	Kind     string  `json:"kind"`
+	Value    float64 `json:"value"`
+	Previous float64 `json:"previous"`
+}
+
+// LoggedSet is one completed working set of an exercise, with its session
+type LoggedSet struct {
+	SessionID string
+	StartedAt time.Time
+	Weight    float64
+	Reps      int
+}
+
+// OneRepMaxPoint is the best estimated one-rep max of one session, and the set behind it
+type OneRepMaxPoint struct {
+	SessionID string    `json:"session_id"`
+	Date      time.Time `json:"date"` // when the session started
+	Weight    float64   `json:"weight"`
+	Reps      int       `json:"reps"`
+	E1RM      float64   `json:"e1rm"`
+}
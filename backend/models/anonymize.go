@@ -0,0 +1,100 @@
+package models
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"unicode"
+)
+
+// AnonymizeReport counts what an anonymization run rewrote or removed
+type AnonymizeReport struct {
+	Users    int64            `json:"users"`
+	Notes    int64            `json:"notes"`    // set, workout and injury notes and injury names scrambled
+	Sessions int64            `json:"sessions"` // sessions whose metadata was rewritten
+	Deleted  map[string]int64 `json:"deleted"`  // credential and log rows removed, by table
+}
+
+// Scrambler replaces personal text with stand-ins that keep its shape. It is
+// deterministic for one key, so repeated values (the same note, the same gym)
+// stay repeated, but the original cannot be recovered without the key.
+type Scrambler struct {
+	key    []byte
+	labels map[string]map[string]int // kind -> value -> number
+}
+
+// NewScrambler creates a scrambler; use a fresh random key for every run
+func NewScrambler(key []byte) *Scrambler {
+	return &Scrambler{key: key, labels: map[string]map[string]int{}}
+}
+
+// Text replaces every letter and digit in s, keeping its length, case, spacing
+// and punctuation
+func (s *Scrambler) Text(text string) string {
+	if text == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(text))
+	seed := mac.Sum(nil)
+	stream := func(i int) uint32 {
+		// Extend the seed with a counter so long text does not repeat
+		h := hmac.New(sha256.New, seed)
+		binary.Write(h, binary.BigEndian, uint32(i/8))
+		sum := h.Sum(nil)
+		return binary.BigEndian.Uint32(sum[(i%8)*4:])
+	}
+
+	out := []rune(text)
+	for i, r := range out {
+		switch {
+		case unicode.IsUpper(r):
+			out[i] = 'A' + rune(stream(i)%26)
+		case unicode.IsLetter(r):
+			out[i] = 'a' + rune(stream(i)%26)
+		case unicode.IsDigit(r):
+			out[i] = '0' + rune(stream(i)%10)
+		}
+	}
+	return string(out)
+}
+
+// Label maps value to "<prefix> <n>", numbering distinct values of one kind in
+// the order first seen, so counts per value are preserved
+func (s *Scrambler) Label(kind, prefix, value string) string {
+	if value == "" {
+		return ""
+	}
+	seen := s.labels[kind]
+	if seen == nil {
+		seen = map[string]int{}
+		s.labels[kind] = seen
+	}
+	n, ok := seen[value]
+	if !ok {
+		n = len(seen) + 1
+		seen[value] = n
+	}
+	return fmt.Sprintf("%s %d", prefix, n)
+}
+
+// Metadata rewrites session metadata: gyms, partners and moods become numbered
+// labels, playlists are dropped and free-form values scrambled. Crowd ratings are kept.
+func (s *Scrambler) Metadata(m SessionMetadata) SessionMetadata {
+	out := SessionMetadata{
+		Gym:   s.Label("gym", "Gym", m.Gym),
+		Mood:  s.Label("mood", "Mood", m.Mood),
+		Crowd: m.Crowd,
+	}
+	for _, p := range m.Partners {
+		out.Partners = append(out.Partners, s.Label("partner", "Partner", p))
+	}
+	if len(m.Extra) > 0 {
+		out.Extra = make(map[string]string, len(m.Extra))
+		for k, v := range m.Extra {
+			out.Extra[k] = s.Text(v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"testing"
+	"unicode"
+)
+
+func TestScramblerText(t *testing.T) {
+	s := NewScrambler([]byte("key"))
+	in := "Felt tweak in L knee @ 3rd set, 225lb!"
+	out := s.Text(in)
+	if out == in || len([]rune(out)) != len([]rune(in)) {
+		t.Fatalf("Text(%q) = %q", in, out)
+	}
+	for i, r := range []rune(in) {
+		o := []rune(out)[i]
+		switch {
+		case unicode.IsUpper(r):
+			if !unicode.IsUpper(o) {
+				t.Errorf("rune %d: %q, want upper case", i, o)
+			}
+		case unicode.IsLetter(r):
+			if !unicode.IsLower(o) {
+				t.Errorf("rune %d: %q, want lower case", i, o)
+			}
+		case unicode.IsDigit(r):
+			if !unicode.IsDigit(o) {
+				t.Errorf("rune %d: %q, want digit", i, o)
+			}
+		default:
+			if o != r {
+				t.Errorf("rune %d: %q, want %q kept", i, o, r)
+			}
+		}
+	}
+	if again := s.Text(in); again != out {
+		t.Errorf("Text is not deterministic: %q then %q", out, again)
+	}
+	if other := NewScrambler([]byte("other")).Text(in); other == out {
+		t.Errorf("different keys gave the same output %q", out)
+	}
+}
+
+func TestScramblerMetadata(t *testing.T) {
+	s := NewScrambler([]byte("key"))
+	first := s.Metadata(SessionMetadata{
+		Gym: "Downtown Gym", Crowd: 3, Mood: "tired", Partners: []string{"Sam", "Alex"},
+		PlaylistURL: "https://example.com/mix", Extra: map[string]string{"coach": "Jordan"},
+	})
+	second := s.Metadata(SessionMetadata{Gym: "Home", Partners: []string{"Alex"}})
+	again := s.Metadata(SessionMetadata{Gym: "Downtown Gym"})
+
+	if first.Gym != "Gym 1" || second.Gym != "Gym 2" || again.Gym != "Gym 1" {
+		t.Errorf("gyms = %q, %q, %q", first.Gym, second.Gym, again.Gym)
+	}
+	if len(first.Partners) != 2 || first.Partners[0] != "Partner 1" || first.Partners[1] != "Partner 2" || second.Partners[0] != "Partner 2" {
+		t.Errorf("partners = %v, %v", first.Partners, second.Partners)
+	}
+	if first.Mood != "Mood 1" || first.Crowd != 3 || first.PlaylistURL != "" {
+		t.Errorf("metadata = %+v", first)
+	}
+	if coach := first.Extra["coach"]; coach == "Jordan" || len(coach) != len("Jordan") {
+		t.Errorf("extra coach = %q", coach)
+	}
+}
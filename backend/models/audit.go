@@ -0,0 +1,27 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Audit actions
+const (
+	AuditActionSessionsRetimed   = "sessions.retimed"
+	AuditActionAccountSuspended  = "account.suspended"
+	AuditActionAccountReinstated = "account.reinstated"
+	AuditActionLegalHoldPlaced   = "account.legal_hold_placed"
+	AuditActionLegalHoldReleased = "account.legal_hold_released"
+	AuditActionRecordHardDeleted = "record.hard_deleted"
+)
+
+// AuditEntry records a change made to a user's history. ActorID is the user
+// themselves or the admin acting for them; Detail is action-specific JSON.
+type AuditEntry struct {
+	ID        string          `json:"id"`
+	UserID    string          `json:"-"`
+	ActorID   string          `json:"actor_id"`
+	Action    string          `json:"action"`
+	Detail    json.RawMessage `json:"detail"`
+	CreatedAt time.Time       `json:"created_at"`
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Limits on a session time correction
+const (
+	MaxCorrectionOffsetHours = 48
+	MaxCorrectedSessions     = 500
+)
+
+// SessionTimeCorrection moves completed sessions logged at the wrong time, e.g.
+// while traveling across time zones. Sessions are picked by ID or by a start
+// time range, then either shifted by OffsetHours or moved to Date, keeping
+// their time of day in TimeZone.
+type SessionTimeCorrection struct {
+	SessionIDs  []string   `json:"session_ids"`
+	From        *time.Time `json:"from"`
+	To          *time.Time `json:"to"`
+	OffsetHours int        `json:"offset_hours"`
+	Date        string     `json:"date"`     // YYYY-MM-DD
+	TimeZone    string     `json:"timezone"` // IANA name for Date, default UTC
+}
+
+// Validate checks the selection and the change, and returns the function mapping
+// a session's start time to its corrected one
+func (c SessionTimeCorrection) Validate() (func(time.Time) time.Time, error) {
+	byRange := c.From != nil || c.To != nil
+	switch {
+	case len(c.SessionIDs) == 0 && !byRange:
+		return nil, errors.New("session_ids or from and to are required")
+	case len(c.SessionIDs) > 0 && byRange:
+		return nil, errors.New("give session_ids or from and to, not both")
+	case byRange && (c.From == nil || c.To == nil || c.To.Before(*c.From)):
+		return nil, errors.New("from and to must both be set, with from before to")
+	case len(c.SessionIDs) > MaxCorrectedSessions:
+		return nil, fmt.Errorf("at most %d sessions can be corrected at once", MaxCorrectedSessions)
+	}
+
+	if (c.OffsetHours != 0) == (c.Date != "") {
+		return nil, errors.New("give either offset_hours or date")
+	}
+	if c.OffsetHours != 0 {
+		if c.OffsetHours < -MaxCorrectionOffsetHours || c.OffsetHours > MaxCorrectionOffsetHours {
+			return nil, fmt.Errorf("offset_hours must be between -%d and %d", MaxCorrectionOffsetHours, MaxCorrectionOffsetHours)
+		}
+		offset := time.Duration(c.OffsetHours) * time.Hour
+		return func(t time.Time) time.Time { return t.Add(offset) }, nil
+	}
+
+	tz := c.TimeZone
+	if tz == "" {
+		tz = "UTC"
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, errors.New("timezone must be an IANA time zone such as Europe/London")
+	}
+	date, err := time.ParseInLocation("2006-01-02", c.Date, loc)
+	if err != nil {
+		return nil, errors.New("date must be YYYY-MM-DD")
+	}
+	return func(t time.Time) time.Time {
+		local := t.In(loc)
+		return time.Date(date.Year(), date.Month(), date.Day(), local.Hour(), local.Minute(), local.Second(), local.Nanosecond(), loc)
+	}, nil
+}
+
+// RetimedSession reports one corrected session
+type RetimedSession struct {
+	ID           string    `json:"id"`
+	OldStartedAt time.Time `json:"old_started_at"`
+	NewStartedAt time.Time `json:"new_started_at"`
+}
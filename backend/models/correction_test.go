@@ -0,0 +1,45 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSessionTimeCorrection_Validate(t *testing.T) {
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(7 * 24 * time.Hour)
+	started := time.Date(2026, 3, 2, 23, 30, 0, 0, time.UTC)
+
+	shift, err := SessionTimeCorrection{From: &from, To: &to, OffsetHours: -9}.Validate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := shift(started); !got.Equal(started.Add(-9 * time.Hour)) {
+		t.Errorf("offset shift = %v", got)
+	}
+
+	// 23:30 UTC is 08:30 the next morning in Tokyo; moving to the 5th keeps 08:30 local
+	shift, err = SessionTimeCorrection{SessionIDs: []string{"s1"}, Date: "2026-03-05", TimeZone: "Asia/Tokyo"}.Validate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := shift(started), time.Date(2026, 3, 4, 23, 30, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("date shift = %v, want %v", got.UTC(), want)
+	}
+
+	for name, c := range map[string]SessionTimeCorrection{
+		"no selection":    {OffsetHours: 1},
+		"both selections": {SessionIDs: []string{"s1"}, From: &from, To: &to, OffsetHours: 1},
+		"open range":      {From: &from, OffsetHours: 1},
+		"reversed range":  {From: &to, To: &from, OffsetHours: 1},
+		"no change":       {SessionIDs: []string{"s1"}},
+		"both changes":    {SessionIDs: []string{"s1"}, OffsetHours: 1, Date: "2026-03-05"},
+		"offset too big":  {SessionIDs: []string{"s1"}, OffsetHours: MaxCorrectionOffsetHours + 1},
+		"bad date":        {SessionIDs: []string{"s1"}, Date: "05/03/2026"},
+		"bad timezone":    {SessionIDs: []string{"s1"}, Date: "2026-03-05", TimeZone: "Mars/Olympus"},
+	} {
+		if _, err := c.Validate(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// DeprecationNotice describes a route scheduled for removal
+type DeprecationNotice struct {
+	Method      string    `json:"method"`
+	Path        string    `json:"path"`
+	Since       time.Time `json:"since"`
+	Sunset      time.Time `json:"sunset"`
+	Link        string    `json:"link,omitempty"`
+	Replacement string    `json:"replacement,omitempty"`
+}
+
+// DeprecatedUsage aggregates calls to a deprecated route by one client token
+type DeprecatedUsage struct {
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	UserID    string    `json:"user_id"`
+	UserEmail string    `json:"user_email,omitempty"`
+	TokenID   string    `json:"token_id"`
+	UserAgent string    `json:"user_agent"`
+	Count     int64     `json:"count"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// DeprecationReport lists a deprecated route and the clients still calling it
+type DeprecationReport struct {
+	DeprecationNotice
+	Usage []*DeprecatedUsage `json:"usage"`
+}
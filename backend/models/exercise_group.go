@@ -0,0 +1,37 @@
+package models
+
+// Exercise group types
+const (
+	ExerciseGroupSuperset = "superset" // exercises alternated set by set
+	ExerciseGroupCircuit  = "circuit"  // exercises done one after another, then repeated
+)
+
+// ValidExerciseGroupType reports whether t is a known exercise group type
+func ValidExerciseGroupType(t string) bool {
+	return t == ExerciseGroupSuperset || t == ExerciseGroupCircuit
+}
+
+// ContiguousGroups returns exercises in the same order, except that the members
+// of each group are pulled up to where the group's first member appears, in
+// their relative order
+func ContiguousGroups(exercises []*Exercise) []*Exercise {
+	members := make(map[string][]*Exercise)
+	for _, e := range exercises {
+		if e.GroupID != "" {
+			members[e.GroupID] = append(members[e.GroupID], e)
+		}
+	}
+	ordered := make([]*Exercise, 0, len(exercises))
+	placed := make(map[string]bool)
+	for _, e := range exercises {
+		if e.GroupID == "" {
+			ordered = append(ordered, e)
+			continue
+		}
+		if !placed[e.GroupID] {
+			placed[e.GroupID] = true
+			ordered = append(ordered, members[e.GroupID]...)
+		}
+	}
+	return ordered
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestContiguousGroups(t *testing.T) {
+	var exercises []*Exercise
+	for _, spec := range []string{"a", "b:g1", "c", "d:g2", "e:g1", "f:g2"} {
+		id, group, _ := strings.Cut(spec, ":")
+		exercises = append(exercises, &Exercise{ID: id, GroupID: group})
+	}
+
+	var got []string
+	for _, e := range ContiguousGroups(exercises) {
+		got = append(got, e.ID)
+	}
+	if strings.Join(got, "") != "abecdf" {
+		t.Errorf("order = %v, want a b e c d f", got)
+	}
+}
@@ -0,0 +1,250 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Muscles an exercise template can list as primary or secondary
+var Muscles = []string{
+	"chest", "back", "shoulders", "biceps", "triceps", "forearms",
+	"core", "quadriceps", "hamstrings", "glutes", "calves",
+}
+
+// Equipment an exercise template can require. Bodyweight exercises require none.
+var Equipment = []string{
+	"barbell", "dumbbell", "kettlebell", "cable", "machine", "bench",
+	"pull_up_bar", "dip_bars", "band", "jump_rope", "bike",
+}
+
+// MaxInstructionsLength caps an exercise template's instructions
+const MaxInstructionsLength = 4000
+
+// StringList is a list of names, stored as a JSON array
+type StringList []string
+
+// Scan implements sql.Scanner for a JSON array column (NULL scans as empty)
+func (l *StringList) Scan(src interface{}) error {
+	*l = nil
+	var raw []byte
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case string:
+		raw = []byte(v)
+	case []byte:
+		raw = v
+	default:
+		return fmt.Errorf("unsupported string list type %T", src)
+	}
+	if len(raw) == 0 {
+		return nil
+	}
+	return json.Unmarshal(raw, l)
+}
+
+// Value implements driver.Valuer, storing an empty list as NULL
+func (l StringList) Value() (driver.Value, error) {
+	if len(l) == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Contains reports whether name is in the list
+func (l StringList) Contains(name string) bool {
+	for _, n := range l {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ExerciseMetadata describes how an exercise template is performed
+type ExerciseMetadata struct {
+	PrimaryMuscles   StringList `json:"primary_muscles,omitempty" db:"primary_muscles"`
+	SecondaryMuscles StringList `json:"secondary_muscles,omitempty" db:"secondary_muscles"`
+	Equipment        StringList `json:"equipment,omitempty" db:"equipment"`
+	Instructions     string     `json:"instructions,omitempty" db:"instructions"`
+}
+
+// Validate lower-cases, sorts and de-duplicates the lists, checking them against
+// Muscles and Equipment. A muscle is either primary or secondary, not both.
+func (m *ExerciseMetadata) Validate() error {
+	var err error
+	if m.PrimaryMuscles, err = normalizeNames(m.PrimaryMuscles, Muscles, "primary_muscles"); err != nil {
+		return err
+	}
+	if m.SecondaryMuscles, err = normalizeNames(m.SecondaryMuscles, Muscles, "secondary_muscles"); err != nil {
+		return err
+	}
+	for _, muscle := range m.SecondaryMuscles {
+		if m.PrimaryMuscles.Contains(muscle) {
+			return fmt.Errorf("%s cannot be both a primary and a secondary muscle", muscle)
+		}
+	}
+	if m.Equipment, err = normalizeNames(m.Equipment, Equipment, "equipment"); err != nil {
+		return err
+	}
+	m.Instructions = strings.TrimSpace(m.Instructions)
+	if len(m.Instructions) > MaxInstructionsLength {
+		return fmt.Errorf("instructions must be at most %d characters", MaxInstructionsLength)
+	}
+	return nil
+}
+
+// Works reports whether the exercise trains muscle, as a primary or secondary muscle
+func (m ExerciseMetadata) Works(muscle string) bool {
+	return m.PrimaryMuscles.Contains(muscle) || m.SecondaryMuscles.Contains(muscle)
+}
+
+// ValidMuscle reports whether muscle is one of Muscles
+func ValidMuscle(muscle string) bool {
+	return StringList(Muscles).Contains(muscle)
+}
+
+// ValidEquipment reports whether equipment is one of Equipment
+func ValidEquipment(equipment string) bool {
+	return StringList(Equipment).Contains(equipment)
+}
+
+func normalizeNames(names StringList, allowed []string, field string) (StringList, error) {
+	seen := map[string]bool{}
+	var out StringList
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !StringList(allowed).Contains(name) {
+			return nil, fmt.Errorf("%s must be among %s", field, strings.Join(allowed, ", "))
+		}
+		if !seen[name] {
+			seen[name] = true
+			out = append(out, name)
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// DefaultExerciseLibraryMetadata describes each built-in library exercise
+var DefaultExerciseLibraryMetadata = map[string]ExerciseMetadata{
+	"Barbell Bench Press": {
+		PrimaryMuscles: StringList{"chest"}, SecondaryMuscles: StringList{"shoulders", "triceps"}, Equipment: StringList{"barbell", "bench"},
+		Instructions: "Lie on the bench with eyes under the bar. Lower the bar to the middle of the chest with elbows about 45 degrees from the body, then press it back up over the shoulders.",
+	},
+	"Dumbbell Bench Press": {
+		PrimaryMuscles: StringList{"chest"}, SecondaryMuscles: StringList{"shoulders", "triceps"}, Equipment: StringList{"bench", "dumbbell"},
+		Instructions: "Lie on the bench with a dumbbell in each hand above the chest. Lower them to chest level, then press them back up until the arms are straight.",
+	},
+	"Incline Dumbbell Press": {
+		PrimaryMuscles: StringList{"chest"}, SecondaryMuscles: StringList{"shoulders", "triceps"}, Equipment: StringList{"bench", "dumbbell"},
+		Instructions: "Set the bench to 30-45 degrees. Lower the dumbbells to the upper chest, then press them up and slightly together.",
+	},
+	"Push-ups": {
+		PrimaryMuscles: StringList{"chest"}, SecondaryMuscles: StringList{"core", "shoulders", "triceps"},
+		Instructions: "Start in a plank with hands just wider than the shoulders. Lower the chest to the floor keeping the body straight, then push back up.",
+	},
+	"Pull-ups": {
+		PrimaryMuscles: StringList{"back"}, SecondaryMuscles: StringList{"biceps", "forearms"}, Equipment: StringList{"pull_up_bar"},
+		Instructions: "Hang from the bar with an overhand grip just wider than the shoulders. Pull until the chin clears the bar, then lower under control to straight arms.",
+	},
+	"Barbell Rows": {
+		PrimaryMuscles: StringList{"back"}, SecondaryMuscles: StringList{"biceps", "core", "hamstrings"}, Equipment: StringList{"barbell"},
+		Instructions: "Hinge forward with a flat back and the bar hanging at arm's length. Row it to the lower ribs, then lower it under control.",
+	},
+	"Dumbbell Rows": {
+		PrimaryMuscles: StringList{"back"}, SecondaryMuscles: StringList{"biceps"}, Equipment: StringList{"bench", "dumbbell"},
+		Instructions: "Brace one hand and knee on the bench. Row the dumbbell towards the hip, then lower it until the arm is straight. Repeat on the other side.",
+	},
+	"Lat Pulldowns": {
+		PrimaryMuscles: StringList{"back"}, SecondaryMuscles: StringList{"biceps"}, Equipment: StringList{"cable", "machine"},
+		Instructions: "Grip the bar just wider than the shoulders. Pull it to the upper chest while leaning back slightly, then let it rise until the arms are straight.",
+	},
+	"Overhead Press": {
+		PrimaryMuscles: StringList{"shoulders"}, SecondaryMuscles: StringList{"core", "triceps"}, Equipment: StringList{"barbell"},
+		Instructions: "Stand with the bar on the front of the shoulders. Press it straight overhead, moving the head back out of the way, until the arms lock out.",
+	},
+	"Dumbbell Shoulder Press": {
+		PrimaryMuscles: StringList{"shoulders"}, SecondaryMuscles: StringList{"triceps"}, Equipment: StringList{"dumbbell"},
+		Instructions: "Hold the dumbbells at shoulder height, palms forward. Press them overhead until the arms are straight, then lower them back to the shoulders.",
+	},
+	"Lateral Raises": {
+		PrimaryMuscles: StringList{"shoulders"}, Equipment: StringList{"dumbbell"},
+		Instructions: "With a slight bend in the elbows, raise the dumbbells out to the sides to shoulder height, then lower them slowly.",
+	},
+	"Front Raises": {
+		PrimaryMuscles: StringList{"shoulders"}, Equipment: StringList{"dumbbell"},
+		Instructions: "Raise the dumbbells in front of you to shoulder height with straight arms, then lower them slowly.",
+	},
+	"Bicep Curls": {
+		PrimaryMuscles: StringList{"biceps"}, SecondaryMuscles: StringList{"forearms"}, Equipment: StringList{"dumbbell"},
+		Instructions: "Keep the elbows at your sides and curl the dumbbells up, palms facing up, then lower them until the arms are straight.",
+	},
+	"Hammer Curls": {
+		PrimaryMuscles: StringList{"biceps"}, SecondaryMuscles: StringList{"forearms"}, Equipment: StringList{"dumbbell"},
+		Instructions: "Curl the dumbbells with the palms facing each other and the elbows at your sides, then lower them under control.",
+	},
+	"Tricep Pushdowns": {
+		PrimaryMuscles: StringList{"triceps"}, Equipment: StringList{"cable"},
+		Instructions: "Keep the elbows at your sides and push the handle down until the arms are straight, then let it rise to about chest height.",
+	},
+	"Tricep Dips": {
+		PrimaryMuscles: StringList{"triceps"}, SecondaryMuscles: StringList{"chest", "shoulders"}, Equipment: StringList{"dip_bars"},
+		Instructions: "Support yourself on straight arms. Lower until the upper arms are about parallel to the floor, keeping the torso upright, then press back up.",
+	},
+	"Barbell Squats": {
+		PrimaryMuscles: StringList{"quadriceps"}, SecondaryMuscles: StringList{"core", "glutes", "hamstrings"}, Equipment: StringList{"barbell"},
+		Instructions: "With the bar across the upper back, sit down between the heels until the hips are below the knees, keeping the chest up, then stand back up.",
+	},
+	"Deadlifts": {
+		PrimaryMuscles: StringList{"glutes", "hamstrings"}, SecondaryMuscles: StringList{"back", "core", "forearms"}, Equipment: StringList{"barbell"},
+		Instructions: "Stand with the bar over mid-foot. Hinge to grip it, flatten the back, then push the floor away and stand tall. Lower it along the same path.",
+	},
+	"Leg Press": {
+		PrimaryMuscles: StringList{"quadriceps"}, SecondaryMuscles: StringList{"glutes", "hamstrings"}, Equipment: StringList{"machine"},
+		Instructions: "With feet shoulder-width on the platform, lower it until the knees reach about 90 degrees, then press it back without locking the knees.",
+	},
+	"Lunges": {
+		PrimaryMuscles: StringList{"quadriceps"}, SecondaryMuscles: StringList{"glutes", "hamstrings"},
+		Instructions: "Step forward and lower until both knees are bent about 90 degrees, then push back to standing. Alternate legs.",
+	},
+	"Plank": {
+		PrimaryMuscles: StringList{"core"}, SecondaryMuscles: StringList{"shoulders"},
+		Instructions: "Hold a straight line from head to heels on the forearms and toes, bracing the abs and squeezing the glutes.",
+	},
+	"Crunches": {
+		PrimaryMuscles: StringList{"core"},
+		Instructions:   "Lie on your back with knees bent. Curl the shoulders off the floor towards the hips, then lower them slowly.",
+	},
+	"Russian Twists": {
+		PrimaryMuscles: StringList{"core"},
+		Instructions:   "Sit leaning back with the feet raised. Rotate the torso from side to side, touching the floor beside each hip.",
+	},
+	"Leg Raises": {
+		PrimaryMuscles: StringList{"core"},
+		Instructions:   "Lie flat and raise straight legs until they point up, then lower them slowly without letting the lower back arch.",
+	},
+	"Running": {
+		PrimaryMuscles: StringList{"quadriceps"}, SecondaryMuscles: StringList{"calves", "glutes", "hamstrings"},
+		Instructions: "Run at a steady, conversational pace with short strides, landing under the hips.",
+	},
+	"Cycling": {
+		PrimaryMuscles: StringList{"quadriceps"}, SecondaryMuscles: StringList{"calves", "glutes", "hamstrings"}, Equipment: StringList{"bike"},
+		Instructions: "Set the saddle so the knee is slightly bent at the bottom of the stroke and ride at a steady cadence.",
+	},
+	"Jump Rope": {
+		PrimaryMuscles: StringList{"calves"}, SecondaryMuscles: StringList{"forearms", "shoulders"}, Equipment: StringList{"jump_rope"},
+		Instructions: "Turn the rope from the wrists and make small hops on the balls of the feet.",
+	},
+	"Burpees": {
+		PrimaryMuscles: StringList{"chest", "quadriceps"}, SecondaryMuscles: StringList{"core", "shoulders"},
+		Instructions: "Squat, place the hands down and jump the feet back into a plank. Do a push-up, jump the feet in and jump up.",
+	},
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExerciseMetadataValidate(t *testing.T) {
+	m := ExerciseMetadata{
+		PrimaryMuscles:   StringList{" Back", "back"},
+		SecondaryMuscles: StringList{"forearms", "biceps"},
+		Equipment:        StringList{"PULL_UP_BAR"},
+		Instructions:     "  Pull until the chin clears the bar ",
+	}
+	if err := m.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(m.PrimaryMuscles, ",") != "back" || strings.Join(m.SecondaryMuscles, ",") != "biceps,forearms" ||
+		strings.Join(m.Equipment, ",") != "pull_up_bar" || m.Instructions != "Pull until the chin clears the bar" {
+		t.Errorf("normalized = %+v", m)
+	}
+	if !m.Works("biceps") || m.Works("chest") {
+		t.Error("Works should check primary and secondary muscles")
+	}
+
+	for name, bad := range map[string]ExerciseMetadata{
+		"unknown muscle":        {PrimaryMuscles: StringList{"lats"}},
+		"primary and secondary": {PrimaryMuscles: StringList{"chest"}, SecondaryMuscles: StringList{"chest"}},
+		"unknown equipment":     {Equipment: StringList{"sandbag"}},
+		"long instructions":     {Instructions: strings.Repeat("x", MaxInstructionsLength+1)},
+	} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestDefaultExerciseLibraryMetadata(t *testing.T) {
+	for name := range DefaultExerciseLibraryCategories {
+		m, ok := DefaultExerciseLibraryMetadata[name]
+		if !ok {
+			t.Errorf("%s has no metadata", name)
+			continue
+		}
+		if err := m.Validate(); err != nil || len(m.PrimaryMuscles) == 0 || m.Instructions == "" {
+			t.Errorf("%s: metadata %+v, %v", name, m, err)
+		}
+	}
+}
@@ -0,0 +1,10 @@
+package models
+
+// ExportSet is an exercise set together with the session and exercise it belongs to,
+// as written to personal data exports
+type ExportSet struct {
+	SessionID    string `json:"session_id"`
+	ExerciseID   string `json:"exercise_id"`
+	ExerciseName string `json:"exercise_name"`
+	ExerciseSet
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Record kinds an admin can hard-delete
+const (
+	HardDeleteWorkout = "workout" // a workout with its exercises and every session of it
+	HardDeleteSession = "session" // one logged session with its sets
+)
+
+// ValidHardDeleteKind reports whether kind names a record that can be hard-deleted
+func ValidHardDeleteKind(kind string) bool {
+	return kind == HardDeleteWorkout || kind == HardDeleteSession
+}
+
+// HardDeleteConfirmation is the single-use token an admin sends back to carry
+// out a requested hard delete. Only its hash is stored.
+type HardDeleteConfirmation struct {
+	Token     string    `json:"confirm_token"`
+	UserID    string    `json:"user_id"`
+	Kind      string    `json:"kind"`
+	RecordID  string    `json:"record_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Injury is a user-logged injury; while active, its restrictions
+// produce warnings for exercises carrying matching risk flags
+type Injury struct {
+	ID           string     `json:"id" db:"id"`
+	UserID       string     `json:"-" db:"user_id"`
+	Name         string     `json:"name" db:"name"`
+	Restrictions []string   `json:"restrictions" db:"restrictions"`
+	Notes        string     `json:"notes" db:"notes"`
+	Active       bool       `json:"active" db:"active"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	ResolvedAt   *time.Time `json:"resolved_at" db:"resolved_at"`
+}
+
+// ExerciseWarning flags an exercise that conflicts with an active injury
+type ExerciseWarning struct {
+	ExerciseName string   `json:"exercise_name"`
+	InjuryID     string   `json:"injury_id"`
+	InjuryName   string   `json:"injury_name"`
+	RiskFlags    []string `json:"risk_flags"`
+	Message      string   `json:"message"`
+}
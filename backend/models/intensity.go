@@ -0,0 +1,49 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"strings"
+)
+
+// RPE (rate of perceived exertion) bounds: 10 is a maximal effort, 6 leaves
+// about four reps in reserve. Ratings go in half steps.
+const (
+	MinRPE = 1
+	MaxRPE = 10
+)
+
+// ValidRPE reports whether rpe is a rating from MinRPE to MaxRPE in steps of 0.5
+func ValidRPE(rpe float64) bool {
+	return rpe >= MinRPE && rpe <= MaxRPE && math.Mod(rpe*2, 1) == 0
+}
+
+// NormalizeTempo checks a lifting tempo of four phases (eccentric, bottom pause,
+// concentric, top pause), each a number of seconds or X for explosive, and
+// returns it as e.g. "3-1-X-0". "31X0" and "3-1-x-0" are both accepted. An
+// empty tempo means none was set.
+func NormalizeTempo(tempo string) (string, error) {
+	tempo = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(tempo), "-", ""))
+	if tempo == "" {
+		return "", nil
+	}
+	if len(tempo) != 4 {
+		return "", errors.New("tempo must have four phases, e.g. 3-1-X-0")
+	}
+	phases := make([]string, 4)
+	for i, r := range tempo {
+		if (r < '0' || r > '9') && r != 'X' {
+			return "", errors.New("tempo phases must be seconds (0-9) or X")
+		}
+		phases[i] = string(r)
+	}
+	return strings.Join(phases, "-"), nil
+}
+
+// ValidateRPE checks the set's actual RPE, if one was logged
+func (s *ExerciseSet) ValidateRPE() error {
+	if s.ActualRPE != nil && !ValidRPE(*s.ActualRPE) {
+		return errors.New("actual_rpe must be 1 to 10 in steps of 0.5")
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package models
+
+import "testing"
+
+func TestValidRPE(t *testing.T) {
+	for rpe, want := range map[float64]bool{
+		1: true, 7.5: true, 10: true,
+		0: false, 0.5: false, 8.25: false, 10.5: false,
+	} {
+		if got := ValidRPE(rpe); got != want {
+			t.Errorf("ValidRPE(%v) = %v, want %v", rpe, got, want)
+		}
+	}
+}
+
+func TestNormalizeTempo(t *testing.T) {
+	for in, want := range map[string]string{
+		"":         "",
+		"3-1-X-0":  "3-1-X-0",
+		"31x0":     "3-1-X-0",
+		" 2-0-1-0": "2-0-1-0",
+	} {
+		got, err := NormalizeTempo(in)
+		if err != nil || got != want {
+			t.Errorf("NormalizeTempo(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"3-1-1", "3-1-1-0-0", "3-1-Y-0", "slow"} {
+		if _, err := NormalizeTempo(in); err == nil {
+			t.Errorf("NormalizeTempo(%q) should fail", in)
+		}
+	}
+}
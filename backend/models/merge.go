@@ -0,0 +1,138 @@
+package models
+
+import (
+	"sort"
+	"strings"
+)
+
+// AccountMerge summarizes an account merged into another
+type AccountMerge struct {
+	UserID        string           `json:"user_id"`
+	MergedUserID  string           `json:"merged_user_id"`
+	MergedEmail   string           `json:"merged_email"`
+	Moved         map[string]int64 `json:"moved"`
+	EndedSessions int64            `json:"ended_sessions"`
+}
+
+// LinkedEmail is an email address tied to a user other than their login email,
+// such as the address reported by a sign-in provider
+type LinkedEmail struct {
+	UserID string
+	Email  string
+}
+
+// DuplicateAccountGroup is a set of accounts whose email addresses look like the same person
+type DuplicateAccountGroup struct {
+	MatchedOn []string `json:"matched_on"`
+	Users     []*User  `json:"users"`
+}
+
+// CanonicalEmail normalizes an address so trivially different spellings compare equal:
+// case and +tags are ignored everywhere, and Gmail also ignores dots and the googlemail domain
+func CanonicalEmail(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return email
+	}
+	local, domain := email[:at], email[at+1:]
+	if plus := strings.Index(local, "+"); plus > 0 {
+		local = local[:plus]
+	}
+	if domain == "gmail.com" || domain == "googlemail.com" {
+		local = strings.ReplaceAll(local, ".", "")
+		domain = "gmail.com"
+	}
+	return local + "@" + domain
+}
+
+// FindDuplicateAccounts groups users whose login or linked emails share a canonical form.
+// Groups are ordered by their oldest account; users within a group oldest first.
+func FindDuplicateAccounts(users []*User, linked []LinkedEmail) []DuplicateAccountGroup {
+	byID := make(map[string]*User, len(users))
+	owners := map[string]map[string]bool{}
+	addEmail := func(userID, email string) {
+		key := CanonicalEmail(email)
+		if key == "" {
+			return
+		}
+		if owners[key] == nil {
+			owners[key] = map[string]bool{}
+		}
+		owners[key][userID] = true
+	}
+	for _, u := range users {
+		byID[u.ID] = u
+		addEmail(u.ID, u.Email)
+	}
+	for _, l := range linked {
+		if byID[l.UserID] != nil {
+			addEmail(l.UserID, l.Email)
+		}
+	}
+
+	// Union users sharing any canonical email, so A~B and B~C form one group
+	parent := map[string]string{}
+	var find func(string) string
+	find = func(id string) string {
+		if p, ok := parent[id]; ok && p != id {
+			root := find(p)
+			parent[id] = root
+			return root
+		}
+		parent[id] = id
+		return id
+	}
+	for _, ids := range owners {
+		var first string
+		for id := range ids {
+			if first == "" {
+				first = id
+				continue
+			}
+			parent[find(id)] = find(first)
+		}
+	}
+
+	groups := map[string]*DuplicateAccountGroup{}
+	for key, ids := range owners {
+		if len(ids) < 2 {
+			continue
+		}
+		var member string
+		for id := range ids {
+			member = id
+			break
+		}
+		root := find(member)
+		if groups[root] == nil {
+			groups[root] = &DuplicateAccountGroup{}
+		}
+		groups[root].MatchedOn = append(groups[root].MatchedOn, key)
+	}
+	for id, u := range byID {
+		if g := groups[find(id)]; g != nil {
+			g.Users = append(g.Users, u)
+		}
+	}
+
+	result := make([]DuplicateAccountGroup, 0, len(groups))
+	for _, g := range groups {
+		sort.Strings(g.MatchedOn)
+		sort.Slice(g.Users, func(i, j int) bool {
+			if !g.Users[i].CreatedAt.Equal(g.Users[j].CreatedAt) {
+				return g.Users[i].CreatedAt.Before(g.Users[j].CreatedAt)
+			}
+			return g.Users[i].ID < g.Users[j].ID
+		})
+		result = append(result, *g)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i].Users[0], result[j].Users[0]
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	})
+	return result
+}
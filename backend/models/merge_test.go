@@ -0,0 +1,46 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCanonicalEmail(t *testing.T) {
+	cases := map[string]string{
+		"  Jo.Smith+gym@GoogleMail.com": "josmith@gmail.com",
+		"jo.smith+lifts@example.com":    "jo.smith@example.com",
+		"+tag@example.com":              "+tag@example.com",
+		"not-an-email":                  "not-an-email",
+	}
+	for in, want := range cases {
+		if got := CanonicalEmail(in); got != want {
+			t.Errorf("CanonicalEmail(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestFindDuplicateAccounts(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	users := []*User{
+		{ID: "a", Email: "jo.smith@gmail.com", CreatedAt: base.Add(2 * time.Hour)},
+		{ID: "b", Email: "josmith+app@gmail.com", CreatedAt: base},
+		{ID: "c", Email: "jo@work.example", CreatedAt: base.Add(time.Hour)},
+		{ID: "d", Email: "someone@else.example", CreatedAt: base},
+	}
+	linked := []LinkedEmail{
+		{UserID: "c", Email: "JoSmith@gmail.com"}, // Google identity on the work account
+		{UserID: "gone", Email: "someone@else.example"},
+	}
+
+	groups := FindDuplicateAccounts(users, linked)
+	if len(groups) != 1 {
+		t.Fatalf("got %d groups: %+v", len(groups), groups)
+	}
+	g := groups[0]
+	if len(g.MatchedOn) != 1 || g.MatchedOn[0] != "josmith@gmail.com" {
+		t.Errorf("matched on %v", g.MatchedOn)
+	}
+	if len(g.Users) != 3 || g.Users[0].ID != "b" || g.Users[1].ID != "c" || g.Users[2].ID != "a" {
+		t.Errorf("users = %v, %v, %v", g.Users[0].ID, g.Users[1].ID, g.Users[2].ID)
+	}
+}
@@ -0,0 +1,87 @@
+package models
+
+import "time"
+
+// Pace assumptions for rep-based sets; timed exercises use their own duration
+const (
+	DefaultSetSeconds  = 45
+	DefaultRestSeconds = 90
+)
+
+// SessionPace compares time spent in an active session with the time its
+// remaining sets are projected to take
+type SessionPace struct {
+	ElapsedSeconds            int   `json:"elapsed_seconds"`
+	CompletedSets             int   `json:"completed_sets"`
+	RemainingSets             int   `json:"remaining_sets"`
+	ProjectedRemainingSeconds int   `json:"projected_remaining_seconds"`
+	ProjectedTotalSeconds     int   `json:"projected_total_seconds"`
+	TargetSeconds             *int  `json:"target_seconds,omitempty"`
+	SlackSeconds              *int  `json:"slack_seconds,omitempty"`
+	OnTrack                   *bool `json:"on_track,omitempty"`
+}
+
+// NewSessionPace projects the finish time of a session at now. Planned sets
+// come from the workout, so warm-up sets are not counted. Each remaining set
+// costs DefaultSetSeconds (or the exercise's DurationSeconds when timed or
+// cardio) plus its RestSeconds, or DefaultRestSeconds when unset, before it.
+// Target fields are set only when the workout has a target duration.
+func NewSessionPace(session *WorkoutSession, now time.Time) *SessionPace {
+	if session == nil {
+		return nil
+	}
+	elapsed := now.Sub(session.StartedAt)
+	if session.EndedAt != nil {
+		elapsed = session.EndedAt.Sub(session.StartedAt)
+	}
+	if elapsed < 0 {
+		elapsed = 0
+	}
+
+	completedByExercise := map[string]int{}
+	completed := 0
+	for _, se := range session.Exercises {
+		for _, set := range se.Sets {
+			if set.Completed && !set.IsWarmup() {
+				completedByExercise[se.ExerciseID]++
+				completed++
+			}
+		}
+	}
+
+	remaining, remainingSeconds := 0, 0
+	if session.Workout != nil {
+		for _, ex := range session.Workout.Exercises {
+			if left := ex.Sets - completedByExercise[ex.ID]; left > 0 {
+				setSeconds := DefaultSetSeconds
+				if (ex.IsTimed() || ex.IsCardio()) && ex.DurationSeconds > 0 {
+					setSeconds = ex.DurationSeconds
+				}
+				restSeconds := DefaultRestSeconds
+				if ex.RestSeconds > 0 {
+					restSeconds = ex.RestSeconds
+				}
+				remaining += left
+				remainingSeconds += left * (setSeconds + restSeconds)
+			}
+		}
+	}
+
+	pace := &SessionPace{
+		ElapsedSeconds:            int(elapsed.Seconds()),
+		CompletedSets:             completed,
+		RemainingSets:             remaining,
+		ProjectedRemainingSeconds: remainingSeconds,
+	}
+	pace.ProjectedTotalSeconds = pace.ElapsedSeconds + pace.ProjectedRemainingSeconds
+
+	if session.Workout != nil && session.Workout.TargetDurationMinutes != nil {
+		target := *session.Workout.TargetDurationMinutes * 60
+		slack := target - pace.ProjectedTotalSeconds
+		onTrack := slack >= 0
+		pace.TargetSeconds = &target
+		pace.SlackSeconds = &slack
+		pace.OnTrack = &onTrack
+	}
+	return pace
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewSessionPace(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	target := 30
+	session := &WorkoutSession{
+		StartedAt: start,
+		Workout: &Workout{
+			TargetDurationMinutes: &target,
+			Exercises: []Exercise{
+				{ID: "squat", Sets: 3},
+				{ID: "press", Sets: 3},
+			},
+		},
+		Exercises: []*SessionExercise{
+			{ExerciseID: "squat", Sets: []*ExerciseSet{{Completed: true}, {Completed: true}, {Completed: false}}},
+		},
+	}
+
+	pace := NewSessionPace(session, start.Add(10*time.Minute))
+	if pace.ElapsedSeconds != 600 || pace.CompletedSets != 2 || pace.RemainingSets != 4 {
+		t.Fatalf("pace = %+v", pace)
+	}
+	perSet := DefaultSetSeconds + DefaultRestSeconds
+	if pace.ProjectedTotalSeconds != 600+4*perSet {
+		t.Errorf("projected total = %d", pace.ProjectedTotalSeconds)
+	}
+	if pace.OnTrack == nil || !*pace.OnTrack || *pace.SlackSeconds != 1800-600-4*perSet {
+		t.Errorf("target fields = %v / %v", pace.OnTrack, pace.SlackSeconds)
+	}
+
+	// Running over the goal flips on_track
+	pace = NewSessionPace(session, start.Add(25*time.Minute))
+	if *pace.OnTrack {
+		t.Errorf("expected to be behind pace: %+v", pace)
+	}
+
+	// Without a goal only the projection is reported
+	session.Workout.TargetDurationMinutes = nil
+	if pace = NewSessionPace(session, start); pace.TargetSeconds != nil || pace.OnTrack != nil {
+		t.Errorf("unexpected target fields: %+v", pace)
+	}
+}
+
+func TestNewSessionPace_TimedExercise(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	session := &WorkoutSession{
+		StartedAt: start,
+		Workout: &Workout{
+			Exercises: []Exercise{
+				{ID: "plank", Sets: 2, Mode: ExerciseModeDuration, DurationSeconds: 120},
+			},
+		},
+	}
+
+	pace := NewSessionPace(session, start)
+	if want := 2 * (120 + DefaultRestSeconds); pace.ProjectedRemainingSeconds != want {
+		t.Errorf("projected remaining = %d, want %d", pace.ProjectedRemainingSeconds, want)
+	}
+}
+
+func TestNewSessionPace_PlannedRest(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	session := &WorkoutSession{
+		StartedAt: start,
+		Workout: &Workout{
+			Exercises: []Exercise{
+				{ID: "squat", Sets: 3, Reps: 5, RestSeconds: 180},
+				{ID: "curl", Sets: 2, Reps: 12},
+			},
+		},
+	}
+
+	pace := NewSessionPace(session, start)
+	if want := 3*(DefaultSetSeconds+180) + 2*(DefaultSetSeconds+DefaultRestSeconds); pace.ProjectedRemainingSeconds != want {
+		t.Errorf("projected remaining = %d, want %d", pace.ProjectedRemainingSeconds, want)
+	}
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Partner session statuses
+const (
+	PartnerSessionInvited = "invited"
+	PartnerSessionJoined  = "joined"
+)
+
+// PartnerSession links a host's workout session with a training partner's. The
+// partner joins with their own session of the same workout and logs their own sets.
+type PartnerSession struct {
+	ID               string     `json:"id"`
+	HostID           string     `json:"-"`
+	HostEmail        string     `json:"host_email"`
+	HostSessionID    string     `json:"host_session_id"`
+	PartnerID        string     `json:"-"`
+	PartnerEmail     string     `json:"partner_email"`
+	PartnerSessionID *string    `json:"partner_session_id"` // nil until the partner joins
+	Status           string     `json:"status"`
+	CreatedAt        time.Time  `json:"created_at"`
+	JoinedAt         *time.Time `json:"joined_at"`
+}
+
+// SessionPartner is the other participant of a partner workout, as seen from one side
+type SessionPartner struct {
+	LinkID    string `json:"link_id"` // the PartnerSession
+	UserID    string `json:"-"`
+	Email     string `json:"email"`
+	SessionID string `json:"session_id"`
+}
+
+// Other returns the participant opposite userID, or nil if the link has not been joined
+func (p *PartnerSession) Other(userID string) *SessionPartner {
+	if p.Status != PartnerSessionJoined || p.PartnerSessionID == nil {
+		return nil
+	}
+	if userID == p.HostID {
+		return &SessionPartner{LinkID: p.ID, UserID: p.PartnerID, Email: p.PartnerEmail, SessionID: *p.PartnerSessionID}
+	}
+	return &SessionPartner{LinkID: p.ID, UserID: p.HostID, Email: p.HostEmail, SessionID: p.HostSessionID}
+}
@@ -0,0 +1,146 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Limits on the number of sessions a printable log sheet covers
+const (
+	DefaultPrintableSessions = 4
+	MaxPrintableSessions     = 12
+)
+
+// PrintableWorkout is a workout laid out as a paper log sheet: exercises grouped
+// by category, each with a blank grid of set rows by session columns
+type PrintableWorkout struct {
+	WorkoutID     string           `json:"workout_id"`
+	Name          string           `json:"name"`
+	Type          string           `json:"type,omitempty"`
+	TargetMinutes *int             `json:"target_duration_minutes,omitempty"`
+	Sessions      int              `json:"sessions"` // number of session columns in every grid
+	Groups        []PrintableGroup `json:"groups"`
+	GeneratedAt   time.Time        `json:"generated_at"`
+}
+
+// PrintableGroup is a run of exercises under one category heading. Category is
+// empty for exercises outside the library.
+type PrintableGroup struct {
+	Category  string              `json:"category"`
+	Exercises []PrintableExercise `json:"exercises"`
+}
+
+// PrintableExercise is one exercise's block on the sheet. Fields names what to
+// write in each cell, e.g. weight and reps, or seconds held.
+type PrintableExercise struct {
+	Number      int            `json:"number"` // position in the workout, from 1
+	Name        string         `json:"name"`
+	MuscleGroup string         `json:"muscle_group,omitempty"`
+	Target      string         `json:"target"` // e.g. "3 × 10 @ 60", "3 × 45s", "3 × 8 each side"
+	Fields      []string       `json:"fields"`
+	Rows        []PrintableRow `json:"rows"`
+	Notes       []string       `json:"notes,omitempty"` // set notes from the last completed session
+}
+
+// PrintableRow is one set of an exercise, with a blank cell per session column
+type PrintableRow struct {
+	Set   int             `json:"set"`
+	Cells []PrintableCell `json:"cells"`
+}
+
+// PrintableCell is a blank cell to be filled in by hand. Side is "left" or
+// "right" for unilateral exercises, which get a cell per side.
+type PrintableCell struct {
+	Session int    `json:"session"`
+	Side    string `json:"side,omitempty"`
+}
+
+// NewPrintableWorkout lays out w for the given number of sessions. classify returns
+// an exercise's category and muscle group names ("" when unknown), and notes maps
+// exercise IDs to notes to print under them. Groups keep the workout's order: a new
+// group starts whenever the category changes.
+func NewPrintableWorkout(w *Workout, sessions int, classify func(name string) (string, string), notes map[string][]string, now time.Time) *PrintableWorkout {
+	p := &PrintableWorkout{
+		WorkoutID:     w.ID,
+		Name:          w.Name,
+		Type:          w.Type,
+		TargetMinutes: w.TargetDurationMinutes,
+		Sessions:      sessions,
+		Groups:        []PrintableGroup{},
+		GeneratedAt:   now,
+	}
+	for i, e := range w.Exercises {
+		category, muscleGroup := classify(e.Name)
+		if n := len(p.Groups); n == 0 || p.Groups[n-1].Category != category {
+			p.Groups = append(p.Groups, PrintableGroup{Category: category})
+		}
+		group := &p.Groups[len(p.Groups)-1]
+		group.Exercises = append(group.Exercises, PrintableExercise{
+			Number:      i + 1,
+			Name:        e.Name,
+			MuscleGroup: muscleGroup,
+			Target:      printableTarget(e),
+			Fields:      printableFields(e),
+			Rows:        printableRows(e, sessions),
+			Notes:       notes[e.ID],
+		})
+	}
+	return p
+}
+
+func printableTarget(e Exercise) string {
+	sets := e.Sets
+	if sets < 1 {
+		sets = 1
+	}
+	var target string
+	if e.IsTimed() {
+		target = fmt.Sprintf("%d × %ds", sets, e.DurationSeconds)
+	} else if e.IsCardio() {
+		target = fmt.Sprintf("%d × %s", sets, FormatCardio(e.DurationSeconds, e.DistanceMeters))
+	} else {
+		target = fmt.Sprintf("%d × %s", sets, e.RepTarget())
+		if e.Weight > 0 {
+			target += " @ " + strconv.FormatFloat(e.Weight, 'f', -1, 64)
+		}
+	}
+	if e.Unilateral {
+		target += " each side"
+	}
+	return target
+}
+
+func printableFields(e Exercise) []string {
+	if e.IsTimed() {
+		return []string{"seconds"}
+	}
+	if e.IsCardio() {
+		return []string{"time", "distance"}
+	}
+	if e.Weight > 0 {
+		return []string{"weight", "reps"}
+	}
+	return []string{"reps"}
+}
+
+func printableRows(e Exercise, sessions int) []PrintableRow {
+	sets := e.Sets
+	if sets < 1 {
+		sets = 1
+	}
+	sides := []string{""}
+	if e.Unilateral {
+		sides = []string{"left", "right"}
+	}
+	rows := make([]PrintableRow, sets)
+	for s := range rows {
+		rows[s] = PrintableRow{Set: s + 1, Cells: make([]PrintableCell, 0, sessions*len(sides))}
+		for session := 1; session <= sessions; session++ {
+			for _, side := range sides {
+				rows[s].Cells = append(rows[s].Cells, PrintableCell{Session: session, Side: side})
+			}
+		}
+	}
+	return rows
+}
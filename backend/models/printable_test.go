@@ -0,0 +1,42 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewPrintableWorkout(t *testing.T) {
+	w := &Workout{ID: "w1", Name: "Push", Exercises: []Exercise{
+		{ID: "e1", Name: "Bench", Sets: 3, Reps: 8, Weight: 62.5},
+		{ID: "e2", Name: "Flyes", Sets: 2, Reps: 12},
+		{ID: "e3", Name: "Plank", Sets: 2, Mode: ExerciseModeDuration, DurationSeconds: 45},
+		{ID: "e4", Name: "Single-arm Press", Sets: 1, Reps: 10, Weight: 20, Unilateral: true},
+	}}
+	categories := map[string]string{"Bench": "Chest", "Flyes": "Chest", "Plank": "Core", "Single-arm Press": "Chest"}
+	classify := func(name string) (string, string) { return categories[name], "" }
+	notes := map[string][]string{"e1": {"felt heavy"}}
+
+	p := NewPrintableWorkout(w, 3, classify, notes, time.Now())
+
+	// Chest, Core, then Chest again: groups follow the workout order
+	if len(p.Groups) != 3 || p.Groups[0].Category != "Chest" || p.Groups[1].Category != "Core" || p.Groups[2].Category != "Chest" {
+		t.Fatalf("groups = %+v", p.Groups)
+	}
+	bench := p.Groups[0].Exercises[0]
+	if bench.Target != "3 × 8 @ 62.5" || len(bench.Fields) != 2 || len(bench.Rows) != 3 || len(bench.Rows[0].Cells) != 3 {
+		t.Errorf("bench = %+v", bench)
+	}
+	if len(bench.Notes) != 1 || bench.Notes[0] != "felt heavy" {
+		t.Errorf("bench notes = %v", bench.Notes)
+	}
+	if flyes := p.Groups[0].Exercises[1]; flyes.Target != "2 × 12" || len(flyes.Fields) != 1 || flyes.Number != 2 {
+		t.Errorf("flyes = %+v", flyes)
+	}
+	if plank := p.Groups[1].Exercises[0]; plank.Target != "2 × 45s" || plank.Fields[0] != "seconds" {
+		t.Errorf("plank = %+v", plank)
+	}
+	press := p.Groups[2].Exercises[0]
+	if press.Target != "1 × 10 @ 20 each side" || len(press.Rows[0].Cells) != 6 || press.Rows[0].Cells[1].Side != "right" || press.Rows[0].Cells[2].Session != 2 {
+		t.Errorf("press = %+v", press)
+	}
+}
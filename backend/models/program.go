@@ -0,0 +1,143 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Limits on a training program
+const (
+	MaxProgramWeeks = 16
+	MaxProgramDays  = 7 // workouts per week
+)
+
+// ProgramWeightRoundTo is the plate jump progressed weights are rounded to
+const ProgramWeightRoundTo = 2.5
+
+// Program enrollment statuses
+const (
+	EnrollmentActive    = "active"
+	EnrollmentCompleted = "completed" // the last week was advanced past
+	EnrollmentLeft      = "left"      // left early, or replaced by another enrollment
+)
+
+// Program is a multi-week training plan: the same workouts every week, with
+// each week's progression applied on top of what the workouts prescribe
+type Program struct {
+	ID          string            `json:"id" db:"id"`
+	UserID      string            `json:"-" db:"user_id"`
+	Name        string            `json:"name" db:"name"`
+	Description string            `json:"description" db:"description"`
+	Workouts    []*ProgramWorkout `json:"workouts" db:"-"` // one per training day, in order
+	Weeks       []*ProgramWeek    `json:"weeks" db:"-"`
+	CreatedAt   time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// ProgramWorkout is one training day of a program's week
+type ProgramWorkout struct {
+	Day         int    `json:"day" db:"day"` // 1-based
+	WorkoutID   string `json:"workout_id" db:"workout_id"`
+	WorkoutName string `json:"workout_name" db:"-"`
+}
+
+// ProgramWeek is one week's progression: working weights at WeightPercent of
+// the workout's, and sets and reps shifted by SetsDelta and RepsDelta
+type ProgramWeek struct {
+	Week          int     `json:"week" db:"week"` // 1-based
+	WeightPercent float64 `json:"weight_percent" db:"weight_percent"`
+	SetsDelta     int     `json:"sets_delta" db:"sets_delta"`
+	RepsDelta     int     `json:"reps_delta" db:"reps_delta"`
+	Notes         string  `json:"notes" db:"notes"`
+}
+
+// ProgramEnrollment tracks a user's place in a program: the week they are on
+// and how many of its workouts they have started
+type ProgramEnrollment struct {
+	ID          string     `json:"id" db:"id"`
+	UserID      string     `json:"-" db:"user_id"`
+	ProgramID   string     `json:"program_id" db:"program_id"`
+	ProgramName string     `json:"program_name" db:"-"`
+	Week        int        `json:"week" db:"week"`
+	DaysDone    int        `json:"days_done" db:"days_done"`
+	Status      string     `json:"status" db:"status"`
+	StartedAt   time.Time  `json:"started_at" db:"started_at"`
+	CompletedAt *time.Time `json:"completed_at" db:"completed_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// ProgramToday is what an enrolled user trains next
+type ProgramToday struct {
+	Enrollment   *ProgramEnrollment `json:"enrollment"`
+	Week         *ProgramWeek       `json:"week"`
+	WeeksTotal   int                `json:"weeks_total"`
+	Day          int                `json:"day"`     // 1-based; 0 once the week's workouts are done
+	Workout      *Workout           `json:"workout"` // with the week's progression applied, nil once the week's workouts are done
+	WeekComplete bool               `json:"week_complete"`
+}
+
+// Validate checks a program before it is saved, numbering its days and weeks
+// and defaulting each week's weight to 100%
+func (p *Program) Validate() error {
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" || len(p.Name) > 255 {
+		return errors.New("name must be 1 to 255 characters")
+	}
+	if len(p.Workouts) < 1 || len(p.Workouts) > MaxProgramDays {
+		return fmt.Errorf("a program needs 1 to %d workouts per week", MaxProgramDays)
+	}
+	for i, w := range p.Workouts {
+		if w == nil || w.WorkoutID == "" {
+			return errors.New("every program workout needs a workout_id")
+		}
+		w.Day = i + 1
+	}
+	if len(p.Weeks) < 1 || len(p.Weeks) > MaxProgramWeeks {
+		return fmt.Errorf("a program runs 1 to %d weeks", MaxProgramWeeks)
+	}
+	for i, w := range p.Weeks {
+		if w == nil {
+			return errors.New("weeks cannot be null")
+		}
+		w.Week = i + 1
+		if w.WeightPercent == 0 {
+			w.WeightPercent = 100
+		}
+		if w.WeightPercent < 50 || w.WeightPercent > 150 {
+			return fmt.Errorf("week %d: weight_percent must be 50 to 150", w.Week)
+		}
+		if w.SetsDelta < -5 || w.SetsDelta > 5 || w.RepsDelta < -10 || w.RepsDelta > 10 {
+			return fmt.Errorf("week %d: sets_delta must be -5 to 5 and reps_delta -10 to 10", w.Week)
+		}
+		if len(w.Notes) > 1000 {
+			return fmt.Errorf("week %d: notes cannot exceed 1000 characters", w.Week)
+		}
+	}
+	return nil
+}
+
+// Apply returns the exercise with the week's progression applied. Sets never
+// drop below one; timed and cardio targets are kept as they are, and rep
+// ranges move with reps. Weights are rounded to ProgramWeightRoundTo.
+func (w *ProgramWeek) Apply(e Exercise) Exercise {
+	if w.SetsDelta != 0 {
+		e.Sets = max(1, e.Sets+w.SetsDelta)
+	}
+	if e.IsTimed() || e.IsCardio() {
+		return e
+	}
+	if w.RepsDelta != 0 && e.Reps > 0 {
+		e.Reps = max(1, e.Reps+w.RepsDelta)
+		if e.RepsMax > 0 {
+			e.RepsMin = max(1, e.RepsMin+w.RepsDelta)
+			e.RepsMax = max(e.RepsMin, e.RepsMax+w.RepsDelta)
+		}
+	}
+	if e.Weight > 0 && w.WeightPercent != 100 {
+		e.Weight = math.Round(e.Weight*w.WeightPercent/100/ProgramWeightRoundTo) * ProgramWeightRoundTo
+	}
+	return e
+}
@@ -0,0 +1,62 @@
+package models
+
+import "testing"
+
+func TestProgramWeekApply(t *testing.T) {
+	heavy := ProgramWeek{WeightPercent: 107.5, SetsDelta: 1, RepsDelta: -2}
+	squat := heavy.Apply(Exercise{Name: "Squat", Sets: 3, Reps: 5, Weight: 200})
+	if squat.Weight != 215 || squat.Sets != 4 || squat.Reps != 3 {
+		t.Errorf("squat = %+v", squat)
+	}
+
+	// Weights round to the nearest plate jump and ranges move with reps
+	row := heavy.Apply(Exercise{Name: "Row", Sets: 3, Reps: 8, RepsMin: 8, RepsMax: 12, Weight: 101})
+	if row.Weight != 107.5 || row.Reps != 6 || row.RepsMin != 6 || row.RepsMax != 10 {
+		t.Errorf("row = %+v", row)
+	}
+
+	deload := ProgramWeek{WeightPercent: 60, SetsDelta: -3, RepsDelta: -10}
+	if curl := deload.Apply(Exercise{Name: "Curl", Sets: 2, Reps: 3, Weight: 30}); curl.Sets != 1 || curl.Reps != 1 || curl.Weight != 17.5 {
+		t.Errorf("deload curl = %+v", curl)
+	}
+	if plank := heavy.Apply(Exercise{Name: "Plank", Sets: 3, Mode: ExerciseModeDuration, DurationSeconds: 60}); plank.DurationSeconds != 60 || plank.Sets != 4 {
+		t.Errorf("plank = %+v", plank)
+	}
+	if run := heavy.Apply(Exercise{Name: "Run", Sets: 1, Mode: ExerciseModeCardio, DistanceMeters: 5000}); run.DistanceMeters != 5000 || run.Sets != 2 {
+		t.Errorf("run = %+v", run)
+	}
+	same := ProgramWeek{WeightPercent: 100}
+	if e := same.Apply(Exercise{Name: "Press", Sets: 3, Reps: 5, Weight: 96.3}); e.Weight != 96.3 {
+		t.Errorf("unprogressed weight rounded: %+v", e)
+	}
+}
+
+func TestProgramValidate(t *testing.T) {
+	p := Program{
+		Name:     "  Linear 8  ",
+		Workouts: []*ProgramWorkout{{WorkoutID: "a"}, {WorkoutID: "b"}},
+		Weeks:    []*ProgramWeek{{}, {WeightPercent: 102.5}, {WeightPercent: 60, Notes: "Deload"}},
+	}
+	if err := p.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "Linear 8" || p.Workouts[1].Day != 2 || p.Weeks[0].Week != 1 || p.Weeks[0].WeightPercent != 100 || p.Weeks[2].Week != 3 {
+		t.Errorf("normalized = %+v", p)
+	}
+
+	for name, bad := range map[string]Program{
+		"no name":      {Workouts: p.Workouts, Weeks: p.Weeks},
+		"no workouts":  {Name: "P", Weeks: p.Weeks},
+		"no weeks":     {Name: "P", Workouts: p.Workouts},
+		"too long":     {Name: "P", Workouts: p.Workouts, Weeks: make([]*ProgramWeek, MaxProgramWeeks+1)},
+		"blank day":    {Name: "P", Workouts: []*ProgramWorkout{{}}, Weeks: p.Weeks},
+		"heavy week":   {Name: "P", Workouts: p.Workouts, Weeks: []*ProgramWeek{{WeightPercent: 200}}},
+		"extra sets":   {Name: "P", Workouts: p.Workouts, Weeks: []*ProgramWeek{{SetsDelta: 6}}},
+		"null week":    {Name: "P", Workouts: p.Workouts, Weeks: []*ProgramWeek{nil}},
+		"eight a week": {Name: "P", Workouts: make([]*ProgramWorkout, MaxProgramDays+1), Weeks: p.Weeks},
+	} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
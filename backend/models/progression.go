@@ -0,0 +1,73 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Progression outcomes for one exercise of an ended session
+const (
+	ProgressionIncreased = "increased" // every working set hit the target reps
+	ProgressionMissed    = "missed"    // a working set fell short; counts towards a deload
+	ProgressionDeloaded  = "deloaded"  // missed FailureLimit sessions in a row
+)
+
+// Defaults for progression rule fields left out
+const (
+	DefaultWeightIncrement = 5 // pounds
+	DefaultFailureLimit    = 2
+	DefaultDeloadPercent   = 10
+)
+
+// ProgressionRule adjusts an exercise's weight after each session it is
+// trained in: up by WeightIncrement when every working set reaches the target
+// reps (the top of a rep range), and down by about DeloadPercent, in whole
+// increments, after FailureLimit sessions in a row that fall short. The
+// workout's exercise is changed, so the next time the workout is trained,
+// scheduled or not, it starts from the new weight.
+type ProgressionRule struct {
+	ExerciseID      string    `json:"exercise_id" db:"exercise_id"`
+	UserID          string    `json:"-" db:"user_id"`
+	ExerciseName    string    `json:"exercise_name" db:"-"`
+	WorkoutID       string    `json:"workout_id" db:"-"`
+	WeightIncrement float64   `json:"weight_increment" db:"weight_increment"`
+	FailureLimit    int       `json:"failure_limit" db:"failure_limit"`
+	DeloadPercent   float64   `json:"deload_percent" db:"deload_percent"`
+	Failures        int       `json:"failures" db:"failures"` // sessions missed in a row so far
+	LastSessionID   string    `json:"-" db:"last_session_id"` // so a session is only counted once
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// ProgressionResult is what a rule did to an exercise when a session ended
+type ProgressionResult struct {
+	ExerciseID     string  `json:"exercise_id"`
+	ExerciseName   string  `json:"exercise_name"`
+	Outcome        string  `json:"outcome"`
+	PreviousWeight float64 `json:"previous_weight"`
+	Weight         float64 `json:"weight"`
+	Failures       int     `json:"failures"`
+}
+
+// Validate checks a rule before it is saved, filling in defaults for fields left at zero
+func (p *ProgressionRule) Validate() error {
+	if p.WeightIncrement == 0 {
+		p.WeightIncrement = DefaultWeightIncrement
+	}
+	if p.FailureLimit == 0 {
+		p.FailureLimit = DefaultFailureLimit
+	}
+	if p.DeloadPercent == 0 {
+		p.DeloadPercent = DefaultDeloadPercent
+	}
+	if p.WeightIncrement < 0 || p.WeightIncrement > 100 {
+		return errors.New("weight_increment must be positive and at most 100")
+	}
+	if p.FailureLimit < 1 || p.FailureLimit > 10 {
+		return errors.New("failure_limit must be 1 to 10")
+	}
+	if p.DeloadPercent < 0 || p.DeloadPercent > 50 {
+		return errors.New("deload_percent must be positive and at most 50")
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Recommendation kinds
+const (
+	RecommendationKindWorkout = "workout_template"
+	RecommendationKindRoutine = "routine_template"
+)
+
+// TemplateRecommendation is a scored suggestion of a workout or routine template
+type TemplateRecommendation struct {
+	TemplateID string    `json:"template_id" db:"template_id"`
+	Kind       string    `json:"kind" db:"kind"`
+	Name       string    `json:"name" db:"name"`
+	Score      float64   `json:"score" db:"score"`
+	Reasons    []string  `json:"reasons" db:"reasons"`
+	ComputedAt time.Time `json:"computed_at" db:"computed_at"`
+}
+
+// TrainingProfile summarises a user's recent training style
+type TrainingProfile struct {
+	SessionsPerWeek   float64            `json:"sessions_per_week"`
+	AvgSessionMinutes float64            `json:"avg_session_minutes"`
+	CategoryShare     map[string]float64 `json:"category_share"`
+	CompletedSessions int                `json:"completed_sessions"`
+	Level             string             `json:"level"` // template difficulty matching SessionsPerWeek
+}
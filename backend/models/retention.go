@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// RetentionWarning records that an inactive user was told their data will be deleted
+type RetentionWarning struct {
+	UserID       string    `json:"user_id"`
+	Email        string    `json:"email"`
+	LastActiveAt time.Time `json:"last_active_at"`
+	WarnedAt     time.Time `json:"warned_at"`
+	DeleteAfter  time.Time `json:"delete_after"`
+}
+
+// RetentionCandidate is a user the retention policy needs to look at
+type RetentionCandidate struct {
+	UserID       string
+	Email        string
+	Role         string
+	LastActiveAt time.Time
+	Warning      *RetentionWarning // nil until warned
+}
+
+// RetentionDeletion records an account deleted for inactivity. The email is not kept.
+type RetentionDeletion struct {
+	UserID       string    `json:"user_id"`
+	LastActiveAt time.Time `json:"last_active_at"`
+	DeletedAt    time.Time `json:"deleted_at"`
+}
+
+// RetentionReport is the admin view of pending warnings and recent deletions
+type RetentionReport struct {
+	InactiveDays int                  `json:"inactive_days"` // 0 when the policy is disabled
+	WarningDays  int                  `json:"warning_days"`
+	Warnings     []*RetentionWarning  `json:"warnings"`
+	Deletions    []*RetentionDeletion `json:"deletions"`
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Scheduled workout statuses
+const (
+	ScheduleStatusPlanned   = "planned"
+	ScheduleStatusCompleted = "completed"
+	ScheduleStatusSkipped   = "skipped"
+)
+
+// Layouts of a scheduled workout's date and optional time of day
+const (
+	ScheduleDateLayout = "2006-01-02"
+	ScheduleTimeLayout = "15:04"
+)
+
+// MaxScheduleRangeDays bounds the span of one GET /api/schedule query
+const MaxScheduleRangeDays = 366
+
+// ScheduledWorkout puts one of the user's workouts on their training calendar.
+// Date is a calendar day and Time an optional local time of day, both as the
+// client gave them, so the calendar does not shift with time zones.
+type ScheduledWorkout struct {
+	ID          string    `json:"id" db:"id"`
+	UserID      string    `json:"-" db:"user_id"`
+	WorkoutID   string    `json:"workout_id" db:"workout_id"`
+	WorkoutName string    `json:"workout_name" db:"-"`
+	Date        string    `json:"date" db:"date"`     // YYYY-MM-DD
+	Time        *string   `json:"time" db:"time"`     // HH:MM, nil for any time that day
+	Status      string    `json:"status" db:"status"` // planned, completed or skipped
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Validate checks the date, time and status, defaulting the status to planned
+// and treating an empty time as none
+func (s *ScheduledWorkout) Validate() error {
+	if _, err := time.Parse(ScheduleDateLayout, s.Date); err != nil {
+		return errors.New("date must be YYYY-MM-DD")
+	}
+	if s.Time != nil && *s.Time == "" {
+		s.Time = nil
+	}
+	if s.Time != nil {
+		if _, err := time.Parse(ScheduleTimeLayout, *s.Time); err != nil {
+			return errors.New("time must be HH:MM")
+		}
+	}
+	switch s.Status {
+	case "":
+		s.Status = ScheduleStatusPlanned
+	case ScheduleStatusPlanned, ScheduleStatusCompleted, ScheduleStatusSkipped:
+	default:
+		return errors.New("status must be planned, completed or skipped")
+	}
+	return nil
+}
+
+// ParseScheduleRange checks a from-to query of calendar days, inclusive
+func ParseScheduleRange(from, to string) error {
+	start, err := time.Parse(ScheduleDateLayout, from)
+	if err != nil {
+		return errors.New("from must be YYYY-MM-DD")
+	}
+	end, err := time.Parse(ScheduleDateLayout, to)
+	if err != nil {
+		return errors.New("to must be YYYY-MM-DD")
+	}
+	if end.Before(start) {
+		return errors.New("to cannot be before from")
+	}
+	if end.Sub(start) >= MaxScheduleRangeDays*24*time.Hour {
+		return fmt.Errorf("the range can span at most %d days", MaxScheduleRangeDays)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Set techniques that continue a set past its first segment
+const (
+	SetTechniqueDropSet   = "drop_set"   // weight reduced between segments
+	SetTechniqueRestPause = "rest_pause" // short rests between segments at the same weight
+)
+
+// MaxSetSegments caps how many extra segments one set may carry
+const MaxSetSegments = 10
+
+// SetSegment is one continuation of a drop or rest-pause set
+type SetSegment struct {
+	Weight float64 `json:"weight"`
+	Reps   int     `json:"reps"`
+}
+
+// SetSegments are the segments after a set's first weight/reps, in order.
+// Stored as JSON in exercise_sets.segments.
+type SetSegments []SetSegment
+
+// Volume is the weight x reps of all segments
+func (s SetSegments) Volume() float64 {
+	var v float64
+	for _, seg := range s {
+		v += seg.Weight * float64(seg.Reps)
+	}
+	return v
+}
+
+// Scan implements sql.Scanner for the JSON segments column (NULL scans as none)
+func (s *SetSegments) Scan(src interface{}) error {
+	*s = nil
+	var raw []byte
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case string:
+		raw = []byte(v)
+	case []byte:
+		raw = v
+	default:
+		return fmt.Errorf("unsupported set segments type %T", src)
+	}
+	if len(raw) == 0 {
+		return nil
+	}
+	return json.Unmarshal(raw, s)
+}
+
+// Value implements driver.Valuer, storing no segments as NULL
+func (s SetSegments) Value() (driver.Value, error) {
+	if len(s) == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Volume is the set's total weight x reps, including drop or rest-pause segments
+// and the right side of unilateral sets
+func (s *ExerciseSet) Volume() float64 {
+	return s.Weight*float64(s.Reps) + s.Segments.Volume() + s.SideVolume()
+}
+
+// SideVolume is the volume of a unilateral set's right side; the left side is
+// already counted through Reps and Weight
+func (s *ExerciseSet) SideVolume() float64 {
+	return s.Sides.Right.Weight * float64(s.Sides.Right.Reps)
+}
+
+// ValidateSegments checks the technique and segments of a set. Drop sets may
+// not add weight between segments; rest-pause segments stay at the set's weight.
+func (s *ExerciseSet) ValidateSegments() error {
+	if len(s.Segments) == 0 {
+		if s.Technique != "" {
+			return errors.New("segments are required when technique is set")
+		}
+		return nil
+	}
+	if len(s.Segments) > MaxSetSegments {
+		return fmt.Errorf("at most %d segments per set", MaxSetSegments)
+	}
+	prev := s.Weight
+	for i, seg := range s.Segments {
+		if seg.Reps < 1 || seg.Weight < 0 {
+			return fmt.Errorf("segment %d needs at least one rep and a non-negative weight", i+1)
+		}
+		switch s.Technique {
+		case SetTechniqueDropSet:
+			if seg.Weight > prev {
+				return fmt.Errorf("segment %d of a drop set is heavier than the one before it", i+1)
+			}
+		case SetTechniqueRestPause:
+			if seg.Weight != s.Weight {
+				return fmt.Errorf("segment %d of a rest-pause set changes the weight", i+1)
+			}
+		default:
+			return errors.New("technique must be drop_set or rest_pause")
+		}
+		prev = seg.Weight
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package models
+
+import "testing"
+
+func TestExerciseSetVolume(t *testing.T) {
+	set := &ExerciseSet{
+		Weight:    100,
+		Reps:      8,
+		Technique: SetTechniqueDropSet,
+		Segments:  SetSegments{{Weight: 80, Reps: 6}, {Weight: 60, Reps: 5}},
+	}
+	if got := set.Volume(); got != 800+480+300 {
+		t.Errorf("volume = %v", got)
+	}
+	if err := set.ValidateSegments(); err != nil {
+		t.Errorf("valid drop set rejected: %v", err)
+	}
+}
+
+func TestValidateSegments(t *testing.T) {
+	tests := []struct {
+		name string
+		set  ExerciseSet
+		ok   bool
+	}{
+		{"plain set", ExerciseSet{Weight: 100, Reps: 5}, true},
+		{"technique without segments", ExerciseSet{Weight: 100, Reps: 5, Technique: SetTechniqueDropSet}, false},
+		{"segments without technique", ExerciseSet{Weight: 100, Reps: 5, Segments: SetSegments{{Weight: 80, Reps: 5}}}, false},
+		{"drop set gets heavier", ExerciseSet{Weight: 100, Reps: 5, Technique: SetTechniqueDropSet, Segments: SetSegments{{Weight: 110, Reps: 3}}}, false},
+		{"rest-pause", ExerciseSet{Weight: 100, Reps: 8, Technique: SetTechniqueRestPause, Segments: SetSegments{{Weight: 100, Reps: 3}, {Weight: 100, Reps: 2}}}, true},
+		{"rest-pause changes weight", ExerciseSet{Weight: 100, Reps: 8, Technique: SetTechniqueRestPause, Segments: SetSegments{{Weight: 90, Reps: 3}}}, false},
+		{"zero reps", ExerciseSet{Weight: 100, Reps: 8, Technique: SetTechniqueDropSet, Segments: SetSegments{{Weight: 80, Reps: 0}}}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.set.ValidateSegments(); (err == nil) != tt.ok {
+			t.Errorf("%s: err = %v", tt.name, err)
+		}
+	}
+}
+
+func TestSetSegmentsScanValue(t *testing.T) {
+	in := SetSegments{{Weight: 80, Reps: 6}}
+	v, err := in.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out SetSegments
+	if err := out.Scan(v); err != nil || len(out) != 1 || out[0] != in[0] {
+		t.Fatalf("round trip = %v, %v", out, err)
+	}
+	if v, _ := (SetSegments{}).Value(); v != nil {
+		t.Errorf("empty segments stored as %v", v)
+	}
+	if err := out.Scan(nil); err != nil || out != nil {
+		t.Errorf("NULL scan = %v, %v", out, err)
+	}
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"errors"
+	"strings"
+)
+
+// Set types, stored in exercise_sets.set_type
+const (
+	SetTypeNormal  = "normal"
+	SetTypeAMRAP   = "amrap"   // as many reps as possible at the given weight
+	SetTypeDrop    = "drop"    // weight reduced without rest; see SetTechniqueDropSet
+	SetTypeFailure = "failure" // taken to muscular failure
+	SetTypeWarmup  = "warmup"  // ramp-up set, left out of volume, records and pace
+)
+
+// ValidateSetType normalizes and checks the set's type. A drop_set technique
+// makes an untyped set a drop set and cannot be combined with another type;
+// warm-up sets take no technique. An empty type is left empty.
+func (s *ExerciseSet) ValidateSetType() error {
+	s.SetType = strings.ToLower(strings.TrimSpace(s.SetType))
+	switch s.SetType {
+	case "", SetTypeNormal, SetTypeAMRAP, SetTypeDrop, SetTypeFailure, SetTypeWarmup:
+	default:
+		return errors.New("set_type must be normal, amrap, drop, failure or warmup")
+	}
+	if s.Technique == SetTechniqueDropSet {
+		if s.SetType != "" && s.SetType != SetTypeDrop {
+			return errors.New("a drop_set technique needs set_type drop")
+		}
+		s.SetType = SetTypeDrop
+	}
+	if s.SetType == SetTypeWarmup && s.Technique != "" {
+		return errors.New("warm-up sets cannot use a technique")
+	}
+	return nil
+}
+
+// IsWarmup reports whether the set is a warm-up rather than a working set
+func (s *ExerciseSet) IsWarmup() bool {
+	return s.SetType == SetTypeWarmup
+}
@@ -0,0 +1,30 @@
+package models
+
+import "testing"
+
+func TestValidateSetType(t *testing.T) {
+	tests := []struct {
+		name string
+		set  ExerciseSet
+		want string
+		ok   bool
+	}{
+		{"untyped", ExerciseSet{}, "", true},
+		{"amrap", ExerciseSet{SetType: " AMRAP "}, SetTypeAMRAP, true},
+		{"drop technique implies drop", ExerciseSet{Technique: SetTechniqueDropSet}, SetTypeDrop, true},
+		{"drop technique with failure", ExerciseSet{SetType: SetTypeFailure, Technique: SetTechniqueDropSet}, "", false},
+		{"rest-pause to failure", ExerciseSet{SetType: SetTypeFailure, Technique: SetTechniqueRestPause}, SetTypeFailure, true},
+		{"warm-up with technique", ExerciseSet{SetType: SetTypeWarmup, Technique: SetTechniqueRestPause}, "", false},
+		{"unknown", ExerciseSet{SetType: "cluster"}, "", false},
+	}
+	for _, tt := range tests {
+		err := tt.set.ValidateSetType()
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: err = %v, want ok %v", tt.name, err, tt.ok)
+			continue
+		}
+		if tt.ok && tt.set.SetType != tt.want {
+			t.Errorf("%s: set_type = %q, want %q", tt.name, tt.set.SetType, tt.want)
+		}
+	}
+}
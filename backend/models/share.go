@@ -0,0 +1,120 @@
+package models
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// SharedWorkoutPrefix marks and versions a shared workout code
+const SharedWorkoutPrefix = "LFT1."
+
+// Limits on what a shared workout code may carry
+const (
+	MaxSharedWorkoutExercises = 50
+	maxSharedWorkoutBytes     = 16 << 10 // decompressed JSON
+)
+
+// SharedWorkout is the compact form of a workout carried in a QR code. Field
+// names are single letters to keep codes short enough to scan reliably.
+type SharedWorkout struct {
+	Name          string           `json:"n"`
+	TargetMinutes *int             `json:"t,omitempty"`
+	Exercises     []SharedExercise `json:"e"`
+}
+
+// SharedExercise is one exercise of a shared workout. Library is the exercise
+// library name it was matched to when shared, so the importer can map it back.
+type SharedExercise struct {
+	Name            string  `json:"n"`
+	Library         string  `json:"l,omitempty"`
+	Sets            int     `json:"s"`
+	Reps            int     `json:"r,omitempty"`
+	Weight          float64 `json:"w,omitempty"`
+	DurationSeconds int     `json:"d,omitempty"` // set for duration-mode exercises
+	DistanceMeters  int     `json:"m,omitempty"`
+	Cardio          bool    `json:"c,omitempty"` // duration and/or distance are a cardio target
+	Unilateral      bool    `json:"u,omitempty"`
+}
+
+// ExerciseMapping reports which library exercise an imported exercise was mapped to
+type ExerciseMapping struct {
+	Name    string `json:"name"`
+	Library string `json:"library,omitempty"` // empty when the exercise is not in the library
+}
+
+// Encode returns the workout as a URL-safe code: deflated JSON in base64 behind SharedWorkoutPrefix
+func (w SharedWorkout) Encode() (string, error) {
+	raw, err := json.Marshal(w)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	zw, err := flate.NewWriter(&buf, flate.BestCompression)
+	if err != nil {
+		return "", err
+	}
+	if _, err := zw.Write(raw); err != nil {
+		return "", err
+	}
+	if err := zw.Close(); err != nil {
+		return "", err
+	}
+	return SharedWorkoutPrefix + base64.RawURLEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// DecodeSharedWorkout parses and validates a code produced by SharedWorkout.Encode
+func DecodeSharedWorkout(code string) (*SharedWorkout, error) {
+	code = strings.TrimSpace(code)
+	if !strings.HasPrefix(code, SharedWorkoutPrefix) {
+		return nil, errors.New("not a Liftoff workout code")
+	}
+	compressed, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(code, SharedWorkoutPrefix))
+	if err != nil {
+		return nil, errors.New("workout code is corrupted")
+	}
+	raw, err := io.ReadAll(io.LimitReader(flate.NewReader(bytes.NewReader(compressed)), maxSharedWorkoutBytes+1))
+	if err != nil {
+		return nil, errors.New("workout code is corrupted")
+	}
+	if len(raw) > maxSharedWorkoutBytes {
+		return nil, errors.New("workout code is too large")
+	}
+	var w SharedWorkout
+	if err := json.Unmarshal(raw, &w); err != nil {
+		return nil, errors.New("workout code is corrupted")
+	}
+	if err := w.Validate(); err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// Validate checks a shared workout before it is imported
+func (w *SharedWorkout) Validate() error {
+	w.Name = strings.TrimSpace(w.Name)
+	if w.Name == "" || len(w.Name) > 100 {
+		return errors.New("workout name must be 1-100 characters")
+	}
+	if w.TargetMinutes != nil && (*w.TargetMinutes < 1 || *w.TargetMinutes > 600) {
+		return errors.New("target duration must be between 1 and 600 minutes")
+	}
+	if len(w.Exercises) > MaxSharedWorkoutExercises {
+		return fmt.Errorf("at most %d exercises can be shared", MaxSharedWorkoutExercises)
+	}
+	for i, e := range w.Exercises {
+		if strings.TrimSpace(e.Name) == "" || len(e.Name) > 100 {
+			return fmt.Errorf("exercise %d needs a name of 1-100 characters", i+1)
+		}
+		if e.Sets < 1 || e.Reps < 0 || e.Weight < 0 || e.DurationSeconds < 0 || e.DistanceMeters < 0 || e.DistanceMeters > MaxDistanceMeters ||
+			(e.Reps == 0 && e.DurationSeconds == 0 && e.DistanceMeters == 0) || (e.DistanceMeters > 0 && !e.Cardio) {
+			return fmt.Errorf("exercise %d has invalid sets, reps, weight, duration or distance", i+1)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSharedWorkoutRoundTrip(t *testing.T) {
+	minutes := 45
+	w := SharedWorkout{
+		Name:          "Push Day",
+		TargetMinutes: &minutes,
+		Exercises: []SharedExercise{
+			{Name: "bench press", Library: "Bench Press", Sets: 3, Reps: 8, Weight: 135},
+			{Name: "Plank", Library: "Plank", Sets: 3, DurationSeconds: 45},
+			{Name: "Single-arm Row", Sets: 3, Reps: 10, Weight: 40, Unilateral: true},
+		},
+	}
+	code, err := w.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(code, SharedWorkoutPrefix) || len(code) > 300 {
+		t.Errorf("code = %q (%d chars)", code, len(code))
+	}
+
+	got, err := DecodeSharedWorkout(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != w.Name || *got.TargetMinutes != 45 || len(got.Exercises) != 3 {
+		t.Fatalf("decoded = %+v", got)
+	}
+	for i := range w.Exercises {
+		if got.Exercises[i] != w.Exercises[i] {
+			t.Errorf("exercise %d = %+v, want %+v", i, got.Exercises[i], w.Exercises[i])
+		}
+	}
+}
+
+func TestDecodeSharedWorkoutRejects(t *testing.T) {
+	encode := func(w SharedWorkout) string {
+		code, err := w.Encode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return code
+	}
+	for name, code := range map[string]string{
+		"no prefix":  "hello",
+		"bad base64": SharedWorkoutPrefix + "!!!",
+		"not flate":  SharedWorkoutPrefix + "aGVsbG8",
+		"no name":    encode(SharedWorkout{Exercises: []SharedExercise{{Name: "Squat", Sets: 3, Reps: 5}}}),
+		"no reps":    encode(SharedWorkout{Name: "Legs", Exercises: []SharedExercise{{Name: "Squat", Sets: 3}}}),
+		"too big":    encode(SharedWorkout{Name: "Legs", Exercises: make([]SharedExercise, 500)}),
+	} {
+		if _, err := DecodeSharedWorkout(code); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
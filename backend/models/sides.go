@@ -0,0 +1,97 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SideResult is the work done on one side of a unilateral set
+type SideResult struct {
+	Weight float64 `json:"weight"`
+	Reps   int     `json:"reps"`
+}
+
+// SetSides holds left and right results logged in one unilateral set entry.
+// Stored as JSON in exercise_sets.sides.
+type SetSides struct {
+	Left  SideResult `json:"left"`
+	Right SideResult `json:"right"`
+}
+
+// IsZero reports whether no sides were logged
+func (s SetSides) IsZero() bool {
+	return s == SetSides{}
+}
+
+// Validate checks that both sides were logged
+func (s SetSides) Validate() error {
+	if s.Left.Reps < 1 || s.Right.Reps < 1 {
+		return errors.New("left and right need at least one rep each")
+	}
+	if s.Left.Weight < 0 || s.Right.Weight < 0 {
+		return errors.New("side weights cannot be negative")
+	}
+	return nil
+}
+
+// Scan implements sql.Scanner for the JSON sides column (NULL scans as zero)
+func (s *SetSides) Scan(src interface{}) error {
+	*s = SetSides{}
+	var raw []byte
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case string:
+		raw = []byte(v)
+	case []byte:
+		raw = v
+	default:
+		return fmt.Errorf("unsupported set sides type %T", src)
+	}
+	if len(raw) == 0 {
+		return nil
+	}
+	return json.Unmarshal(raw, s)
+}
+
+// Value implements driver.Valuer, storing no sides as NULL
+func (s SetSides) Value() (driver.Value, error) {
+	if s.IsZero() {
+		return nil, nil
+	}
+	b, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// SideSetEntry is a completed unilateral set, as read for imbalance analytics
+type SideSetEntry struct {
+	ExerciseName string
+	LoggedAt     time.Time
+	Sides        SetSides
+}
+
+// SideImbalance compares the best left and right sets of an exercise in one week.
+// Strength is estimated 1RM, or reps when none of the week's sets were weighted,
+// as Kind (RecordE1RM or RecordReps) says.
+type SideImbalance struct {
+	WeekStart    time.Time `json:"week_start"`
+	Kind         string    `json:"kind"`
+	Left         float64   `json:"left"`
+	Right        float64   `json:"right"`
+	Weaker       string    `json:"weaker,omitempty"` // "left" or "right"; empty when even
+	ImbalancePct float64   `json:"imbalance_pct"`
+}
+
+// ExerciseImbalance is the weekly left/right history of one unilateral exercise
+type ExerciseImbalance struct {
+	ExerciseName string          `json:"exercise_name"`
+	Weeks        []SideImbalance `json:"weeks"`
+	Latest       SideImbalance   `json:"latest"`
+	Flagged      bool            `json:"flagged"` // latest imbalance is above the threshold
+}
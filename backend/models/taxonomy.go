@@ -0,0 +1,125 @@
+package models
+
+import (
+	"sort"
+	"strings"
+)
+
+// ExerciseCategory is a node of the exercise taxonomy: a top-level category
+// ("Arms") or, when it has a parent, a muscle group within one ("Biceps")
+type ExerciseCategory struct {
+	ID       string              `json:"id"`
+	ParentID string              `json:"parent_id,omitempty"`
+	Name     string              `json:"name"`
+	Position int                 `json:"position"`
+	Children []*ExerciseCategory `json:"children,omitempty"`
+}
+
+// Taxonomy is the admin-managed category tree together with the category each
+// library exercise is filed under
+type Taxonomy struct {
+	Categories []*ExerciseCategory `json:"categories"` // top-level categories, each with its muscle groups
+
+	byID    map[string]*ExerciseCategory
+	library map[string]string // lower-cased exercise name -> category ID
+}
+
+// NewTaxonomy builds the tree from flat rows and the exercise library's category IDs
+func NewTaxonomy(rows []*ExerciseCategory, library map[string]string) *Taxonomy {
+	t := &Taxonomy{Categories: []*ExerciseCategory{}, byID: map[string]*ExerciseCategory{}, library: map[string]string{}}
+	for _, c := range rows {
+		c.Children = nil
+		t.byID[c.ID] = c
+	}
+	for _, c := range rows {
+		if parent := t.byID[c.ParentID]; parent != nil {
+			parent.Children = append(parent.Children, c)
+		} else {
+			t.Categories = append(t.Categories, c)
+		}
+	}
+	byPosition := func(list []*ExerciseCategory) {
+		sort.SliceStable(list, func(i, j int) bool {
+			if list[i].Position != list[j].Position {
+				return list[i].Position < list[j].Position
+			}
+			return list[i].Name < list[j].Name
+		})
+	}
+	byPosition(t.Categories)
+	for _, c := range t.Categories {
+		byPosition(c.Children)
+	}
+	for name, id := range library {
+		t.library[strings.ToLower(name)] = id
+	}
+	return t
+}
+
+// Category returns the node with id, or nil
+func (t *Taxonomy) Category(id string) *ExerciseCategory {
+	return t.byID[id]
+}
+
+// Classify returns the top-level category a library exercise is filed under and,
+// when it is filed under a muscle group, that group. Both are nil for exercises
+// outside the library.
+func (t *Taxonomy) Classify(exerciseName string) (category, muscleGroup *ExerciseCategory) {
+	node := t.byID[t.library[strings.ToLower(exerciseName)]]
+	if node == nil {
+		return nil, nil
+	}
+	if parent := t.byID[node.ParentID]; parent != nil {
+		return parent, node
+	}
+	return node, nil
+}
+
+// DefaultExerciseCategories seeds the taxonomy on first start. Muscle groups are
+// listed after their category.
+var DefaultExerciseCategories = []ExerciseCategory{
+	{ID: "chest", Name: "Chest", Position: 1},
+	{ID: "back", Name: "Back", Position: 2},
+	{ID: "shoulders", Name: "Shoulders", Position: 3},
+	{ID: "arms", Name: "Arms", Position: 4},
+	{ID: "biceps", ParentID: "arms", Name: "Biceps", Position: 1},
+	{ID: "triceps", ParentID: "arms", Name: "Triceps", Position: 2},
+	{ID: "legs", Name: "Legs", Position: 5},
+	{ID: "quadriceps", ParentID: "legs", Name: "Quadriceps", Position: 1},
+	{ID: "posterior-chain", ParentID: "legs", Name: "Hamstrings & Glutes", Position: 2},
+	{ID: "core", Name: "Core", Position: 6},
+	{ID: "cardio", Name: "Cardio", Position: 7},
+}
+
+// DefaultExerciseLibraryCategories files each built-in library exercise under a
+// seeded category or muscle group
+var DefaultExerciseLibraryCategories = map[string]string{
+	"Barbell Bench Press":     "chest",
+	"Dumbbell Bench Press":    "chest",
+	"Incline Dumbbell Press":  "chest",
+	"Push-ups":                "chest",
+	"Pull-ups":                "back",
+	"Barbell Rows":            "back",
+	"Dumbbell Rows":           "back",
+	"Lat Pulldowns":           "back",
+	"Overhead Press":          "shoulders",
+	"Dumbbell Shoulder Press": "shoulders",
+	"Lateral Raises":          "shoulders",
+	"Front Raises":            "shoulders",
+	"Bicep Curls":             "biceps",
+	"Hammer Curls":            "biceps",
+	"Tricep Pushdowns":        "triceps",
+	"Tricep Dips":             "triceps",
+	"Barbell Squats":          "quadriceps",
+	"Deadlifts":               "posterior-chain",
+	"Leg Press":               "quadriceps",
+	"Lunges":                  "quadriceps",
+	"Plank":                   "core",
+	"Crunches":                "core",
+	"Russian Twists":          "core",
+	"Leg Raises":              "core",
+	"Running":                 "cardio",
+	"Cycling":                 "cardio",
+	"Jump Rope":               "cardio",
+	"Burpees":                 "cardio",
+}
@@ -0,0 +1,33 @@
+package models
+
+import "testing"
+
+func TestNewTaxonomy(t *testing.T) {
+	tax := NewTaxonomy([]*ExerciseCategory{
+		{ID: "legs", Name: "Legs", Position: 2},
+		{ID: "quads", ParentID: "legs", Name: "Quadriceps", Position: 1},
+		{ID: "chest", Name: "Chest", Position: 1},
+		{ID: "orphan", ParentID: "deleted", Name: "Orphan", Position: 3},
+	}, map[string]string{"Barbell Squats": "quads", "Push-ups": "chest"})
+
+	var order []string
+	for _, c := range tax.Categories {
+		order = append(order, c.ID)
+	}
+	if len(order) != 3 || order[0] != "chest" || order[1] != "legs" || order[2] != "orphan" {
+		t.Errorf("top-level order = %v", order)
+	}
+	if legs := tax.Category("legs"); len(legs.Children) != 1 || legs.Children[0].ID != "quads" {
+		t.Errorf("legs children = %+v", legs.Children)
+	}
+
+	if category, group := tax.Classify("barbell squats"); category == nil || category.ID != "legs" || group == nil || group.ID != "quads" {
+		t.Errorf("squats = %v / %v", category, group)
+	}
+	if category, group := tax.Classify("Push-ups"); category == nil || category.ID != "chest" || group != nil {
+		t.Errorf("push-ups = %v / %v", category, group)
+	}
+	if category, _ := tax.Classify("Zercher Squat"); category != nil {
+		t.Errorf("unfiled exercise = %v", category)
+	}
+}
@@ -9,5 +9,47 @@ type User struct {
 	ID           string    `json:"id" db:"id"`
 	Email        string    `json:"email" db:"email"`
 	PasswordHash string    `json:"-" db:"password_hash"`
+	Role         string    `json:"role" db:"role"`     // auth.RoleUser, RoleCoach or RoleAdmin
+	Status       string    `json:"status" db:"status"` // UserStatusActive or UserStatusSuspended
 	CreatedAt    time.Time `json:"created_at" db:"created_at"`
 }
+
+// Account statuses. Suspended users cannot sign in and their tokens and API keys stop working.
+const (
+	UserStatusActive    = "active"
+	UserStatusSuspended = "suspended"
+)
+
+// Suspended reports whether an admin has suspended the account
+func (u *User) Suspended() bool {
+	return u.Status == UserStatusSuspended
+}
+
+// APIKey is a personal API key for scripting against the API.
+// Only a hash of the key is stored; the plaintext is shown once at creation.
+type APIKey struct {
+	ID         string     `json:"id" db:"id"`
+	UserID     string     `json:"-" db:"user_id"`
+	Name       string     `json:"name" db:"name"`
+	Prefix     string     `json:"prefix" db:"prefix"` // first characters of the key, for recognising it
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at" db:"last_used_at"`
+}
+
+// UserSession is a signed-in device: one issued token, tracked from its first use
+type UserSession struct {
+	ID         string    `json:"id" db:"id"` // the token's jti
+	Device     string    `json:"device" db:"device"`
+	IP         string    `json:"ip" db:"ip"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at" db:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at" db:"expires_at"`
+	Current    bool      `json:"current" db:"-"` // the session making the request
+}
+
+// UserPreferences are a user's display settings. Units is imperial or metric;
+// weights are stored in pounds either way and converted on the way out.
+type UserPreferences struct {
+	Units     string     `json:"units" db:"units"`
+	UpdatedAt *time.Time `json:"updated_at" db:"updated_at"` // nil until first changed
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Warm-up weights are rounded to the nearest 2.5 (the smallest common plate
+// pair) unless a scheme says otherwise
+const (
+	DefaultWarmupRoundTo = 2.5
+	MaxWarmupSteps       = 6
+)
+
+// WarmupStep is one set of a warm-up ramp, at a percentage of the working weight
+type WarmupStep struct {
+	Percent int `json:"percent"`
+	Reps    int `json:"reps"`
+}
+
+// WarmupScheme is a warm-up ramp: sets at rising percentages of an exercise's
+// working weight, rounded to a multiple of RoundTo
+type WarmupScheme struct {
+	Steps   []WarmupStep `json:"steps"`
+	RoundTo float64      `json:"round_to"`
+}
+
+// WarmupSet is a generated warm-up set for one exercise
+type WarmupSet struct {
+	Percent int     `json:"percent"`
+	Weight  float64 `json:"weight"`
+	Reps    int     `json:"reps"`
+}
+
+// DefaultWarmupScheme returns the 40/60/80% ramp
+func DefaultWarmupScheme() WarmupScheme {
+	return WarmupScheme{
+		Steps:   []WarmupStep{{Percent: 40, Reps: 8}, {Percent: 60, Reps: 5}, {Percent: 80, Reps: 3}},
+		RoundTo: DefaultWarmupRoundTo,
+	}
+}
+
+// ParseWarmupScheme reads a ramp written as percent x reps, e.g. "50x5,70x3,85x1",
+// and a rounding increment. Empty values fall back to the default scheme.
+func ParseWarmupScheme(steps, roundTo string) (WarmupScheme, error) {
+	scheme := DefaultWarmupScheme()
+	if roundTo = strings.TrimSpace(roundTo); roundTo != "" {
+		v, err := strconv.ParseFloat(roundTo, 64)
+		if err != nil || v <= 0 || v > 50 {
+			return scheme, errors.New("round_to must be a positive weight up to 50")
+		}
+		scheme.RoundTo = v
+	}
+	if steps = strings.TrimSpace(steps); steps == "" {
+		return scheme, nil
+	}
+
+	parts := strings.Split(steps, ",")
+	if len(parts) > MaxWarmupSteps {
+		return scheme, fmt.Errorf("a warm-up has at most %d sets", MaxWarmupSteps)
+	}
+	scheme.Steps = make([]WarmupStep, 0, len(parts))
+	for _, part := range parts {
+		percent, reps, ok := strings.Cut(strings.ToLower(strings.TrimSpace(part)), "x")
+		p, perr := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(percent), "%"))
+		r, rerr := strconv.Atoi(strings.TrimSpace(reps))
+		if !ok || perr != nil || rerr != nil || p < 1 || p > 99 || r < 1 || r > 20 {
+			return scheme, fmt.Errorf("invalid warm-up set %q: want percent x reps, e.g. 60x5", part)
+		}
+		if n := len(scheme.Steps); n > 0 && p <= scheme.Steps[n-1].Percent {
+			return scheme, errors.New("warm-up percentages must rise")
+		}
+		scheme.Steps = append(scheme.Steps, WarmupStep{Percent: p, Reps: r})
+	}
+	return scheme, nil
+}
+
+// Ramp generates the warm-up sets for an exercise. Timed, cardio and unweighted
+// exercises get none, and steps that round to nothing, to the working weight or
+// to the same weight as the step before are dropped.
+func (s WarmupScheme) Ramp(e Exercise) []WarmupSet {
+	sets := []WarmupSet{}
+	if e.IsTimed() || e.IsCardio() || e.Weight <= 0 {
+		return sets
+	}
+	roundTo := s.RoundTo
+	if roundTo <= 0 {
+		roundTo = DefaultWarmupRoundTo
+	}
+	prev := 0.0
+	for _, step := range s.Steps {
+		weight := math.Round(e.Weight*float64(step.Percent)/100/roundTo) * roundTo
+		if weight <= prev || weight >= e.Weight {
+			continue
+		}
+		sets = append(sets, WarmupSet{Percent: step.Percent, Weight: weight, Reps: step.Reps})
+		prev = weight
+	}
+	return sets
+}
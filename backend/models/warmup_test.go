@@ -0,0 +1,51 @@
+package models
+
+import "testing"
+
+func TestWarmupRamp(t *testing.T) {
+	squat := Exercise{Name: "Squat", Sets: 3, Reps: 5, Weight: 100}
+	sets := DefaultWarmupScheme().Ramp(squat)
+	want := []WarmupSet{{40, 40, 8}, {60, 60, 5}, {80, 80, 3}}
+	if len(sets) != len(want) {
+		t.Fatalf("ramp = %+v", sets)
+	}
+	for i := range want {
+		if sets[i] != want[i] {
+			t.Errorf("set %d = %+v, want %+v", i+1, sets[i], want[i])
+		}
+	}
+
+	// Light weights collapse steps that round to the same plate
+	curl := Exercise{Name: "Curl", Sets: 3, Reps: 10, Weight: 7.5}
+	if sets := DefaultWarmupScheme().Ramp(curl); len(sets) != 2 || sets[0].Weight != 2.5 || sets[1].Weight != 5 {
+		t.Errorf("light ramp = %+v", sets)
+	}
+
+	for name, e := range map[string]Exercise{
+		"bodyweight": {Name: "Pull-up", Sets: 3, Reps: 8},
+		"timed":      {Name: "Plank", Sets: 3, Mode: ExerciseModeDuration, DurationSeconds: 60, Weight: 20},
+	} {
+		if sets := DefaultWarmupScheme().Ramp(e); len(sets) != 0 {
+			t.Errorf("%s: ramp = %+v, want none", name, sets)
+		}
+	}
+}
+
+func TestParseWarmupScheme(t *testing.T) {
+	scheme, err := ParseWarmupScheme("50x5, 70%x3,85x1", "5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scheme.Steps) != 3 || scheme.Steps[1] != (WarmupStep{70, 3}) || scheme.RoundTo != 5 {
+		t.Errorf("scheme = %+v", scheme)
+	}
+	if scheme, err := ParseWarmupScheme("", ""); err != nil || len(scheme.Steps) != 3 || scheme.RoundTo != DefaultWarmupRoundTo {
+		t.Errorf("default scheme = %+v, %v", scheme, err)
+	}
+
+	for _, bad := range [][2]string{{"50", ""}, {"60x5,50x3", ""}, {"100x1", ""}, {"50x0", ""}, {"", "-1"}, {"10x1,20x1,30x1,40x1,50x1,60x1,70x1", ""}} {
+		if _, err := ParseWarmupScheme(bad[0], bad[1]); err == nil {
+			t.Errorf("ParseWarmupScheme(%q, %q) should fail", bad[0], bad[1])
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// WebAuthnCredential is a passkey registered to a user
+type WebAuthnCredential struct {
+	ID         string     `json:"id" db:"id"` // base64url credential ID
+	UserID     string     `json:"-" db:"user_id"`
+	Name       string     `json:"name" db:"name"`
+	PublicKey  []byte     `json:"-" db:"public_key"` // COSE_Key
+	Algorithm  int64      `json:"algorithm" db:"algorithm"`
+	SignCount  uint32     `json:"-" db:"sign_count"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at" db:"last_used_at"`
+}
+
+// WebAuthn ceremony kinds
+const (
+	WebAuthnCeremonyRegister = "register"
+	WebAuthnCeremonyLogin    = "login"
+)
+
+// WebAuthnChallenge is a single-use challenge issued at the start of a ceremony
+type WebAuthnChallenge struct {
+	ID        string    `db:"id"`
+	UserID    string    `db:"user_id"` // empty for discoverable-credential logins
+	Kind      string    `db:"kind"`
+	Challenge string    `db:"challenge"`
+	ExpiresAt time.Time `db:"expires_at"`
+}
@@ -1,7 +1,14 @@
 package models
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
+	"unicode"
 )
 
 // Workout types for categorization
@@ -14,18 +21,73 @@ const (
 	WorkoutTypePower       = "power"
 )
 
+// ValidWorkoutType reports whether t is a known workout type or empty (uncategorized)
+func ValidWorkoutType(t string) bool {
+	switch t {
+	case "", WorkoutTypeStrength, WorkoutTypeCardio, WorkoutTypeFlexibility, WorkoutTypeHIIT, WorkoutTypeEndurance, WorkoutTypePower:
+		return true
+	}
+	return false
+}
+
 // Workout represents a workout plan with exercises
 type Workout struct {
-	ID        string     `json:"id" db:"id"`
-	UserID    string     `json:"-" db:"user_id"`
-	Name      string     `json:"name" db:"name"`
-	Type      string     `json:"type" db:"type"`
-	Exercises []Exercise `json:"exercises" db:"-"`
-	CreatedAt time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+	ID                    string     `json:"id" db:"id"`
+	UserID                string     `json:"-" db:"user_id"`
+	Name                  string     `json:"name" db:"name"`
+	Type                  string     `json:"type" db:"type"`
+	Notes                 string     `json:"notes" db:"notes"`
+	TargetDurationMinutes *int       `json:"target_duration_minutes" db:"target_duration_minutes"`
+	ArchivedAt            *time.Time `json:"archived_at" db:"archived_at"` // hidden from the workout list when set
+	Tags                  []string   `json:"tags" db:"-"`
+	Exercises             []Exercise `json:"exercises" db:"-"`
+	CreatedAt             time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt             time.Time  `json:"updated_at" db:"updated_at"`
 }
 
-// WorkoutTemplate represents a predefined workout template with exercises
+// MaxWorkoutNotesLen bounds a workout's notes, in bytes
+const MaxWorkoutNotesLen = 2000
+
+// Limits on workout tags
+const (
+	MaxWorkoutTags   = 20
+	MaxWorkoutTagLen = 30
+)
+
+// NormalizeTag lowercases and trims a workout tag and checks it is 1-30 letters,
+// digits, spaces or hyphens
+func NormalizeTag(tag string) (string, error) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if tag == "" || len(tag) > MaxWorkoutTagLen {
+		return "", fmt.Errorf("tag must be 1 to %d characters", MaxWorkoutTagLen)
+	}
+	for _, r := range tag {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != ' ' && r != '-' {
+			return "", errors.New("tag may only contain letters, digits, spaces and hyphens")
+		}
+	}
+	return tag, nil
+}
+
+// WorkoutPage is one page of GET /api/workouts?limit=, with the total number of
+// workouts matching the filter
+type WorkoutPage struct {
+	Workouts []*Workout `json:"workouts"`
+	Total    int        `json:"total"`
+	Limit    int        `json:"limit"`
+	Offset   int        `json:"offset"`
+}
+
+// WorkoutSearchResult is one hit from GET /api/workouts/search. Higher scores
+// rank first: 4 for an exact name match, 3 for a prefix, 2 for a substring and
+// 1 when only an exercise name matched.
+type WorkoutSearchResult struct {
+	Workout          *Workout `json:"workout"`
+	Score            int      `json:"score"`
+	MatchedExercises []string `json:"matched_exercises,omitempty"`
+}
+
+// WorkoutTemplate represents a built-in or user-saved workout template with exercises
 type WorkoutTemplate struct {
 	ID          string     `json:"id" db:"id"`
 	Name        string     `json:"name" db:"name"`
@@ -34,42 +96,277 @@ type WorkoutTemplate struct {
 	Difficulty  string     `json:"difficulty" db:"difficulty"`
 	Duration    int        `json:"duration" db:"duration"` // in minutes
 	Exercises   []Exercise `json:"exercises" db:"-"`
-	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	// Custom marks a template the user saved from one of their workouts
+	Custom    bool      `json:"custom,omitempty" db:"-"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
 }
 
-// Exercise represents an exercise within a workout
+// Exercise modes: counted in reps, held for a target time (e.g. plank), or
+// cardio covered for a target time and/or distance (e.g. running)
+const (
+	ExerciseModeReps     = "reps"
+	ExerciseModeDuration = "duration"
+	ExerciseModeCardio   = "cardio"
+)
+
+// MaxDistanceMeters bounds a cardio target or logged distance
+const MaxDistanceMeters = 1000000
+
+// MaxRestSeconds bounds the planned rest between sets of an exercise
+const MaxRestSeconds = 3600
+
+// Exercise represents an exercise within a workout.
+// Duration-mode exercises use DurationSeconds per set instead of Reps, and
+// cardio exercises DurationSeconds and/or DistanceMeters. Rep
+// exercises may prescribe a range from RepsMin to RepsMax; Reps is then the
+// bottom of the range unless set within it, for clients that only know Reps.
 type Exercise struct {
-	ID        string    `json:"id" db:"id"`
-	Name      string    `json:"name" db:"name"`
-	Sets      int       `json:"sets" db:"sets"`
-	Reps      int       `json:"reps" db:"reps"`
-	Weight    float64   `json:"weight" db:"weight"`
-	WorkoutID string    `json:"workout_id" db:"workout_id"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	ID              string    `json:"id" db:"id"`
+	Name            string    `json:"name" db:"name"`
+	Sets            int       `json:"sets" db:"sets"`
+	Reps            int       `json:"reps" db:"reps"`
+	RepsMin         int       `json:"reps_min" db:"reps_min"` // 0 when a single rep count is prescribed
+	RepsMax         int       `json:"reps_max" db:"reps_max"`
+	Weight          float64   `json:"weight" db:"weight"`
+	Mode            string    `json:"mode" db:"mode"`
+	DurationSeconds int       `json:"duration_seconds" db:"duration_seconds"`
+	DistanceMeters  int       `json:"distance_meters" db:"distance_meters"` // cardio only; 0 when not set
+	RestSeconds     int       `json:"rest_seconds" db:"rest_seconds"`       // planned rest after each set; 0 when not set
+	TargetRPE       *float64  `json:"target_rpe,omitempty" db:"target_rpe"`
+	Tempo           string    `json:"tempo,omitempty" db:"tempo"`         // e.g. 3-1-X-0, see NormalizeTempo
+	VideoURL        string    `json:"video_url,omitempty" db:"video_url"` // form demonstration, linked or uploaded
+	ImageURL        string    `json:"image_url,omitempty" db:"image_url"`
+	Unilateral      bool      `json:"unilateral" db:"unilateral"` // single-arm/leg; sets log each side
+	WorkoutID       string    `json:"workout_id" db:"workout_id"`
+	Position        int       `json:"position" db:"position"`               // 1-based order within the workout
+	GroupID         string    `json:"group_id,omitempty" db:"group_id"`     // shared by the members of a superset or circuit
+	GroupType       string    `json:"group_type,omitempty" db:"group_type"` // ExerciseGroupSuperset or ExerciseGroupCircuit
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// IsTimed reports whether the exercise is performed for time rather than reps
+func (e Exercise) IsTimed() bool {
+	return e.Mode == ExerciseModeDuration
 }
 
-// ExerciseTemplate represents a predefined exercise template for quick addition
+// IsCardio reports whether the exercise is covered for time and/or distance
+func (e Exercise) IsCardio() bool {
+	return e.Mode == ExerciseModeCardio
+}
+
+// RepTarget describes the prescribed reps, e.g. "8-12" or "10"
+func (e Exercise) RepTarget() string {
+	if e.RepsMax > 0 && e.RepsMax != e.RepsMin {
+		return fmt.Sprintf("%d-%d", e.RepsMin, e.RepsMax)
+	}
+	return strconv.Itoa(e.Reps)
+}
+
+// FormatCardio describes a cardio time and/or distance, e.g. "5 km in 25:00",
+// "800 m" or "1:05:00". Zero values are left out.
+func FormatCardio(durationSeconds, distanceMeters int) string {
+	var distance, duration string
+	if distanceMeters >= 1000 {
+		distance = strconv.FormatFloat(float64(distanceMeters)/1000, 'f', -1, 64) + " km"
+	} else if distanceMeters > 0 {
+		distance = strconv.Itoa(distanceMeters) + " m"
+	}
+	if h, m, sec := durationSeconds/3600, durationSeconds/60%60, durationSeconds%60; h > 0 {
+		duration = fmt.Sprintf("%d:%02d:%02d", h, m, sec)
+	} else if durationSeconds > 0 {
+		duration = fmt.Sprintf("%d:%02d", m, sec)
+	}
+	switch {
+	case distance != "" && duration != "":
+		return distance + " in " + duration
+	case distance != "":
+		return distance
+	}
+	return duration
+}
+
+// validateCardioTarget checks a cardio exercise's target time and distance,
+// given with field names duration and distance: at least one must be set
+func validateCardioTarget(durationSeconds, distanceMeters int, duration, distance string) error {
+	if durationSeconds < 0 || distanceMeters < 0 || durationSeconds == 0 && distanceMeters == 0 {
+		return fmt.Errorf("%s or %s must be positive for cardio exercises", duration, distance)
+	}
+	if distanceMeters > MaxDistanceMeters {
+		return fmt.Errorf("%s cannot exceed %d", distance, MaxDistanceMeters)
+	}
+	return nil
+}
+
+// validateRepRange checks an optional rep range given with field names min and
+// max, and fills in reps from its bottom when reps is unset. A range must have
+// both ends, with reps inside it.
+func validateRepRange(reps *int, repsMin, repsMax int, min, max string) error {
+	if repsMin == 0 && repsMax == 0 {
+		return nil
+	}
+	if repsMin < 1 || repsMax < repsMin {
+		return fmt.Errorf("%s and %s must both be set, with %s between 1 and %s", min, max, min, max)
+	}
+	if *reps == 0 {
+		*reps = repsMin
+	}
+	if *reps < repsMin || *reps > repsMax {
+		return fmt.Errorf("reps must be within %s and %s", min, max)
+	}
+	return nil
+}
+
+// Validate checks an exercise before it is saved and normalizes its mode:
+// rep exercises drop any duration and distance, timed exercises drop reps and
+// distance, and cardio exercises drop reps
+func (e *Exercise) Validate() error {
+	e.Name = strings.TrimSpace(e.Name)
+	if e.Name == "" || len(e.Name) > 255 {
+		return errors.New("name must be 1 to 255 characters")
+	}
+	if e.Sets < 1 {
+		return errors.New("sets must be positive")
+	}
+	if e.Weight < 0 {
+		return errors.New("weight cannot be negative")
+	}
+	if e.RestSeconds < 0 || e.RestSeconds > MaxRestSeconds {
+		return fmt.Errorf("rest_seconds must be 0 to %d", MaxRestSeconds)
+	}
+	if e.TargetRPE != nil && !ValidRPE(*e.TargetRPE) {
+		return errors.New("target_rpe must be 1 to 10 in steps of 0.5")
+	}
+	tempo, err := NormalizeTempo(e.Tempo)
+	if err != nil {
+		return err
+	}
+	e.Tempo = tempo
+	switch e.Mode {
+	case "", ExerciseModeReps:
+		if err := validateRepRange(&e.Reps, e.RepsMin, e.RepsMax, "reps_min", "reps_max"); err != nil {
+			return err
+		}
+		if e.Reps <= 0 {
+			return errors.New("reps must be positive")
+		}
+		e.Mode, e.DurationSeconds, e.DistanceMeters = ExerciseModeReps, 0, 0
+	case ExerciseModeDuration:
+		if e.DurationSeconds <= 0 {
+			return errors.New("duration_seconds must be positive for duration exercises")
+		}
+		e.Reps, e.RepsMin, e.RepsMax, e.DistanceMeters = 0, 0, 0, 0
+	case ExerciseModeCardio:
+		if err := validateCardioTarget(e.DurationSeconds, e.DistanceMeters, "duration_seconds", "distance_meters"); err != nil {
+			return err
+		}
+		e.Reps, e.RepsMin, e.RepsMax = 0, 0, 0
+	default:
+		return errors.New("mode must be reps, duration or cardio")
+	}
+	return nil
+}
+
+// ExerciseTemplate represents an exercise template for quick addition: one of the
+// built-in library, or one a user defined (those have an ID)
 type ExerciseTemplate struct {
-	Name          string  `json:"name" db:"name"`
-	Category      string  `json:"category" db:"category"`
-	DefaultSets   int     `json:"default_sets" db:"default_sets"`
-	DefaultReps   int     `json:"default_reps" db:"default_reps"`
-	DefaultWeight float64 `json:"default_weight" db:"default_weight"`
+	ID                     string   `json:"id,omitempty" db:"id"`
+	Name                   string   `json:"name" db:"name"`
+	Category               string   `json:"category" db:"-"` // top-level taxonomy category name
+	CategoryID             string   `json:"category_id,omitempty" db:"-"`
+	MuscleGroup            string   `json:"muscle_group,omitempty" db:"-"`
+	DefaultSets            int      `json:"default_sets" db:"default_sets"`
+	DefaultReps            int      `json:"default_reps" db:"default_reps"`
+	DefaultRepsMin         int      `json:"default_reps_min,omitempty" db:"default_reps_min"`
+	DefaultRepsMax         int      `json:"default_reps_max,omitempty" db:"default_reps_max"`
+	DefaultWeight          float64  `json:"default_weight" db:"default_weight"`
+	Mode                   string   `json:"mode,omitempty" db:"mode"` // empty means reps
+	DefaultDurationSeconds int      `json:"default_duration_seconds,omitempty" db:"default_duration_seconds"`
+	DefaultDistanceMeters  int      `json:"default_distance_meters,omitempty" db:"default_distance_meters"`
+	DefaultRestSeconds     int      `json:"default_rest_seconds,omitempty" db:"default_rest_seconds"`
+	RiskFlags              []string `json:"risk_flags,omitempty" db:"-"`
+	VideoURL               string   `json:"video_url,omitempty" db:"video_url"`
+	ImageURL               string   `json:"image_url,omitempty" db:"image_url"`
+	ExerciseMetadata
+}
+
+// Validate trims the name and checks a user-defined template's defaults, clearing
+// the field the mode does not use. Reps templates are stored with an empty mode,
+// like the built-in library.
+func (t *ExerciseTemplate) Validate() error {
+	t.Name = strings.TrimSpace(t.Name)
+	if t.Name == "" || len(t.Name) > 255 {
+		return errors.New("name must be 1 to 255 characters")
+	}
+	if t.DefaultSets < 1 {
+		return errors.New("default_sets must be positive")
+	}
+	if t.DefaultWeight < 0 {
+		return errors.New("default_weight cannot be negative")
+	}
+	if t.DefaultRestSeconds < 0 || t.DefaultRestSeconds > MaxRestSeconds {
+		return fmt.Errorf("default_rest_seconds must be 0 to %d", MaxRestSeconds)
+	}
+	if err := t.ExerciseMetadata.Validate(); err != nil {
+		return err
+	}
+	switch t.Mode {
+	case "", ExerciseModeReps:
+		if err := validateRepRange(&t.DefaultReps, t.DefaultRepsMin, t.DefaultRepsMax, "default_reps_min", "default_reps_max"); err != nil {
+			return err
+		}
+		if t.DefaultReps <= 0 {
+			return errors.New("default_reps must be positive")
+		}
+		t.Mode, t.DefaultDurationSeconds, t.DefaultDistanceMeters = "", 0, 0
+	case ExerciseModeDuration:
+		if t.DefaultDurationSeconds <= 0 {
+			return errors.New("default_duration_seconds must be positive for duration exercises")
+		}
+		t.DefaultReps, t.DefaultRepsMin, t.DefaultRepsMax, t.DefaultDistanceMeters = 0, 0, 0, 0
+	case ExerciseModeCardio:
+		if err := validateCardioTarget(t.DefaultDurationSeconds, t.DefaultDistanceMeters, "default_duration_seconds", "default_distance_meters"); err != nil {
+			return err
+		}
+		t.DefaultReps, t.DefaultRepsMin, t.DefaultRepsMax = 0, 0, 0
+	default:
+		return errors.New("mode must be reps, duration or cardio")
+	}
+	return nil
+}
+
+// Exercise risk flags used to match library exercises against injury restrictions
+const (
+	RiskSpinalLoading   = "spinal_loading"
+	RiskSpinalFlexion   = "spinal_flexion"
+	RiskOverhead        = "overhead"
+	RiskKneeDominant    = "knee_dominant"
+	RiskShoulderLoading = "shoulder_loading"
+	RiskHighImpact      = "high_impact"
+)
+
+// RiskFlags lists every known exercise risk flag
+var RiskFlags = []string{
+	RiskSpinalLoading, RiskSpinalFlexion, RiskOverhead, RiskKneeDominant, RiskShoulderLoading, RiskHighImpact,
 }
 
 // WorkoutSession represents an active or completed workout session
 type WorkoutSession struct {
-	ID        string             `json:"id" db:"id"`
-	UserID    string             `json:"-" db:"user_id"`
-	WorkoutID string             `json:"workout_id" db:"workout_id"`
-	Workout   *Workout           `json:"workout" db:"-"`
-	StartedAt time.Time          `json:"started_at" db:"started_at"`
-	EndedAt   *time.Time         `json:"ended_at" db:"ended_at"`
-	IsActive  bool               `json:"is_active" db:"is_active"`
-	Exercises []*SessionExercise `json:"exercises" db:"-"`
-	CreatedAt time.Time          `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time          `json:"updated_at" db:"updated_at"`
+	ID           string              `json:"id" db:"id"`
+	UserID       string              `json:"-" db:"user_id"`
+	WorkoutID    string              `json:"workout_id" db:"workout_id"`
+	Workout      *Workout            `json:"workout" db:"-"`
+	StartedAt    time.Time           `json:"started_at" db:"started_at"`
+	EndedAt      *time.Time          `json:"ended_at" db:"ended_at"`
+	IsActive     bool                `json:"is_active" db:"is_active"`
+	Metadata     SessionMetadata     `json:"metadata" db:"metadata"`
+	Exercises    []*SessionExercise  `json:"exercises" db:"-"`
+	Warnings     []ExerciseWarning   `json:"warnings,omitempty" db:"-"`
+	Pace         *SessionPace        `json:"pace,omitempty" db:"-"`
+	Partner      *SessionPartner     `json:"partner,omitempty" db:"-"`      // set for partner workouts
+	Progressions []ProgressionResult `json:"progressions,omitempty" db:"-"` // set by progression rules when the session ends
+	CreatedAt    time.Time           `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time           `json:"updated_at" db:"updated_at"`
 }
 
 // SessionExercise represents an exercise performed during a workout session
@@ -85,14 +382,21 @@ type SessionExercise struct {
 
 // ExerciseSet represents a single set of an exercise during a session
 type ExerciseSet struct {
-	ID                string    `json:"id" db:"id"`
-	SessionExerciseID string    `json:"session_exercise_id" db:"session_exercise_id"`
-	Reps              int       `json:"reps" db:"reps"`
-	Weight            float64   `json:"weight" db:"weight"`
-	Completed         bool      `json:"completed" db:"completed"`
-	Notes             *string   `json:"notes" db:"notes"`
-	CreatedAt         time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt         time.Time `json:"updated_at" db:"updated_at"`
+	ID                string      `json:"id" db:"id"`
+	SessionExerciseID string      `json:"session_exercise_id" db:"session_exercise_id"`
+	Reps              int         `json:"reps" db:"reps"`
+	Weight            float64     `json:"weight" db:"weight"`
+	DurationSeconds   *int        `json:"duration_seconds" db:"duration_seconds"` // time held, for duration-mode and cardio exercises
+	DistanceMeters    *int        `json:"distance_meters" db:"distance_meters"`   // distance covered, for cardio exercises
+	Technique         string      `json:"technique,omitempty" db:"technique"`     // drop_set or rest_pause when Segments are logged
+	Segments          SetSegments `json:"segments,omitempty" db:"segments"`
+	Sides             SetSides    `json:"sides,omitzero" db:"sides"` // unilateral sets; Reps and Weight then mirror the left side
+	ActualRPE         *float64    `json:"actual_rpe,omitempty" db:"actual_rpe"`
+	SetType           string      `json:"set_type" db:"set_type"` // normal, amrap, drop, failure or warmup
+	Completed         bool        `json:"completed" db:"completed"`
+	Notes             *string     `json:"notes" db:"notes"`
+	CreatedAt         time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time   `json:"updated_at" db:"updated_at"`
 }
 
 // DinoGameScore represents a score from the Dino Game easter egg
@@ -101,3 +405,51 @@ type DinoGameScore struct {
 	Score     int       `json:"score" db:"score"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 }
+
+// SessionMetadata is optional journaling context attached to a workout session.
+// Stored as JSON so new fields can be added without schema changes.
+type SessionMetadata struct {
+	Gym         string            `json:"gym,omitempty"`
+	Partners    []string          `json:"partners,omitempty"`
+	PlaylistURL string            `json:"playlist_url,omitempty"`
+	Mood        string            `json:"mood,omitempty"`
+	Crowd       int               `json:"crowd,omitempty"` // 1 (empty) to 5 (packed)
+	Extra       map[string]string `json:"extra,omitempty"`
+}
+
+// IsEmpty reports whether no metadata has been recorded
+func (m SessionMetadata) IsEmpty() bool {
+	return m.Gym == "" && len(m.Partners) == 0 && m.PlaylistURL == "" && m.Mood == "" && m.Crowd == 0 && len(m.Extra) == 0
+}
+
+// Scan implements sql.Scanner for the JSON metadata column (NULL scans as empty)
+func (m *SessionMetadata) Scan(src interface{}) error {
+	*m = SessionMetadata{}
+	var raw []byte
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case string:
+		raw = []byte(v)
+	case []byte:
+		raw = v
+	default:
+		return fmt.Errorf("unsupported session metadata type %T", src)
+	}
+	if len(raw) == 0 {
+		return nil
+	}
+	return json.Unmarshal(raw, m)
+}
+
+// Value implements driver.Valuer, storing empty metadata as NULL
+func (m SessionMetadata) Value() (driver.Value, error) {
+	if m.IsEmpty() {
+		return nil, nil
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
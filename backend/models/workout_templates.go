@@ -0,0 +1,89 @@
+package models
+
+// DefaultWorkoutTemplates seeds the workout_templates catalog on first start.
+// After that the catalog lives in the database, so templates can be added or
+// edited there without a release.
+var DefaultWorkoutTemplates = []WorkoutTemplate{
+	{
+		ID:          "push-pull-legs",
+		Name:        "Push Pull Legs",
+		Type:        WorkoutTypeStrength,
+		Description: "Classic 3-day split focusing on pushing, pulling, and leg movements",
+		Difficulty:  "intermediate",
+		Duration:    60,
+		Exercises: []Exercise{
+			{Name: "Bench Press", Sets: 4, Reps: 8, Weight: 0},
+			{Name: "Overhead Press", Sets: 3, Reps: 10, Weight: 0},
+			{Name: "Dips", Sets: 3, Reps: 12, Weight: 0},
+			{Name: "Lateral Raises", Sets: 3, Reps: 15, Weight: 0},
+		},
+	},
+	{
+		ID:          "full-body-strength",
+		Name:        "Full Body Strength",
+		Type:        WorkoutTypeStrength,
+		Description: "Complete full-body workout hitting all major muscle groups",
+		Difficulty:  "beginner",
+		Duration:    45,
+		Exercises: []Exercise{
+			{Name: "Squats", Sets: 3, Reps: 12, Weight: 0},
+			{Name: "Push-ups", Sets: 3, Reps: 10, Weight: 0},
+			{Name: "Rows", Sets: 3, Reps: 12, Weight: 0},
+			{Name: "Plank", Sets: 3, Reps: 0, Weight: 0, Mode: ExerciseModeDuration, DurationSeconds: 45},
+		},
+	},
+	{
+		ID:          "hiit-cardio",
+		Name:        "HIIT Cardio",
+		Type:        WorkoutTypeHIIT,
+		Description: "High-intensity interval training for cardiovascular fitness",
+		Difficulty:  "advanced",
+		Duration:    30,
+		Exercises: []Exercise{
+			{Name: "Burpees", Sets: 4, Reps: 20, Weight: 0},
+			{Name: "Mountain Climbers", Sets: 4, Reps: 30, Weight: 0},
+			{Name: "Jump Squats", Sets: 4, Reps: 15, Weight: 0},
+			{Name: "High Knees", Sets: 4, Reps: 30, Weight: 0},
+		},
+	},
+	{
+		ID:          "upper-body-focus",
+		Name:        "Upper Body Focus",
+		Type:        WorkoutTypeStrength,
+		Description: "Targeted upper body workout for chest, back, and arms",
+		Difficulty:  "intermediate",
+		Duration:    50,
+		Exercises: []Exercise{
+			{Name: "Pull-ups", Sets: 4, Reps: 8, Weight: 0},
+			{Name: "Dumbbell Rows", Sets: 3, Reps: 12, Weight: 0},
+			{Name: "Diamond Push-ups", Sets: 3, Reps: 12, Weight: 0},
+			{Name: "Bicep Curls", Sets: 3, Reps: 15, Weight: 0},
+		},
+	},
+	{
+		ID:          "core-strength",
+		Name:        "Core Strength",
+		Type:        WorkoutTypeStrength,
+		Description: "Comprehensive core workout for stability and strength",
+		Difficulty:  "beginner",
+		Duration:    25,
+		Exercises: []Exercise{
+			{Name: "Crunches", Sets: 3, Reps: 20, Weight: 0},
+			{Name: "Russian Twists", Sets: 3, Reps: 20, Weight: 0},
+			{Name: "Leg Raises", Sets: 3, Reps: 15, Weight: 0},
+			{Name: "Side Plank", Sets: 3, Reps: 0, Weight: 0, Mode: ExerciseModeDuration, DurationSeconds: 30},
+		},
+	},
+	{
+		ID:          "endurance-run",
+		Name:        "Endurance Run",
+		Type:        WorkoutTypeEndurance,
+		Description: "Steady-state cardio for building endurance",
+		Difficulty:  "beginner",
+		Duration:    45,
+		Exercises: []Exercise{
+			{Name: "Running", Sets: 1, Reps: 0, Weight: 0, Mode: ExerciseModeCardio, DurationSeconds: 1800},
+			{Name: "Walking", Sets: 1, Reps: 0, Weight: 0, Mode: ExerciseModeCardio, DurationSeconds: 900},
+		},
+	},
+}
@@ -0,0 +1,105 @@
+package models
+
+import "testing"
+
+func TestExerciseValidate(t *testing.T) {
+	e := Exercise{Name: " Plank ", Sets: 3, Reps: 10, Mode: ExerciseModeDuration, DurationSeconds: 45}
+	if err := e.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if e.Name != "Plank" || e.Reps != 0 {
+		t.Errorf("duration exercise not normalized: %+v", e)
+	}
+
+	e = Exercise{Name: "Squat", Sets: 3, Reps: 5, DurationSeconds: 30}
+	if err := e.Validate(); err != nil || e.Mode != ExerciseModeReps || e.DurationSeconds != 0 {
+		t.Errorf("rep exercise: %+v, %v", e, err)
+	}
+
+	// Reps default to the bottom of a range
+	e = Exercise{Name: "Curl", Sets: 3, RepsMin: 8, RepsMax: 12}
+	if err := e.Validate(); err != nil || e.Reps != 8 || e.RepTarget() != "8-12" {
+		t.Errorf("rep range: %+v, %v", e, err)
+	}
+
+	// Cardio keeps its time and distance and drops reps
+	e = Exercise{Name: "Running", Sets: 1, Reps: 20, Mode: ExerciseModeCardio, DistanceMeters: 5000}
+	if err := e.Validate(); err != nil || e.Reps != 0 || e.DistanceMeters != 5000 || e.DurationSeconds != 0 {
+		t.Errorf("cardio exercise: %+v, %v", e, err)
+	}
+	e = Exercise{Name: "Squat", Sets: 3, Reps: 5, DistanceMeters: 100}
+	if err := e.Validate(); err != nil || e.DistanceMeters != 0 {
+		t.Errorf("rep exercise kept a distance: %+v, %v", e, err)
+	}
+
+	for name, bad := range map[string]Exercise{
+		"no name":         {Name: " ", Sets: 3, Reps: 5},
+		"no sets":         {Name: "Squat", Reps: 5},
+		"negative weight": {Name: "Squat", Sets: 3, Reps: 5, Weight: -5},
+		"no reps":         {Name: "Squat", Sets: 3},
+		"no duration":     {Name: "Plank", Sets: 3, Mode: ExerciseModeDuration},
+		"unknown mode":    {Name: "Squat", Sets: 3, Reps: 5, Mode: "distance"},
+		"no cardio goal":  {Name: "Running", Sets: 1, Reps: 20, Mode: ExerciseModeCardio},
+		"negative dist":   {Name: "Running", Sets: 1, Mode: ExerciseModeCardio, DurationSeconds: 600, DistanceMeters: -1},
+		"half a range":    {Name: "Curl", Sets: 3, RepsMin: 8},
+		"inverted range":  {Name: "Curl", Sets: 3, RepsMin: 12, RepsMax: 8},
+		"reps off range":  {Name: "Curl", Sets: 3, Reps: 15, RepsMin: 8, RepsMax: 12},
+	} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestExerciseTemplateValidate(t *testing.T) {
+	tmpl := ExerciseTemplate{Name: " Sled Push ", DefaultSets: 4, DefaultReps: 20, Mode: ExerciseModeReps, DefaultDurationSeconds: 30}
+	if err := tmpl.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if tmpl.Name != "Sled Push" || tmpl.Mode != "" || tmpl.DefaultDurationSeconds != 0 {
+		t.Errorf("rep template not normalized: %+v", tmpl)
+	}
+
+	for name, bad := range map[string]ExerciseTemplate{
+		"no name":     {Name: "", DefaultSets: 3, DefaultReps: 5},
+		"no sets":     {Name: "Sled Push", DefaultReps: 5},
+		"no duration": {Name: "Wall Sit", DefaultSets: 3, Mode: ExerciseModeDuration},
+		"no goal":     {Name: "Rowing", DefaultSets: 1, Mode: ExerciseModeCardio},
+		"bad range":   {Name: "Sled Push", DefaultSets: 3, DefaultRepsMin: 0, DefaultRepsMax: 20},
+	} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestFormatCardio(t *testing.T) {
+	for _, tc := range []struct {
+		seconds, meters int
+		want            string
+	}{
+		{1500, 5000, "5 km in 25:00"},
+		{0, 800, "800 m"},
+		{3900, 0, "1:05:00"},
+		{0, 21097, "21.097 km"},
+		{0, 0, ""},
+	} {
+		if got := FormatCardio(tc.seconds, tc.meters); got != tc.want {
+			t.Errorf("FormatCardio(%d, %d) = %q, want %q", tc.seconds, tc.meters, got, tc.want)
+		}
+	}
+}
+
+func TestNormalizeTag(t *testing.T) {
+	if got, err := NormalizeTag("  Upper Body "); err != nil || got != "upper body" {
+		t.Errorf("NormalizeTag = %q, %v", got, err)
+	}
+	if got, err := NormalizeTag("5x5-Beginner"); err != nil || got != "5x5-beginner" {
+		t.Errorf("NormalizeTag = %q, %v", got, err)
+	}
+	for _, bad := range []string{"", "   ", "legs!", "a/b", "this-tag-is-far-too-long-to-be-useful"} {
+		if _, err := NormalizeTag(bad); err == nil {
+			t.Errorf("NormalizeTag(%q) should fail", bad)
+		}
+	}
+}
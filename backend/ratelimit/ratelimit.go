@@ -0,0 +1,179 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"liftoff/backend/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+/**
+ * Rate Limit Package
+ *
+ * In-memory token buckets keyed by authenticated user (or client IP for
+ * anonymous requests). Each Limiter refills at a steady rate up to its
+ * burst size; requests without a token are rejected with 429. Every
+ * response carries X-RateLimit-Limit, X-RateLimit-Remaining and
+ * X-RateLimit-Reset (seconds until the bucket is full again).
+ *
+ * Buckets live in process memory, so limits apply per server instance.
+ */
+
+// Limiter is a set of token buckets sharing one rate and burst size
+type Limiter struct {
+	rate  float64 // tokens per second
+	burst float64
+	now   func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewLimiter allows perMinute requests per key on average, with bursts of up to burst.
+// It returns nil (no limiting) when perMinute is 0.
+func NewLimiter(perMinute, burst int) *Limiter {
+	if perMinute <= 0 {
+		return nil
+	}
+	if burst <= 0 {
+		burst = 1
+	}
+	return &Limiter{
+		rate:    float64(perMinute) / 60,
+		burst:   float64(burst),
+		now:     time.Now,
+		buckets: map[string]*bucket{},
+	}
+}
+
+// LimiterFromEnv builds a limiter from <prefix>_RPM and <prefix>_BURST, falling back to the defaults
+func LimiterFromEnv(prefix string, defPerMinute, defBurst int) *Limiter {
+	return NewLimiter(intFromEnv(prefix+"_RPM", defPerMinute), intFromEnv(prefix+"_BURST", defBurst))
+}
+
+// Result describes a bucket after a request was counted against it
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration // until the bucket is full again
+	RetryAfter time.Duration // until the next token, when not allowed
+}
+
+// Allow takes a token from key's bucket if one is available
+func (l *Limiter) Allow(key string) Result {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	res := Result{Limit: int(l.burst)}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = l.secondsFor(1 - b.tokens)
+	}
+	res.Remaining = int(b.tokens)
+	res.Reset = l.secondsFor(l.burst - b.tokens)
+	return res
+}
+
+func (l *Limiter) secondsFor(tokens float64) time.Duration {
+	return time.Duration(tokens / l.rate * float64(time.Second))
+}
+
+// Sweep drops buckets that have refilled completely, since they behave like new ones.
+// Intended to run from the jobs scheduler.
+func (l *Limiter) Sweep(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+	return nil
+}
+
+// Rule applies a limiter to requests whose path starts with Prefix
+type Rule struct {
+	Prefix  string
+	Limiter *Limiter
+}
+
+// Middleware limits each request with the first rule whose prefix matches its path.
+// Rules with a nil (disabled) limiter are skipped. Requests with a valid bearer token
+// are limited per user, others per client IP.
+func Middleware(rules ...Rule) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var limiter *Limiter
+		for _, rule := range rules {
+			if rule.Limiter != nil && strings.HasPrefix(c.Request.URL.Path, rule.Prefix) {
+				limiter = rule.Limiter
+				break
+			}
+		}
+		if limiter == nil {
+			c.Next()
+			return
+		}
+
+		res := limiter.Allow(clientKey(c))
+		h := c.Writer.Header()
+		h.Set("X-RateLimit-Limit", strconv.Itoa(res.Limit))
+		h.Set("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		h.Set("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+		if !res.Allowed {
+			h.Set("Retry-After", strconv.Itoa(ceilSeconds(res.RetryAfter)))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Rate limit exceeded, slow down"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// clientKey identifies the caller without requiring AuthMiddleware to have run
+func clientKey(c *gin.Context) string {
+	if token := auth.RequestToken(c); token != "" {
+		if claims, err := auth.ValidateToken(token); err == nil {
+			return "user:" + claims.UserID
+		}
+	}
+	return "ip:" + c.ClientIP()
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+func intFromEnv(key string, def int) int {
+	if n, err := strconv.Atoi(os.Getenv(key)); err == nil && n >= 0 {
+		return n
+	}
+	return def
+}
@@ -0,0 +1,100 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func fakeClock(l *Limiter) *time.Time {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return now }
+	return &now
+}
+
+func TestLimiter_BurstAndRefill(t *testing.T) {
+	l := NewLimiter(60, 3) // one token per second
+	now := fakeClock(l)
+
+	for i := 0; i < 3; i++ {
+		if res := l.Allow("k"); !res.Allowed || res.Remaining != 2-i {
+			t.Fatalf("request %d: %+v", i+1, res)
+		}
+	}
+	res := l.Allow("k")
+	if res.Allowed {
+		t.Fatal("expected bucket to be empty")
+	}
+	if res.RetryAfter != time.Second {
+		t.Errorf("RetryAfter = %v, want 1s", res.RetryAfter)
+	}
+	if other := l.Allow("other"); !other.Allowed {
+		t.Error("keys should have independent buckets")
+	}
+
+	*now = now.Add(1500 * time.Millisecond)
+	if res := l.Allow("k"); !res.Allowed {
+		t.Errorf("expected refill after 1.5s: %+v", res)
+	}
+}
+
+func TestLimiter_Sweep(t *testing.T) {
+	l := NewLimiter(60, 2)
+	now := fakeClock(l)
+	l.Allow("a")
+	*now = now.Add(5 * time.Second)
+	l.Allow("b")
+
+	_ = l.Sweep(context.Background())
+	if _, ok := l.buckets["a"]; ok {
+		t.Error("refilled bucket should be swept")
+	}
+	if _, ok := l.buckets["b"]; !ok {
+		t.Error("partially used bucket should be kept")
+	}
+}
+
+func TestNewLimiter_Disabled(t *testing.T) {
+	if NewLimiter(0, 10) != nil {
+		t.Error("0 requests per minute should disable limiting")
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Middleware(
+		Rule{Prefix: "/api/auth", Limiter: NewLimiter(60, 1)},
+		Rule{Prefix: "/api", Limiter: NewLimiter(60, 5)},
+	))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	r.POST("/api/auth/login", ok)
+	r.GET("/api/workouts", ok)
+	r.GET("/health", ok)
+
+	do := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w
+	}
+
+	w := do("POST", "/api/auth/login")
+	if w.Code != http.StatusOK || w.Header().Get("X-RateLimit-Limit") != "1" || w.Header().Get("X-RateLimit-Remaining") != "0" {
+		t.Errorf("first login: %d %v", w.Code, w.Header())
+	}
+	w = do("POST", "/api/auth/login")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "1" {
+		t.Errorf("second login: %d %v", w.Code, w.Header())
+	}
+
+	if w := do("GET", "/api/workouts"); w.Code != http.StatusOK || w.Header().Get("X-RateLimit-Limit") != "5" {
+		t.Errorf("general route should use its own bucket: %d %v", w.Code, w.Header())
+	}
+	if w := do("GET", "/health"); w.Header().Get("X-RateLimit-Limit") != "" {
+		t.Error("unmatched routes should not be limited")
+	}
+}
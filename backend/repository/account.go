@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrUserNotFound is returned when a user to delete or update does not exist
+var ErrUserNotFound = errors.New("user not found")
+
+// accountDeletes removes everything tied to a user, children before parents.
+// Each statement takes the user ID as its only parameter, written as $1.
+var accountDeletes = []string{
+	`DELETE FROM exercise_sets WHERE session_exercise_id IN (
+		SELECT se.id FROM session_exercises se JOIN workout_sessions ws ON se.session_id = ws.id WHERE ws.user_id = $1)`,
+	`DELETE FROM session_exercises WHERE session_id IN (SELECT id FROM workout_sessions WHERE user_id = $1)`,
+	`DELETE FROM workout_sessions WHERE user_id = $1`,
+	`DELETE FROM routine_workouts WHERE routine_id IN (SELECT id FROM routines WHERE user_id = $1)`,
+	`DELETE FROM routines WHERE user_id = $1`,
+	`DELETE FROM program_enrollments WHERE user_id = $1`,
+	`DELETE FROM program_workouts WHERE program_id IN (SELECT id FROM programs WHERE user_id = $1)`,
+	`DELETE FROM program_weeks WHERE program_id IN (SELECT id FROM programs WHERE user_id = $1)`,
+	`DELETE FROM programs WHERE user_id = $1`,
+	`DELETE FROM progression_rules WHERE user_id = $1`,
+	`DELETE FROM exercises WHERE workout_id IN (SELECT id FROM workouts WHERE user_id = $1)`,
+	`DELETE FROM workout_tags WHERE workout_id IN (SELECT id FROM workouts WHERE user_id = $1)`,
+	`DELETE FROM scheduled_workouts WHERE user_id = $1`,
+	`DELETE FROM workouts WHERE user_id = $1`,
+	`DELETE FROM exercise_templates WHERE user_id = $1`,
+	`DELETE FROM workout_template_exercises WHERE template_id IN (SELECT id FROM workout_templates WHERE user_id = $1)`,
+	`DELETE FROM workout_templates WHERE user_id = $1`,
+	`DELETE FROM dino_game_scores WHERE user_id = $1`,
+	`DELETE FROM injuries WHERE user_id = $1`,
+	`DELETE FROM user_preferences WHERE user_id = $1`,
+	`DELETE FROM user_alerts WHERE user_id = $1`,
+	`DELETE FROM audit_log WHERE user_id = $1`,
+	`DELETE FROM template_recommendations WHERE user_id = $1`,
+	`DELETE FROM deprecated_endpoint_usage WHERE user_id = $1`,
+	`DELETE FROM password_reset_tokens WHERE user_id = $1`,
+	`DELETE FROM password_history WHERE user_id = $1`,
+	`DELETE FROM pending_email_changes WHERE user_id = $1`,
+	`DELETE FROM oauth_identities WHERE user_id = $1`,
+	`DELETE FROM webauthn_credentials WHERE user_id = $1`,
+	`DELETE FROM webauthn_challenges WHERE user_id = $1`,
+	`DELETE FROM api_keys WHERE user_id = $1`,
+	`DELETE FROM revoked_tokens WHERE user_id = $1`,
+	`DELETE FROM user_sessions WHERE user_id = $1`,
+	`DELETE FROM user_token_cutoffs WHERE user_id = $1`,
+	`DELETE FROM retention_warnings WHERE user_id = $1`,
+	`DELETE FROM partner_sessions WHERE host_id = $1`,
+	`DELETE FROM partner_sessions WHERE partner_id = $1`,
+	`DELETE FROM guest_accounts WHERE user_id = $1`,
+	`DELETE FROM login_events WHERE user_id = $1`,
+	`DELETE FROM hard_delete_confirmations WHERE user_id = $1`,
+	`DELETE FROM users WHERE id = $1`,
+}
+
+// DeleteAccount permanently deletes a user and all of their data in one transaction.
+// Outstanding tokens stop working because the revocation check rejects unknown users.
+// Accounts under legal hold are kept and ErrLegalHold is returned.
+func (r *UserRepository) DeleteAccount(ctx context.Context, userID string) error {
+	if r.useSQLite {
+		return r.deleteAccountSQLite(ctx, userID)
+	}
+	return r.deleteAccountPostgres(ctx, userID)
+}
+
+func (r *UserRepository) deleteAccountPostgres(ctx context.Context, userID string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var email string
+	var hold bool
+	err = tx.QueryRow(ctx, `SELECT email, legal_hold FROM users WHERE id = $1 FOR UPDATE`, userID).Scan(&email, &hold)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrUserNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if hold {
+		return ErrLegalHold
+	}
+	for _, stmt := range accountDeletes {
+		if _, err := tx.Exec(ctx, stmt, userID); err != nil {
+			return fmt.Errorf("failed to delete account data: %w", err)
+		}
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM login_attempts WHERE attempt_key = $1`, LoginAccountKey(email)); err != nil {
+		return fmt.Errorf("failed to delete account data: %w", err)
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *UserRepository) deleteAccountSQLite(ctx context.Context, userID string) error {
+	tx, err := r.sqlite.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var email string
+	var hold bool
+	err = tx.QueryRowContext(ctx, `SELECT email, legal_hold FROM users WHERE id = ?`, userID).Scan(&email, &hold)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrUserNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if hold {
+		return ErrLegalHold
+	}
+	for _, stmt := range accountDeletes {
+		if _, err := tx.ExecContext(ctx, rebind(stmt, 1), userID); err != nil {
+			return fmt.Errorf("failed to delete account data: %w", err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM login_attempts WHERE attempt_key = ?`, LoginAccountKey(email)); err != nil {
+		return fmt.Errorf("failed to delete account data: %w", err)
+	}
+	return tx.Commit()
+}
@@ -3,8 +3,6 @@ package repository
 import (
 	"context"
 	"database/sql"
-
-	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // AdminStats holds aggregate statistics for the admin panel
@@ -17,13 +15,13 @@ type AdminStats struct {
 
 // AdminRepository provides admin-only data access
 type AdminRepository struct {
-	db        *pgxpool.Pool
+	db        Pool
 	sqlite    *sql.DB
 	useSQLite bool
 }
 
 // NewAdminRepository creates a new admin repository
-func NewAdminRepository(db *pgxpool.Pool, sqlite *sql.DB, useSQLite bool) *AdminRepository {
+func NewAdminRepository(db Pool, sqlite *sql.DB, useSQLite bool) *AdminRepository {
 	return &AdminRepository{db: db, sqlite: sqlite, useSQLite: useSQLite}
 }
 
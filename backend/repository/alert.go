@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"liftoff/backend/ids"
+	"liftoff/backend/models"
+)
+
+// ErrAlertNotFound is returned when an alert does not exist or belongs to another user
+var ErrAlertNotFound = errors.New("alert not found or access denied")
+
+// AlertRepository stores in-app alerts. Times are unix seconds.
+type AlertRepository struct {
+	db        Pool
+	sqlite    *sql.DB
+	useSQLite bool
+}
+
+// NewAlertRepository creates a new alert repository
+func NewAlertRepository(db Pool, sqlite *sql.DB, useSQLite bool) *AlertRepository {
+	if useSQLite {
+		return &AlertRepository{db: nil, sqlite: sqlite, useSQLite: true}
+	}
+	return &AlertRepository{db: db, sqlite: nil, useSQLite: false}
+}
+
+// CreateAlert records a new alert for the user
+func (r *AlertRepository) CreateAlert(ctx context.Context, userID, kind, message string) (*models.Alert, error) {
+	alert := &models.Alert{
+		ID:        ids.New(),
+		UserID:    userID,
+		Kind:      kind,
+		Message:   message,
+		CreatedAt: time.Unix(time.Now().Unix(), 0),
+	}
+	var err error
+	if r.useSQLite {
+		_, err = r.sqlite.ExecContext(ctx, `
+			INSERT INTO user_alerts (id, user_id, kind, message, created_at) VALUES (?, ?, ?, ?, ?)`,
+			alert.ID, userID, kind, message, alert.CreatedAt.Unix())
+	} else {
+		_, err = r.db.Exec(ctx, `
+			INSERT INTO user_alerts (id, user_id, kind, message, created_at) VALUES ($1, $2, $3, $4, $5)`,
+			alert.ID, userID, kind, message, alert.CreatedAt.Unix())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create alert: %w", err)
+	}
+	return alert, nil
+}
+
+// HasAlertSince reports whether an alert of kind was raised for the user after since,
+// dismissed or not, so the same condition is not reported repeatedly
+func (r *AlertRepository) HasAlertSince(ctx context.Context, userID, kind string, since time.Time) (bool, error) {
+	var exists bool
+	var err error
+	if r.useSQLite {
+		err = r.sqlite.QueryRowContext(ctx,
+			`SELECT EXISTS(SELECT 1 FROM user_alerts WHERE user_id = ? AND kind = ? AND created_at >= ?)`,
+			userID, kind, since.Unix()).Scan(&exists)
+	} else {
+		err = r.db.QueryRow(ctx,
+			`SELECT EXISTS(SELECT 1 FROM user_alerts WHERE user_id = $1 AND kind = $2 AND created_at >= $3)`,
+			userID, kind, since.Unix()).Scan(&exists)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check alerts: %w", err)
+	}
+	return exists, nil
+}
+
+// ListAlerts returns the user's alerts, newest first. Dismissed alerts are only included when all is set.
+func (r *AlertRepository) ListAlerts(ctx context.Context, userID string, all bool) ([]*models.Alert, error) {
+	alerts := []*models.Alert{}
+	scan := func(scan func(...interface{}) error) error {
+		var alert models.Alert
+		var createdAt int64
+		var dismissedAt sql.NullInt64
+		if err := scan(&alert.ID, &alert.UserID, &alert.Kind, &alert.Message, &createdAt, &dismissedAt); err != nil {
+			return fmt.Errorf("failed to scan alert: %w", err)
+		}
+		alert.CreatedAt = time.Unix(createdAt, 0)
+		if dismissedAt.Valid {
+			t := time.Unix(dismissedAt.Int64, 0)
+			alert.DismissedAt = &t
+		}
+		alerts = append(alerts, &alert)
+		return nil
+	}
+
+	const query = `SELECT id, user_id, kind, message, created_at, dismissed_at FROM user_alerts
+		WHERE user_id = %s AND (%s OR dismissed_at IS NULL) ORDER BY created_at DESC, id`
+	if r.useSQLite {
+		rows, err := r.sqlite.QueryContext(ctx, fmt.Sprintf(query, "?", "?"), userID, all)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list alerts: %w", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			if err := scan(rows.Scan); err != nil {
+				return nil, err
+			}
+		}
+		return alerts, rows.Err()
+	}
+
+	rows, err := r.db.Query(ctx, fmt.Sprintf(query, "$1", "$2"), userID, all)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list alerts: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := scan(rows.Scan); err != nil {
+			return nil, err
+		}
+	}
+	return alerts, rows.Err()
+}
+
+// DismissAlert hides one of the user's alerts
+func (r *AlertRepository) DismissAlert(ctx context.Context, userID, id string) error {
+	var affected int64
+	now := time.Now().Unix()
+	if r.useSQLite {
+		result, err := r.sqlite.ExecContext(ctx,
+			`UPDATE user_alerts SET dismissed_at = COALESCE(dismissed_at, ?) WHERE id = ? AND user_id = ?`,
+			now, id, userID)
+		if err != nil {
+			return fmt.Errorf("failed to dismiss alert: %w", err)
+		}
+		affected, _ = result.RowsAffected()
+	} else {
+		tag, err := r.db.Exec(ctx,
+			`UPDATE user_alerts SET dismissed_at = COALESCE(dismissed_at, $1) WHERE id = $2 AND user_id = $3`,
+			now, id, userID)
+		if err != nil {
+			return fmt.Errorf("failed to dismiss alert: %w", err)
+		}
+		affected = tag.RowsAffected()
+	}
+	if affected == 0 {
+		return ErrAlertNotFound
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"liftoff/backend/models"
+)
+
+// Postgres materialized views behind analytics reads (see migrations/033_analytics_views.sql)
+const (
+	viewDailyExerciseVolume   = "daily_exercise_volume"
+	viewWeeklyTrainingSummary = "weekly_training_summary"
+)
+
+// analyticsRefreshedAt selects when the view named by $1 was last refreshed, or
+// -infinity if never, so reads fall back to the tables entirely
+const analyticsRefreshedAt = `(SELECT COALESCE(MAX(refreshed_at), '-infinity') FROM analytics_view_refreshes WHERE view_name = $1)`
+
+// RefreshAnalyticsViews refreshes the analytics materialized views. It does nothing
+// on SQLite or when connected to a read-only standby, and skips a view another
+// instance is already refreshing. Refreshes run CONCURRENTLY so reads are never
+// blocked. Intended to run from the jobs scheduler.
+func (r *SessionRepository) RefreshAnalyticsViews(ctx context.Context) error {
+	if r.useSQLite {
+		return nil
+	}
+	var standby bool
+	if err := r.db.QueryRow(ctx, `SELECT pg_is_in_recovery()`).Scan(&standby); err != nil {
+		return fmt.Errorf("failed to check recovery state: %w", err)
+	}
+	if standby {
+		return nil
+	}
+	for _, view := range []string{viewDailyExerciseVolume, viewWeeklyTrainingSummary} {
+		if err := r.refreshAnalyticsView(ctx, view); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *SessionRepository) refreshAnalyticsView(ctx context.Context, view string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// LOCALTIMESTAMP is the transaction start, so everything before it is in the refresh
+	var locked bool
+	var refreshedAt time.Time
+	err = tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock(hashtext($1)), LOCALTIMESTAMP`, "analytics:"+view).Scan(&locked, &refreshedAt)
+	if err != nil {
+		return fmt.Errorf("failed to lock %s: %w", view, err)
+	}
+	if !locked {
+		return nil
+	}
+	if _, err := tx.Exec(ctx, `REFRESH MATERIALIZED VIEW CONCURRENTLY `+view); err != nil {
+		return fmt.Errorf("failed to refresh %s: %w", view, err)
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO analytics_view_refreshes (view_name, refreshed_at) VALUES ($1, $2)
+		ON CONFLICT (view_name) DO UPDATE SET refreshed_at = excluded.refreshed_at`,
+		view, refreshedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record refresh of %s: %w", view, err)
+	}
+	return tx.Commit(ctx)
+}
+
+// GetWeeklySummaries totals the user's finished sessions per calendar week (starting
+// Monday) from the week containing since, oldest first. On Postgres, weeks before
+// the last refresh come from the weekly_training_summary view.
+func (r *SessionRepository) GetWeeklySummaries(ctx context.Context, userID string, since time.Time) ([]models.WeeklyLoad, error) {
+	var weeks []models.WeeklyLoad
+	scan := func(scan func(...interface{}) error) error {
+		var w models.WeeklyLoad
+		var weekStart interface{}
+		if err := scan(&weekStart, &w.Sessions, &w.Sets, &w.Tonnage, &w.Minutes); err != nil {
+			return fmt.Errorf("failed to scan weekly summary: %w", err)
+		}
+		switch v := weekStart.(type) {
+		case time.Time:
+			w.WeekStart = v
+		case string:
+			t, err := time.Parse("2006-01-02", v)
+			if err != nil {
+				return fmt.Errorf("failed to parse week start: %w", err)
+			}
+			w.WeekStart = t
+		}
+		weeks = append(weeks, w)
+		return nil
+	}
+
+	if r.useSQLite {
+		// date(x, 'weekday 0', '-6 days') is the Monday on or before x
+		rows, err := r.sqlite.QueryContext(ctx, `
+			SELECT week_start, COUNT(*), SUM(sets), SUM(tonnage), SUM(minutes)
+			FROM (
+				SELECT DATE(ws.ended_at, 'weekday 0', '-6 days') AS week_start,
+					COUNT(es.id) AS sets,
+					COALESCE(SUM(es.weight * es.reps + es.segment_volume + es.side_volume), 0) AS tonnage,
+					(julianday(ws.ended_at) - julianday(ws.started_at)) * 1440 AS minutes
+				FROM workout_sessions ws
+				LEFT JOIN session_exercises se ON se.session_id = ws.id
+				LEFT JOIN exercise_sets es ON es.session_exercise_id = se.id AND es.completed = 1 AND es.set_type <> 'warmup'
+				WHERE ws.user_id = ? AND ws.ended_at IS NOT NULL
+				GROUP BY ws.id
+			) s
+			WHERE week_start >= DATE(?, 'weekday 0', '-6 days')
+			GROUP BY week_start
+			ORDER BY week_start`,
+			userID, since)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get weekly summaries: %w", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			if err := scan(rows.Scan); err != nil {
+				return nil, err
+			}
+		}
+		return weeks, rows.Err()
+	}
+
+	rows, err := r.db.Query(ctx, `
+		WITH cutoff AS (SELECT DATE_TRUNC('week', `+analyticsRefreshedAt+`)::date AS week)
+		SELECT week_start, sessions::int, sets::int, tonnage, minutes
+		FROM weekly_training_summary
+		WHERE user_id = $2 AND week_start >= DATE_TRUNC('week', $3::timestamp)::date
+			AND week_start < (SELECT week FROM cutoff)
+		UNION ALL
+		SELECT week_start, COUNT(*)::int, SUM(sets)::int, SUM(tonnage), SUM(minutes)
+		FROM (
+			SELECT DATE_TRUNC('week', ws.ended_at)::date AS week_start,
+				COUNT(es.id) AS sets,
+				COALESCE(SUM(es.weight * es.reps + es.segment_volume + es.side_volume), 0)::float8 AS tonnage,
+				EXTRACT(EPOCH FROM ws.ended_at - ws.started_at)::float8 / 60 AS minutes
+			FROM workout_sessions ws
+			LEFT JOIN session_exercises se ON se.session_id = ws.id
+			LEFT JOIN exercise_sets es ON es.session_exercise_id = se.id AND es.completed = true AND es.set_type <> 'warmup'
+			WHERE ws.user_id = $4 AND ws.ended_at >= GREATEST(DATE_TRUNC('week', $5::timestamp), (SELECT week FROM cutoff))
+			GROUP BY ws.id
+		) s
+		GROUP BY week_start
+		ORDER BY week_start`,
+		viewWeeklyTrainingSummary, userID, since, userID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get weekly summaries: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := scan(rows.Scan); err != nil {
+			return nil, err
+		}
+	}
+	return weeks, rows.Err()
+}
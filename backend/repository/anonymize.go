@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"liftoff/backend/models"
+)
+
+// anonymizeDeletes are tables of credentials, tokens and request logs, emptied
+// outright because nothing in them is needed to reproduce training-data bugs
+var anonymizeDeletes = []string{
+	"password_reset_tokens",
+	"pending_email_changes",
+	"password_history",
+	"oauth_identities",
+	"webauthn_credentials",
+	"webauthn_challenges",
+	"api_keys",
+	"revoked_tokens",
+	"user_token_cutoffs",
+	"user_sessions",
+	"login_attempts",
+	"deprecated_endpoint_usage",
+}
+
+// anonymizeTx abstracts the running transaction; queries are written with $n placeholders.
+// query collects every row before returning, so statements can follow on the same connection.
+type anonymizeTx struct {
+	exec  func(query string, args ...interface{}) (int64, error)
+	query func(query string, scan func(func(...interface{}) error) error) error
+}
+
+// Anonymize scrambles personal data across the whole database in one transaction, for
+// copies of production used in staging. Emails become userN@example.invalid and every
+// password is replaced with passwordHash (empty disables password sign-in). Notes, injury
+// names and session metadata are scrambled, and credential, token and request-log tables emptied.
+// Workouts, sets, weights and dates are untouched so the data keeps its statistical shape.
+func (r *AdminRepository) Anonymize(ctx context.Context, s *models.Scrambler, passwordHash string) (*models.AnonymizeReport, error) {
+	if r.useSQLite {
+		tx, err := r.sqlite.BeginTx(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		defer tx.Rollback()
+		report, err := anonymize(anonymizeTx{
+			exec: func(query string, args ...interface{}) (int64, error) {
+				res, err := tx.ExecContext(ctx, rebind(query, len(args)), args...)
+				if err != nil {
+					return 0, err
+				}
+				return res.RowsAffected()
+			},
+			query: func(query string, scan func(func(...interface{}) error) error) error {
+				rows, err := tx.QueryContext(ctx, query)
+				if err != nil {
+					return err
+				}
+				defer rows.Close()
+				for rows.Next() {
+					if err := scan(rows.Scan); err != nil {
+						return err
+					}
+				}
+				return rows.Err()
+			},
+		}, s, passwordHash)
+		if err != nil {
+			return nil, err
+		}
+		return report, tx.Commit()
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	report, err := anonymize(anonymizeTx{
+		exec: func(query string, args ...interface{}) (int64, error) {
+			tag, err := tx.Exec(ctx, query, args...)
+			return tag.RowsAffected(), err
+		},
+		query: func(query string, scan func(func(...interface{}) error) error) error {
+			rows, err := tx.Query(ctx, query)
+			if err != nil {
+				return err
+			}
+			defer rows.Close()
+			for rows.Next() {
+				if err := scan(rows.Scan); err != nil {
+					return err
+				}
+			}
+			return rows.Err()
+		},
+	}, s, passwordHash)
+	if err != nil {
+		return nil, err
+	}
+	return report, tx.Commit(ctx)
+}
+
+func anonymize(tx anonymizeTx, s *models.Scrambler, passwordHash string) (*models.AnonymizeReport, error) {
+	report := &models.AnonymizeReport{Deleted: map[string]int64{}}
+
+	// Emails are numbered in sign-up order. The interim address keeps every row unique
+	// while the final ones are assigned, even when this runs on an anonymized copy.
+	var userIDs []string
+	err := tx.query(`SELECT id FROM users ORDER BY created_at, id`, func(scan func(...interface{}) error) error {
+		var id string
+		if err := scan(&id); err != nil {
+			return err
+		}
+		userIDs = append(userIDs, id)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	if _, err := tx.exec(`UPDATE users SET email = id || '@anonymizing.invalid', password_hash = $1`, passwordHash); err != nil {
+		return nil, fmt.Errorf("failed to anonymize users: %w", err)
+	}
+	for i, id := range userIDs {
+		if _, err := tx.exec(`UPDATE users SET email = $1 WHERE id = $2`, fmt.Sprintf("user%d@example.invalid", i+1), id); err != nil {
+			return nil, fmt.Errorf("failed to anonymize user: %w", err)
+		}
+	}
+	report.Users = int64(len(userIDs))
+
+	// Injury names are free text too, and health data
+	for _, col := range []struct{ table, column string }{
+		{"exercise_sets", "notes"},
+		{"workouts", "notes"},
+		{"injuries", "notes"},
+		{"injuries", "name"},
+	} {
+		type note struct{ id, text string }
+		var notes []note
+		err := tx.query(fmt.Sprintf(`SELECT id, %[2]s FROM %[1]s WHERE %[2]s IS NOT NULL AND %[2]s != ''`, col.table, col.column), func(scan func(...interface{}) error) error {
+			var n note
+			if err := scan(&n.id, &n.text); err != nil {
+				return err
+			}
+			notes = append(notes, n)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s.%s: %w", col.table, col.column, err)
+		}
+		for _, n := range notes {
+			if _, err := tx.exec(fmt.Sprintf(`UPDATE %s SET %s = $1 WHERE id = $2`, col.table, col.column), s.Text(n.text), n.id); err != nil {
+				return nil, fmt.Errorf("failed to scramble %s.%s: %w", col.table, col.column, err)
+			}
+		}
+		report.Notes += int64(len(notes))
+	}
+
+	// Sessions are visited in order so gyms and partners are numbered as first seen
+	type session struct {
+		id       string
+		metadata models.SessionMetadata
+	}
+	var sessions []session
+	err = tx.query(`SELECT id, metadata FROM workout_sessions WHERE metadata IS NOT NULL ORDER BY started_at, id`, func(scan func(...interface{}) error) error {
+		var ses session
+		if err := scan(&ses.id, &ses.metadata); err != nil {
+			return err
+		}
+		sessions = append(sessions, ses)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list session metadata: %w", err)
+	}
+	for _, ses := range sessions {
+		if _, err := tx.exec(`UPDATE workout_sessions SET metadata = $1 WHERE id = $2`, s.Metadata(ses.metadata), ses.id); err != nil {
+			return nil, fmt.Errorf("failed to scramble session metadata: %w", err)
+		}
+	}
+	report.Sessions = int64(len(sessions))
+
+	for _, table := range anonymizeDeletes {
+		n, err := tx.exec(`DELETE FROM ` + table)
+		if err != nil {
+			return nil, fmt.Errorf("failed to empty %s: %w", table, err)
+		}
+		if n > 0 {
+			report.Deleted[table] = n
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/ids"
+	"liftoff/backend/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrAPIKeyNotFound is returned when an API key does not exist or belongs to another user
+var ErrAPIKeyNotFound = errors.New("api key not found or access denied")
+
+// apiKeyTouchInterval limits how often last_used_at is written for a busy key
+const apiKeyTouchInterval = time.Minute
+
+// APIKeyRepository stores personal API keys by hash. Times are unix seconds.
+type APIKeyRepository struct {
+	db        Pool
+	sqlite    *sql.DB
+	useSQLite bool
+}
+
+// NewAPIKeyRepository creates a new API key repository
+func NewAPIKeyRepository(db Pool, sqlite *sql.DB, useSQLite bool) *APIKeyRepository {
+	if useSQLite {
+		return &APIKeyRepository{db: nil, sqlite: sqlite, useSQLite: true}
+	}
+	return &APIKeyRepository{db: db, sqlite: nil, useSQLite: false}
+}
+
+// CreateAPIKey stores a new key for the user
+func (r *APIKeyRepository) CreateAPIKey(ctx context.Context, userID, name, prefix, keyHash string) (*models.APIKey, error) {
+	key := &models.APIKey{
+		ID:        ids.New(),
+		UserID:    userID,
+		Name:      name,
+		Prefix:    prefix,
+		CreatedAt: time.Unix(time.Now().Unix(), 0),
+	}
+	var err error
+	if r.useSQLite {
+		_, err = r.sqlite.ExecContext(ctx, `
+			INSERT INTO api_keys (id, user_id, name, prefix, key_hash, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+			key.ID, userID, name, prefix, keyHash, key.CreatedAt.Unix())
+	} else {
+		_, err = r.db.Exec(ctx, `
+			INSERT INTO api_keys (id, user_id, name, prefix, key_hash, created_at) VALUES ($1, $2, $3, $4, $5, $6)`,
+			key.ID, userID, name, prefix, keyHash, key.CreatedAt.Unix())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create api key: %w", err)
+	}
+	return key, nil
+}
+
+// ListAPIKeys returns the user's keys, newest first
+func (r *APIKeyRepository) ListAPIKeys(ctx context.Context, userID string) ([]*models.APIKey, error) {
+	keys := []*models.APIKey{}
+	scan := func(scan func(...interface{}) error) error {
+		var key models.APIKey
+		var createdAt int64
+		var lastUsedAt sql.NullInt64
+		if err := scan(&key.ID, &key.UserID, &key.Name, &key.Prefix, &createdAt, &lastUsedAt); err != nil {
+			return fmt.Errorf("failed to scan api key: %w", err)
+		}
+		key.CreatedAt = time.Unix(createdAt, 0)
+		if lastUsedAt.Valid {
+			t := time.Unix(lastUsedAt.Int64, 0)
+			key.LastUsedAt = &t
+		}
+		keys = append(keys, &key)
+		return nil
+	}
+
+	const query = `SELECT id, user_id, name, prefix, created_at, last_used_at FROM api_keys WHERE user_id = %s ORDER BY created_at DESC, id`
+	if r.useSQLite {
+		rows, err := r.sqlite.QueryContext(ctx, fmt.Sprintf(query, "?"), userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list api keys: %w", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			if err := scan(rows.Scan); err != nil {
+				return nil, err
+			}
+		}
+		return keys, rows.Err()
+	}
+
+	rows, err := r.db.Query(ctx, fmt.Sprintf(query, "$1"), userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := scan(rows.Scan); err != nil {
+			return nil, err
+		}
+	}
+	return keys, rows.Err()
+}
+
+// CountAPIKeys returns how many keys the user has
+func (r *APIKeyRepository) CountAPIKeys(ctx context.Context, userID string) (int, error) {
+	var n int
+	var err error
+	if r.useSQLite {
+		err = r.sqlite.QueryRowContext(ctx, `SELECT COUNT(*) FROM api_keys WHERE user_id = ?`, userID).Scan(&n)
+	} else {
+		err = r.db.QueryRow(ctx, `SELECT COUNT(*) FROM api_keys WHERE user_id = $1`, userID).Scan(&n)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to count api keys: %w", err)
+	}
+	return n, nil
+}
+
+// DeleteAPIKey revokes one of the user's keys
+func (r *APIKeyRepository) DeleteAPIKey(ctx context.Context, userID, id string) error {
+	var affected int64
+	if r.useSQLite {
+		result, err := r.sqlite.ExecContext(ctx, `DELETE FROM api_keys WHERE id = ? AND user_id = ?`, id, userID)
+		if err != nil {
+			return fmt.Errorf("failed to delete api key: %w", err)
+		}
+		affected, _ = result.RowsAffected()
+	} else {
+		tag, err := r.db.Exec(ctx, `DELETE FROM api_keys WHERE id = $1 AND user_id = $2`, id, userID)
+		if err != nil {
+			return fmt.Errorf("failed to delete api key: %w", err)
+		}
+		affected = tag.RowsAffected()
+	}
+	if affected == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// ResolveAPIKey implements auth.APIKeyResolver, recording when the key was last used
+func (r *APIKeyRepository) ResolveAPIKey(ctx context.Context, keyHash string) (*auth.APIKeyOwner, error) {
+	var owner auth.APIKeyOwner
+	var status string
+	var err error
+	if r.useSQLite {
+		err = r.sqlite.QueryRowContext(ctx, `
+			SELECT k.id, k.user_id, u.email, u.status FROM api_keys k JOIN users u ON u.id = k.user_id
+			WHERE k.key_hash = ?`, keyHash).Scan(&owner.KeyID, &owner.UserID, &owner.Email, &status)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, auth.ErrInvalidAPIKey
+		}
+	} else {
+		err = r.db.QueryRow(ctx, `
+			SELECT k.id, k.user_id, u.email, u.status FROM api_keys k JOIN users u ON u.id = k.user_id
+			WHERE k.key_hash = $1`, keyHash).Scan(&owner.KeyID, &owner.UserID, &owner.Email, &status)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, auth.ErrInvalidAPIKey
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve api key: %w", err)
+	}
+	if status == models.UserStatusSuspended {
+		return nil, auth.ErrAccountSuspended
+	}
+
+	now := time.Now().Unix()
+	stale := now - int64(apiKeyTouchInterval.Seconds())
+	if r.useSQLite {
+		_, err = r.sqlite.ExecContext(ctx, `
+			UPDATE api_keys SET last_used_at = ? WHERE id = ? AND (last_used_at IS NULL OR last_used_at < ?)`,
+			now, owner.KeyID, stale)
+	} else {
+		_, err = r.db.Exec(ctx, `
+			UPDATE api_keys SET last_used_at = $1 WHERE id = $2 AND (last_used_at IS NULL OR last_used_at < $3)`,
+			now, owner.KeyID, stale)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to record api key use: %w", err)
+	}
+	return &owner, nil
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"liftoff/backend/ids"
+	"liftoff/backend/models"
+)
+
+// insertAuditEntry is shared by repositories that record an audit entry in the
+// same transaction as the change it describes
+const insertAuditEntry = `INSERT INTO audit_log (id, user_id, actor_id, action, detail, created_at) VALUES ($1, $2, $3, $4, $5, $6)`
+
+// newAuditEntry builds an entry for insertAuditEntry, marshaling detail to JSON
+func newAuditEntry(userID, actorID, action string, detail interface{}) (*models.AuditEntry, []interface{}, error) {
+	raw, err := json.Marshal(detail)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode audit detail: %w", err)
+	}
+	entry := &models.AuditEntry{
+		ID:        ids.New(),
+		UserID:    userID,
+		ActorID:   actorID,
+		Action:    action,
+		Detail:    raw,
+		CreatedAt: time.Unix(time.Now().Unix(), 0),
+	}
+	return entry, []interface{}{entry.ID, userID, actorID, action, string(raw), entry.CreatedAt.Unix()}, nil
+}
+
+// AuditRepository reads the audit log. Times are unix seconds.
+type AuditRepository struct {
+	db        Pool
+	sqlite    *sql.DB
+	useSQLite bool
+}
+
+// NewAuditRepository creates a new audit repository
+func NewAuditRepository(db Pool, sqlite *sql.DB, useSQLite bool) *AuditRepository {
+	if useSQLite {
+		return &AuditRepository{db: nil, sqlite: sqlite, useSQLite: true}
+	}
+	return &AuditRepository{db: db, sqlite: nil, useSQLite: false}
+}
+
+// ListForUser returns the changes made to a user's history, newest first. A non-empty
+// before continues after the entry with that ID; entries are keyed by creation time
+// then ID, since entries from before IDs were time-ordered have random IDs.
+func (r *AuditRepository) ListForUser(ctx context.Context, userID, before string, limit int) ([]*models.AuditEntry, error) {
+	entries := []*models.AuditEntry{}
+	err := eachRow(ctx, r.db, r.sqlite, r.useSQLite, `
+		SELECT id, user_id, actor_id, action, detail, created_at FROM audit_log
+		WHERE user_id = $1 AND ($2 = '' OR (created_at, id) < (SELECT created_at, id FROM audit_log WHERE id = $3 AND user_id = $4))
+		ORDER BY created_at DESC, id DESC LIMIT $5`,
+		[]interface{}{userID, before, before, userID, limit}, func(scan func(...interface{}) error) error {
+			var e models.AuditEntry
+			var detail string
+			var createdAt int64
+			if err := scan(&e.ID, &e.UserID, &e.ActorID, &e.Action, &detail, &createdAt); err != nil {
+				return err
+			}
+			e.Detail = json.RawMessage(detail)
+			e.CreatedAt = time.Unix(createdAt, 0)
+			entries = append(entries, &e)
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"liftoff/backend/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// DeprecationRepository stores usage of deprecated API routes
+type DeprecationRepository struct {
+	db        Pool
+	sqlite    *sql.DB
+	useSQLite bool
+}
+
+// NewDeprecationRepository creates a new deprecation repository
+func NewDeprecationRepository(db Pool, sqlite *sql.DB, useSQLite bool) *DeprecationRepository {
+	if useSQLite {
+		return &DeprecationRepository{db: nil, sqlite: sqlite, useSQLite: true}
+	}
+	return &DeprecationRepository{db: db, sqlite: nil, useSQLite: false}
+}
+
+// RecordDeprecatedUsage adds buffered call counts to the stored totals
+func (r *DeprecationRepository) RecordDeprecatedUsage(ctx context.Context, usage []*models.DeprecatedUsage) error {
+	if r.useSQLite {
+		return r.recordDeprecatedUsageSQLite(ctx, usage)
+	}
+	return r.recordDeprecatedUsagePostgres(ctx, usage)
+}
+
+func (r *DeprecationRepository) recordDeprecatedUsagePostgres(ctx context.Context, usage []*models.DeprecatedUsage) error {
+	query := `
+		INSERT INTO deprecated_endpoint_usage (method, path, user_id, token_id, user_agent, call_count, first_seen, last_seen)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (method, path, user_id, token_id) DO UPDATE SET
+			call_count = deprecated_endpoint_usage.call_count + EXCLUDED.call_count,
+			user_agent = EXCLUDED.user_agent,
+			last_seen = EXCLUDED.last_seen
+	`
+	batch := &pgx.Batch{}
+	for _, u := range usage {
+		batch.Queue(query, u.Method, u.Path, u.UserID, u.TokenID, u.UserAgent, u.Count, u.FirstSeen, u.LastSeen)
+	}
+	if err := r.db.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to record deprecated usage: %w", err)
+	}
+	return nil
+}
+
+func (r *DeprecationRepository) recordDeprecatedUsageSQLite(ctx context.Context, usage []*models.DeprecatedUsage) error {
+	tx, err := r.sqlite.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO deprecated_endpoint_usage (method, path, user_id, token_id, user_agent, call_count, first_seen, last_seen)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (method, path, user_id, token_id) DO UPDATE SET
+			call_count = deprecated_endpoint_usage.call_count + excluded.call_count,
+			user_agent = excluded.user_agent,
+			last_seen = excluded.last_seen
+	`
+	for _, u := range usage {
+		if _, err := tx.ExecContext(ctx, query, u.Method, u.Path, u.UserID, u.TokenID, u.UserAgent, u.Count, u.FirstSeen, u.LastSeen); err != nil {
+			return fmt.Errorf("failed to record deprecated usage: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// GetDeprecatedUsage returns stored usage with caller emails, most recent first
+func (r *DeprecationRepository) GetDeprecatedUsage(ctx context.Context) ([]*models.DeprecatedUsage, error) {
+	query := `
+		SELECT d.method, d.path, d.user_id, COALESCE(u.email, ''), d.token_id, d.user_agent,
+			d.call_count, d.first_seen, d.last_seen
+		FROM deprecated_endpoint_usage d
+		LEFT JOIN users u ON u.id = d.user_id
+		ORDER BY d.last_seen DESC
+	`
+
+	var usage []*models.DeprecatedUsage
+	scan := func(scan func(...interface{}) error) error {
+		var u models.DeprecatedUsage
+		if err := scan(&u.Method, &u.Path, &u.UserID, &u.UserEmail, &u.TokenID, &u.UserAgent,
+			&u.Count, &u.FirstSeen, &u.LastSeen); err != nil {
+			return fmt.Errorf("failed to scan deprecated usage: %w", err)
+		}
+		usage = append(usage, &u)
+		return nil
+	}
+
+	if r.useSQLite {
+		rows, err := r.sqlite.QueryContext(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get deprecated usage: %w", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			if err := scan(rows.Scan); err != nil {
+				return nil, err
+			}
+		}
+		return usage, rows.Err()
+	}
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deprecated usage: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := scan(rows.Scan); err != nil {
+			return nil, err
+		}
+	}
+	return usage, rows.Err()
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"liftoff/backend/ids"
+	"liftoff/backend/models"
+)
+
+// ErrInvalidExerciseGroup is returned when a group does not name at least two
+// distinct exercises of the workout
+var ErrInvalidExerciseGroup = errors.New("a group needs at least two different exercises from the workout")
+
+// ErrExerciseGroupNotFound is returned when a workout has no group with the given ID
+var ErrExerciseGroupNotFound = errors.New("exercise group not found")
+
+// GroupExercises makes the listed exercises a superset or circuit, performed in
+// the listed order, and returns the new group's ID. Exercises already in another
+// group leave it, and groups left with a single exercise are dissolved. The
+// members are moved together to where the first of them was.
+func (r *WorkoutRepository) GroupExercises(ctx context.Context, userID, workoutID, groupType string, exerciseIDs []string) (string, error) {
+	if err := r.checkWorkoutOwner(ctx, userID, workoutID); err != nil {
+		return "", err
+	}
+	current, err := r.GetExercisesByWorkout(ctx, workoutID)
+	if err != nil {
+		return "", err
+	}
+	byID := make(map[string]*models.Exercise, len(current))
+	for _, e := range current {
+		byID[e.ID] = e
+	}
+	if len(exerciseIDs) < 2 {
+		return "", ErrInvalidExerciseGroup
+	}
+	groupID := ids.New()
+	members := make([]*models.Exercise, 0, len(exerciseIDs))
+	for _, id := range exerciseIDs {
+		e, ok := byID[id]
+		if !ok || e.GroupID == groupID {
+			return "", ErrInvalidExerciseGroup
+		}
+		e.GroupID, e.GroupType = groupID, groupType
+		members = append(members, e)
+	}
+
+	// Members follow the listed order, starting where the earliest of them sits
+	ordered := make([]*models.Exercise, 0, len(current))
+	placed := false
+	for _, e := range current {
+		if e.GroupID != groupID {
+			ordered = append(ordered, e)
+		} else if !placed {
+			ordered = append(ordered, members...)
+			placed = true
+		}
+	}
+	dissolveSingletonGroups(ordered)
+	if err := r.saveExerciseLayout(ctx, workoutID, models.ContiguousGroups(ordered)); err != nil {
+		return "", err
+	}
+	return groupID, nil
+}
+
+// UngroupExercises dissolves a superset or circuit, keeping its exercises where they are
+func (r *WorkoutRepository) UngroupExercises(ctx context.Context, userID, workoutID, groupID string) error {
+	if err := r.checkWorkoutOwner(ctx, userID, workoutID); err != nil {
+		return err
+	}
+	var affected int64
+	if r.useSQLite {
+		result, err := r.sqlite.ExecContext(ctx, `UPDATE exercises SET group_id = '', group_type = '' WHERE workout_id = ? AND group_id = ? AND group_id <> ''`,
+			workoutID, groupID)
+		if err != nil {
+			return fmt.Errorf("failed to ungroup exercises: %w", err)
+		}
+		affected, _ = result.RowsAffected()
+	} else {
+		tag, err := r.db.Exec(ctx, `UPDATE exercises SET group_id = '', group_type = '' WHERE workout_id = $1 AND group_id = $2 AND group_id <> ''`,
+			workoutID, groupID)
+		if err != nil {
+			return fmt.Errorf("failed to ungroup exercises: %w", err)
+		}
+		affected = tag.RowsAffected()
+	}
+	if affected == 0 {
+		return ErrExerciseGroupNotFound
+	}
+	return nil
+}
+
+// dissolveSingletonGroups clears the group of any exercise that is the last one left in it
+func dissolveSingletonGroups(exercises []*models.Exercise) {
+	counts := make(map[string]int)
+	for _, e := range exercises {
+		counts[e.GroupID]++
+	}
+	for _, e := range exercises {
+		if e.GroupID != "" && counts[e.GroupID] < 2 {
+			e.GroupID, e.GroupType = "", ""
+		}
+	}
+}
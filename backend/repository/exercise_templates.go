@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"liftoff/backend/ids"
+	"liftoff/backend/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrExerciseTemplateNotFound is returned when a user-defined exercise template does
+// not exist or belongs to another user
+var ErrExerciseTemplateNotFound = errors.New("exercise template not found or access denied")
+
+// ErrExerciseTemplateExists is returned when a template's name is already taken by
+// the built-in library or another of the user's templates
+var ErrExerciseTemplateExists = errors.New("an exercise template with that name already exists")
+
+const exerciseTemplateColumns = `id, name, default_sets, default_reps, default_reps_min, default_reps_max, default_weight, mode, default_duration_seconds, default_distance_meters, default_rest_seconds,
+	primary_muscles, secondary_muscles, equipment, instructions, video_url, image_url`
+
+func scanExerciseTemplate(scan func(...interface{}) error) (*models.ExerciseTemplate, error) {
+	var t models.ExerciseTemplate
+	if err := scan(&t.ID, &t.Name, &t.DefaultSets, &t.DefaultReps, &t.DefaultRepsMin, &t.DefaultRepsMax, &t.DefaultWeight, &t.Mode, &t.DefaultDurationSeconds, &t.DefaultDistanceMeters, &t.DefaultRestSeconds,
+		&t.PrimaryMuscles, &t.SecondaryMuscles, &t.Equipment, &t.Instructions, &t.VideoURL, &t.ImageURL); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// GetExerciseTemplate returns one of the user's own exercise templates
+func (r *WorkoutRepository) GetExerciseTemplate(ctx context.Context, userID, id string) (*models.ExerciseTemplate, error) {
+	var t *models.ExerciseTemplate
+	var err error
+	if r.useSQLite {
+		row := r.sqlite.QueryRowContext(ctx, `SELECT `+exerciseTemplateColumns+` FROM exercise_templates WHERE id = ? AND user_id = ?`, id, userID)
+		t, err = scanExerciseTemplate(row.Scan)
+	} else {
+		row := r.db.QueryRow(ctx, `SELECT `+exerciseTemplateColumns+` FROM exercise_templates WHERE id = $1 AND user_id = $2`, id, userID)
+		t, err = scanExerciseTemplate(row.Scan)
+	}
+	if errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrExerciseTemplateNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exercise template: %w", err)
+	}
+	return t, nil
+}
+
+// getUserExerciseTemplates lists the user's own exercise templates by name
+func (r *WorkoutRepository) getUserExerciseTemplates(ctx context.Context, userID string) ([]*models.ExerciseTemplate, error) {
+	var templates []*models.ExerciseTemplate
+	add := func(scan func(...interface{}) error) error {
+		t, err := scanExerciseTemplate(scan)
+		if err != nil {
+			return fmt.Errorf("failed to scan exercise template: %w", err)
+		}
+		templates = append(templates, t)
+		return nil
+	}
+
+	if r.useSQLite {
+		rows, err := r.sqlite.QueryContext(ctx, `SELECT `+exerciseTemplateColumns+` FROM exercise_templates WHERE user_id = ? ORDER BY LOWER(name)`, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get exercise templates: %w", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			if err := add(rows.Scan); err != nil {
+				return nil, err
+			}
+		}
+		return templates, rows.Err()
+	}
+
+	rows, err := r.db.Query(ctx, `SELECT `+exerciseTemplateColumns+` FROM exercise_templates WHERE user_id = $1 ORDER BY LOWER(name)`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exercise templates: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := add(rows.Scan); err != nil {
+			return nil, err
+		}
+	}
+	return templates, rows.Err()
+}
+
+// checkExerciseTemplateName returns ErrExerciseTemplateExists when name matches,
+// ignoring case, a built-in template or one of the user's templates other than id
+func (r *WorkoutRepository) checkExerciseTemplateName(ctx context.Context, userID, id, name string) error {
+	if r.LibraryExerciseName(name) != "" {
+		return ErrExerciseTemplateExists
+	}
+	var taken int
+	var err error
+	if r.useSQLite {
+		err = r.sqlite.QueryRowContext(ctx, `SELECT COUNT(*) FROM exercise_templates WHERE user_id = ? AND LOWER(name) = LOWER(?) AND id <> ?`,
+			userID, name, id).Scan(&taken)
+	} else {
+		err = r.db.QueryRow(ctx, `SELECT COUNT(*) FROM exercise_templates WHERE user_id = $1 AND LOWER(name) = LOWER($2) AND id <> $3`,
+			userID, name, id).Scan(&taken)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check exercise template name: %w", err)
+	}
+	if taken > 0 {
+		return ErrExerciseTemplateExists
+	}
+	return nil
+}
+
+// CreateExerciseTemplate saves a validated template for the user and sets its ID
+func (r *WorkoutRepository) CreateExerciseTemplate(ctx context.Context, userID string, t *models.ExerciseTemplate) error {
+	if err := r.checkExerciseTemplateName(ctx, userID, "", t.Name); err != nil {
+		return err
+	}
+	id := ids.New()
+	now := time.Now()
+	var err error
+	if r.useSQLite {
+		_, err = r.sqlite.ExecContext(ctx, `
+			INSERT INTO exercise_templates (id, user_id, name, default_sets, default_reps, default_reps_min, default_reps_max, default_weight, mode, default_duration_seconds, default_distance_meters, default_rest_seconds,
+				primary_muscles, secondary_muscles, equipment, instructions, video_url, image_url, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			id, userID, t.Name, t.DefaultSets, t.DefaultReps, t.DefaultRepsMin, t.DefaultRepsMax, t.DefaultWeight, t.Mode, t.DefaultDurationSeconds, t.DefaultDistanceMeters, t.DefaultRestSeconds,
+			t.PrimaryMuscles, t.SecondaryMuscles, t.Equipment, t.Instructions, t.VideoURL, t.ImageURL, now, now)
+	} else {
+		_, err = r.db.Exec(ctx, `
+			INSERT INTO exercise_templates (id, user_id, name, default_sets, default_reps, default_reps_min, default_reps_max, default_weight, mode, default_duration_seconds, default_distance_meters, default_rest_seconds,
+				primary_muscles, secondary_muscles, equipment, instructions, video_url, image_url, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)`,
+			id, userID, t.Name, t.DefaultSets, t.DefaultReps, t.DefaultRepsMin, t.DefaultRepsMax, t.DefaultWeight, t.Mode, t.DefaultDurationSeconds, t.DefaultDistanceMeters, t.DefaultRestSeconds,
+			t.PrimaryMuscles, t.SecondaryMuscles, t.Equipment, t.Instructions, t.VideoURL, t.ImageURL, now, now)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create exercise template: %w", err)
+	}
+	t.ID = id
+	return nil
+}
+
+// UpdateExerciseTemplate saves changes to one of the user's validated templates
+func (r *WorkoutRepository) UpdateExerciseTemplate(ctx context.Context, userID string, t *models.ExerciseTemplate) error {
+	if err := r.checkExerciseTemplateName(ctx, userID, t.ID, t.Name); err != nil {
+		return err
+	}
+	var affected int64
+	now := time.Now()
+	if r.useSQLite {
+		result, err := r.sqlite.ExecContext(ctx, `
+			UPDATE exercise_templates
+			SET name = ?, default_sets = ?, default_reps = ?, default_reps_min = ?, default_reps_max = ?, default_weight = ?, mode = ?, default_duration_seconds = ?, default_distance_meters = ?, default_rest_seconds = ?,
+				primary_muscles = ?, secondary_muscles = ?, equipment = ?, instructions = ?, video_url = ?, image_url = ?, updated_at = ?
+			WHERE id = ? AND user_id = ?`,
+			t.Name, t.DefaultSets, t.DefaultReps, t.DefaultRepsMin, t.DefaultRepsMax, t.DefaultWeight, t.Mode, t.DefaultDurationSeconds, t.DefaultDistanceMeters, t.DefaultRestSeconds,
+			t.PrimaryMuscles, t.SecondaryMuscles, t.Equipment, t.Instructions, t.VideoURL, t.ImageURL, now, t.ID, userID)
+		if err != nil {
+			return fmt.Errorf("failed to update exercise template: %w", err)
+		}
+		affected, _ = result.RowsAffected()
+	} else {
+		tag, err := r.db.Exec(ctx, `
+			UPDATE exercise_templates
+			SET name = $1, default_sets = $2, default_reps = $3, default_reps_min = $4, default_reps_max = $5, default_weight = $6, mode = $7,
+				default_duration_seconds = $8, default_distance_meters = $9, default_rest_seconds = $10,
+				primary_muscles = $11, secondary_muscles = $12, equipment = $13, instructions = $14,
+				video_url = $15, image_url = $16, updated_at = $17
+			WHERE id = $18 AND user_id = $19`,
+			t.Name, t.DefaultSets, t.DefaultReps, t.DefaultRepsMin, t.DefaultRepsMax, t.DefaultWeight, t.Mode, t.DefaultDurationSeconds, t.DefaultDistanceMeters, t.DefaultRestSeconds,
+			t.PrimaryMuscles, t.SecondaryMuscles, t.Equipment, t.Instructions, t.VideoURL, t.ImageURL, now, t.ID, userID)
+		if err != nil {
+			return fmt.Errorf("failed to update exercise template: %w", err)
+		}
+		affected = tag.RowsAffected()
+	}
+	if affected == 0 {
+		return ErrExerciseTemplateNotFound
+	}
+	return nil
+}
+
+// DeleteExerciseTemplate removes one of the user's templates. Exercises already
+// added from it are kept.
+func (r *WorkoutRepository) DeleteExerciseTemplate(ctx context.Context, userID, id string) error {
+	var affected int64
+	if r.useSQLite {
+		result, err := r.sqlite.ExecContext(ctx, `DELETE FROM exercise_templates WHERE id = ? AND user_id = ?`, id, userID)
+		if err != nil {
+			return fmt.Errorf("failed to delete exercise template: %w", err)
+		}
+		affected, _ = result.RowsAffected()
+	} else {
+		tag, err := r.db.Exec(ctx, `DELETE FROM exercise_templates WHERE id = $1 AND user_id = $2`, id, userID)
+		if err != nil {
+			return fmt.Errorf("failed to delete exercise template: %w", err)
+		}
+		affected = tag.RowsAffected()
+	}
+	if affected == 0 {
+		return ErrExerciseTemplateNotFound
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"liftoff/backend/models"
+)
+
+// EachSession streams every session of the user, oldest first, to fn
+func (r *SessionRepository) EachSession(ctx context.Context, userID string, fn func(*models.WorkoutSession) error) error {
+	const query = `
+		SELECT id, user_id, workout_id, started_at, ended_at, is_active, created_at, updated_at, metadata
+		FROM workout_sessions
+		WHERE user_id = $1
+		ORDER BY started_at`
+	return eachRow(ctx, r.db, r.sqlite, r.useSQLite, query, []interface{}{userID}, func(scan func(...interface{}) error) error {
+		var session models.WorkoutSession
+		if err := scan(
+			&session.ID, &session.UserID, &session.WorkoutID, &session.StartedAt, &session.EndedAt,
+			&session.IsActive, &session.CreatedAt, &session.UpdatedAt, &session.Metadata,
+		); err != nil {
+			return fmt.Errorf("failed to scan session: %w", err)
+		}
+		return fn(&session)
+	})
+}
+
+// EachSet streams every set the user has logged, grouped by session, to fn
+func (r *SessionRepository) EachSet(ctx context.Context, userID string, fn func(*models.ExportSet) error) error {
+	const query = `
+		SELECT se.session_id, se.exercise_id, e.name,
+		       es.id, es.session_exercise_id, es.reps, es.weight, es.duration_seconds, es.distance_meters, es.technique, es.segments, es.sides, es.actual_rpe, es.set_type,
+		       es.completed, es.notes, es.created_at, es.updated_at
+		FROM exercise_sets es
+		JOIN session_exercises se ON es.session_exercise_id = se.id
+		JOIN workout_sessions ws ON se.session_id = ws.id
+		JOIN exercises e ON se.exercise_id = e.id
+		WHERE ws.user_id = $1
+		ORDER BY ws.started_at, se.created_at, es.created_at`
+	return eachRow(ctx, r.db, r.sqlite, r.useSQLite, query, []interface{}{userID}, func(scan func(...interface{}) error) error {
+		var set models.ExportSet
+		if err := scan(
+			&set.SessionID, &set.ExerciseID, &set.ExerciseName,
+			&set.ID, &set.SessionExerciseID, &set.Reps, &set.Weight, &set.DurationSeconds, &set.DistanceMeters, &set.Technique, &set.Segments, &set.Sides, &set.ActualRPE, &set.SetType,
+			&set.Completed, &set.Notes, &set.CreatedAt, &set.UpdatedAt,
+		); err != nil {
+			return fmt.Errorf("failed to scan exercise set: %w", err)
+		}
+		return fn(&set)
+	})
+}
+
+// EachDinoGameScore streams every dino game score of the user, oldest first, to fn
+func (r *WorkoutRepository) EachDinoGameScore(ctx context.Context, userID string, fn func(*models.DinoGameScore) error) error {
+	const query = `SELECT id, score, created_at FROM dino_game_scores WHERE user_id = $1 ORDER BY created_at`
+	return eachRow(ctx, r.db, r.sqlite, r.useSQLite, query, []interface{}{userID}, func(scan func(...interface{}) error) error {
+		var score models.DinoGameScore
+		if err := scan(&score.ID, &score.Score, &score.CreatedAt); err != nil {
+			return fmt.Errorf("failed to scan dino game score: %w", err)
+		}
+		return fn(&score)
+	})
+}
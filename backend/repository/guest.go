@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"liftoff/backend/ids"
+	"liftoff/backend/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrNotGuest is returned when claiming an account that is not a guest account
+var ErrNotGuest = errors.New("account is not a guest account")
+
+// guestEmailDomain gives guests a unique placeholder email that cannot receive mail
+const guestEmailDomain = "guest.invalid"
+
+// GetOrCreateGuest returns the provisional user for a device, creating one the first
+// time the device is seen. Either way the guest's last-seen time moves to now.
+func (r *UserRepository) GetOrCreateGuest(ctx context.Context, deviceHash string) (*models.User, error) {
+	var userID string
+	err := r.inMergeTx(ctx, func(tx mergeTx) error {
+		now := time.Now()
+		err := tx.queryRow(`SELECT user_id FROM guest_accounts WHERE device_hash = $1`+tx.lock, deviceHash)(&userID)
+		if err == nil {
+			_, err = tx.exec(`UPDATE guest_accounts SET last_seen_at = $1 WHERE user_id = $2`, now.Unix(), userID)
+			return err
+		}
+		if !errors.Is(err, sql.ErrNoRows) && !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+
+		userID = ids.New()
+		if _, err := tx.exec(`INSERT INTO users (id, email, password_hash, created_at) VALUES ($1, $2, $3, $4)`,
+			userID, fmt.Sprintf("guest-%s@%s", userID, guestEmailDomain), "", now.UTC()); err != nil {
+			return err
+		}
+		_, err = tx.exec(`INSERT INTO guest_accounts (user_id, device_hash, created_at, last_seen_at) VALUES ($1, $2, $3, $4)`,
+			userID, deviceHash, now.Unix(), now.Unix())
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get guest account: %w", err)
+	}
+	return r.GetByID(ctx, userID)
+}
+
+// ClaimGuest registers email and passwordHash as a new account and merges the guest's
+// data into it, deleting the guest, in one transaction
+func (r *UserRepository) ClaimGuest(ctx context.Context, guestID, email, passwordHash string) (*models.User, *models.AccountMerge, error) {
+	userID := ids.New()
+	var result *models.AccountMerge
+	err := r.inMergeTx(ctx, func(tx mergeTx) error {
+		var createdAt int64
+		err := tx.queryRow(`SELECT created_at FROM guest_accounts WHERE user_id = $1`+tx.lock, guestID)(&createdAt)
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotGuest
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get guest account: %w", err)
+		}
+		if _, err := tx.exec(`INSERT INTO users (id, email, password_hash, created_at) VALUES ($1, $2, $3, $4)`,
+			userID, email, passwordHash, time.Now().UTC()); err != nil {
+			return fmt.Errorf("failed to create user: %w", err)
+		}
+		result, err = mergeAccounts(tx, userID, guestID)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	user, err := r.GetByID(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, result, nil
+}
+
+// PurgeIdleGuests deletes guest accounts, with everything they logged, that have not
+// signed in for idleFor; their tokens have expired by then
+func (r *UserRepository) PurgeIdleGuests(ctx context.Context, idleFor time.Duration) error {
+	var ids []string
+	err := eachRow(ctx, r.db, r.sqlite, r.useSQLite,
+		`SELECT user_id FROM guest_accounts WHERE last_seen_at < $1`,
+		[]interface{}{time.Now().Add(-idleFor).Unix()}, func(scan func(...interface{}) error) error {
+			var id string
+			if err := scan(&id); err != nil {
+				return err
+			}
+			ids = append(ids, id)
+			return nil
+		})
+	if err != nil {
+		return fmt.Errorf("failed to list idle guests: %w", err)
+	}
+	for _, id := range ids {
+		if err := r.DeleteAccount(ctx, id); err != nil && !errors.Is(err, ErrUserNotFound) && !errors.Is(err, ErrLegalHold) {
+			return fmt.Errorf("failed to delete guest %s: %w", id, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"liftoff/backend/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	// ErrLegalHold is returned when deleting data of an account under legal hold
+	ErrLegalHold = errors.New("account is under legal hold")
+	// ErrRecordNotFound is returned when the user has no record of that kind and ID
+	ErrRecordNotFound = errors.New("record not found")
+	// ErrInvalidConfirmation is returned for unknown, expired or mismatched hard-delete tokens
+	ErrInvalidConfirmation = errors.New("invalid or expired confirmation token")
+)
+
+// hardDeleteKind describes how to find and remove one kind of record. Every
+// placeholder in owner and deletes is bound to the record ID.
+type hardDeleteKind struct {
+	owner   string   // selects the owning user ID
+	deletes []string // children before parents
+}
+
+var hardDeleteKinds = map[string]hardDeleteKind{
+	models.HardDeleteWorkout: {
+		owner: `SELECT user_id FROM workouts WHERE id = $1`,
+		deletes: []string{
+			`DELETE FROM exercise_sets WHERE session_exercise_id IN (
+				SELECT se.id FROM session_exercises se JOIN workout_sessions ws ON se.session_id = ws.id WHERE ws.workout_id = $1
+				UNION SELECT se.id FROM session_exercises se JOIN exercises e ON se.exercise_id = e.id WHERE e.workout_id = $2)`,
+			`DELETE FROM session_exercises WHERE session_id IN (SELECT id FROM workout_sessions WHERE workout_id = $1)
+				OR exercise_id IN (SELECT id FROM exercises WHERE workout_id = $2)`,
+			`DELETE FROM partner_sessions WHERE host_session_id IN (SELECT id FROM workout_sessions WHERE workout_id = $1)
+				OR partner_session_id IN (SELECT id FROM workout_sessions WHERE workout_id = $2)`,
+			`DELETE FROM workout_sessions WHERE workout_id = $1`,
+			`DELETE FROM routine_workouts WHERE workout_id = $1`,
+			`DELETE FROM program_workouts WHERE workout_id = $1`,
+			`DELETE FROM progression_rules WHERE exercise_id IN (SELECT id FROM exercises WHERE workout_id = $1)`,
+			`DELETE FROM exercises WHERE workout_id = $1`,
+			`DELETE FROM workout_tags WHERE workout_id = $1`,
+			`DELETE FROM scheduled_workouts WHERE workout_id = $1`,
+			`DELETE FROM workouts WHERE id = $1`,
+		},
+	},
+	models.HardDeleteSession: {
+		owner: `SELECT user_id FROM workout_sessions WHERE id = $1`,
+		deletes: []string{
+			`DELETE FROM exercise_sets WHERE session_exercise_id IN (SELECT id FROM session_exercises WHERE session_id = $1)`,
+			`DELETE FROM session_exercises WHERE session_id = $1`,
+			`DELETE FROM partner_sessions WHERE host_session_id = $1 OR partner_session_id = $2`,
+			`DELETE FROM workout_sessions WHERE id = $1`,
+		},
+	},
+}
+
+// recordArgs repeats the record ID for every placeholder in query
+func recordArgs(query, recordID string) []interface{} {
+	args := make([]interface{}, strings.Count(query, "$"))
+	for i := range args {
+		args[i] = recordID
+	}
+	return args
+}
+
+// SetLegalHold places or releases a legal hold on an account, recording who did it
+// in the audit log. Accounts under hold cannot be deleted, in whole or in part.
+func (r *UserRepository) SetLegalHold(ctx context.Context, userID, actorID string, hold bool, reason string) (*models.AuditEntry, error) {
+	action := models.AuditActionLegalHoldReleased
+	if hold {
+		action = models.AuditActionLegalHoldPlaced
+	}
+	entry, args, err := newAuditEntry(userID, actorID, action, map[string]string{"reason": reason})
+	if err != nil {
+		return nil, err
+	}
+	err = r.inMergeTx(ctx, func(tx mergeTx) error {
+		affected, err := tx.exec(`UPDATE users SET legal_hold = $1 WHERE id = $2`, hold, userID)
+		if err != nil {
+			return fmt.Errorf("failed to set legal hold: %w", err)
+		}
+		if affected == 0 {
+			return ErrUserNotFound
+		}
+		if _, err := tx.exec(insertAuditEntry, args...); err != nil {
+			return fmt.Errorf("failed to record audit entry: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// RequestHardDelete checks that the user owns the record and is not under legal hold,
+// then stores a confirmation for it under tokenHash until expiresAt
+func (r *UserRepository) RequestHardDelete(ctx context.Context, actorID, userID, kind, recordID, reason, tokenHash string, expiresAt time.Time) error {
+	return r.inMergeTx(ctx, func(tx mergeTx) error {
+		if err := checkHardDelete(tx, userID, kind, recordID); err != nil {
+			return err
+		}
+		now := time.Now().Unix()
+		if _, err := tx.exec(`DELETE FROM hard_delete_confirmations WHERE expires_at < $1`, now); err != nil {
+			return fmt.Errorf("failed to purge hard-delete confirmations: %w", err)
+		}
+		_, err := tx.exec(`
+			INSERT INTO hard_delete_confirmations (token_hash, admin_id, user_id, kind, record_id, reason, expires_at, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+			tokenHash, actorID, userID, kind, recordID, reason, expiresAt.Unix(), now)
+		if err != nil {
+			return fmt.Errorf("failed to store hard-delete confirmation: %w", err)
+		}
+		return nil
+	})
+}
+
+// HardDelete consumes a confirmation from RequestHardDelete and permanently removes the
+// record in the same transaction as its audit entry. The confirmation must have been
+// requested by the same admin for the same record, and the hold is checked again.
+func (r *UserRepository) HardDelete(ctx context.Context, actorID, userID, kind, recordID, tokenHash string) (*models.AuditEntry, error) {
+	var entry *models.AuditEntry
+	err := r.inMergeTx(ctx, func(tx mergeTx) error {
+		var reason string
+		var requestedAt int64
+		err := tx.queryRow(`
+			SELECT reason, created_at FROM hard_delete_confirmations
+			WHERE token_hash = $1 AND admin_id = $2 AND user_id = $3 AND kind = $4 AND record_id = $5 AND expires_at >= $6`+tx.lock,
+			tokenHash, actorID, userID, kind, recordID, time.Now().Unix())(&reason, &requestedAt)
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgx.ErrNoRows) {
+			return ErrInvalidConfirmation
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get hard-delete confirmation: %w", err)
+		}
+		if _, err := tx.exec(`DELETE FROM hard_delete_confirmations WHERE token_hash = $1`, tokenHash); err != nil {
+			return fmt.Errorf("failed to consume hard-delete confirmation: %w", err)
+		}
+		if err := checkHardDelete(tx, userID, kind, recordID); err != nil {
+			return err
+		}
+
+		for _, stmt := range hardDeleteKinds[kind].deletes {
+			if _, err := tx.exec(stmt, recordArgs(stmt, recordID)...); err != nil {
+				return fmt.Errorf("failed to hard-delete %s: %w", kind, err)
+			}
+		}
+
+		var args []interface{}
+		entry, args, err = newAuditEntry(userID, actorID, models.AuditActionRecordHardDeleted, map[string]interface{}{
+			"kind":         kind,
+			"record_id":    recordID,
+			"reason":       reason,
+			"requested_at": time.Unix(requestedAt, 0).UTC(),
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := tx.exec(insertAuditEntry, args...); err != nil {
+			return fmt.Errorf("failed to record audit entry: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// checkHardDelete returns ErrUserNotFound, ErrLegalHold or ErrRecordNotFound unless
+// the record exists, belongs to userID and the account is not under hold
+func checkHardDelete(tx mergeTx, userID, kind, recordID string) error {
+	var hold bool
+	err := tx.queryRow(`SELECT legal_hold FROM users WHERE id = $1`+tx.lock, userID)(&hold)
+	if errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgx.ErrNoRows) {
+		return ErrUserNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if hold {
+		return ErrLegalHold
+	}
+
+	k, ok := hardDeleteKinds[kind]
+	if !ok {
+		return ErrRecordNotFound
+	}
+	var owner string
+	err = tx.queryRow(k.owner, recordArgs(k.owner, recordID)...)(&owner)
+	if errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgx.ErrNoRows) || (err == nil && owner != userID) {
+		return ErrRecordNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get %s: %w", kind, err)
+	}
+	return nil
+}
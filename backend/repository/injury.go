@@ -0,0 +1,254 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"liftoff/backend/ids"
+	"liftoff/backend/models"
+)
+
+// ErrInjuryNotFound is returned when an injury does not exist or belongs to another user
+var ErrInjuryNotFound = errors.New("injury not found")
+
+// InjuryRepository stores user injuries and derives exercise warnings from them
+type InjuryRepository struct {
+	db        Pool
+	sqlite    *sql.DB
+	useSQLite bool
+	workout   *WorkoutRepository
+}
+
+// NewInjuryRepository creates a new injury repository
+func NewInjuryRepository(db Pool, sqlite *sql.DB, useSQLite bool, workout *WorkoutRepository) *InjuryRepository {
+	if useSQLite {
+		return &InjuryRepository{db: nil, sqlite: sqlite, useSQLite: true, workout: workout}
+	}
+	return &InjuryRepository{db: db, sqlite: nil, useSQLite: false, workout: workout}
+}
+
+// CreateInjury logs a new active injury for the user
+func (r *InjuryRepository) CreateInjury(ctx context.Context, injury *models.Injury) error {
+	injury.ID = ids.New()
+	injury.Active = true
+	injury.CreatedAt = time.Now()
+	injury.ResolvedAt = nil
+
+	restrictions, err := json.Marshal(injury.Restrictions)
+	if err != nil {
+		return fmt.Errorf("failed to encode restrictions: %w", err)
+	}
+
+	if r.useSQLite {
+		_, err = r.sqlite.ExecContext(ctx, `
+			INSERT INTO injuries (id, user_id, name, restrictions, notes, active, created_at)
+			VALUES (?, ?, ?, ?, ?, 1, ?)`,
+			injury.ID, injury.UserID, injury.Name, string(restrictions), injury.Notes, injury.CreatedAt)
+	} else {
+		_, err = r.db.Exec(ctx, `
+			INSERT INTO injuries (id, user_id, name, restrictions, notes, active, created_at)
+			VALUES ($1, $2, $3, $4, $5, true, $6)`,
+			injury.ID, injury.UserID, injury.Name, string(restrictions), injury.Notes, injury.CreatedAt)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create injury: %w", err)
+	}
+	return nil
+}
+
+// GetInjuries returns the user's injuries, newest first
+func (r *InjuryRepository) GetInjuries(ctx context.Context, userID string, activeOnly bool) ([]*models.Injury, error) {
+	if r.useSQLite {
+		return r.getInjuriesSQLite(ctx, userID, activeOnly)
+	}
+	return r.getInjuriesPostgres(ctx, userID, activeOnly)
+}
+
+func (r *InjuryRepository) getInjuriesPostgres(ctx context.Context, userID string, activeOnly bool) ([]*models.Injury, error) {
+	query := `
+		SELECT id, user_id, name, restrictions, notes, active, created_at, resolved_at
+		FROM injuries
+		WHERE user_id = $1 AND (active = true OR $2 = false)
+		ORDER BY created_at DESC
+	`
+	rows, err := r.db.Query(ctx, query, userID, activeOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get injuries: %w", err)
+	}
+	defer rows.Close()
+
+	var injuries []*models.Injury
+	for rows.Next() {
+		injury, err := scanInjury(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		injuries = append(injuries, injury)
+	}
+	return injuries, rows.Err()
+}
+
+func (r *InjuryRepository) getInjuriesSQLite(ctx context.Context, userID string, activeOnly bool) ([]*models.Injury, error) {
+	query := `
+		SELECT id, user_id, name, restrictions, notes, active, created_at, resolved_at
+		FROM injuries
+		WHERE user_id = ? AND (active = 1 OR ? = 0)
+		ORDER BY created_at DESC
+	`
+	rows, err := r.sqlite.QueryContext(ctx, query, userID, activeOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get injuries: %w", err)
+	}
+	defer rows.Close()
+
+	var injuries []*models.Injury
+	for rows.Next() {
+		injury, err := scanInjury(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		injuries = append(injuries, injury)
+	}
+	return injuries, rows.Err()
+}
+
+func scanInjury(scan func(...interface{}) error) (*models.Injury, error) {
+	var injury models.Injury
+	var restrictions string
+	if err := scan(&injury.ID, &injury.UserID, &injury.Name, &restrictions, &injury.Notes,
+		&injury.Active, &injury.CreatedAt, &injury.ResolvedAt); err != nil {
+		return nil, fmt.Errorf("failed to scan injury: %w", err)
+	}
+	if err := json.Unmarshal([]byte(restrictions), &injury.Restrictions); err != nil {
+		return nil, fmt.Errorf("failed to decode restrictions: %w", err)
+	}
+	if injury.Restrictions == nil {
+		injury.Restrictions = []string{}
+	}
+	return &injury, nil
+}
+
+// ResolveInjury marks an injury as healed so it no longer produces warnings
+func (r *InjuryRepository) ResolveInjury(ctx context.Context, userID, id string) error {
+	var affected int64
+	now := time.Now()
+	if r.useSQLite {
+		result, err := r.sqlite.ExecContext(ctx,
+			`UPDATE injuries SET active = 0, resolved_at = ? WHERE id = ? AND user_id = ? AND active = 1`,
+			now, id, userID)
+		if err != nil {
+			return fmt.Errorf("failed to resolve injury: %w", err)
+		}
+		affected, _ = result.RowsAffected()
+	} else {
+		tag, err := r.db.Exec(ctx,
+			`UPDATE injuries SET active = false, resolved_at = $1 WHERE id = $2 AND user_id = $3 AND active = true`,
+			now, id, userID)
+		if err != nil {
+			return fmt.Errorf("failed to resolve injury: %w", err)
+		}
+		affected = tag.RowsAffected()
+	}
+	if affected == 0 {
+		return ErrInjuryNotFound
+	}
+	return nil
+}
+
+// DeleteInjury removes an injury record
+func (r *InjuryRepository) DeleteInjury(ctx context.Context, userID, id string) error {
+	var affected int64
+	if r.useSQLite {
+		result, err := r.sqlite.ExecContext(ctx, `DELETE FROM injuries WHERE id = ? AND user_id = ?`, id, userID)
+		if err != nil {
+			return fmt.Errorf("failed to delete injury: %w", err)
+		}
+		affected, _ = result.RowsAffected()
+	} else {
+		tag, err := r.db.Exec(ctx, `DELETE FROM injuries WHERE id = $1 AND user_id = $2`, id, userID)
+		if err != nil {
+			return fmt.Errorf("failed to delete injury: %w", err)
+		}
+		affected = tag.RowsAffected()
+	}
+	if affected == 0 {
+		return ErrInjuryNotFound
+	}
+	return nil
+}
+
+// ExerciseWarnings returns a warning for every exercise whose library risk flags
+// intersect the restrictions of one of the user's active injuries
+func (r *InjuryRepository) ExerciseWarnings(ctx context.Context, userID string, exerciseNames []string) ([]models.ExerciseWarning, error) {
+	if len(exerciseNames) == 0 {
+		return nil, nil
+	}
+	injuries, err := r.GetInjuries(ctx, userID, true)
+	if err != nil || len(injuries) == 0 {
+		return nil, err
+	}
+
+	var warnings []models.ExerciseWarning
+	seen := map[string]bool{}
+	for _, name := range exerciseNames {
+		key := strings.ToLower(strings.TrimSpace(name))
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		flags := r.workout.ExerciseRiskFlags(name)
+		if len(flags) == 0 {
+			continue
+		}
+		for _, injury := range injuries {
+			matched := intersect(flags, injury.Restrictions)
+			if len(matched) == 0 {
+				continue
+			}
+			warnings = append(warnings, models.ExerciseWarning{
+				ExerciseName: name,
+				InjuryID:     injury.ID,
+				InjuryName:   injury.Name,
+				RiskFlags:    matched,
+				Message: fmt.Sprintf("%s is flagged %s, which is restricted while %s is active",
+					name, strings.ReplaceAll(strings.Join(matched, ", "), "_", " "), injury.Name),
+			})
+		}
+	}
+	return warnings, nil
+}
+
+// SessionWarnings collects warnings for the workout and logged exercises of a session
+func (r *InjuryRepository) SessionWarnings(ctx context.Context, userID string, session *models.WorkoutSession) ([]models.ExerciseWarning, error) {
+	var names []string
+	if session.Workout != nil {
+		for _, e := range session.Workout.Exercises {
+			names = append(names, e.Name)
+		}
+	}
+	for _, se := range session.Exercises {
+		if se.Exercise != nil {
+			names = append(names, se.Exercise.Name)
+		}
+	}
+	return r.ExerciseWarnings(ctx, userID, names)
+}
+
+func intersect(a, b []string) []string {
+	var out []string
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				out = append(out, x)
+				break
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// LoginAccountKey identifies failed-login tracking for an account
+func LoginAccountKey(email string) string { return "account:" + email }
+
+// LoginIPKey identifies failed-login tracking for a client IP
+func LoginIPKey(ip string) string { return "ip:" + ip }
+
+// ResetAccountKey identifies forgot-password throttling for an email address
+func ResetAccountKey(email string) string { return "reset:account:" + email }
+
+// ResetIPKey identifies forgot-password throttling for a client IP
+func ResetIPKey(ip string) string { return "reset:ip:" + ip }
+
+// GetLoginLockout returns when the lock on key expires, or the zero time if it is not locked
+func (r *UserRepository) GetLoginLockout(ctx context.Context, key string) (time.Time, error) {
+	var lockedUntil int64
+	var err error
+	if r.useSQLite {
+		err = r.sqlite.QueryRowContext(ctx, `SELECT locked_until FROM login_attempts WHERE attempt_key = ?`, key).Scan(&lockedUntil)
+		if errors.Is(err, sql.ErrNoRows) {
+			return time.Time{}, nil
+		}
+	} else {
+		err = r.db.QueryRow(ctx, `SELECT locked_until FROM login_attempts WHERE attempt_key = $1`, key).Scan(&lockedUntil)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return time.Time{}, nil
+		}
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get login lockout: %w", err)
+	}
+	until := time.Unix(lockedUntil, 0)
+	if !until.After(time.Now()) {
+		return time.Time{}, nil
+	}
+	return until, nil
+}
+
+// RecordLoginFailure counts a failed login for key and returns the consecutive failure count.
+// The count restarts when the previous failure is older than window.
+func (r *UserRepository) RecordLoginFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	now := time.Now().Unix()
+	windowStart := now - int64(window.Seconds())
+
+	var failures int
+	var err error
+	if r.useSQLite {
+		err = r.sqlite.QueryRowContext(ctx, `
+			INSERT INTO login_attempts (attempt_key, failures, last_failure, locked_until) VALUES (?, 1, ?, 0)
+			ON CONFLICT (attempt_key) DO UPDATE SET
+				failures = CASE WHEN login_attempts.last_failure < ? THEN 1 ELSE login_attempts.failures + 1 END,
+				last_failure = excluded.last_failure
+			RETURNING failures`,
+			key, now, windowStart).Scan(&failures)
+	} else {
+		err = r.db.QueryRow(ctx, `
+			INSERT INTO login_attempts (attempt_key, failures, last_failure, locked_until) VALUES ($1, 1, $2, 0)
+			ON CONFLICT (attempt_key) DO UPDATE SET
+				failures = CASE WHEN login_attempts.last_failure < $3 THEN 1 ELSE login_attempts.failures + 1 END,
+				last_failure = EXCLUDED.last_failure
+			RETURNING failures`,
+			key, now, windowStart).Scan(&failures)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to record login failure: %w", err)
+	}
+	return failures, nil
+}
+
+// LockLogin blocks logins for key until the given time
+func (r *UserRepository) LockLogin(ctx context.Context, key string, until time.Time) error {
+	var err error
+	if r.useSQLite {
+		_, err = r.sqlite.ExecContext(ctx, `UPDATE login_attempts SET locked_until = ? WHERE attempt_key = ?`, until.Unix(), key)
+	} else {
+		_, err = r.db.Exec(ctx, `UPDATE login_attempts SET locked_until = $1 WHERE attempt_key = $2`, until.Unix(), key)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock login: %w", err)
+	}
+	return nil
+}
+
+// ClearLoginFailures forgets failures for key after a successful login
+func (r *UserRepository) ClearLoginFailures(ctx context.Context, key string) error {
+	var err error
+	if r.useSQLite {
+		_, err = r.sqlite.ExecContext(ctx, `DELETE FROM login_attempts WHERE attempt_key = ?`, key)
+	} else {
+		_, err = r.db.Exec(ctx, `DELETE FROM login_attempts WHERE attempt_key = $1`, key)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to clear login failures: %w", err)
+	}
+	return nil
+}
+
+// PurgeLoginAttempts removes entries that are unlocked and older than window.
+// Intended to run from the jobs scheduler.
+func (r *UserRepository) PurgeLoginAttempts(ctx context.Context, window time.Duration) error {
+	now := time.Now().Unix()
+	cutoff := now - int64(window.Seconds())
+	var err error
+	if r.useSQLite {
+		_, err = r.sqlite.ExecContext(ctx, `DELETE FROM login_attempts WHERE last_failure < ? AND locked_until < ?`, cutoff, now)
+	} else {
+		_, err = r.db.Exec(ctx, `DELETE FROM login_attempts WHERE last_failure < $1 AND locked_until < $2`, cutoff, now)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to purge login attempts: %w", err)
+	}
+	return nil
+}
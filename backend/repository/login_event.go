@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"liftoff/backend/ids"
+)
+
+// maxLoginUserAgentLength caps the stored user agent
+const maxLoginUserAgentLength = 255
+
+// RecordLogin stores a successful sign-in and reports whether it came from an IP and
+// user agent pair the user has not signed in from before. A user's first recorded
+// sign-in is not reported as new, so existing accounts are not alerted on their
+// first sign-in after login events were introduced.
+func (r *UserRepository) RecordLogin(ctx context.Context, userID, ip, userAgent string) (bool, error) {
+	if len(userAgent) > maxLoginUserAgentLength {
+		userAgent = userAgent[:maxLoginUserAgentLength]
+	}
+
+	query := `SELECT COUNT(*), COALESCE(SUM(CASE WHEN ip = $1 AND user_agent = $2 THEN 1 ELSE 0 END), 0)
+		FROM login_events WHERE user_id = $3`
+	insert := `INSERT INTO login_events (id, user_id, ip, user_agent, created_at) VALUES ($1, $2, $3, $4, $5)`
+	if r.useSQLite {
+		query = rebind(query, 3)
+		insert = rebind(insert, 5)
+	}
+
+	var total, seen int64
+	var err error
+	if r.useSQLite {
+		err = r.sqlite.QueryRowContext(ctx, query, ip, userAgent, userID).Scan(&total, &seen)
+	} else {
+		err = r.db.QueryRow(ctx, query, ip, userAgent, userID).Scan(&total, &seen)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check login history: %w", err)
+	}
+
+	args := []interface{}{ids.New(), userID, ip, userAgent, time.Now().Unix()}
+	if r.useSQLite {
+		_, err = r.sqlite.ExecContext(ctx, insert, args...)
+	} else {
+		_, err = r.db.Exec(ctx, insert, args...)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to record login: %w", err)
+	}
+	return total > 0 && seen == 0, nil
+}
+
+// PurgeLoginEvents removes sign-ins older than maxAge; a device unused for that long
+// counts as new again. Intended to run from the jobs scheduler.
+func (r *UserRepository) PurgeLoginEvents(ctx context.Context, maxAge time.Duration) error {
+	cutoff := time.Now().Add(-maxAge).Unix()
+	var err error
+	if r.useSQLite {
+		_, err = r.sqlite.ExecContext(ctx, `DELETE FROM login_events WHERE created_at < ?`, cutoff)
+	} else {
+		_, err = r.db.Exec(ctx, `DELETE FROM login_events WHERE created_at < $1`, cutoff)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to purge login events: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,263 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrMergeSameAccount is returned when asked to merge an account into itself
+var ErrMergeSameAccount = errors.New("cannot merge an account into itself")
+
+// mergeTables hold user-owned rows that move to the surviving account as they are.
+// Rows hanging off these (exercises, sets, routine and program workouts) follow their parents.
+var mergeTables = []string{
+	"workouts",
+	"workout_sessions",
+	"routines",
+	"scheduled_workouts",
+	"programs",
+	"program_enrollments",
+	"progression_rules",
+	"dino_game_scores",
+	"injuries",
+	"user_alerts",
+	"audit_log",
+	"login_events",
+	"oauth_identities",
+	"webauthn_credentials",
+	"api_keys",
+}
+
+// roleRank orders roles so a merge keeps the more privileged one
+var roleRank = map[string]int{auth.RoleUser: 0, auth.RoleCoach: 1, auth.RoleAdmin: 2}
+
+// mergeTx abstracts the running transaction; queries are written with $n placeholders
+type mergeTx struct {
+	exec     func(query string, args ...interface{}) (int64, error)
+	queryRow func(query string, args ...interface{}) func(...interface{}) error
+	lock     string // row-locking suffix for SELECTs
+}
+
+type mergeUser struct {
+	email, passwordHash, role string
+	createdAt                 time.Time
+	legalHold                 bool
+}
+
+// MergeAccounts moves everything owned by sourceID onto targetID and deletes the source
+// account, all in one transaction. Conflicts are resolved by timestamp: the most recently
+// started active session and program enrollment stay active and the account keeps the
+// earlier creation date.
+// The target also gains the source's password if it had none, and the higher of the two roles.
+func (r *UserRepository) MergeAccounts(ctx context.Context, targetID, sourceID string) (*models.AccountMerge, error) {
+	if targetID == sourceID {
+		return nil, ErrMergeSameAccount
+	}
+	var result *models.AccountMerge
+	err := r.inMergeTx(ctx, func(tx mergeTx) error {
+		var err error
+		result, err = mergeAccounts(tx, targetID, sourceID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// inMergeTx runs fn in a transaction, committing when it returns nil
+func (r *UserRepository) inMergeTx(ctx context.Context, fn func(mergeTx) error) error {
+	return inTx(ctx, r.db, r.sqlite, r.useSQLite, fn)
+}
+
+// inTx runs fn in a transaction on whichever database is in use, committing
+// when it returns nil
+func inTx(ctx context.Context, db Pool, sqlite *sql.DB, useSQLite bool, fn func(mergeTx) error) error {
+	if useSQLite {
+		return inTxSQLite(ctx, sqlite, fn)
+	}
+	return inTxPostgres(ctx, db, fn)
+}
+
+func inTxPostgres(ctx context.Context, db Pool, fn func(mergeTx) error) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = fn(mergeTx{
+		exec: func(query string, args ...interface{}) (int64, error) {
+			tag, err := tx.Exec(ctx, query, args...)
+			return tag.RowsAffected(), err
+		},
+		queryRow: func(query string, args ...interface{}) func(...interface{}) error {
+			return tx.QueryRow(ctx, query, args...).Scan
+		},
+		lock: " FOR UPDATE",
+	})
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func inTxSQLite(ctx context.Context, sqlite *sql.DB, fn func(mergeTx) error) error {
+	tx, err := sqlite.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = fn(mergeTx{
+		exec: func(query string, args ...interface{}) (int64, error) {
+			res, err := tx.ExecContext(ctx, rebind(query, len(args)), args...)
+			if err != nil {
+				return 0, err
+			}
+			return res.RowsAffected()
+		},
+		queryRow: func(query string, args ...interface{}) func(...interface{}) error {
+			return tx.QueryRowContext(ctx, rebind(query, len(args)), args...).Scan
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func mergeAccounts(tx mergeTx, targetID, sourceID string) (*models.AccountMerge, error) {
+	var target, source mergeUser
+	for _, u := range []struct {
+		id   string
+		into *mergeUser
+	}{{targetID, &target}, {sourceID, &source}} {
+		err := tx.queryRow(`SELECT email, password_hash, role, created_at, legal_hold FROM users WHERE id = $1`+tx.lock, u.id)(
+			&u.into.email, &u.into.passwordHash, &u.into.role, &u.into.createdAt, &u.into.legalHold)
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+	}
+	// The source account is deleted by the merge
+	if source.legalHold {
+		return nil, ErrLegalHold
+	}
+
+	result := &models.AccountMerge{
+		UserID:       targetID,
+		MergedUserID: sourceID,
+		MergedEmail:  source.email,
+		Moved:        map[string]int64{},
+	}
+
+	// Only one session may be active; the most recently started one wins
+	var keepID string
+	err := tx.queryRow(`SELECT id FROM workout_sessions WHERE user_id IN ($1, $2) AND is_active = $3
+		ORDER BY started_at DESC LIMIT 1`, targetID, sourceID, true)(&keepID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get active sessions: %w", err)
+	}
+	if keepID != "" {
+		now := time.Now()
+		ended, err := tx.exec(`UPDATE workout_sessions SET ended_at = $1, is_active = $2, updated_at = $3
+			WHERE user_id IN ($4, $5) AND is_active = $6 AND id != $7`,
+			now, false, now, targetID, sourceID, true, keepID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to end duplicate active session: %w", err)
+		}
+		result.EndedSessions = ended
+	}
+
+	// Likewise only one program enrollment may be active
+	keepID = ""
+	err = tx.queryRow(`SELECT id FROM program_enrollments WHERE user_id IN ($1, $2) AND status = $3
+		ORDER BY started_at DESC LIMIT 1`, targetID, sourceID, models.EnrollmentActive)(&keepID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get program enrollments: %w", err)
+	}
+	if keepID != "" {
+		_, err := tx.exec(`UPDATE program_enrollments SET status = $1, updated_at = $2
+			WHERE user_id IN ($3, $4) AND status = $5 AND id != $6`,
+			models.EnrollmentLeft, time.Now(), targetID, sourceID, models.EnrollmentActive, keepID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to leave duplicate program enrollment: %w", err)
+		}
+	}
+
+	for _, table := range mergeTables {
+		moved, err := tx.exec(fmt.Sprintf(`UPDATE %s SET user_id = $1 WHERE user_id = $2`, table), targetID, sourceID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to move %s: %w", table, err)
+		}
+		if moved > 0 {
+			result.Moved[table] = moved
+		}
+	}
+
+	// Partner links follow both sides; a link between the two accounts is dropped
+	for _, column := range []string{"host_id", "partner_id"} {
+		if _, err := tx.exec(fmt.Sprintf(`UPDATE partner_sessions SET %s = $1 WHERE %s = $2`, column, column), targetID, sourceID); err != nil {
+			return nil, fmt.Errorf("failed to move partner_sessions: %w", err)
+		}
+	}
+	if _, err := tx.exec(`DELETE FROM partner_sessions WHERE host_id = $1 AND partner_id = $2`, targetID, targetID); err != nil {
+		return nil, fmt.Errorf("failed to move partner_sessions: %w", err)
+	}
+
+	if target.passwordHash == "" && source.passwordHash != "" {
+		if _, err := tx.exec(`UPDATE users SET password_hash = $1 WHERE id = $2`, source.passwordHash, targetID); err != nil {
+			return nil, fmt.Errorf("failed to merge password: %w", err)
+		}
+	}
+	if roleRank[source.role] > roleRank[target.role] {
+		if _, err := tx.exec(`UPDATE users SET role = $1 WHERE id = $2`, source.role, targetID); err != nil {
+			return nil, fmt.Errorf("failed to merge role: %w", err)
+		}
+	}
+	if source.createdAt.Before(target.createdAt) {
+		if _, err := tx.exec(`UPDATE users SET created_at = $1 WHERE id = $2`, source.createdAt, targetID); err != nil {
+			return nil, fmt.Errorf("failed to merge creation date: %w", err)
+		}
+	}
+
+	// Whatever did not move (tokens, resets, cached recommendations) goes with the source user
+	for _, stmt := range accountDeletes {
+		if _, err := tx.exec(stmt, sourceID); err != nil {
+			return nil, fmt.Errorf("failed to delete merged account: %w", err)
+		}
+	}
+	if _, err := tx.exec(`DELETE FROM login_attempts WHERE attempt_key = $1`, LoginAccountKey(source.email)); err != nil {
+		return nil, fmt.Errorf("failed to delete merged account: %w", err)
+	}
+	return result, nil
+}
+
+// ListLinkedEmails returns the emails reported by sign-in providers for linked identities
+func (r *UserRepository) ListLinkedEmails(ctx context.Context) ([]models.LinkedEmail, error) {
+	emails := []models.LinkedEmail{}
+	const query = `SELECT user_id, email FROM oauth_identities WHERE email != ''`
+	err := eachRow(ctx, r.db, r.sqlite, r.useSQLite, query, nil, func(scan func(...interface{}) error) error {
+		var e models.LinkedEmail
+		if err := scan(&e.UserID, &e.Email); err != nil {
+			return fmt.Errorf("failed to scan linked email: %w", err)
+		}
+		emails = append(emails, e)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list linked emails: %w", err)
+	}
+	return emails, nil
+}
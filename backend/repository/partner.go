@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"liftoff/backend/ids"
+	"liftoff/backend/models"
+)
+
+var (
+	// ErrPartnerSessionNotFound is returned when a partner link does not exist or the user is not part of it
+	ErrPartnerSessionNotFound = errors.New("partner session not found")
+	// ErrPartnerSessionExists is returned when the host's session already has a partner
+	ErrPartnerSessionExists = errors.New("session already has a partner")
+)
+
+// PartnerRepository links training partners' workout sessions. Times are unix seconds.
+type PartnerRepository struct {
+	db        Pool
+	sqlite    *sql.DB
+	useSQLite bool
+}
+
+// NewPartnerRepository creates a new partner repository
+func NewPartnerRepository(db Pool, sqlite *sql.DB, useSQLite bool) *PartnerRepository {
+	if useSQLite {
+		return &PartnerRepository{db: nil, sqlite: sqlite, useSQLite: true}
+	}
+	return &PartnerRepository{db: db, sqlite: nil, useSQLite: false}
+}
+
+// exec runs a statement and returns the number of rows it affected
+func (r *PartnerRepository) exec(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	return exec(ctx, r.db, r.sqlite, r.useSQLite, query, args...)
+}
+
+// partnerSessionQuery reads partner links with both users' emails; %s is the WHERE clause,
+// in which each $n may appear only once
+const partnerSessionQuery = `
+	SELECT p.id, p.host_id, h.email, p.host_session_id, p.partner_id, u.email,
+	       p.partner_session_id, p.status, p.created_at, p.joined_at
+	FROM partner_sessions p
+	JOIN users h ON h.id = p.host_id
+	JOIN users u ON u.id = p.partner_id
+	WHERE %s`
+
+func (r *PartnerRepository) list(ctx context.Context, where string, args ...interface{}) ([]*models.PartnerSession, error) {
+	links := []*models.PartnerSession{}
+	err := eachRow(ctx, r.db, r.sqlite, r.useSQLite, fmt.Sprintf(partnerSessionQuery, where), args, func(scan func(...interface{}) error) error {
+		var p models.PartnerSession
+		var createdAt int64
+		var joinedAt *int64
+		if err := scan(&p.ID, &p.HostID, &p.HostEmail, &p.HostSessionID, &p.PartnerID, &p.PartnerEmail,
+			&p.PartnerSessionID, &p.Status, &createdAt, &joinedAt); err != nil {
+			return fmt.Errorf("failed to scan partner session: %w", err)
+		}
+		p.CreatedAt = time.Unix(createdAt, 0)
+		if joinedAt != nil {
+			t := time.Unix(*joinedAt, 0)
+			p.JoinedAt = &t
+		}
+		links = append(links, &p)
+		return nil
+	})
+	return links, err
+}
+
+// CreateInvite invites partnerID into the host's active session
+func (r *PartnerRepository) CreateInvite(ctx context.Context, hostID, hostSessionID, partnerID string) (*models.PartnerSession, error) {
+	active, err := r.list(ctx, `p.host_session_id = $1`, hostSessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check partner sessions: %w", err)
+	}
+	if len(active) > 0 {
+		return nil, ErrPartnerSessionExists
+	}
+	id := ids.New()
+	_, err = r.exec(ctx, `
+		INSERT INTO partner_sessions (id, host_id, host_session_id, partner_id, status, created_at)
+		SELECT $1, $2, $3, $4, $5, $6
+		FROM workout_sessions WHERE id = $7 AND user_id = $8 AND is_active = $9`,
+		id, hostID, hostSessionID, partnerID, models.PartnerSessionInvited, time.Now().Unix(), hostSessionID, hostID, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create partner invite: %w", err)
+	}
+	link, err := r.Get(ctx, hostID, id)
+	if errors.Is(err, ErrPartnerSessionNotFound) {
+		// Nothing was inserted: the session is not the host's or has ended
+		return nil, ErrSessionNotFound
+	}
+	return link, err
+}
+
+// Get returns a partner link the user is part of
+func (r *PartnerRepository) Get(ctx context.Context, userID, id string) (*models.PartnerSession, error) {
+	links, err := r.list(ctx, `p.id = $1 AND (p.host_id = $2 OR p.partner_id = $3)`, id, userID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get partner session: %w", err)
+	}
+	if len(links) == 0 {
+		return nil, ErrPartnerSessionNotFound
+	}
+	return links[0], nil
+}
+
+// ListInvites returns invites waiting for the user to join, newest first
+func (r *PartnerRepository) ListInvites(ctx context.Context, userID string) ([]*models.PartnerSession, error) {
+	links, err := r.list(ctx, `p.partner_id = $1 AND p.status = $2 ORDER BY p.created_at DESC`, userID, models.PartnerSessionInvited)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list partner invites: %w", err)
+	}
+	return links, nil
+}
+
+// Join records the session the partner started for an invite
+func (r *PartnerRepository) Join(ctx context.Context, partnerID, id, partnerSessionID string) error {
+	n, err := r.exec(ctx, `
+		UPDATE partner_sessions SET partner_session_id = $1, status = $2, joined_at = $3
+		WHERE id = $4 AND partner_id = $5 AND status = $6`,
+		partnerSessionID, models.PartnerSessionJoined, time.Now().Unix(), id, partnerID, models.PartnerSessionInvited)
+	if err != nil {
+		return fmt.Errorf("failed to join partner session: %w", err)
+	}
+	if n == 0 {
+		return ErrPartnerSessionNotFound
+	}
+	return nil
+}
+
+// DeleteInvite withdraws (host) or declines (partner) an invite that has not been joined
+func (r *PartnerRepository) DeleteInvite(ctx context.Context, userID, id string) error {
+	n, err := r.exec(ctx, `DELETE FROM partner_sessions WHERE id = $1 AND (host_id = $2 OR partner_id = $3) AND status = $4`,
+		id, userID, userID, models.PartnerSessionInvited)
+	if err != nil {
+		return fmt.Errorf("failed to delete partner invite: %w", err)
+	}
+	if n == 0 {
+		return ErrPartnerSessionNotFound
+	}
+	return nil
+}
+
+// PartnersBySession returns the other participant of each of the user's partner
+// workouts, keyed by the user's own session ID
+func (r *PartnerRepository) PartnersBySession(ctx context.Context, userID string) (map[string]*models.SessionPartner, error) {
+	links, err := r.list(ctx, `(p.host_id = $1 OR p.partner_id = $2) AND p.status = $3`, userID, userID, models.PartnerSessionJoined)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list partner sessions: %w", err)
+	}
+	partners := make(map[string]*models.SessionPartner, len(links))
+	for _, link := range links {
+		own := link.HostSessionID
+		if userID == link.PartnerID {
+			own = *link.PartnerSessionID
+		}
+		partners[own] = link.Other(userID)
+	}
+	return partners, nil
+}
+
+// GetPartner returns the other participant of one of the user's sessions, or nil
+// if it is not a partner workout
+func (r *PartnerRepository) GetPartner(ctx context.Context, userID, sessionID string) (*models.SessionPartner, error) {
+	links, err := r.list(ctx, `p.status = $1 AND ((p.host_id = $2 AND p.host_session_id = $3) OR (p.partner_id = $4 AND p.partner_session_id = $5))`,
+		models.PartnerSessionJoined, userID, sessionID, userID, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session partner: %w", err)
+	}
+	if len(links) == 0 {
+		return nil, nil
+	}
+	return links[0].Other(userID), nil
+}
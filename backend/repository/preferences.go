@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"liftoff/backend/models"
+	"liftoff/backend/units"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// PreferencesRepository stores users' display preferences
+type PreferencesRepository struct {
+	db        Pool
+	sqlite    *sql.DB
+	useSQLite bool
+}
+
+// NewPreferencesRepository creates a new preferences repository
+func NewPreferencesRepository(db Pool, sqlite *sql.DB, useSQLite bool) *PreferencesRepository {
+	if useSQLite {
+		return &PreferencesRepository{db: nil, sqlite: sqlite, useSQLite: true}
+	}
+	return &PreferencesRepository{db: db, sqlite: nil, useSQLite: false}
+}
+
+// GetPreferences returns the user's preferences, imperial until they choose otherwise
+func (r *PreferencesRepository) GetPreferences(ctx context.Context, userID string) (*models.UserPreferences, error) {
+	prefs := &models.UserPreferences{}
+	var updatedAt time.Time
+	var err error
+	if r.useSQLite {
+		err = r.sqlite.QueryRowContext(ctx, `SELECT units, updated_at FROM user_preferences WHERE user_id = ?`, userID).
+			Scan(&prefs.Units, &updatedAt)
+	} else {
+		err = r.db.QueryRow(ctx, `SELECT units, updated_at FROM user_preferences WHERE user_id = $1`, userID).
+			Scan(&prefs.Units, &updatedAt)
+	}
+	if errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgx.ErrNoRows) {
+		return &models.UserPreferences{Units: units.Imperial}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get preferences: %w", err)
+	}
+	prefs.UpdatedAt = &updatedAt
+	return prefs, nil
+}
+
+// SetPreferences saves the user's preferences
+func (r *PreferencesRepository) SetPreferences(ctx context.Context, userID string, prefs *models.UserPreferences) error {
+	now := time.Now()
+	var err error
+	if r.useSQLite {
+		_, err = r.sqlite.ExecContext(ctx, `
+			INSERT INTO user_preferences (user_id, units, updated_at) VALUES (?, ?, ?)
+			ON CONFLICT (user_id) DO UPDATE SET units = excluded.units, updated_at = excluded.updated_at`,
+			userID, prefs.Units, now)
+	} else {
+		_, err = r.db.Exec(ctx, `
+			INSERT INTO user_preferences (user_id, units, updated_at) VALUES ($1, $2, $3)
+			ON CONFLICT (user_id) DO UPDATE SET units = excluded.units, updated_at = excluded.updated_at`,
+			userID, prefs.Units, now)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save preferences: %w", err)
+	}
+	prefs.UpdatedAt = &now
+	return nil
+}
+
+// Units returns the user's preferred unit system, for units.Middleware
+func (r *PreferencesRepository) Units(ctx context.Context, userID string) (string, error) {
+	prefs, err := r.GetPreferences(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	return prefs.Units, nil
+}
@@ -0,0 +1,437 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"liftoff/backend/ids"
+	"liftoff/backend/models"
+)
+
+var (
+	// ErrProgramNotFound is returned when a program does not exist or belongs to another user
+	ErrProgramNotFound = errors.New("program not found")
+	// ErrNotEnrolled is returned when the user has no active program enrollment
+	ErrNotEnrolled = errors.New("not enrolled in a program")
+	// ErrProgramDayTaken is returned when today's program workout was started, or
+	// the week advanced, since the enrollment was read
+	ErrProgramDayTaken = errors.New("today's program workout was already started")
+	// ErrWeekAdvanced is returned when the enrollment moved on, or ended, since it was read
+	ErrWeekAdvanced = errors.New("program week was already advanced")
+)
+
+// ProgramRepository stores multi-week training programs and users' progress through them
+type ProgramRepository struct {
+	db        Pool
+	sqlite    *sql.DB
+	useSQLite bool
+	workout   *WorkoutRepository
+}
+
+// NewProgramRepository creates a new program repository
+func NewProgramRepository(db Pool, sqlite *sql.DB, useSQLite bool, workout *WorkoutRepository) *ProgramRepository {
+	if useSQLite {
+		return &ProgramRepository{db: nil, sqlite: sqlite, useSQLite: true, workout: workout}
+	}
+	return &ProgramRepository{db: db, sqlite: nil, useSQLite: false, workout: workout}
+}
+
+// GetPrograms lists the user's programs, newest first, with their workouts and weeks
+func (r *ProgramRepository) GetPrograms(ctx context.Context, userID string) ([]*models.Program, error) {
+	programs := []*models.Program{}
+	err := eachRow(ctx, r.db, r.sqlite, r.useSQLite, `
+		SELECT id, user_id, name, description, created_at, updated_at
+		FROM programs WHERE user_id = $1 ORDER BY created_at DESC`,
+		[]interface{}{userID}, func(scan func(...interface{}) error) error {
+			var p models.Program
+			if err := scan(&p.ID, &p.UserID, &p.Name, &p.Description, &p.CreatedAt, &p.UpdatedAt); err != nil {
+				return err
+			}
+			programs = append(programs, &p)
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get programs: %w", err)
+	}
+	for _, p := range programs {
+		if err := r.loadProgramDetails(ctx, p); err != nil {
+			return nil, err
+		}
+	}
+	return programs, nil
+}
+
+// GetProgram returns one of the user's programs, or ErrProgramNotFound
+func (r *ProgramRepository) GetProgram(ctx context.Context, userID, id string) (*models.Program, error) {
+	var found *models.Program
+	err := eachRow(ctx, r.db, r.sqlite, r.useSQLite, `
+		SELECT id, user_id, name, description, created_at, updated_at
+		FROM programs WHERE id = $1 AND user_id = $2`,
+		[]interface{}{id, userID}, func(scan func(...interface{}) error) error {
+			var p models.Program
+			if err := scan(&p.ID, &p.UserID, &p.Name, &p.Description, &p.CreatedAt, &p.UpdatedAt); err != nil {
+				return err
+			}
+			found = &p
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get program: %w", err)
+	}
+	if found == nil {
+		return nil, ErrProgramNotFound
+	}
+	if err := r.loadProgramDetails(ctx, found); err != nil {
+		return nil, err
+	}
+	return found, nil
+}
+
+// loadProgramDetails fills in a program's workouts and weeks. Workouts deleted
+// since the program was saved are left out and the remaining days renumbered.
+func (r *ProgramRepository) loadProgramDetails(ctx context.Context, p *models.Program) error {
+	p.Workouts = []*models.ProgramWorkout{}
+	err := eachRow(ctx, r.db, r.sqlite, r.useSQLite, `
+		SELECT pw.workout_id, w.name
+		FROM program_workouts pw
+		JOIN workouts w ON w.id = pw.workout_id
+		WHERE pw.program_id = $1 ORDER BY pw.day`,
+		[]interface{}{p.ID}, func(scan func(...interface{}) error) error {
+			w := &models.ProgramWorkout{Day: len(p.Workouts) + 1}
+			if err := scan(&w.WorkoutID, &w.WorkoutName); err != nil {
+				return err
+			}
+			p.Workouts = append(p.Workouts, w)
+			return nil
+		})
+	if err != nil {
+		return fmt.Errorf("failed to get program workouts: %w", err)
+	}
+
+	p.Weeks = []*models.ProgramWeek{}
+	err = eachRow(ctx, r.db, r.sqlite, r.useSQLite, `
+		SELECT week, weight_percent, sets_delta, reps_delta, notes
+		FROM program_weeks WHERE program_id = $1 ORDER BY week`,
+		[]interface{}{p.ID}, func(scan func(...interface{}) error) error {
+			var w models.ProgramWeek
+			if err := scan(&w.Week, &w.WeightPercent, &w.SetsDelta, &w.RepsDelta, &w.Notes); err != nil {
+				return err
+			}
+			p.Weeks = append(p.Weeks, &w)
+			return nil
+		})
+	if err != nil {
+		return fmt.Errorf("failed to get program weeks: %w", err)
+	}
+	return nil
+}
+
+// CreateProgram saves a validated program for p.UserID and returns it as stored
+//
+// Returns ErrWorkoutNotFound if any of its workouts is not the user's.
+func (r *ProgramRepository) CreateProgram(ctx context.Context, p *models.Program) (*models.Program, error) {
+	if err := r.checkWorkouts(ctx, p); err != nil {
+		return nil, err
+	}
+	id := ids.New()
+	now := time.Now()
+	err := inTx(ctx, r.db, r.sqlite, r.useSQLite, func(tx mergeTx) error {
+		if _, err := tx.exec(`INSERT INTO programs (id, user_id, name, description, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6)`, id, p.UserID, p.Name, p.Description, now, now); err != nil {
+			return err
+		}
+		return saveProgramDetails(tx, id, p)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create program: %w", err)
+	}
+	return r.GetProgram(ctx, p.UserID, id)
+}
+
+// UpdateProgram replaces the name, description, workouts and weeks of one of
+// the user's programs. Enrollments keep their place; one past the new last
+// week trains that week until advanced.
+//
+// Returns ErrProgramNotFound if the program is not the user's, or
+// ErrWorkoutNotFound if any of its workouts is not.
+func (r *ProgramRepository) UpdateProgram(ctx context.Context, p *models.Program) (*models.Program, error) {
+	if err := r.checkWorkouts(ctx, p); err != nil {
+		return nil, err
+	}
+	err := inTx(ctx, r.db, r.sqlite, r.useSQLite, func(tx mergeTx) error {
+		updated, err := tx.exec(`UPDATE programs SET name = $1, description = $2, updated_at = $3 WHERE id = $4 AND user_id = $5`,
+			p.Name, p.Description, time.Now(), p.ID, p.UserID)
+		if err != nil {
+			return err
+		}
+		if updated == 0 {
+			return ErrProgramNotFound
+		}
+		return saveProgramDetails(tx, p.ID, p)
+	})
+	if errors.Is(err, ErrProgramNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update program: %w", err)
+	}
+	return r.GetProgram(ctx, p.UserID, p.ID)
+}
+
+// saveProgramDetails replaces a program's workouts and weeks
+func saveProgramDetails(tx mergeTx, programID string, p *models.Program) error {
+	for _, stmt := range []string{
+		`DELETE FROM program_workouts WHERE program_id = $1`,
+		`DELETE FROM program_weeks WHERE program_id = $1`,
+	} {
+		if _, err := tx.exec(stmt, programID); err != nil {
+			return err
+		}
+	}
+	for _, w := range p.Workouts {
+		if _, err := tx.exec(`INSERT INTO program_workouts (program_id, day, workout_id) VALUES ($1, $2, $3)`,
+			programID, w.Day, w.WorkoutID); err != nil {
+			return err
+		}
+	}
+	for _, w := range p.Weeks {
+		if _, err := tx.exec(`INSERT INTO program_weeks (program_id, week, weight_percent, sets_delta, reps_delta, notes)
+			VALUES ($1, $2, $3, $4, $5, $6)`, programID, w.Week, w.WeightPercent, w.SetsDelta, w.RepsDelta, w.Notes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkWorkouts returns ErrWorkoutNotFound unless every workout of the program is the user's
+func (r *ProgramRepository) checkWorkouts(ctx context.Context, p *models.Program) error {
+	for _, w := range p.Workouts {
+		if err := r.workout.checkWorkoutOwner(ctx, p.UserID, w.WorkoutID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteProgram deletes one of the user's programs and every enrollment in it;
+// its workouts are kept
+func (r *ProgramRepository) DeleteProgram(ctx context.Context, userID, id string) error {
+	err := inTx(ctx, r.db, r.sqlite, r.useSQLite, func(tx mergeTx) error {
+		deleted, err := tx.exec(`DELETE FROM programs WHERE id = $1 AND user_id = $2`, id, userID)
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			return ErrProgramNotFound
+		}
+		for _, stmt := range []string{
+			`DELETE FROM program_enrollments WHERE program_id = $1`,
+			`DELETE FROM program_workouts WHERE program_id = $1`,
+			`DELETE FROM program_weeks WHERE program_id = $1`,
+		} {
+			if _, err := tx.exec(stmt, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, ErrProgramNotFound) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete program: %w", err)
+	}
+	return nil
+}
+
+// Enroll starts the user on week one of one of their programs, leaving any
+// program they were enrolled in
+//
+// Returns ErrProgramNotFound if the program is not the user's.
+func (r *ProgramRepository) Enroll(ctx context.Context, userID, programID string) (*models.ProgramEnrollment, error) {
+	if _, err := r.GetProgram(ctx, userID, programID); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	err := inTx(ctx, r.db, r.sqlite, r.useSQLite, func(tx mergeTx) error {
+		if _, err := tx.exec(`UPDATE program_enrollments SET status = $1, updated_at = $2 WHERE user_id = $3 AND status = $4`,
+			models.EnrollmentLeft, now, userID, models.EnrollmentActive); err != nil {
+			return err
+		}
+		_, err := tx.exec(`INSERT INTO program_enrollments (id, user_id, program_id, week, days_done, status, started_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+			ids.New(), userID, programID, 1, 0, models.EnrollmentActive, now, now)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to enroll in program: %w", err)
+	}
+	return r.GetEnrollment(ctx, userID)
+}
+
+// GetEnrollment returns the user's active enrollment, or ErrNotEnrolled
+func (r *ProgramRepository) GetEnrollment(ctx context.Context, userID string) (*models.ProgramEnrollment, error) {
+	var found *models.ProgramEnrollment
+	err := eachRow(ctx, r.db, r.sqlite, r.useSQLite, `
+		SELECT e.id, e.user_id, e.program_id, p.name, e.week, e.days_done, e.status, e.started_at, e.completed_at, e.updated_at
+		FROM program_enrollments e
+		JOIN programs p ON p.id = e.program_id
+		WHERE e.user_id = $1 AND e.status = $2
+		ORDER BY e.started_at DESC LIMIT 1`,
+		[]interface{}{userID, models.EnrollmentActive}, func(scan func(...interface{}) error) error {
+			var e models.ProgramEnrollment
+			if err := scan(&e.ID, &e.UserID, &e.ProgramID, &e.ProgramName, &e.Week, &e.DaysDone, &e.Status,
+				&e.StartedAt, &e.CompletedAt, &e.UpdatedAt); err != nil {
+				return err
+			}
+			found = &e
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get program enrollment: %w", err)
+	}
+	if found == nil {
+		return nil, ErrNotEnrolled
+	}
+	return found, nil
+}
+
+// AdvanceWeek moves the user's enrollment on to the next week, whether or not
+// every workout of the current one was done. Advancing past the last week
+// completes the program. The week only moves on if the enrollment is still
+// active at the week it was read at, so two advances at once cannot skip a
+// week; otherwise ErrWeekAdvanced is returned.
+func (r *ProgramRepository) AdvanceWeek(ctx context.Context, userID string) (*models.ProgramEnrollment, error) {
+	e, err := r.GetEnrollment(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	program, err := r.GetProgram(ctx, userID, e.ProgramID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	week := e.Week
+	if e.Week >= len(program.Weeks) {
+		e.Status, e.CompletedAt = models.EnrollmentCompleted, &now
+	} else {
+		e.Week++
+		e.DaysDone = 0
+	}
+	e.UpdatedAt = now
+	affected, err := exec(ctx, r.db, r.sqlite, r.useSQLite, `
+		UPDATE program_enrollments SET week = $1, days_done = $2, status = $3, completed_at = $4, updated_at = $5
+		WHERE id = $6 AND status = $7 AND week = $8`,
+		e.Week, e.DaysDone, e.Status, e.CompletedAt, e.UpdatedAt, e.ID, models.EnrollmentActive, week)
+	if err != nil {
+		return nil, fmt.Errorf("failed to advance program week: %w", err)
+	}
+	if affected == 0 {
+		return nil, ErrWeekAdvanced
+	}
+	return e, nil
+}
+
+// LeaveProgram ends the user's active enrollment early
+func (r *ProgramRepository) LeaveProgram(ctx context.Context, userID string) error {
+	var affected int64
+	now := time.Now()
+	if r.useSQLite {
+		result, err := r.sqlite.ExecContext(ctx, `UPDATE program_enrollments SET status = ?, updated_at = ? WHERE user_id = ? AND status = ?`,
+			models.EnrollmentLeft, now, userID, models.EnrollmentActive)
+		if err != nil {
+			return fmt.Errorf("failed to leave program: %w", err)
+		}
+		affected, _ = result.RowsAffected()
+	} else {
+		tag, err := r.db.Exec(ctx, `UPDATE program_enrollments SET status = $1, updated_at = $2 WHERE user_id = $3 AND status = $4`,
+			models.EnrollmentLeft, now, userID, models.EnrollmentActive)
+		if err != nil {
+			return fmt.Errorf("failed to leave program: %w", err)
+		}
+		affected = tag.RowsAffected()
+	}
+	if affected == 0 {
+		return ErrNotEnrolled
+	}
+	return nil
+}
+
+// GetToday returns the workout an enrolled user trains next: the first of the
+// current week's workouts not yet started from the program, with the week's
+// progression applied. Once all have been, the week is reported complete.
+func (r *ProgramRepository) GetToday(ctx context.Context, userID string) (*models.ProgramToday, error) {
+	e, err := r.GetEnrollment(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	program, err := r.GetProgram(ctx, userID, e.ProgramID)
+	if err != nil {
+		return nil, err
+	}
+	today := &models.ProgramToday{Enrollment: e, WeeksTotal: len(program.Weeks)}
+	if len(program.Weeks) > 0 {
+		today.Week = program.Weeks[min(e.Week, len(program.Weeks))-1]
+	} else {
+		today.Week = &models.ProgramWeek{Week: e.Week, WeightPercent: 100}
+	}
+	if e.DaysDone >= len(program.Workouts) {
+		today.WeekComplete = true
+		return today, nil
+	}
+
+	day := program.Workouts[e.DaysDone]
+	workout, err := r.workout.GetWorkout(ctx, userID, day.WorkoutID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get program workout: %w", err)
+	}
+	for i := range workout.Exercises {
+		workout.Exercises[i] = today.Week.Apply(workout.Exercises[i])
+	}
+	today.Day, today.Workout = day.Day, workout
+	return today, nil
+}
+
+// CompleteDay counts today's workout of the enrollment e, as read by GetToday,
+// as started. The count only moves on while the enrollment is still at that
+// week and day, so two sessions cannot both be started from the same workout;
+// otherwise ErrProgramDayTaken is returned.
+func (r *ProgramRepository) CompleteDay(ctx context.Context, userID string, e *models.ProgramEnrollment) error {
+	return r.moveDay(ctx, userID, e, e.DaysDone, e.DaysDone+1)
+}
+
+// UndoDay takes back a CompleteDay of the enrollment e, for when the session
+// it was counted for could not be started
+func (r *ProgramRepository) UndoDay(ctx context.Context, userID string, e *models.ProgramEnrollment) error {
+	return r.moveDay(ctx, userID, e, e.DaysDone+1, e.DaysDone)
+}
+
+// moveDay sets the days done of the enrollment e from one count to another
+func (r *ProgramRepository) moveDay(ctx context.Context, userID string, e *models.ProgramEnrollment, from, to int) error {
+	var affected int64
+	now := time.Now()
+	if r.useSQLite {
+		result, err := r.sqlite.ExecContext(ctx, `UPDATE program_enrollments SET days_done = ?, updated_at = ?
+			WHERE id = ? AND user_id = ? AND status = ? AND week = ? AND days_done = ?`,
+			to, now, e.ID, userID, models.EnrollmentActive, e.Week, from)
+		if err != nil {
+			return fmt.Errorf("failed to record program workout: %w", err)
+		}
+		affected, _ = result.RowsAffected()
+	} else {
+		tag, err := r.db.Exec(ctx, `UPDATE program_enrollments SET days_done = $1, updated_at = $2
+			WHERE id = $3 AND user_id = $4 AND status = $5 AND week = $6 AND days_done = $7`,
+			to, now, e.ID, userID, models.EnrollmentActive, e.Week, from)
+		if err != nil {
+			return fmt.Errorf("failed to record program workout: %w", err)
+		}
+		affected = tag.RowsAffected()
+	}
+	if affected == 0 {
+		return ErrProgramDayTaken
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"liftoff/backend/analytics"
+	"liftoff/backend/models"
+)
+
+// ErrProgressionRuleNotFound is returned when an exercise has no progression rule
+var ErrProgressionRuleNotFound = errors.New("progression rule not found")
+
+// ErrProgressionNotApplicable is returned when a rule is set on a timed or cardio exercise
+var ErrProgressionNotApplicable = errors.New("progression rules apply to rep exercises only")
+
+// ProgressionRepository stores per-exercise progression rules and applies them as sessions end
+type ProgressionRepository struct {
+	db        Pool
+	sqlite    *sql.DB
+	useSQLite bool
+	workout   *WorkoutRepository
+	session   *SessionRepository
+}
+
+// NewProgressionRepository creates a new progression repository
+func NewProgressionRepository(db Pool, sqlite *sql.DB, useSQLite bool, workout *WorkoutRepository, session *SessionRepository) *ProgressionRepository {
+	if useSQLite {
+		return &ProgressionRepository{db: nil, sqlite: sqlite, useSQLite: true, workout: workout, session: session}
+	}
+	return &ProgressionRepository{db: db, sqlite: nil, useSQLite: false, workout: workout, session: session}
+}
+
+// progressionRuleSelect reads rules with their exercise's name and workout.
+// Rules whose exercise is gone are left out.
+const progressionRuleSelect = `
+	SELECT r.exercise_id, r.user_id, e.name, e.workout_id, r.weight_increment, r.failure_limit,
+		r.deload_percent, r.failures, COALESCE(r.last_session_id, ''), r.created_at, r.updated_at
+	FROM progression_rules r
+	JOIN exercises e ON e.id = r.exercise_id`
+
+func scanProgressionRule(scan func(...interface{}) error) (*models.ProgressionRule, error) {
+	var p models.ProgressionRule
+	if err := scan(&p.ExerciseID, &p.UserID, &p.ExerciseName, &p.WorkoutID, &p.WeightIncrement, &p.FailureLimit,
+		&p.DeloadPercent, &p.Failures, &p.LastSessionID, &p.CreatedAt, &p.UpdatedAt); err != nil {
+		return nil, fmt.Errorf("failed to scan progression rule: %w", err)
+	}
+	return &p, nil
+}
+
+// GetRules lists the user's progression rules by exercise name
+func (r *ProgressionRepository) GetRules(ctx context.Context, userID string) ([]*models.ProgressionRule, error) {
+	rules := []*models.ProgressionRule{}
+	err := eachRow(ctx, r.db, r.sqlite, r.useSQLite,
+		progressionRuleSelect+` WHERE r.user_id = $1 ORDER BY e.name, e.workout_id`,
+		[]interface{}{userID}, func(scan func(...interface{}) error) error {
+			p, err := scanProgressionRule(scan)
+			if err != nil {
+				return err
+			}
+			rules = append(rules, p)
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get progression rules: %w", err)
+	}
+	return rules, nil
+}
+
+// GetRule returns the rule of one of the user's exercises, or ErrProgressionRuleNotFound
+func (r *ProgressionRepository) GetRule(ctx context.Context, userID, exerciseID string) (*models.ProgressionRule, error) {
+	var found *models.ProgressionRule
+	err := eachRow(ctx, r.db, r.sqlite, r.useSQLite,
+		progressionRuleSelect+` WHERE r.exercise_id = $1 AND r.user_id = $2`,
+		[]interface{}{exerciseID, userID}, func(scan func(...interface{}) error) error {
+			p, err := scanProgressionRule(scan)
+			found = p
+			return err
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get progression rule: %w", err)
+	}
+	if found == nil {
+		return nil, ErrProgressionRuleNotFound
+	}
+	return found, nil
+}
+
+// SetRule creates or replaces the rule of one of the user's exercises. A
+// replaced rule keeps its count of missed sessions.
+//
+// Returns ErrExerciseNotFound if the exercise is not the user's, or
+// ErrProgressionNotApplicable if it is timed or cardio.
+func (r *ProgressionRepository) SetRule(ctx context.Context, rule *models.ProgressionRule) (*models.ProgressionRule, error) {
+	exercise, err := r.workout.GetUserExercise(ctx, rule.UserID, rule.ExerciseID)
+	if err != nil {
+		return nil, err
+	}
+	if exercise.IsTimed() || exercise.IsCardio() {
+		return nil, ErrProgressionNotApplicable
+	}
+	now := time.Now()
+	if r.useSQLite {
+		_, err = r.sqlite.ExecContext(ctx, `
+			INSERT INTO progression_rules (exercise_id, user_id, weight_increment, failure_limit, deload_percent, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (exercise_id) DO UPDATE SET
+				weight_increment = excluded.weight_increment,
+				failure_limit = excluded.failure_limit,
+				deload_percent = excluded.deload_percent,
+				updated_at = excluded.updated_at`,
+			rule.ExerciseID, rule.UserID, rule.WeightIncrement, rule.FailureLimit, rule.DeloadPercent, now, now)
+	} else {
+		_, err = r.db.Exec(ctx, `
+			INSERT INTO progression_rules (exercise_id, user_id, weight_increment, failure_limit, deload_percent, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (exercise_id) DO UPDATE SET
+				weight_increment = EXCLUDED.weight_increment,
+				failure_limit = EXCLUDED.failure_limit,
+				deload_percent = EXCLUDED.deload_percent,
+				updated_at = EXCLUDED.updated_at`,
+			rule.ExerciseID, rule.UserID, rule.WeightIncrement, rule.FailureLimit, rule.DeloadPercent, now, now)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to save progression rule: %w", err)
+	}
+	return r.GetRule(ctx, rule.UserID, rule.ExerciseID)
+}
+
+// DeleteRule removes the rule of one of the user's exercises; its weight is kept
+func (r *ProgressionRepository) DeleteRule(ctx context.Context, userID, exerciseID string) error {
+	var affected int64
+	if r.useSQLite {
+		result, err := r.sqlite.ExecContext(ctx, `DELETE FROM progression_rules WHERE exercise_id = ? AND user_id = ?`, exerciseID, userID)
+		if err != nil {
+			return fmt.Errorf("failed to delete progression rule: %w", err)
+		}
+		affected, _ = result.RowsAffected()
+	} else {
+		tag, err := r.db.Exec(ctx, `DELETE FROM progression_rules WHERE exercise_id = $1 AND user_id = $2`, exerciseID, userID)
+		if err != nil {
+			return fmt.Errorf("failed to delete progression rule: %w", err)
+		}
+		affected = tag.RowsAffected()
+	}
+	if affected == 0 {
+		return ErrProgressionRuleNotFound
+	}
+	return nil
+}
+
+// ApplyProgression evaluates the rules of the exercises trained in one of the
+// user's ended sessions, saving each exercise's new weight and the rule's count
+// of misses. A rule skips the session it last counted, so ending a session
+// twice counts it once. Exercises without a rule, or for which the rule does
+// not apply, are left out of the results.
+func (r *ProgressionRepository) ApplyProgression(ctx context.Context, userID, sessionID string) ([]models.ProgressionResult, error) {
+	rules, err := r.GetRules(ctx, userID)
+	if err != nil || len(rules) == 0 {
+		return nil, err
+	}
+	byExercise := make(map[string]*models.ProgressionRule, len(rules))
+	for _, rule := range rules {
+		byExercise[rule.ExerciseID] = rule
+	}
+	session, err := r.session.GetSessionWithExercises(ctx, userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.IsActive {
+		return nil, nil
+	}
+
+	var results []models.ProgressionResult
+	err = inTx(ctx, r.db, r.sqlite, r.useSQLite, func(tx mergeTx) error {
+		now := time.Now()
+		for _, se := range session.Exercises {
+			rule := byExercise[se.ExerciseID]
+			if rule == nil || se.Exercise == nil || rule.LastSessionID == session.ID {
+				continue
+			}
+			result, ok := analytics.EvaluateProgression(*rule, *se.Exercise, se.Sets)
+			if !ok {
+				continue
+			}
+			if _, err := tx.exec(`UPDATE progression_rules SET failures = $1, last_session_id = $2, updated_at = $3 WHERE exercise_id = $4`,
+				result.Failures, session.ID, now, rule.ExerciseID); err != nil {
+				return err
+			}
+			if result.Weight != result.PreviousWeight {
+				if _, err := tx.exec(`UPDATE exercises SET weight = $1, updated_at = $2 WHERE id = $3`,
+					result.Weight, now, se.ExerciseID); err != nil {
+					return err
+				}
+			}
+			results = append(results, result)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply progression rules: %w", err)
+	}
+	return results, nil
+}
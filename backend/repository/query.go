@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// rebind rewrites the $1..$n placeholders of a query for SQLite's ?. Arguments
+// bind by position, so each $n may appear only once and in order.
+func rebind(query string, n int) string {
+	for i := n; i >= 1; i-- {
+		query = strings.ReplaceAll(query, fmt.Sprintf("$%d", i), "?")
+	}
+	return query
+}
+
+// exec runs a statement written with $n placeholders and returns the number of
+// rows it affected
+func exec(ctx context.Context, db Pool, sqlite *sql.DB, useSQLite bool, query string, args ...interface{}) (int64, error) {
+	if useSQLite {
+		result, err := sqlite.ExecContext(ctx, rebind(query, len(args)), args...)
+		if err != nil {
+			return 0, err
+		}
+		return result.RowsAffected()
+	}
+	tag, err := db.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// eachRow runs a query written with $n placeholders and hands every row to scan
+// as it is read, so large results are never held in memory
+func eachRow(ctx context.Context, db Pool, sqlite *sql.DB, useSQLite bool, query string, args []interface{}, scan func(func(...interface{}) error) error) error {
+	if useSQLite {
+		rows, err := sqlite.QueryContext(ctx, rebind(query, len(args)), args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			if err := scan(rows.Scan); err != nil {
+				return err
+			}
+		}
+		return rows.Err()
+	}
+
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := scan(rows.Scan); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
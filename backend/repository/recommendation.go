@@ -0,0 +1,367 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"liftoff/backend/models"
+)
+
+// profileWindow is how far back training history is considered for recommendations
+const profileWindow = 28 * 24 * time.Hour
+
+// RecommendationRepository computes and stores template recommendations
+type RecommendationRepository struct {
+	db        Pool
+	sqlite    *sql.DB
+	useSQLite bool
+	workout   *WorkoutRepository
+	taxonomy  *TaxonomyRepository
+}
+
+// NewRecommendationRepository creates a new recommendation repository
+func NewRecommendationRepository(db Pool, sqlite *sql.DB, useSQLite bool, workout *WorkoutRepository, taxonomy *TaxonomyRepository) *RecommendationRepository {
+	if useSQLite {
+		return &RecommendationRepository{db: nil, sqlite: sqlite, useSQLite: true, workout: workout, taxonomy: taxonomy}
+	}
+	return &RecommendationRepository{db: db, sqlite: nil, useSQLite: false, workout: workout, taxonomy: taxonomy}
+}
+
+// GetTemplateRecommendations returns stored recommendations, computing them if none exist yet
+func (r *RecommendationRepository) GetTemplateRecommendations(ctx context.Context, userID string, limit int) ([]*models.TemplateRecommendation, error) {
+	recs, err := r.getStored(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(recs) == 0 {
+		recs, err = r.RefreshUser(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if limit > 0 && len(recs) > limit {
+		recs = recs[:limit]
+	}
+	return recs, nil
+}
+
+// RefreshAll recomputes recommendations for every user who trained within the profile window.
+// Intended to run from the jobs scheduler.
+func (r *RecommendationRepository) RefreshAll(ctx context.Context) error {
+	userIDs, err := r.recentlyActiveUsers(ctx, time.Now().Add(-profileWindow))
+	if err != nil {
+		return err
+	}
+	for _, userID := range userIDs {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if _, err := r.RefreshUser(ctx, userID); err != nil {
+			return fmt.Errorf("refresh recommendations for %s: %w", userID, err)
+		}
+	}
+	return nil
+}
+
+// RefreshUser recomputes and stores recommendations for a single user
+func (r *RecommendationRepository) RefreshUser(ctx context.Context, userID string) ([]*models.TemplateRecommendation, error) {
+	tax, err := r.taxonomy.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	profile, err := r.trainingProfile(ctx, userID, tax)
+	if err != nil {
+		return nil, err
+	}
+	workoutTemplates, err := r.workout.GetWorkoutTemplates(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	recs := r.scoreTemplates(profile, tax, workoutTemplates, time.Now())
+	if err := r.store(ctx, userID, recs); err != nil {
+		return nil, err
+	}
+	return recs, nil
+}
+
+// GetTrainingProfile summarises the user's frequency, session length and favoured muscle groups
+func (r *RecommendationRepository) GetTrainingProfile(ctx context.Context, userID string) (*models.TrainingProfile, error) {
+	tax, err := r.taxonomy.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.trainingProfile(ctx, userID, tax)
+}
+
+// trainingProfile counts training volume by the top-level taxonomy category
+func (r *RecommendationRepository) trainingProfile(ctx context.Context, userID string, tax *models.Taxonomy) (*models.TrainingProfile, error) {
+	since := time.Now().Add(-profileWindow)
+	profile := &models.TrainingProfile{CategoryShare: map[string]float64{}}
+
+	var sessionQuery, setQuery string
+	if r.useSQLite {
+		sessionQuery = `SELECT started_at, ended_at FROM workout_sessions
+			WHERE user_id = ? AND ended_at IS NOT NULL AND started_at >= ?`
+		setQuery = `SELECT e.name, COUNT(*) FROM exercise_sets es
+			JOIN session_exercises se ON es.session_exercise_id = se.id
+			JOIN workout_sessions ws ON se.session_id = ws.id
+			JOIN exercises e ON se.exercise_id = e.id
+			WHERE ws.user_id = ? AND es.completed = 1 AND es.set_type <> 'warmup' AND ws.started_at >= ?
+			GROUP BY e.name`
+	} else {
+		sessionQuery = `SELECT started_at, ended_at FROM workout_sessions
+			WHERE user_id = $1 AND ended_at IS NOT NULL AND started_at >= $2`
+		setQuery = `SELECT e.name, COUNT(*) FROM exercise_sets es
+			JOIN session_exercises se ON es.session_exercise_id = se.id
+			JOIN workout_sessions ws ON se.session_id = ws.id
+			JOIN exercises e ON se.exercise_id = e.id
+			WHERE ws.user_id = $1 AND es.completed = true AND es.set_type <> 'warmup' AND ws.started_at >= $2
+			GROUP BY e.name`
+	}
+
+	var totalMinutes float64
+	err := eachRow(ctx, r.db, r.sqlite, r.useSQLite, sessionQuery, []interface{}{userID, since}, func(scan func(...interface{}) error) error {
+		var started time.Time
+		var ended *time.Time
+		if err := scan(&started, &ended); err != nil {
+			return err
+		}
+		profile.CompletedSessions++
+		if ended != nil {
+			totalMinutes += ended.Sub(started).Minutes()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sessions for profile: %w", err)
+	}
+
+	var totalSets float64
+	err = eachRow(ctx, r.db, r.sqlite, r.useSQLite, setQuery, []interface{}{userID, since}, func(scan func(...interface{}) error) error {
+		var name string
+		var count int
+		if err := scan(&name, &count); err != nil {
+			return err
+		}
+		if category := r.workout.ExerciseCategory(tax, name); category != "" {
+			profile.CategoryShare[category] += float64(count)
+			totalSets += float64(count)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sets for profile: %w", err)
+	}
+
+	for category, count := range profile.CategoryShare {
+		profile.CategoryShare[category] = count / totalSets
+	}
+	weeks := profileWindow.Hours() / (24 * 7)
+	profile.SessionsPerWeek = float64(profile.CompletedSessions) / weeks
+	profile.Level = levelForFrequency(profile.SessionsPerWeek)
+	if profile.CompletedSessions > 0 {
+		profile.AvgSessionMinutes = totalMinutes / float64(profile.CompletedSessions)
+	}
+	return profile, nil
+}
+
+// scoreTemplates ranks built-in workout and routine templates against a training profile.
+// Score = 50% muscle-group overlap + 30% duration fit + 20% difficulty/frequency fit.
+func (r *RecommendationRepository) scoreTemplates(profile *models.TrainingProfile, tax *models.Taxonomy, workoutTemplates []*models.WorkoutTemplate, now time.Time) []*models.TemplateRecommendation {
+	level := profile.Level
+	var recs []*models.TemplateRecommendation
+
+	for _, t := range workoutTemplates {
+		var reasons []string
+		muscle := r.muscleOverlap(profile, tax, t.Exercises)
+		if muscle >= 0.5 && len(profile.CategoryShare) > 0 {
+			reasons = append(reasons, "Targets muscle groups you train most")
+		}
+
+		duration := 0.5
+		if profile.AvgSessionMinutes > 0 {
+			duration = 1 - math.Abs(float64(t.Duration)-profile.AvgSessionMinutes)/math.Max(float64(t.Duration), profile.AvgSessionMinutes)
+			if duration >= 0.75 {
+				reasons = append(reasons, fmt.Sprintf("Fits your typical %d-minute session", int(math.Round(profile.AvgSessionMinutes))))
+			}
+		}
+
+		difficulty := 0.0
+		if t.Difficulty == level {
+			difficulty = 1
+			reasons = append(reasons, fmt.Sprintf("Matches your %s training frequency", level))
+		}
+
+		recs = append(recs, &models.TemplateRecommendation{
+			TemplateID: t.ID,
+			Kind:       models.RecommendationKindWorkout,
+			Name:       t.Name,
+			Score:      round2(0.5*muscle + 0.3*duration + 0.2*difficulty),
+			Reasons:    nonNilReasons(reasons),
+			ComputedAt: now,
+		})
+	}
+
+	for _, t := range getRoutineTemplates() {
+		var reasons []string
+		var exercises []models.Exercise
+		for _, w := range t.Workouts {
+			exercises = append(exercises, w.Exercises...)
+		}
+		muscle := r.muscleOverlap(profile, tax, exercises)
+		if muscle >= 0.5 && len(profile.CategoryShare) > 0 {
+			reasons = append(reasons, "Targets muscle groups you train most")
+		}
+
+		// A routine fits when its number of days is close to how often the user trains
+		frequency := 0.5
+		if profile.SessionsPerWeek > 0 {
+			days := float64(len(t.Workouts))
+			frequency = 1 - math.Min(1, math.Abs(days-profile.SessionsPerWeek)/math.Max(days, profile.SessionsPerWeek))
+			if frequency >= 0.75 {
+				reasons = append(reasons, fmt.Sprintf("%d-day split matches your weekly frequency", len(t.Workouts)))
+			}
+		}
+
+		recs = append(recs, &models.TemplateRecommendation{
+			TemplateID: t.ID,
+			Kind:       models.RecommendationKindRoutine,
+			Name:       t.Name,
+			Score:      round2(0.5*muscle + 0.5*frequency),
+			Reasons:    nonNilReasons(reasons),
+			ComputedAt: now,
+		})
+	}
+
+	sort.SliceStable(recs, func(i, j int) bool { return recs[i].Score > recs[j].Score })
+	return recs
+}
+
+// muscleOverlap returns the share of the user's training volume covered by the exercises' categories
+func (r *RecommendationRepository) muscleOverlap(profile *models.TrainingProfile, tax *models.Taxonomy, exercises []models.Exercise) float64 {
+	if len(profile.CategoryShare) == 0 {
+		return 0.5 // no history: neutral
+	}
+	seen := map[string]bool{}
+	var overlap float64
+	for _, e := range exercises {
+		category := r.workout.ExerciseCategory(tax, e.Name)
+		if category == "" || seen[category] {
+			continue
+		}
+		seen[category] = true
+		overlap += profile.CategoryShare[category]
+	}
+	return math.Min(1, overlap)
+}
+
+// levelForFrequency maps weekly sessions to a template difficulty
+func levelForFrequency(sessionsPerWeek float64) string {
+	switch {
+	case sessionsPerWeek >= 4:
+		return "advanced"
+	case sessionsPerWeek >= 2:
+		return "intermediate"
+	default:
+		return "beginner"
+	}
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+func nonNilReasons(reasons []string) []string {
+	if reasons == nil {
+		return []string{}
+	}
+	return reasons
+}
+
+func (r *RecommendationRepository) recentlyActiveUsers(ctx context.Context, since time.Time) ([]string, error) {
+	query := `SELECT DISTINCT user_id FROM workout_sessions WHERE started_at >= $1`
+	if r.useSQLite {
+		query = `SELECT DISTINCT user_id FROM workout_sessions WHERE started_at >= ?`
+	}
+	var ids []string
+	err := eachRow(ctx, r.db, r.sqlite, r.useSQLite, query, []interface{}{since}, func(scan func(...interface{}) error) error {
+		var id string
+		if err := scan(&id); err != nil {
+			return err
+		}
+		ids = append(ids, id)
+		return nil
+	})
+	return ids, err
+}
+
+func (r *RecommendationRepository) getStored(ctx context.Context, userID string) ([]*models.TemplateRecommendation, error) {
+	query := `SELECT template_id, kind, name, score, reasons, computed_at
+		FROM template_recommendations WHERE user_id = $1 ORDER BY score DESC`
+	if r.useSQLite {
+		query = `SELECT template_id, kind, name, score, reasons, computed_at
+		FROM template_recommendations WHERE user_id = ? ORDER BY score DESC`
+	}
+	var recs []*models.TemplateRecommendation
+	err := eachRow(ctx, r.db, r.sqlite, r.useSQLite, query, []interface{}{userID}, func(scan func(...interface{}) error) error {
+		var rec models.TemplateRecommendation
+		var reasons string
+		if err := scan(&rec.TemplateID, &rec.Kind, &rec.Name, &rec.Score, &reasons, &rec.ComputedAt); err != nil {
+			return err
+		}
+		if err := json.Unmarshal([]byte(reasons), &rec.Reasons); err != nil {
+			rec.Reasons = []string{}
+		}
+		recs = append(recs, &rec)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recommendations: %w", err)
+	}
+	return recs, nil
+}
+
+func (r *RecommendationRepository) store(ctx context.Context, userID string, recs []*models.TemplateRecommendation) error {
+	if r.useSQLite {
+		tx, err := r.sqlite.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+		if _, err := tx.ExecContext(ctx, `DELETE FROM template_recommendations WHERE user_id = ?`, userID); err != nil {
+			return fmt.Errorf("failed to clear recommendations: %w", err)
+		}
+		for _, rec := range recs {
+			reasons, _ := json.Marshal(rec.Reasons)
+			_, err := tx.ExecContext(ctx, `INSERT INTO template_recommendations (user_id, template_id, kind, name, score, reasons, computed_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?)`, userID, rec.TemplateID, rec.Kind, rec.Name, rec.Score, string(reasons), rec.ComputedAt)
+			if err != nil {
+				return fmt.Errorf("failed to store recommendation: %w", err)
+			}
+		}
+		return tx.Commit()
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, `DELETE FROM template_recommendations WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to clear recommendations: %w", err)
+	}
+	for _, rec := range recs {
+		reasons, _ := json.Marshal(rec.Reasons)
+		_, err := tx.Exec(ctx, `INSERT INTO template_recommendations (user_id, template_id, kind, name, score, reasons, computed_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`, userID, rec.TemplateID, rec.Kind, rec.Name, rec.Score, string(reasons), rec.ComputedAt)
+		if err != nil {
+			return fmt.Errorf("failed to store recommendation: %w", err)
+		}
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,280 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Pool is the PostgreSQL access repositories use. *pgxpool.Pool satisfies it,
+// as does ResilientPool, which adds retries and a circuit breaker.
+type Pool interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Begin(ctx context.Context) (pgx.Tx, error)
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+}
+
+// ErrDatabaseUnavailable is returned without touching the database while the circuit breaker is open
+var ErrDatabaseUnavailable = errors.New("database temporarily unavailable")
+
+// Retry defaults, overridable with DB_RETRY_* and DB_BREAKER_* env
+const (
+	DefaultRetryAttempts    = 3
+	DefaultRetryBaseDelay   = 50 * time.Millisecond
+	DefaultBreakerThreshold = 5
+	DefaultBreakerCooldown  = 10 * time.Second
+)
+
+// RetryConfig controls ResilientPool
+type RetryConfig struct {
+	Attempts         int           // tries per statement, including the first
+	BaseDelay        time.Duration // backoff before the first retry, doubled for each further one
+	BreakerThreshold int           // consecutive failed statements that open the breaker; 0 disables it
+	BreakerCooldown  time.Duration // how long the breaker stays open before a probe is let through
+}
+
+// RetryConfigFromEnv loads DB_RETRY_ATTEMPTS, DB_RETRY_BASE_DELAY, DB_BREAKER_THRESHOLD
+// and DB_BREAKER_COOLDOWN, falling back to the defaults
+func RetryConfigFromEnv() RetryConfig {
+	cfg := RetryConfig{
+		Attempts:         DefaultRetryAttempts,
+		BaseDelay:        DefaultRetryBaseDelay,
+		BreakerThreshold: DefaultBreakerThreshold,
+		BreakerCooldown:  DefaultBreakerCooldown,
+	}
+	if n, err := strconv.Atoi(os.Getenv("DB_RETRY_ATTEMPTS")); err == nil && n >= 1 {
+		cfg.Attempts = n
+	}
+	if d, err := time.ParseDuration(os.Getenv("DB_RETRY_BASE_DELAY")); err == nil && d >= 0 {
+		cfg.BaseDelay = d
+	}
+	if n, err := strconv.Atoi(os.Getenv("DB_BREAKER_THRESHOLD")); err == nil && n >= 0 {
+		cfg.BreakerThreshold = n
+	}
+	if d, err := time.ParseDuration(os.Getenv("DB_BREAKER_COOLDOWN")); err == nil && d > 0 {
+		cfg.BreakerCooldown = d
+	}
+	return cfg
+}
+
+// IsRetryable reports whether a failed statement can safely be run again: the
+// connection failed before the statement reached the server, or the server
+// rolled it back for a serialization failure or deadlock
+func IsRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == "40001" || pgErr.Code == "40P01" || isUnavailableCode(pgErr.Code)
+	}
+	return isConnectionError(err)
+}
+
+// isConnectionError reports failures to reach the server, which count towards the breaker
+func isConnectionError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var connectErr *pgconn.ConnectError
+	if errors.As(err, &connectErr) {
+		return true
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return isUnavailableCode(pgErr.Code)
+	}
+	return pgconn.SafeToRetry(err)
+}
+
+// isUnavailableCode matches server errors raised before a statement runs:
+// connection exceptions, too many connections, and a server starting up
+func isUnavailableCode(code string) bool {
+	return strings.HasPrefix(code, "08") || code == "53300" || code == "57P03"
+}
+
+// ResilientPool retries transient PostgreSQL failures with exponential backoff and,
+// after BreakerThreshold consecutive statements fail to reach the database, fails
+// fast with ErrDatabaseUnavailable for BreakerCooldown before letting a probe through.
+// Statements inside transactions are not retried; Begin is.
+type ResilientPool struct {
+	pool *pgxpool.Pool
+	cfg  RetryConfig
+	now  func() time.Time
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+// NewResilientPool wraps pool. It returns nil for a nil pool (SQLite mode), so the
+// result can be handed to repository constructors either way.
+func NewResilientPool(pool *pgxpool.Pool, cfg RetryConfig) *ResilientPool {
+	if pool == nil {
+		return nil
+	}
+	if cfg.Attempts < 1 {
+		cfg.Attempts = 1
+	}
+	return &ResilientPool{pool: pool, cfg: cfg, now: time.Now}
+}
+
+// AsPool returns p for repository constructors. A nil p becomes a nil Pool rather
+// than a non-nil interface holding nil.
+func (p *ResilientPool) AsPool() Pool {
+	if p == nil {
+		return nil
+	}
+	return p
+}
+
+// OpenFor returns how long the breaker stays open, or 0 while it is closed
+func (p *ResilientPool) OpenFor() time.Duration {
+	if p == nil {
+		return 0
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if d := p.openUntil.Sub(p.now()); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// Ping checks the database through the breaker, for health probes
+func (p *ResilientPool) Ping(ctx context.Context) error {
+	return p.do(ctx, func() error { return p.pool.Ping(ctx) })
+}
+
+func (p *ResilientPool) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	var tag pgconn.CommandTag
+	err := p.do(ctx, func() error {
+		var err error
+		tag, err = p.pool.Exec(ctx, sql, args...)
+		return err
+	})
+	return tag, err
+}
+
+func (p *ResilientPool) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	var rows pgx.Rows
+	err := p.do(ctx, func() error {
+		var err error
+		rows, err = p.pool.Query(ctx, sql, args...)
+		return err
+	})
+	return rows, err
+}
+
+// QueryRow defers the query to Scan, where pgx reports its errors, so Scan can retry it
+func (p *ResilientPool) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return &resilientRow{p: p, ctx: ctx, sql: sql, args: args}
+}
+
+func (p *ResilientPool) Begin(ctx context.Context) (pgx.Tx, error) {
+	var tx pgx.Tx
+	err := p.do(ctx, func() error {
+		var err error
+		tx, err = p.pool.Begin(ctx)
+		return err
+	})
+	return tx, err
+}
+
+// SendBatch is passed straight through: batch results are read lazily and cannot be retried
+func (p *ResilientPool) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	return p.pool.SendBatch(ctx, b)
+}
+
+type resilientRow struct {
+	p    *ResilientPool
+	ctx  context.Context
+	sql  string
+	args []any
+}
+
+func (r *resilientRow) Scan(dest ...any) error {
+	return r.p.do(r.ctx, func() error {
+		return r.p.pool.QueryRow(r.ctx, r.sql, r.args...).Scan(dest...)
+	})
+}
+
+// do runs fn through the breaker, retrying retryable errors with backoff
+func (p *ResilientPool) do(ctx context.Context, fn func() error) error {
+	if err := p.allow(); err != nil {
+		return err
+	}
+	var err error
+	for attempt := 0; attempt < p.cfg.Attempts; attempt++ {
+		if attempt > 0 {
+			// The delay doubles each time, with jitter so callers do not retry in step
+			delay := p.cfg.BaseDelay << (attempt - 1)
+			delay = delay/2 + time.Duration(rand.Int64N(int64(delay/2)+1))
+			select {
+			case <-ctx.Done():
+				p.record(err)
+				return err
+			case <-time.After(delay):
+			}
+		}
+		err = fn()
+		if err == nil || !IsRetryable(err) || ctx.Err() != nil {
+			break
+		}
+	}
+	p.record(err)
+	return err
+}
+
+// allow rejects calls while the breaker is open. Once the cooldown has passed a
+// single probe is let through; the rest keep failing fast until it succeeds.
+func (p *ResilientPool) allow() error {
+	if p.cfg.BreakerThreshold <= 0 {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.openUntil.IsZero() {
+		return nil
+	}
+	if p.now().Before(p.openUntil) || p.probing {
+		return ErrDatabaseUnavailable
+	}
+	p.probing = true
+	return nil
+}
+
+// record counts a statement's outcome. Only failures to reach the database count;
+// query errors such as constraint violations show the database is up.
+func (p *ResilientPool) record(err error) {
+	if p.cfg.BreakerThreshold <= 0 {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !isConnectionError(err) {
+		if !p.openUntil.IsZero() {
+			log.Println("Database reachable again, closing circuit breaker")
+		}
+		p.failures, p.openUntil, p.probing = 0, time.Time{}, false
+		return
+	}
+	p.failures++
+	if p.probing || p.failures >= p.cfg.BreakerThreshold {
+		if p.openUntil.IsZero() {
+			log.Printf("Database unreachable after %d failures, opening circuit breaker for %v: %v", p.failures, p.cfg.BreakerCooldown, err)
+		}
+		p.openUntil = p.now().Add(p.cfg.BreakerCooldown)
+		p.probing = false
+	}
+}
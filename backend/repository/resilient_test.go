@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestIsRetryable(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"serialization failure", &pgconn.PgError{Code: "40001"}, true},
+		{"deadlock", &pgconn.PgError{Code: "40P01"}, true},
+		{"connection failure", &pgconn.PgError{Code: "08006"}, true},
+		{"starting up", &pgconn.PgError{Code: "57P03"}, true},
+		{"unique violation", &pgconn.PgError{Code: "23505"}, false},
+		{"no rows", pgx.ErrNoRows, false},
+		{"canceled", context.Canceled, false},
+		{"other", errors.New("boom"), false},
+	} {
+		if got := IsRetryable(tt.err); got != tt.want {
+			t.Errorf("%s: IsRetryable = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestResilientPoolRetries(t *testing.T) {
+	p := &ResilientPool{cfg: RetryConfig{Attempts: 3, BreakerThreshold: 0}, now: time.Now}
+	calls := 0
+	err := p.do(context.Background(), func() error {
+		calls++
+		if calls < 3 {
+			return &pgconn.PgError{Code: "40001"}
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("transient failure: err %v after %d calls", err, calls)
+	}
+
+	calls = 0
+	err = p.do(context.Background(), func() error {
+		calls++
+		return &pgconn.PgError{Code: "23505"}
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("permanent failure: err %v after %d calls, want 1 call", err, calls)
+	}
+}
+
+func TestResilientPoolBreaker(t *testing.T) {
+	now := time.Now()
+	p := &ResilientPool{cfg: RetryConfig{Attempts: 1, BreakerThreshold: 2, BreakerCooldown: 10 * time.Second}, now: func() time.Time { return now }}
+	down := func() error { return &pgconn.PgError{Code: "08006"} }
+	up := func() error { return nil }
+
+	p.do(context.Background(), down)
+	if p.OpenFor() != 0 {
+		t.Fatal("breaker opened before the threshold")
+	}
+	p.do(context.Background(), down)
+	if p.OpenFor() != 10*time.Second {
+		t.Fatalf("breaker open for %v after threshold", p.OpenFor())
+	}
+	called := false
+	if err := p.do(context.Background(), func() error { called = true; return nil }); !errors.Is(err, ErrDatabaseUnavailable) || called {
+		t.Errorf("open breaker: err %v, called %v", err, called)
+	}
+
+	// After the cooldown one probe goes through; failing it reopens the breaker
+	now = now.Add(11 * time.Second)
+	if err := p.do(context.Background(), down); errors.Is(err, ErrDatabaseUnavailable) {
+		t.Fatal("probe was not let through")
+	}
+	if p.OpenFor() != 10*time.Second {
+		t.Fatalf("failed probe: breaker open for %v", p.OpenFor())
+	}
+	now = now.Add(11 * time.Second)
+	if err := p.do(context.Background(), up); err != nil {
+		t.Fatal(err)
+	}
+	if p.OpenFor() != 0 || p.failures != 0 {
+		t.Errorf("successful probe left breaker open for %v with %d failures", p.OpenFor(), p.failures)
+	}
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"liftoff/backend/models"
+)
+
+// RetentionRepository stores inactivity warnings and deletions for the retention policy.
+// Times are unix seconds.
+type RetentionRepository struct {
+	db        Pool
+	sqlite    *sql.DB
+	useSQLite bool
+}
+
+// NewRetentionRepository creates a new retention repository
+func NewRetentionRepository(db Pool, sqlite *sql.DB, useSQLite bool) *RetentionRepository {
+	if useSQLite {
+		return &RetentionRepository{db: nil, sqlite: sqlite, useSQLite: true}
+	}
+	return &RetentionRepository{db: db, sqlite: nil, useSQLite: false}
+}
+
+func (r *RetentionRepository) exec(ctx context.Context, query string, args ...interface{}) error {
+	_, err := exec(ctx, r.db, r.sqlite, r.useSQLite, query, args...)
+	return err
+}
+
+// StartTracking implements retention.Store
+func (r *RetentionRepository) StartTracking(ctx context.Context, now time.Time) error {
+	if err := r.exec(ctx, `UPDATE users SET last_active_at = $1 WHERE last_active_at IS NULL`, now.Unix()); err != nil {
+		return fmt.Errorf("failed to start activity tracking: %w", err)
+	}
+	return nil
+}
+
+// retentionCandidateQuery reads a user's activity and warning state; %s is the WHERE clause.
+// API key use counts as activity alongside signed-in requests.
+const retentionCandidateQuery = `
+	SELECT u.id, u.email, u.role, COALESCE(u.last_active_at, 0),
+	       COALESCE((SELECT MAX(k.last_used_at) FROM api_keys k WHERE k.user_id = u.id), 0),
+	       w.warned_at, w.delete_after
+	FROM users u
+	LEFT JOIN retention_warnings w ON w.user_id = u.id
+	WHERE %s`
+
+func (r *RetentionRepository) eachCandidate(ctx context.Context, where string, args []interface{}, fn func(*models.RetentionCandidate)) error {
+	query := fmt.Sprintf(retentionCandidateQuery, where)
+	return eachRow(ctx, r.db, r.sqlite, r.useSQLite, query, args, func(scan func(...interface{}) error) error {
+		var c models.RetentionCandidate
+		var lastActive, lastKeyUse int64
+		var warnedAt, deleteAfter *int64
+		if err := scan(&c.UserID, &c.Email, &c.Role, &lastActive, &lastKeyUse, &warnedAt, &deleteAfter); err != nil {
+			return fmt.Errorf("failed to scan retention candidate: %w", err)
+		}
+		c.LastActiveAt = time.Unix(max(lastActive, lastKeyUse), 0)
+		if warnedAt != nil && deleteAfter != nil {
+			c.Warning = &models.RetentionWarning{
+				UserID:       c.UserID,
+				Email:        c.Email,
+				LastActiveAt: c.LastActiveAt,
+				WarnedAt:     time.Unix(*warnedAt, 0),
+				DeleteAfter:  time.Unix(*deleteAfter, 0),
+			}
+		}
+		fn(&c)
+		return nil
+	})
+}
+
+// ListRetentionCandidates implements retention.Store
+func (r *RetentionRepository) ListRetentionCandidates(ctx context.Context, cutoff time.Time) ([]*models.RetentionCandidate, error) {
+	candidates := []*models.RetentionCandidate{}
+	// Accounts under legal hold are never warned or deleted
+	err := r.eachCandidate(ctx, `(u.last_active_at < $1 OR w.user_id IS NOT NULL) AND NOT u.legal_hold`, []interface{}{cutoff.Unix()}, func(c *models.RetentionCandidate) {
+		candidates = append(candidates, c)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list retention candidates: %w", err)
+	}
+	return candidates, nil
+}
+
+// ListRetentionWarnings returns outstanding warnings, soonest deletion first
+func (r *RetentionRepository) ListRetentionWarnings(ctx context.Context) ([]*models.RetentionWarning, error) {
+	warnings := []*models.RetentionWarning{}
+	err := r.eachCandidate(ctx, `w.user_id IS NOT NULL ORDER BY w.delete_after, u.id`, nil, func(c *models.RetentionCandidate) {
+		warnings = append(warnings, c.Warning)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list retention warnings: %w", err)
+	}
+	return warnings, nil
+}
+
+// RecordRetentionWarning implements retention.Store
+func (r *RetentionRepository) RecordRetentionWarning(ctx context.Context, warning *models.RetentionWarning) error {
+	err := r.exec(ctx, `
+		INSERT INTO retention_warnings (user_id, warned_at, delete_after) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE SET warned_at = EXCLUDED.warned_at, delete_after = EXCLUDED.delete_after`,
+		warning.UserID, warning.WarnedAt.Unix(), warning.DeleteAfter.Unix())
+	if err != nil {
+		return fmt.Errorf("failed to record retention warning: %w", err)
+	}
+	return nil
+}
+
+// ClearRetentionWarning implements retention.Store
+func (r *RetentionRepository) ClearRetentionWarning(ctx context.Context, userID string) error {
+	if err := r.exec(ctx, `DELETE FROM retention_warnings WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to clear retention warning: %w", err)
+	}
+	return nil
+}
+
+// RecordRetentionDeletion implements retention.Store
+func (r *RetentionRepository) RecordRetentionDeletion(ctx context.Context, deletion *models.RetentionDeletion) error {
+	err := r.exec(ctx, `
+		INSERT INTO retention_deletions (user_id, last_active_at, deleted_at) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO NOTHING`,
+		deletion.UserID, deletion.LastActiveAt.Unix(), deletion.DeletedAt.Unix())
+	if err != nil {
+		return fmt.Errorf("failed to record retention deletion: %w", err)
+	}
+	return nil
+}
+
+// ListRetentionDeletions returns the most recent deletions, newest first
+func (r *RetentionRepository) ListRetentionDeletions(ctx context.Context, limit int) ([]*models.RetentionDeletion, error) {
+	deletions := []*models.RetentionDeletion{}
+	const query = `SELECT user_id, last_active_at, deleted_at FROM retention_deletions ORDER BY deleted_at DESC, user_id LIMIT $1`
+	err := eachRow(ctx, r.db, r.sqlite, r.useSQLite, query, []interface{}{limit}, func(scan func(...interface{}) error) error {
+		var d models.RetentionDeletion
+		var lastActive, deletedAt int64
+		if err := scan(&d.UserID, &lastActive, &deletedAt); err != nil {
+			return fmt.Errorf("failed to scan retention deletion: %w", err)
+		}
+		d.LastActiveAt, d.DeletedAt = time.Unix(lastActive, 0), time.Unix(deletedAt, 0)
+		deletions = append(deletions, &d)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list retention deletions: %w", err)
+	}
+	return deletions, nil
+}
@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"liftoff/backend/models"
+)
+
+// ErrTooManySessions is returned when a correction would move more than
+// models.MaxCorrectedSessions sessions
+var ErrTooManySessions = fmt.Errorf("at most %d sessions can be corrected at once", models.MaxCorrectedSessions)
+
+// ErrNoSessionsMatched is returned when a correction selects no completed sessions
+var ErrNoSessionsMatched = errors.New("no completed sessions match")
+
+// retimeTx abstracts the running transaction; queries are written with $n placeholders
+type retimeTx struct {
+	exec func(query string, args ...interface{}) error
+	each func(query string, args []interface{}, scan func(func(...interface{}) error) error) error
+}
+
+// RetimeSessions moves the user's completed sessions selected by c to the start
+// times shift gives them. Each session's end time and logged sets move by the same
+// amount, so per-day history follows. An audit entry by actorID is recorded in the
+// same transaction.
+func (r *SessionRepository) RetimeSessions(ctx context.Context, userID, actorID string, c models.SessionTimeCorrection, shift func(time.Time) time.Time) ([]models.RetimedSession, *models.AuditEntry, error) {
+	if r.useSQLite {
+		tx, err := r.sqlite.BeginTx(ctx, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer tx.Rollback()
+		retimed, entry, err := retimeSessions(retimeTx{
+			exec: func(query string, args ...interface{}) error {
+				_, err := tx.ExecContext(ctx, rebind(query, len(args)), args...)
+				return err
+			},
+			each: func(query string, args []interface{}, scan func(func(...interface{}) error) error) error {
+				rows, err := tx.QueryContext(ctx, rebind(query, len(args)), args...)
+				if err != nil {
+					return err
+				}
+				defer rows.Close()
+				for rows.Next() {
+					if err := scan(rows.Scan); err != nil {
+						return err
+					}
+				}
+				return rows.Err()
+			},
+		}, userID, actorID, c, shift)
+		if err != nil {
+			return nil, nil, err
+		}
+		return retimed, entry, tx.Commit()
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback(ctx)
+	retimed, entry, err := retimeSessions(retimeTx{
+		exec: func(query string, args ...interface{}) error {
+			_, err := tx.Exec(ctx, query, args...)
+			return err
+		},
+		each: func(query string, args []interface{}, scan func(func(...interface{}) error) error) error {
+			rows, err := tx.Query(ctx, query, args...)
+			if err != nil {
+				return err
+			}
+			defer rows.Close()
+			for rows.Next() {
+				if err := scan(rows.Scan); err != nil {
+					return err
+				}
+			}
+			return rows.Err()
+		},
+	}, userID, actorID, c, shift)
+	if err != nil {
+		return nil, nil, err
+	}
+	return retimed, entry, tx.Commit(ctx)
+}
+
+type retimeSession struct {
+	id              string
+	startedAt       time.Time
+	endedAt         *time.Time
+	delta           time.Duration
+	setIDs          []string
+	setCreatedTimes []time.Time
+}
+
+func retimeSessions(tx retimeTx, userID, actorID string, c models.SessionTimeCorrection, shift func(time.Time) time.Time) ([]models.RetimedSession, *models.AuditEntry, error) {
+	query := `SELECT id, started_at, ended_at FROM workout_sessions
+		WHERE user_id = $1 AND is_active = false AND ended_at IS NOT NULL AND started_at >= $2 AND started_at <= $3
+		ORDER BY started_at`
+	args := []interface{}{userID}
+	if len(c.SessionIDs) > 0 {
+		marks := make([]string, len(c.SessionIDs))
+		for i, id := range c.SessionIDs {
+			marks[i] = fmt.Sprintf("$%d", i+2)
+			args = append(args, id)
+		}
+		query = `SELECT id, started_at, ended_at FROM workout_sessions
+			WHERE user_id = $1 AND is_active = false AND ended_at IS NOT NULL AND id IN (` + strings.Join(marks, ", ") + `)
+			ORDER BY started_at`
+	} else {
+		args = append(args, c.From.UTC(), c.To.UTC())
+	}
+
+	var sessions []*retimeSession
+	err := tx.each(query, args, func(scan func(...interface{}) error) error {
+		var s retimeSession
+		if err := scan(&s.id, &s.startedAt, &s.endedAt); err != nil {
+			return err
+		}
+		sessions = append(sessions, &s)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to select sessions: %w", err)
+	}
+	if len(sessions) == 0 {
+		return nil, nil, ErrNoSessionsMatched
+	}
+	if len(sessions) > models.MaxCorrectedSessions {
+		return nil, nil, ErrTooManySessions
+	}
+
+	retimed := make([]models.RetimedSession, 0, len(sessions))
+	for _, s := range sessions {
+		newStart := shift(s.startedAt).UTC()
+		s.delta = newStart.Sub(s.startedAt)
+		retimed = append(retimed, models.RetimedSession{ID: s.id, OldStartedAt: s.startedAt.UTC(), NewStartedAt: newStart})
+		if s.delta == 0 {
+			continue
+		}
+		var endedAt interface{}
+		if s.endedAt != nil {
+			endedAt = s.endedAt.Add(s.delta).UTC()
+		}
+		if err := tx.exec(`UPDATE workout_sessions SET started_at = $1, ended_at = $2 WHERE id = $3`, newStart, endedAt, s.id); err != nil {
+			return nil, nil, fmt.Errorf("failed to move session: %w", err)
+		}
+
+		err := tx.each(`SELECT es.id, es.created_at FROM exercise_sets es
+			JOIN session_exercises se ON es.session_exercise_id = se.id WHERE se.session_id = $1`,
+			[]interface{}{s.id}, func(scan func(...interface{}) error) error {
+				var id string
+				var createdAt time.Time
+				if err := scan(&id, &createdAt); err != nil {
+					return err
+				}
+				s.setIDs = append(s.setIDs, id)
+				s.setCreatedTimes = append(s.setCreatedTimes, createdAt)
+				return nil
+			})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load sets: %w", err)
+		}
+		for i, id := range s.setIDs {
+			if err := tx.exec(`UPDATE exercise_sets SET created_at = $1 WHERE id = $2`, s.setCreatedTimes[i].Add(s.delta).UTC(), id); err != nil {
+				return nil, nil, fmt.Errorf("failed to move sets: %w", err)
+			}
+		}
+	}
+
+	entry, args, err := newAuditEntry(userID, actorID, models.AuditActionSessionsRetimed, map[string]interface{}{
+		"correction": c,
+		"sessions":   retimed,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := tx.exec(insertAuditEntry, args...); err != nil {
+		return nil, nil, fmt.Errorf("failed to record audit entry: %w", err)
+	}
+	return retimed, entry, nil
+}
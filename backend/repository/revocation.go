@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/models"
+)
+
+// TokenRevocationRepository stores revoked token IDs and per-user logout-everywhere cutoffs.
+// Times are stored as unix seconds to match JWT claim precision.
+type TokenRevocationRepository struct {
+	db        Pool
+	sqlite    *sql.DB
+	useSQLite bool
+}
+
+// NewTokenRevocationRepository creates a new token revocation repository
+func NewTokenRevocationRepository(db Pool, sqlite *sql.DB, useSQLite bool) *TokenRevocationRepository {
+	if useSQLite {
+		return &TokenRevocationRepository{db: nil, sqlite: sqlite, useSQLite: true}
+	}
+	return &TokenRevocationRepository{db: db, sqlite: nil, useSQLite: false}
+}
+
+// RevokeToken denylists a single token until it would have expired anyway
+func (r *TokenRevocationRepository) RevokeToken(ctx context.Context, jti, userID string, expiresAt time.Time) error {
+	var err error
+	if r.useSQLite {
+		_, err = r.sqlite.ExecContext(ctx, `
+			INSERT INTO revoked_tokens (jti, user_id, expires_at, revoked_at) VALUES (?, ?, ?, ?)
+			ON CONFLICT (jti) DO NOTHING`,
+			jti, userID, expiresAt.Unix(), time.Now().Unix())
+	} else {
+		_, err = r.db.Exec(ctx, `
+			INSERT INTO revoked_tokens (jti, user_id, expires_at, revoked_at) VALUES ($1, $2, $3, $4)
+			ON CONFLICT (jti) DO NOTHING`,
+			jti, userID, expiresAt.Unix(), time.Now().Unix())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+	return nil
+}
+
+// RevokeAllForUser rejects every token for the user issued before the given time
+func (r *TokenRevocationRepository) RevokeAllForUser(ctx context.Context, userID string, before time.Time) error {
+	var err error
+	if r.useSQLite {
+		_, err = r.sqlite.ExecContext(ctx, `
+			INSERT INTO user_token_cutoffs (user_id, revoked_before) VALUES (?, ?)
+			ON CONFLICT (user_id) DO UPDATE SET revoked_before = excluded.revoked_before`,
+			userID, before.Unix())
+	} else {
+		_, err = r.db.Exec(ctx, `
+			INSERT INTO user_token_cutoffs (user_id, revoked_before) VALUES ($1, $2)
+			ON CONFLICT (user_id) DO UPDATE SET revoked_before = EXCLUDED.revoked_before`,
+			userID, before.Unix())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to revoke user tokens: %w", err)
+	}
+	return nil
+}
+
+// IsTokenRevoked implements auth.RevocationChecker. Tokens of deleted accounts count as
+// revoked; tokens of suspended accounts return auth.ErrAccountSuspended.
+func (r *TokenRevocationRepository) IsTokenRevoked(ctx context.Context, jti, userID string, issuedAt time.Time) (bool, error) {
+	var revoked, suspended bool
+	var err error
+	if r.useSQLite {
+		err = r.sqlite.QueryRowContext(ctx, `
+			SELECT EXISTS(SELECT 1 FROM revoked_tokens WHERE jti = ? AND ? != '')
+				OR EXISTS(SELECT 1 FROM user_token_cutoffs WHERE user_id = ? AND revoked_before > ?)
+				OR NOT EXISTS(SELECT 1 FROM users WHERE id = ?),
+				EXISTS(SELECT 1 FROM users WHERE id = ? AND status = ?)`,
+			jti, jti, userID, issuedAt.Unix(), userID, userID, models.UserStatusSuspended).Scan(&revoked, &suspended)
+	} else {
+		err = r.db.QueryRow(ctx, `
+			SELECT EXISTS(SELECT 1 FROM revoked_tokens WHERE jti = $1 AND $1 != '')
+				OR EXISTS(SELECT 1 FROM user_token_cutoffs WHERE user_id = $2 AND revoked_before > $3)
+				OR NOT EXISTS(SELECT 1 FROM users WHERE id = $2),
+				EXISTS(SELECT 1 FROM users WHERE id = $2 AND status = $4)`,
+			jti, userID, issuedAt.Unix(), models.UserStatusSuspended).Scan(&revoked, &suspended)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check token revocation: %w", err)
+	}
+	if suspended {
+		return true, auth.ErrAccountSuspended
+	}
+	return revoked, nil
+}
+
+// PurgeExpired drops denylist entries for tokens that have expired on their own.
+// Intended to run from the jobs scheduler.
+func (r *TokenRevocationRepository) PurgeExpired(ctx context.Context) error {
+	var err error
+	if r.useSQLite {
+		_, err = r.sqlite.ExecContext(ctx, `DELETE FROM revoked_tokens WHERE expires_at < ?`, time.Now().Unix())
+	} else {
+		_, err = r.db.Exec(ctx, `DELETE FROM revoked_tokens WHERE expires_at < $1`, time.Now().Unix())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to purge revoked tokens: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// RoleRepository reads and changes the role stored on each user
+type RoleRepository struct {
+	db        Pool
+	sqlite    *sql.DB
+	useSQLite bool
+}
+
+// NewRoleRepository creates a new role repository
+func NewRoleRepository(db Pool, sqlite *sql.DB, useSQLite bool) *RoleRepository {
+	if useSQLite {
+		return &RoleRepository{db: nil, sqlite: sqlite, useSQLite: true}
+	}
+	return &RoleRepository{db: db, sqlite: nil, useSQLite: false}
+}
+
+// UserRole implements auth.RoleResolver. Unknown users have the plain user role.
+func (r *RoleRepository) UserRole(ctx context.Context, userID string) (string, error) {
+	var role string
+	var err error
+	if r.useSQLite {
+		err = r.sqlite.QueryRowContext(ctx, `SELECT role FROM users WHERE id = ?`, userID).Scan(&role)
+	} else {
+		err = r.db.QueryRow(ctx, `SELECT role FROM users WHERE id = $1`, userID).Scan(&role)
+	}
+	if errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgx.ErrNoRows) {
+		return auth.RoleUser, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get user role: %w", err)
+	}
+	return role, nil
+}
+
+// SetRole stores a user's role; auth.RoleUser revokes any elevated role
+func (r *RoleRepository) SetRole(ctx context.Context, userID, role string) error {
+	var affected int64
+	if r.useSQLite {
+		result, err := r.sqlite.ExecContext(ctx, `UPDATE users SET role = ? WHERE id = ?`, role, userID)
+		if err != nil {
+			return fmt.Errorf("failed to set user role: %w", err)
+		}
+		affected, _ = result.RowsAffected()
+	} else {
+		tag, err := r.db.Exec(ctx, `UPDATE users SET role = $1 WHERE id = $2`, role, userID)
+		if err != nil {
+			return fmt.Errorf("failed to set user role: %w", err)
+		}
+		affected = tag.RowsAffected()
+	}
+	if affected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// ListElevated returns users with a role other than auth.RoleUser, by email
+func (r *RoleRepository) ListElevated(ctx context.Context) ([]*models.User, error) {
+	users := []*models.User{}
+	scan := func(scan func(...interface{}) error) error {
+		var u models.User
+		if err := scan(&u.ID, &u.Email, &u.Role, &u.CreatedAt); err != nil {
+			return fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, &u)
+		return nil
+	}
+
+	const query = `SELECT id, email, role, created_at FROM users WHERE role != %s ORDER BY email`
+	if r.useSQLite {
+		rows, err := r.sqlite.QueryContext(ctx, fmt.Sprintf(query, "?"), auth.RoleUser)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list roles: %w", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			if err := scan(rows.Scan); err != nil {
+				return nil, err
+			}
+		}
+		return users, rows.Err()
+	}
+
+	rows, err := r.db.Query(ctx, fmt.Sprintf(query, "$1"), auth.RoleUser)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := scan(rows.Scan); err != nil {
+			return nil, err
+		}
+	}
+	return users, rows.Err()
+}
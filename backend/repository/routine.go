@@ -6,10 +6,8 @@ import (
 	"fmt"
 	"time"
 
+	"liftoff/backend/ids"
 	"liftoff/backend/models"
-
-	"github.com/google/uuid"
-	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // RoutineTemplateWorkout defines a workout within a routine template
@@ -27,13 +25,13 @@ type RoutineTemplate struct {
 }
 
 type RoutineRepository struct {
-	db        *pgxpool.Pool
+	db        Pool
 	sqlite    *sql.DB
 	useSQLite bool
 	workout   *WorkoutRepository
 }
 
-func NewRoutineRepository(db *pgxpool.Pool, sqlite *sql.DB, useSQLite bool, workout *WorkoutRepository) *RoutineRepository {
+func NewRoutineRepository(db Pool, sqlite *sql.DB, useSQLite bool, workout *WorkoutRepository) *RoutineRepository {
 	if useSQLite {
 		return &RoutineRepository{db: nil, sqlite: sqlite, useSQLite: true, workout: workout}
 	}
@@ -41,7 +39,7 @@ func NewRoutineRepository(db *pgxpool.Pool, sqlite *sql.DB, useSQLite bool, work
 }
 
 func (r *RoutineRepository) CreateRoutine(ctx context.Context, userID, name, description string) (*models.Routine, error) {
-	id := uuid.New().String()
+	id := ids.New()
 	now := time.Now()
 	if r.useSQLite {
 		return r.createRoutineSQLite(ctx, id, userID, name, description, now)
@@ -255,7 +253,7 @@ func (r *RoutineRepository) addWorkoutToRoutinePostgres(ctx context.Context, use
 	if _, err := r.getRoutinePostgres(ctx, userID, routineID); err != nil {
 		return err
 	}
-	id := uuid.New().String()
+	id := ids.New()
 	now := time.Now()
 	_, err := r.db.Exec(ctx, `INSERT INTO routine_workouts (id, routine_id, workout_id, slot_order, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, $6)`, id, routineID, workoutID, slotOrder, now, now)
@@ -266,7 +264,7 @@ func (r *RoutineRepository) addWorkoutToRoutineSQLite(ctx context.Context, userI
 	if _, err := r.getRoutineSQLite(ctx, userID, routineID); err != nil {
 		return err
 	}
-	id := uuid.New().String()
+	id := ids.New()
 	now := time.Now()
 	_, err := r.sqlite.ExecContext(ctx, `INSERT INTO routine_workouts (id, routine_id, workout_id, slot_order, created_at, updated_at)
 		VALUES (?, ?, ?, ?, ?, ?)`, id, routineID, workoutID, slotOrder, now, now)
@@ -299,7 +297,7 @@ func (r *RoutineRepository) GetRoutineTemplates() []RoutineTemplate {
 	return getRoutineTemplates()
 }
 
-func (r *RoutineRepository) CreateFromTemplate(ctx context.Context, userID, templateID string, routineName string) (*models.Routine, error) {
+func (r *RoutineRepository) CreateFromTemplate(ctx context.Context, userID, templateID string, routineName string, scale func(models.Exercise) models.Exercise) (*models.Routine, error) {
 	templates := getRoutineTemplates()
 	var tpl *RoutineTemplate
 	for i := range templates {
@@ -318,11 +316,14 @@ func (r *RoutineRepository) CreateFromTemplate(ctx context.Context, userID, temp
 
 	var workoutIDs []string
 	for _, w := range tpl.Workouts {
-		workout, err := r.workout.CreateWorkout(ctx, userID, w.Name)
+		workout, err := r.workout.CreateWorkout(ctx, userID, w.Name, "", "")
 		if err != nil {
 			return nil, fmt.Errorf("create workout %s: %w", w.Name, err)
 		}
 		for _, ex := range w.Exercises {
+			if scale != nil {
+				ex = scale(ex)
+			}
 			ex.WorkoutID = workout.ID
 			if err := r.workout.CreateExercise(ctx, userID, &ex); err != nil {
 				return nil, fmt.Errorf("create exercise %s: %w", ex.Name, err)
@@ -470,7 +471,7 @@ func getRoutineTemplates() []RoutineTemplate {
 						{Name: "Barbell Rows", Sets: 3, Reps: 10, Weight: 95},
 						{Name: "Overhead Press", Sets: 3, Reps: 8, Weight: 65},
 						{Name: "Deadlifts", Sets: 3, Reps: 5, Weight: 135},
-						{Name: "Plank", Sets: 3, Reps: 30, Weight: 0},
+						{Name: "Plank", Sets: 3, Reps: 0, Weight: 0, Mode: models.ExerciseModeDuration, DurationSeconds: 30},
 					},
 				},
 			},
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"liftoff/backend/ids"
+	"liftoff/backend/models"
+)
+
+// ErrScheduledWorkoutNotFound is returned when a calendar entry does not exist or belongs to another user
+var ErrScheduledWorkoutNotFound = errors.New("scheduled workout not found")
+
+// ScheduleRepository stores the workouts users plan on their training calendar
+type ScheduleRepository struct {
+	db        Pool
+	sqlite    *sql.DB
+	useSQLite bool
+	workout   *WorkoutRepository
+}
+
+// NewScheduleRepository creates a new schedule repository
+func NewScheduleRepository(db Pool, sqlite *sql.DB, useSQLite bool, workout *WorkoutRepository) *ScheduleRepository {
+	if useSQLite {
+		return &ScheduleRepository{db: nil, sqlite: sqlite, useSQLite: true, workout: workout}
+	}
+	return &ScheduleRepository{db: db, sqlite: nil, useSQLite: false, workout: workout}
+}
+
+// scheduledWorkoutSelect reads calendar entries with their workout's name. Entries
+// whose workout is gone are left out.
+const scheduledWorkoutSelect = `
+	SELECT s.id, s.user_id, s.workout_id, w.name, s.date, s.time, s.status, s.created_at, s.updated_at
+	FROM scheduled_workouts s
+	JOIN workouts w ON w.id = s.workout_id`
+
+func scanScheduledWorkout(scan func(...interface{}) error) (*models.ScheduledWorkout, error) {
+	var s models.ScheduledWorkout
+	if err := scan(&s.ID, &s.UserID, &s.WorkoutID, &s.WorkoutName, &s.Date, &s.Time, &s.Status, &s.CreatedAt, &s.UpdatedAt); err != nil {
+		return nil, fmt.Errorf("failed to scan scheduled workout: %w", err)
+	}
+	return &s, nil
+}
+
+// GetSchedule returns the user's calendar entries from one day to another, inclusive,
+// by date and then time; entries without a time come first on their day
+func (r *ScheduleRepository) GetSchedule(ctx context.Context, userID, from, to string) ([]*models.ScheduledWorkout, error) {
+	schedule := []*models.ScheduledWorkout{}
+	err := eachRow(ctx, r.db, r.sqlite, r.useSQLite,
+		scheduledWorkoutSelect+`
+		WHERE s.user_id = $1 AND s.date >= $2 AND s.date <= $3
+		ORDER BY s.date, COALESCE(s.time, ''), s.created_at`,
+		[]interface{}{userID, from, to}, func(scan func(...interface{}) error) error {
+			s, err := scanScheduledWorkout(scan)
+			if err != nil {
+				return err
+			}
+			schedule = append(schedule, s)
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedule: %w", err)
+	}
+	return schedule, nil
+}
+
+// GetScheduledWorkout returns one of the user's calendar entries, or ErrScheduledWorkoutNotFound
+func (r *ScheduleRepository) GetScheduledWorkout(ctx context.Context, userID, id string) (*models.ScheduledWorkout, error) {
+	var found *models.ScheduledWorkout
+	err := eachRow(ctx, r.db, r.sqlite, r.useSQLite,
+		scheduledWorkoutSelect+` WHERE s.id = $1 AND s.user_id = $2`,
+		[]interface{}{id, userID}, func(scan func(...interface{}) error) error {
+			s, err := scanScheduledWorkout(scan)
+			found = s
+			return err
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scheduled workout: %w", err)
+	}
+	if found == nil {
+		return nil, ErrScheduledWorkoutNotFound
+	}
+	return found, nil
+}
+
+// CreateScheduledWorkout puts one of the user's workouts on their calendar
+//
+// Returns ErrWorkoutNotFound if the workout is not the user's.
+func (r *ScheduleRepository) CreateScheduledWorkout(ctx context.Context, s *models.ScheduledWorkout) error {
+	if err := r.workout.checkWorkoutOwner(ctx, s.UserID, s.WorkoutID); err != nil {
+		return err
+	}
+	id := ids.New()
+	now := time.Now()
+	var err error
+	if r.useSQLite {
+		_, err = r.sqlite.ExecContext(ctx, `
+			INSERT INTO scheduled_workouts (id, user_id, workout_id, date, time, status, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			id, s.UserID, s.WorkoutID, s.Date, s.Time, s.Status, now, now)
+	} else {
+		_, err = r.db.Exec(ctx, `
+			INSERT INTO scheduled_workouts (id, user_id, workout_id, date, time, status, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+			id, s.UserID, s.WorkoutID, s.Date, s.Time, s.Status, now, now)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to schedule workout: %w", err)
+	}
+	s.ID, s.CreatedAt, s.UpdatedAt = id, now, now
+	return r.fillWorkoutName(ctx, s)
+}
+
+// UpdateScheduledWorkout saves a calendar entry's workout, date, time and status
+//
+// Returns ErrScheduledWorkoutNotFound if the entry is not the user's, or
+// ErrWorkoutNotFound if the workout is not.
+func (r *ScheduleRepository) UpdateScheduledWorkout(ctx context.Context, s *models.ScheduledWorkout) error {
+	if err := r.workout.checkWorkoutOwner(ctx, s.UserID, s.WorkoutID); err != nil {
+		return err
+	}
+	var affected int64
+	now := time.Now()
+	if r.useSQLite {
+		result, err := r.sqlite.ExecContext(ctx, `
+			UPDATE scheduled_workouts SET workout_id = ?, date = ?, time = ?, status = ?, updated_at = ?
+			WHERE id = ? AND user_id = ?`,
+			s.WorkoutID, s.Date, s.Time, s.Status, now, s.ID, s.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to update scheduled workout: %w", err)
+		}
+		affected, _ = result.RowsAffected()
+	} else {
+		tag, err := r.db.Exec(ctx, `
+			UPDATE scheduled_workouts SET workout_id = $1, date = $2, time = $3, status = $4, updated_at = $5
+			WHERE id = $6 AND user_id = $7`,
+			s.WorkoutID, s.Date, s.Time, s.Status, now, s.ID, s.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to update scheduled workout: %w", err)
+		}
+		affected = tag.RowsAffected()
+	}
+	if affected == 0 {
+		return ErrScheduledWorkoutNotFound
+	}
+	s.UpdatedAt = now
+	return r.fillWorkoutName(ctx, s)
+}
+
+// DeleteScheduledWorkout removes a calendar entry; the workout itself is kept
+func (r *ScheduleRepository) DeleteScheduledWorkout(ctx context.Context, userID, id string) error {
+	var affected int64
+	if r.useSQLite {
+		result, err := r.sqlite.ExecContext(ctx, `DELETE FROM scheduled_workouts WHERE id = ? AND user_id = ?`, id, userID)
+		if err != nil {
+			return fmt.Errorf("failed to delete scheduled workout: %w", err)
+		}
+		affected, _ = result.RowsAffected()
+	} else {
+		tag, err := r.db.Exec(ctx, `DELETE FROM scheduled_workouts WHERE id = $1 AND user_id = $2`, id, userID)
+		if err != nil {
+			return fmt.Errorf("failed to delete scheduled workout: %w", err)
+		}
+		affected = tag.RowsAffected()
+	}
+	if affected == 0 {
+		return ErrScheduledWorkoutNotFound
+	}
+	return nil
+}
+
+func (r *ScheduleRepository) fillWorkoutName(ctx context.Context, s *models.ScheduledWorkout) error {
+	var err error
+	if r.useSQLite {
+		err = r.sqlite.QueryRowContext(ctx, `SELECT name FROM workouts WHERE id = ?`, s.WorkoutID).Scan(&s.WorkoutName)
+	} else {
+		err = r.db.QueryRow(ctx, `SELECT name FROM workouts WHERE id = $1`, s.WorkoutID).Scan(&s.WorkoutName)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get workout name: %w", err)
+	}
+	return nil
+}
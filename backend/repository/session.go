@@ -3,23 +3,27 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"liftoff/backend/ids"
 	"liftoff/backend/models"
 
-	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// ErrSessionNotFound is returned when a session does not exist or belongs to another user
+var ErrSessionNotFound = errors.New("session not found or access denied")
+
 type SessionRepository struct {
-	db        *pgxpool.Pool
+	db        Pool
 	sqlite    *sql.DB
 	useSQLite bool
 }
 
-func NewSessionRepository(db *pgxpool.Pool, sqlite *sql.DB, useSQLite bool) *SessionRepository {
+func NewSessionRepository(db Pool, sqlite *sql.DB, useSQLite bool) *SessionRepository {
 	if useSQLite {
 		return &SessionRepository{db: nil, sqlite: sqlite, useSQLite: true}
 	}
@@ -36,6 +40,19 @@ func (r *SessionRepository) CreateSession(ctx context.Context, userID, workoutID
 
 // CreateSessionWithExercises creates a session and initializes all exercises with sets
 func (r *SessionRepository) CreateSessionWithExercises(ctx context.Context, userID, workoutID string) (*models.WorkoutSession, error) {
+	return r.CreateSessionWithWarmups(ctx, userID, workoutID, nil)
+}
+
+// CreateSessionWithWarmups is CreateSessionWithExercises with each weighted exercise's
+// working sets preceded by warm-up sets from the scheme, when one is given
+func (r *SessionRepository) CreateSessionWithWarmups(ctx context.Context, userID, workoutID string, warmup *models.WarmupScheme) (*models.WorkoutSession, error) {
+	return r.CreateSessionWithTargets(ctx, userID, workoutID, warmup, nil)
+}
+
+// CreateSessionWithTargets is CreateSessionWithWarmups with each exercise passed
+// through adjust, when given, before its sets are created, e.g. to apply a
+// training program's progression for the week
+func (r *SessionRepository) CreateSessionWithTargets(ctx context.Context, userID, workoutID string, warmup *models.WarmupScheme, adjust func(models.Exercise) models.Exercise) (*models.WorkoutSession, error) {
 	// Create the session first
 	session, err := r.CreateSession(ctx, userID, workoutID)
 	if err != nil {
@@ -51,6 +68,9 @@ func (r *SessionRepository) CreateSessionWithExercises(ctx context.Context, user
 
 	// Create session exercises and sets for each exercise
 	for _, exercise := range workout.Exercises {
+		if adjust != nil {
+			exercise = adjust(exercise)
+		}
 		// Create session exercise (no userID check - we're creating)
 		sessionExercise, err := r.CreateSessionExercise(ctx, "", session.ID, exercise.ID)
 		if err != nil {
@@ -58,6 +78,14 @@ func (r *SessionRepository) CreateSessionWithExercises(ctx context.Context, user
 		}
 
 		// Create sets for this exercise (no userID - internal create)
+		if warmup != nil {
+			for _, w := range warmup.Ramp(exercise) {
+				set := &models.ExerciseSet{SessionExerciseID: sessionExercise.ID, Reps: w.Reps, Weight: w.Weight, SetType: models.SetTypeWarmup}
+				if err := r.CreateExerciseSet(ctx, "", set); err != nil {
+					return nil, fmt.Errorf("failed to create warm-up set: %w", err)
+				}
+			}
+		}
 		for i := 0; i < exercise.Sets; i++ {
 			set := &models.ExerciseSet{
 				SessionExerciseID: sessionExercise.ID,
@@ -65,6 +93,14 @@ func (r *SessionRepository) CreateSessionWithExercises(ctx context.Context, user
 				Weight:            exercise.Weight,
 				Completed:         false,
 			}
+			if exercise.IsTimed() || exercise.IsCardio() && exercise.DurationSeconds > 0 {
+				target := exercise.DurationSeconds
+				set.DurationSeconds = &target
+			}
+			if exercise.IsCardio() && exercise.DistanceMeters > 0 {
+				target := exercise.DistanceMeters
+				set.DistanceMeters = &target
+			}
 			err = r.CreateExerciseSet(ctx, "", set)
 			if err != nil {
 				return nil, fmt.Errorf("failed to create exercise set: %w", err)
@@ -82,7 +118,24 @@ func (r *SessionRepository) GetActiveSessionWithExercises(ctx context.Context, u
 	if err != nil || session == nil {
 		return nil, err
 	}
+	return r.withExercises(ctx, userID, session)
+}
 
+// GetSessionWithExercises returns one of the user's sessions, active or completed,
+// with all exercises and sets populated
+func (r *SessionRepository) GetSessionWithExercises(ctx context.Context, userID, id string) (*models.WorkoutSession, error) {
+	if _, err := r.getSessionForUser(ctx, userID, id); err != nil {
+		return nil, ErrSessionNotFound
+	}
+	session, err := r.GetSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return r.withExercises(ctx, userID, session)
+}
+
+// withExercises loads the workout, exercises and sets of a session owned by the user
+func (r *SessionRepository) withExercises(ctx context.Context, userID string, session *models.WorkoutSession) (*models.WorkoutSession, error) {
 	// Get session exercises
 	sessionExercises, err := r.GetSessionExercises(ctx, session.ID)
 	if err != nil {
@@ -98,7 +151,7 @@ func (r *SessionRepository) GetActiveSessionWithExercises(ctx context.Context, u
 			return nil, fmt.Errorf("failed to get exercise: %w", err)
 		}
 		se.Exercise = exercise
-		
+
 		// Get sets for this exercise
 		sets, err := r.GetExerciseSets(ctx, se.ID)
 		if err != nil {
@@ -121,6 +174,7 @@ func (r *SessionRepository) GetActiveSessionWithExercises(ctx context.Context, u
 		StartedAt: session.StartedAt,
 		EndedAt:   session.EndedAt,
 		IsActive:  session.IsActive,
+		Metadata:  session.Metadata,
 		CreatedAt: session.CreatedAt,
 		UpdatedAt: session.UpdatedAt,
 		Workout:   workout,
@@ -130,23 +184,29 @@ func (r *SessionRepository) GetActiveSessionWithExercises(ctx context.Context, u
 	return sessionWithExercises, nil
 }
 
-// GetCompletedSessions returns all completed workout sessions for the user
-func (r *SessionRepository) GetCompletedSessions(ctx context.Context, userID string) ([]*models.WorkoutSession, error) {
+// GetCompletedSessions returns all completed workout sessions for the user.
+// A non-empty search matches case-insensitively against the values recorded in
+// the session metadata (gym, mood, partners, extra values and so on), never
+// against its field names.
+func (r *SessionRepository) GetCompletedSessions(ctx context.Context, userID, search string) ([]*models.WorkoutSession, error) {
 	if r.useSQLite {
-		return r.getCompletedSessionsSQLite(ctx, userID)
+		return r.getCompletedSessionsSQLite(ctx, userID, search)
 	}
-	return r.getCompletedSessionsPostgres(ctx, userID)
+	return r.getCompletedSessionsPostgres(ctx, userID, search)
 }
 
-func (r *SessionRepository) getCompletedSessionsPostgres(ctx context.Context, userID string) ([]*models.WorkoutSession, error) {
+func (r *SessionRepository) getCompletedSessionsPostgres(ctx context.Context, userID, search string) ([]*models.WorkoutSession, error) {
 	query := `
-		SELECT id, user_id, workout_id, started_at, ended_at, is_active, created_at, updated_at
+		SELECT id, user_id, workout_id, started_at, ended_at, is_active, created_at, updated_at, metadata
 		FROM workout_sessions
 		WHERE user_id = $1 AND is_active = false AND ended_at IS NOT NULL
+		  AND ($2 = '' OR EXISTS (
+			SELECT 1 FROM jsonb_path_query(NULLIF(metadata, '')::jsonb, 'strict $.**') v
+			WHERE jsonb_typeof(v) IN ('string', 'number') AND v #>> '{}' ILIKE $3 ESCAPE '\'))
 		ORDER BY ended_at DESC
 	`
 
-	rows, err := r.db.Query(ctx, query, userID)
+	rows, err := r.db.Query(ctx, query, userID, search, likePattern(search))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get completed sessions: %w", err)
 	}
@@ -157,7 +217,7 @@ func (r *SessionRepository) getCompletedSessionsPostgres(ctx context.Context, us
 		var session models.WorkoutSession
 		err := rows.Scan(
 			&session.ID, &session.UserID, &session.WorkoutID, &session.StartedAt, &session.EndedAt,
-			&session.IsActive, &session.CreatedAt, &session.UpdatedAt,
+			&session.IsActive, &session.CreatedAt, &session.UpdatedAt, &session.Metadata,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan session: %w", err)
@@ -168,15 +228,18 @@ func (r *SessionRepository) getCompletedSessionsPostgres(ctx context.Context, us
 	return sessions, nil
 }
 
-func (r *SessionRepository) getCompletedSessionsSQLite(ctx context.Context, userID string) ([]*models.WorkoutSession, error) {
+func (r *SessionRepository) getCompletedSessionsSQLite(ctx context.Context, userID, search string) ([]*models.WorkoutSession, error) {
 	query := `
-		SELECT id, user_id, workout_id, started_at, ended_at, is_active, created_at, updated_at
+		SELECT id, user_id, workout_id, started_at, ended_at, is_active, created_at, updated_at, metadata
 		FROM workout_sessions
 		WHERE user_id = ? AND is_active = 0 AND ended_at IS NOT NULL
+		  AND (? = '' OR EXISTS (
+			SELECT 1 FROM json_tree(NULLIF(workout_sessions.metadata, '')) v
+			WHERE v.type IN ('text', 'integer', 'real') AND v.value LIKE ? ESCAPE '\'))
 		ORDER BY ended_at DESC
 	`
 
-	rows, err := r.sqlite.QueryContext(ctx, query, userID)
+	rows, err := r.sqlite.QueryContext(ctx, query, userID, search, likePattern(search))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get completed sessions: %w", err)
 	}
@@ -187,7 +250,7 @@ func (r *SessionRepository) getCompletedSessionsSQLite(ctx context.Context, user
 		var session models.WorkoutSession
 		err := rows.Scan(
 			&session.ID, &session.UserID, &session.WorkoutID, &session.StartedAt, &session.EndedAt,
-			&session.IsActive, &session.CreatedAt, &session.UpdatedAt,
+			&session.IsActive, &session.CreatedAt, &session.UpdatedAt, &session.Metadata,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan session: %w", err)
@@ -199,19 +262,19 @@ func (r *SessionRepository) getCompletedSessionsSQLite(ctx context.Context, user
 }
 
 func (r *SessionRepository) createSessionPostgres(ctx context.Context, userID, workoutID string) (*models.WorkoutSession, error) {
-	id := uuid.New().String()
+	id := ids.New()
 	now := time.Now()
 
 	query := `
 		INSERT INTO workout_sessions (id, user_id, workout_id, started_at, is_active, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING id, user_id, workout_id, started_at, ended_at, is_active, created_at, updated_at
+		RETURNING id, user_id, workout_id, started_at, ended_at, is_active, created_at, updated_at, metadata
 	`
 
 	var session models.WorkoutSession
 	err := r.db.QueryRow(ctx, query, id, userID, workoutID, now, true, now, now).Scan(
 		&session.ID, &session.UserID, &session.WorkoutID, &session.StartedAt, &session.EndedAt,
-		&session.IsActive, &session.CreatedAt, &session.UpdatedAt,
+		&session.IsActive, &session.CreatedAt, &session.UpdatedAt, &session.Metadata,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create session: %w", err)
@@ -221,7 +284,7 @@ func (r *SessionRepository) createSessionPostgres(ctx context.Context, userID, w
 }
 
 func (r *SessionRepository) createSessionSQLite(ctx context.Context, userID, workoutID string) (*models.WorkoutSession, error) {
-	id := uuid.New().String()
+	id := ids.New()
 	now := time.Now()
 
 	query := `
@@ -254,7 +317,7 @@ func (r *SessionRepository) GetActiveSession(ctx context.Context, userID string)
 
 func (r *SessionRepository) getActiveSessionPostgres(ctx context.Context, userID string) (*models.WorkoutSession, error) {
 	query := `
-		SELECT id, user_id, workout_id, started_at, ended_at, is_active, created_at, updated_at
+		SELECT id, user_id, workout_id, started_at, ended_at, is_active, created_at, updated_at, metadata
 		FROM workout_sessions
 		WHERE user_id = $1 AND is_active = true
 		ORDER BY started_at DESC
@@ -264,7 +327,7 @@ func (r *SessionRepository) getActiveSessionPostgres(ctx context.Context, userID
 	var session models.WorkoutSession
 	err := r.db.QueryRow(ctx, query, userID).Scan(
 		&session.ID, &session.UserID, &session.WorkoutID, &session.StartedAt, &session.EndedAt,
-		&session.IsActive, &session.CreatedAt, &session.UpdatedAt,
+		&session.IsActive, &session.CreatedAt, &session.UpdatedAt, &session.Metadata,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -278,7 +341,7 @@ func (r *SessionRepository) getActiveSessionPostgres(ctx context.Context, userID
 
 func (r *SessionRepository) getActiveSessionSQLite(ctx context.Context, userID string) (*models.WorkoutSession, error) {
 	query := `
-		SELECT id, user_id, workout_id, started_at, ended_at, is_active, created_at, updated_at
+		SELECT id, user_id, workout_id, started_at, ended_at, is_active, created_at, updated_at, metadata
 		FROM workout_sessions
 		WHERE user_id = ? AND is_active = 1
 		ORDER BY started_at DESC
@@ -288,7 +351,7 @@ func (r *SessionRepository) getActiveSessionSQLite(ctx context.Context, userID s
 	var session models.WorkoutSession
 	err := r.sqlite.QueryRowContext(ctx, query, userID).Scan(
 		&session.ID, &session.UserID, &session.WorkoutID, &session.StartedAt, &session.EndedAt,
-		&session.IsActive, &session.CreatedAt, &session.UpdatedAt,
+		&session.IsActive, &session.CreatedAt, &session.UpdatedAt, &session.Metadata,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -309,7 +372,7 @@ func (r *SessionRepository) GetSession(ctx context.Context, id string) (*models.
 
 func (r *SessionRepository) getSessionPostgres(ctx context.Context, id string) (*models.WorkoutSession, error) {
 	query := `
-		SELECT id, workout_id, started_at, ended_at, is_active, created_at, updated_at
+		SELECT id, workout_id, started_at, ended_at, is_active, created_at, updated_at, metadata
 		FROM workout_sessions
 		WHERE id = $1
 	`
@@ -317,7 +380,7 @@ func (r *SessionRepository) getSessionPostgres(ctx context.Context, id string) (
 	var session models.WorkoutSession
 	err := r.db.QueryRow(ctx, query, id).Scan(
 		&session.ID, &session.WorkoutID, &session.StartedAt, &session.EndedAt,
-		&session.IsActive, &session.CreatedAt, &session.UpdatedAt,
+		&session.IsActive, &session.CreatedAt, &session.UpdatedAt, &session.Metadata,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get session: %w", err)
@@ -328,7 +391,7 @@ func (r *SessionRepository) getSessionPostgres(ctx context.Context, id string) (
 
 func (r *SessionRepository) getSessionSQLite(ctx context.Context, id string) (*models.WorkoutSession, error) {
 	query := `
-		SELECT id, workout_id, started_at, ended_at, is_active, created_at, updated_at
+		SELECT id, workout_id, started_at, ended_at, is_active, created_at, updated_at, metadata
 		FROM workout_sessions
 		WHERE id = ?
 	`
@@ -336,7 +399,7 @@ func (r *SessionRepository) getSessionSQLite(ctx context.Context, id string) (*m
 	var session models.WorkoutSession
 	err := r.sqlite.QueryRowContext(ctx, query, id).Scan(
 		&session.ID, &session.WorkoutID, &session.StartedAt, &session.EndedAt,
-		&session.IsActive, &session.CreatedAt, &session.UpdatedAt,
+		&session.IsActive, &session.CreatedAt, &session.UpdatedAt, &session.Metadata,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get session: %w", err)
@@ -357,13 +420,13 @@ func (r *SessionRepository) endSessionPostgres(ctx context.Context, userID, id s
 		UPDATE workout_sessions
 		SET ended_at = $2, is_active = false, updated_at = $3
 		WHERE id = $1 AND user_id = $4
-		RETURNING id, user_id, workout_id, started_at, ended_at, is_active, created_at, updated_at
+		RETURNING id, user_id, workout_id, started_at, ended_at, is_active, created_at, updated_at, metadata
 	`
 
 	var session models.WorkoutSession
 	err := r.db.QueryRow(ctx, query, id, time.Now(), time.Now(), userID).Scan(
 		&session.ID, &session.UserID, &session.WorkoutID, &session.StartedAt, &session.EndedAt,
-		&session.IsActive, &session.CreatedAt, &session.UpdatedAt,
+		&session.IsActive, &session.CreatedAt, &session.UpdatedAt, &session.Metadata,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to end session: %w", err)
@@ -392,6 +455,62 @@ func (r *SessionRepository) endSessionSQLite(ctx context.Context, userID, id str
 	return r.getSessionSQLite(ctx, id)
 }
 
+// UpdateSessionMetadata replaces the metadata of a session owned by the user
+func (r *SessionRepository) UpdateSessionMetadata(ctx context.Context, userID, id string, metadata models.SessionMetadata) (*models.WorkoutSession, error) {
+	if r.useSQLite {
+		return r.updateSessionMetadataSQLite(ctx, userID, id, metadata)
+	}
+	return r.updateSessionMetadataPostgres(ctx, userID, id, metadata)
+}
+
+func (r *SessionRepository) updateSessionMetadataPostgres(ctx context.Context, userID, id string, metadata models.SessionMetadata) (*models.WorkoutSession, error) {
+	query := `
+		UPDATE workout_sessions
+		SET metadata = $1, updated_at = $2
+		WHERE id = $3 AND user_id = $4
+		RETURNING id, user_id, workout_id, started_at, ended_at, is_active, created_at, updated_at, metadata
+	`
+
+	var session models.WorkoutSession
+	err := r.db.QueryRow(ctx, query, metadata, time.Now(), id, userID).Scan(
+		&session.ID, &session.UserID, &session.WorkoutID, &session.StartedAt, &session.EndedAt,
+		&session.IsActive, &session.CreatedAt, &session.UpdatedAt, &session.Metadata,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("failed to update session metadata: %w", err)
+	}
+
+	return &session, nil
+}
+
+func (r *SessionRepository) updateSessionMetadataSQLite(ctx context.Context, userID, id string, metadata models.SessionMetadata) (*models.WorkoutSession, error) {
+	query := `
+		UPDATE workout_sessions
+		SET metadata = ?, updated_at = ?
+		WHERE id = ? AND user_id = ?
+	`
+
+	result, err := r.sqlite.ExecContext(ctx, query, metadata, time.Now(), id, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update session metadata: %w", err)
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return nil, ErrSessionNotFound
+	}
+
+	return r.getSessionSQLite(ctx, id)
+}
+
+// likePattern wraps a search term for a LIKE ... ESCAPE '\' substring match
+func likePattern(search string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(search)
+	return "%" + escaped + "%"
+}
+
 func (r *SessionRepository) GetSessions(ctx context.Context) ([]*models.WorkoutSession, error) {
 	if r.useSQLite {
 		return r.getSessionsSQLite(ctx)
@@ -401,7 +520,7 @@ func (r *SessionRepository) GetSessions(ctx context.Context) ([]*models.WorkoutS
 
 func (r *SessionRepository) getSessionsPostgres(ctx context.Context) ([]*models.WorkoutSession, error) {
 	query := `
-		SELECT id, workout_id, started_at, ended_at, is_active, created_at, updated_at
+		SELECT id, workout_id, started_at, ended_at, is_active, created_at, updated_at, metadata
 		FROM workout_sessions
 		ORDER BY started_at DESC
 	`
@@ -417,7 +536,7 @@ func (r *SessionRepository) getSessionsPostgres(ctx context.Context) ([]*models.
 		var session models.WorkoutSession
 		err := rows.Scan(
 			&session.ID, &session.WorkoutID, &session.StartedAt, &session.EndedAt,
-			&session.IsActive, &session.CreatedAt, &session.UpdatedAt,
+			&session.IsActive, &session.CreatedAt, &session.UpdatedAt, &session.Metadata,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan session: %w", err)
@@ -430,7 +549,7 @@ func (r *SessionRepository) getSessionsPostgres(ctx context.Context) ([]*models.
 
 func (r *SessionRepository) getSessionsSQLite(ctx context.Context) ([]*models.WorkoutSession, error) {
 	query := `
-		SELECT id, workout_id, started_at, ended_at, is_active, created_at, updated_at
+		SELECT id, workout_id, started_at, ended_at, is_active, created_at, updated_at, metadata
 		FROM workout_sessions
 		ORDER BY started_at DESC
 	`
@@ -446,7 +565,7 @@ func (r *SessionRepository) getSessionsSQLite(ctx context.Context) ([]*models.Wo
 		var session models.WorkoutSession
 		err := rows.Scan(
 			&session.ID, &session.WorkoutID, &session.StartedAt, &session.EndedAt,
-			&session.IsActive, &session.CreatedAt, &session.UpdatedAt,
+			&session.IsActive, &session.CreatedAt, &session.UpdatedAt, &session.Metadata,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan session: %w", err)
@@ -500,7 +619,7 @@ func (r *SessionRepository) getSessionForUserSQLite(ctx context.Context, userID,
 }
 
 func (r *SessionRepository) createSessionExercisePostgres(ctx context.Context, sessionID, exerciseID string) (*models.SessionExercise, error) {
-	id := uuid.New().String()
+	id := ids.New()
 	now := time.Now()
 
 	query := `
@@ -522,7 +641,7 @@ func (r *SessionRepository) createSessionExercisePostgres(ctx context.Context, s
 }
 
 func (r *SessionRepository) createSessionExerciseSQLite(ctx context.Context, sessionID, exerciseID string) (*models.SessionExercise, error) {
-	id := uuid.New().String()
+	id := ids.New()
 	now := time.Now()
 
 	query := `
@@ -618,6 +737,9 @@ func (r *SessionRepository) CreateExerciseSet(ctx context.Context, userID string
 			return fmt.Errorf("session exercise not found or access denied")
 		}
 	}
+	if set.SetType == "" {
+		set.SetType = models.SetTypeNormal
+	}
 	if r.useSQLite {
 		return r.createExerciseSetSQLite(ctx, set)
 	}
@@ -659,15 +781,15 @@ func (r *SessionRepository) verifySessionExerciseAccess(ctx context.Context, use
 }
 
 func (r *SessionRepository) createExerciseSetPostgres(ctx context.Context, set *models.ExerciseSet) error {
-	id := uuid.New().String()
+	id := ids.New()
 	now := time.Now()
 
 	query := `
-		INSERT INTO exercise_sets (id, session_exercise_id, reps, weight, completed, notes, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO exercise_sets (id, session_exercise_id, reps, weight, duration_seconds, distance_meters, technique, segments, segment_volume, sides, side_volume, actual_rpe, set_type, completed, notes, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
 	`
 
-	_, err := r.db.Exec(ctx, query, id, set.SessionExerciseID, set.Reps, set.Weight, set.Completed, set.Notes, now, now)
+	_, err := r.db.Exec(ctx, query, id, set.SessionExerciseID, set.Reps, set.Weight, set.DurationSeconds, set.DistanceMeters, set.Technique, set.Segments, set.Segments.Volume(), set.Sides, set.SideVolume(), set.ActualRPE, set.SetType, set.Completed, set.Notes, now, now)
 	if err != nil {
 		return fmt.Errorf("failed to create exercise set: %w", err)
 	}
@@ -679,15 +801,15 @@ func (r *SessionRepository) createExerciseSetPostgres(ctx context.Context, set *
 }
 
 func (r *SessionRepository) createExerciseSetSQLite(ctx context.Context, set *models.ExerciseSet) error {
-	id := uuid.New().String()
+	id := ids.New()
 	now := time.Now()
 
 	query := `
-		INSERT INTO exercise_sets (id, session_exercise_id, reps, weight, completed, notes, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO exercise_sets (id, session_exercise_id, reps, weight, duration_seconds, distance_meters, technique, segments, segment_volume, sides, side_volume, actual_rpe, set_type, completed, notes, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	_, err := r.sqlite.ExecContext(ctx, query, id, set.SessionExerciseID, set.Reps, set.Weight, set.Completed, set.Notes, now, now)
+	_, err := r.sqlite.ExecContext(ctx, query, id, set.SessionExerciseID, set.Reps, set.Weight, set.DurationSeconds, set.DistanceMeters, set.Technique, set.Segments, set.Segments.Volume(), set.Sides, set.SideVolume(), set.ActualRPE, set.SetType, set.Completed, set.Notes, now, now)
 	if err != nil {
 		return fmt.Errorf("failed to create exercise set: %w", err)
 	}
@@ -707,7 +829,7 @@ func (r *SessionRepository) GetExerciseSets(ctx context.Context, sessionExercise
 
 func (r *SessionRepository) getExerciseSetsPostgres(ctx context.Context, sessionExerciseID string) ([]*models.ExerciseSet, error) {
 	query := `
-		SELECT id, session_exercise_id, reps, weight, completed, notes, created_at, updated_at
+		SELECT id, session_exercise_id, reps, weight, duration_seconds, distance_meters, technique, segments, sides, actual_rpe, set_type, completed, notes, created_at, updated_at
 		FROM exercise_sets
 		WHERE session_exercise_id = $1
 		ORDER BY created_at ASC
@@ -723,7 +845,7 @@ func (r *SessionRepository) getExerciseSetsPostgres(ctx context.Context, session
 	for rows.Next() {
 		var set models.ExerciseSet
 		err := rows.Scan(
-			&set.ID, &set.SessionExerciseID, &set.Reps, &set.Weight,
+			&set.ID, &set.SessionExerciseID, &set.Reps, &set.Weight, &set.DurationSeconds, &set.DistanceMeters, &set.Technique, &set.Segments, &set.Sides, &set.ActualRPE, &set.SetType,
 			&set.Completed, &set.Notes, &set.CreatedAt, &set.UpdatedAt,
 		)
 		if err != nil {
@@ -737,7 +859,7 @@ func (r *SessionRepository) getExerciseSetsPostgres(ctx context.Context, session
 
 func (r *SessionRepository) getExerciseSetsSQLite(ctx context.Context, sessionExerciseID string) ([]*models.ExerciseSet, error) {
 	query := `
-		SELECT id, session_exercise_id, reps, weight, completed, notes, created_at, updated_at
+		SELECT id, session_exercise_id, reps, weight, duration_seconds, distance_meters, technique, segments, sides, actual_rpe, set_type, completed, notes, created_at, updated_at
 		FROM exercise_sets
 		WHERE session_exercise_id = ?
 		ORDER BY created_at ASC
@@ -753,7 +875,7 @@ func (r *SessionRepository) getExerciseSetsSQLite(ctx context.Context, sessionEx
 	for rows.Next() {
 		var set models.ExerciseSet
 		err := rows.Scan(
-			&set.ID, &set.SessionExerciseID, &set.Reps, &set.Weight,
+			&set.ID, &set.SessionExerciseID, &set.Reps, &set.Weight, &set.DurationSeconds, &set.DistanceMeters, &set.Technique, &set.Segments, &set.Sides, &set.ActualRPE, &set.SetType,
 			&set.Completed, &set.Notes, &set.CreatedAt, &set.UpdatedAt,
 		)
 		if err != nil {
@@ -790,11 +912,13 @@ func (r *SessionRepository) UpdateExerciseSet(ctx context.Context, userID string
 func (r *SessionRepository) updateExerciseSetPostgres(ctx context.Context, set *models.ExerciseSet) error {
 	query := `
 		UPDATE exercise_sets
-		SET reps = $2, weight = $3, completed = $4, notes = $5, updated_at = $6
+		SET reps = $2, weight = $3, duration_seconds = $4, distance_meters = $5, technique = $6, segments = $7, segment_volume = $8,
+			sides = $9, side_volume = $10, actual_rpe = $11, set_type = COALESCE(NULLIF($12, ''), set_type), completed = $13, notes = $14, updated_at = $15
 		WHERE id = $1
 	`
 
-	_, err := r.db.Exec(ctx, query, set.ID, set.Reps, set.Weight, set.Completed, set.Notes, time.Now())
+	_, err := r.db.Exec(ctx, query, set.ID, set.Reps, set.Weight, set.DurationSeconds, set.DistanceMeters, set.Technique, set.Segments, set.Segments.Volume(),
+		set.Sides, set.SideVolume(), set.ActualRPE, set.SetType, set.Completed, set.Notes, time.Now())
 	if err != nil {
 		return fmt.Errorf("failed to update exercise set: %w", err)
 	}
@@ -805,11 +929,13 @@ func (r *SessionRepository) updateExerciseSetPostgres(ctx context.Context, set *
 func (r *SessionRepository) updateExerciseSetSQLite(ctx context.Context, set *models.ExerciseSet) error {
 	query := `
 		UPDATE exercise_sets
-		SET reps = ?, weight = ?, completed = ?, notes = ?, updated_at = ?
+		SET reps = ?, weight = ?, duration_seconds = ?, distance_meters = ?, technique = ?, segments = ?, segment_volume = ?,
+			sides = ?, side_volume = ?, actual_rpe = ?, set_type = COALESCE(NULLIF(?, ''), set_type), completed = ?, notes = ?, updated_at = ?
 		WHERE id = ?
 	`
 
-	_, err := r.sqlite.ExecContext(ctx, query, set.Reps, set.Weight, set.Completed, set.Notes, time.Now(), set.ID)
+	_, err := r.sqlite.ExecContext(ctx, query, set.Reps, set.Weight, set.DurationSeconds, set.DistanceMeters, set.Technique, set.Segments, set.Segments.Volume(),
+		set.Sides, set.SideVolume(), set.ActualRPE, set.SetType, set.Completed, set.Notes, time.Now(), set.ID)
 	if err != nil {
 		return fmt.Errorf("failed to update exercise set: %w", err)
 	}
@@ -846,23 +972,35 @@ func (r *SessionRepository) GetProgressData(ctx context.Context, userID string)
 	return r.getProgressDataPostgres(ctx, userID)
 }
 
+// getProgressDataPostgres reads days before the last refresh of the daily_exercise_volume
+// view from the view, and later days from the tables, so new sets show up immediately
 func (r *SessionRepository) getProgressDataPostgres(ctx context.Context, userID string) ([]map[string]interface{}, error) {
 	query := `
+		WITH cutoff AS (SELECT DATE(` + analyticsRefreshedAt + `) AS day)
+		SELECT exercise_name, day, max_weight, total_volume, mode, total_duration, max_duration, total_distance, max_distance
+		FROM daily_exercise_volume
+		WHERE user_id = $2 AND day < (SELECT day FROM cutoff)
+		UNION ALL
 		SELECT 
 			e.name as exercise_name,
 			DATE(es.created_at) as workout_date,
 			MAX(es.weight) as max_weight,
-			SUM(es.weight * es.reps) as total_volume
+			SUM(es.weight * es.reps + es.segment_volume + es.side_volume) as total_volume,
+			MAX(e.mode) as mode,
+			COALESCE(SUM(es.duration_seconds), 0) as total_duration,
+			COALESCE(MAX(es.duration_seconds), 0) as max_duration,
+			COALESCE(SUM(es.distance_meters), 0) as total_distance,
+			COALESCE(MAX(es.distance_meters), 0) as max_distance
 		FROM exercise_sets es
 		JOIN session_exercises se ON es.session_exercise_id = se.id
 		JOIN workout_sessions ws ON se.session_id = ws.id
 		JOIN exercises e ON se.exercise_id = e.id
-		WHERE es.completed = true AND ws.user_id = $1
+		WHERE es.completed = true AND es.set_type <> 'warmup' AND ws.user_id = $2 AND es.created_at >= (SELECT day FROM cutoff)
 		GROUP BY e.name, DATE(es.created_at)
-		ORDER BY workout_date DESC, exercise_name
+		ORDER BY 2 DESC, 1
 	`
 
-	rows, err := r.db.Query(ctx, query, userID)
+	rows, err := r.db.Query(ctx, query, viewDailyExerciseVolume, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get progress data: %w", err)
 	}
@@ -874,17 +1012,25 @@ func (r *SessionRepository) getProgressDataPostgres(ctx context.Context, userID
 		var workoutDate time.Time
 		var maxWeight float64
 		var totalVolume float64
+		var mode string
+		var totalDuration, maxDuration int
+		var totalDistance, maxDistance int
 
-		err := rows.Scan(&exerciseName, &workoutDate, &maxWeight, &totalVolume)
+		err := rows.Scan(&exerciseName, &workoutDate, &maxWeight, &totalVolume, &mode, &totalDuration, &maxDuration, &totalDistance, &maxDistance)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan progress data: %w", err)
 		}
 
 		progress = append(progress, map[string]interface{}{
-			"exerciseName": exerciseName,
-			"date":         workoutDate.Format("2006-01-02"),
-			"maxWeight":    maxWeight,
-			"totalVolume":  totalVolume,
+			"exerciseName":  exerciseName,
+			"date":          workoutDate.Format("2006-01-02"),
+			"maxWeight":     maxWeight,
+			"totalVolume":   totalVolume,
+			"mode":          mode,
+			"totalDuration": totalDuration,
+			"maxDuration":   maxDuration,
+			"totalDistance": totalDistance,
+			"maxDistance":   maxDistance,
 		})
 	}
 
@@ -897,12 +1043,17 @@ func (r *SessionRepository) getProgressDataSQLite(ctx context.Context, userID st
 			e.name as exercise_name,
 			DATE(es.created_at) as workout_date,
 			MAX(es.weight) as max_weight,
-			SUM(es.weight * es.reps) as total_volume
+			SUM(es.weight * es.reps + es.segment_volume + es.side_volume) as total_volume,
+			MAX(e.mode) as mode,
+			COALESCE(SUM(es.duration_seconds), 0) as total_duration,
+			COALESCE(MAX(es.duration_seconds), 0) as max_duration,
+			COALESCE(SUM(es.distance_meters), 0) as total_distance,
+			COALESCE(MAX(es.distance_meters), 0) as max_distance
 		FROM exercise_sets es
 		JOIN session_exercises se ON es.session_exercise_id = se.id
 		JOIN workout_sessions ws ON se.session_id = ws.id
 		JOIN exercises e ON se.exercise_id = e.id
-		WHERE es.completed = 1 AND ws.user_id = ?
+		WHERE es.completed = 1 AND es.set_type <> 'warmup' AND ws.user_id = ?
 		GROUP BY e.name, DATE(es.created_at)
 		ORDER BY workout_date DESC, exercise_name
 	`
@@ -916,22 +1067,289 @@ func (r *SessionRepository) getProgressDataSQLite(ctx context.Context, userID st
 	var progress []map[string]interface{}
 	for rows.Next() {
 		var exerciseName string
-		var workoutDate time.Time
+		var workoutDate string // DATE() is text in SQLite
 		var maxWeight float64
 		var totalVolume float64
+		var mode string
+		var totalDuration, maxDuration int
+		var totalDistance, maxDistance int
 
-		err := rows.Scan(&exerciseName, &workoutDate, &maxWeight, &totalVolume)
+		err := rows.Scan(&exerciseName, &workoutDate, &maxWeight, &totalVolume, &mode, &totalDuration, &maxDuration, &totalDistance, &maxDistance)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan progress data: %w", err)
 		}
 
 		progress = append(progress, map[string]interface{}{
-			"exerciseName": exerciseName,
-			"date":         workoutDate.Format("2006-01-02"),
-			"maxWeight":    maxWeight,
-			"totalVolume":  totalVolume,
+			"exerciseName":  exerciseName,
+			"date":          workoutDate,
+			"maxWeight":     maxWeight,
+			"totalVolume":   totalVolume,
+			"mode":          mode,
+			"totalDuration": totalDuration,
+			"maxDuration":   maxDuration,
+			"totalDistance": totalDistance,
+			"maxDistance":   maxDistance,
 		})
 	}
 
 	return progress, nil
 }
+
+// GetSessionLoads returns the work done in each session the user finished since the given time
+func (r *SessionRepository) GetSessionLoads(ctx context.Context, userID string, since time.Time) ([]models.SessionLoad, error) {
+	var loads []models.SessionLoad
+	scan := func(scan func(...interface{}) error) error {
+		var load models.SessionLoad
+		var started time.Time
+		if err := scan(&load.SessionID, &started, &load.EndedAt, &load.Sets, &load.Tonnage); err != nil {
+			return fmt.Errorf("failed to scan session load: %w", err)
+		}
+		load.Minutes = load.EndedAt.Sub(started).Minutes()
+		loads = append(loads, load)
+		return nil
+	}
+
+	if r.useSQLite {
+		rows, err := r.sqlite.QueryContext(ctx, `
+			SELECT ws.id, ws.started_at, ws.ended_at,
+				COUNT(es.id), COALESCE(SUM(es.weight * es.reps + es.segment_volume + es.side_volume), 0)
+			FROM workout_sessions ws
+			LEFT JOIN session_exercises se ON se.session_id = ws.id
+			LEFT JOIN exercise_sets es ON es.session_exercise_id = se.id AND es.completed = 1 AND es.set_type <> 'warmup'
+			WHERE ws.user_id = ? AND ws.ended_at IS NOT NULL AND ws.ended_at >= ?
+			GROUP BY ws.id, ws.started_at, ws.ended_at`,
+			userID, since)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get session loads: %w", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			if err := scan(rows.Scan); err != nil {
+				return nil, err
+			}
+		}
+		return loads, rows.Err()
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT ws.id, ws.started_at, ws.ended_at,
+			COUNT(es.id), COALESCE(SUM(es.weight * es.reps + es.segment_volume + es.side_volume), 0)::float8
+		FROM workout_sessions ws
+		LEFT JOIN session_exercises se ON se.session_id = ws.id
+		LEFT JOIN exercise_sets es ON es.session_exercise_id = se.id AND es.completed = true AND es.set_type <> 'warmup'
+		WHERE ws.user_id = $1 AND ws.ended_at IS NOT NULL AND ws.ended_at >= $2
+		GROUP BY ws.id, ws.started_at, ws.ended_at`,
+		userID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session loads: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := scan(rows.Scan); err != nil {
+			return nil, err
+		}
+	}
+	return loads, rows.Err()
+}
+
+// GetSideSets returns the user's completed unilateral sets logged since the given time, oldest first
+func (r *SessionRepository) GetSideSets(ctx context.Context, userID string, since time.Time) ([]models.SideSetEntry, error) {
+	var entries []models.SideSetEntry
+	scan := func(scan func(...interface{}) error) error {
+		var entry models.SideSetEntry
+		if err := scan(&entry.ExerciseName, &entry.LoggedAt, &entry.Sides); err != nil {
+			return fmt.Errorf("failed to scan side set: %w", err)
+		}
+		entries = append(entries, entry)
+		return nil
+	}
+
+	const query = `
+		SELECT e.name, es.created_at, es.sides
+		FROM exercise_sets es
+		JOIN session_exercises se ON es.session_exercise_id = se.id
+		JOIN workout_sessions ws ON se.session_id = ws.id
+		JOIN exercises e ON se.exercise_id = e.id
+		WHERE ws.user_id = %s AND es.completed = %s AND es.set_type <> 'warmup' AND es.sides IS NOT NULL AND es.created_at >= %s
+		ORDER BY es.created_at`
+
+	if r.useSQLite {
+		rows, err := r.sqlite.QueryContext(ctx, fmt.Sprintf(query, "?", "1", "?"), userID, since)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get side sets: %w", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			if err := scan(rows.Scan); err != nil {
+				return nil, err
+			}
+		}
+		return entries, rows.Err()
+	}
+
+	rows, err := r.db.Query(ctx, fmt.Sprintf(query, "$1", "true", "$2"), userID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get side sets: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := scan(rows.Scan); err != nil {
+			return nil, err
+		}
+	}
+	return entries, rows.Err()
+}
+
+// GetCrowdRatings returns the crowd ratings the user journaled at a gym, matching the
+// gym name case-insensitively. Metadata is JSON, so the gym is matched after decoding.
+func (r *SessionRepository) GetCrowdRatings(ctx context.Context, userID, gym string) ([]models.CrowdRating, error) {
+	var ratings []models.CrowdRating
+	scan := func(scan func(...interface{}) error) error {
+		var startedAt time.Time
+		var metadata models.SessionMetadata
+		if err := scan(&startedAt, &metadata); err != nil {
+			return fmt.Errorf("failed to scan crowd rating: %w", err)
+		}
+		if metadata.Crowd > 0 && strings.EqualFold(metadata.Gym, gym) {
+			ratings = append(ratings, models.CrowdRating{StartedAt: startedAt, Crowd: metadata.Crowd})
+		}
+		return nil
+	}
+
+	const query = `
+		SELECT started_at, metadata
+		FROM workout_sessions
+		WHERE user_id = %s AND metadata IS NOT NULL
+		ORDER BY started_at`
+
+	if r.useSQLite {
+		rows, err := r.sqlite.QueryContext(ctx, fmt.Sprintf(query, "?"), userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get crowd ratings: %w", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			if err := scan(rows.Scan); err != nil {
+				return nil, err
+			}
+		}
+		return ratings, rows.Err()
+	}
+
+	rows, err := r.db.Query(ctx, fmt.Sprintf(query, "$1"), userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get crowd ratings: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := scan(rows.Scan); err != nil {
+			return nil, err
+		}
+	}
+	return ratings, rows.Err()
+}
+
+// GetExerciseBests returns the user's best completed results per exercise, keyed by
+// lowercased exercise name, from sessions started before the given time. Estimated
+// 1RM uses the Epley formula, as calc.OneRepMax does.
+func (r *SessionRepository) GetExerciseBests(ctx context.Context, userID string, before time.Time) (map[string]models.ExerciseBest, error) {
+	bests := map[string]models.ExerciseBest{}
+	scan := func(scan func(...interface{}) error) error {
+		var name string
+		var best models.ExerciseBest
+		if err := scan(&name, &best.Weight, &best.E1RM, &best.Reps, &best.DurationSeconds, &best.DistanceMeters); err != nil {
+			return fmt.Errorf("failed to scan exercise best: %w", err)
+		}
+		bests[name] = best
+		return nil
+	}
+
+	const query = `
+		SELECT LOWER(e.name), MAX(es.weight),
+		       MAX(CASE WHEN es.weight <= 0 THEN 0
+		                WHEN es.reps = 1 THEN es.weight
+		                WHEN es.reps BETWEEN 2 AND 30 THEN es.weight * (1 + es.reps / 30.0)
+		                ELSE 0 END),
+		       MAX(es.reps), MAX(COALESCE(es.duration_seconds, 0)), MAX(COALESCE(es.distance_meters, 0))
+		FROM exercise_sets es
+		JOIN session_exercises se ON es.session_exercise_id = se.id
+		JOIN workout_sessions ws ON se.session_id = ws.id
+		JOIN exercises e ON se.exercise_id = e.id
+		WHERE ws.user_id = %s AND es.completed = %s AND es.set_type <> 'warmup' AND ws.started_at < %s
+		GROUP BY LOWER(e.name)`
+
+	if r.useSQLite {
+		rows, err := r.sqlite.QueryContext(ctx, fmt.Sprintf(query, "?", "1", "?"), userID, before)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get exercise bests: %w", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			if err := scan(rows.Scan); err != nil {
+				return nil, err
+			}
+		}
+		return bests, rows.Err()
+	}
+
+	rows, err := r.db.Query(ctx, fmt.Sprintf(query, "$1", "true", "$2"), userID, before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exercise bests: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := scan(rows.Scan); err != nil {
+			return nil, err
+		}
+	}
+	return bests, rows.Err()
+}
+
+// GetExerciseSetHistory returns the user's completed working sets of an exercise,
+// matched by name case-insensitively, oldest session first
+func (r *SessionRepository) GetExerciseSetHistory(ctx context.Context, userID, exerciseName string) ([]models.LoggedSet, error) {
+	var sets []models.LoggedSet
+	scan := func(scan func(...interface{}) error) error {
+		var set models.LoggedSet
+		if err := scan(&set.SessionID, &set.StartedAt, &set.Weight, &set.Reps); err != nil {
+			return fmt.Errorf("failed to scan exercise set history: %w", err)
+		}
+		sets = append(sets, set)
+		return nil
+	}
+
+	const query = `
+		SELECT ws.id, ws.started_at, es.weight, es.reps
+		FROM exercise_sets es
+		JOIN session_exercises se ON es.session_exercise_id = se.id
+		JOIN workout_sessions ws ON se.session_id = ws.id
+		JOIN exercises e ON se.exercise_id = e.id
+		WHERE ws.user_id = %s AND es.completed = %s AND es.set_type <> 'warmup' AND LOWER(e.name) = LOWER(%s)
+		ORDER BY ws.started_at, es.created_at`
+
+	if r.useSQLite {
+		rows, err := r.sqlite.QueryContext(ctx, fmt.Sprintf(query, "?", "1", "?"), userID, exerciseName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get exercise set history: %w", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			if err := scan(rows.Scan); err != nil {
+				return nil, err
+			}
+		}
+		return sets, rows.Err()
+	}
+
+	rows, err := r.db.Query(ctx, fmt.Sprintf(query, "$1", "true", "$2"), userID, exerciseName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exercise set history: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := scan(rows.Scan); err != nil {
+			return nil, err
+		}
+	}
+	return sets, rows.Err()
+}
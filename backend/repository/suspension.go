@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"liftoff/backend/models"
+)
+
+// SetStatus suspends or reinstates a user, recording who did it in the audit log.
+// Suspending also cuts off every token issued so far, so reinstated users sign in again.
+func (r *UserRepository) SetStatus(ctx context.Context, userID, actorID, status, reason string) (*models.AuditEntry, error) {
+	action := models.AuditActionAccountReinstated
+	if status == models.UserStatusSuspended {
+		action = models.AuditActionAccountSuspended
+	}
+	entry, args, err := newAuditEntry(userID, actorID, action, map[string]string{"reason": reason})
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.inMergeTx(ctx, func(tx mergeTx) error {
+		affected, err := tx.exec(`UPDATE users SET status = $1 WHERE id = $2`, status, userID)
+		if err != nil {
+			return fmt.Errorf("failed to set user status: %w", err)
+		}
+		if affected == 0 {
+			return ErrUserNotFound
+		}
+		if status == models.UserStatusSuspended {
+			// Cut off at the next second so tokens issued within this one are caught too
+			if _, err := tx.exec(`
+				INSERT INTO user_token_cutoffs (user_id, revoked_before) VALUES ($1, $2)
+				ON CONFLICT (user_id) DO UPDATE SET revoked_before = EXCLUDED.revoked_before`,
+				userID, time.Now().Unix()+1); err != nil {
+				return fmt.Errorf("failed to revoke user tokens: %w", err)
+			}
+		}
+		if _, err := tx.exec(insertAuditEntry, args...); err != nil {
+			return fmt.Errorf("failed to record audit entry: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"liftoff/backend/ids"
+	"liftoff/backend/models"
+)
+
+var (
+	// ErrCategoryNotFound is returned for unknown category or muscle group IDs
+	ErrCategoryNotFound = errors.New("category not found")
+	// ErrCategoryNameTaken is returned when another category already has the name
+	ErrCategoryNameTaken = errors.New("a category with this name already exists")
+	// ErrCategoryDepth is returned when a muscle group would be nested below another muscle
+	// group, or a category with muscle groups would itself become one
+	ErrCategoryDepth = errors.New("muscle groups must belong to a top-level category")
+	// ErrCategoryInUse is returned when deleting a category that still has muscle groups or exercises
+	ErrCategoryInUse = errors.New("category still has muscle groups or exercises filed under it")
+)
+
+// TaxonomyRepository stores the admin-managed exercise categories and muscle groups,
+// and which of them each library exercise is filed under
+type TaxonomyRepository struct {
+	db        Pool
+	sqlite    *sql.DB
+	useSQLite bool
+}
+
+// NewTaxonomyRepository creates a new taxonomy repository
+func NewTaxonomyRepository(db Pool, sqlite *sql.DB, useSQLite bool) *TaxonomyRepository {
+	if useSQLite {
+		return &TaxonomyRepository{db: nil, sqlite: sqlite, useSQLite: true}
+	}
+	return &TaxonomyRepository{db: db, sqlite: nil, useSQLite: false}
+}
+
+// exec runs a statement and returns the number of rows it affected
+func (r *TaxonomyRepository) exec(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	return exec(ctx, r.db, r.sqlite, r.useSQLite, query, args...)
+}
+
+// Load reads the whole taxonomy. It is small, so callers load it once per request or job.
+func (r *TaxonomyRepository) Load(ctx context.Context) (*models.Taxonomy, error) {
+	var rows []*models.ExerciseCategory
+	err := eachRow(ctx, r.db, r.sqlite, r.useSQLite, `SELECT id, COALESCE(parent_id, ''), name, position FROM exercise_categories`, nil, func(scan func(...interface{}) error) error {
+		var c models.ExerciseCategory
+		if err := scan(&c.ID, &c.ParentID, &c.Name, &c.Position); err != nil {
+			return err
+		}
+		rows = append(rows, &c)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load exercise categories: %w", err)
+	}
+	library := map[string]string{}
+	err = eachRow(ctx, r.db, r.sqlite, r.useSQLite, `SELECT name, category_id FROM exercise_library`, nil, func(scan func(...interface{}) error) error {
+		var name, categoryID string
+		if err := scan(&name, &categoryID); err != nil {
+			return err
+		}
+		library[name] = categoryID
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load exercise library categories: %w", err)
+	}
+	return models.NewTaxonomy(rows, library), nil
+}
+
+// CreateCategory adds a top-level category, or a muscle group when c.ParentID is set
+func (r *TaxonomyRepository) CreateCategory(ctx context.Context, c *models.ExerciseCategory) error {
+	tax, err := r.Load(ctx)
+	if err != nil {
+		return err
+	}
+	if err := validateCategory(tax, c); err != nil {
+		return err
+	}
+	c.ID = ids.New()
+	c.Children = nil
+	_, err = r.exec(ctx, `INSERT INTO exercise_categories (id, parent_id, name, position, created_at) VALUES ($1, NULLIF($2, ''), $3, $4, $5)`,
+		c.ID, c.ParentID, c.Name, c.Position, time.Now().Unix())
+	if err != nil {
+		return fmt.Errorf("failed to create category: %w", err)
+	}
+	return nil
+}
+
+// UpdateCategory renames, moves or reorders a category
+func (r *TaxonomyRepository) UpdateCategory(ctx context.Context, c *models.ExerciseCategory) error {
+	tax, err := r.Load(ctx)
+	if err != nil {
+		return err
+	}
+	existing := tax.Category(c.ID)
+	if existing == nil {
+		return ErrCategoryNotFound
+	}
+	if c.ParentID != "" && len(existing.Children) > 0 {
+		return ErrCategoryDepth
+	}
+	if err := validateCategory(tax, c); err != nil {
+		return err
+	}
+	_, err = r.exec(ctx, `UPDATE exercise_categories SET parent_id = NULLIF($1, ''), name = $2, position = $3 WHERE id = $4`,
+		c.ParentID, c.Name, c.Position, c.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update category: %w", err)
+	}
+	c.Children = existing.Children
+	return nil
+}
+
+// validateCategory checks c's parent and name against the current taxonomy
+func validateCategory(tax *models.Taxonomy, c *models.ExerciseCategory) error {
+	if c.ParentID != "" {
+		parent := tax.Category(c.ParentID)
+		if parent == nil {
+			return ErrCategoryNotFound
+		}
+		if parent.ParentID != "" || parent.ID == c.ID {
+			return ErrCategoryDepth
+		}
+	}
+	for _, root := range tax.Categories {
+		for _, other := range append([]*models.ExerciseCategory{root}, root.Children...) {
+			if other.ID != c.ID && strings.EqualFold(other.Name, c.Name) {
+				return ErrCategoryNameTaken
+			}
+		}
+	}
+	return nil
+}
+
+// DeleteCategory removes a category that has no muscle groups or exercises filed under it
+func (r *TaxonomyRepository) DeleteCategory(ctx context.Context, id string) error {
+	var inUse int
+	var err error
+	query := `SELECT (SELECT COUNT(*) FROM exercise_categories WHERE parent_id = $1) + (SELECT COUNT(*) FROM exercise_library WHERE category_id = $2)`
+	if r.useSQLite {
+		err = r.sqlite.QueryRowContext(ctx, rebind(query, 2), id, id).Scan(&inUse)
+	} else {
+		err = r.db.QueryRow(ctx, query, id, id).Scan(&inUse)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check category usage: %w", err)
+	}
+	if inUse > 0 {
+		return ErrCategoryInUse
+	}
+	n, err := r.exec(ctx, `DELETE FROM exercise_categories WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete category: %w", err)
+	}
+	if n == 0 {
+		return ErrCategoryNotFound
+	}
+	return nil
+}
+
+// SetExerciseCategory files a library exercise under a category or muscle group
+func (r *TaxonomyRepository) SetExerciseCategory(ctx context.Context, exerciseName, categoryID string) error {
+	tax, err := r.Load(ctx)
+	if err != nil {
+		return err
+	}
+	if tax.Category(categoryID) == nil {
+		return ErrCategoryNotFound
+	}
+	_, err = r.exec(ctx, `INSERT INTO exercise_library (name, category_id) VALUES ($1, $2)
+		ON CONFLICT (name) DO UPDATE SET category_id = excluded.category_id`, exerciseName, categoryID)
+	if err != nil {
+		return fmt.Errorf("failed to set exercise category: %w", err)
+	}
+	return nil
+}
@@ -5,24 +5,25 @@ import (
 	"crypto/rand"
 	"database/sql"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"time"
 
+	"liftoff/backend/ids"
 	"liftoff/backend/models"
 
-	"github.com/google/uuid"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5"
 )
 
 // UserRepository manages user-related database operations
 type UserRepository struct {
-	db        *pgxpool.Pool
+	db        Pool
 	sqlite    *sql.DB
 	useSQLite bool
 }
 
 // NewUserRepository creates a new user repository
-func NewUserRepository(db *pgxpool.Pool, sqlite *sql.DB, useSQLite bool) *UserRepository {
+func NewUserRepository(db Pool, sqlite *sql.DB, useSQLite bool) *UserRepository {
 	if useSQLite {
 		return &UserRepository{db: nil, sqlite: sqlite, useSQLite: true}
 	}
@@ -31,7 +32,7 @@ func NewUserRepository(db *pgxpool.Pool, sqlite *sql.DB, useSQLite bool) *UserRe
 
 // CreateUser creates a new user with hashed password
 func (r *UserRepository) CreateUser(ctx context.Context, email, passwordHash string) (*models.User, error) {
-	id := uuid.New().String()
+	id := ids.New()
 
 	if r.useSQLite {
 		return r.createUserSQLite(ctx, id, email, passwordHash)
@@ -43,12 +44,12 @@ func (r *UserRepository) createUserPostgres(ctx context.Context, id, email, pass
 	query := `
 		INSERT INTO users (id, email, password_hash, created_at)
 		VALUES ($1, $2, $3, NOW())
-		RETURNING id, email, created_at
+		RETURNING id, email, role, status, created_at
 	`
 
 	var user models.User
 	err := r.db.QueryRow(ctx, query, id, email, passwordHash).Scan(
-		&user.ID, &user.Email, &user.CreatedAt,
+		&user.ID, &user.Email, &user.Role, &user.Status, &user.CreatedAt,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create user: %w", err)
@@ -69,8 +70,8 @@ func (r *UserRepository) createUserSQLite(ctx context.Context, id, email, passwo
 	}
 
 	var user models.User
-	err = r.sqlite.QueryRowContext(ctx, "SELECT id, email, created_at FROM users WHERE id = ?", id).Scan(
-		&user.ID, &user.Email, &user.CreatedAt,
+	err = r.sqlite.QueryRowContext(ctx, "SELECT id, email, role, status, created_at FROM users WHERE id = ?", id).Scan(
+		&user.ID, &user.Email, &user.Role, &user.Status, &user.CreatedAt,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch created user: %w", err)
@@ -79,32 +80,89 @@ func (r *UserRepository) createUserSQLite(ctx context.Context, id, email, passwo
 	return &user, nil
 }
 
-// CreatePasswordResetToken creates a reset token for the user
+// One-time token purposes stored in password_reset_tokens
+const (
+	TokenPurposePasswordReset = "password_reset"
+	TokenPurposeMagicLink     = "magic_link"
+)
+
+// CreatePasswordResetToken creates a reset token for the user, invalidating any
+// earlier reset tokens that have not been used
 func (r *UserRepository) CreatePasswordResetToken(ctx context.Context, userID string, tokenHash string, expiresAt time.Time) error {
-	id := uuid.New().String()
+	var err error
+	if r.useSQLite {
+		_, err = r.sqlite.ExecContext(ctx, `
+			DELETE FROM password_reset_tokens WHERE user_id = ? AND purpose = ? AND used_at IS NULL
+		`, userID, TokenPurposePasswordReset)
+	} else {
+		_, err = r.db.Exec(ctx, `
+			DELETE FROM password_reset_tokens WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL
+		`, userID, TokenPurposePasswordReset)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to invalidate reset tokens: %w", err)
+	}
+	return r.CreateOneTimeToken(ctx, userID, TokenPurposePasswordReset, tokenHash, expiresAt)
+}
+
+// CreateOneTimeToken stores a hashed single-use token (password reset, magic link) for the user
+func (r *UserRepository) CreateOneTimeToken(ctx context.Context, userID, purpose, tokenHash string, expiresAt time.Time) error {
+	id := ids.New()
 	if r.useSQLite {
-		return r.createPasswordResetTokenSQLite(ctx, id, userID, tokenHash, expiresAt)
+		return r.createOneTimeTokenSQLite(ctx, id, userID, purpose, tokenHash, expiresAt)
 	}
-	return r.createPasswordResetTokenPostgres(ctx, id, userID, tokenHash, expiresAt)
+	return r.createOneTimeTokenPostgres(ctx, id, userID, purpose, tokenHash, expiresAt)
 }
 
-func (r *UserRepository) createPasswordResetTokenPostgres(ctx context.Context, id, userID, tokenHash string, expiresAt time.Time) error {
+func (r *UserRepository) createOneTimeTokenPostgres(ctx context.Context, id, userID, purpose, tokenHash string, expiresAt time.Time) error {
 	_, err := r.db.Exec(ctx, `
-		INSERT INTO password_reset_tokens (id, user_id, token_hash, expires_at, created_at)
-		VALUES ($1, $2, $3, $4, NOW())
-	`, id, userID, tokenHash, expiresAt)
+		INSERT INTO password_reset_tokens (id, user_id, purpose, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+	`, id, userID, purpose, tokenHash, expiresAt)
 	return err
 }
 
-func (r *UserRepository) createPasswordResetTokenSQLite(ctx context.Context, id, userID, tokenHash string, expiresAt time.Time) error {
+func (r *UserRepository) createOneTimeTokenSQLite(ctx context.Context, id, userID, purpose, tokenHash string, expiresAt time.Time) error {
 	_, err := r.sqlite.ExecContext(ctx, `
-		INSERT INTO password_reset_tokens (id, user_id, token_hash, expires_at, created_at)
-		VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP)
-	`, id, userID, tokenHash, expiresAt)
+		INSERT INTO password_reset_tokens (id, user_id, purpose, token_hash, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+	`, id, userID, purpose, tokenHash, expiresAt)
 	return err
 }
 
-// GetUserIDByResetToken returns user ID if token is valid and not expired
+// ConsumeOneTimeToken deletes a token and returns its user ID, or "" if the token
+// is unknown, already used, expired or issued for a different purpose
+func (r *UserRepository) ConsumeOneTimeToken(ctx context.Context, purpose, tokenHash string) (string, error) {
+	var userID string
+	var expiresAt time.Time
+	var err error
+	if r.useSQLite {
+		err = r.sqlite.QueryRowContext(ctx, `
+			DELETE FROM password_reset_tokens WHERE token_hash = ? AND purpose = ?
+			RETURNING user_id, expires_at
+		`, tokenHash, purpose).Scan(&userID, &expiresAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil
+		}
+	} else {
+		err = r.db.QueryRow(ctx, `
+			DELETE FROM password_reset_tokens WHERE token_hash = $1 AND purpose = $2
+			RETURNING user_id, expires_at
+		`, tokenHash, purpose).Scan(&userID, &expiresAt)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", nil
+		}
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to consume token: %w", err)
+	}
+	if time.Now().After(expiresAt) {
+		return "", nil
+	}
+	return userID, nil
+}
+
+// GetUserIDByResetToken returns user ID if token is valid, unused and not expired
 func (r *UserRepository) GetUserIDByResetToken(ctx context.Context, tokenHash string) (string, error) {
 	if r.useSQLite {
 		return r.getUserIDByResetTokenSQLite(ctx, tokenHash)
@@ -116,9 +174,9 @@ func (r *UserRepository) getUserIDByResetTokenPostgres(ctx context.Context, toke
 	var userID string
 	err := r.db.QueryRow(ctx, `
 		SELECT user_id FROM password_reset_tokens
-		WHERE token_hash = $1 AND expires_at > NOW()
+		WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW()
 		LIMIT 1
-	`, tokenHash).Scan(&userID)
+	`, tokenHash, TokenPurposePasswordReset).Scan(&userID)
 	if err == sql.ErrNoRows {
 		return "", nil
 	}
@@ -129,33 +187,164 @@ func (r *UserRepository) getUserIDByResetTokenSQLite(ctx context.Context, tokenH
 	var userID string
 	err := r.sqlite.QueryRowContext(ctx, `
 		SELECT user_id FROM password_reset_tokens
-		WHERE token_hash = ? AND expires_at > datetime('now')
+		WHERE token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > datetime('now')
 		LIMIT 1
-	`, tokenHash).Scan(&userID)
+	`, tokenHash, TokenPurposePasswordReset).Scan(&userID)
 	if err == sql.ErrNoRows {
 		return "", nil
 	}
 	return userID, err
 }
 
-// DeletePasswordResetToken removes used/expired tokens for a user
-func (r *UserRepository) DeletePasswordResetToken(ctx context.Context, tokenHash string) error {
+// UsePasswordResetToken marks a reset token as redeemed. It returns false if the token
+// was already used, so only one of several concurrent redemptions goes ahead.
+func (r *UserRepository) UsePasswordResetToken(ctx context.Context, tokenHash string) (bool, error) {
+	var n int64
 	if r.useSQLite {
-		_, err := r.sqlite.ExecContext(ctx, `DELETE FROM password_reset_tokens WHERE token_hash = ?`, tokenHash)
-		return err
+		res, err := r.sqlite.ExecContext(ctx, `
+			UPDATE password_reset_tokens SET used_at = CURRENT_TIMESTAMP
+			WHERE token_hash = ? AND purpose = ? AND used_at IS NULL
+		`, tokenHash, TokenPurposePasswordReset)
+		if err != nil {
+			return false, fmt.Errorf("failed to use reset token: %w", err)
+		}
+		n, err = res.RowsAffected()
+		if err != nil {
+			return false, err
+		}
+	} else {
+		tag, err := r.db.Exec(ctx, `
+			UPDATE password_reset_tokens SET used_at = NOW()
+			WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL
+		`, tokenHash, TokenPurposePasswordReset)
+		if err != nil {
+			return false, fmt.Errorf("failed to use reset token: %w", err)
+		}
+		n = tag.RowsAffected()
 	}
-	_, err := r.db.Exec(ctx, `DELETE FROM password_reset_tokens WHERE token_hash = $1`, tokenHash)
-	return err
+	return n > 0, nil
+}
+
+// PurgeExpiredOneTimeTokens deletes reset and magic-link tokens past their expiry,
+// including redeemed reset tokens. Intended to run from the jobs scheduler.
+func (r *UserRepository) PurgeExpiredOneTimeTokens(ctx context.Context) error {
+	var err error
+	if r.useSQLite {
+		_, err = r.sqlite.ExecContext(ctx, `DELETE FROM password_reset_tokens WHERE expires_at < datetime('now')`)
+	} else {
+		_, err = r.db.Exec(ctx, `DELETE FROM password_reset_tokens WHERE expires_at < NOW()`)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to purge one-time tokens: %w", err)
+	}
+	return nil
 }
 
-// UpdatePassword updates a user's password
+// maxPasswordHistory caps the previous hashes kept per user, whatever PASSWORD_HISTORY is
+const maxPasswordHistory = 24
+
+// UpdatePassword updates a user's password, remembering the previous one for RecentPasswordHashes
 func (r *UserRepository) UpdatePassword(ctx context.Context, userID, passwordHash string) error {
 	if r.useSQLite {
-		_, err := r.sqlite.ExecContext(ctx, `UPDATE users SET password_hash = ? WHERE id = ?`, passwordHash, userID)
+		return r.updatePasswordSQLite(ctx, userID, passwordHash)
+	}
+	return r.updatePasswordPostgres(ctx, userID, passwordHash)
+}
+
+func (r *UserRepository) updatePasswordPostgres(ctx context.Context, userID, passwordHash string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
 		return err
 	}
-	_, err := r.db.Exec(ctx, `UPDATE users SET password_hash = $1 WHERE id = $2`, passwordHash, userID)
-	return err
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO password_history (id, user_id, password_hash, created_at)
+		SELECT $1, id, password_hash, $2 FROM users WHERE id = $3 AND password_hash != ''
+	`, ids.New(), time.Now().Unix(), userID)
+	if err != nil {
+		return fmt.Errorf("failed to record password history: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `UPDATE users SET password_hash = $1 WHERE id = $2`, passwordHash, userID); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+	_, err = tx.Exec(ctx, `
+		DELETE FROM password_history WHERE user_id = $1 AND id NOT IN (
+			SELECT id FROM password_history WHERE user_id = $1 ORDER BY created_at DESC, id LIMIT $2)
+	`, userID, maxPasswordHistory)
+	if err != nil {
+		return fmt.Errorf("failed to prune password history: %w", err)
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *UserRepository) updatePasswordSQLite(ctx context.Context, userID, passwordHash string) error {
+	tx, err := r.sqlite.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO password_history (id, user_id, password_hash, created_at)
+		SELECT ?, id, password_hash, ? FROM users WHERE id = ? AND password_hash != ''
+	`, ids.New(), time.Now().Unix(), userID)
+	if err != nil {
+		return fmt.Errorf("failed to record password history: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE users SET password_hash = ? WHERE id = ?`, passwordHash, userID); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM password_history WHERE user_id = ? AND id NOT IN (
+			SELECT id FROM password_history WHERE user_id = ? ORDER BY created_at DESC, id LIMIT ?)
+	`, userID, userID, maxPasswordHistory)
+	if err != nil {
+		return fmt.Errorf("failed to prune password history: %w", err)
+	}
+	return tx.Commit()
+}
+
+// RehashPassword replaces a user's password hash with a stronger hash of the same password.
+// It is a no-op if the hash has changed since oldHash was read, and leaves password history alone.
+func (r *UserRepository) RehashPassword(ctx context.Context, userID, oldHash, newHash string) error {
+	var err error
+	if r.useSQLite {
+		_, err = r.sqlite.ExecContext(ctx, `UPDATE users SET password_hash = ? WHERE id = ? AND password_hash = ?`, newHash, userID, oldHash)
+	} else {
+		_, err = r.db.Exec(ctx, `UPDATE users SET password_hash = $1 WHERE id = $2 AND password_hash = $3`, newHash, userID, oldHash)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to rehash password: %w", err)
+	}
+	return nil
+}
+
+// RecentPasswordHashes returns the user's current password hash followed by up to n-1
+// previous ones, newest first
+func (r *UserRepository) RecentPasswordHashes(ctx context.Context, userID string, n int) ([]string, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	hashes := []string{}
+	const query = `
+		SELECT password_hash FROM (
+			SELECT COALESCE(password_hash, '') AS password_hash, 1 AS current, 0 AS created_at FROM users WHERE id = $1
+			UNION ALL
+			SELECT password_hash, 0 AS current, created_at FROM password_history WHERE user_id = $2
+		) h ORDER BY current DESC, created_at DESC LIMIT $3`
+	err := eachRow(ctx, r.db, r.sqlite, r.useSQLite, query, []interface{}{userID, userID, n}, func(scan func(...interface{}) error) error {
+		var hash string
+		if err := scan(&hash); err != nil {
+			return err
+		}
+		hashes = append(hashes, hash)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get password history: %w", err)
+	}
+	return hashes, nil
 }
 
 // GenerateSecureToken creates a cryptographically secure random token
@@ -177,14 +366,14 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.
 
 func (r *UserRepository) getByEmailPostgres(ctx context.Context, email string) (*models.User, error) {
 	query := `
-		SELECT id, email, password_hash, created_at
+		SELECT id, email, password_hash, role, status, created_at
 		FROM users
 		WHERE LOWER(email) = LOWER($1)
 	`
 
 	var user models.User
 	err := r.db.QueryRow(ctx, query, email).Scan(
-		&user.ID, &user.Email, &user.PasswordHash, &user.CreatedAt,
+		&user.ID, &user.Email, &user.PasswordHash, &user.Role, &user.Status, &user.CreatedAt,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -198,14 +387,14 @@ func (r *UserRepository) getByEmailPostgres(ctx context.Context, email string) (
 
 func (r *UserRepository) getByEmailSQLite(ctx context.Context, email string) (*models.User, error) {
 	query := `
-		SELECT id, email, password_hash, created_at
+		SELECT id, email, password_hash, role, status, created_at
 		FROM users
 		WHERE LOWER(email) = LOWER(?)
 	`
 
 	var user models.User
 	err := r.sqlite.QueryRowContext(ctx, query, email).Scan(
-		&user.ID, &user.Email, &user.PasswordHash, &user.CreatedAt,
+		&user.ID, &user.Email, &user.PasswordHash, &user.Role, &user.Status, &user.CreatedAt,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -227,13 +416,13 @@ func (r *UserRepository) GetByID(ctx context.Context, id string) (*models.User,
 
 func (r *UserRepository) getByIDPostgres(ctx context.Context, id string) (*models.User, error) {
 	query := `
-		SELECT id, email, created_at
+		SELECT id, email, role, status, created_at
 		FROM users
 		WHERE id = $1
 	`
 
 	var user models.User
-	err := r.db.QueryRow(ctx, query, id).Scan(&user.ID, &user.Email, &user.CreatedAt)
+	err := r.db.QueryRow(ctx, query, id).Scan(&user.ID, &user.Email, &user.Role, &user.Status, &user.CreatedAt)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -246,13 +435,13 @@ func (r *UserRepository) getByIDPostgres(ctx context.Context, id string) (*model
 
 func (r *UserRepository) getByIDSQLite(ctx context.Context, id string) (*models.User, error) {
 	query := `
-		SELECT id, email, created_at
+		SELECT id, email, role, status, created_at
 		FROM users
 		WHERE id = ?
 	`
 
 	var user models.User
-	err := r.sqlite.QueryRowContext(ctx, query, id).Scan(&user.ID, &user.Email, &user.CreatedAt)
+	err := r.sqlite.QueryRowContext(ctx, query, id).Scan(&user.ID, &user.Email, &user.Role, &user.Status, &user.CreatedAt)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -272,7 +461,7 @@ func (r *UserRepository) ListAllUsers(ctx context.Context) ([]*models.User, erro
 }
 
 func (r *UserRepository) listAllUsersPostgres(ctx context.Context) ([]*models.User, error) {
-	rows, err := r.db.Query(ctx, `SELECT id, email, created_at FROM users ORDER BY created_at DESC`)
+	rows, err := r.db.Query(ctx, `SELECT id, email, role, status, created_at FROM users ORDER BY created_at DESC`)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list users: %w", err)
 	}
@@ -280,7 +469,7 @@ func (r *UserRepository) listAllUsersPostgres(ctx context.Context) ([]*models.Us
 	var users []*models.User
 	for rows.Next() {
 		var u models.User
-		if err := rows.Scan(&u.ID, &u.Email, &u.CreatedAt); err != nil {
+		if err := rows.Scan(&u.ID, &u.Email, &u.Role, &u.Status, &u.CreatedAt); err != nil {
 			return nil, err
 		}
 		users = append(users, &u)
@@ -289,7 +478,7 @@ func (r *UserRepository) listAllUsersPostgres(ctx context.Context) ([]*models.Us
 }
 
 func (r *UserRepository) listAllUsersSQLite(ctx context.Context) ([]*models.User, error) {
-	rows, err := r.sqlite.QueryContext(ctx, `SELECT id, email, created_at FROM users ORDER BY created_at DESC`)
+	rows, err := r.sqlite.QueryContext(ctx, `SELECT id, email, role, status, created_at FROM users ORDER BY created_at DESC`)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list users: %w", err)
 	}
@@ -297,7 +486,7 @@ func (r *UserRepository) listAllUsersSQLite(ctx context.Context) ([]*models.User
 	var users []*models.User
 	for rows.Next() {
 		var u models.User
-		if err := rows.Scan(&u.ID, &u.Email, &u.CreatedAt); err != nil {
+		if err := rows.Scan(&u.ID, &u.Email, &u.Role, &u.Status, &u.CreatedAt); err != nil {
 			return nil, err
 		}
 		users = append(users, &u)
@@ -305,3 +494,235 @@ func (r *UserRepository) listAllUsersSQLite(ctx context.Context) ([]*models.User
 	return users, nil
 }
 
+// GetByOAuthIdentity returns the user linked to a provider identity, or nil if none
+func (r *UserRepository) GetByOAuthIdentity(ctx context.Context, provider, subject string) (*models.User, error) {
+	if r.useSQLite {
+		return r.getByOAuthIdentitySQLite(ctx, provider, subject)
+	}
+	return r.getByOAuthIdentityPostgres(ctx, provider, subject)
+}
+
+func (r *UserRepository) getByOAuthIdentityPostgres(ctx context.Context, provider, subject string) (*models.User, error) {
+	query := `
+		SELECT u.id, u.email, u.role, u.status, u.created_at
+		FROM users u
+		JOIN oauth_identities oi ON oi.user_id = u.id
+		WHERE oi.provider = $1 AND oi.subject = $2
+	`
+
+	var user models.User
+	err := r.db.QueryRow(ctx, query, provider, subject).Scan(&user.ID, &user.Email, &user.Role, &user.Status, &user.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user by identity: %w", err)
+	}
+
+	return &user, nil
+}
+
+func (r *UserRepository) getByOAuthIdentitySQLite(ctx context.Context, provider, subject string) (*models.User, error) {
+	query := `
+		SELECT u.id, u.email, u.role, u.status, u.created_at
+		FROM users u
+		JOIN oauth_identities oi ON oi.user_id = u.id
+		WHERE oi.provider = ? AND oi.subject = ?
+	`
+
+	var user models.User
+	err := r.sqlite.QueryRowContext(ctx, query, provider, subject).Scan(&user.ID, &user.Email, &user.Role, &user.Status, &user.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user by identity: %w", err)
+	}
+
+	return &user, nil
+}
+
+// LinkOAuthIdentity links a provider identity to an existing user
+func (r *UserRepository) LinkOAuthIdentity(ctx context.Context, userID, provider, subject, email string) error {
+	id := ids.New()
+	if r.useSQLite {
+		_, err := r.sqlite.ExecContext(ctx, `
+			INSERT INTO oauth_identities (id, user_id, provider, subject, email, created_at)
+			VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+		`, id, userID, provider, subject, email)
+		if err != nil {
+			return fmt.Errorf("failed to link identity: %w", err)
+		}
+		return nil
+	}
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO oauth_identities (id, user_id, provider, subject, email, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+	`, id, userID, provider, subject, email)
+	if err != nil {
+		return fmt.Errorf("failed to link identity: %w", err)
+	}
+	return nil
+}
+
+// ErrEmailTaken is returned when an email change targets an address another account uses
+var ErrEmailTaken = errors.New("email already in use")
+
+// CreatePendingEmailChange records a requested email change awaiting confirmation from
+// the new address. Any earlier pending change for the user is replaced.
+func (r *UserRepository) CreatePendingEmailChange(ctx context.Context, userID, newEmail, tokenHash string, expiresAt time.Time) error {
+	id := ids.New()
+	now := time.Now().Unix()
+	if r.useSQLite {
+		tx, err := r.sqlite.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+		if _, err := tx.ExecContext(ctx, `DELETE FROM pending_email_changes WHERE user_id = ?`, userID); err != nil {
+			return fmt.Errorf("failed to clear pending email change: %w", err)
+		}
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO pending_email_changes (id, user_id, new_email, token_hash, expires_at, created_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, id, userID, newEmail, tokenHash, expiresAt.Unix(), now)
+		if err != nil {
+			return fmt.Errorf("failed to create pending email change: %w", err)
+		}
+		return tx.Commit()
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, `DELETE FROM pending_email_changes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to clear pending email change: %w", err)
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO pending_email_changes (id, user_id, new_email, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, id, userID, newEmail, tokenHash, expiresAt.Unix(), now)
+	if err != nil {
+		return fmt.Errorf("failed to create pending email change: %w", err)
+	}
+	return tx.Commit(ctx)
+}
+
+// ConfirmEmailChange consumes a pending change and moves the user to the new address.
+// It returns the updated user and the previous email, or a nil user if the token is
+// unknown, already used or expired. ErrEmailTaken means the address was claimed by
+// another account after the change was requested.
+func (r *UserRepository) ConfirmEmailChange(ctx context.Context, tokenHash string) (*models.User, string, error) {
+	var user models.User
+	var oldEmail string
+	var expiresAt int64
+	now := time.Now()
+
+	if r.useSQLite {
+		tx, err := r.sqlite.BeginTx(ctx, nil)
+		if err != nil {
+			return nil, "", err
+		}
+		defer tx.Rollback()
+		err = tx.QueryRowContext(ctx, `
+			DELETE FROM pending_email_changes WHERE token_hash = ?
+			RETURNING user_id, new_email, expires_at
+		`, tokenHash).Scan(&user.ID, &user.Email, &expiresAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, "", nil
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to consume email change: %w", err)
+		}
+		if now.Unix() > expiresAt {
+			return nil, "", tx.Commit()
+		}
+		var taken bool
+		err = tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM users WHERE LOWER(email) = LOWER(?) AND id != ?)`,
+			user.Email, user.ID).Scan(&taken)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to check email: %w", err)
+		}
+		if taken {
+			if err := tx.Commit(); err != nil {
+				return nil, "", err
+			}
+			return nil, "", ErrEmailTaken
+		}
+		err = tx.QueryRowContext(ctx, `SELECT email, role, status, created_at FROM users WHERE id = ?`, user.ID).Scan(&oldEmail, &user.Role, &user.Status, &user.CreatedAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, "", nil
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to get user: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE users SET email = ? WHERE id = ?`, user.Email, user.ID); err != nil {
+			return nil, "", fmt.Errorf("failed to update email: %w", err)
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, "", err
+		}
+		return &user, oldEmail, nil
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	defer tx.Rollback(ctx)
+	err = tx.QueryRow(ctx, `
+		DELETE FROM pending_email_changes WHERE token_hash = $1
+		RETURNING user_id, new_email, expires_at
+	`, tokenHash).Scan(&user.ID, &user.Email, &expiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to consume email change: %w", err)
+	}
+	if now.Unix() > expiresAt {
+		return nil, "", tx.Commit(ctx)
+	}
+	var taken bool
+	err = tx.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM users WHERE LOWER(email) = LOWER($1) AND id != $2)`,
+		user.Email, user.ID).Scan(&taken)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to check email: %w", err)
+	}
+	if taken {
+		if err := tx.Commit(ctx); err != nil {
+			return nil, "", err
+		}
+		return nil, "", ErrEmailTaken
+	}
+	err = tx.QueryRow(ctx, `SELECT email, role, status, created_at FROM users WHERE id = $1`, user.ID).Scan(&oldEmail, &user.Role, &user.Status, &user.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get user: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `UPDATE users SET email = $1 WHERE id = $2`, user.Email, user.ID); err != nil {
+		return nil, "", fmt.Errorf("failed to update email: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, "", err
+	}
+	return &user, oldEmail, nil
+}
+
+// PurgeExpiredEmailChanges deletes email changes that were never confirmed
+func (r *UserRepository) PurgeExpiredEmailChanges(ctx context.Context) error {
+	var err error
+	if r.useSQLite {
+		_, err = r.sqlite.ExecContext(ctx, `DELETE FROM pending_email_changes WHERE expires_at < ?`, time.Now().Unix())
+	} else {
+		_, err = r.db.Exec(ctx, `DELETE FROM pending_email_changes WHERE expires_at < $1`, time.Now().Unix())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to purge email changes: %w", err)
+	}
+	return nil
+}
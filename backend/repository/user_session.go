@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrUserSessionNotFound is returned when a session does not exist or belongs to another user
+var ErrUserSessionNotFound = errors.New("session not found or access denied")
+
+// userSessionTouchInterval limits how often last_seen_at is written for a busy session
+const userSessionTouchInterval = time.Minute
+
+// maxSessionDeviceLength caps the stored user agent
+const maxSessionDeviceLength = 255
+
+// UserSessionRepository tracks signed-in devices, one row per token (keyed by jti).
+// Times are unix seconds.
+type UserSessionRepository struct {
+	db        Pool
+	sqlite    *sql.DB
+	useSQLite bool
+}
+
+// NewUserSessionRepository creates a new user session repository
+func NewUserSessionRepository(db Pool, sqlite *sql.DB, useSQLite bool) *UserSessionRepository {
+	if useSQLite {
+		return &UserSessionRepository{db: nil, sqlite: sqlite, useSQLite: true}
+	}
+	return &UserSessionRepository{db: db, sqlite: nil, useSQLite: false}
+}
+
+// TrackSession implements auth.SessionTracker. The first request with a token records
+// its device; later ones refresh the IP and last-seen time at most once a minute.
+func (r *UserSessionRepository) TrackSession(ctx context.Context, claims *auth.Claims, device, ip string) error {
+	if len(device) > maxSessionDeviceLength {
+		device = device[:maxSessionDeviceLength]
+	}
+	now := time.Now().Unix()
+	issuedAt, expiresAt := now, now
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Unix()
+	}
+	if claims.ExpiresAt != nil {
+		expiresAt = claims.ExpiresAt.Unix()
+	}
+	stale := now - int64(userSessionTouchInterval.Seconds())
+
+	// The user's last_active_at, read by the retention policy, moves with last_seen_at
+	if r.useSQLite {
+		result, err := r.sqlite.ExecContext(ctx, `
+			INSERT INTO user_sessions (id, user_id, device, ip, issued_at, expires_at, created_at, last_seen_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (id) DO UPDATE SET ip = excluded.ip, last_seen_at = excluded.last_seen_at
+			WHERE user_sessions.last_seen_at < ?`,
+			claims.ID, claims.UserID, device, ip, issuedAt, expiresAt, now, now, stale)
+		if err != nil {
+			return fmt.Errorf("failed to track session: %w", err)
+		}
+		if n, _ := result.RowsAffected(); n > 0 {
+			_, err = r.sqlite.ExecContext(ctx, `UPDATE users SET last_active_at = ? WHERE id = ?`, now, claims.UserID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to record activity: %w", err)
+		}
+		return nil
+	}
+
+	tag, err := r.db.Exec(ctx, `
+		INSERT INTO user_sessions (id, user_id, device, ip, issued_at, expires_at, created_at, last_seen_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+		ON CONFLICT (id) DO UPDATE SET ip = EXCLUDED.ip, last_seen_at = EXCLUDED.last_seen_at
+		WHERE user_sessions.last_seen_at < $8`,
+		claims.ID, claims.UserID, device, ip, issuedAt, expiresAt, now, stale)
+	if err != nil {
+		return fmt.Errorf("failed to track session: %w", err)
+	}
+	if tag.RowsAffected() > 0 {
+		if _, err := r.db.Exec(ctx, `UPDATE users SET last_active_at = $1 WHERE id = $2`, now, claims.UserID); err != nil {
+			return fmt.Errorf("failed to record activity: %w", err)
+		}
+	}
+	return nil
+}
+
+// userSessionColumns are the columns scanned by scanUserSession
+const userSessionColumns = `s.id, s.device, s.ip, s.created_at, s.last_seen_at, s.expires_at`
+
+func scanUserSession(scan func(...interface{}) error) (*models.UserSession, error) {
+	var s models.UserSession
+	var createdAt, lastSeenAt, expiresAt int64
+	if err := scan(&s.ID, &s.Device, &s.IP, &createdAt, &lastSeenAt, &expiresAt); err != nil {
+		return nil, err
+	}
+	s.CreatedAt, s.LastSeenAt, s.ExpiresAt = time.Unix(createdAt, 0), time.Unix(lastSeenAt, 0), time.Unix(expiresAt, 0)
+	return &s, nil
+}
+
+// ListSessions returns the user's signed-in devices, most recently seen first.
+// Expired tokens and tokens revoked individually or by logout-all are left out.
+func (r *UserSessionRepository) ListSessions(ctx context.Context, userID string) ([]*models.UserSession, error) {
+	sessions := []*models.UserSession{}
+	const query = `
+		SELECT ` + userSessionColumns + `
+		FROM user_sessions s
+		WHERE s.user_id = %s AND s.expires_at > %s
+		  AND NOT EXISTS (SELECT 1 FROM revoked_tokens r WHERE r.jti = s.id)
+		  AND NOT EXISTS (SELECT 1 FROM user_token_cutoffs c WHERE c.user_id = s.user_id AND c.revoked_before > s.issued_at)
+		ORDER BY s.last_seen_at DESC, s.id`
+	now := time.Now().Unix()
+
+	if r.useSQLite {
+		rows, err := r.sqlite.QueryContext(ctx, fmt.Sprintf(query, "?", "?"), userID, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list sessions: %w", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			s, err := scanUserSession(rows.Scan)
+			if err != nil {
+				return nil, fmt.Errorf("failed to scan session: %w", err)
+			}
+			sessions = append(sessions, s)
+		}
+		return sessions, rows.Err()
+	}
+
+	rows, err := r.db.Query(ctx, fmt.Sprintf(query, "$1", "$2"), userID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		s, err := scanUserSession(rows.Scan)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, rows.Err()
+}
+
+// GetSession returns one of the user's sessions, revoked or not
+func (r *UserSessionRepository) GetSession(ctx context.Context, userID, id string) (*models.UserSession, error) {
+	const query = `SELECT ` + userSessionColumns + ` FROM user_sessions s WHERE s.id = %s AND s.user_id = %s`
+	var s *models.UserSession
+	var err error
+	if r.useSQLite {
+		s, err = scanUserSession(r.sqlite.QueryRowContext(ctx, fmt.Sprintf(query, "?", "?"), id, userID).Scan)
+	} else {
+		s, err = scanUserSession(r.db.QueryRow(ctx, fmt.Sprintf(query, "$1", "$2"), id, userID).Scan)
+	}
+	if errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrUserSessionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	return s, nil
+}
+
+// PurgeExpired drops sessions whose tokens have expired.
+// Intended to run from the jobs scheduler.
+func (r *UserSessionRepository) PurgeExpired(ctx context.Context) error {
+	var err error
+	if r.useSQLite {
+		_, err = r.sqlite.ExecContext(ctx, `DELETE FROM user_sessions WHERE expires_at < ?`, time.Now().Unix())
+	} else {
+		_, err = r.db.Exec(ctx, `DELETE FROM user_sessions WHERE expires_at < $1`, time.Now().Unix())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to purge sessions: %w", err)
+	}
+	return nil
+}
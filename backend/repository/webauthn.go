@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"liftoff/backend/ids"
+	"liftoff/backend/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	// ErrChallengeNotFound is returned when a ceremony challenge is unknown, used or expired
+	ErrChallengeNotFound = errors.New("challenge not found or expired")
+	// ErrCredentialNotFound is returned when a passkey does not exist or belongs to another user
+	ErrCredentialNotFound = errors.New("credential not found")
+)
+
+// WebAuthnRepository stores passkey credentials and ceremony challenges
+type WebAuthnRepository struct {
+	db        Pool
+	sqlite    *sql.DB
+	useSQLite bool
+}
+
+// NewWebAuthnRepository creates a new WebAuthn repository
+func NewWebAuthnRepository(db Pool, sqlite *sql.DB, useSQLite bool) *WebAuthnRepository {
+	if useSQLite {
+		return &WebAuthnRepository{db: nil, sqlite: sqlite, useSQLite: true}
+	}
+	return &WebAuthnRepository{db: db, sqlite: nil, useSQLite: false}
+}
+
+// CreateChallenge stores a single-use challenge and returns its ID.
+// Expired challenges are purged opportunistically.
+func (r *WebAuthnRepository) CreateChallenge(ctx context.Context, userID, kind, challenge string, ttl time.Duration) (string, error) {
+	id := ids.New()
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+
+	var err error
+	if r.useSQLite {
+		_, _ = r.sqlite.ExecContext(ctx, `DELETE FROM webauthn_challenges WHERE expires_at < ?`, now)
+		_, err = r.sqlite.ExecContext(ctx,
+			`INSERT INTO webauthn_challenges (id, user_id, kind, challenge, expires_at) VALUES (?, ?, ?, ?, ?)`,
+			id, userID, kind, challenge, expiresAt)
+	} else {
+		_, _ = r.db.Exec(ctx, `DELETE FROM webauthn_challenges WHERE expires_at < $1`, now)
+		_, err = r.db.Exec(ctx,
+			`INSERT INTO webauthn_challenges (id, user_id, kind, challenge, expires_at) VALUES ($1, $2, $3, $4, $5)`,
+			id, userID, kind, challenge, expiresAt)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to create challenge: %w", err)
+	}
+	return id, nil
+}
+
+// ConsumeChallenge deletes and returns an unexpired challenge of the given kind
+func (r *WebAuthnRepository) ConsumeChallenge(ctx context.Context, id, kind string) (*models.WebAuthnChallenge, error) {
+	var ch models.WebAuthnChallenge
+	var err error
+	if r.useSQLite {
+		err = r.sqlite.QueryRowContext(ctx,
+			`DELETE FROM webauthn_challenges WHERE id = ? AND kind = ? RETURNING id, user_id, kind, challenge, expires_at`,
+			id, kind).Scan(&ch.ID, &ch.UserID, &ch.Kind, &ch.Challenge, &ch.ExpiresAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrChallengeNotFound
+		}
+	} else {
+		err = r.db.QueryRow(ctx,
+			`DELETE FROM webauthn_challenges WHERE id = $1 AND kind = $2 RETURNING id, user_id, kind, challenge, expires_at`,
+			id, kind).Scan(&ch.ID, &ch.UserID, &ch.Kind, &ch.Challenge, &ch.ExpiresAt)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrChallengeNotFound
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume challenge: %w", err)
+	}
+	if time.Now().After(ch.ExpiresAt) {
+		return nil, ErrChallengeNotFound
+	}
+	return &ch, nil
+}
+
+// AddCredential stores a newly registered passkey
+func (r *WebAuthnRepository) AddCredential(ctx context.Context, cred *models.WebAuthnCredential) error {
+	cred.CreatedAt = time.Now()
+	var err error
+	if r.useSQLite {
+		_, err = r.sqlite.ExecContext(ctx, `
+			INSERT INTO webauthn_credentials (id, user_id, name, public_key, algorithm, sign_count, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			cred.ID, cred.UserID, cred.Name, cred.PublicKey, cred.Algorithm, int64(cred.SignCount), cred.CreatedAt)
+	} else {
+		_, err = r.db.Exec(ctx, `
+			INSERT INTO webauthn_credentials (id, user_id, name, public_key, algorithm, sign_count, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			cred.ID, cred.UserID, cred.Name, cred.PublicKey, cred.Algorithm, int64(cred.SignCount), cred.CreatedAt)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to add credential: %w", err)
+	}
+	return nil
+}
+
+// GetCredential returns a passkey by credential ID, or nil if unknown
+func (r *WebAuthnRepository) GetCredential(ctx context.Context, id string) (*models.WebAuthnCredential, error) {
+	query := `SELECT id, user_id, name, public_key, algorithm, sign_count, created_at, last_used_at
+		FROM webauthn_credentials WHERE id = `
+	var cred *models.WebAuthnCredential
+	var err error
+	if r.useSQLite {
+		cred, err = scanCredential(r.sqlite.QueryRowContext(ctx, query+"?", id).Scan)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+	} else {
+		cred, err = scanCredential(r.db.QueryRow(ctx, query+"$1", id).Scan)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credential: %w", err)
+	}
+	return cred, nil
+}
+
+// GetCredentialsByUser lists a user's passkeys, newest first
+func (r *WebAuthnRepository) GetCredentialsByUser(ctx context.Context, userID string) ([]*models.WebAuthnCredential, error) {
+	query := `SELECT id, user_id, name, public_key, algorithm, sign_count, created_at, last_used_at
+		FROM webauthn_credentials WHERE user_id = %s ORDER BY created_at DESC`
+
+	var creds []*models.WebAuthnCredential
+	if r.useSQLite {
+		rows, err := r.sqlite.QueryContext(ctx, fmt.Sprintf(query, "?"), userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list credentials: %w", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			cred, err := scanCredential(rows.Scan)
+			if err != nil {
+				return nil, fmt.Errorf("failed to scan credential: %w", err)
+			}
+			creds = append(creds, cred)
+		}
+		return creds, rows.Err()
+	}
+
+	rows, err := r.db.Query(ctx, fmt.Sprintf(query, "$1"), userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list credentials: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		cred, err := scanCredential(rows.Scan)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan credential: %w", err)
+		}
+		creds = append(creds, cred)
+	}
+	return creds, rows.Err()
+}
+
+func scanCredential(scan func(...interface{}) error) (*models.WebAuthnCredential, error) {
+	var cred models.WebAuthnCredential
+	var signCount int64
+	if err := scan(&cred.ID, &cred.UserID, &cred.Name, &cred.PublicKey, &cred.Algorithm,
+		&signCount, &cred.CreatedAt, &cred.LastUsedAt); err != nil {
+		return nil, err
+	}
+	cred.SignCount = uint32(signCount)
+	return &cred, nil
+}
+
+// MarkCredentialUsed records a successful assertion and the authenticator's new counter
+func (r *WebAuthnRepository) MarkCredentialUsed(ctx context.Context, id string, signCount uint32) error {
+	var err error
+	if r.useSQLite {
+		_, err = r.sqlite.ExecContext(ctx,
+			`UPDATE webauthn_credentials SET sign_count = ?, last_used_at = ? WHERE id = ?`,
+			int64(signCount), time.Now(), id)
+	} else {
+		_, err = r.db.Exec(ctx,
+			`UPDATE webauthn_credentials SET sign_count = $1, last_used_at = $2 WHERE id = $3`,
+			int64(signCount), time.Now(), id)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update credential: %w", err)
+	}
+	return nil
+}
+
+// DeleteCredential removes one of the user's passkeys
+func (r *WebAuthnRepository) DeleteCredential(ctx context.Context, userID, id string) error {
+	var affected int64
+	if r.useSQLite {
+		result, err := r.sqlite.ExecContext(ctx, `DELETE FROM webauthn_credentials WHERE id = ? AND user_id = ?`, id, userID)
+		if err != nil {
+			return fmt.Errorf("failed to delete credential: %w", err)
+		}
+		affected, _ = result.RowsAffected()
+	} else {
+		tag, err := r.db.Exec(ctx, `DELETE FROM webauthn_credentials WHERE id = $1 AND user_id = $2`, id, userID)
+		if err != nil {
+			return fmt.Errorf("failed to delete credential: %w", err)
+		}
+		affected = tag.RowsAffected()
+	}
+	if affected == 0 {
+		return ErrCredentialNotFound
+	}
+	return nil
+}
@@ -3,13 +3,16 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"liftoff/backend/analytics"
+	"liftoff/backend/ids"
 	"liftoff/backend/models"
 
-	"github.com/google/uuid"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5"
 )
 
 /**
@@ -27,11 +30,73 @@ import (
  * - Proper error handling and logging
  */
 
+// ErrWorkoutNotFound is returned when a workout does not exist or belongs to another user
+var ErrWorkoutNotFound = errors.New("workout not found or access denied")
+
+// ErrInvalidExerciseOrder is returned when a reorder does not list each of the workout's exercises exactly once
+var ErrInvalidExerciseOrder = errors.New("exercise order must list every exercise in the workout exactly once")
+
+// ErrWorkoutTemplateNotFound is returned when no workout template has the requested ID
+var ErrWorkoutTemplateNotFound = errors.New("template not found")
+
+// ErrExerciseNotFound is returned when an exercise does not exist or belongs to another user
+var ErrExerciseNotFound = errors.New("exercise not found or access denied")
+
+// Sort orders for GetWorkouts
+const (
+	WorkoutSortCreatedAt = "created_at" // newest first by default
+	WorkoutSortName      = "name"       // A to Z by default
+)
+
+// WorkoutFilter narrows, orders and pages GetWorkouts. Empty Tag and Type match
+// every workout, and a zero Limit returns every match.
+type WorkoutFilter struct {
+	IncludeArchived bool
+	Tag             string // normalized, see models.NormalizeTag
+	Type            string
+	Sort            string // WorkoutSortCreatedAt (default) or WorkoutSortName
+	Descending      *bool  // nil uses the sort's natural direction
+	Limit           int
+	Offset          int
+}
+
+// orderAndPage returns the ORDER BY and LIMIT/OFFSET clauses for the filter. Ties
+// are broken by ID so pages never overlap.
+func (f WorkoutFilter) orderAndPage() string {
+	column, desc := "created_at", true
+	if f.Sort == WorkoutSortName {
+		column, desc = "LOWER(name)", false
+	}
+	if f.Descending != nil {
+		desc = *f.Descending
+	}
+	direction := "ASC"
+	if desc {
+		direction = "DESC"
+	}
+	clause := fmt.Sprintf("ORDER BY %s %s, id %s", column, direction, direction)
+	if f.Limit > 0 {
+		clause += fmt.Sprintf(" LIMIT %d OFFSET %d", f.Limit, f.Offset)
+	}
+	return clause
+}
+
+// Conditions shared by listing and counting workouts; arguments are
+// (userID, includeArchived, tag, type), each tag and type bound twice on SQLite
+const (
+	workoutFilterPostgres = `user_id = $1 AND ($2 OR archived_at IS NULL)
+			AND ($3 = '' OR id IN (SELECT workout_id FROM workout_tags WHERE tag = $3))
+			AND ($4 = '' OR type = $4)`
+	workoutFilterSQLite = `user_id = ? AND (? OR archived_at IS NULL)
+			AND (? = '' OR id IN (SELECT workout_id FROM workout_tags WHERE tag = ?))
+			AND (? = '' OR type = ?)`
+)
+
 // WorkoutRepository manages workout-related database operations
 type WorkoutRepository struct {
-	db        *pgxpool.Pool // PostgreSQL connection pool
-	sqlite    *sql.DB       // SQLite database connection
-	useSQLite bool          // Flag indicating which database to use
+	db        Pool    // PostgreSQL connection pool
+	sqlite    *sql.DB // SQLite database connection
+	useSQLite bool    // Flag indicating which database to use
 }
 
 /**
@@ -45,7 +110,7 @@ type WorkoutRepository struct {
  * Returns:
  * - *WorkoutRepository: Configured repository instance
  */
-func NewWorkoutRepository(db *pgxpool.Pool, sqlite *sql.DB, useSQLite bool) *WorkoutRepository {
+func NewWorkoutRepository(db Pool, sqlite *sql.DB, useSQLite bool) *WorkoutRepository {
 	if useSQLite {
 		return &WorkoutRepository{db: nil, sqlite: sqlite, useSQLite: true}
 	}
@@ -61,19 +126,21 @@ func NewWorkoutRepository(db *pgxpool.Pool, sqlite *sql.DB, useSQLite bool) *Wor
  * Args:
  * - ctx: Context for the operation
  * - name: Name of the workout to create
+ * - workoutType: One of the models.WorkoutType constants, or empty
+ * - notes: Freeform notes, may be empty
  *
  * Returns:
  * - *models.Workout: Created workout with generated ID and timestamps
  * - error: Creation error if any
  */
-func (r *WorkoutRepository) CreateWorkout(ctx context.Context, userID, name string) (*models.Workout, error) {
-	id := uuid.New().String()
+func (r *WorkoutRepository) CreateWorkout(ctx context.Context, userID, name, workoutType, notes string) (*models.Workout, error) {
+	id := ids.New()
 	now := time.Now()
 
 	if r.useSQLite {
-		return r.createWorkoutSQLite(ctx, id, userID, name, now)
+		return r.createWorkoutSQLite(ctx, id, userID, name, workoutType, notes, now)
 	}
-	return r.createWorkoutPostgres(ctx, id, userID, name, now)
+	return r.createWorkoutPostgres(ctx, id, userID, name, workoutType, notes, now)
 }
 
 /**
@@ -92,16 +159,16 @@ func (r *WorkoutRepository) CreateWorkout(ctx context.Context, userID, name stri
  * - *models.Workout: Created workout with all fields
  * - error: Database error if any
  */
-func (r *WorkoutRepository) createWorkoutPostgres(ctx context.Context, id, userID, name string, now time.Time) (*models.Workout, error) {
+func (r *WorkoutRepository) createWorkoutPostgres(ctx context.Context, id, userID, name, workoutType, notes string, now time.Time) (*models.Workout, error) {
 	query := `
-		INSERT INTO workouts (id, user_id, name, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id, user_id, name, created_at, updated_at
+		INSERT INTO workouts (id, user_id, name, type, notes, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, user_id, name, type, notes, target_duration_minutes, archived_at, created_at, updated_at
 	`
 
 	var workout models.Workout
-	err := r.db.QueryRow(ctx, query, id, userID, name, now, now).Scan(
-		&workout.ID, &workout.UserID, &workout.Name, &workout.CreatedAt, &workout.UpdatedAt,
+	err := r.db.QueryRow(ctx, query, id, userID, name, workoutType, notes, now, now).Scan(
+		&workout.ID, &workout.UserID, &workout.Name, &workout.Type, &workout.Notes, &workout.TargetDurationMinutes, &workout.ArchivedAt, &workout.CreatedAt, &workout.UpdatedAt,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create workout: %w", err)
@@ -126,13 +193,13 @@ func (r *WorkoutRepository) createWorkoutPostgres(ctx context.Context, id, userI
  * - *models.Workout: Created workout with all fields
  * - error: Database error if any
  */
-func (r *WorkoutRepository) createWorkoutSQLite(ctx context.Context, id, userID, name string, now time.Time) (*models.Workout, error) {
+func (r *WorkoutRepository) createWorkoutSQLite(ctx context.Context, id, userID, name, workoutType, notes string, now time.Time) (*models.Workout, error) {
 	query := `
-		INSERT INTO workouts (id, user_id, name, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?)
+		INSERT INTO workouts (id, user_id, name, type, notes, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
 	`
 
-	_, err := r.sqlite.ExecContext(ctx, query, id, userID, name, now, now)
+	_, err := r.sqlite.ExecContext(ctx, query, id, userID, name, workoutType, notes, now, now)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create workout: %w", err)
 	}
@@ -141,6 +208,8 @@ func (r *WorkoutRepository) createWorkoutSQLite(ctx context.Context, id, userID,
 		ID:        id,
 		UserID:    userID,
 		Name:      name,
+		Type:      workoutType,
+		Notes:     notes,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}, nil
@@ -150,27 +219,55 @@ func (r *WorkoutRepository) createWorkoutSQLite(ctx context.Context, id, userID,
  * GetWorkouts retrieves all workouts from the database
  *
  * Delegates to the appropriate database implementation and returns
- * workouts ordered by creation date (newest first).
+ * workouts ordered and paged as the filter asks (newest first by default).
  *
  * Args:
  * - ctx: Context for the operation
+ * - filter: Which workouts to return; the zero value lists all unarchived ones
  *
  * Returns:
  * - []*models.Workout: List of all workouts
  * - error: Database error if any
  */
-func (r *WorkoutRepository) GetWorkouts(ctx context.Context, userID string) ([]*models.Workout, error) {
+func (r *WorkoutRepository) GetWorkouts(ctx context.Context, userID string, filter WorkoutFilter) ([]*models.Workout, error) {
+	var workouts []*models.Workout
+	var err error
 	if r.useSQLite {
-		return r.getWorkoutsSQLite(ctx, userID)
+		workouts, err = r.getWorkoutsSQLite(ctx, userID, filter)
+	} else {
+		workouts, err = r.getWorkoutsPostgres(ctx, userID, filter)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := r.attachWorkoutTags(ctx, userID, workouts); err != nil {
+		return nil, err
 	}
-	return r.getWorkoutsPostgres(ctx, userID)
+	return workouts, nil
+}
+
+// CountWorkouts counts the workouts matching filter, ignoring its sort and paging
+func (r *WorkoutRepository) CountWorkouts(ctx context.Context, userID string, filter WorkoutFilter) (int, error) {
+	var total int
+	var err error
+	if r.useSQLite {
+		err = r.sqlite.QueryRowContext(ctx, `SELECT COUNT(*) FROM workouts WHERE `+workoutFilterSQLite,
+			userID, filter.IncludeArchived, filter.Tag, filter.Tag, filter.Type, filter.Type).Scan(&total)
+	} else {
+		err = r.db.QueryRow(ctx, `SELECT COUNT(*) FROM workouts WHERE `+workoutFilterPostgres,
+			userID, filter.IncludeArchived, filter.Tag, filter.Type).Scan(&total)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to count workouts: %w", err)
+	}
+	return total, nil
 }
 
 /**
  * getWorkoutsPostgres retrieves workouts from PostgreSQL database
  *
  * Uses parameterized queries and proper row scanning with error handling.
- * Returns workouts ordered by creation date descending.
+ * Returns workouts in the filter's order.
  *
  * Args:
  * - ctx: Context for the operation
@@ -179,15 +276,14 @@ func (r *WorkoutRepository) GetWorkouts(ctx context.Context, userID string) ([]*
  * - []*models.Workout: List of workouts from PostgreSQL
  * - error: Database error if any
  */
-func (r *WorkoutRepository) getWorkoutsPostgres(ctx context.Context, userID string) ([]*models.Workout, error) {
+func (r *WorkoutRepository) getWorkoutsPostgres(ctx context.Context, userID string, filter WorkoutFilter) ([]*models.Workout, error) {
 	query := `
-		SELECT id, user_id, name, created_at, updated_at
+		SELECT id, user_id, name, type, notes, target_duration_minutes, archived_at, created_at, updated_at
 		FROM workouts
-		WHERE user_id = $1
-		ORDER BY created_at DESC
-	`
+		WHERE ` + workoutFilterPostgres + `
+		` + filter.orderAndPage()
 
-	rows, err := r.db.Query(ctx, query, userID)
+	rows, err := r.db.Query(ctx, query, userID, filter.IncludeArchived, filter.Tag, filter.Type)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get workouts: %w", err)
 	}
@@ -196,7 +292,7 @@ func (r *WorkoutRepository) getWorkoutsPostgres(ctx context.Context, userID stri
 	var workouts []*models.Workout
 	for rows.Next() {
 		var workout models.Workout
-		err := rows.Scan(&workout.ID, &workout.UserID, &workout.Name, &workout.CreatedAt, &workout.UpdatedAt)
+		err := rows.Scan(&workout.ID, &workout.UserID, &workout.Name, &workout.Type, &workout.Notes, &workout.TargetDurationMinutes, &workout.ArchivedAt, &workout.CreatedAt, &workout.UpdatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan workout: %w", err)
 		}
@@ -210,7 +306,7 @@ func (r *WorkoutRepository) getWorkoutsPostgres(ctx context.Context, userID stri
  * getWorkoutsSQLite retrieves workouts from SQLite database
  *
  * Uses SQLite-specific parameter syntax (?) and proper row scanning with error handling.
- * Returns workouts ordered by creation date descending.
+ * Returns workouts in the filter's order.
  *
  * Args:
  * - ctx: Context for the operation
@@ -219,15 +315,14 @@ func (r *WorkoutRepository) getWorkoutsPostgres(ctx context.Context, userID stri
  * - []*models.Workout: List of workouts from SQLite
  * - error: Database error if any
  */
-func (r *WorkoutRepository) getWorkoutsSQLite(ctx context.Context, userID string) ([]*models.Workout, error) {
+func (r *WorkoutRepository) getWorkoutsSQLite(ctx context.Context, userID string, filter WorkoutFilter) ([]*models.Workout, error) {
 	query := `
-		SELECT id, user_id, name, created_at, updated_at
+		SELECT id, user_id, name, type, notes, target_duration_minutes, archived_at, created_at, updated_at
 		FROM workouts
-		WHERE user_id = ?
-		ORDER BY created_at DESC
-	`
+		WHERE ` + workoutFilterSQLite + `
+		` + filter.orderAndPage()
 
-	rows, err := r.sqlite.QueryContext(ctx, query, userID)
+	rows, err := r.sqlite.QueryContext(ctx, query, userID, filter.IncludeArchived, filter.Tag, filter.Tag, filter.Type, filter.Type)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get workouts: %w", err)
 	}
@@ -236,7 +331,7 @@ func (r *WorkoutRepository) getWorkoutsSQLite(ctx context.Context, userID string
 	var workouts []*models.Workout
 	for rows.Next() {
 		var workout models.Workout
-		err := rows.Scan(&workout.ID, &workout.UserID, &workout.Name, &workout.CreatedAt, &workout.UpdatedAt)
+		err := rows.Scan(&workout.ID, &workout.UserID, &workout.Name, &workout.Type, &workout.Notes, &workout.TargetDurationMinutes, &workout.ArchivedAt, &workout.CreatedAt, &workout.UpdatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan workout: %w", err)
 		}
@@ -286,6 +381,10 @@ func (r *WorkoutRepository) GetWorkout(ctx context.Context, userID, id string) (
 	}
 
 	workout.Exercises = exercises
+
+	if workout.Tags, err = r.getWorkoutTags(ctx, id); err != nil {
+		return nil, err
+	}
 	return workout, nil
 }
 
@@ -304,14 +403,14 @@ func (r *WorkoutRepository) GetWorkout(ctx context.Context, userID, id string) (
  */
 func (r *WorkoutRepository) getWorkoutPostgres(ctx context.Context, userID, id string) (*models.Workout, error) {
 	query := `
-		SELECT id, user_id, name, created_at, updated_at
+		SELECT id, user_id, name, type, notes, target_duration_minutes, archived_at, created_at, updated_at
 		FROM workouts
 		WHERE id = $1 AND user_id = $2
 	`
 
 	var workout models.Workout
 	err := r.db.QueryRow(ctx, query, id, userID).Scan(
-		&workout.ID, &workout.UserID, &workout.Name, &workout.CreatedAt, &workout.UpdatedAt,
+		&workout.ID, &workout.UserID, &workout.Name, &workout.Type, &workout.Notes, &workout.TargetDurationMinutes, &workout.ArchivedAt, &workout.CreatedAt, &workout.UpdatedAt,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get workout: %w", err)
@@ -335,14 +434,14 @@ func (r *WorkoutRepository) getWorkoutPostgres(ctx context.Context, userID, id s
  */
 func (r *WorkoutRepository) getWorkoutSQLite(ctx context.Context, userID, id string) (*models.Workout, error) {
 	query := `
-		SELECT id, user_id, name, created_at, updated_at
+		SELECT id, user_id, name, type, notes, target_duration_minutes, archived_at, created_at, updated_at
 		FROM workouts
 		WHERE id = ? AND user_id = ?
 	`
 
 	var workout models.Workout
 	err := r.sqlite.QueryRowContext(ctx, query, id, userID).Scan(
-		&workout.ID, &workout.UserID, &workout.Name, &workout.CreatedAt, &workout.UpdatedAt,
+		&workout.ID, &workout.UserID, &workout.Name, &workout.Type, &workout.Notes, &workout.TargetDurationMinutes, &workout.ArchivedAt, &workout.CreatedAt, &workout.UpdatedAt,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get workout: %w", err)
@@ -352,36 +451,46 @@ func (r *WorkoutRepository) getWorkoutSQLite(ctx context.Context, userID, id str
 }
 
 /**
- * UpdateWorkout updates an existing workout in the database
+ * UpdateWorkout renames, recategorizes and/or re-notes a workout
  *
- * Uses parameterized query with error handling and returns the updated workout.
+ * Fields left nil keep their current value, so name, type and notes can be
+ * changed separately or together.
  *
  * Args:
  * - ctx: Context for the operation
+ * - userID: Owner of the workout
  * - id: ID of the workout to update
- * - name: New name for the workout
+ * - name: New name, or nil
+ * - workoutType: New type, or nil
+ * - notes: New notes, or nil
  *
  * Returns:
- * - *models.Workout: Updated workout
- * - error: Database error if any
+ * - error: ErrWorkoutNotFound if the user has no such workout, or a database error
  */
-func (r *WorkoutRepository) UpdateWorkout(ctx context.Context, id, name string) (*models.Workout, error) {
-	query := `
-		UPDATE workouts
-		SET name = $2, updated_at = $3
-		WHERE id = $1
-		RETURNING id, name, created_at, updated_at
-	`
-
-	var workout models.Workout
-	err := r.db.QueryRow(ctx, query, id, name, time.Now()).Scan(
-		&workout.ID, &workout.Name, &workout.CreatedAt, &workout.UpdatedAt,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to update workout: %w", err)
+func (r *WorkoutRepository) UpdateWorkout(ctx context.Context, userID, id string, name, workoutType, notes *string) error {
+	var affected int64
+	now := time.Now()
+	if r.useSQLite {
+		result, err := r.sqlite.ExecContext(ctx,
+			`UPDATE workouts SET name = COALESCE(?, name), type = COALESCE(?, type), notes = COALESCE(?, notes), updated_at = ? WHERE id = ? AND user_id = ?`,
+			name, workoutType, notes, now, id, userID)
+		if err != nil {
+			return fmt.Errorf("failed to update workout: %w", err)
+		}
+		affected, _ = result.RowsAffected()
+	} else {
+		tag, err := r.db.Exec(ctx,
+			`UPDATE workouts SET name = COALESCE($1, name), type = COALESCE($2, type), notes = COALESCE($3, notes), updated_at = $4 WHERE id = $5 AND user_id = $6`,
+			name, workoutType, notes, now, id, userID)
+		if err != nil {
+			return fmt.Errorf("failed to update workout: %w", err)
+		}
+		affected = tag.RowsAffected()
 	}
-
-	return &workout, nil
+	if affected == 0 {
+		return ErrWorkoutNotFound
+	}
+	return nil
 }
 
 /**
@@ -445,6 +554,138 @@ func (r *WorkoutRepository) deleteWorkoutSQLite(ctx context.Context, userID, id
 	return nil
 }
 
+/**
+ * SetWorkoutTargetDuration sets or clears the session duration goal of a workout
+ *
+ * Args:
+ * - ctx: Context for the operation
+ * - userID: Owner of the workout
+ * - id: ID of the workout to update
+ * - minutes: Target duration in minutes, or nil to clear it
+ *
+ * Returns:
+ * - error: ErrWorkoutNotFound if the user has no such workout, or a database error
+ */
+func (r *WorkoutRepository) SetWorkoutTargetDuration(ctx context.Context, userID, id string, minutes *int) error {
+	var affected int64
+	now := time.Now()
+	if r.useSQLite {
+		result, err := r.sqlite.ExecContext(ctx,
+			`UPDATE workouts SET target_duration_minutes = ?, updated_at = ? WHERE id = ? AND user_id = ?`,
+			minutes, now, id, userID)
+		if err != nil {
+			return fmt.Errorf("failed to set workout target duration: %w", err)
+		}
+		affected, _ = result.RowsAffected()
+	} else {
+		tag, err := r.db.Exec(ctx,
+			`UPDATE workouts SET target_duration_minutes = $1, updated_at = $2 WHERE id = $3 AND user_id = $4`,
+			minutes, now, id, userID)
+		if err != nil {
+			return fmt.Errorf("failed to set workout target duration: %w", err)
+		}
+		affected = tag.RowsAffected()
+	}
+	if affected == 0 {
+		return ErrWorkoutNotFound
+	}
+	return nil
+}
+
+// SetWorkoutArchived archives or restores a workout. Archiving keeps the original
+// archive time if the workout is already archived.
+func (r *WorkoutRepository) SetWorkoutArchived(ctx context.Context, userID, id string, archived bool) error {
+	var archivedAt *time.Time
+	if archived {
+		now := time.Now()
+		archivedAt = &now
+	}
+	var affected int64
+	if r.useSQLite {
+		result, err := r.sqlite.ExecContext(ctx,
+			`UPDATE workouts SET archived_at = CASE WHEN ? IS NULL THEN NULL ELSE COALESCE(archived_at, ?) END WHERE id = ? AND user_id = ?`,
+			archivedAt, archivedAt, id, userID)
+		if err != nil {
+			return fmt.Errorf("failed to archive workout: %w", err)
+		}
+		affected, _ = result.RowsAffected()
+	} else {
+		tag, err := r.db.Exec(ctx,
+			`UPDATE workouts SET archived_at = CASE WHEN $1::timestamp IS NULL THEN NULL ELSE COALESCE(archived_at, $1) END WHERE id = $2 AND user_id = $3`,
+			archivedAt, id, userID)
+		if err != nil {
+			return fmt.Errorf("failed to archive workout: %w", err)
+		}
+		affected = tag.RowsAffected()
+	}
+	if affected == 0 {
+		return ErrWorkoutNotFound
+	}
+	return nil
+}
+
+// ReorderExercises sets the order of a workout's exercises to exerciseIDs, which
+// must list every exercise in the workout exactly once. Members of a superset or
+// circuit stay together, at the position of whichever member is listed first.
+func (r *WorkoutRepository) ReorderExercises(ctx context.Context, userID, workoutID string, exerciseIDs []string) error {
+	if _, err := r.GetWorkout(ctx, userID, workoutID); err != nil {
+		return ErrWorkoutNotFound
+	}
+	current, err := r.GetExercisesByWorkout(ctx, workoutID)
+	if err != nil {
+		return err
+	}
+	if len(current) != len(exerciseIDs) {
+		return ErrInvalidExerciseOrder
+	}
+	byID := make(map[string]*models.Exercise, len(current))
+	for _, e := range current {
+		byID[e.ID] = e
+	}
+	ordered := make([]*models.Exercise, 0, len(exerciseIDs))
+	for _, id := range exerciseIDs {
+		e, ok := byID[id]
+		if !ok {
+			return ErrInvalidExerciseOrder
+		}
+		ordered = append(ordered, e)
+		delete(byID, id)
+	}
+	return r.saveExerciseLayout(ctx, workoutID, models.ContiguousGroups(ordered))
+}
+
+// saveExerciseLayout writes the position, group_id and group_type of each of a
+// workout's exercises, numbering them in slice order. It is one statement, so
+// readers never see a half-applied change.
+func (r *WorkoutRepository) saveExerciseLayout(ctx context.Context, workoutID string, exercises []*models.Exercise) error {
+	// Numbered in the order they appear, so the SQLite ? placeholders line up too
+	args := make([]interface{}, 0, len(exercises)*6+2)
+	cases := func(value func(i int, e *models.Exercise) interface{}) string {
+		var b strings.Builder
+		for i, e := range exercises {
+			fmt.Fprintf(&b, " WHEN $%d THEN $%d", len(args)+1, len(args)+2)
+			args = append(args, e.ID, value(i, e))
+		}
+		return b.String()
+	}
+	positions := cases(func(i int, _ *models.Exercise) interface{} { return i + 1 })
+	groupIDs := cases(func(_ int, e *models.Exercise) interface{} { return e.GroupID })
+	groupTypes := cases(func(_ int, e *models.Exercise) interface{} { return e.GroupType })
+	args = append(args, time.Now(), workoutID)
+	query := fmt.Sprintf(`UPDATE exercises
+		SET position = CASE id%s ELSE position END,
+			group_id = CASE id%s ELSE group_id END,
+			group_type = CASE id%s ELSE group_type END,
+			updated_at = $%d
+		WHERE workout_id = $%d`,
+		positions, groupIDs, groupTypes, len(args)-1, len(args))
+
+	if _, err := exec(ctx, r.db, r.sqlite, r.useSQLite, query, args...); err != nil {
+		return fmt.Errorf("failed to save exercise order: %w", err)
+	}
+	return nil
+}
+
 /**
  * Exercise operations
  *
@@ -471,7 +712,11 @@ func (r *WorkoutRepository) CreateExercise(ctx context.Context, userID string, e
 		return fmt.Errorf("workout not found or access denied: %w", err)
 	}
 
-	id := uuid.New().String()
+	if exercise.Mode == "" {
+		exercise.Mode = models.ExerciseModeReps
+	}
+
+	id := ids.New()
 	now := time.Now()
 
 	if r.useSQLite {
@@ -496,11 +741,12 @@ func (r *WorkoutRepository) CreateExercise(ctx context.Context, userID string, e
  */
 func (r *WorkoutRepository) createExercisePostgres(ctx context.Context, id string, exercise *models.Exercise, now time.Time) error {
 	query := `
-		INSERT INTO exercises (id, name, sets, reps, weight, workout_id, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO exercises (id, name, sets, reps, reps_min, reps_max, weight, mode, duration_seconds, distance_meters, rest_seconds, target_rpe, tempo, video_url, image_url, unilateral, workout_id, position, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, (SELECT COALESCE(MAX(position), 0) + 1 FROM exercises WHERE workout_id = $17), $18, $19)
+		RETURNING position
 	`
 
-	_, err := r.db.Exec(ctx, query, id, exercise.Name, exercise.Sets, exercise.Reps, exercise.Weight, exercise.WorkoutID, now, now)
+	err := r.db.QueryRow(ctx, query, id, exercise.Name, exercise.Sets, exercise.Reps, exercise.RepsMin, exercise.RepsMax, exercise.Weight, exercise.Mode, exercise.DurationSeconds, exercise.DistanceMeters, exercise.RestSeconds, exercise.TargetRPE, exercise.Tempo, exercise.VideoURL, exercise.ImageURL, exercise.Unilateral, exercise.WorkoutID, now, now).Scan(&exercise.Position)
 	if err != nil {
 		return fmt.Errorf("failed to create exercise: %w", err)
 	}
@@ -527,11 +773,12 @@ func (r *WorkoutRepository) createExercisePostgres(ctx context.Context, id strin
  */
 func (r *WorkoutRepository) createExerciseSQLite(ctx context.Context, id string, exercise *models.Exercise, now time.Time) error {
 	query := `
-		INSERT INTO exercises (id, name, sets, reps, weight, workout_id, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO exercises (id, name, sets, reps, reps_min, reps_max, weight, mode, duration_seconds, distance_meters, rest_seconds, target_rpe, tempo, video_url, image_url, unilateral, workout_id, position, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, (SELECT COALESCE(MAX(position), 0) + 1 FROM exercises WHERE workout_id = ?), ?, ?)
+		RETURNING position
 	`
 
-	_, err := r.sqlite.ExecContext(ctx, query, id, exercise.Name, exercise.Sets, exercise.Reps, exercise.Weight, exercise.WorkoutID, now, now)
+	err := r.sqlite.QueryRowContext(ctx, query, id, exercise.Name, exercise.Sets, exercise.Reps, exercise.RepsMin, exercise.RepsMax, exercise.Weight, exercise.Mode, exercise.DurationSeconds, exercise.DistanceMeters, exercise.RestSeconds, exercise.TargetRPE, exercise.Tempo, exercise.VideoURL, exercise.ImageURL, exercise.Unilateral, exercise.WorkoutID, exercise.WorkoutID, now, now).Scan(&exercise.Position)
 	if err != nil {
 		return fmt.Errorf("failed to create exercise: %w", err)
 	}
@@ -542,6 +789,75 @@ func (r *WorkoutRepository) createExerciseSQLite(ctx context.Context, id string,
 	return nil
 }
 
+/**
+ * CreateExercises appends several exercises to one of the user's workouts in a
+ * single transaction, in the order given: either all are created or none are
+ *
+ * Args:
+ * - ctx: Context for the operation
+ * - userID: Owner of the workout
+ * - workoutID: Workout to add the exercises to
+ * - exercises: Validated exercises; ID, position and timestamps are filled in
+ *
+ * Returns:
+ * - error: ErrWorkoutNotFound if the user has no such workout, or a database error
+ */
+func (r *WorkoutRepository) CreateExercises(ctx context.Context, userID, workoutID string, exercises []*models.Exercise) error {
+	if err := r.checkWorkoutOwner(ctx, userID, workoutID); err != nil {
+		return err
+	}
+	now := time.Now()
+	args := func(id string, e *models.Exercise) []interface{} {
+		return []interface{}{id, e.Name, e.Sets, e.Reps, e.RepsMin, e.RepsMax, e.Weight, e.Mode, e.DurationSeconds, e.DistanceMeters, e.RestSeconds, e.TargetRPE, e.Tempo, e.VideoURL, e.ImageURL, e.Unilateral, workoutID, now, now}
+	}
+	for _, e := range exercises {
+		e.WorkoutID = workoutID
+		if e.Mode == "" {
+			e.Mode = models.ExerciseModeReps
+		}
+	}
+
+	if r.useSQLite {
+		tx, err := r.sqlite.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+		query := `
+			INSERT INTO exercises (id, name, sets, reps, reps_min, reps_max, weight, mode, duration_seconds, distance_meters, rest_seconds, target_rpe, tempo, video_url, image_url, unilateral, workout_id, position, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, (SELECT COALESCE(MAX(position), 0) + 1 FROM exercises WHERE workout_id = ?), ?, ?)
+			RETURNING position`
+		for _, e := range exercises {
+			id := ids.New()
+			// Positional placeholders: the workout ID is bound again for the position subquery
+			sqliteArgs := append(args(id, e)[:17:17], workoutID, now, now)
+			if err := tx.QueryRowContext(ctx, query, sqliteArgs...).Scan(&e.Position); err != nil {
+				return fmt.Errorf("failed to create exercise %s: %w", e.Name, err)
+			}
+			e.ID, e.CreatedAt, e.UpdatedAt = id, now, now
+		}
+		return tx.Commit()
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	query := `
+		INSERT INTO exercises (id, name, sets, reps, reps_min, reps_max, weight, mode, duration_seconds, distance_meters, rest_seconds, target_rpe, tempo, video_url, image_url, unilateral, workout_id, position, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, (SELECT COALESCE(MAX(position), 0) + 1 FROM exercises WHERE workout_id = $17), $18, $19)
+		RETURNING position`
+	for _, e := range exercises {
+		id := ids.New()
+		if err := tx.QueryRow(ctx, query, args(id, e)...).Scan(&e.Position); err != nil {
+			return fmt.Errorf("failed to create exercise %s: %w", e.Name, err)
+		}
+		e.ID, e.CreatedAt, e.UpdatedAt = id, now, now
+	}
+	return tx.Commit(ctx)
+}
+
 /**
  * GetExercisesByWorkout retrieves all exercises for a specific workout from the database
  *
@@ -577,10 +893,10 @@ func (r *WorkoutRepository) GetExercisesByWorkout(ctx context.Context, workoutID
  */
 func (r *WorkoutRepository) getExercisesByWorkoutPostgres(ctx context.Context, workoutID string) ([]*models.Exercise, error) {
 	query := `
-		SELECT id, name, sets, reps, weight, workout_id, created_at, updated_at
+		SELECT id, name, sets, reps, reps_min, reps_max, weight, mode, duration_seconds, distance_meters, rest_seconds, target_rpe, tempo, video_url, image_url, unilateral, workout_id, position, group_id, group_type, created_at, updated_at
 		FROM exercises
 		WHERE workout_id = $1
-		ORDER BY created_at ASC
+		ORDER BY position, created_at
 	`
 
 	rows, err := r.db.Query(ctx, query, workoutID)
@@ -593,8 +909,8 @@ func (r *WorkoutRepository) getExercisesByWorkoutPostgres(ctx context.Context, w
 	for rows.Next() {
 		var exercise models.Exercise
 		err := rows.Scan(
-			&exercise.ID, &exercise.Name, &exercise.Sets, &exercise.Reps,
-			&exercise.Weight, &exercise.WorkoutID, &exercise.CreatedAt, &exercise.UpdatedAt,
+			&exercise.ID, &exercise.Name, &exercise.Sets, &exercise.Reps, &exercise.RepsMin, &exercise.RepsMax,
+			&exercise.Weight, &exercise.Mode, &exercise.DurationSeconds, &exercise.DistanceMeters, &exercise.RestSeconds, &exercise.TargetRPE, &exercise.Tempo, &exercise.VideoURL, &exercise.ImageURL, &exercise.Unilateral, &exercise.WorkoutID, &exercise.Position, &exercise.GroupID, &exercise.GroupType, &exercise.CreatedAt, &exercise.UpdatedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan exercise: %w", err)
@@ -620,10 +936,10 @@ func (r *WorkoutRepository) getExercisesByWorkoutPostgres(ctx context.Context, w
  */
 func (r *WorkoutRepository) getExercisesByWorkoutSQLite(ctx context.Context, workoutID string) ([]*models.Exercise, error) {
 	query := `
-		SELECT id, name, sets, reps, weight, workout_id, created_at, updated_at
+		SELECT id, name, sets, reps, reps_min, reps_max, weight, mode, duration_seconds, distance_meters, rest_seconds, target_rpe, tempo, video_url, image_url, unilateral, workout_id, position, group_id, group_type, created_at, updated_at
 		FROM exercises
 		WHERE workout_id = ?
-		ORDER BY created_at ASC
+		ORDER BY position, created_at
 	`
 
 	rows, err := r.sqlite.QueryContext(ctx, query, workoutID)
@@ -636,8 +952,8 @@ func (r *WorkoutRepository) getExercisesByWorkoutSQLite(ctx context.Context, wor
 	for rows.Next() {
 		var exercise models.Exercise
 		err := rows.Scan(
-			&exercise.ID, &exercise.Name, &exercise.Sets, &exercise.Reps,
-			&exercise.Weight, &exercise.WorkoutID, &exercise.CreatedAt, &exercise.UpdatedAt,
+			&exercise.ID, &exercise.Name, &exercise.Sets, &exercise.Reps, &exercise.RepsMin, &exercise.RepsMax,
+			&exercise.Weight, &exercise.Mode, &exercise.DurationSeconds, &exercise.DistanceMeters, &exercise.RestSeconds, &exercise.TargetRPE, &exercise.Tempo, &exercise.VideoURL, &exercise.ImageURL, &exercise.Unilateral, &exercise.WorkoutID, &exercise.Position, &exercise.GroupID, &exercise.GroupType, &exercise.CreatedAt, &exercise.UpdatedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan exercise: %w", err)
@@ -658,15 +974,15 @@ func (r *WorkoutRepository) GetExercise(ctx context.Context, exerciseID string)
 
 func (r *WorkoutRepository) getExercisePostgres(ctx context.Context, exerciseID string) (*models.Exercise, error) {
 	query := `
-		SELECT id, name, sets, reps, weight, workout_id, created_at, updated_at
+		SELECT id, name, sets, reps, reps_min, reps_max, weight, mode, duration_seconds, distance_meters, rest_seconds, target_rpe, tempo, video_url, image_url, unilateral, workout_id, position, group_id, group_type, created_at, updated_at
 		FROM exercises
 		WHERE id = $1
 	`
 
 	var exercise models.Exercise
 	err := r.db.QueryRow(ctx, query, exerciseID).Scan(
-		&exercise.ID, &exercise.Name, &exercise.Sets, &exercise.Reps,
-		&exercise.Weight, &exercise.WorkoutID, &exercise.CreatedAt, &exercise.UpdatedAt,
+		&exercise.ID, &exercise.Name, &exercise.Sets, &exercise.Reps, &exercise.RepsMin, &exercise.RepsMax,
+		&exercise.Weight, &exercise.Mode, &exercise.DurationSeconds, &exercise.DistanceMeters, &exercise.RestSeconds, &exercise.TargetRPE, &exercise.Tempo, &exercise.VideoURL, &exercise.ImageURL, &exercise.Unilateral, &exercise.WorkoutID, &exercise.Position, &exercise.GroupID, &exercise.GroupType, &exercise.CreatedAt, &exercise.UpdatedAt,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get exercise: %w", err)
@@ -677,15 +993,15 @@ func (r *WorkoutRepository) getExercisePostgres(ctx context.Context, exerciseID
 
 func (r *WorkoutRepository) getExerciseSQLite(ctx context.Context, exerciseID string) (*models.Exercise, error) {
 	query := `
-		SELECT id, name, sets, reps, weight, workout_id, created_at, updated_at
+		SELECT id, name, sets, reps, reps_min, reps_max, weight, mode, duration_seconds, distance_meters, rest_seconds, target_rpe, tempo, video_url, image_url, unilateral, workout_id, position, group_id, group_type, created_at, updated_at
 		FROM exercises
 		WHERE id = ?
 	`
 
 	var exercise models.Exercise
 	err := r.sqlite.QueryRowContext(ctx, query, exerciseID).Scan(
-		&exercise.ID, &exercise.Name, &exercise.Sets, &exercise.Reps,
-		&exercise.Weight, &exercise.WorkoutID, &exercise.CreatedAt, &exercise.UpdatedAt,
+		&exercise.ID, &exercise.Name, &exercise.Sets, &exercise.Reps, &exercise.RepsMin, &exercise.RepsMax,
+		&exercise.Weight, &exercise.Mode, &exercise.DurationSeconds, &exercise.DistanceMeters, &exercise.RestSeconds, &exercise.TargetRPE, &exercise.Tempo, &exercise.VideoURL, &exercise.ImageURL, &exercise.Unilateral, &exercise.WorkoutID, &exercise.Position, &exercise.GroupID, &exercise.GroupType, &exercise.CreatedAt, &exercise.UpdatedAt,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get exercise: %w", err)
@@ -694,30 +1010,70 @@ func (r *WorkoutRepository) getExerciseSQLite(ctx context.Context, exerciseID st
 	return &exercise, nil
 }
 
+// GetUserExercise returns an exercise from one of the user's workouts, or ErrExerciseNotFound
+func (r *WorkoutRepository) GetUserExercise(ctx context.Context, userID, exerciseID string) (*models.Exercise, error) {
+	exercise, err := r.GetExercise(ctx, exerciseID)
+	if errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrExerciseNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := r.checkWorkoutOwner(ctx, userID, exercise.WorkoutID); err != nil {
+		if errors.Is(err, ErrWorkoutNotFound) {
+			return nil, ErrExerciseNotFound
+		}
+		return nil, err
+	}
+	return exercise, nil
+}
+
 /**
- * UpdateExercise updates an existing exercise in the database
+ * UpdateExercise saves an exercise's name, prescription and mode
  *
- * Uses parameterized query with error handling.
+ * Only exercises in the user's own workouts are updated. The workout an
+ * exercise belongs to never changes.
  *
  * Args:
  * - ctx: Context for the operation
- * - exercise: Pointer to the exercise model to update
+ * - userID: Owner of the exercise's workout
+ * - exercise: Pointer to the exercise model to update; UpdatedAt is set on success
  *
  * Returns:
- * - error: Database error if any
+ * - error: ErrExerciseNotFound if the user has no such exercise, or a database error
  */
-func (r *WorkoutRepository) UpdateExercise(ctx context.Context, exercise *models.Exercise) error {
-	query := `
-		UPDATE exercises
-		SET name = $2, sets = $3, reps = $4, weight = $5, updated_at = $6
-		WHERE id = $1
-	`
-
-	_, err := r.db.Exec(ctx, query, exercise.ID, exercise.Name, exercise.Sets, exercise.Reps, exercise.Weight, time.Now())
-	if err != nil {
-		return fmt.Errorf("failed to update exercise: %w", err)
+func (r *WorkoutRepository) UpdateExercise(ctx context.Context, userID string, exercise *models.Exercise) error {
+	var affected int64
+	now := time.Now()
+	if r.useSQLite {
+		result, err := r.sqlite.ExecContext(ctx, `
+			UPDATE exercises
+			SET name = ?, sets = ?, reps = ?, reps_min = ?, reps_max = ?, weight = ?, mode = ?, duration_seconds = ?, distance_meters = ?, rest_seconds = ?,
+				target_rpe = ?, tempo = ?, video_url = ?, image_url = ?, unilateral = ?, updated_at = ?
+			WHERE id = ? AND workout_id IN (SELECT id FROM workouts WHERE user_id = ?)`,
+			exercise.Name, exercise.Sets, exercise.Reps, exercise.RepsMin, exercise.RepsMax, exercise.Weight, exercise.Mode, exercise.DurationSeconds, exercise.DistanceMeters, exercise.RestSeconds, exercise.TargetRPE, exercise.Tempo, exercise.VideoURL, exercise.ImageURL, exercise.Unilateral, now,
+			exercise.ID, userID)
+		if err != nil {
+			return fmt.Errorf("failed to update exercise: %w", err)
+		}
+		affected, _ = result.RowsAffected()
+	} else {
+		tag, err := r.db.Exec(ctx, `
+			UPDATE exercises
+			SET name = $1, sets = $2, reps = $3, reps_min = $4, reps_max = $5, weight = $6, mode = $7, duration_seconds = $8, distance_meters = $9, rest_seconds = $10,
+				target_rpe = $11, tempo = $12, video_url = $13, image_url = $14, unilateral = $15, updated_at = $16
+			WHERE id = $17 AND workout_id IN (SELECT id FROM workouts WHERE user_id = $18)`,
+			exercise.Name, exercise.Sets, exercise.Reps, exercise.RepsMin, exercise.RepsMax, exercise.Weight, exercise.Mode, exercise.DurationSeconds, exercise.DistanceMeters, exercise.RestSeconds, exercise.TargetRPE, exercise.Tempo, exercise.VideoURL, exercise.ImageURL, exercise.Unilateral, now,
+			exercise.ID, userID)
+		if err != nil {
+			return fmt.Errorf("failed to update exercise: %w", err)
+		}
+		affected = tag.RowsAffected()
 	}
-
+	if affected == 0 {
+		return ErrExerciseNotFound
+	}
+	exercise.UpdatedAt = now
 	return nil
 }
 
@@ -734,10 +1090,33 @@ func (r *WorkoutRepository) UpdateExercise(ctx context.Context, exercise *models
  * - error: Database error if any
  */
 func (r *WorkoutRepository) DeleteExercise(ctx context.Context, userID, id string) error {
+	// A superset or circuit left with one exercise is dissolved
+	var groupID string
+	if exercise, err := r.GetExercise(ctx, id); err == nil {
+		groupID = exercise.GroupID
+	}
+
+	var err error
+	if r.useSQLite {
+		err = r.deleteExerciseSQLite(ctx, userID, id)
+	} else {
+		err = r.deleteExercisePostgres(ctx, userID, id)
+	}
+	if err != nil || groupID == "" {
+		return err
+	}
+
+	query := `UPDATE exercises SET group_id = '', group_type = ''
+		WHERE group_id = $1 AND (SELECT COUNT(*) FROM exercises WHERE group_id = $2) < 2`
 	if r.useSQLite {
-		return r.deleteExerciseSQLite(ctx, userID, id)
+		_, err = r.sqlite.ExecContext(ctx, rebind(query, 2), groupID, groupID)
+	} else {
+		_, err = r.db.Exec(ctx, query, groupID, groupID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to dissolve exercise group: %w", err)
 	}
-	return r.deleteExercisePostgres(ctx, userID, id)
+	return nil
 }
 
 /**
@@ -785,70 +1164,245 @@ func (r *WorkoutRepository) deleteExerciseSQLite(ctx context.Context, userID, id
 /**
  * GetWorkoutTemplates returns all available workout templates
  *
- * Retrieves templates from the appropriate database implementation.
+ * Reads the catalog from the workout_templates tables, in catalog order,
+ * each template with its exercises. The user's own templates follow the
+ * built-in ones, oldest first.
  *
  * Args:
  * - ctx: Context for the operation
+ * - userID: User whose saved templates to include, or "" for the built-ins alone
  *
  * Returns:
  * - []*models.WorkoutTemplate: List of workout templates
  * - error: Database error if any
  */
-func (r *WorkoutRepository) GetWorkoutTemplates(ctx context.Context) ([]*models.WorkoutTemplate, error) {
-	if r.useSQLite {
-		return r.getWorkoutTemplatesSQLite(ctx)
-	}
-	return r.getWorkoutTemplatesPostgres(ctx)
+func (r *WorkoutRepository) GetWorkoutTemplates(ctx context.Context, userID string) ([]*models.WorkoutTemplate, error) {
+	return r.queryWorkoutTemplates(ctx, userID, "")
 }
 
 /**
- * getWorkoutTemplatesPostgres retrieves workout templates from PostgreSQL database
+ * GetWorkoutTemplate returns one workout template with its exercises
  *
- * For now, returns predefined templates.
+ * Args:
+ * - ctx: Context for the operation
+ * - userID: User whose saved templates may match, or "" for the built-ins alone
+ * - id: Template ID, e.g. "push-pull-legs"
+ *
+ * Returns:
+ * - *models.WorkoutTemplate: The template
+ * - error: ErrWorkoutTemplateNotFound if there is no such template, or a database error
+ */
+func (r *WorkoutRepository) GetWorkoutTemplate(ctx context.Context, userID, id string) (*models.WorkoutTemplate, error) {
+	templates, err := r.queryWorkoutTemplates(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(templates) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrWorkoutTemplateNotFound, id)
+	}
+	return templates[0], nil
+}
+
+/**
+ * queryWorkoutTemplates loads templates and their exercises
  *
  * Args:
  * - ctx: Context for the operation
+ * - userID: User whose saved templates to include alongside the built-ins
+ * - id: Template to load, or "" for all of them
  *
  * Returns:
- * - []*models.WorkoutTemplate: List of workout templates from PostgreSQL
+ * - []*models.WorkoutTemplate: Templates in catalog order
  * - error: Database error if any
  */
-func (r *WorkoutRepository) getWorkoutTemplatesPostgres(ctx context.Context) ([]*models.WorkoutTemplate, error) {
-	// For now, return predefined templates
-	return r.getPredefinedTemplates(), nil
+func (r *WorkoutRepository) queryWorkoutTemplates(ctx context.Context, userID, id string) ([]*models.WorkoutTemplate, error) {
+	templatesQuery := `
+		SELECT id, name, type, description, difficulty, duration_minutes, user_id IS NOT NULL, created_at
+		FROM workout_templates
+		WHERE ($1 = '' OR id = $2) AND (user_id IS NULL OR user_id = $3)
+		ORDER BY user_id IS NOT NULL, position, created_at, id`
+	exercisesQuery := `
+		SELECT e.template_id, e.name, e.sets, e.reps, e.reps_min, e.reps_max, e.weight, e.mode, e.duration_seconds, e.distance_meters, e.rest_seconds
+		FROM workout_template_exercises e
+		JOIN workout_templates t ON t.id = e.template_id
+		WHERE ($1 = '' OR t.id = $2) AND (t.user_id IS NULL OR t.user_id = $3)
+		ORDER BY e.template_id, e.position`
+
+	var templates []*models.WorkoutTemplate
+	byID := make(map[string]*models.WorkoutTemplate)
+	scanTemplate := func(scan func(...interface{}) error) error {
+		t := &models.WorkoutTemplate{Exercises: []models.Exercise{}}
+		if err := scan(&t.ID, &t.Name, &t.Type, &t.Description, &t.Difficulty, &t.Duration, &t.Custom, &t.CreatedAt); err != nil {
+			return fmt.Errorf("failed to scan workout template: %w", err)
+		}
+		templates = append(templates, t)
+		byID[t.ID] = t
+		return nil
+	}
+	scanExercise := func(scan func(...interface{}) error) error {
+		var templateID string
+		var e models.Exercise
+		if err := scan(&templateID, &e.Name, &e.Sets, &e.Reps, &e.RepsMin, &e.RepsMax, &e.Weight, &e.Mode, &e.DurationSeconds, &e.DistanceMeters, &e.RestSeconds); err != nil {
+			return fmt.Errorf("failed to scan workout template exercise: %w", err)
+		}
+		if t := byID[templateID]; t != nil {
+			t.Exercises = append(t.Exercises, e)
+		}
+		return nil
+	}
+
+	args := []interface{}{id, id, userID}
+	if err := eachRow(ctx, r.db, r.sqlite, r.useSQLite, templatesQuery, args, scanTemplate); err != nil {
+		return nil, fmt.Errorf("failed to get workout templates: %w", err)
+	}
+	if err := eachRow(ctx, r.db, r.sqlite, r.useSQLite, exercisesQuery, args, scanExercise); err != nil {
+		return nil, fmt.Errorf("failed to get workout template exercises: %w", err)
+	}
+	return templates, nil
 }
 
 /**
- * getWorkoutTemplatesSQLite retrieves workout templates from SQLite database
+ * SaveWorkoutAsTemplate snapshots one of the user's workouts as a template
  *
- * For now, returns predefined templates.
+ * The template copies the workout's type, target duration and exercises as
+ * they are now; later changes to the workout do not affect it. Only the
+ * user sees it, next to the built-in templates.
  *
  * Args:
  * - ctx: Context for the operation
+ * - userID: Owner of the workout and of the new template
+ * - workoutID: Workout to copy
+ * - name: Template name, or "" to use the workout's
+ * - description: Template description, or "" to use the workout's notes
+ *
+ * Returns:
+ * - *models.WorkoutTemplate: The saved template
+ * - error: ErrWorkoutNotFound if the user has no such workout, or a database error
+ */
+func (r *WorkoutRepository) SaveWorkoutAsTemplate(ctx context.Context, userID, workoutID, name, description string) (*models.WorkoutTemplate, error) {
+	if err := r.checkWorkoutOwner(ctx, userID, workoutID); err != nil {
+		return nil, err
+	}
+	workout, err := r.GetWorkout(ctx, userID, workoutID)
+	if err != nil {
+		return nil, err
+	}
+	template := &models.WorkoutTemplate{
+		ID:          ids.New(),
+		Name:        name,
+		Type:        workout.Type,
+		Description: description,
+		Exercises:   workout.Exercises,
+		Custom:      true,
+		CreatedAt:   time.Now(),
+	}
+	if template.Name == "" {
+		template.Name = workout.Name
+	}
+	if template.Description == "" {
+		template.Description = workout.Notes
+	}
+	if workout.TargetDurationMinutes != nil {
+		template.Duration = *workout.TargetDurationMinutes
+	}
+
+	if err := r.insertWorkoutTemplate(ctx, userID, template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+/**
+ * insertWorkoutTemplate saves a new user-owned template and its exercises
+ *
+ * Args:
+ * - ctx: Context for the operation
+ * - userID: Owner of the template
+ * - template: Template to save, with its ID and CreatedAt set
  *
  * Returns:
- * - []*models.WorkoutTemplate: List of workout templates from SQLite
  * - error: Database error if any
  */
-func (r *WorkoutRepository) getWorkoutTemplatesSQLite(ctx context.Context) ([]*models.WorkoutTemplate, error) {
-	// For now, return predefined templates
-	return r.getPredefinedTemplates(), nil
+func (r *WorkoutRepository) insertWorkoutTemplate(ctx context.Context, userID string, template *models.WorkoutTemplate) error {
+	templateInsert := `
+		INSERT INTO workout_templates (id, user_id, name, type, description, difficulty, duration_minutes, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	exerciseInsert := `
+		INSERT INTO workout_template_exercises (template_id, position, name, sets, reps, reps_min, reps_max, weight, mode, duration_seconds, distance_meters, rest_seconds)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+	templateArgs := []interface{}{template.ID, userID, template.Name, template.Type, template.Description, template.Difficulty, template.Duration, template.CreatedAt}
+	exerciseArgs := func(i int, e models.Exercise) []interface{} {
+		return []interface{}{template.ID, i + 1, e.Name, e.Sets, e.Reps, e.RepsMin, e.RepsMax, e.Weight, e.Mode, e.DurationSeconds, e.DistanceMeters, e.RestSeconds}
+	}
+
+	if r.useSQLite {
+		tx, err := r.sqlite.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+		if _, err := tx.ExecContext(ctx, rebind(templateInsert, len(templateArgs)), templateArgs...); err != nil {
+			return fmt.Errorf("failed to save workout template: %w", err)
+		}
+		for i, e := range template.Exercises {
+			if _, err := tx.ExecContext(ctx, rebind(exerciseInsert, 12), exerciseArgs(i, e)...); err != nil {
+				return fmt.Errorf("failed to save workout template exercise: %w", err)
+			}
+		}
+		return tx.Commit()
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, templateInsert, templateArgs...); err != nil {
+		return fmt.Errorf("failed to save workout template: %w", err)
+	}
+	for i, e := range template.Exercises {
+		if _, err := tx.Exec(ctx, exerciseInsert, exerciseArgs(i, e)...); err != nil {
+			return fmt.Errorf("failed to save workout template exercise: %w", err)
+		}
+	}
+	return tx.Commit(ctx)
 }
 
 /**
  * GetExerciseTemplates returns all available exercise templates
  *
- * Returns a predefined list of exercise templates.
+ * Returns the predefined exercise library followed by the user's own
+ * templates, each labelled with the category and muscle group it is filed
+ * under in the taxonomy.
  *
  * Args:
  * - ctx: Context for the operation
+ * - userID: User whose templates to include, or "" for the library alone
+ * - tax: Exercise taxonomy from TaxonomyRepository.Load
  *
  * Returns:
  * - []*models.ExerciseTemplate: List of exercise templates
  * - error: Database error if any
  */
-func (r *WorkoutRepository) GetExerciseTemplates(ctx context.Context) ([]*models.ExerciseTemplate, error) {
-	return r.getPredefinedExerciseTemplates(), nil
+func (r *WorkoutRepository) GetExerciseTemplates(ctx context.Context, userID string, tax *models.Taxonomy) ([]*models.ExerciseTemplate, error) {
+	templates := r.getPredefinedExerciseTemplates()
+	if userID != "" {
+		own, err := r.getUserExerciseTemplates(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, own...)
+	}
+	for _, t := range templates {
+		category, group := tax.Classify(t.Name)
+		if category != nil {
+			t.Category, t.CategoryID = category.Name, category.ID
+		}
+		if group != nil {
+			t.MuscleGroup, t.CategoryID = group.Name, group.ID
+		}
+	}
+	return templates, nil
 }
 
 /**
@@ -860,184 +1414,88 @@ func (r *WorkoutRepository) GetExerciseTemplates(ctx context.Context) ([]*models
  * - []*models.ExerciseTemplate: List of exercise templates
  */
 func (r *WorkoutRepository) getPredefinedExerciseTemplates() []*models.ExerciseTemplate {
-	return []*models.ExerciseTemplate{
+	templates := []*models.ExerciseTemplate{
 		// Chest
-		{Name: "Barbell Bench Press", Category: "Chest", DefaultSets: 4, DefaultReps: 8, DefaultWeight: 135},
-		{Name: "Dumbbell Bench Press", Category: "Chest", DefaultSets: 3, DefaultReps: 10, DefaultWeight: 40},
-		{Name: "Incline Dumbbell Press", Category: "Chest", DefaultSets: 3, DefaultReps: 10, DefaultWeight: 35},
-		{Name: "Push-ups", Category: "Chest", DefaultSets: 3, DefaultReps: 15, DefaultWeight: 0},
+		{Name: "Barbell Bench Press", DefaultSets: 4, DefaultReps: 8, DefaultWeight: 135, RiskFlags: []string{models.RiskShoulderLoading}},
+		{Name: "Dumbbell Bench Press", DefaultSets: 3, DefaultReps: 10, DefaultWeight: 40, RiskFlags: []string{models.RiskShoulderLoading}},
+		{Name: "Incline Dumbbell Press", DefaultSets: 3, DefaultReps: 10, DefaultWeight: 35, RiskFlags: []string{models.RiskShoulderLoading}},
+		{Name: "Push-ups", DefaultSets: 3, DefaultReps: 15, DefaultWeight: 0},
 
 		// Back
-		{Name: "Pull-ups", Category: "Back", DefaultSets: 4, DefaultReps: 8, DefaultWeight: 0},
-		{Name: "Barbell Rows", Category: "Back", DefaultSets: 4, DefaultReps: 10, DefaultWeight: 95},
-		{Name: "Dumbbell Rows", Category: "Back", DefaultSets: 3, DefaultReps: 12, DefaultWeight: 40},
-		{Name: "Lat Pulldowns", Category: "Back", DefaultSets: 3, DefaultReps: 12, DefaultWeight: 80},
+		{Name: "Pull-ups", DefaultSets: 4, DefaultReps: 8, DefaultWeight: 0, RiskFlags: []string{models.RiskOverhead}},
+		{Name: "Barbell Rows", DefaultSets: 4, DefaultReps: 10, DefaultWeight: 95, RiskFlags: []string{models.RiskSpinalLoading}},
+		{Name: "Dumbbell Rows", DefaultSets: 3, DefaultReps: 12, DefaultWeight: 40},
+		{Name: "Lat Pulldowns", DefaultSets: 3, DefaultReps: 12, DefaultWeight: 80, RiskFlags: []string{models.RiskOverhead}},
 
 		// Shoulders
-		{Name: "Overhead Press", Category: "Shoulders", DefaultSets: 3, DefaultReps: 8, DefaultWeight: 65},
-		{Name: "Dumbbell Shoulder Press", Category: "Shoulders", DefaultSets: 3, DefaultReps: 10, DefaultWeight: 30},
-		{Name: "Lateral Raises", Category: "Shoulders", DefaultSets: 3, DefaultReps: 15, DefaultWeight: 15},
-		{Name: "Front Raises", Category: "Shoulders", DefaultSets: 3, DefaultReps: 12, DefaultWeight: 15},
+		{Name: "Overhead Press", DefaultSets: 3, DefaultReps: 8, DefaultWeight: 65, RiskFlags: []string{models.RiskOverhead, models.RiskSpinalLoading}},
+		{Name: "Dumbbell Shoulder Press", DefaultSets: 3, DefaultReps: 10, DefaultWeight: 30, RiskFlags: []string{models.RiskOverhead}},
+		{Name: "Lateral Raises", DefaultSets: 3, DefaultReps: 15, DefaultWeight: 15},
+		{Name: "Front Raises", DefaultSets: 3, DefaultReps: 12, DefaultWeight: 15},
 
 		// Arms
-		{Name: "Bicep Curls", Category: "Arms", DefaultSets: 3, DefaultReps: 12, DefaultWeight: 25},
-		{Name: "Hammer Curls", Category: "Arms", DefaultSets: 3, DefaultReps: 12, DefaultWeight: 25},
-		{Name: "Tricep Pushdowns", Category: "Arms", DefaultSets: 3, DefaultReps: 15, DefaultWeight: 40},
-		{Name: "Tricep Dips", Category: "Arms", DefaultSets: 3, DefaultReps: 12, DefaultWeight: 0},
+		{Name: "Bicep Curls", DefaultSets: 3, DefaultReps: 12, DefaultWeight: 25},
+		{Name: "Hammer Curls", DefaultSets: 3, DefaultReps: 12, DefaultWeight: 25},
+		{Name: "Tricep Pushdowns", DefaultSets: 3, DefaultReps: 15, DefaultWeight: 40},
+		{Name: "Tricep Dips", DefaultSets: 3, DefaultReps: 12, DefaultWeight: 0, RiskFlags: []string{models.RiskShoulderLoading}},
 
 		// Legs
-		{Name: "Barbell Squats", Category: "Legs", DefaultSets: 4, DefaultReps: 8, DefaultWeight: 135},
-		{Name: "Deadlifts", Category: "Legs", DefaultSets: 4, DefaultReps: 5, DefaultWeight: 135},
-		{Name: "Leg Press", Category: "Legs", DefaultSets: 3, DefaultReps: 10, DefaultWeight: 180},
-		{Name: "Lunges", Category: "Legs", DefaultSets: 3, DefaultReps: 12, DefaultWeight: 0},
+		{Name: "Barbell Squats", DefaultSets: 4, DefaultReps: 8, DefaultWeight: 135, RiskFlags: []string{models.RiskSpinalLoading, models.RiskKneeDominant}},
+		{Name: "Deadlifts", DefaultSets: 4, DefaultReps: 5, DefaultWeight: 135, RiskFlags: []string{models.RiskSpinalLoading}},
+		{Name: "Leg Press", DefaultSets: 3, DefaultReps: 10, DefaultWeight: 180, RiskFlags: []string{models.RiskKneeDominant}},
+		{Name: "Lunges", DefaultSets: 3, DefaultReps: 12, DefaultWeight: 0, RiskFlags: []string{models.RiskKneeDominant}},
 
 		// Core
-		{Name: "Plank", Category: "Core", DefaultSets: 3, DefaultReps: 30, DefaultWeight: 0},
-		{Name: "Crunches", Category: "Core", DefaultSets: 3, DefaultReps: 20, DefaultWeight: 0},
-		{Name: "Russian Twists", Category: "Core", DefaultSets: 3, DefaultReps: 20, DefaultWeight: 0},
-		{Name: "Leg Raises", Category: "Core", DefaultSets: 3, DefaultReps: 15, DefaultWeight: 0},
+		{Name: "Plank", DefaultSets: 3, DefaultReps: 0, DefaultWeight: 0, Mode: models.ExerciseModeDuration, DefaultDurationSeconds: 30},
+		{Name: "Crunches", DefaultSets: 3, DefaultReps: 20, DefaultWeight: 0, RiskFlags: []string{models.RiskSpinalFlexion}},
+		{Name: "Russian Twists", DefaultSets: 3, DefaultReps: 20, DefaultWeight: 0, RiskFlags: []string{models.RiskSpinalFlexion}},
+		{Name: "Leg Raises", DefaultSets: 3, DefaultReps: 15, DefaultWeight: 0},
 
 		// Cardio
-		{Name: "Running", Category: "Cardio", DefaultSets: 1, DefaultReps: 20, DefaultWeight: 0},
-		{Name: "Cycling", Category: "Cardio", DefaultSets: 1, DefaultReps: 30, DefaultWeight: 0},
-		{Name: "Jump Rope", Category: "Cardio", DefaultSets: 5, DefaultReps: 100, DefaultWeight: 0},
-		{Name: "Burpees", Category: "Cardio", DefaultSets: 3, DefaultReps: 10, DefaultWeight: 0},
+		{Name: "Running", DefaultSets: 1, DefaultWeight: 0, Mode: models.ExerciseModeCardio, DefaultDurationSeconds: 1200, RiskFlags: []string{models.RiskHighImpact}},
+		{Name: "Cycling", DefaultSets: 1, DefaultWeight: 0, Mode: models.ExerciseModeCardio, DefaultDurationSeconds: 1800},
+		{Name: "Jump Rope", DefaultSets: 5, DefaultReps: 100, DefaultWeight: 0, RiskFlags: []string{models.RiskHighImpact}},
+		{Name: "Burpees", DefaultSets: 3, DefaultReps: 10, DefaultWeight: 0, RiskFlags: []string{models.RiskHighImpact, models.RiskKneeDominant}},
 	}
-}
-
-/**
- * getPredefinedTemplates returns a curated list of workout templates
- *
- * Returns a predefined list of workout templates.
- *
- * Returns:
- * - []*models.WorkoutTemplate: List of workout templates
- */
-func (r *WorkoutRepository) getPredefinedTemplates() []*models.WorkoutTemplate {
-	return []*models.WorkoutTemplate{
-		{
-			ID:          "push-pull-legs",
-			Name:        "Push Pull Legs",
-			Type:        models.WorkoutTypeStrength,
-			Description: "Classic 3-day split focusing on pushing, pulling, and leg movements",
-			Difficulty:  "intermediate",
-			Duration:    60,
-			Exercises: []models.Exercise{
-				{Name: "Bench Press", Sets: 4, Reps: 8, Weight: 0},
-				{Name: "Overhead Press", Sets: 3, Reps: 10, Weight: 0},
-				{Name: "Dips", Sets: 3, Reps: 12, Weight: 0},
-				{Name: "Lateral Raises", Sets: 3, Reps: 15, Weight: 0},
-			},
-		},
-		{
-			ID:          "full-body-strength",
-			Name:        "Full Body Strength",
-			Type:        models.WorkoutTypeStrength,
-			Description: "Complete full-body workout hitting all major muscle groups",
-			Difficulty:  "beginner",
-			Duration:    45,
-			Exercises: []models.Exercise{
-				{Name: "Squats", Sets: 3, Reps: 12, Weight: 0},
-				{Name: "Push-ups", Sets: 3, Reps: 10, Weight: 0},
-				{Name: "Rows", Sets: 3, Reps: 12, Weight: 0},
-				{Name: "Plank", Sets: 3, Reps: 1, Weight: 0},
-			},
-		},
-		{
-			ID:          "hiit-cardio",
-			Name:        "HIIT Cardio",
-			Type:        models.WorkoutTypeHIIT,
-			Description: "High-intensity interval training for cardiovascular fitness",
-			Difficulty:  "advanced",
-			Duration:    30,
-			Exercises: []models.Exercise{
-				{Name: "Burpees", Sets: 4, Reps: 20, Weight: 0},
-				{Name: "Mountain Climbers", Sets: 4, Reps: 30, Weight: 0},
-				{Name: "Jump Squats", Sets: 4, Reps: 15, Weight: 0},
-				{Name: "High Knees", Sets: 4, Reps: 30, Weight: 0},
-			},
-		},
-		{
-			ID:          "upper-body-focus",
-			Name:        "Upper Body Focus",
-			Type:        models.WorkoutTypeStrength,
-			Description: "Targeted upper body workout for chest, back, and arms",
-			Difficulty:  "intermediate",
-			Duration:    50,
-			Exercises: []models.Exercise{
-				{Name: "Pull-ups", Sets: 4, Reps: 8, Weight: 0},
-				{Name: "Dumbbell Rows", Sets: 3, Reps: 12, Weight: 0},
-				{Name: "Diamond Push-ups", Sets: 3, Reps: 12, Weight: 0},
-				{Name: "Bicep Curls", Sets: 3, Reps: 15, Weight: 0},
-			},
-		},
-		{
-			ID:          "core-strength",
-			Name:        "Core Strength",
-			Type:        models.WorkoutTypeStrength,
-			Description: "Comprehensive core workout for stability and strength",
-			Difficulty:  "beginner",
-			Duration:    25,
-			Exercises: []models.Exercise{
-				{Name: "Crunches", Sets: 3, Reps: 20, Weight: 0},
-				{Name: "Russian Twists", Sets: 3, Reps: 20, Weight: 0},
-				{Name: "Leg Raises", Sets: 3, Reps: 15, Weight: 0},
-				{Name: "Side Plank", Sets: 3, Reps: 1, Weight: 0},
-			},
-		},
-		{
-			ID:          "endurance-run",
-			Name:        "Endurance Run",
-			Type:        models.WorkoutTypeEndurance,
-			Description: "Steady-state cardio for building endurance",
-			Difficulty:  "beginner",
-			Duration:    45,
-			Exercises: []models.Exercise{
-				{Name: "Running", Sets: 1, Reps: 1, Weight: 0},
-				{Name: "Walking", Sets: 1, Reps: 1, Weight: 0},
-			},
-		},
+	for _, t := range templates {
+		t.ExerciseMetadata = models.DefaultExerciseLibraryMetadata[t.Name]
 	}
+	return templates
 }
 
 /**
  * CreateWorkoutFromTemplate creates a new workout based on a template
  *
- * Retrieves a template by its ID, creates a new workout, and adds exercises
- * from the template to the new workout.
+ * Reads a template by its ID, creates a new workout, and adds exercises
+ * from the template to the new workout, passing each through scale first.
  *
  * Args:
  * - ctx: Context for the operation
  * - templateID: ID of the template to use
  * - name: Name for the new workout
+ * - scale: Adjusts each exercise for the user (see TemplateScaler), or nil to copy as-is
  *
  * Returns:
  * - *models.Workout: Created workout with exercises from template
  * - error: Creation error if any
  */
-func (r *WorkoutRepository) CreateWorkoutFromTemplate(ctx context.Context, userID, templateID string, name string) (*models.Workout, error) {
-	templates := r.getPredefinedTemplates()
-	var template *models.WorkoutTemplate
-
-	for _, t := range templates {
-		if t.ID == templateID {
-			template = t
-			break
-		}
-	}
-
-	if template == nil {
-		return nil, fmt.Errorf("template not found: %s", templateID)
+func (r *WorkoutRepository) CreateWorkoutFromTemplate(ctx context.Context, userID, templateID string, name string, scale func(models.Exercise) models.Exercise) (*models.Workout, error) {
+	template, err := r.GetWorkoutTemplate(ctx, userID, templateID)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create the workout
-	workout, err := r.CreateWorkout(ctx, userID, name)
+	workout, err := r.CreateWorkout(ctx, userID, name, template.Type, "")
 	if err != nil {
 		return nil, err
 	}
 
 	// Add exercises from template
 	for _, exercise := range template.Exercises {
+		if scale != nil {
+			exercise = scale(exercise)
+		}
 		exercise.WorkoutID = workout.ID
 		err = r.CreateExercise(ctx, userID, &exercise)
 		if err != nil {
@@ -1048,11 +1506,196 @@ func (r *WorkoutRepository) CreateWorkoutFromTemplate(ctx context.Context, userI
 	return workout, nil
 }
 
+/**
+ * TemplateScaler fits template exercises to a user before they are copied
+ *
+ * Each exercise is matched to the user's logged bests by name, falling back to
+ * its library exercise's name, and scaled with analytics.ScaleExercise.
+ *
+ * Args:
+ * - level: User's training level, as in models.TrainingProfile
+ * - bests: User's bests keyed by lowercased name, from SessionRepository.GetExerciseBests
+ *
+ * Returns:
+ * - func(models.Exercise) models.Exercise: Scaler for CreateWorkoutFromTemplate
+ */
+func (r *WorkoutRepository) TemplateScaler(level string, bests map[string]models.ExerciseBest) func(models.Exercise) models.Exercise {
+	return func(e models.Exercise) models.Exercise {
+		library := r.libraryExercise(e.Name)
+		best, ok := bests[strings.ToLower(e.Name)]
+		if !ok && library != nil {
+			best, ok = bests[strings.ToLower(library.Name)]
+		}
+		if !ok {
+			return analytics.ScaleExercise(e, nil, library, level)
+		}
+		return analytics.ScaleExercise(e, &best, library, level)
+	}
+}
+
+/**
+ * ShareWorkout builds the compact shareable form of a user's workout
+ *
+ * Each exercise records the library exercise it matches, if any, so the
+ * importing user gets the library's canonical name and metadata.
+ *
+ * Args:
+ * - ctx: Context for the operation
+ * - userID: Owner of the workout
+ * - id: ID of the workout to share
+ *
+ * Returns:
+ * - *models.SharedWorkout: Workout ready to encode
+ * - error: ErrWorkoutNotFound if the user has no such workout
+ */
+func (r *WorkoutRepository) ShareWorkout(ctx context.Context, userID, id string) (*models.SharedWorkout, error) {
+	workout, err := r.GetWorkout(ctx, userID, id)
+	if err != nil {
+		return nil, ErrWorkoutNotFound
+	}
+	shared := &models.SharedWorkout{
+		Name:          workout.Name,
+		TargetMinutes: workout.TargetDurationMinutes,
+		Exercises:     make([]models.SharedExercise, 0, len(workout.Exercises)),
+	}
+	for _, e := range workout.Exercises {
+		se := models.SharedExercise{
+			Name:       e.Name,
+			Sets:       e.Sets,
+			Reps:       e.Reps,
+			Weight:     e.Weight,
+			Unilateral: e.Unilateral,
+		}
+		if e.IsTimed() {
+			se.Reps, se.DurationSeconds = 0, e.DurationSeconds
+		}
+		if e.IsCardio() {
+			se.Reps, se.DurationSeconds, se.DistanceMeters, se.Cardio = 0, e.DurationSeconds, e.DistanceMeters, true
+		}
+		if t := r.libraryExercise(e.Name); t != nil {
+			se.Library = t.Name
+		}
+		shared.Exercises = append(shared.Exercises, se)
+	}
+	return shared, nil
+}
+
+/**
+ * LastSessionNotes returns the set notes from the user's most recent completed
+ * session of a workout, by workout exercise ID, in the order they were logged
+ *
+ * Args:
+ * - ctx: Context for the operation
+ * - userID: Owner of the workout
+ * - workoutID: Workout whose last session to read
+ *
+ * Returns:
+ * - map[string][]string: Notes by exercise ID; empty if the workout was never completed
+ * - error: Database error if any
+ */
+func (r *WorkoutRepository) LastSessionNotes(ctx context.Context, userID, workoutID string) (map[string][]string, error) {
+	query := `
+		SELECT se.exercise_id, es.notes
+		FROM exercise_sets es
+		JOIN session_exercises se ON se.id = es.session_exercise_id
+		WHERE se.session_id = (
+			SELECT id FROM workout_sessions
+			WHERE user_id = $1 AND workout_id = $2 AND is_active = false AND ended_at IS NOT NULL
+			ORDER BY ended_at DESC LIMIT 1
+		) AND es.notes IS NOT NULL AND es.notes <> ''
+		ORDER BY es.created_at
+	`
+	notes := map[string][]string{}
+	err := eachRow(ctx, r.db, r.sqlite, r.useSQLite, query, []interface{}{userID, workoutID}, func(scan func(...interface{}) error) error {
+		var exerciseID, note string
+		if err := scan(&exerciseID, &note); err != nil {
+			return err
+		}
+		notes[exerciseID] = append(notes[exerciseID], note)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load last session notes: %w", err)
+	}
+	return notes, nil
+}
+
+/**
+ * ImportSharedWorkout creates a copy of a shared workout for the user
+ *
+ * Exercises are mapped onto the exercise library: by the library name recorded
+ * when shared, falling back to matching the exercise name. Names differing
+ * from the library only in case take the library's spelling; otherwise the
+ * shared name is kept and the match is only reported.
+ *
+ * Args:
+ * - ctx: Context for the operation
+ * - userID: User importing the workout
+ * - shared: Decoded and validated shared workout
+ *
+ * Returns:
+ * - *models.Workout: Created workout with its exercises
+ * - []models.ExerciseMapping: Library match of each exercise, in order
+ * - error: Creation error if any
+ */
+func (r *WorkoutRepository) ImportSharedWorkout(ctx context.Context, userID string, shared *models.SharedWorkout) (*models.Workout, []models.ExerciseMapping, error) {
+	workout, err := r.CreateWorkout(ctx, userID, shared.Name, "", "")
+	if err != nil {
+		return nil, nil, err
+	}
+	if shared.TargetMinutes != nil {
+		if err := r.SetWorkoutTargetDuration(ctx, userID, workout.ID, shared.TargetMinutes); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	mappings := make([]models.ExerciseMapping, 0, len(shared.Exercises))
+	for _, se := range shared.Exercises {
+		name := strings.TrimSpace(se.Name)
+		t := r.libraryExercise(se.Library)
+		if t == nil {
+			t = r.libraryExercise(name)
+		}
+		mapping := models.ExerciseMapping{Name: name}
+		if t != nil {
+			mapping.Library = t.Name
+			if strings.EqualFold(t.Name, name) {
+				name = t.Name
+			}
+		}
+		mappings = append(mappings, mapping)
+
+		exercise := &models.Exercise{
+			Name:       name,
+			Sets:       se.Sets,
+			Reps:       se.Reps,
+			Weight:     se.Weight,
+			Mode:       models.ExerciseModeReps,
+			Unilateral: se.Unilateral,
+			WorkoutID:  workout.ID,
+		}
+		if se.Cardio {
+			exercise.Mode, exercise.Reps, exercise.DurationSeconds, exercise.DistanceMeters = models.ExerciseModeCardio, 0, se.DurationSeconds, se.DistanceMeters
+		} else if se.DurationSeconds > 0 {
+			exercise.Mode, exercise.Reps, exercise.DurationSeconds = models.ExerciseModeDuration, 0, se.DurationSeconds
+		}
+		if err := r.CreateExercise(ctx, userID, exercise); err != nil {
+			return nil, nil, fmt.Errorf("failed to create exercise %s: %w", exercise.Name, err)
+		}
+	}
+
+	workout, err = r.GetWorkout(ctx, userID, workout.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return workout, mappings, nil
+}
+
 /**
  * CreateDinoGameScore creates a new dino game score in the database
  */
 func (r *WorkoutRepository) CreateDinoGameScore(ctx context.Context, userID string, score int) (*models.DinoGameScore, error) {
-	id := uuid.New().String()
+	id := ids.New()
 	now := time.Now()
 
 	if r.useSQLite {
@@ -1138,3 +1781,92 @@ func (r *WorkoutRepository) getDinoGameHighScoreSQLite(ctx context.Context, user
 
 	return highScore, nil
 }
+
+/**
+ * ExerciseCategory maps an exercise name to its top-level taxonomy category
+ *
+ * Args:
+ * - tax: Exercise taxonomy from TaxonomyRepository.Load
+ * - name: Exercise name as entered by the user or a template
+ *
+ * Returns:
+ * - string: Category name, or "" if the exercise is not in the library or not filed
+ */
+func (r *WorkoutRepository) ExerciseCategory(tax *models.Taxonomy, name string) string {
+	if t := r.libraryExercise(name); t != nil {
+		if category, _ := tax.Classify(t.Name); category != nil {
+			return category.Name
+		}
+	}
+	return ""
+}
+
+/**
+ * ExerciseRiskFlags maps an exercise name to its library risk flags
+ *
+ * Args:
+ * - name: Exercise name as entered by the user or a template
+ *
+ * Returns:
+ * - []string: Risk flags (models.Risk*), or nil if none are known
+ */
+func (r *WorkoutRepository) ExerciseRiskFlags(name string) []string {
+	if t := r.libraryExercise(name); t != nil {
+		return t.RiskFlags
+	}
+	return nil
+}
+
+/**
+ * LibraryExerciseName returns the library's spelling of an exercise name
+ *
+ * Unlike the category and risk lookups, only exact (case-insensitive) names match.
+ *
+ * Args:
+ * - name: Exercise name to look up
+ *
+ * Returns:
+ * - string: Library exercise name, or "" if the library has no such exercise
+ */
+func (r *WorkoutRepository) LibraryExerciseName(name string) string {
+	for _, t := range r.getPredefinedExerciseTemplates() {
+		if strings.EqualFold(t.Name, strings.TrimSpace(name)) {
+			return t.Name
+		}
+	}
+	return ""
+}
+
+/**
+ * libraryExercise finds the library template matching an exercise name
+ *
+ * Matches the exercise template library case-insensitively, first by exact
+ * name and then by shared trailing word ("Squats" matches "Barbell Squats").
+ *
+ * Args:
+ * - name: Exercise name as entered by the user or a template
+ *
+ * Returns:
+ * - *models.ExerciseTemplate: Matching template, or nil if none
+ */
+func (r *WorkoutRepository) libraryExercise(name string) *models.ExerciseTemplate {
+	needle := strings.ToLower(strings.TrimSpace(name))
+	if needle == "" {
+		return nil
+	}
+	library := r.getPredefinedExerciseTemplates()
+	for _, t := range library {
+		if strings.ToLower(t.Name) == needle {
+			return t
+		}
+	}
+	words := strings.Fields(needle)
+	last := words[len(words)-1]
+	for _, t := range library {
+		libWords := strings.Fields(strings.ToLower(t.Name))
+		if libWords[len(libWords)-1] == last {
+			return t
+		}
+	}
+	return nil
+}
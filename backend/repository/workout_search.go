@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"liftoff/backend/models"
+)
+
+// Search scores, best first
+const (
+	searchScoreExact    = 4 // workout name equals the query
+	searchScorePrefix   = 3 // workout name starts with it
+	searchScoreContains = 2 // workout name contains it
+	searchScoreExercise = 1 // only an exercise name matches
+)
+
+// SearchWorkouts finds the user's unarchived workouts whose name contains q,
+// ignoring case, and with withExercises also those with an exercise whose name
+// does. Results are ranked exact match, then prefix, then substring, then
+// exercise-only, most recently updated first within a rank, and cut to limit.
+func (r *WorkoutRepository) SearchWorkouts(ctx context.Context, userID, q string, withExercises bool, limit int) ([]models.WorkoutSearchResult, error) {
+	pattern := likePattern(q)
+	var workouts []*models.Workout
+	matched := make(map[string][]string)
+
+	scanWorkout := func(scan func(...interface{}) error) error {
+		var w models.Workout
+		if err := scan(&w.ID, &w.UserID, &w.Name, &w.Type, &w.Notes, &w.TargetDurationMinutes, &w.ArchivedAt, &w.CreatedAt, &w.UpdatedAt); err != nil {
+			return fmt.Errorf("failed to scan workout: %w", err)
+		}
+		workouts = append(workouts, &w)
+		return nil
+	}
+	scanExercise := func(scan func(...interface{}) error) error {
+		var workoutID, name string
+		if err := scan(&workoutID, &name); err != nil {
+			return fmt.Errorf("failed to scan exercise: %w", err)
+		}
+		matched[workoutID] = append(matched[workoutID], name)
+		return nil
+	}
+
+	if r.useSQLite {
+		// SQLite's LIKE already ignores ASCII case
+		rows, err := r.sqlite.QueryContext(ctx, `
+			SELECT id, user_id, name, type, notes, target_duration_minutes, archived_at, created_at, updated_at
+			FROM workouts
+			WHERE user_id = ? AND archived_at IS NULL
+				AND (name LIKE ? ESCAPE '\' OR (? AND EXISTS (
+					SELECT 1 FROM exercises e WHERE e.workout_id = workouts.id AND e.name LIKE ? ESCAPE '\')))`,
+			userID, pattern, withExercises, pattern)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search workouts: %w", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			if err := scanWorkout(rows.Scan); err != nil {
+				return nil, err
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		if withExercises {
+			rows, err := r.sqlite.QueryContext(ctx, `
+				SELECT e.workout_id, e.name FROM exercises e JOIN workouts w ON w.id = e.workout_id
+				WHERE w.user_id = ? AND w.archived_at IS NULL AND e.name LIKE ? ESCAPE '\'
+				ORDER BY e.position, e.created_at`,
+				userID, pattern)
+			if err != nil {
+				return nil, fmt.Errorf("failed to search exercises: %w", err)
+			}
+			defer rows.Close()
+			for rows.Next() {
+				if err := scanExercise(rows.Scan); err != nil {
+					return nil, err
+				}
+			}
+			if err := rows.Err(); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		rows, err := r.db.Query(ctx, `
+			SELECT id, user_id, name, type, notes, target_duration_minutes, archived_at, created_at, updated_at
+			FROM workouts
+			WHERE user_id = $1 AND archived_at IS NULL
+				AND (name ILIKE $2 ESCAPE '\' OR ($3 AND EXISTS (
+					SELECT 1 FROM exercises e WHERE e.workout_id = workouts.id AND e.name ILIKE $2 ESCAPE '\')))`,
+			userID, pattern, withExercises)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search workouts: %w", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			if err := scanWorkout(rows.Scan); err != nil {
+				return nil, err
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		if withExercises {
+			rows, err := r.db.Query(ctx, `
+				SELECT e.workout_id, e.name FROM exercises e JOIN workouts w ON w.id = e.workout_id
+				WHERE w.user_id = $1 AND w.archived_at IS NULL AND e.name ILIKE $2 ESCAPE '\'
+				ORDER BY e.position, e.created_at`,
+				userID, pattern)
+			if err != nil {
+				return nil, fmt.Errorf("failed to search exercises: %w", err)
+			}
+			defer rows.Close()
+			for rows.Next() {
+				if err := scanExercise(rows.Scan); err != nil {
+					return nil, err
+				}
+			}
+			if err := rows.Err(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := r.attachWorkoutTags(ctx, userID, workouts); err != nil {
+		return nil, err
+	}
+	results := make([]models.WorkoutSearchResult, 0, len(workouts))
+	for _, w := range workouts {
+		results = append(results, models.WorkoutSearchResult{
+			Workout:          w,
+			Score:            searchScore(w.Name, q),
+			MatchedExercises: matched[w.ID],
+		})
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Workout.UpdatedAt.After(results[j].Workout.UpdatedAt)
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// searchScore ranks how well a workout name matches q
+func searchScore(name, q string) int {
+	name, q = strings.ToLower(name), strings.ToLower(q)
+	switch {
+	case name == q:
+		return searchScoreExact
+	case strings.HasPrefix(name, q):
+		return searchScorePrefix
+	case strings.Contains(name, q):
+		return searchScoreContains
+	default:
+		return searchScoreExercise
+	}
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"liftoff/backend/models"
+)
+
+// ErrTooManyTags is returned when tagging a workout that already has MaxWorkoutTags tags
+var ErrTooManyTags = fmt.Errorf("a workout can have at most %d tags", models.MaxWorkoutTags)
+
+// AddWorkoutTag tags one of the user's workouts. Adding a tag it already has does
+// nothing. The tag must already be normalized (see models.NormalizeTag).
+func (r *WorkoutRepository) AddWorkoutTag(ctx context.Context, userID, workoutID, tag string) error {
+	if err := r.checkWorkoutOwner(ctx, userID, workoutID); err != nil {
+		return err
+	}
+	tags, err := r.getWorkoutTags(ctx, workoutID)
+	if err != nil {
+		return err
+	}
+	for _, t := range tags {
+		if t == tag {
+			return nil
+		}
+	}
+	if len(tags) >= models.MaxWorkoutTags {
+		return ErrTooManyTags
+	}
+
+	if r.useSQLite {
+		_, err = r.sqlite.ExecContext(ctx, `INSERT INTO workout_tags (workout_id, tag, created_at) VALUES (?, ?, ?) ON CONFLICT DO NOTHING`,
+			workoutID, tag, time.Now())
+	} else {
+		_, err = r.db.Exec(ctx, `INSERT INTO workout_tags (workout_id, tag, created_at) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`,
+			workoutID, tag, time.Now())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to tag workout: %w", err)
+	}
+	return nil
+}
+
+// RemoveWorkoutTag removes a tag from one of the user's workouts, if it has it
+func (r *WorkoutRepository) RemoveWorkoutTag(ctx context.Context, userID, workoutID, tag string) error {
+	if err := r.checkWorkoutOwner(ctx, userID, workoutID); err != nil {
+		return err
+	}
+	var err error
+	if r.useSQLite {
+		_, err = r.sqlite.ExecContext(ctx, `DELETE FROM workout_tags WHERE workout_id = ? AND tag = ?`, workoutID, tag)
+	} else {
+		_, err = r.db.Exec(ctx, `DELETE FROM workout_tags WHERE workout_id = $1 AND tag = $2`, workoutID, tag)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to untag workout: %w", err)
+	}
+	return nil
+}
+
+// checkWorkoutOwner returns ErrWorkoutNotFound unless the user owns the workout
+func (r *WorkoutRepository) checkWorkoutOwner(ctx context.Context, userID, workoutID string) error {
+	var owned int
+	var err error
+	if r.useSQLite {
+		err = r.sqlite.QueryRowContext(ctx, `SELECT COUNT(*) FROM workouts WHERE id = ? AND user_id = ?`, workoutID, userID).Scan(&owned)
+	} else {
+		err = r.db.QueryRow(ctx, `SELECT COUNT(*) FROM workouts WHERE id = $1 AND user_id = $2`, workoutID, userID).Scan(&owned)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get workout: %w", err)
+	}
+	if owned == 0 {
+		return ErrWorkoutNotFound
+	}
+	return nil
+}
+
+// getWorkoutTags lists a workout's tags alphabetically
+func (r *WorkoutRepository) getWorkoutTags(ctx context.Context, workoutID string) ([]string, error) {
+	byWorkout, err := r.queryWorkoutTags(ctx,
+		`SELECT workout_id, tag FROM workout_tags WHERE workout_id = $1 ORDER BY tag`, workoutID)
+	if err != nil {
+		return nil, err
+	}
+	if tags := byWorkout[workoutID]; tags != nil {
+		return tags, nil
+	}
+	return []string{}, nil
+}
+
+// attachWorkoutTags fills in Tags on each of the user's workouts with one query
+func (r *WorkoutRepository) attachWorkoutTags(ctx context.Context, userID string, workouts []*models.Workout) error {
+	byWorkout, err := r.queryWorkoutTags(ctx, `
+		SELECT wt.workout_id, wt.tag FROM workout_tags wt
+		JOIN workouts w ON w.id = wt.workout_id
+		WHERE w.user_id = $1
+		ORDER BY wt.tag`, userID)
+	if err != nil {
+		return err
+	}
+	for _, w := range workouts {
+		w.Tags = byWorkout[w.ID]
+		if w.Tags == nil {
+			w.Tags = []string{}
+		}
+	}
+	return nil
+}
+
+// queryWorkoutTags runs a query selecting (workout_id, tag) with a single $1 argument
+func (r *WorkoutRepository) queryWorkoutTags(ctx context.Context, query, arg string) (map[string][]string, error) {
+	byWorkout := make(map[string][]string)
+	add := func(scan func(...interface{}) error) error {
+		var workoutID, tag string
+		if err := scan(&workoutID, &tag); err != nil {
+			return fmt.Errorf("failed to scan workout tag: %w", err)
+		}
+		byWorkout[workoutID] = append(byWorkout[workoutID], tag)
+		return nil
+	}
+
+	if r.useSQLite {
+		rows, err := r.sqlite.QueryContext(ctx, rebind(query, 1), arg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get workout tags: %w", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			if err := add(rows.Scan); err != nil {
+				return nil, err
+			}
+		}
+		return byWorkout, rows.Err()
+	}
+
+	rows, err := r.db.Query(ctx, query, arg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workout tags: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := add(rows.Scan); err != nil {
+			return nil, err
+		}
+	}
+	return byWorkout, rows.Err()
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"liftoff/backend/ids"
+	"liftoff/backend/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ShareWorkoutTemplate publishes one of the user's saved templates under a share
+// token, given as its SHA-256 hash. Sharing again replaces the token, so older
+// links stop working. Built-in templates cannot be shared.
+func (r *WorkoutRepository) ShareWorkoutTemplate(ctx context.Context, userID, templateID, tokenHash string) error {
+	return r.setWorkoutTemplateShare(ctx, userID, templateID, &tokenHash)
+}
+
+// UnshareWorkoutTemplate withdraws the share link of one of the user's templates
+func (r *WorkoutRepository) UnshareWorkoutTemplate(ctx context.Context, userID, templateID string) error {
+	return r.setWorkoutTemplateShare(ctx, userID, templateID, nil)
+}
+
+func (r *WorkoutRepository) setWorkoutTemplateShare(ctx context.Context, userID, templateID string, tokenHash *string) error {
+	var affected int64
+	if r.useSQLite {
+		result, err := r.sqlite.ExecContext(ctx, `UPDATE workout_templates SET share_token_hash = ? WHERE id = ? AND user_id = ?`,
+			tokenHash, templateID, userID)
+		if err != nil {
+			return fmt.Errorf("failed to share workout template: %w", err)
+		}
+		affected, _ = result.RowsAffected()
+	} else {
+		tag, err := r.db.Exec(ctx, `UPDATE workout_templates SET share_token_hash = $1 WHERE id = $2 AND user_id = $3`,
+			tokenHash, templateID, userID)
+		if err != nil {
+			return fmt.Errorf("failed to share workout template: %w", err)
+		}
+		affected = tag.RowsAffected()
+	}
+	if affected == 0 {
+		return ErrWorkoutTemplateNotFound
+	}
+	return nil
+}
+
+// GetSharedWorkoutTemplate returns the template published under a share token,
+// given as its SHA-256 hash, or ErrWorkoutTemplateNotFound
+func (r *WorkoutRepository) GetSharedWorkoutTemplate(ctx context.Context, tokenHash string) (*models.WorkoutTemplate, error) {
+	var id, ownerID string
+	var err error
+	if r.useSQLite {
+		err = r.sqlite.QueryRowContext(ctx, `SELECT id, user_id FROM workout_templates WHERE share_token_hash = ? AND user_id IS NOT NULL`,
+			tokenHash).Scan(&id, &ownerID)
+	} else {
+		err = r.db.QueryRow(ctx, `SELECT id, user_id FROM workout_templates WHERE share_token_hash = $1 AND user_id IS NOT NULL`,
+			tokenHash).Scan(&id, &ownerID)
+	}
+	if errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrWorkoutTemplateNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shared workout template: %w", err)
+	}
+	return r.GetWorkoutTemplate(ctx, ownerID, id)
+}
+
+// CloneWorkoutTemplate copies a template, such as a shared one, into the user's
+// own templates. The copy is not shared.
+func (r *WorkoutRepository) CloneWorkoutTemplate(ctx context.Context, userID string, source *models.WorkoutTemplate) (*models.WorkoutTemplate, error) {
+	clone := *source
+	clone.ID = ids.New()
+	clone.Custom = true
+	clone.CreatedAt = time.Now()
+	clone.Exercises = append([]models.Exercise(nil), source.Exercises...)
+	if err := r.insertWorkoutTemplate(ctx, userID, &clone); err != nil {
+		return nil, err
+	}
+	return &clone, nil
+}
@@ -0,0 +1,858 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"liftoff/backend/database"
+	"liftoff/backend/models"
+)
+
+func TestUpdateWorkout_SQLite(t *testing.T) {
+	db, err := database.NewMockDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	repo := NewWorkoutRepository(nil, db.GetSQLite(), true)
+	ctx := context.Background()
+
+	created, err := repo.CreateWorkout(ctx, database.DemoUserID, "Push Day", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Type != "" {
+		t.Errorf("new workout type = %q, want uncategorized", created.Type)
+	}
+
+	name, workoutType := "Upper Body", models.WorkoutTypeStrength
+	if err := repo.UpdateWorkout(ctx, database.DemoUserID, created.ID, &name, &workoutType, nil); err != nil {
+		t.Fatalf("UpdateWorkout: %v", err)
+	}
+	// Changing only the type and notes keeps the name
+	workoutType, notes := models.WorkoutTypePower, "Focus on pause reps this block"
+	if err := repo.UpdateWorkout(ctx, database.DemoUserID, created.ID, nil, &workoutType, &notes); err != nil {
+		t.Fatalf("UpdateWorkout type only: %v", err)
+	}
+	got, err := repo.GetWorkout(ctx, database.DemoUserID, created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "Upper Body" || got.Type != models.WorkoutTypePower || got.Notes != notes {
+		t.Errorf("workout = %q/%q/%q, want Upper Body/power with notes", got.Name, got.Type, got.Notes)
+	}
+
+	err = repo.UpdateWorkout(ctx, "someone-else", created.ID, &name, nil, nil)
+	if !errors.Is(err, ErrWorkoutNotFound) {
+		t.Errorf("other user's update: err = %v, want ErrWorkoutNotFound", err)
+	}
+}
+
+func TestUpdateExercise_SQLite(t *testing.T) {
+	db, err := database.NewMockDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	repo := NewWorkoutRepository(nil, db.GetSQLite(), true)
+	ctx := context.Background()
+
+	workout, err := repo.CreateWorkout(ctx, database.DemoUserID, "Legs", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exercise := &models.Exercise{Name: "Squat", Sets: 3, Reps: 5, Weight: 100, Mode: models.ExerciseModeReps, WorkoutID: workout.ID}
+	if err := repo.CreateExercise(ctx, database.DemoUserID, exercise); err != nil {
+		t.Fatal(err)
+	}
+
+	rpe := 8.5
+	exercise.Sets, exercise.Reps, exercise.Weight, exercise.RestSeconds = 5, 3, 110, 180
+	exercise.TargetRPE, exercise.Tempo = &rpe, "3-1-X-0"
+	exercise.VideoURL = "https://example.com/squat.mp4"
+	if err := repo.UpdateExercise(ctx, database.DemoUserID, exercise); err != nil {
+		t.Fatalf("UpdateExercise: %v", err)
+	}
+	got, err := repo.GetExercise(ctx, exercise.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Sets != 5 || got.Reps != 3 || got.Weight != 110 || got.RestSeconds != 180 || got.WorkoutID != workout.ID {
+		t.Errorf("exercise = %+v", got)
+	}
+	if got.TargetRPE == nil || *got.TargetRPE != 8.5 || got.Tempo != "3-1-X-0" {
+		t.Errorf("target_rpe = %v, tempo = %q", got.TargetRPE, got.Tempo)
+	}
+	if got.VideoURL != "https://example.com/squat.mp4" || got.ImageURL != "" {
+		t.Errorf("video_url = %q, image_url = %q", got.VideoURL, got.ImageURL)
+	}
+
+	// Sets record the RPE actually reached
+	sessions := NewSessionRepository(nil, db.GetSQLite(), true)
+	session, err := sessions.CreateSession(ctx, database.DemoUserID, workout.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	se, err := sessions.CreateSessionExercise(ctx, database.DemoUserID, session.ID, exercise.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual := 9.0
+	set := &models.ExerciseSet{SessionExerciseID: se.ID, Reps: 3, Weight: 110, ActualRPE: &actual}
+	if err := sessions.CreateExerciseSet(ctx, database.DemoUserID, set); err != nil {
+		t.Fatal(err)
+	}
+	sets, err := sessions.GetExerciseSets(ctx, se.ID)
+	if err != nil || len(sets) != 1 || sets[0].ActualRPE == nil || *sets[0].ActualRPE != 9 {
+		t.Errorf("sets = %+v, %v", sets, err)
+	}
+
+	if err := repo.UpdateExercise(ctx, "someone-else", exercise); !errors.Is(err, ErrExerciseNotFound) {
+		t.Errorf("other user's update: err = %v, want ErrExerciseNotFound", err)
+	}
+}
+
+func TestReorderExercises_SQLite(t *testing.T) {
+	db, err := database.NewMockDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	repo := NewWorkoutRepository(nil, db.GetSQLite(), true)
+	ctx := context.Background()
+
+	workout, err := repo.CreateWorkout(ctx, database.DemoUserID, "Pull", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for i, name := range []string{"Deadlift", "Row", "Curl"} {
+		e := &models.Exercise{Name: name, Sets: 3, Reps: 8, WorkoutID: workout.ID}
+		if err := repo.CreateExercise(ctx, database.DemoUserID, e); err != nil {
+			t.Fatal(err)
+		}
+		if e.Position != i+1 {
+			t.Errorf("%s position = %d, want %d", name, e.Position, i+1)
+		}
+		ids = append(ids, e.ID)
+	}
+
+	if err := repo.ReorderExercises(ctx, database.DemoUserID, workout.ID, []string{ids[2], ids[0], ids[1]}); err != nil {
+		t.Fatalf("ReorderExercises: %v", err)
+	}
+	got, err := repo.GetExercisesByWorkout(ctx, workout.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range got {
+		names = append(names, e.Name)
+	}
+	if len(names) != 3 || names[0] != "Curl" || names[1] != "Deadlift" || names[2] != "Row" || got[0].Position != 1 {
+		t.Errorf("order = %v", names)
+	}
+
+	for name, order := range map[string][]string{
+		"missing":   {ids[0], ids[1]},
+		"duplicate": {ids[0], ids[0], ids[1]},
+		"foreign":   {ids[0], ids[1], "not-an-exercise"},
+	} {
+		if err := repo.ReorderExercises(ctx, database.DemoUserID, workout.ID, order); !errors.Is(err, ErrInvalidExerciseOrder) {
+			t.Errorf("%s: err = %v, want ErrInvalidExerciseOrder", name, err)
+		}
+	}
+	if err := repo.ReorderExercises(ctx, "someone-else", workout.ID, ids); !errors.Is(err, ErrWorkoutNotFound) {
+		t.Errorf("other user's reorder: err = %v, want ErrWorkoutNotFound", err)
+	}
+}
+
+func TestCreateExercises_SQLite(t *testing.T) {
+	db, err := database.NewMockDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	repo := NewWorkoutRepository(nil, db.GetSQLite(), true)
+	ctx := context.Background()
+
+	workout, err := repo.CreateWorkout(ctx, database.DemoUserID, "Legs", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.CreateExercise(ctx, database.DemoUserID, &models.Exercise{Name: "Squat", Sets: 5, Reps: 5, WorkoutID: workout.ID}); err != nil {
+		t.Fatal(err)
+	}
+	batch := []*models.Exercise{
+		{Name: "Lunge", Sets: 3, Reps: 10, Weight: 40},
+		{Name: "Wall Sit", Sets: 3, Mode: models.ExerciseModeDuration, DurationSeconds: 45},
+	}
+	if err := repo.CreateExercises(ctx, database.DemoUserID, workout.ID, batch); err != nil {
+		t.Fatalf("CreateExercises: %v", err)
+	}
+	if batch[0].ID == "" || batch[0].Position != 2 || batch[1].Position != 3 || batch[0].Mode != models.ExerciseModeReps || batch[1].WorkoutID != workout.ID {
+		t.Errorf("created = %+v, %+v", batch[0], batch[1])
+	}
+	got, err := repo.GetExercisesByWorkout(ctx, workout.ID)
+	if err != nil || len(got) != 3 || got[2].Name != "Wall Sit" || got[2].DurationSeconds != 45 {
+		t.Errorf("exercises = %+v, %v", got, err)
+	}
+
+	if err := repo.CreateExercises(ctx, "someone-else", workout.ID, []*models.Exercise{{Name: "Curl", Sets: 3, Reps: 8}}); !errors.Is(err, ErrWorkoutNotFound) {
+		t.Errorf("other user's workout: err = %v, want ErrWorkoutNotFound", err)
+	}
+}
+
+func TestCardioExercises_SQLite(t *testing.T) {
+	db, err := database.NewMockDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	repo := NewWorkoutRepository(nil, db.GetSQLite(), true)
+	sessions := NewSessionRepository(nil, db.GetSQLite(), true)
+	ctx := context.Background()
+
+	workout, err := repo.CreateWorkout(ctx, database.DemoUserID, "Long Run", models.WorkoutTypeEndurance, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	run := &models.Exercise{Name: "Running", Sets: 1, Mode: models.ExerciseModeCardio, DurationSeconds: 1800, DistanceMeters: 5000, WorkoutID: workout.ID}
+	if err := run.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.CreateExercise(ctx, database.DemoUserID, run); err != nil {
+		t.Fatal(err)
+	}
+	got, err := repo.GetExercise(ctx, run.ID)
+	if err != nil || got.Mode != models.ExerciseModeCardio || got.DistanceMeters != 5000 || got.DurationSeconds != 1800 {
+		t.Fatalf("stored cardio exercise = %+v, %v", got, err)
+	}
+
+	// Sessions start with the targets filled in, and log the distance actually covered
+	session, err := sessions.CreateSessionWithExercises(ctx, database.DemoUserID, workout.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := session.Exercises[0].Sets[0]
+	if set.DurationSeconds == nil || *set.DurationSeconds != 1800 || set.DistanceMeters == nil || *set.DistanceMeters != 5000 {
+		t.Fatalf("pre-filled set = %+v", set)
+	}
+	covered := 5400
+	set.DistanceMeters, set.Completed = &covered, true
+	if err := sessions.UpdateExerciseSet(ctx, database.DemoUserID, set); err != nil {
+		t.Fatal(err)
+	}
+	progress, err := sessions.GetProgressData(ctx, database.DemoUserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, p := range progress {
+		if p["exerciseName"] == "Running" {
+			found = p["mode"] == models.ExerciseModeCardio && p["totalDistance"] == 5400 && p["maxDuration"] == 1800
+		}
+	}
+	if !found {
+		t.Errorf("progress has no cardio entry for Running: %v", progress)
+	}
+}
+
+func TestExerciseGroups_SQLite(t *testing.T) {
+	db, err := database.NewMockDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	repo := NewWorkoutRepository(nil, db.GetSQLite(), true)
+	ctx := context.Background()
+
+	workout, err := repo.CreateWorkout(ctx, database.DemoUserID, "Arms", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, name := range []string{"Curl", "Dip", "Pushdown", "Hammer Curl"} {
+		e := &models.Exercise{Name: name, Sets: 3, Reps: 10, WorkoutID: workout.ID}
+		if err := repo.CreateExercise(ctx, database.DemoUserID, e); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, e.ID)
+	}
+	order := func() string {
+		got, err := repo.GetExercisesByWorkout(ctx, workout.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, e := range got {
+			name := e.Name
+			if e.GroupID != "" {
+				name += "*"
+			}
+			names = append(names, name)
+		}
+		return strings.Join(names, ",")
+	}
+
+	// Curl and Hammer Curl become a superset and move next to each other
+	groupID, err := repo.GroupExercises(ctx, database.DemoUserID, workout.ID, models.ExerciseGroupSuperset, []string{ids[0], ids[3]})
+	if err != nil {
+		t.Fatalf("GroupExercises: %v", err)
+	}
+	if got := order(); got != "Curl*,Hammer Curl*,Dip,Pushdown" {
+		t.Errorf("after grouping: %s", got)
+	}
+
+	// Reordering keeps the group contiguous around its first member
+	if err := repo.ReorderExercises(ctx, database.DemoUserID, workout.ID, []string{ids[1], ids[0], ids[2], ids[3]}); err != nil {
+		t.Fatal(err)
+	}
+	if got := order(); got != "Dip,Curl*,Hammer Curl*,Pushdown" {
+		t.Errorf("after reorder: %s", got)
+	}
+
+	for name, members := range map[string][]string{
+		"single":    {ids[1]},
+		"duplicate": {ids[1], ids[1]},
+		"foreign":   {ids[1], "not-an-exercise"},
+	} {
+		if _, err := repo.GroupExercises(ctx, database.DemoUserID, workout.ID, models.ExerciseGroupCircuit, members); !errors.Is(err, ErrInvalidExerciseGroup) {
+			t.Errorf("%s: err = %v, want ErrInvalidExerciseGroup", name, err)
+		}
+	}
+
+	// Deleting a member of a two-exercise group dissolves it
+	if err := repo.DeleteExercise(ctx, database.DemoUserID, ids[3]); err != nil {
+		t.Fatal(err)
+	}
+	if got := order(); got != "Dip,Curl,Pushdown" {
+		t.Errorf("after delete: %s", got)
+	}
+
+	groupID, err = repo.GroupExercises(ctx, database.DemoUserID, workout.ID, models.ExerciseGroupCircuit, []string{ids[1], ids[0], ids[2]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.UngroupExercises(ctx, database.DemoUserID, workout.ID, groupID); err != nil {
+		t.Fatalf("UngroupExercises: %v", err)
+	}
+	if got := order(); got != "Dip,Curl,Pushdown" {
+		t.Errorf("after ungrouping: %s", got)
+	}
+	if err := repo.UngroupExercises(ctx, database.DemoUserID, workout.ID, groupID); !errors.Is(err, ErrExerciseGroupNotFound) {
+		t.Errorf("ungrouping twice: err = %v, want ErrExerciseGroupNotFound", err)
+	}
+}
+
+func TestArchiveWorkout_SQLite(t *testing.T) {
+	db, err := database.NewMockDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	repo := NewWorkoutRepository(nil, db.GetSQLite(), true)
+	ctx := context.Background()
+
+	listed := func(includeArchived bool, id string) bool {
+		workouts, err := repo.GetWorkouts(ctx, database.DemoUserID, WorkoutFilter{IncludeArchived: includeArchived})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, w := range workouts {
+			if w.ID == id {
+				return true
+			}
+		}
+		return false
+	}
+
+	workout, err := repo.CreateWorkout(ctx, database.DemoUserID, "Old Program", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.SetWorkoutArchived(ctx, database.DemoUserID, workout.ID, true); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	got, err := repo.GetWorkout(ctx, database.DemoUserID, workout.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ArchivedAt == nil {
+		t.Fatal("archived_at not set")
+	}
+	// Archiving again keeps the original time
+	first := *got.ArchivedAt
+	if err := repo.SetWorkoutArchived(ctx, database.DemoUserID, workout.ID, true); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ = repo.GetWorkout(ctx, database.DemoUserID, workout.ID); got.ArchivedAt == nil || !got.ArchivedAt.Equal(first) {
+		t.Errorf("archived_at changed from %v to %v", first, got.ArchivedAt)
+	}
+	if listed(false, workout.ID) || !listed(true, workout.ID) {
+		t.Error("archived workout should only be listed with includeArchived")
+	}
+
+	if err := repo.SetWorkoutArchived(ctx, database.DemoUserID, workout.ID, false); err != nil {
+		t.Fatalf("unarchive: %v", err)
+	}
+	if !listed(false, workout.ID) {
+		t.Error("unarchived workout missing from list")
+	}
+	if err := repo.SetWorkoutArchived(ctx, "someone-else", workout.ID, true); !errors.Is(err, ErrWorkoutNotFound) {
+		t.Errorf("other user's archive: err = %v, want ErrWorkoutNotFound", err)
+	}
+}
+
+func TestWorkoutTags_SQLite(t *testing.T) {
+	db, err := database.NewMockDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	repo := NewWorkoutRepository(nil, db.GetSQLite(), true)
+	ctx := context.Background()
+
+	legs, err := repo.CreateWorkout(ctx, database.DemoUserID, "Leg Day", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tag := range []string{"legs", "strength", "legs"} {
+		if err := repo.AddWorkoutTag(ctx, database.DemoUserID, legs.ID, tag); err != nil {
+			t.Fatalf("AddWorkoutTag(%s): %v", tag, err)
+		}
+	}
+	got, err := repo.GetWorkout(ctx, database.DemoUserID, legs.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Tags) != 2 || got.Tags[0] != "legs" || got.Tags[1] != "strength" {
+		t.Errorf("tags = %v, want [legs strength]", got.Tags)
+	}
+
+	workouts, err := repo.GetWorkouts(ctx, database.DemoUserID, WorkoutFilter{Tag: "legs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(workouts) != 1 || workouts[0].ID != legs.ID || len(workouts[0].Tags) != 2 {
+		t.Errorf("?tag=legs returned %+v", workouts)
+	}
+	// Untagged workouts are listed with an empty tag list
+	all, err := repo.GetWorkouts(ctx, database.DemoUserID, WorkoutFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) < 2 {
+		t.Fatalf("expected seeded workouts too, got %d", len(all))
+	}
+	for _, w := range all {
+		if w.Tags == nil {
+			t.Errorf("workout %s has nil tags", w.Name)
+		}
+	}
+
+	if err := repo.RemoveWorkoutTag(ctx, database.DemoUserID, legs.ID, "legs"); err != nil {
+		t.Fatal(err)
+	}
+	if workouts, _ = repo.GetWorkouts(ctx, database.DemoUserID, WorkoutFilter{Tag: "legs"}); len(workouts) != 0 {
+		t.Errorf("untagged workout still listed under legs")
+	}
+	if err := repo.AddWorkoutTag(ctx, "someone-else", legs.ID, "legs"); !errors.Is(err, ErrWorkoutNotFound) {
+		t.Errorf("other user's tag: err = %v, want ErrWorkoutNotFound", err)
+	}
+}
+
+func TestGetWorkouts_TypeFilter(t *testing.T) {
+	db, err := database.NewMockDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	repo := NewWorkoutRepository(nil, db.GetSQLite(), true)
+	ctx := context.Background()
+
+	run, err := repo.CreateWorkout(ctx, database.DemoUserID, "Tempo Run", models.WorkoutTypeCardio, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if run.Type != models.WorkoutTypeCardio {
+		t.Errorf("created type = %q", run.Type)
+	}
+	workouts, err := repo.GetWorkouts(ctx, database.DemoUserID, WorkoutFilter{Type: models.WorkoutTypeCardio})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(workouts) != 1 || workouts[0].ID != run.ID || workouts[0].Type != models.WorkoutTypeCardio {
+		t.Errorf("?type=cardio returned %+v", workouts)
+	}
+	if workouts, _ = repo.GetWorkouts(ctx, database.DemoUserID, WorkoutFilter{Type: models.WorkoutTypeHIIT}); len(workouts) != 0 {
+		t.Errorf("?type=hiit returned %d workouts", len(workouts))
+	}
+}
+
+func TestGetWorkouts_SortAndPage(t *testing.T) {
+	db, err := database.NewMockDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	repo := NewWorkoutRepository(nil, db.GetSQLite(), true)
+	ctx := context.Background()
+
+	all, err := repo.GetWorkouts(ctx, database.DemoUserID, WorkoutFilter{Sort: WorkoutSortName})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) < 3 {
+		t.Fatalf("expected seeded workouts, got %d", len(all))
+	}
+	for i := 1; i < len(all); i++ {
+		if strings.ToLower(all[i-1].Name) > strings.ToLower(all[i].Name) {
+			t.Errorf("not sorted by name: %q before %q", all[i-1].Name, all[i].Name)
+		}
+	}
+
+	// Pages of two cover the list exactly once
+	var paged []string
+	for offset := 0; offset < len(all); offset += 2 {
+		page, err := repo.GetWorkouts(ctx, database.DemoUserID, WorkoutFilter{Sort: WorkoutSortName, Limit: 2, Offset: offset})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, w := range page {
+			paged = append(paged, w.ID)
+		}
+	}
+	if len(paged) != len(all) {
+		t.Fatalf("paged %d workouts, want %d", len(paged), len(all))
+	}
+	for i := range all {
+		if paged[i] != all[i].ID {
+			t.Errorf("page order differs at %d", i)
+		}
+	}
+
+	desc := true
+	reversed, err := repo.GetWorkouts(ctx, database.DemoUserID, WorkoutFilter{Sort: WorkoutSortName, Descending: &desc, Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reversed) != 1 || reversed[0].ID != all[len(all)-1].ID {
+		t.Errorf("descending first = %+v, want %s", reversed, all[len(all)-1].Name)
+	}
+
+	total, err := repo.CountWorkouts(ctx, database.DemoUserID, WorkoutFilter{Limit: 1})
+	if err != nil || total != len(all) {
+		t.Errorf("CountWorkouts = %d, %v; want %d", total, err, len(all))
+	}
+}
+
+func TestSearchWorkouts_SQLite(t *testing.T) {
+	db, err := database.NewMockDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	repo := NewWorkoutRepository(nil, db.GetSQLite(), true)
+	ctx := context.Background()
+
+	var ids []string
+	for _, name := range []string{"Sprint Block Extra", "Sprint Block", "Hill sprint block", "Upper 100%"} {
+		w, err := repo.CreateWorkout(ctx, database.DemoUserID, name, "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, w.ID)
+	}
+	press := &models.Exercise{Name: "Sprint Block Drill", Sets: 3, Reps: 10, WorkoutID: ids[3]}
+	if err := repo.CreateExercise(ctx, database.DemoUserID, press); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := repo.SearchWorkouts(ctx, database.DemoUserID, "SPRINT BLOCK", false, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range results {
+		got = append(got, fmt.Sprintf("%s:%d", r.Workout.Name, r.Score))
+	}
+	want := []string{"Sprint Block:4", "Sprint Block Extra:3", "Hill sprint block:2"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("results = %v, want %v", got, want)
+	}
+
+	results, err = repo.SearchWorkouts(ctx, database.DemoUserID, "sprint block", true, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := results[len(results)-1]
+	if len(results) != 4 || last.Workout.ID != ids[3] || last.Score != 1 || len(last.MatchedExercises) != 1 {
+		t.Errorf("exercise match = %+v", last)
+	}
+
+	// Wildcards in the query are literal
+	if results, _ = repo.SearchWorkouts(ctx, database.DemoUserID, "100%", false, 10); len(results) != 1 {
+		t.Errorf("literal %% search returned %d results", len(results))
+	}
+	if results, _ = repo.SearchWorkouts(ctx, database.DemoUserID, "sprint", false, 1); len(results) != 1 {
+		t.Errorf("limit ignored: %d results", len(results))
+	}
+}
+
+func TestExerciseTemplates_SQLite(t *testing.T) {
+	db, err := database.NewMockDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	repo := NewWorkoutRepository(nil, db.GetSQLite(), true)
+	ctx := context.Background()
+	tax := &models.Taxonomy{}
+
+	builtIn, err := repo.GetExerciseTemplates(ctx, "", tax)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sled := &models.ExerciseTemplate{Name: "Sled Push", DefaultSets: 4, DefaultReps: 20, DefaultWeight: 90, DefaultRestSeconds: 120,
+		ExerciseMetadata: models.ExerciseMetadata{PrimaryMuscles: models.StringList{"quadriceps"}, Instructions: "Drive with short steps"}}
+	if err := repo.CreateExerciseTemplate(ctx, database.DemoUserID, sled); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"sled push", "Barbell Squats"} {
+		dup := &models.ExerciseTemplate{Name: name, DefaultSets: 3, DefaultReps: 10}
+		if err := repo.CreateExerciseTemplate(ctx, database.DemoUserID, dup); !errors.Is(err, ErrExerciseTemplateExists) {
+			t.Errorf("create %q: err = %v, want ErrExerciseTemplateExists", name, err)
+		}
+	}
+
+	templates, err := repo.GetExerciseTemplates(ctx, database.DemoUserID, tax)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(templates) != len(builtIn)+1 || templates[len(templates)-1].ID != sled.ID {
+		t.Fatalf("got %d templates, want the %d built-in ones then Sled Push", len(templates), len(builtIn))
+	}
+	if others, _ := repo.GetExerciseTemplates(ctx, "someone-else", tax); len(others) != len(builtIn) {
+		t.Errorf("another user sees %d templates, want %d", len(others), len(builtIn))
+	}
+
+	sled.Name, sled.DefaultSets = "Heavy Sled Push", 5
+	if err := repo.UpdateExerciseTemplate(ctx, database.DemoUserID, sled); err != nil {
+		t.Fatal(err)
+	}
+	got, err := repo.GetExerciseTemplate(ctx, database.DemoUserID, sled.ID)
+	if err != nil || got.Name != "Heavy Sled Push" || got.DefaultSets != 5 || got.DefaultWeight != 90 || got.DefaultRestSeconds != 120 {
+		t.Errorf("after update got %+v, %v", got, err)
+	}
+	if got != nil && (!got.PrimaryMuscles.Contains("quadriceps") || got.Instructions != "Drive with short steps" || got.Equipment != nil) {
+		t.Errorf("metadata = %+v", got.ExerciseMetadata)
+	}
+	if err := repo.UpdateExerciseTemplate(ctx, "someone-else", sled); !errors.Is(err, ErrExerciseTemplateNotFound) {
+		t.Errorf("update by another user: err = %v", err)
+	}
+
+	if err := repo.DeleteExerciseTemplate(ctx, "someone-else", sled.ID); !errors.Is(err, ErrExerciseTemplateNotFound) {
+		t.Errorf("delete by another user: err = %v", err)
+	}
+	if err := repo.DeleteExerciseTemplate(ctx, database.DemoUserID, sled.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.GetExerciseTemplate(ctx, database.DemoUserID, sled.ID); !errors.Is(err, ErrExerciseTemplateNotFound) {
+		t.Errorf("after delete: err = %v", err)
+	}
+}
+
+func TestWorkoutTemplates_SQLite(t *testing.T) {
+	db, err := database.NewMockDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	repo := NewWorkoutRepository(nil, db.GetSQLite(), true)
+	ctx := context.Background()
+
+	templates, err := repo.GetWorkoutTemplates(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(templates) != len(models.DefaultWorkoutTemplates) {
+		t.Fatalf("got %d templates, want the %d seeded", len(templates), len(models.DefaultWorkoutTemplates))
+	}
+	for i, tmpl := range templates {
+		want := models.DefaultWorkoutTemplates[i]
+		if tmpl.ID != want.ID || len(tmpl.Exercises) != len(want.Exercises) || tmpl.Exercises[0].Name != want.Exercises[0].Name {
+			t.Errorf("template %d = %s with %d exercises, want %s with %d", i, tmpl.ID, len(tmpl.Exercises), want.ID, len(want.Exercises))
+		}
+	}
+
+	// Templates added to the tables are picked up without code changes
+	sqlite := db.GetSQLite()
+	if _, err := sqlite.Exec(`INSERT INTO workout_templates (id, name, type, description, difficulty, duration_minutes, position) VALUES ('grip', 'Grip Day', 'strength', '', 'beginner', 20, 99)`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sqlite.Exec(`INSERT INTO workout_template_exercises (template_id, position, name, sets, reps) VALUES ('grip', 1, 'Farmer Carry', 3, 1)`); err != nil {
+		t.Fatal(err)
+	}
+	workout, err := repo.CreateWorkoutFromTemplate(ctx, database.DemoUserID, "grip", "Grip", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exercises, err := repo.GetExercisesByWorkout(ctx, workout.ID)
+	if err != nil || len(exercises) != 1 || exercises[0].Name != "Farmer Carry" || workout.Type != models.WorkoutTypeStrength {
+		t.Errorf("workout from added template: %+v, exercises %v, %v", workout, exercises, err)
+	}
+
+	if _, err := repo.CreateWorkoutFromTemplate(ctx, database.DemoUserID, "missing", "x", nil); !errors.Is(err, ErrWorkoutTemplateNotFound) {
+		t.Errorf("missing template: err = %v", err)
+	}
+}
+
+func TestSaveWorkoutAsTemplate_SQLite(t *testing.T) {
+	db, err := database.NewMockDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	repo := NewWorkoutRepository(nil, db.GetSQLite(), true)
+	ctx := context.Background()
+
+	workout, err := repo.CreateWorkout(ctx, database.DemoUserID, "Grip Day", models.WorkoutTypeStrength, "Heavy carries")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"Farmer Carry", "Dead Hang"} {
+		e := &models.Exercise{Name: name, Sets: 3, Reps: 1, RepsMin: 1, RepsMax: 2, WorkoutID: workout.ID}
+		if err := repo.CreateExercise(ctx, database.DemoUserID, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := repo.SaveWorkoutAsTemplate(ctx, "someone-else", workout.ID, "", ""); !errors.Is(err, ErrWorkoutNotFound) {
+		t.Errorf("another user's workout: err = %v", err)
+	}
+	saved, err := repo.SaveWorkoutAsTemplate(ctx, database.DemoUserID, workout.ID, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Name != "Grip Day" || saved.Description != "Heavy carries" || !saved.Custom {
+		t.Errorf("saved template = %+v", saved)
+	}
+
+	// Deleting the workout leaves the template intact
+	if err := repo.DeleteWorkout(ctx, database.DemoUserID, workout.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	builtIn, _ := repo.GetWorkoutTemplates(ctx, "")
+	templates, err := repo.GetWorkoutTemplates(ctx, database.DemoUserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := templates[len(templates)-1]
+	if len(templates) != len(builtIn)+1 || last.ID != saved.ID || len(last.Exercises) != 2 || last.Exercises[1].Name != "Dead Hang" || last.Exercises[1].RepsMax != 2 {
+		t.Fatalf("got %d templates ending with %+v", len(templates), last)
+	}
+	if _, err := repo.GetWorkoutTemplate(ctx, "someone-else", saved.ID); !errors.Is(err, ErrWorkoutTemplateNotFound) {
+		t.Errorf("another user's template: err = %v", err)
+	}
+
+	copied, err := repo.CreateWorkoutFromTemplate(ctx, database.DemoUserID, saved.ID, "Grip Day 2", nil)
+	if err != nil || copied.Type != models.WorkoutTypeStrength {
+		t.Errorf("workout from saved template: %+v, %v", copied, err)
+	}
+}
+
+func TestGetProgressData_SQLite(t *testing.T) {
+	db, err := database.NewMockDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	repo := NewWorkoutRepository(nil, db.GetSQLite(), true)
+	sessions := NewSessionRepository(nil, db.GetSQLite(), true)
+	ctx := context.Background()
+
+	workout, err := repo.CreateWorkout(ctx, database.DemoUserID, "Leg Day", models.WorkoutTypeStrength, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	squat := &models.Exercise{Name: "Squat", Sets: 1, Reps: 5, Weight: 185, WorkoutID: workout.ID}
+	if err := repo.CreateExercise(ctx, database.DemoUserID, squat); err != nil {
+		t.Fatal(err)
+	}
+	session, err := sessions.CreateSessionWithExercises(ctx, database.DemoUserID, workout.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := session.Exercises[0].Sets[0]
+	set.Completed = true
+	if err := sessions.UpdateExerciseSet(ctx, database.DemoUserID, set); err != nil {
+		t.Fatal(err)
+	}
+
+	// SQLite's DATE() comes back as text; the newest day is listed first
+	progress, err := sessions.GetProgressData(ctx, database.DemoUserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(progress) == 0 || progress[0]["exerciseName"] != "Squat" {
+		t.Fatalf("progress = %v", progress)
+	}
+	if date, _ := progress[0]["date"].(string); len(date) != len("2006-01-02") {
+		t.Errorf("date = %v", progress[0]["date"])
+	}
+}
+
+func TestGetCompletedSessionsSearch_SQLite(t *testing.T) {
+	db, err := database.NewMockDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	repo := NewWorkoutRepository(nil, db.GetSQLite(), true)
+	sessions := NewSessionRepository(nil, db.GetSQLite(), true)
+	ctx := context.Background()
+	// A user of their own, as the demo sessions record gyms and partners too
+	const userID = "search-user"
+
+	workout, err := repo.CreateWorkout(ctx, userID, "Leg Day", models.WorkoutTypeStrength, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	session, err := sessions.CreateSession(ctx, userID, workout.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	metadata := models.SessionMetadata{Gym: "Iron Temple", Partners: []string{"Sam"}, Mood: "fired up", Extra: map[string]string{"weather": "Rainy"}}
+	if _, err := sessions.UpdateSessionMetadata(ctx, userID, session.ID, metadata); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sessions.EndSession(ctx, userID, session.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// Values match, ignoring case, wherever they sit in the metadata
+	for _, q := range []string{"iron", "SAM", "fired", "rainy"} {
+		found, err := sessions.GetCompletedSessions(ctx, userID, q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(found) != 1 || found[0].ID != session.ID {
+			t.Errorf("search %q = %d sessions, want this one", q, len(found))
+		}
+	}
+	// Field names do not
+	for _, q := range []string{"gym", "mood", "partners", "extra", "weather"} {
+		found, err := sessions.GetCompletedSessions(ctx, userID, q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(found) != 0 {
+			t.Errorf("search %q matched %d sessions by field name", q, len(found))
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package requestid
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+)
+
+/**
+ * Request ID Package
+ *
+ * Tags every request with an ID, echoed in the X-Request-ID response
+ * header so a user's bug report can be matched to server logs. An ID sent
+ * by a proxy or client is kept when it looks sane; otherwise one is
+ * generated.
+ */
+
+// Header carries the request ID in both directions
+const Header = "X-Request-ID"
+
+const contextKey = "requestID"
+
+// validID limits client-supplied IDs to something safe to log and echo
+var validID = regexp.MustCompile(`^[A-Za-z0-9._-]{8,64}$`)
+
+// Middleware assigns the request ID; install it before anything that logs
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(Header)
+		if !validID.MatchString(id) {
+			id = generate()
+		}
+		c.Set(contextKey, id)
+		c.Header(Header, id)
+		c.Next()
+	}
+}
+
+// Get returns the request's ID, or "" outside Middleware
+func Get(c *gin.Context) string {
+	return c.GetString(contextKey)
+}
+
+func generate() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,31 @@
+package requestid
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Middleware())
+	var seen string
+	r.GET("/", func(c *gin.Context) { seen = Get(c) })
+
+	for incoming, keep := range map[string]bool{"": false, "abc-123_XYZ.9": true, "short": false, "bad id\nwith newline": false} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(Header, incoming)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		got := w.Header().Get(Header)
+		if got == "" || got != seen {
+			t.Errorf("%q: header %q, context %q", incoming, got, seen)
+		}
+		if (got == incoming) != keep {
+			t.Errorf("%q: got %q, keep = %v", incoming, got, keep)
+		}
+	}
+}
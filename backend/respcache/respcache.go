@@ -0,0 +1,256 @@
+package respcache
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"liftoff/backend/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+/**
+ * Response Cache Package
+ *
+ * HTTP caching for expensive read-only endpoints. Successful GET responses
+ * are memoized in process memory, keyed by route tag, query and (for
+ * private routes) the signed-in user, and served with an ETag so clients
+ * can revalidate with If-None-Match and get 304 Not Modified. Public
+ * responses also carry Cache-Control max-age; private ones must always be
+ * revalidated.
+ *
+ * Entries expire after RESPONSE_CACHE_TTL and are invalidated as soon as
+ * the user makes a successful write (InvalidateOnWrite), or explicitly
+ * with Invalidate. Entries live per server instance.
+ */
+
+// DefaultTTL is how long responses are memoized when RESPONSE_CACHE_TTL is unset
+const DefaultTTL = 5 * time.Minute
+
+// Cache memoizes responses; a nil Cache only sets caching headers
+type Cache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*entry
+}
+
+type entry struct {
+	tag, userID string
+	status      int
+	contentType string
+	body        []byte
+	etag        string
+	expires     time.Time
+}
+
+// New creates a cache keeping responses for ttl. It returns nil (no memoization) when ttl is 0.
+func New(ttl time.Duration) *Cache {
+	if ttl <= 0 {
+		return nil
+	}
+	return &Cache{ttl: ttl, now: time.Now, entries: map[string]*entry{}}
+}
+
+// FromEnv builds a cache from RESPONSE_CACHE_TTL (a duration; 0 disables memoization)
+func FromEnv() *Cache {
+	raw := os.Getenv("RESPONSE_CACHE_TTL")
+	if raw == "" {
+		return New(DefaultTTL)
+	}
+	if raw == "0" {
+		return nil
+	}
+	ttl, err := time.ParseDuration(raw)
+	if err != nil || ttl < 0 {
+		log.Printf("Invalid RESPONSE_CACHE_TTL=%q, using %v", raw, DefaultTTL)
+		return New(DefaultTTL)
+	}
+	return New(ttl)
+}
+
+// Public caches a response that is the same for every caller; clients and shared
+// caches may reuse it for maxAge without asking again
+func (c *Cache) Public(tag string, maxAge time.Duration) gin.HandlerFunc {
+	cacheControl := "public, max-age=" + strconv.Itoa(int(maxAge.Seconds()))
+	return c.handle(tag, false, cacheControl)
+}
+
+// Private caches a response per signed-in user (after AuthMiddleware); clients
+// must revalidate it on every use
+func (c *Cache) Private(tag string) gin.HandlerFunc {
+	return c.handle(tag, true, "private, no-cache")
+}
+
+func (c *Cache) handle(tag string, perUser bool, cacheControl string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if ctx.Request.Method != http.MethodGet {
+			ctx.Next()
+			return
+		}
+		userID := ""
+		if perUser {
+			userID = auth.GetUserID(ctx)
+			ctx.Header("Vary", "Authorization, Cookie")
+		}
+		key := tag + "\x00" + userID + "\x00" + ctx.Request.URL.RequestURI()
+
+		if e := c.get(key); e != nil {
+			ctx.Header("X-Cache", "HIT")
+			write(ctx, e, cacheControl)
+			ctx.Abort()
+			return
+		}
+
+		buf := &bufferedWriter{ResponseWriter: ctx.Writer, status: http.StatusOK}
+		ctx.Writer = buf
+		ctx.Next()
+		ctx.Writer = buf.ResponseWriter
+
+		if buf.status != http.StatusOK {
+			// Errors pass through uncached and without caching headers
+			ctx.Writer.WriteHeader(buf.status)
+			ctx.Writer.Write(buf.body.Bytes())
+			return
+		}
+		sum := sha256.Sum256(buf.body.Bytes())
+		e := &entry{
+			tag:         tag,
+			userID:      userID,
+			status:      buf.status,
+			contentType: buf.Header().Get("Content-Type"),
+			body:        buf.body.Bytes(),
+			etag:        `"` + hex.EncodeToString(sum[:16]) + `"`,
+		}
+		c.put(key, e)
+		ctx.Header("X-Cache", "MISS")
+		write(ctx, e, cacheControl)
+	}
+}
+
+// write sends a cached response, or 304 when the client already has it
+func write(ctx *gin.Context, e *entry, cacheControl string) {
+	h := ctx.Writer.Header()
+	h.Set("Cache-Control", cacheControl)
+	h.Set("ETag", e.etag)
+	if etagMatches(ctx.GetHeader("If-None-Match"), e.etag) {
+		ctx.Writer.WriteHeader(http.StatusNotModified)
+		return
+	}
+	if e.contentType != "" {
+		h.Set("Content-Type", e.contentType)
+	}
+	ctx.Writer.WriteHeader(e.status)
+	ctx.Writer.Write(e.body)
+}
+
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Cache) get(key string) *entry {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok || !c.now().Before(e.expires) {
+		return nil
+	}
+	return e
+}
+
+func (c *Cache) put(key string, e *entry) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e.expires = c.now().Add(c.ttl)
+	c.entries[key] = e
+}
+
+// Invalidate drops cached responses for tag, or for every tag when tag is empty.
+// With a userID only that user's private responses are dropped.
+func (c *Cache) Invalidate(tag, userID string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, e := range c.entries {
+		if (tag == "" || e.tag == tag) && (userID == "" || e.userID == userID) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// InvalidateOnWrite drops the signed-in user's private responses after each of their
+// successful non-GET requests, so reads reflect the change straight away.
+// Use it after AuthMiddleware.
+func (c *Cache) InvalidateOnWrite() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Next()
+		method := ctx.Request.Method
+		if method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions {
+			return
+		}
+		if status := ctx.Writer.Status(); status < 200 || status >= 300 {
+			return
+		}
+		if userID := auth.GetUserID(ctx); userID != "" {
+			c.Invalidate("", userID)
+		}
+	}
+}
+
+// Sweep removes expired entries. Intended to run from the jobs scheduler.
+func (c *Cache) Sweep(ctx context.Context) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for key, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, key)
+		}
+	}
+	return nil
+}
+
+// bufferedWriter holds the handler's response so caching headers can be set
+// from its body before anything is sent
+type bufferedWriter struct {
+	gin.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedWriter) WriteHeader(code int) { w.status = code }
+func (w *bufferedWriter) WriteHeaderNow()      {}
+func (w *bufferedWriter) Status() int          { return w.status }
+func (w *bufferedWriter) Size() int            { return w.body.Len() }
+func (w *bufferedWriter) Written() bool        { return w.body.Len() > 0 }
+
+func (w *bufferedWriter) Write(b []byte) (int, error) { return w.body.Write(b) }
+
+func (w *bufferedWriter) WriteString(s string) (int, error) { return w.body.WriteString(s) }
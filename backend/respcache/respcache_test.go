@@ -0,0 +1,134 @@
+package respcache
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"liftoff/backend/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newRouter(c *Cache) (*gin.Engine, *int) {
+	gin.SetMode(gin.TestMode)
+	calls := new(int)
+	r := gin.New()
+	r.Use(func(ctx *gin.Context) {
+		if user := ctx.GetHeader("X-Test-User"); user != "" {
+			ctx.Set(auth.UserIDKey, user)
+		}
+	}, c.InvalidateOnWrite())
+	r.GET("/templates", c.Public("templates", time.Hour), func(ctx *gin.Context) {
+		*calls++
+		ctx.JSON(http.StatusOK, gin.H{"templates": []string{"push", "pull"}})
+	})
+	r.GET("/progress", c.Private("progress"), func(ctx *gin.Context) {
+		*calls++
+		if ctx.Query("fail") != "" {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "boom"})
+			return
+		}
+		ctx.JSON(http.StatusOK, gin.H{"user": auth.GetUserID(ctx), "calls": *calls})
+	})
+	r.POST("/sets", func(ctx *gin.Context) { ctx.Status(http.StatusCreated) })
+	return r, calls
+}
+
+func get(r *gin.Engine, path, user, etag string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("X-Test-User", user)
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestPublic(t *testing.T) {
+	r, calls := newRouter(New(time.Minute))
+
+	first := get(r, "/templates", "", "")
+	if first.Code != http.StatusOK || first.Header().Get("X-Cache") != "MISS" ||
+		first.Header().Get("Cache-Control") != "public, max-age=3600" || first.Header().Get("ETag") == "" {
+		t.Fatalf("first: %d %v", first.Code, first.Header())
+	}
+	second := get(r, "/templates", "someone", "")
+	if second.Header().Get("X-Cache") != "HIT" || second.Body.String() != first.Body.String() ||
+		second.Header().Get("Content-Type") != first.Header().Get("Content-Type") {
+		t.Errorf("second: %v %s", second.Header(), second.Body)
+	}
+	if w := get(r, "/templates", "", first.Header().Get("ETag")); w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("revalidate: got %d %q", w.Code, w.Body)
+	}
+	if *calls != 1 {
+		t.Errorf("handler ran %d times, want 1", *calls)
+	}
+}
+
+func TestPrivate(t *testing.T) {
+	c := New(time.Minute)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+	r, calls := newRouter(c)
+
+	a := get(r, "/progress", "a", "")
+	if a.Header().Get("Cache-Control") != "private, no-cache" || a.Header().Get("Vary") != "Authorization, Cookie" {
+		t.Errorf("headers = %v", a.Header())
+	}
+	get(r, "/progress", "a", "")
+	if b := get(r, "/progress", "b", ""); b.Header().Get("X-Cache") != "MISS" || b.Body.String() == a.Body.String() {
+		t.Errorf("other user got %s", b.Body)
+	}
+	if *calls != 2 {
+		t.Fatalf("handler ran %d times, want 2", *calls)
+	}
+
+	// Errors are neither cached nor given caching headers
+	for i := 0; i < 2; i++ {
+		if w := get(r, "/progress?fail=1", "a", ""); w.Code != http.StatusInternalServerError || w.Header().Get("ETag") != "" {
+			t.Errorf("error response: %d %v", w.Code, w.Header())
+		}
+	}
+	*calls = 2
+
+	// A write by user a drops only a's responses
+	req := httptest.NewRequest(http.MethodPost, "/sets", nil)
+	req.Header.Set("X-Test-User", "a")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	if w := get(r, "/progress", "a", ""); w.Header().Get("X-Cache") != "MISS" {
+		t.Error("user a should miss after writing")
+	}
+	if w := get(r, "/progress", "b", ""); w.Header().Get("X-Cache") != "HIT" {
+		t.Error("user b should still hit")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if w := get(r, "/progress", "b", ""); w.Header().Get("X-Cache") != "MISS" {
+		t.Error("expired entry should miss")
+	}
+	now = now.Add(2 * time.Minute)
+	c.Sweep(context.Background())
+	if len(c.entries) != 0 {
+		t.Errorf("%d entries left after sweep", len(c.entries))
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	t.Setenv("RESPONSE_CACHE_TTL", "0")
+	c := FromEnv()
+	if c != nil {
+		t.Fatal("RESPONSE_CACHE_TTL=0 should disable memoization")
+	}
+	r, calls := newRouter(c)
+	first := get(r, "/templates", "", "")
+	if w := get(r, "/templates", "", first.Header().Get("ETag")); w.Code != http.StatusNotModified {
+		t.Errorf("revalidate without memoization: got %d", w.Code)
+	}
+	if *calls != 2 {
+		t.Errorf("handler ran %d times, want 2", *calls)
+	}
+}
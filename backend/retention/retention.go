@@ -0,0 +1,184 @@
+package retention
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/models"
+)
+
+/**
+ * Retention Package
+ *
+ * Inactivity data-retention policy for hosted deployments. Accounts with no
+ * sign-in or API activity for RETENTION_INACTIVE_DAYS are deleted, but only
+ * after the owner has been warned at least RETENTION_WARNING_DAYS ahead.
+ * Warning state is stored so a returning user cancels the deletion, and the
+ * Enforcer runs as a background job. The policy is off unless configured.
+ */
+
+// DefaultWarningDays is how long before deletion users are warned
+const DefaultWarningDays = 30
+
+// Policy configures inactivity retention; a zero InactiveAfter disables it
+type Policy struct {
+	InactiveAfter time.Duration
+	WarnBefore    time.Duration
+}
+
+// PolicyFromEnv reads RETENTION_INACTIVE_DAYS and RETENTION_WARNING_DAYS.
+// A warning period not shorter than the inactivity period is cut to half of it.
+func PolicyFromEnv() Policy {
+	days := func(key string, def int) time.Duration {
+		n, err := strconv.Atoi(os.Getenv(key))
+		if err != nil || n < 0 {
+			n = def
+		}
+		return time.Duration(n) * 24 * time.Hour
+	}
+	p := Policy{
+		InactiveAfter: days("RETENTION_INACTIVE_DAYS", 0),
+		WarnBefore:    days("RETENTION_WARNING_DAYS", DefaultWarningDays),
+	}
+	if p.WarnBefore >= p.InactiveAfter {
+		p.WarnBefore = p.InactiveAfter / 2
+	}
+	return p
+}
+
+// Enabled reports whether accounts are ever deleted for inactivity
+func (p Policy) Enabled() bool {
+	return p.InactiveAfter > 0
+}
+
+// Action is what the policy wants done with one account
+type Action int
+
+const (
+	ActionNone Action = iota
+	ActionWarn
+	ActionClearWarning
+	ActionDelete
+)
+
+// Decide applies the policy to an account last active at lastActive. For ActionWarn
+// it also returns the deletion date, which never falls less than WarnBefore after now,
+// so accounts already past the deadline when the policy is enabled still get full notice.
+func (p Policy) Decide(lastActive time.Time, warning *models.RetentionWarning, now time.Time) (Action, time.Time) {
+	if !p.Enabled() {
+		return ActionNone, time.Time{}
+	}
+	if warning != nil {
+		if lastActive.After(warning.WarnedAt) {
+			return ActionClearWarning, time.Time{}
+		}
+		if !now.Before(warning.DeleteAfter) {
+			return ActionDelete, time.Time{}
+		}
+		return ActionNone, time.Time{}
+	}
+	deadline := lastActive.Add(p.InactiveAfter)
+	if now.Before(deadline.Add(-p.WarnBefore)) {
+		return ActionNone, time.Time{}
+	}
+	if earliest := now.Add(p.WarnBefore); deadline.Before(earliest) {
+		deadline = earliest
+	}
+	return ActionWarn, deadline
+}
+
+// Store persists activity and warning state for the Enforcer
+type Store interface {
+	// StartTracking stamps users with no recorded activity, so inactivity is measured
+	// from when tracking began rather than from account creation
+	StartTracking(ctx context.Context, now time.Time) error
+	// ListRetentionCandidates returns warned users and those inactive since before cutoff
+	ListRetentionCandidates(ctx context.Context, cutoff time.Time) ([]*models.RetentionCandidate, error)
+	RecordRetentionWarning(ctx context.Context, warning *models.RetentionWarning) error
+	ClearRetentionWarning(ctx context.Context, userID string) error
+	RecordRetentionDeletion(ctx context.Context, deletion *models.RetentionDeletion) error
+}
+
+// Notifier tells a user their account will be deleted at deleteAfter
+type Notifier func(ctx context.Context, email string, deleteAfter time.Time) error
+
+// Enforcer applies a Policy to every account
+type Enforcer struct {
+	store         Store
+	deleteAccount func(ctx context.Context, userID string) error
+	policy        Policy
+	notify        Notifier
+}
+
+// NewEnforcer creates an enforcer that removes accounts with deleteAccount
+func NewEnforcer(store Store, deleteAccount func(ctx context.Context, userID string) error, policy Policy, notify Notifier) *Enforcer {
+	return &Enforcer{store: store, deleteAccount: deleteAccount, policy: policy, notify: notify}
+}
+
+// Run warns, clears and deletes accounts as the policy requires. Intended to run
+// from the jobs scheduler. Admins are never deleted, and a user is only deleted
+// after a warning was delivered.
+func (e *Enforcer) Run(ctx context.Context) error {
+	if !e.policy.Enabled() {
+		return nil
+	}
+	now := time.Now()
+	if err := e.store.StartTracking(ctx, now); err != nil {
+		return err
+	}
+	candidates, err := e.store.ListRetentionCandidates(ctx, now.Add(e.policy.WarnBefore-e.policy.InactiveAfter))
+	if err != nil {
+		return err
+	}
+
+	var warned, cleared, deleted int
+	for _, c := range candidates {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if auth.EffectiveRole(c.Role, c.Email) == auth.RoleAdmin {
+			continue
+		}
+		action, deleteAfter := e.policy.Decide(c.LastActiveAt, c.Warning, now)
+		switch action {
+		case ActionWarn:
+			if err := e.notify(ctx, c.Email, deleteAfter); err != nil {
+				// Not recorded, so the warning is retried on the next run
+				log.Printf("Retention warning to %s failed: %v", c.UserID, err)
+				continue
+			}
+			err := e.store.RecordRetentionWarning(ctx, &models.RetentionWarning{
+				UserID: c.UserID, Email: c.Email, LastActiveAt: c.LastActiveAt, WarnedAt: now, DeleteAfter: deleteAfter,
+			})
+			if err != nil {
+				return err
+			}
+			warned++
+		case ActionClearWarning:
+			if err := e.store.ClearRetentionWarning(ctx, c.UserID); err != nil {
+				return err
+			}
+			cleared++
+		case ActionDelete:
+			if err := e.deleteAccount(ctx, c.UserID); err != nil {
+				return fmt.Errorf("delete inactive account %s: %w", c.UserID, err)
+			}
+			err := e.store.RecordRetentionDeletion(ctx, &models.RetentionDeletion{
+				UserID: c.UserID, LastActiveAt: c.LastActiveAt, DeletedAt: now,
+			})
+			if err != nil {
+				return err
+			}
+			deleted++
+		}
+	}
+	if warned+cleared+deleted > 0 {
+		log.Printf("Retention: %d warned, %d warnings cleared, %d accounts deleted", warned, cleared, deleted)
+	}
+	return nil
+}
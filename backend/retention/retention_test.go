@@ -0,0 +1,102 @@
+package retention
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"liftoff/backend/models"
+)
+
+func TestDecide(t *testing.T) {
+	day := 24 * time.Hour
+	p := Policy{InactiveAfter: 365 * day, WarnBefore: 30 * day}
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	if action, _ := p.Decide(now.Add(-300*day), nil, now); action != ActionNone {
+		t.Errorf("recently active: %v", action)
+	}
+	action, deleteAfter := p.Decide(now.Add(-335*day), nil, now)
+	if action != ActionWarn || !deleteAfter.Equal(now.Add(30*day)) {
+		t.Errorf("inside warning window: %v %v", action, deleteAfter)
+	}
+	// Long-inactive accounts still get the full warning period
+	action, deleteAfter = p.Decide(now.Add(-900*day), nil, now)
+	if action != ActionWarn || !deleteAfter.Equal(now.Add(30*day)) {
+		t.Errorf("overdue: %v %v", action, deleteAfter)
+	}
+
+	warning := &models.RetentionWarning{WarnedAt: now.Add(-31 * day), DeleteAfter: now.Add(-day)}
+	if action, _ := p.Decide(now.Add(-400*day), warning, now); action != ActionDelete {
+		t.Errorf("warning expired: %v", action)
+	}
+	if action, _ := p.Decide(now.Add(-2*day), warning, now); action != ActionClearWarning {
+		t.Errorf("returned after warning: %v", action)
+	}
+	if action, _ := (Policy{}).Decide(now.Add(-900*day), nil, now); action != ActionNone {
+		t.Errorf("disabled policy: %v", action)
+	}
+}
+
+type fakeStore struct {
+	candidates []*models.RetentionCandidate
+	warned     []string
+	cleared    []string
+	deleted    []string
+}
+
+func (s *fakeStore) StartTracking(ctx context.Context, now time.Time) error { return nil }
+func (s *fakeStore) ListRetentionCandidates(ctx context.Context, cutoff time.Time) ([]*models.RetentionCandidate, error) {
+	return s.candidates, nil
+}
+func (s *fakeStore) RecordRetentionWarning(ctx context.Context, w *models.RetentionWarning) error {
+	s.warned = append(s.warned, w.UserID)
+	return nil
+}
+func (s *fakeStore) ClearRetentionWarning(ctx context.Context, userID string) error {
+	s.cleared = append(s.cleared, userID)
+	return nil
+}
+func (s *fakeStore) RecordRetentionDeletion(ctx context.Context, d *models.RetentionDeletion) error {
+	s.deleted = append(s.deleted, d.UserID)
+	return nil
+}
+
+func TestEnforcerRun(t *testing.T) {
+	day := 24 * time.Hour
+	now := time.Now()
+	long := now.Add(-500 * day)
+	expired := &models.RetentionWarning{WarnedAt: now.Add(-40 * day), DeleteAfter: now.Add(-10 * day)}
+	store := &fakeStore{candidates: []*models.RetentionCandidate{
+		{UserID: "warn", Email: "warn@example.com", Role: "user", LastActiveAt: long},
+		{UserID: "mute", Email: "mute@example.com", Role: "user", LastActiveAt: long},
+		{UserID: "gone", Email: "gone@example.com", Role: "user", LastActiveAt: long, Warning: expired},
+		{UserID: "back", Email: "back@example.com", Role: "user", LastActiveAt: now, Warning: expired},
+		{UserID: "admin", Email: "admin@example.com", Role: "admin", LastActiveAt: long, Warning: expired},
+	}}
+	var deletedAccounts []string
+	notify := func(ctx context.Context, email string, deleteAfter time.Time) error {
+		if email == "mute@example.com" {
+			return context.DeadlineExceeded
+		}
+		return nil
+	}
+	e := NewEnforcer(store, func(ctx context.Context, userID string) error {
+		deletedAccounts = append(deletedAccounts, userID)
+		return nil
+	}, Policy{InactiveAfter: 365 * day, WarnBefore: 30 * day}, notify)
+
+	if err := e.Run(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	// A failed notification is not recorded, so that user is never deleted unwarned
+	if len(store.warned) != 1 || store.warned[0] != "warn" {
+		t.Errorf("warned = %v", store.warned)
+	}
+	if len(store.cleared) != 1 || store.cleared[0] != "back" {
+		t.Errorf("cleared = %v", store.cleared)
+	}
+	if len(deletedAccounts) != 1 || deletedAccounts[0] != "gone" || len(store.deleted) != 1 {
+		t.Errorf("deleted = %v, recorded %v", deletedAccounts, store.deleted)
+	}
+}
@@ -0,0 +1,136 @@
+package secheaders
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+/**
+ * Security Headers Package
+ *
+ * Browser hardening headers on every response, so the API can be exposed
+ * directly rather than only behind a proxy that adds them. The API only
+ * serves JSON and plain text, so the defaults forbid loading, framing and
+ * sniffing anything. Strict-Transport-Security is only sent over HTTPS.
+ */
+
+// Config holds the header values; an empty value leaves that header out
+type Config struct {
+	ContentSecurityPolicy string
+	FrameOptions          string
+	ReferrerPolicy        string
+	// HSTSMaxAge of zero disables Strict-Transport-Security
+	HSTSMaxAge            time.Duration
+	HSTSIncludeSubdomains bool
+	HSTSPreload           bool
+}
+
+// Defaults used when the corresponding SECURITY_* variable is unset
+const (
+	DefaultContentSecurityPolicy = "default-src 'none'; frame-ancestors 'none'"
+	DefaultFrameOptions          = "DENY"
+	DefaultReferrerPolicy        = "no-referrer"
+	DefaultHSTSMaxAge            = 365 * 24 * time.Hour
+)
+
+// ConfigFromEnv reads SECURITY_CSP, SECURITY_FRAME_OPTIONS and SECURITY_REFERRER_POLICY
+// ("off" omits the header), SECURITY_HSTS_MAX_AGE (Go duration, 0 disables HSTS),
+// SECURITY_HSTS_INCLUDE_SUBDOMAINS (default true) and SECURITY_HSTS_PRELOAD
+func ConfigFromEnv() Config {
+	cfg := Config{
+		ContentSecurityPolicy: stringFromEnv("SECURITY_CSP", DefaultContentSecurityPolicy),
+		FrameOptions:          stringFromEnv("SECURITY_FRAME_OPTIONS", DefaultFrameOptions),
+		ReferrerPolicy:        stringFromEnv("SECURITY_REFERRER_POLICY", DefaultReferrerPolicy),
+		HSTSMaxAge:            DefaultHSTSMaxAge,
+		HSTSIncludeSubdomains: boolFromEnv("SECURITY_HSTS_INCLUDE_SUBDOMAINS", true),
+		HSTSPreload:           boolFromEnv("SECURITY_HSTS_PRELOAD", false),
+	}
+	if raw := os.Getenv("SECURITY_HSTS_MAX_AGE"); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil || d < 0 {
+			log.Printf("Invalid SECURITY_HSTS_MAX_AGE=%q, ignoring", raw)
+		} else {
+			cfg.HSTSMaxAge = d
+		}
+	}
+	return cfg
+}
+
+// hsts builds the Strict-Transport-Security value, or "" when disabled
+func (cfg Config) hsts() string {
+	if cfg.HSTSMaxAge <= 0 {
+		return ""
+	}
+	value := fmt.Sprintf("max-age=%d", int64(cfg.HSTSMaxAge/time.Second))
+	if cfg.HSTSIncludeSubdomains {
+		value += "; includeSubDomains"
+	}
+	if cfg.HSTSPreload {
+		value += "; preload"
+	}
+	return value
+}
+
+// Middleware sets the configured headers before the handler runs, so error
+// responses carry them too
+func Middleware(cfg Config) gin.HandlerFunc {
+	hsts := cfg.hsts()
+	return func(c *gin.Context) {
+		h := c.Writer.Header()
+		h.Set("X-Content-Type-Options", "nosniff")
+		if cfg.ContentSecurityPolicy != "" {
+			h.Set("Content-Security-Policy", cfg.ContentSecurityPolicy)
+		}
+		if cfg.FrameOptions != "" {
+			h.Set("X-Frame-Options", cfg.FrameOptions)
+		}
+		if cfg.ReferrerPolicy != "" {
+			h.Set("Referrer-Policy", cfg.ReferrerPolicy)
+		}
+		if hsts != "" && isHTTPS(c) {
+			h.Set("Strict-Transport-Security", hsts)
+		}
+		c.Next()
+	}
+}
+
+// isHTTPS reports whether the client connected over TLS, directly or through a
+// TLS-terminating proxy. X-Forwarded-Proto is believed from anyone: browsers
+// ignore HSTS received over plain HTTP, so a forged header has no effect.
+func isHTTPS(c *gin.Context) bool {
+	return c.Request.TLS != nil || strings.EqualFold(c.GetHeader("X-Forwarded-Proto"), "https")
+}
+
+func stringFromEnv(key, fallback string) string {
+	raw, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+	raw = strings.TrimSpace(raw)
+	if strings.EqualFold(raw, "off") {
+		return ""
+	}
+	if raw == "" {
+		return fallback
+	}
+	return raw
+}
+
+func boolFromEnv(key string, fallback bool) bool {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+	b, err := strconv.ParseBool(raw)
+	if err != nil {
+		log.Printf("Invalid %s=%q, ignoring", key, raw)
+		return fallback
+	}
+	return b
+}
@@ -0,0 +1,87 @@
+package secheaders
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func request(cfg Config, setup func(*http.Request)) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Middleware(cfg))
+	r.GET("/api/workouts", func(c *gin.Context) { c.JSON(http.StatusNotFound, gin.H{"error": "Workout not found"}) })
+
+	req := httptest.NewRequest("GET", "/api/workouts", nil)
+	if setup != nil {
+		setup(req)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestMiddleware_Defaults(t *testing.T) {
+	t.Setenv("SECURITY_HSTS_MAX_AGE", "")
+	cfg := ConfigFromEnv()
+
+	w := request(cfg, nil)
+	want := map[string]string{
+		"Content-Security-Policy":   DefaultContentSecurityPolicy,
+		"X-Content-Type-Options":    "nosniff",
+		"X-Frame-Options":           "DENY",
+		"Referrer-Policy":           "no-referrer",
+		"Strict-Transport-Security": "",
+	}
+	for header, value := range want {
+		if got := w.Header().Get(header); got != value {
+			t.Errorf("%s = %q, want %q", header, got, value)
+		}
+	}
+
+	// HSTS only over HTTPS, direct or via a TLS-terminating proxy
+	for name, setup := range map[string]func(*http.Request){
+		"tls":   func(r *http.Request) { r.TLS = &tls.ConnectionState{} },
+		"proxy": func(r *http.Request) { r.Header.Set("X-Forwarded-Proto", "https") },
+	} {
+		w = request(cfg, setup)
+		if got := w.Header().Get("Strict-Transport-Security"); got != "max-age=31536000; includeSubDomains" {
+			t.Errorf("%s: Strict-Transport-Security = %q", name, got)
+		}
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("SECURITY_CSP", "default-src 'self'")
+	t.Setenv("SECURITY_FRAME_OPTIONS", "off")
+	t.Setenv("SECURITY_REFERRER_POLICY", "strict-origin")
+	t.Setenv("SECURITY_HSTS_MAX_AGE", "48h")
+	t.Setenv("SECURITY_HSTS_INCLUDE_SUBDOMAINS", "false")
+	t.Setenv("SECURITY_HSTS_PRELOAD", "true")
+
+	cfg := ConfigFromEnv()
+	if cfg.ContentSecurityPolicy != "default-src 'self'" || cfg.FrameOptions != "" || cfg.ReferrerPolicy != "strict-origin" {
+		t.Errorf("config = %+v", cfg)
+	}
+	if cfg.HSTSMaxAge != 48*time.Hour || cfg.HSTSIncludeSubdomains || !cfg.HSTSPreload {
+		t.Errorf("hsts config = %+v", cfg)
+	}
+
+	w := request(cfg, func(r *http.Request) { r.TLS = &tls.ConnectionState{} })
+	if _, ok := w.Header()["X-Frame-Options"]; ok {
+		t.Error("X-Frame-Options should be omitted when off")
+	}
+	if got := w.Header().Get("Strict-Transport-Security"); got != "max-age=172800; preload" {
+		t.Errorf("Strict-Transport-Security = %q", got)
+	}
+
+	t.Setenv("SECURITY_HSTS_MAX_AGE", "0")
+	w = request(ConfigFromEnv(), func(r *http.Request) { r.TLS = &tls.ConnectionState{} })
+	if got := w.Header().Get("Strict-Transport-Security"); got != "" {
+		t.Errorf("HSTS disabled but got %q", got)
+	}
+}
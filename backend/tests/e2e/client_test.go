@@ -0,0 +1,141 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// client calls the API as one user
+type client struct {
+	t       *testing.T
+	token   string
+	headers map[string]string
+}
+
+func newClient(t *testing.T) *client {
+	return &client{t: t, headers: map[string]string{}}
+}
+
+// do sends body as JSON and returns the status and raw response body
+func (c *client) do(method, path string, body interface{}) (int, []byte) {
+	c.t.Helper()
+	var r io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			c.t.Fatal(err)
+		}
+		r = bytes.NewReader(raw)
+	}
+	req, err := http.NewRequest(method, baseURL+"/api"+path, r)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		c.t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	return resp.StatusCode, raw
+}
+
+// must sends the request, fails the test unless it answers want, and decodes the
+// response into out when out is not nil
+func (c *client) must(want int, method, path string, body, out interface{}) []byte {
+	c.t.Helper()
+	status, raw := c.do(method, path, body)
+	if status != want {
+		c.t.Fatalf("%s %s: got %d, want %d: %s", method, path, status, want, raw)
+	}
+	if out != nil {
+		if err := json.Unmarshal(raw, out); err != nil {
+			c.t.Fatalf("%s %s: decode: %v: %s", method, path, err, raw)
+		}
+	}
+	return raw
+}
+
+// mailpitMessage is a message summary from the Mailpit API
+type mailpitMessage struct {
+	ID      string `json:"ID"`
+	Subject string `json:"Subject"`
+}
+
+// waitForMail polls Mailpit for the newest message to address whose subject
+// contains subject, and returns its plain-text body
+func waitForMail(t *testing.T, address, subject string) string {
+	t.Helper()
+	deadline := time.Now().Add(15 * time.Second)
+	for time.Now().Before(deadline) {
+		var list struct {
+			Messages []mailpitMessage `json:"messages"`
+		}
+		query := url.QueryEscape(fmt.Sprintf("to:%q", address))
+		if err := getJSON(mailpitURL+"/api/v1/search?query="+query, &list); err != nil {
+			t.Fatalf("mailpit: %v", err)
+		}
+		// Newest first
+		for _, m := range list.Messages {
+			if !strings.Contains(m.Subject, subject) {
+				continue
+			}
+			var msg struct {
+				Text string `json:"Text"`
+			}
+			if err := getJSON(mailpitURL+"/api/v1/message/"+m.ID, &msg); err != nil {
+				t.Fatalf("mailpit: %v", err)
+			}
+			return msg.Text
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+	t.Fatalf("no %q email to %s", subject, address)
+	return ""
+}
+
+var linkTokenRe = regexp.MustCompile(`[?&]token=([0-9A-Za-z_-]+)`)
+
+// linkToken returns the token from the first link with ?token= in an email body
+func linkToken(t *testing.T, text string) string {
+	t.Helper()
+	match := linkTokenRe.FindStringSubmatch(text)
+	if match == nil {
+		t.Fatalf("no link with a token in: %s", text)
+	}
+	return match[1]
+}
+
+func getJSON(u string, out interface{}) error {
+	resp, err := http.Get(u)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", u, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
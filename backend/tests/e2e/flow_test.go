@@ -0,0 +1,146 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+type authResponse struct {
+	Token string `json:"token"`
+	User  struct {
+		ID    string `json:"id"`
+		Email string `json:"email"`
+	} `json:"user"`
+}
+
+// TestAccountLifecycle walks one account from sign-up to data export:
+// register, confirm a new email address, reset the password, build a workout,
+// log a session and export everything
+func TestAccountLifecycle(t *testing.T) {
+	run := time.Now().UnixNano()
+	firstEmail := fmt.Sprintf("lifter-%d@e2e.test", run)
+	email := fmt.Sprintf("lifter-%d-new@e2e.test", run)
+	password := "Squat-Bench-Dead-1"
+	newPassword := "Press-Row-Pull-2"
+
+	c := newClient(t)
+
+	// Register
+	var reg authResponse
+	c.must(http.StatusCreated, "POST", "/auth/register", map[string]string{"email": firstEmail, "password": password}, &reg)
+	if reg.Token == "" || reg.User.Email != firstEmail {
+		t.Fatalf("register response = %+v", reg)
+	}
+	c.token = reg.Token
+	waitForMail(t, firstEmail, "Welcome to Liftoff")
+
+	// Verify a new address: step up, request the change, confirm from the email
+	var reauth struct {
+		ReauthToken string `json:"reauthToken"`
+	}
+	c.must(http.StatusOK, "POST", "/auth/reauth", map[string]string{"password": password}, &reauth)
+	c.headers["X-Reauth-Token"] = reauth.ReauthToken
+	c.must(http.StatusOK, "POST", "/auth/change-email", map[string]string{"newEmail": email}, nil)
+	delete(c.headers, "X-Reauth-Token")
+	confirm := linkToken(t, waitForMail(t, email, "Confirm your new Liftoff email address"))
+	c.must(http.StatusOK, "POST", "/auth/confirm-email-change", map[string]string{"token": confirm}, nil)
+	// Confirming signs every device out
+	if status, _ := c.do("GET", "/auth/me", nil); status != http.StatusUnauthorized {
+		t.Fatalf("token still valid after email change: %d", status)
+	}
+
+	// Reset the password from the emailed link
+	c.token = ""
+	c.must(http.StatusOK, "POST", "/auth/forgot-password", map[string]string{"email": email}, nil)
+	reset := linkToken(t, waitForMail(t, email, "Reset your Liftoff password"))
+	c.must(http.StatusOK, "POST", "/auth/reset-password", map[string]string{"token": reset, "newPassword": newPassword}, nil)
+	if status, _ := c.do("POST", "/auth/login", map[string]string{"email": email, "password": password}); status != http.StatusUnauthorized {
+		t.Fatalf("old password still works: %d", status)
+	}
+	var login authResponse
+	c.must(http.StatusOK, "POST", "/auth/login", map[string]string{"email": email, "password": newPassword}, &login)
+	if login.User.ID != reg.User.ID {
+		t.Fatalf("signed in as %s, want %s", login.User.ID, reg.User.ID)
+	}
+	c.token = login.Token
+
+	// Build a workout
+	var workout struct {
+		ID string `json:"id"`
+	}
+	c.must(http.StatusCreated, "POST", "/workouts", map[string]string{"name": "E2E Push"}, &workout)
+	c.must(http.StatusCreated, "POST", "/exercises", map[string]interface{}{
+		"name": "Bench Press", "sets": 3, "reps": 5, "weight": 80, "workout_id": workout.ID,
+	}, nil)
+
+	// Log a session against it
+	var session struct {
+		ID        string `json:"id"`
+		Exercises []struct {
+			ID string `json:"id"`
+		} `json:"exercises"`
+	}
+	c.must(http.StatusCreated, "POST", "/sessions", map[string]string{"workout_id": workout.ID}, &session)
+	if len(session.Exercises) != 1 {
+		t.Fatalf("session has %d exercises, want 1", len(session.Exercises))
+	}
+	for i := 0; i < 3; i++ {
+		c.must(http.StatusCreated, "POST", "/exercise-sets", map[string]interface{}{
+			"sessionExerciseId": session.Exercises[0].ID, "reps": 5, "weight": 82.5,
+		}, nil)
+	}
+	c.must(http.StatusOK, "PUT", "/sessions/"+session.ID+"/end", nil, nil)
+
+	// Export everything and check the session made it in
+	raw := c.must(http.StatusOK, "GET", "/auth/export", nil, nil)
+	archive, err := zip.NewReader(bytes.NewReader(raw), int64(len(raw)))
+	if err != nil {
+		t.Fatalf("export is not a zip: %v", err)
+	}
+	files := map[string][]byte{}
+	for _, f := range archive.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name], err = io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var profile struct {
+		User struct {
+			Email string `json:"email"`
+		} `json:"user"`
+	}
+	if err := json.Unmarshal(files["profile.json"], &profile); err != nil || profile.User.Email != email {
+		t.Errorf("profile.json = %s (%v)", files["profile.json"], err)
+	}
+	if !strings.Contains(string(files["workouts.json"]), "Bench Press") {
+		t.Errorf("workouts.json is missing the workout: %s", files["workouts.json"])
+	}
+	if !strings.Contains(string(files["sessions.json"]), session.ID) {
+		t.Errorf("sessions.json is missing the session: %s", files["sessions.json"])
+	}
+	var sets []struct {
+		SessionID string  `json:"session_id"`
+		Weight    float64 `json:"weight"`
+	}
+	if err := json.Unmarshal(files["sets.json"], &sets); err != nil {
+		t.Fatalf("sets.json: %v", err)
+	}
+	if len(sets) != 3 || sets[0].SessionID != session.ID || sets[0].Weight != 82.5 {
+		t.Errorf("sets.json = %s", files["sets.json"])
+	}
+}
@@ -0,0 +1,272 @@
+package units
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"liftoff/backend/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+/**
+ * Units Package
+ *
+ * Weights are stored in pounds whatever unit the user prefers. For users who
+ * prefer metric, Middleware converts the weight fields of JSON request bodies
+ * (and the round_to query parameter) from kilograms to pounds before the
+ * handler runs, and those of JSON responses back to kilograms. Handlers,
+ * storage and every total, record and progress series computed from them
+ * only ever see pounds, so history stays consistent when a user switches.
+ * Plain-text and file responses (session logs, printables, exports) are
+ * left in pounds.
+ */
+
+// Unit systems a user can prefer
+const (
+	Imperial = "imperial" // pounds, the stored unit
+	Metric   = "metric"   // kilograms
+)
+
+// KilogramsPerPound is the exact international avoirdupois conversion
+const KilogramsPerPound = 0.45359237
+
+// Header tells clients which unit the response's weights are in
+const Header = "X-Weight-Unit"
+
+// WeightFields are the JSON keys holding a weight, or weight x reps, at any depth
+var WeightFields = map[string]bool{
+	"weight":           true,
+	"default_weight":   true,
+	"working_weight":   true,
+	"e1rm":             true,
+	"round_to":         true,
+	"warmup_round_to":  true,
+	"maxWeight":        true,
+	"totalVolume":      true,
+	"tonnage":          true,
+	"weight_increment": true,
+	"previous_weight":  true,
+}
+
+// Valid reports whether units is a known unit system
+func Valid(units string) bool {
+	return units == Imperial || units == Metric
+}
+
+// ToPounds converts kilograms to pounds, to the hundredth stored
+func ToPounds(kg float64) float64 {
+	return round(kg / KilogramsPerPound)
+}
+
+// ToKilograms converts pounds to kilograms, to the hundredth
+func ToKilograms(lb float64) float64 {
+	return round(lb * KilogramsPerPound)
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// Lookup returns the signed-in user's preferred unit system
+type Lookup func(ctx context.Context, userID string) (string, error)
+
+// Middleware converts weights for users who prefer metric. Use it after
+// AuthMiddleware (or OptionalAuthMiddleware) and before any response cache,
+// whose ETags it tags with "-kg" so cached pound and kilogram bodies are
+// never confused.
+func Middleware(lookup Lookup) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := auth.GetUserID(c)
+		if userID == "" {
+			c.Next()
+			return
+		}
+		preferred, err := lookup(c.Request.Context(), userID)
+		if err != nil {
+			// Pounds are still correct, and the header says so
+			log.Printf("Error loading unit preference: %v", err)
+			preferred = Imperial
+		}
+		if preferred != Metric {
+			c.Header(Header, "lb")
+			c.Next()
+			return
+		}
+
+		if err := convertRequest(c.Request); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+		if inm := c.GetHeader("If-None-Match"); inm != "" {
+			if inm = untagETags(inm); inm == "" {
+				c.Request.Header.Del("If-None-Match")
+			} else {
+				c.Request.Header.Set("If-None-Match", inm)
+			}
+		}
+
+		buf := &bufferedWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+		c.Writer = buf
+		c.Next()
+		c.Writer = buf.ResponseWriter
+
+		h := c.Writer.Header()
+		h.Set(Header, "kg")
+		if etag := h.Get("ETag"); etag != "" {
+			h.Set("ETag", tagETag(etag))
+		}
+		body := buf.body.Bytes()
+		if buf.status < 300 && isJSON(h.Get("Content-Type")) {
+			if converted, err := convertJSON(body, ToKilograms); err == nil {
+				body = converted
+			} else {
+				log.Printf("Error converting response weights: %v", err)
+				h.Set(Header, "lb")
+			}
+		}
+		c.Writer.WriteHeader(buf.status)
+		if len(body) > 0 {
+			c.Writer.Write(body)
+		}
+	}
+}
+
+// convertRequest rewrites a JSON body and the round_to parameter from kilograms
+// to pounds. Malformed bodies are passed on untouched for the handler to reject.
+func convertRequest(r *http.Request) error {
+	if raw := r.URL.Query().Get("round_to"); raw != "" {
+		if kg, err := strconv.ParseFloat(raw, 64); err == nil {
+			q := r.URL.Query()
+			q.Set("round_to", strconv.FormatFloat(kg/KilogramsPerPound, 'f', -1, 64))
+			r.URL.RawQuery = q.Encode()
+		}
+	}
+	if r.Body == nil || !isJSON(r.Header.Get("Content-Type")) {
+		return nil
+	}
+	body, err := io.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		return err
+	}
+	if converted, err := convertJSON(body, ToPounds); err == nil {
+		body = converted
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.ContentLength = int64(len(body))
+	return nil
+}
+
+func isJSON(contentType string) bool {
+	return strings.HasPrefix(strings.TrimSpace(contentType), "application/json")
+}
+
+// convertJSON applies convert to every weight in a JSON document
+func convertJSON(body []byte, convert func(float64) float64) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return json.Marshal(convertValue(doc, convert))
+}
+
+func convertValue(v interface{}, convert func(float64) float64) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if isWeightKey(v, key) {
+				v[key] = convertNumber(child, convert)
+			} else {
+				v[key] = convertValue(child, convert)
+			}
+		}
+		return v
+	case []interface{}:
+		for i, child := range v {
+			v[i] = convertValue(child, convert)
+		}
+		return v
+	}
+	return v
+}
+
+// isWeightKey reports whether obj[key] is a weight. Besides WeightFields,
+// weight and e1rm records carry weights in value and previous, e1rm side
+// imbalances in left and right, and tonnage training load in acute and chronic.
+func isWeightKey(obj map[string]interface{}, key string) bool {
+	if WeightFields[key] {
+		return true
+	}
+	switch key {
+	case "value", "previous":
+		return obj["kind"] == "weight" || obj["kind"] == "e1rm"
+	case "left", "right":
+		return obj["kind"] == "e1rm"
+	case "acute", "chronic":
+		return obj["metric"] == "tonnage"
+	}
+	return false
+}
+
+func convertNumber(v interface{}, convert func(float64) float64) interface{} {
+	n, ok := v.(json.Number)
+	if !ok {
+		return v
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return v
+	}
+	return convert(f)
+}
+
+// tagETag marks an ETag as belonging to the kilogram representation
+func tagETag(etag string) string {
+	if strings.HasSuffix(etag, `"`) {
+		return strings.TrimSuffix(etag, `"`) + `-kg"`
+	}
+	return etag + "-kg"
+}
+
+// untagETags keeps the If-None-Match candidates issued for kilogram responses,
+// without their tag, so pound ones never match a kilogram request
+func untagETags(ifNoneMatch string) string {
+	var kept []string
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			kept = append(kept, candidate)
+		} else if strings.HasSuffix(candidate, `-kg"`) {
+			kept = append(kept, strings.TrimSuffix(candidate, `-kg"`)+`"`)
+		}
+	}
+	return strings.Join(kept, ", ")
+}
+
+// bufferedWriter holds the handler's response so its weights can be
+// converted before anything is sent
+type bufferedWriter struct {
+	gin.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedWriter) WriteHeader(code int) { w.status = code }
+func (w *bufferedWriter) WriteHeaderNow()      {}
+func (w *bufferedWriter) Status() int          { return w.status }
+func (w *bufferedWriter) Size() int            { return w.body.Len() }
+func (w *bufferedWriter) Written() bool        { return w.body.Len() > 0 }
+
+func (w *bufferedWriter) Write(b []byte) (int, error) { return w.body.Write(b) }
+
+func (w *bufferedWriter) WriteString(s string) (int, error) { return w.body.WriteString(s) }
@@ -0,0 +1,142 @@
+package units
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"liftoff/backend/auth"
+	"liftoff/backend/respcache"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newRouter(prefs map[string]string) (*gin.Engine, *float64) {
+	gin.SetMode(gin.TestMode)
+	lookup := func(_ context.Context, userID string) (string, error) { return prefs[userID], nil }
+	stored := new(float64)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if user := c.GetHeader("X-Test-User"); user != "" {
+			c.Set(auth.UserIDKey, user)
+		}
+	}, Middleware(lookup))
+	r.POST("/exercises", func(c *gin.Context) {
+		var body struct {
+			Weight float64 `json:"weight"`
+			Reps   int     `json:"reps"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		*stored = body.Weight
+		c.JSON(http.StatusCreated, body)
+	})
+	r.GET("/progress", respcache.New(time.Minute).Private("progress"), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"maxWeight": 225,
+			"reps":      5,
+			"records":   []gin.H{{"kind": "e1rm", "value": 100, "previous": 0}, {"kind": "reps", "value": 12, "previous": 10}},
+			"load":      gin.H{"metric": "tonnage", "acute": 1000, "chronic": 500, "ratio": 2},
+			"imbalance": []gin.H{{"kind": "e1rm", "left": 100, "right": 90, "imbalance_pct": 10}, {"kind": "reps", "left": 12, "right": 10}},
+		})
+	})
+	r.GET("/log", func(c *gin.Context) { c.String(http.StatusOK, "Squat 225 lb x 5") })
+	return r, stored
+}
+
+func do(r *gin.Engine, method, path, user, body, etag string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("X-Test-User", user)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestMiddlewareConvertsForMetricUsers(t *testing.T) {
+	r, stored := newRouter(map[string]string{"kg-user": Metric, "lb-user": Imperial})
+
+	w := do(r, http.MethodPost, "/exercises", "kg-user", `{"weight": 100, "reps": 5}`, "")
+	if w.Code != http.StatusCreated || *stored != 220.46 {
+		t.Fatalf("metric POST: %d %s, stored %v", w.Code, w.Body, *stored)
+	}
+	var echoed struct {
+		Weight float64
+		Reps   int
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &echoed); err != nil || echoed.Weight != 100 || echoed.Reps != 5 {
+		t.Errorf("metric echo = %s", w.Body)
+	}
+	if w := do(r, http.MethodPost, "/exercises", "lb-user", `{"weight": 100, "reps": 5}`, ""); *stored != 100 || w.Header().Get(Header) != "lb" {
+		t.Errorf("imperial POST stored %v, header %q", *stored, w.Header().Get(Header))
+	}
+
+	w = do(r, http.MethodGet, "/progress", "kg-user", "", "")
+	var progress struct {
+		MaxWeight float64 `json:"maxWeight"`
+		Reps      int
+		Records   []struct {
+			Kind            string
+			Value, Previous float64
+		}
+		Load      struct{ Acute, Chronic, Ratio float64 }
+		Imbalance []struct {
+			Left, Right  float64
+			ImbalancePct float64 `json:"imbalance_pct"`
+		}
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &progress); err != nil || w.Header().Get(Header) != "kg" {
+		t.Fatalf("metric GET: %s %v", w.Body, w.Header())
+	}
+	if progress.MaxWeight != 102.06 || progress.Reps != 5 || progress.Records[0].Value != 45.36 || progress.Records[1].Value != 12 ||
+		progress.Load.Acute != 453.59 || progress.Load.Chronic != 226.8 || progress.Load.Ratio != 2 {
+		t.Errorf("metric progress = %+v", progress)
+	}
+	// Estimated 1RM imbalances are weights, rep imbalances and percentages are not
+	if imb := progress.Imbalance; imb[0].Left != 45.36 || imb[0].Right != 40.82 || imb[0].ImbalancePct != 10 || imb[1].Left != 12 || imb[1].Right != 10 {
+		t.Errorf("metric imbalance = %+v", imb)
+	}
+
+	if w := do(r, http.MethodGet, "/log", "kg-user", "", ""); w.Body.String() != "Squat 225 lb x 5" {
+		t.Errorf("text response changed: %q", w.Body)
+	}
+	if w := do(r, http.MethodPost, "/exercises", "kg-user", `{"weight":`, ""); w.Code != http.StatusBadRequest {
+		t.Errorf("malformed body: got %d, want the handler's 400", w.Code)
+	}
+}
+
+func TestMiddlewareKeepsETagsApart(t *testing.T) {
+	prefs := map[string]string{"user": Metric}
+	r, _ := newRouter(prefs)
+
+	kg := do(r, http.MethodGet, "/progress", "user", "", "")
+	etag := kg.Header().Get("ETag")
+	if !strings.HasSuffix(etag, `-kg"`) {
+		t.Fatalf("metric ETag = %q", etag)
+	}
+	if w := do(r, http.MethodGet, "/progress", "user", "", etag); w.Code != http.StatusNotModified || w.Header().Get("ETag") != etag {
+		t.Errorf("revalidating: %d %q", w.Code, w.Header().Get("ETag"))
+	}
+
+	// After switching to imperial the kilogram copy is stale, and vice versa
+	prefs["user"] = Imperial
+	lb := do(r, http.MethodGet, "/progress", "user", "", etag)
+	if lb.Code != http.StatusOK || strings.Contains(lb.Body.String(), "102.06") {
+		t.Fatalf("after switching to imperial: %d %s", lb.Code, lb.Body)
+	}
+	prefs["user"] = Metric
+	if w := do(r, http.MethodGet, "/progress", "user", "", lb.Header().Get("ETag")); w.Code != http.StatusOK {
+		t.Errorf("pound ETag revalidated a metric request: got %d", w.Code)
+	}
+}